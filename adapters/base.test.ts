@@ -0,0 +1,59 @@
+import { afterEach, describe, expect, setSystemTime, test } from 'bun:test';
+import { BaseAdapter } from './base';
+
+/** Exposes the base helpers; every verb is unsupported */
+class TestAdapter extends BaseAdapter {
+  async capabilities() {
+    return this.unsupported('capabilities');
+  }
+  async authStart() {
+    return this.unsupported('auth:start');
+  }
+  async authRefresh() {
+    return this.unsupported('auth:refresh');
+  }
+  async fetchConfig() {
+    return this.unsupported('fetch:config');
+  }
+  async syncEnv() {
+    return this.unsupported('sync:env');
+  }
+  async deployPreview() {
+    return this.unsupported('deploy:preview');
+  }
+  async dnsUpdate() {
+    return this.unsupported('dns:update');
+  }
+  async dnsRollback() {
+    return this.unsupported('dns:rollback');
+  }
+
+  rateLimitedFor(retryAfter?: string) {
+    const headers: Record<string, string> = retryAfter === undefined ? {} : { 'Retry-After': retryAfter };
+    return this.rateLimited(new Response(null, { status: 429, headers }));
+  }
+}
+
+afterEach(() => {
+  setSystemTime();
+});
+
+describe('rateLimited', () => {
+  test.each<[string, string | undefined, number | undefined]>([
+    ['delay in seconds', '7', 7],
+    ['zero delay', '0', 0],
+    ['HTTP date', 'Thu, 01 Jan 2026 00:00:30 GMT', 30],
+    ['HTTP date rounds up', 'Thu, 01 Jan 2026 00:00:01 GMT', 1],
+    ['HTTP date in the past', 'Wed, 31 Dec 2025 23:59:00 GMT', 0],
+    ['unparseable', 'soon', undefined],
+    ['no header', undefined, undefined],
+  ])('%s', (_name, header, retryAfter) => {
+    setSystemTime(new Date('2026-01-01T00:00:00.500Z'));
+
+    const response = new TestAdapter().rateLimitedFor(header);
+    expect(response.ok).toBe(false);
+    expect(response.error?.code).toBe('RATE_LIMITED');
+    expect(response.error?.recoverable).toBe(true);
+    expect(response.error?.details).toEqual(retryAfter === undefined ? undefined : { retry_after: retryAfter });
+  });
+});
@@ -13,10 +13,15 @@ import type {
   SyncEnvData,
   DeployPreviewParams,
   DeployPreviewData,
+  DeployStatusParams,
   DnsUpdateParams,
   DnsUpdateData,
   DnsRollbackParams,
   DnsRollbackData,
+  DomainsListParams,
+  DomainsListData,
+  DnsListParams,
+  DnsListData,
 } from './types';
 
 export abstract class BaseAdapter implements Adapter {
@@ -31,6 +36,18 @@ export abstract class BaseAdapter implements Adapter {
   abstract dnsUpdate(params: DnsUpdateParams): Promise<BridgeResponse<DnsUpdateData>>;
   abstract dnsRollback(params: DnsRollbackParams): Promise<BridgeResponse<DnsRollbackData>>;
 
+  async deployStatus(_params: DeployStatusParams): Promise<BridgeResponse<DeployPreviewData>> {
+    return this.unsupported('deploy:status');
+  }
+
+  async domainsList(_params: DomainsListParams): Promise<BridgeResponse<DomainsListData>> {
+    return this.unsupported('domains:list');
+  }
+
+  async dnsList(_params: DnsListParams): Promise<BridgeResponse<DnsListData>> {
+    return this.unsupported('dns:list');
+  }
+
   protected success<T>(data: T): BridgeResponse<T> {
     return {
       ok: true,
@@ -47,6 +64,25 @@ export abstract class BaseAdapter implements Adapter {
     };
   }
 
+  /**
+   * RATE_LIMITED error carrying the provider's Retry-After (in seconds) as
+   * details.retry_after, which the CLI waits for before retrying
+   */
+  protected rateLimited(response: Response): BridgeResponse<never> {
+    const header = response.headers.get('retry-after');
+    let retryAfter = header ? Number(header) : NaN;
+    if (header && Number.isNaN(retryAfter)) {
+      // Retry-After may also be an HTTP date
+      retryAfter = Math.max(0, Math.ceil((Date.parse(header) - Date.now()) / 1000));
+    }
+    return this.error({
+      code: 'RATE_LIMITED',
+      message: 'Provider rate limit reached',
+      recoverable: true,
+      details: Number.isNaN(retryAfter) ? undefined : { retry_after: retryAfter },
+    });
+  }
+
   protected unsupported(verb: string): BridgeResponse<never> {
     return this.error({
       code: 'UNSUPPORTED',
@@ -81,12 +117,21 @@ export abstract class BaseAdapter implements Adapter {
         case 'deploy:preview':
           response = await this.deployPreview(params as DeployPreviewParams);
           break;
+        case 'deploy:status':
+          response = await this.deployStatus(params as DeployStatusParams);
+          break;
         case 'dns:update':
           response = await this.dnsUpdate(params as DnsUpdateParams);
           break;
         case 'dns:rollback':
           response = await this.dnsRollback(params as DnsRollbackParams);
           break;
+        case 'domains:list':
+          response = await this.domainsList(params as DomainsListParams);
+          break;
+        case 'dns:list':
+          response = await this.dnsList(params as DnsListParams);
+          break;
         default:
           response = this.error({
             code: 'INVALID_PARAMS',
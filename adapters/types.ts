@@ -115,6 +115,13 @@ export interface DeployPreviewData {
   build_time?: number;
 }
 
+// Command: deploy:status
+export interface DeployStatusParams {
+  provider: Provider;
+  token: string;
+  deployment_id: string;
+}
+
 // Command: dns:update
 export interface DnsUpdateParams {
   provider: Provider;
@@ -145,6 +152,42 @@ export interface DnsRollbackData {
   current_value: string;
 }
 
+// Command: domains:list
+export interface DomainsListParams {
+  provider: Provider;
+  token: string;
+}
+
+export interface Domain {
+  name: string;
+  verified: boolean;
+  project_id?: string;
+}
+
+export interface DomainsListData {
+  domains: Domain[];
+}
+
+// Command: dns:list
+export interface DnsListParams {
+  provider: Provider;
+  token: string;
+  domain: string;
+}
+
+// Any record type the zone holds, not only those dns:update can set
+export interface DnsRecord {
+  id: string;
+  type: string;
+  name: string;
+  value: string;
+  ttl: number;
+}
+
+export interface DnsListData {
+  records: DnsRecord[];
+}
+
 // Command: capabilities
 export interface CapabilitiesData {
   adapter_name: string;
@@ -167,6 +210,9 @@ export interface Adapter {
   fetchConfig(params: FetchConfigParams): Promise<BridgeResponse<FetchConfigData>>;
   syncEnv(params: SyncEnvParams): Promise<BridgeResponse<SyncEnvData>>;
   deployPreview(params: DeployPreviewParams): Promise<BridgeResponse<DeployPreviewData>>;
+  deployStatus(params: DeployStatusParams): Promise<BridgeResponse<DeployPreviewData>>;
   dnsUpdate(params: DnsUpdateParams): Promise<BridgeResponse<DnsUpdateData>>;
   dnsRollback(params: DnsRollbackParams): Promise<BridgeResponse<DnsRollbackData>>;
+  domainsList(params: DomainsListParams): Promise<BridgeResponse<DomainsListData>>;
+  dnsList(params: DnsListParams): Promise<BridgeResponse<DnsListData>>;
 }
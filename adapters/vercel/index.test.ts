@@ -0,0 +1,77 @@
+import { afterEach, describe, expect, test } from 'bun:test';
+import type { DeploymentStatus } from '../types';
+import { VercelAdapter } from './index';
+
+const realFetch = globalThis.fetch;
+
+afterEach(() => {
+  globalThis.fetch = realFetch;
+});
+
+/** Answers every request with body as JSON and the given status */
+function respondWith(body: unknown, status = 200, headers: Record<string, string> = {}) {
+  globalThis.fetch = (async () =>
+    new Response(JSON.stringify(body), {
+      status,
+      headers: { 'Content-Type': 'application/json', ...headers },
+    })) as unknown as typeof fetch;
+}
+
+function deployStatus() {
+  return new VercelAdapter().deployStatus({
+    provider: 'vercel',
+    token: 'tok_test',
+    deployment_id: 'dpl_1',
+  });
+}
+
+describe('deployStatus', () => {
+  test.each<[string, DeploymentStatus]>([
+    ['QUEUED', 'queued'],
+    ['INITIALIZING', 'building'],
+    ['BUILDING', 'building'],
+    ['READY', 'ready'],
+    ['ERROR', 'error'],
+    ['CANCELED', 'error'],
+    ['SOMETHING_NEW', 'queued'],
+  ])('maps %s to %s', async (readyState, status) => {
+    respondWith({ id: 'dpl_1', url: 'app-abc.vercel.app', readyState });
+
+    const response = await deployStatus();
+    expect(response.ok).toBe(true);
+    expect(response.data?.status).toBe(status);
+    expect(response.data?.url).toBe('https://app-abc.vercel.app');
+  });
+
+  test('reports build time once the build has finished', async () => {
+    respondWith({ id: 'dpl_1', readyState: 'READY', buildingAt: 1_000, ready: 43_400 });
+
+    const response = await deployStatus();
+    expect(response.data?.build_time).toBe(42);
+    expect(response.data?.url).toBe('');
+  });
+
+  test('leaves build time unset while building', async () => {
+    respondWith({ id: 'dpl_1', readyState: 'BUILDING', buildingAt: 1_000 });
+
+    const response = await deployStatus();
+    expect(response.data?.build_time).toBeUndefined();
+  });
+
+  test('maps a missing deployment to NOT_FOUND', async () => {
+    respondWith({ error: { message: 'Deployment not found' } }, 404);
+
+    const response = await deployStatus();
+    expect(response.ok).toBe(false);
+    expect(response.error?.code).toBe('NOT_FOUND');
+    expect(response.error?.message).toBe('Deployment not found');
+  });
+
+  test('passes a rate limit on as RATE_LIMITED', async () => {
+    respondWith({}, 429, { 'Retry-After': '7' });
+
+    const response = await deployStatus();
+    expect(response.error?.code).toBe('RATE_LIMITED');
+    expect(response.error?.details).toEqual({ retry_after: 7 });
+  });
+});
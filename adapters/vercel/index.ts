@@ -14,15 +14,19 @@ import type {
   SyncEnvData,
   DeployPreviewParams,
   DeployPreviewData,
+  DeployStatusParams,
+  DeploymentStatus,
   DnsUpdateParams,
   DnsUpdateData,
   DnsRollbackParams,
   DnsRollbackData,
+  DomainsListParams,
+  DomainsListData,
 } from '../types';
 
 const VERCEL_API_BASE = 'https://api.vercel.com';
 
-class VercelAdapter extends BaseAdapter {
+export class VercelAdapter extends BaseAdapter {
   async capabilities(): Promise<BridgeResponse<CapabilitiesData>> {
     return this.success({
       adapter_name: 'vercel',
@@ -33,8 +37,10 @@ class VercelAdapter extends BaseAdapter {
         'fetch:config',
         'sync:env',
         'deploy:preview',
+        'deploy:status',
         'dns:update',
         'dns:rollback',
+        'domains:list',
       ],
       auth_type: 'token',
       features: {
@@ -73,6 +79,9 @@ class VercelAdapter extends BaseAdapter {
           },
         });
 
+        if (response.status === 429) {
+          return this.rateLimited(response);
+        }
         if (!response.ok) {
           const error = await response.json();
           return this.error({
@@ -104,6 +113,9 @@ class VercelAdapter extends BaseAdapter {
         },
       });
 
+      if (projectResponse.status === 429) {
+        return this.rateLimited(projectResponse);
+      }
       if (!projectResponse.ok) {
         const error = await projectResponse.json();
         return this.error({
@@ -192,7 +204,131 @@ class VercelAdapter extends BaseAdapter {
   }
 
   async deployPreview(params: DeployPreviewParams): Promise<BridgeResponse<DeployPreviewData>> {
-    return this.unsupported('deploy:preview');
+    const { token, project_id, branch } = params;
+
+    try {
+      // A preview is built from the project's linked git repository
+      const projectResponse = await fetch(`${VERCEL_API_BASE}/v9/projects/${project_id}`, {
+        headers: {
+          Authorization: `Bearer ${token}`,
+        },
+      });
+
+      if (projectResponse.status === 429) {
+        return this.rateLimited(projectResponse);
+      }
+      if (!projectResponse.ok) {
+        const error = await projectResponse.json();
+        return this.error({
+          code: projectResponse.status === 404 ? 'NOT_FOUND' : 'PROVIDER_ERROR',
+          message: error.error?.message || 'Failed to fetch project',
+          recoverable: false,
+          details: error,
+        });
+      }
+
+      const project = await projectResponse.json();
+      if (!project.link?.repoId) {
+        return this.error({
+          code: 'INVALID_PARAMS',
+          message: `Project ${project.name} is not linked to a git repository`,
+          recoverable: false,
+        });
+      }
+
+      const response = await fetch(`${VERCEL_API_BASE}/v13/deployments`, {
+        method: 'POST',
+        headers: {
+          Authorization: `Bearer ${token}`,
+          'Content-Type': 'application/json',
+        },
+        body: JSON.stringify({
+          name: project.name,
+          project: project.id,
+          gitSource: {
+            type: project.link.type,
+            repoId: project.link.repoId,
+            ref: branch || project.link.productionBranch || 'main',
+          },
+        }),
+      });
+
+      if (response.status === 429) {
+        return this.rateLimited(response);
+      }
+      if (!response.ok) {
+        const error = await response.json();
+        return this.error({
+          code: response.status === 401 ? 'AUTH_FAILED' : 'PROVIDER_ERROR',
+          message: error.error?.message || 'Failed to create deployment',
+          recoverable: response.status === 401,
+          details: error,
+        });
+      }
+
+      return this.success(this.deployment(await response.json()));
+    } catch (err) {
+      return this.error({
+        code: 'NETWORK_ERROR',
+        message: err instanceof Error ? err.message : String(err),
+        recoverable: true,
+      });
+    }
+  }
+
+  async deployStatus(params: DeployStatusParams): Promise<BridgeResponse<DeployPreviewData>> {
+    try {
+      const response = await fetch(`${VERCEL_API_BASE}/v13/deployments/${params.deployment_id}`, {
+        headers: {
+          Authorization: `Bearer ${params.token}`,
+        },
+      });
+
+      if (response.status === 429) {
+        return this.rateLimited(response);
+      }
+      if (!response.ok) {
+        const error = await response.json();
+        return this.error({
+          code: response.status === 404 ? 'NOT_FOUND' : 'PROVIDER_ERROR',
+          message: error.error?.message || 'Failed to get deployment',
+          recoverable: false,
+          details: error,
+        });
+      }
+
+      return this.success(this.deployment(await response.json()));
+    } catch (err) {
+      return this.error({
+        code: 'NETWORK_ERROR',
+        message: err instanceof Error ? err.message : String(err),
+        recoverable: true,
+      });
+    }
+  }
+
+  /**
+   * Maps a Vercel deployment to the bridge's shape. Build time is known once
+   * the build has finished.
+   */
+  private deployment(d: any): DeployPreviewData {
+    const states: Record<string, DeploymentStatus> = {
+      QUEUED: 'queued',
+      INITIALIZING: 'building',
+      BUILDING: 'building',
+      READY: 'ready',
+      ERROR: 'error',
+      CANCELED: 'error',
+    };
+    const data: DeployPreviewData = {
+      deployment_id: d.id,
+      url: d.url ? `https://${d.url}` : '',
+      status: states[d.readyState] || 'queued',
+    };
+    if (d.buildingAt && d.ready) {
+      data.build_time = Math.round((d.ready - d.buildingAt) / 1000);
+    }
+    return data;
   }
 
   async dnsUpdate(params: DnsUpdateParams): Promise<BridgeResponse<DnsUpdateData>> {
@@ -202,24 +338,64 @@ class VercelAdapter extends BaseAdapter {
   async dnsRollback(params: DnsRollbackParams): Promise<BridgeResponse<DnsRollbackData>> {
     return this.unsupported('dns:rollback');
   }
-}
 
-// CLI entry point
-const adapter = new VercelAdapter();
-const verb = process.argv[2];
-let params: unknown;
-
-// Read params from stdin if available
-if (process.stdin.isTTY === false) {
-  const stdin = await Bun.stdin.text();
-  if (stdin.trim()) {
+  async domainsList(params: DomainsListParams): Promise<BridgeResponse<DomainsListData>> {
     try {
-      params = JSON.parse(stdin);
+      const response = await fetch(`${VERCEL_API_BASE}/v5/domains`, {
+        headers: {
+          Authorization: `Bearer ${params.token}`,
+        },
+      });
+
+      if (response.status === 429) {
+        return this.rateLimited(response);
+      }
+      if (!response.ok) {
+        const error = await response.json();
+        return this.error({
+          code: response.status === 401 ? 'AUTH_FAILED' : 'PROVIDER_ERROR',
+          message: error.error?.message || 'Failed to list domains',
+          recoverable: response.status === 401,
+          details: error,
+        });
+      }
+
+      // Account domains aren't tied to a project, so project_id is left unset
+      const data = await response.json();
+      return this.success({
+        domains: (data.domains || []).map((d: any) => ({
+          name: d.name,
+          verified: Boolean(d.verified),
+        })),
+      });
     } catch (err) {
-      console.error('Failed to parse stdin JSON:', err);
-      process.exit(1);
+      return this.error({
+        code: 'NETWORK_ERROR',
+        message: err instanceof Error ? err.message : String(err),
+        recoverable: true,
+      });
     }
   }
 }
 
-await adapter.execute(verb, params);
+// CLI entry point, skipped when the adapter is imported by tests
+if (import.meta.main) {
+  const adapter = new VercelAdapter();
+  const verb = process.argv[2];
+  let params: unknown;
+
+  // Read params from stdin if available
+  if (process.stdin.isTTY === false) {
+    const stdin = await Bun.stdin.text();
+    if (stdin.trim()) {
+      try {
+        params = JSON.parse(stdin);
+      } catch (err) {
+        console.error('Failed to parse stdin JSON:', err);
+        process.exit(1);
+      }
+    }
+  }
+
+  await adapter.execute(verb, params);
+}
@@ -0,0 +1,100 @@
+package bridge
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// userAdaptersPath is where `dt adapters install` puts adapters when no
+// adapters path is set and none is found: ~/.deploy-tunnel/adapters
+func userAdaptersPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home dir: %w", err)
+	}
+	return filepath.Join(home, ".deploy-tunnel", "adapters"), nil
+}
+
+// InstallAdaptersPath returns where adapters should be installed: path when
+// set, otherwise the adapters path already in use, otherwise
+// ~/.deploy-tunnel/adapters. Unlike ResolveAdaptersPath, the directory
+// doesn't have to hold adapters yet.
+func InstallAdaptersPath(path string) (string, error) {
+	if path != "" {
+		return path, nil
+	}
+	if resolved, err := ResolveAdaptersPath(""); err == nil {
+		return resolved, nil
+	}
+	return userAdaptersPath()
+}
+
+// defaultAdaptersPaths are searched in order when no adapters path is set:
+// beside an installed binary, then ./adapters for `go run` from a checkout,
+// then ~/.deploy-tunnel/adapters where `dt adapters install` puts them
+func defaultAdaptersPaths() []string {
+	var paths []string
+	if execPath, err := os.Executable(); err == nil {
+		dir := filepath.Dir(execPath)
+		paths = append(paths,
+			filepath.Join(dir, "..", "adapters"),
+			filepath.Join(dir, "adapters"),
+		)
+	}
+	if wd, err := os.Getwd(); err == nil {
+		paths = append(paths, filepath.Join(wd, "adapters"))
+	}
+	if user, err := userAdaptersPath(); err == nil {
+		paths = append(paths, user)
+	}
+	return paths
+}
+
+// ResolveAdaptersPath returns path if it holds at least one adapter
+// (<path>/<provider>/index.ts). An empty path searches the default
+// locations; the error lists every place that was checked.
+func ResolveAdaptersPath(path string) (string, error) {
+	if path != "" {
+		if err := checkAdaptersDir(path); err != nil {
+			return "", fmt.Errorf("invalid adapters path: %w", err)
+		}
+		return path, nil
+	}
+
+	candidates := defaultAdaptersPaths()
+	looked := make([]string, len(candidates))
+	for i, candidate := range candidates {
+		candidate = filepath.Clean(candidate)
+		err := checkAdaptersDir(candidate)
+		if err == nil {
+			return candidate, nil
+		}
+		looked[i] = "  " + err.Error()
+	}
+	return "", fmt.Errorf("no adapters found; looked in:\n%s\nset --adapters-path or DEPLOY_TUNNEL_ADAPTERS to the directory holding <provider>/index.ts", strings.Join(looked, "\n"))
+}
+
+// checkAdaptersDir reports why dir can't be used as the adapters path
+func checkAdaptersDir(dir string) error {
+	info, err := os.Stat(dir)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("%s: does not exist", dir)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", dir, err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s: not a directory", dir)
+	}
+
+	providers, err := InstalledAdapters(dir)
+	if err != nil {
+		return fmt.Errorf("%s: %w", dir, err)
+	}
+	if len(providers) == 0 {
+		return fmt.Errorf("%s: contains no <provider>/index.ts adapters", dir)
+	}
+	return nil
+}
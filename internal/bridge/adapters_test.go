@@ -0,0 +1,114 @@
+package bridge
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// installAdapter creates dir/<provider>/index.ts
+func installAdapter(t *testing.T, dir string, provider Provider) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Join(dir, string(provider)), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, string(provider), "index.ts"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestResolveAdaptersPathExplicit(t *testing.T) {
+	valid := t.TempDir()
+	installAdapter(t, valid, "vercel")
+	empty := t.TempDir()
+	// A staging dir from an interrupted install doesn't count
+	installAdapter(t, empty, ".staging")
+	file := filepath.Join(t.TempDir(), "adapters")
+	if err := os.WriteFile(file, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		path string
+		// wantErr is a substring of the expected error, "" when path is used
+		wantErr string
+	}{
+		{name: "holds adapters", path: valid},
+		{name: "nonexistent", path: filepath.Join(valid, "missing"), wantErr: "does not exist"},
+		{name: "no adapters", path: empty, wantErr: "contains no <provider>/index.ts adapters"},
+		{name: "not a directory", path: file, wantErr: "not a directory"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveAdaptersPath(tt.path)
+			if tt.wantErr == "" {
+				if err != nil || got != tt.path {
+					t.Errorf("ResolveAdaptersPath(%q) = %q, %v", tt.path, got, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), "invalid adapters path") || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ResolveAdaptersPath(%q) error = %v, want %q", tt.path, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestResolveAdaptersPathDefaults(t *testing.T) {
+	tests := []struct {
+		name    string
+		inWd    bool
+		inHome  bool
+		wantDir string
+	}{
+		{name: "checkout before home", inWd: true, inHome: true, wantDir: "wd"},
+		{name: "home", inHome: true, wantDir: "home"},
+		{name: "none"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := t.TempDir()
+			t.Setenv("HOME", home)
+			wd := t.TempDir()
+			t.Chdir(wd)
+
+			wdAdapters := filepath.Join(wd, "adapters")
+			homeAdapters := filepath.Join(home, ".deploy-tunnel", "adapters")
+			if tt.inWd {
+				installAdapter(t, wdAdapters, "vercel")
+			}
+			if tt.inHome {
+				installAdapter(t, homeAdapters, "netlify")
+			}
+
+			got, err := ResolveAdaptersPath("")
+			switch tt.wantDir {
+			case "wd":
+				if err != nil || got != wdAdapters {
+					t.Errorf("ResolveAdaptersPath() = %q, %v; want %q", got, err, wdAdapters)
+				}
+			case "home":
+				if err != nil || got != homeAdapters {
+					t.Errorf("ResolveAdaptersPath() = %q, %v; want %q", got, err, homeAdapters)
+				}
+			default:
+				if err == nil {
+					t.Fatalf("ResolveAdaptersPath() = %q, want an error", got)
+				}
+				// Every candidate is listed, in search order
+				msg := err.Error()
+				i, j := strings.Index(msg, wdAdapters+": does not exist"), strings.Index(msg, homeAdapters+": does not exist")
+				if i < 0 || j < 0 || i > j {
+					t.Errorf("error doesn't list %s then %s:\n%s", wdAdapters, homeAdapters, msg)
+				}
+				if !strings.Contains(msg, "--adapters-path") {
+					t.Errorf("error doesn't say how to set the path:\n%s", msg)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,283 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// CapResult is the capabilities result for a single provider. Err is set
+// when the provider could not be reached; Capabilities is nil in that case.
+type CapResult struct {
+	Capabilities *CapabilitiesData
+	Err          error
+}
+
+// Available reports whether the provider responded successfully
+func (r CapResult) Available() bool {
+	return r.Err == nil && r.Capabilities != nil
+}
+
+// ProviderCapabilities is a CapResult with its provider, for listing
+// results in a fixed order
+type ProviderCapabilities struct {
+	Provider Provider
+	CapResult
+}
+
+// InOrder lists results in the order of providers; providers without a
+// result are skipped
+func InOrder(providers []Provider, results map[Provider]CapResult) []ProviderCapabilities {
+	ordered := make([]ProviderCapabilities, 0, len(providers))
+	for _, provider := range providers {
+		if r, ok := results[provider]; ok {
+			ordered = append(ordered, ProviderCapabilities{Provider: provider, CapResult: r})
+		}
+	}
+	return ordered
+}
+
+// maxCapabilityWorkers bounds how many adapters CapabilitiesAll runs at once
+const maxCapabilityWorkers = 4
+
+// CapabilitiesAll fetches capabilities for each provider concurrently,
+// collecting errors per provider instead of failing the whole batch on the
+// first error. Each call is bounded by the bridge timeout, so a slow adapter
+// only delays its own result. Providers not yet started when ctx is
+// cancelled get ctx's error.
+func (b *Bridge) CapabilitiesAll(ctx context.Context, providers []Provider) map[Provider]CapResult {
+	results := make(map[Provider]CapResult, len(providers))
+	var mu sync.Mutex
+	jobs := make(chan Provider)
+
+	workers := maxCapabilityWorkers
+	if len(providers) < workers {
+		workers = len(providers)
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for provider := range jobs {
+				var result CapResult
+				if err := ctx.Err(); err != nil {
+					result.Err = err
+				} else {
+					result.Capabilities, result.Err = b.Capabilities(ctx, provider)
+				}
+
+				mu.Lock()
+				results[provider] = result
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, provider := range providers {
+		jobs <- provider
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+// maxDnsUpdateWorkers bounds how many records DnsUpdateBatch updates at once
+const maxDnsUpdateWorkers = 4
+
+// DnsUpdateResult is the outcome of one record in a DnsUpdateBatch. Record
+// is the request with its token cleared; Data (with the record's previous
+// value, for rollback) is set on success and Err on failure.
+type DnsUpdateResult struct {
+	Record DnsUpdateParams
+	Data   *DnsUpdateData
+	Err    error
+}
+
+// DnsUpdateBatch applies several DNS record updates on one provider
+// concurrently. Every record is attempted even if others fail, and results
+// are in the order of records. The error is non-nil when any record failed,
+// but the results still report which ones were applied and must be rolled
+// back or recorded.
+func (b *Bridge) DnsUpdateBatch(ctx context.Context, provider Provider, token string, records []DnsUpdateParams) ([]DnsUpdateResult, error) {
+	results := make([]DnsUpdateResult, len(records))
+	jobs := make(chan int)
+
+	workers := maxDnsUpdateWorkers
+	if len(records) < workers {
+		workers = len(records)
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				params := records[i]
+				params.Provider = provider
+				params.Token = token
+
+				result := DnsUpdateResult{Record: params}
+				result.Record.Token = ""
+				if err := ctx.Err(); err != nil {
+					result.Err = err
+				} else {
+					result.Data, result.Err = b.DnsUpdate(ctx, params)
+				}
+				results[i] = result
+			}
+		}()
+	}
+
+	for i := range records {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	failed := 0
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return results, fmt.Errorf("%d of %d DNS updates failed", failed, len(records))
+	}
+	return results, nil
+}
+
+// ListAdapters fetches capabilities for every adapter installed under the
+// adapters path, including ones for providers this binary doesn't know about
+func (b *Bridge) ListAdapters(ctx context.Context) ([]ProviderCapabilities, error) {
+	providers, err := InstalledAdapters(b.adaptersPath)
+	if err != nil {
+		return nil, err
+	}
+	return InOrder(providers, b.CapabilitiesAll(ctx, providers)), nil
+}
+
+// ParseProvider returns the named provider if its adapter is installed under
+// the adapters path, or an error listing the ones that are
+func (b *Bridge) ParseProvider(name string) (Provider, error) {
+	if err := ValidProviderName(name); err != nil {
+		return "", err
+	}
+	installed, err := InstalledAdapters(b.adaptersPath)
+	if err != nil {
+		return "", err
+	}
+
+	names := make([]string, len(installed))
+	for i, p := range installed {
+		if string(p) == name {
+			return p, nil
+		}
+		names[i] = string(p)
+	}
+	return "", fmt.Errorf("unknown provider %q (installed: %s)", name, strings.Join(names, ", "))
+}
+
+// InstalledAdapters lists the providers with a <dir>/<provider>/index.ts, sorted
+func InstalledAdapters(dir string) ([]Provider, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read adapters directory: %w", err)
+	}
+
+	var providers []Provider
+	for _, entry := range entries {
+		// Dot-prefixed directories are install staging and backups
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(dir, entry.Name(), "index.ts")); err == nil {
+			providers = append(providers, Provider(entry.Name()))
+		}
+	}
+	sort.Slice(providers, func(i, j int) bool { return providers[i] < providers[j] })
+	return providers, nil
+}
+
+// DefaultEnvBatchSize is how many env vars SyncEnvBatched sends per adapter call
+const DefaultEnvBatchSize = 20
+
+// SyncProgress is the running total reported after each SyncEnvBatched batch
+type SyncProgress struct {
+	Synced int
+	Failed []string
+	Total  int
+}
+
+// Done returns how many variables have been attempted so far
+func (p SyncProgress) Done() int {
+	return p.Synced + len(p.Failed)
+}
+
+// SyncEnvBatched syncs env vars in batches of batchSize, calling onBatch after
+// each one. On error the totals so far are returned alongside it.
+func (b *Bridge) SyncEnvBatched(ctx context.Context, params SyncEnvParams, batchSize int, onBatch func(SyncProgress)) (*SyncEnvData, error) {
+	if batchSize <= 0 {
+		batchSize = DefaultEnvBatchSize
+	}
+
+	total := &SyncEnvData{}
+	progress := SyncProgress{Total: len(params.EnvVars)}
+
+	for start := 0; start < len(params.EnvVars); start += batchSize {
+		end := start + batchSize
+		if end > len(params.EnvVars) {
+			end = len(params.EnvVars)
+		}
+
+		batch := params
+		batch.EnvVars = params.EnvVars[start:end]
+		data, err := b.SyncEnv(ctx, batch)
+		if err != nil {
+			return total, fmt.Errorf("failed to sync batch %d-%d: %w", start+1, end, err)
+		}
+
+		total.Synced += data.Synced
+		total.Failed = append(total.Failed, data.Failed...)
+		progress.Synced = total.Synced
+		progress.Failed = append([]string(nil), total.Failed...)
+		if onBatch != nil {
+			onBatch(progress)
+		}
+	}
+
+	return total, nil
+}
+
+// SyncedVars returns the variables from sent that a SyncEnvBatched result
+// reports as synced. Batches run in order, so everything up to the number
+// attempted went out, less the keys that failed.
+func SyncedVars(sent []EnvVar, result *SyncEnvData) []EnvVar {
+	if result == nil {
+		return nil
+	}
+	attempted := result.Synced + len(result.Failed)
+	if attempted > len(sent) {
+		attempted = len(sent)
+	}
+
+	failed := make(map[string]bool, len(result.Failed))
+	for _, key := range result.Failed {
+		failed[key] = true
+	}
+
+	var synced []EnvVar
+	for _, e := range sent[:attempted] {
+		if !failed[e.Key] {
+			synced = append(synced, e)
+		}
+	}
+	return synced
+}
@@ -0,0 +1,210 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeAdapters writes adapters whose index.ts is the response they print,
+// run by a stand-in runtime that sleeps for delay first
+func fakeAdapters(t *testing.T, responses map[Provider]string, delay time.Duration) *Bridge {
+	t.Helper()
+	dir := t.TempDir()
+	for provider, response := range responses {
+		if err := os.MkdirAll(filepath.Join(dir, string(provider)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, string(provider), "index.ts"), []byte(response), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Called as: runtime run <adapter path> <verb>
+	runtime := filepath.Join(t.TempDir(), "runtime")
+	script := fmt.Sprintf("#!/bin/sh\nsleep %.3f\ncat \"$2\"\n", delay.Seconds())
+	if err := os.WriteFile(runtime, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	b := NewBridge(dir)
+	b.SetRuntime(runtime)
+	return b
+}
+
+func capabilitiesResponse(name string) string {
+	return fmt.Sprintf(`{"ok":true,"data":{"adapter_name":%q,"adapter_version":"1.0.0","supported_verbs":["capabilities"],"auth_type":"token"}}`, name)
+}
+
+func TestCapabilitiesAll(t *testing.T) {
+	const delay = 300 * time.Millisecond
+	responses := map[Provider]string{
+		"alpha": capabilitiesResponse("alpha"),
+		"beta":  capabilitiesResponse("beta"),
+		"gamma": capabilitiesResponse("gamma"),
+		"delta": `not json`,
+	}
+	b := fakeAdapters(t, responses, delay)
+	providers := []Provider{"alpha", "beta", "gamma", "delta", "missing"}
+
+	started := time.Now()
+	results := b.CapabilitiesAll(context.Background(), providers)
+	elapsed := time.Since(started)
+
+	// Four adapters run serially would take 4 × delay
+	if elapsed >= 3*delay {
+		t.Errorf("CapabilitiesAll took %s; adapters did not run concurrently", elapsed)
+	}
+
+	tests := []struct {
+		provider  Provider
+		available bool
+	}{
+		{"alpha", true},
+		{"beta", true},
+		{"gamma", true},
+		{"delta", false},
+		{"missing", false},
+	}
+	for _, tt := range tests {
+		r, ok := results[tt.provider]
+		if !ok {
+			t.Errorf("no result for %s", tt.provider)
+			continue
+		}
+		if r.Available() != tt.available {
+			t.Errorf("%s available = %v, want %v (err: %v)", tt.provider, r.Available(), tt.available, r.Err)
+		}
+		if tt.available && r.Capabilities.AdapterName != string(tt.provider) {
+			t.Errorf("%s got capabilities of %s", tt.provider, r.Capabilities.AdapterName)
+		}
+	}
+
+	ordered := InOrder(providers, results)
+	for i, r := range ordered {
+		if r.Provider != providers[i] {
+			t.Errorf("InOrder[%d] = %s, want %s", i, r.Provider, providers[i])
+		}
+	}
+}
+
+func TestCapabilitiesAllCancelled(t *testing.T) {
+	b := fakeAdapters(t, map[Provider]string{"alpha": capabilitiesResponse("alpha")}, 0)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results := b.CapabilitiesAll(ctx, []Provider{"alpha"})
+	if r := results["alpha"]; r.Err != context.Canceled {
+		t.Errorf("err = %v, want context.Canceled", r.Err)
+	}
+}
+
+// dnsUpdateScript answers dns:update with rec_<name>, after a pause for
+// "slow" so results finish out of order, and fails for "broken"
+const dnsUpdateScript = `input=$(cat)
+case "$3" in
+capabilities) echo '{"ok":true,"data":{"adapter_name":"fake","adapter_version":"1.0.0","auth_type":"token"}}' ;;
+dns:update)
+	case "$input" in *'"token":"tok_batch"'*) ;; *) echo '{"ok":false,"error":{"code":"AUTH_FAILED","message":"no token","recoverable":false}}'; exit 0 ;; esac
+	name=$(echo "$input" | sed 's/.*"record_name":"\([^"]*\)".*/\1/')
+	case "$name" in
+	slow) sleep 0.2 ;;
+	broken) echo '{"ok":false,"error":{"code":"PROVIDER_ERROR","message":"record is locked","recoverable":false}}'; exit 0 ;;
+	esac
+	echo '{"ok":true,"data":{"record_id":"rec_'"$name"'","propagation_time":60}}' ;;
+esac
+`
+
+func TestDnsUpdateBatch(t *testing.T) {
+	b, _ := scriptBridge(t, dnsUpdateScript, "netlify")
+	records := []DnsUpdateParams{
+		{Domain: "example.com", RecordType: "A", RecordName: "slow", RecordValue: "203.0.113.10"},
+		{Domain: "example.com", RecordType: "CNAME", RecordName: "broken", RecordValue: "app.netlify.app"},
+		{Domain: "example.com", RecordType: "CNAME", RecordName: "www", RecordValue: "app.netlify.app"},
+	}
+
+	results, err := b.DnsUpdateBatch(context.Background(), "netlify", "tok_batch", records)
+	if err == nil || !strings.Contains(err.Error(), "1 of 3 DNS updates failed") {
+		t.Errorf("DnsUpdateBatch() error = %v, want one of three failed", err)
+	}
+	if len(results) != len(records) {
+		t.Fatalf("got %d results, want %d", len(results), len(records))
+	}
+
+	for i, r := range results {
+		name := records[i].RecordName
+		if r.Record.RecordName != name {
+			t.Errorf("result %d is for %q, want %q (results out of order)", i, r.Record.RecordName, name)
+		}
+		if r.Record.Token != "" {
+			t.Errorf("result %d kept the token in its record", i)
+		}
+		if r.Record.Provider != "netlify" {
+			t.Errorf("result %d provider = %q, want netlify", i, r.Record.Provider)
+		}
+
+		if name == "broken" {
+			if r.Err == nil || !strings.Contains(r.Err.Error(), "record is locked") {
+				t.Errorf("%s error = %v, want the adapter's error", name, r.Err)
+			}
+			continue
+		}
+		if r.Err != nil {
+			t.Errorf("%s error: %v; a failed record aborted the others", name, r.Err)
+			continue
+		}
+		if r.Data == nil || r.Data.RecordID != "rec_"+name {
+			t.Errorf("%s data = %+v, want record rec_%s", name, r.Data, name)
+		}
+	}
+}
+
+func TestParseProvider(t *testing.T) {
+	// fly isn't built in, but its adapter is installed
+	b := fakeAdapters(t, map[Provider]string{"vercel": "", "fly": ""}, 0)
+
+	tests := []struct {
+		name string
+		// wantErr is a substring of the expected error, "" when name is installed
+		wantErr string
+	}{
+		{name: "vercel"},
+		{name: "fly"},
+		{name: "netlify", wantErr: `unknown provider "netlify" (installed: fly, vercel)`},
+		{name: "../vercel", wantErr: `invalid provider "../vercel"`},
+		{name: "", wantErr: "invalid provider"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, err := b.ParseProvider(tt.name)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("ParseProvider(%q) error = %v, want %q", tt.name, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || provider != Provider(tt.name) {
+				t.Errorf("ParseProvider(%q) = %q, %v, want it accepted", tt.name, provider, err)
+			}
+		})
+	}
+}
+
+func TestExecuteInstalledProvider(t *testing.T) {
+	b, _ := scriptBridge(t, `cat > /dev/null
+echo '{"ok":true,"data":{"project":{"id":"p1","name":"shop","domain":""},"build":{"command":"","output_dir":""},"env":[]}}'
+`, "fly")
+
+	data, err := b.FetchConfig(context.Background(), FetchConfigParams{Provider: "fly", Token: "tok_1"})
+	if err != nil {
+		t.Fatalf("FetchConfig(fly) error: %v", err)
+	}
+	if data.Project.ID != "p1" {
+		t.Errorf("FetchConfig(fly) project = %+v, want p1", data.Project)
+	}
+}
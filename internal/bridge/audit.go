@@ -0,0 +1,167 @@
+package bridge
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/johnhorton/deploy-tunnel/internal/redact"
+)
+
+// maxAuditLogSize is the size past which the audit log is rotated to <path>.1
+const maxAuditLogSize = 10 << 20
+
+// sensitiveKeys are params fields holding credentials, which are kept out of
+// the audit log and adapter error messages
+var sensitiveKeys = map[string]bool{
+	"token":         true,
+	"refresh_token": true,
+	"secret":        true,
+	"client_secret": true,
+	"password":      true,
+	"api_key":       true,
+}
+
+// auditLog appends one JSON line per adapter call
+type auditLog struct {
+	path string
+	mu   sync.Mutex
+}
+
+// auditEntry is one line of the audit log
+type auditEntry struct {
+	Time       time.Time       `json:"ts"`
+	Provider   Provider        `json:"provider"`
+	Verb       string          `json:"verb"`
+	Params     json.RawMessage `json:"params,omitempty"`
+	DurationMs int64           `json:"duration_ms"`
+	// Result is "OK" or the error code the call failed with
+	Result string `json:"result"`
+}
+
+// SetAuditLog appends a line to path for every Execute call, recording the
+// provider, verb, redacted params, duration, and result code. The file is
+// rotated to <path>.1 past 10MB. An empty path turns auditing off. Write
+// failures never fail the adapter call.
+func (b *Bridge) SetAuditLog(path string) {
+	if path == "" {
+		b.audit = nil
+		return
+	}
+	b.audit = &auditLog{path: path}
+}
+
+// record writes the entry for one Execute call
+func (a *auditLog) record(provider Provider, verb string, stdinData []byte, started time.Time, err error) {
+	entry := auditEntry{
+		Time:       started.UTC(),
+		Provider:   provider,
+		Verb:       verb,
+		Params:     redactParams(stdinData),
+		DurationMs: time.Since(started).Milliseconds(),
+		Result:     resultCode(err),
+	}
+	line, marshalErr := json.Marshal(entry)
+	if marshalErr != nil {
+		return
+	}
+	line = append(line, '\n')
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if info, statErr := os.Stat(a.path); statErr == nil && info.Size()+int64(len(line)) > maxAuditLogSize {
+		os.Rename(a.path, a.path+".1")
+	}
+	f, openErr := os.OpenFile(a.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if openErr != nil {
+		return
+	}
+	defer f.Close()
+	f.Write(line)
+}
+
+// resultCode is "OK" for success, otherwise the bridge error code
+func resultCode(err error) string {
+	if err == nil {
+		return "OK"
+	}
+	var bridgeErr *BridgeError
+	if errors.As(err, &bridgeErr) {
+		return string(bridgeErr.Code)
+	}
+	return string(ErrUnknown)
+}
+
+// redactParams returns params JSON with credentials and env var values
+// replaced, or nil when there are no params
+func redactParams(data []byte) json.RawMessage {
+	if len(data) == 0 {
+		return nil
+	}
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil
+	}
+	out, err := json.Marshal(redactValue(v))
+	if err != nil {
+		return nil
+	}
+	return out
+}
+
+func redactValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, val := range t {
+			switch {
+			case sensitiveKeys[k]:
+				if s, ok := val.(string); ok && s != "" {
+					t[k] = redact.Mask
+				}
+			case k == "env":
+				// deploy:preview's env is a key -> value map
+				if m, ok := val.(map[string]interface{}); ok {
+					for key := range m {
+						m[key] = redact.Mask
+					}
+				}
+			case k == "env_vars":
+				// sync:env's env_vars is a list of {key, value, target}
+				if list, ok := val.([]interface{}); ok {
+					for _, item := range list {
+						if e, ok := item.(map[string]interface{}); ok {
+							if _, has := e["value"]; has {
+								e["value"] = redact.Mask
+							}
+						}
+					}
+				}
+			default:
+				t[k] = redactValue(val)
+			}
+		}
+	case []interface{}:
+		for i := range t {
+			t[i] = redactValue(t[i])
+		}
+	}
+	return v
+}
+
+// paramSecrets returns the credential values in params JSON
+func paramSecrets(data []byte) []string {
+	var params map[string]interface{}
+	if json.Unmarshal(data, &params) != nil {
+		return nil
+	}
+	var values []string
+	for k, v := range params {
+		if s, ok := v.(string); ok && sensitiveKeys[k] && s != "" {
+			values = append(values, s)
+		}
+	}
+	return values
+}
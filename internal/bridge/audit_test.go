@@ -0,0 +1,124 @@
+package bridge
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// readAudit returns the audit log's entries, one per line
+func readAudit(t *testing.T, path string) []auditEntry {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var entries []auditEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry auditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("audit line isn't JSON: %v\n%s", err, scanner.Text())
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestAuditLog(t *testing.T) {
+	const (
+		token  = "tok_audit_0123456789"
+		secret = "sk_live_env_secret"
+	)
+	b, _ := scriptBridge(t, `input=$(cat)
+case "$3" in
+capabilities) echo '{"ok":true,"data":{"adapter_name":"fake","adapter_version":"1.0.0","auth_type":"token"}}' ;;
+fetch:config) echo '{"ok":false,"error":{"code":"AUTH_FAILED","message":"bad token"}}' ;;
+*) echo '{"ok":true,"data":{"synced":1,"failed":[]}}' ;;
+esac
+`, "vercel")
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	b.SetAuditLog(path)
+
+	ctx := context.Background()
+	if _, err := b.SyncEnv(ctx, SyncEnvParams{Provider: "vercel", Token: token, EnvVars: []EnvVar{{Key: "API_KEY", Value: secret}}}); err != nil {
+		t.Fatalf("SyncEnv: %v", err)
+	}
+	if _, err := b.FetchConfig(ctx, FetchConfigParams{Provider: "vercel", Token: token}); err == nil {
+		t.Fatal("FetchConfig succeeded, want AUTH_FAILED")
+	}
+	// Rejected locally, but still an attempted call
+	if _, err := b.DnsUpdate(ctx, DnsUpdateParams{Provider: "vercel", Token: token}); err == nil {
+		t.Fatal("DnsUpdate with missing fields succeeded")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, leaked := range []string{token, secret} {
+		if strings.Contains(string(data), leaked) {
+			t.Errorf("audit log contains %q:\n%s", leaked, data)
+		}
+	}
+
+	entries := readAudit(t, path)
+	// The first call also fetched the capabilities, itself an Execute
+	want := []struct{ verb, result string }{
+		{"capabilities", "OK"},
+		{"sync:env", "OK"},
+		{"fetch:config", string(ErrAuthFailed)},
+		{"dns:update", string(ErrInvalidParams)},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("audit log has %d lines, want one per call (%d):\n%s", len(entries), len(want), data)
+	}
+	for i, w := range want {
+		e := entries[i]
+		if e.Provider != "vercel" || e.Verb != w.verb || e.Result != w.result {
+			t.Errorf("entry %d = %s %s %s, want vercel %s %s", i, e.Provider, e.Verb, e.Result, w.verb, w.result)
+		}
+	}
+
+	var params struct {
+		Token   string `json:"token"`
+		EnvVars []struct {
+			Key   string `json:"key"`
+			Value string `json:"value"`
+		} `json:"env_vars"`
+	}
+	if err := json.Unmarshal(entries[1].Params, &params); err != nil {
+		t.Fatal(err)
+	}
+	if params.Token != "[REDACTED]" || len(params.EnvVars) != 1 || params.EnvVars[0].Key != "API_KEY" || params.EnvVars[0].Value != "[REDACTED]" {
+		t.Errorf("sync:env params = %s, want the token and value redacted and the key kept", entries[1].Params)
+	}
+}
+
+func TestAuditLogRotates(t *testing.T) {
+	b, _ := scriptBridge(t, `cat > /dev/null
+echo '{"ok":true,"data":{}}'
+`, "vercel")
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	full := make([]byte, maxAuditLogSize)
+	if err := os.WriteFile(path, full, 0600); err != nil {
+		t.Fatal(err)
+	}
+	b.SetAuditLog(path)
+
+	if _, err := b.Execute(context.Background(), "vercel", "capabilities", nil); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Stat(path + ".1"); err != nil || info.Size() != maxAuditLogSize {
+		t.Errorf("full log not rotated to %s.1 (%v)", path, err)
+	}
+	if entries := readAudit(t, path); len(entries) != 1 {
+		t.Errorf("new log has %d lines, want 1", len(entries))
+	}
+}
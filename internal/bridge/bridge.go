@@ -8,18 +8,44 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"time"
+	"unicode/utf8"
+
+	"github.com/johnhorton/deploy-tunnel/internal/redact"
 )
 
 const (
 	defaultTimeout = 30 * time.Second
+	defaultRuntime = "bun"
 	maxRetries     = 3
+
+	// maxOutputExcerpt caps how much adapter output an error message quotes
+	maxOutputExcerpt = 500
+)
+
+// Bridge protocol versions this binary speaks (see bridge_spec.json)
+const (
+	MinProtocolVersion = "1.0.0"
+	MaxProtocolVersion = "1.0.0"
 )
 
 // Bridge manages communication with Bun adapters
 type Bridge struct {
 	adaptersPath string
 	timeout      time.Duration
+	runtime      string
+
+	// tokens enables refreshing expired tokens; see WithAutoRefresh
+	tokens TokenStore
+	// audit records every call when set; see SetAuditLog
+	audit *auditLog
+	// retries is how many times a rate limited call is retried; see WithRetries
+	retries int
+	// caps caches each adapter's capabilities; see Supports
+	caps capsCache
+	// cache reuses read-only responses; see SetCacheTTL
+	cache responseCache
 }
 
 // NewBridge creates a new Bridge instance
@@ -33,6 +59,8 @@ func NewBridge(adaptersPath string) *Bridge {
 	return &Bridge{
 		adaptersPath: adaptersPath,
 		timeout:      defaultTimeout,
+		runtime:      defaultRuntime,
+		cache:        responseCache{ttl: defaultCacheTTL},
 	}
 }
 
@@ -41,30 +69,79 @@ func (b *Bridge) SetTimeout(timeout time.Duration) {
 	b.timeout = timeout
 }
 
+// SetRuntime configures the Bun executable used to run adapters
+func (b *Bridge) SetRuntime(runtime string) {
+	b.runtime = runtime
+}
+
 // Execute runs an adapter command and returns the parsed response
-func (b *Bridge) Execute(ctx context.Context, provider Provider, verb string, params interface{}) (*Response, error) {
-	adapterPath := filepath.Join(b.adaptersPath, string(provider), "index.ts")
+func (b *Bridge) Execute(ctx context.Context, provider Provider, verb string, params interface{}) (resp *Response, err error) {
+	var stdinData []byte
+	if b.audit != nil {
+		started := time.Now()
+		defer func() { b.audit.record(provider, verb, stdinData, started, err) }()
+	}
 
-	// Check if adapter exists
-	if _, err := os.Stat(adapterPath); os.IsNotExist(err) {
-		return nil, fmt.Errorf("adapter not found: %s", provider)
+	// The provider names a directory under the adapters path
+	if err := ValidProviderName(string(provider)); err != nil {
+		return nil, invalidParams("%s", err)
 	}
 
-	// Marshal params to JSON
-	var stdinData []byte
-	var err error
+	// Marshal params to JSON, rejecting invalid ones before spawning anything
 	if params != nil {
 		stdinData, err = json.Marshal(params)
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal params: %w", err)
 		}
+		if err := validateParams(provider, verb, params, stdinData); err != nil {
+			return nil, err
+		}
+	}
+
+	adapterPath := filepath.Join(b.adaptersPath, string(provider), "index.ts")
+
+	// Check if adapter exists
+	if _, err := os.Stat(adapterPath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("adapter not found: %s", provider)
+	}
+
+	key := newCacheKey(provider, verb, stdinData)
+	if cachedVerbs[verb] && ctx.Value(noCacheKey{}) == nil {
+		if cached, ok := b.cache.get(key); ok {
+			return cached, nil
+		}
+	}
+	if mutatingVerbs[verb] {
+		// Even a failed change may have partly applied, so drop reads either way
+		defer b.cache.invalidate(provider)
+	}
+
+	if err := b.requireVerb(ctx, provider, verb); err != nil {
+		return nil, err
+	}
+
+	resp, err = b.runWithRetries(ctx, provider, adapterPath, verb, stdinData)
+	if err != nil && b.shouldRefresh(ctx, verb, err) {
+		// One refresh per call; the retry's error is returned as is
+		if retryData, ok := b.refreshToken(ctx, provider, stdinData); ok {
+			resp, err = b.runWithRetries(ctx, provider, adapterPath, verb, retryData)
+		}
+	}
+	if err == nil && cachedVerbs[verb] {
+		b.cache.put(key, resp)
 	}
+	return resp, err
+}
 
-	// Create command with timeout context
-	timeoutCtx, cancel := context.WithTimeout(ctx, b.timeout)
+// run executes one adapter invocation
+func (b *Bridge) run(ctx context.Context, adapterPath, verb string, stdinData []byte) (*Response, error) {
+	// Create command with timeout context, never outliving the caller's
+	timeout := b.callTimeout(ctx)
+	timeoutCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
+	started := time.Now()
 
-	cmd := exec.CommandContext(timeoutCtx, "bun", "run", adapterPath, verb)
+	cmd := exec.CommandContext(timeoutCtx, b.runtime, "run", adapterPath, verb)
 	cmd.Stdin = bytes.NewReader(stdinData)
 
 	var stdout, stderr bytes.Buffer
@@ -72,34 +149,78 @@ func (b *Bridge) Execute(ctx context.Context, provider Provider, verb string, pa
 	cmd.Stderr = &stderr
 
 	// Execute command
-	err = cmd.Run()
-	if err != nil {
-		if timeoutCtx.Err() == context.DeadlineExceeded {
-			return nil, &BridgeError{
-				Code:        ErrTimeout,
-				Message:     fmt.Sprintf("adapter command timed out after %s", b.timeout),
-				Recoverable: true,
-			}
+	if err := cmd.Run(); err != nil {
+		if timeoutErr := b.timeoutError(ctx, timeoutCtx, timeout, time.Since(started)); timeoutErr != nil {
+			return nil, timeoutErr
+		}
+		if runtimeMissing(cmd, err) {
+			return nil, runtimeMissingError(b.runtime)
+		}
+		// Adapters can echo what they were sent, so scrub it before it surfaces
+		return nil, fmt.Errorf("adapter execution failed: %w (stderr: %s)", err, outputExcerpt(stderr.Bytes(), stdinData))
+	}
+
+	// Binary output would otherwise surface as a baffling JSON syntax error
+	if !utf8.Valid(stdout.Bytes()) {
+		return nil, &BridgeError{
+			Code: ErrUnknown,
+			Message: fmt.Sprintf("adapter produced non-text output (%d bytes of invalid UTF-8); it should print only its JSON response to stdout (output: %s)",
+				stdout.Len(), outputExcerpt(stdout.Bytes(), stdinData)),
 		}
-		return nil, fmt.Errorf("adapter execution failed: %w (stderr: %s)", err, stderr.String())
 	}
 
 	// Parse response
 	var response Response
 	if err := json.Unmarshal(stdout.Bytes(), &response); err != nil {
-		return nil, fmt.Errorf("failed to parse adapter response: %w (output: %s)", err, stdout.String())
+		return nil, fmt.Errorf("failed to parse adapter response: %w (output: %s)", err, outputExcerpt(stdout.Bytes(), stdinData))
 	}
 
 	// Check for error in response
 	if !response.OK && response.Error != nil {
+		response.Error.Message = redact.String(response.Error.Message, paramSecrets(stdinData)...)
 		return &response, response.Error
 	}
 
 	return &response, nil
 }
 
-// Capabilities fetches adapter capabilities
+// outputExcerpt prepares adapter output for an error message: secrets from
+// the params are scrubbed, anything that isn't printable text is escaped, and
+// the result is cut to maxOutputExcerpt bytes so a huge dump can't flood the
+// terminal
+func outputExcerpt(output, stdinData []byte) string {
+	// Scrub before cutting, so a secret straddling the cut can't leak a prefix
+	text := redact.String(string(output), paramSecrets(stdinData)...)
+
+	truncated := 0
+	if len(text) > maxOutputExcerpt {
+		cut := maxOutputExcerpt
+		for cut > 0 && !utf8.RuneStart(text[cut]) {
+			cut--
+		}
+		truncated = len(text) - cut
+		text = text[:cut]
+	}
+
+	if !utf8.ValidString(text) {
+		// Escape invalid bytes as \xNN so the message stays readable text
+		quoted := strconv.QuoteToGraphic(text)
+		text = quoted[1 : len(quoted)-1]
+	}
+
+	if truncated > 0 {
+		text += fmt.Sprintf("... (%d more bytes)", truncated)
+	}
+	return text
+}
+
+// Capabilities fetches adapter capabilities, asking each adapter only once
 func (b *Bridge) Capabilities(ctx context.Context, provider Provider) (*CapabilitiesData, error) {
+	if cached, ok := b.caps.get(provider); ok {
+		caps := *cached
+		return &caps, nil
+	}
+
 	resp, err := b.Execute(ctx, provider, "capabilities", nil)
 	if err != nil {
 		return nil, err
@@ -110,6 +231,7 @@ func (b *Bridge) Capabilities(ctx context.Context, provider Provider) (*Capabili
 		return nil, fmt.Errorf("failed to parse capabilities: %w", err)
 	}
 
+	b.caps.put(provider, &caps)
 	return &caps, nil
 }
 
@@ -128,6 +250,21 @@ func (b *Bridge) AuthStart(ctx context.Context, params AuthStartParams) (*AuthSt
 	return &data, nil
 }
 
+// AuthRefresh exchanges a refresh token for a new access token
+func (b *Bridge) AuthRefresh(ctx context.Context, params AuthRefreshParams) (*AuthRefreshData, error) {
+	resp, err := b.Execute(ctx, params.Provider, "auth:refresh", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var data AuthRefreshData
+	if err := mapToStruct(resp.Data, &data); err != nil {
+		return nil, fmt.Errorf("failed to parse refresh data: %w", err)
+	}
+
+	return &data, nil
+}
+
 // FetchConfig retrieves project configuration
 func (b *Bridge) FetchConfig(ctx context.Context, params FetchConfigParams) (*FetchConfigData, error) {
 	resp, err := b.Execute(ctx, params.Provider, "fetch:config", params)
@@ -173,6 +310,21 @@ func (b *Bridge) DeployPreview(ctx context.Context, params DeployPreviewParams)
 	return &data, nil
 }
 
+// DeployStatus gets the current state of a deployment
+func (b *Bridge) DeployStatus(ctx context.Context, params DeployStatusParams) (*DeployPreviewData, error) {
+	resp, err := b.Execute(ctx, params.Provider, "deploy:status", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var data DeployPreviewData
+	if err := mapToStruct(resp.Data, &data); err != nil {
+		return nil, fmt.Errorf("failed to parse deploy status: %w", err)
+	}
+
+	return &data, nil
+}
+
 // DnsUpdate updates a DNS record
 func (b *Bridge) DnsUpdate(ctx context.Context, params DnsUpdateParams) (*DnsUpdateData, error) {
 	resp, err := b.Execute(ctx, params.Provider, "dns:update", params)
@@ -203,6 +355,38 @@ func (b *Bridge) DnsRollback(ctx context.Context, params DnsRollbackParams) (*Dn
 	return &data, nil
 }
 
+// ListDomains lists the domains on the provider's account
+func (b *Bridge) ListDomains(ctx context.Context, provider Provider, token string) ([]Domain, error) {
+	params := DomainsListParams{Provider: provider, Token: token}
+	resp, err := b.Execute(ctx, provider, "domains:list", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var data DomainsListData
+	if err := mapToStruct(resp.Data, &data); err != nil {
+		return nil, fmt.Errorf("failed to parse domains: %w", err)
+	}
+
+	return data.Domains, nil
+}
+
+// ListDnsRecords lists the records currently in domain's zone
+func (b *Bridge) ListDnsRecords(ctx context.Context, provider Provider, token, domain string) ([]DnsRecord, error) {
+	params := DnsListParams{Provider: provider, Token: token, Domain: domain}
+	resp, err := b.Execute(ctx, provider, "dns:list", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var data DnsListData
+	if err := mapToStruct(resp.Data, &data); err != nil {
+		return nil, fmt.Errorf("failed to parse DNS records: %w", err)
+	}
+
+	return data.Records, nil
+}
+
 // mapToStruct converts a map to a struct using JSON marshaling
 func mapToStruct(m map[string]interface{}, v interface{}) error {
 	data, err := json.Marshal(m)
@@ -0,0 +1,212 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// scriptBridge installs an empty adapter for each provider and runs them all
+// with script as the runtime, called as: script run <adapter path> <verb>,
+// with the params on stdin. Every verb is appended to the returned log, which
+// the script can read as $calls.
+func scriptBridge(t *testing.T, script string, providers ...Provider) (*Bridge, string) {
+	t.Helper()
+	dir := t.TempDir()
+	for _, provider := range providers {
+		if err := os.MkdirAll(filepath.Join(dir, string(provider)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, string(provider), "index.ts"), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	calls := filepath.Join(t.TempDir(), "calls")
+	runtime := filepath.Join(t.TempDir(), "runtime")
+	body := "#!/bin/sh\ncalls='" + calls + "'\necho \"$3\" >> \"$calls\"\n" + script
+	if err := os.WriteFile(runtime, []byte(body), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	b := NewBridge(dir)
+	b.SetRuntime(runtime)
+	return b, calls
+}
+
+// calledVerbs returns the verbs logged by a scriptBridge runtime, in order
+func calledVerbs(t *testing.T, calls string) []string {
+	t.Helper()
+	data, err := os.ReadFile(calls)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Fields(string(data))
+}
+
+func TestOutputExcerpt(t *testing.T) {
+	const token = "tok_excerpt_0123456789"
+	params := []byte(`{"provider":"vercel","token":"` + token + `"}`)
+	long := strings.Repeat("x", maxOutputExcerpt-5)
+
+	tests := []struct {
+		name   string
+		output string
+		want   string
+	}{
+		{name: "plain", output: "adapter crashed", want: "adapter crashed"},
+		{name: "token in params", output: "rejected " + token, want: "rejected [REDACTED]"},
+		{name: "invalid bytes escaped", output: "bad \xff\xfe byte", want: `bad \xff\xfe byte`},
+		{name: "truncated", output: strings.Repeat("y", maxOutputExcerpt+10), want: strings.Repeat("y", maxOutputExcerpt) + "... (10 more bytes)"},
+		// Scrubbed before cutting, so no prefix of the token survives
+		{name: "token across the cut", output: long + token, want: long + "[REDA... (5 more bytes)"},
+		{name: "cut between runes", output: strings.Repeat("x", maxOutputExcerpt-1) + "é", want: strings.Repeat("x", maxOutputExcerpt-1) + "... (2 more bytes)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := outputExcerpt([]byte(tt.output), params); got != tt.want {
+				t.Errorf("outputExcerpt() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecuteRedactsStderr(t *testing.T) {
+	const token = "tok_stderr_0123456789"
+	b, _ := scriptBridge(t, `input=$(cat)
+echo "request failed: $input" >&2
+exit 1
+`, "vercel")
+
+	_, err := b.FetchConfig(context.Background(), FetchConfigParams{Provider: "vercel", Token: token})
+	if err == nil {
+		t.Fatal("FetchConfig succeeded with a failing adapter")
+	}
+	if strings.Contains(err.Error(), token) || !strings.Contains(err.Error(), "[REDACTED]") {
+		t.Errorf("error = %q, want the echoed token masked", err)
+	}
+}
+
+// verbsScript answers capabilities with verbs as the supported ones (none
+// listed when verbs is empty) and domains:list with two domains
+func verbsScript(verbs string) string {
+	return fmt.Sprintf(`cat > /dev/null
+case "$3" in
+capabilities) echo '{"ok":true,"data":{"adapter_name":"fake","adapter_version":"1.0.0","auth_type":"token","supported_verbs":[%s]}}' ;;
+domains:list) echo '{"ok":true,"data":{"domains":[{"name":"example.com","verified":true,"project_id":"prj_1"},{"name":"parked.dev","verified":false}]}}' ;;
+*) echo '{"ok":true,"data":{}}' ;;
+esac
+`, verbs)
+}
+
+func TestListDomains(t *testing.T) {
+	b, calls := scriptBridge(t, verbsScript(`"capabilities","domains:list"`), "vercel")
+
+	domains, err := b.ListDomains(context.Background(), "vercel", "tok_domains")
+	if err != nil {
+		t.Fatalf("ListDomains() error: %v", err)
+	}
+	want := []Domain{
+		{Name: "example.com", Verified: true, ProjectID: "prj_1"},
+		{Name: "parked.dev"},
+	}
+	if fmt.Sprint(domains) != fmt.Sprint(want) {
+		t.Errorf("ListDomains() = %+v, want %+v", domains, want)
+	}
+	if got := calledVerbs(t, calls); fmt.Sprint(got) != "[capabilities domains:list]" {
+		t.Errorf("called %v, want capabilities then domains:list", got)
+	}
+
+	if _, err := b.ListDomains(context.Background(), "vercel", ""); err == nil || !strings.Contains(err.Error(), "token") {
+		t.Errorf("ListDomains() without a token error = %v, want it rejected", err)
+	}
+}
+
+func TestRequireVerb(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		// wantRun is whether domains:list is spawned
+		wantRun bool
+	}{
+		{name: "listed", script: verbsScript(`"capabilities","domains:list"`), wantRun: true},
+		{name: "not listed", script: verbsScript(`"capabilities","dns:update"`)},
+		{name: "no verbs listed", script: verbsScript(""), wantRun: true},
+		{
+			name: "capabilities fail",
+			script: `cat > /dev/null
+case "$3" in
+capabilities) echo 'not json' ;;
+*) echo '{"ok":true,"data":{}}' ;;
+esac
+`,
+			wantRun: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, calls := scriptBridge(t, tt.script, "vercel")
+
+			_, err := b.ListDomains(context.Background(), "vercel", "tok_verbs")
+			ran := false
+			for _, verb := range calledVerbs(t, calls) {
+				ran = ran || verb == "domains:list"
+			}
+			if ran != tt.wantRun {
+				t.Errorf("domains:list spawned = %v, want %v", ran, tt.wantRun)
+			}
+			if tt.wantRun {
+				if err != nil {
+					t.Errorf("ListDomains() error: %v", err)
+				}
+				return
+			}
+			var bridgeErr *BridgeError
+			if !errors.As(err, &bridgeErr) || bridgeErr.Code != ErrUnsupported {
+				t.Errorf("ListDomains() error = %v, want %s", err, ErrUnsupported)
+			}
+		})
+	}
+}
+
+func TestExecuteNonUTF8Output(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		// wantUnknown is whether the error is an UNKNOWN BridgeError
+		wantUnknown bool
+		want        string
+	}{
+		{name: "binary", output: `\377\376PNG`, wantUnknown: true, want: `non-text output (5 bytes of invalid UTF-8); it should print only its JSON response to stdout (output: \xff\xfePNG`},
+		{name: "text that isn't JSON", output: `Listening on port 3000`, want: "failed to parse adapter response"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, _ := scriptBridge(t, `cat > /dev/null
+case "$3" in
+capabilities) echo '{"ok":true,"data":{"adapter_name":"fake","adapter_version":"1.0.0","auth_type":"token"}}' ;;
+*) printf '`+tt.output+`' ;;
+esac
+`, "vercel")
+
+			_, err := b.FetchConfig(context.Background(), FetchConfigParams{Provider: "vercel", Token: "tok_binary"})
+			var bridgeErr *BridgeError
+			if isUnknown := errors.As(err, &bridgeErr) && bridgeErr.Code == ErrUnknown; isUnknown != tt.wantUnknown {
+				t.Errorf("error = %v, want UNKNOWN = %v", err, tt.wantUnknown)
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package bridge
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// defaultCacheTTL keeps cached reads short-lived so config edits made
+// outside dt show up quickly
+const defaultCacheTTL = 60 * time.Second
+
+// cachedVerbs are read-only, so repeating one with the same params within
+// the TTL reuses the last response
+var cachedVerbs = map[string]bool{
+	"capabilities": true,
+	"fetch:config": true,
+	"domains:list": true,
+	"dns:list":     true,
+}
+
+// mutatingVerbs change the provider, so they drop its cached responses
+var mutatingVerbs = map[string]bool{
+	"sync:env":       true,
+	"deploy:preview": true,
+	"dns:update":     true,
+	"dns:rollback":   true,
+}
+
+type noCacheKey struct{}
+
+// WithoutCache makes calls made with ctx always run the adapter, e.g. to
+// measure a provider's latency. Their responses still refresh the cache.
+func WithoutCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noCacheKey{}, true)
+}
+
+type cacheKey struct {
+	provider Provider
+	verb     string
+	// params is a hash of the params JSON, so tokens aren't kept as keys
+	params string
+}
+
+type cacheEntry struct {
+	// response is stored as JSON so each hit gets its own copy
+	response []byte
+	expires  time.Time
+}
+
+// responseCache holds successful responses to cachedVerbs. Errors aren't
+// cached so a failed call is tried again next time.
+type responseCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[cacheKey]cacheEntry
+}
+
+func newCacheKey(provider Provider, verb string, stdinData []byte) cacheKey {
+	sum := sha256.Sum256(stdinData)
+	return cacheKey{provider: provider, verb: verb, params: hex.EncodeToString(sum[:])}
+}
+
+func (c *responseCache) get(key cacheKey) (*Response, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !time.Now().Before(entry.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+
+	var resp Response
+	if err := json.Unmarshal(entry.response, &resp); err != nil {
+		return nil, false
+	}
+	return &resp, true
+}
+
+func (c *responseCache) put(key cacheKey, resp *Response) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.ttl <= 0 {
+		return
+	}
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return
+	}
+	if c.entries == nil {
+		c.entries = make(map[cacheKey]cacheEntry)
+	}
+	c.entries[key] = cacheEntry{response: data, expires: time.Now().Add(c.ttl)}
+}
+
+// invalidate drops every cached response from provider
+func (c *responseCache) invalidate(provider Provider) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.entries {
+		if key.provider == provider {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// SetCacheTTL sets how long responses to read-only verbs (capabilities,
+// fetch:config, domains:list, dns:list) are reused, dropping anything already cached;
+// zero or less turns caching off
+func (b *Bridge) SetCacheTTL(ttl time.Duration) {
+	b.cache.mu.Lock()
+	defer b.cache.mu.Unlock()
+	b.cache.ttl = ttl
+	b.cache.entries = nil
+}
+
+// InvalidateCache forgets every cached response and the capabilities of
+// provider's adapter, e.g. after the adapter was reinstalled or the project
+// was changed outside dt
+func (b *Bridge) InvalidateCache(provider Provider) {
+	b.cache.invalidate(provider)
+	b.caps.forget(provider)
+}
@@ -0,0 +1,115 @@
+package bridge
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// cacheScript answers every verb, failing the first fetch:config when the
+// adapter is asked for project "flaky"
+const cacheScript = `input=$(cat)
+case "$3" in
+capabilities) echo '{"ok":true,"data":{"adapter_name":"fake","adapter_version":"1.0.0","auth_type":"token"}}' ;;
+fetch:config)
+	case "$input" in *flaky*)
+		if [ "$(grep -c '^fetch:config$' "$calls")" -le 1 ]; then
+			echo '{"ok":false,"error":{"code":"PROVIDER_ERROR","message":"try again","recoverable":false}}'
+			exit 0
+		fi ;;
+	esac
+	echo '{"ok":true,"data":{"project":{"id":"prj_1","name":"app"},"env":[]}}' ;;
+*) echo '{"ok":true,"data":{"record_id":"rec_1"}}' ;;
+esac
+`
+
+func TestResponseCache(t *testing.T) {
+	fetch := func(b *Bridge, ctx context.Context, token, project string) {
+		b.FetchConfig(ctx, FetchConfigParams{Provider: "vercel", Token: token, ProjectID: project})
+	}
+	update := func(b *Bridge) {
+		b.DnsUpdate(context.Background(), DnsUpdateParams{Provider: "vercel", Token: "tok_a", Domain: "example.com", RecordType: "A", RecordName: "@", RecordValue: "203.0.113.10"})
+	}
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		ttl  time.Duration
+		run  func(b *Bridge)
+		// wantRuns counts fetch:config runs
+		wantRuns int
+	}{
+		{name: "hit", run: func(b *Bridge) { fetch(b, ctx, "tok_a", "p"); fetch(b, ctx, "tok_a", "p") }, wantRuns: 1},
+		{name: "other params", run: func(b *Bridge) { fetch(b, ctx, "tok_a", "p"); fetch(b, ctx, "tok_b", "p") }, wantRuns: 2},
+		{
+			name: "expired",
+			ttl:  50 * time.Millisecond,
+			run: func(b *Bridge) {
+				fetch(b, ctx, "tok_a", "p")
+				time.Sleep(80 * time.Millisecond)
+				fetch(b, ctx, "tok_a", "p")
+			},
+			wantRuns: 2,
+		},
+		{name: "disabled", ttl: -1, run: func(b *Bridge) { fetch(b, ctx, "tok_a", "p"); fetch(b, ctx, "tok_a", "p") }, wantRuns: 2},
+		{name: "mutation invalidates", run: func(b *Bridge) { fetch(b, ctx, "tok_a", "p"); update(b); fetch(b, ctx, "tok_a", "p") }, wantRuns: 2},
+		{
+			name: "explicit invalidation",
+			run: func(b *Bridge) {
+				fetch(b, ctx, "tok_a", "p")
+				b.InvalidateCache("vercel")
+				fetch(b, ctx, "tok_a", "p")
+			},
+			wantRuns: 2,
+		},
+		{
+			name: "bypassed but refreshed",
+			run: func(b *Bridge) {
+				fetch(b, ctx, "tok_a", "p")
+				fetch(b, WithoutCache(ctx), "tok_a", "p")
+				fetch(b, ctx, "tok_a", "p")
+			},
+			wantRuns: 2,
+		},
+		{name: "errors not cached", run: func(b *Bridge) { fetch(b, ctx, "tok_a", "flaky"); fetch(b, ctx, "tok_a", "flaky") }, wantRuns: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, calls := scriptBridge(t, cacheScript, "vercel")
+			if tt.ttl != 0 {
+				b.SetCacheTTL(tt.ttl)
+			}
+
+			tt.run(b)
+			runs := 0
+			for _, verb := range calledVerbs(t, calls) {
+				if verb == "fetch:config" {
+					runs++
+				}
+			}
+			if runs != tt.wantRuns {
+				t.Errorf("fetch:config ran %d times, want %d", runs, tt.wantRuns)
+			}
+		})
+	}
+}
+
+func TestCachedResponsesAreCopies(t *testing.T) {
+	b, _ := scriptBridge(t, cacheScript, "vercel")
+	params := FetchConfigParams{Provider: "vercel", Token: "tok_a", ProjectID: "p"}
+
+	first, err := b.FetchConfig(context.Background(), params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first.Project.Name = "changed by the caller"
+
+	second, err := b.FetchConfig(context.Background(), params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.Project.Name != "app" {
+		t.Errorf("cached project name = %q, want the adapter's app", second.Project.Name)
+	}
+}
@@ -0,0 +1,139 @@
+package bridge
+
+import "strings"
+
+// What a planned DNS update does to a record, in a DnsChange
+const (
+	DnsChangeCreate    = "create"
+	DnsChangeUpdate    = "update"
+	DnsChangeUnchanged = "unchanged"
+	// DnsChangeRemove is a record the update displaces: a CNAME can't share
+	// its name with other records, so providers replace or reject them
+	DnsChangeRemove = "remove"
+)
+
+// DnsChange is one record a planned update creates, changes, or displaces.
+// Names are fully qualified. Warning is set for changes that are easy to
+// regret, such as moving the apex address or dropping mail routing.
+type DnsChange struct {
+	Action   string `json:"action"`
+	Type     string `json:"type"`
+	Name     string `json:"name"`
+	OldValue string `json:"old_value,omitempty"`
+	NewValue string `json:"new_value,omitempty"`
+	OldTTL   int    `json:"old_ttl,omitempty"`
+	NewTTL   int    `json:"new_ttl,omitempty"`
+	Warning  string `json:"warning,omitempty"`
+}
+
+// Dangerous reports whether the change deserves a second look
+func (c DnsChange) Dangerous() bool {
+	return c.Warning != ""
+}
+
+// DnsDiff compares the records in domain's zone with the updates planned
+// for it, in plan order. Each planned record is a create, update, or
+// unchanged; records a planned CNAME would displace (or a CNAME displaced by
+// another type) follow it as removals.
+func DnsDiff(domain string, current []DnsRecord, planned []DnsUpdateParams) []DnsChange {
+	byName := make(map[string][]DnsRecord)
+	for _, r := range current {
+		name := qualifyName(r.Name, domain)
+		byName[name] = append(byName[name], r)
+	}
+
+	changes := []DnsChange{}
+	removed := make(map[DnsRecord]bool)
+	for _, p := range planned {
+		name := qualifyName(p.RecordName, domain)
+		recordType := strings.ToUpper(p.RecordType)
+
+		var same, others []DnsRecord
+		for _, r := range byName[name] {
+			if strings.EqualFold(r.Type, recordType) {
+				same = append(same, r)
+			} else {
+				others = append(others, r)
+			}
+		}
+
+		change := DnsChange{Type: recordType, Name: name, NewValue: p.RecordValue, NewTTL: p.TTL}
+		switch {
+		case len(same) == 0:
+			change.Action = DnsChangeCreate
+		case len(same) == 1 && sameRecordValue(recordType, same[0].Value, p.RecordValue) && (p.TTL == 0 || p.TTL == same[0].TTL):
+			change.Action = DnsChangeUnchanged
+			change.OldValue, change.OldTTL = same[0].Value, same[0].TTL
+		default:
+			change.Action = DnsChangeUpdate
+			values := make([]string, len(same))
+			for i, r := range same {
+				values[i] = r.Value
+			}
+			change.OldValue, change.OldTTL = strings.Join(values, ", "), same[0].TTL
+		}
+		change.Warning = dnsWarning(change, domain)
+		changes = append(changes, change)
+
+		for _, r := range others {
+			if recordType != "CNAME" && !strings.EqualFold(r.Type, "CNAME") {
+				continue
+			}
+			if removed[r] {
+				continue
+			}
+			removed[r] = true
+			removal := DnsChange{
+				Action:   DnsChangeRemove,
+				Type:     strings.ToUpper(r.Type),
+				Name:     name,
+				OldValue: r.Value,
+				OldTTL:   r.TTL,
+			}
+			removal.Warning = dnsWarning(removal, domain)
+			changes = append(changes, removal)
+		}
+	}
+	return changes
+}
+
+// dnsWarning explains why a change is dangerous, or returns ""
+func dnsWarning(c DnsChange, domain string) string {
+	apex := c.Name == qualifyName("@", domain)
+	address := c.Type == "A" || c.Type == "AAAA"
+
+	switch {
+	case c.Action == DnsChangeRemove && c.Type == "MX":
+		return "removes mail routing (MX) for " + c.Name
+	case c.Action == DnsChangeRemove && c.Type == "NS":
+		return "removes the nameserver delegation (NS) for " + c.Name
+	case c.Action == DnsChangeRemove && apex && address:
+		return "removes the apex " + c.Type + " record"
+	case c.Action == DnsChangeUpdate && apex && address && !sameRecordValue(c.Type, c.OldValue, c.NewValue):
+		return "changes the apex " + c.Type + " record"
+	}
+	return ""
+}
+
+// qualifyName makes a record name fully qualified within domain: "@" and ""
+// are the apex, and relative names such as "www" get the domain appended
+func qualifyName(name, domain string) string {
+	name = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(name), "."))
+	domain = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(domain), "."))
+	switch {
+	case name == "" || name == "@":
+		return domain
+	case name == domain || strings.HasSuffix(name, "."+domain):
+		return name
+	}
+	return name + "." + domain
+}
+
+// sameRecordValue compares record values the way DNS does: host names
+// ignore case and a trailing dot, while TXT data is compared exactly
+func sameRecordValue(recordType, a, b string) bool {
+	if strings.EqualFold(recordType, "TXT") {
+		return strings.Trim(a, `"`) == strings.Trim(b, `"`)
+	}
+	return strings.EqualFold(strings.TrimSuffix(a, "."), strings.TrimSuffix(b, "."))
+}
@@ -0,0 +1,115 @@
+package bridge
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// describeChanges renders changes as "action type name old->new [warning]"
+func describeChanges(changes []DnsChange) string {
+	lines := make([]string, len(changes))
+	for i, c := range changes {
+		lines[i] = fmt.Sprintf("%s %s %s %s->%s", c.Action, c.Type, c.Name, c.OldValue, c.NewValue)
+		if c.Dangerous() {
+			lines[i] += " [" + c.Warning + "]"
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestDnsDiff(t *testing.T) {
+	zone := []DnsRecord{
+		{Type: "A", Name: "@", Value: "198.51.100.1", TTL: 300},
+		{Type: "MX", Name: "@", Value: "mx.example.net", TTL: 300},
+		{Type: "CNAME", Name: "www", Value: "Old.Example.net.", TTL: 300},
+		{Type: "A", Name: "api.example.com", Value: "198.51.100.2", TTL: 300},
+		{Type: "TXT", Name: "@", Value: `"v=spf1 -all"`, TTL: 300},
+	}
+
+	tests := []struct {
+		name    string
+		planned []DnsUpdateParams
+		want    string
+	}{
+		{
+			name:    "create",
+			planned: []DnsUpdateParams{{RecordType: "cname", RecordName: "app", RecordValue: "app.netlify.app"}},
+			want:    "create CNAME app.example.com ->app.netlify.app",
+		},
+		{
+			name:    "unchanged ignores case and trailing dot",
+			planned: []DnsUpdateParams{{RecordType: "CNAME", RecordName: "www.example.com.", RecordValue: "old.example.net"}},
+			want:    "unchanged CNAME www.example.com Old.Example.net.->old.example.net",
+		},
+		{
+			name:    "unchanged TXT ignores quotes",
+			planned: []DnsUpdateParams{{RecordType: "TXT", RecordName: "@", RecordValue: "v=spf1 -all"}},
+			want:    `unchanged TXT example.com "v=spf1 -all"->v=spf1 -all`,
+		},
+		{
+			name:    "TTL change is an update",
+			planned: []DnsUpdateParams{{RecordType: "A", RecordName: "api", RecordValue: "198.51.100.2", TTL: 60}},
+			want:    "update A api.example.com 198.51.100.2->198.51.100.2",
+		},
+		{
+			name:    "apex address change warns",
+			planned: []DnsUpdateParams{{RecordType: "A", RecordName: "@", RecordValue: "203.0.113.10"}},
+			want:    "update A example.com 198.51.100.1->203.0.113.10 [changes the apex A record]",
+		},
+		{
+			name:    "apex CNAME displaces the rest",
+			planned: []DnsUpdateParams{{RecordType: "CNAME", RecordName: "@", RecordValue: "app.netlify.app"}},
+			want: "create CNAME example.com ->app.netlify.app\n" +
+				"remove A example.com 198.51.100.1-> [removes the apex A record]\n" +
+				"remove MX example.com mx.example.net-> [removes mail routing (MX) for example.com]\n" +
+				"remove TXT example.com \"v=spf1 -all\"->",
+		},
+		{
+			name:    "address displaces a CNAME once",
+			planned: []DnsUpdateParams{{RecordType: "A", RecordName: "www", RecordValue: "203.0.113.10"}, {RecordType: "AAAA", RecordName: "www", RecordValue: "2001:db8::1"}},
+			want: "create A www.example.com ->203.0.113.10\n" +
+				"remove CNAME www.example.com Old.Example.net.->\n" +
+				"create AAAA www.example.com ->2001:db8::1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := describeChanges(DnsDiff("Example.com.", zone, tt.planned)); got != tt.want {
+				t.Errorf("DnsDiff() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRecordSpec(t *testing.T) {
+	tests := []struct {
+		spec string
+		want DnsRecord
+		// wantErr is a substring of the expected error, "" when spec parses
+		wantErr string
+	}{
+		{spec: "a:@:203.0.113.10", want: DnsRecord{Type: "A", Name: "@", Value: "203.0.113.10"}},
+		{spec: "AAAA:@:2001:db8::10", want: DnsRecord{Type: "AAAA", Name: "@", Value: "2001:db8::10"}},
+		{spec: "CNAME:www:app.example.dev", want: DnsRecord{Type: "CNAME", Name: "www", Value: "app.example.dev"}},
+		{spec: "MX:@:mail.example.com", wantErr: `"MX" is not a supported record type`},
+		{spec: "A:@", wantErr: "want TYPE:NAME:VALUE"},
+		{spec: "A::203.0.113.10", wantErr: "want TYPE:NAME:VALUE"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			got, err := ParseRecordSpec(tt.spec)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("ParseRecordSpec(%q) error = %v, want %q", tt.spec, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("ParseRecordSpec(%q) = %+v, %v, want %+v", tt.spec, got, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package bridge
+
+import "sort"
+
+// maskedEnvValue stands in for env var values in an EnvDiff
+const maskedEnvValue = "••••••••"
+
+// allEnvironments is the Environment of a variable that isn't scoped to
+// particular environments, or of a diff made at the key level
+const allEnvironments = ""
+
+// EnvDiffEntry is one variable that differs between the source and target.
+// Environment is the target environment it differs in, or empty when the
+// variable isn't scoped by environment. Values are masked.
+type EnvDiffEntry struct {
+	Key         string `json:"key"`
+	Environment string `json:"environment,omitempty"`
+	SourceValue string `json:"source_value,omitempty"`
+	TargetValue string `json:"target_value,omitempty"`
+}
+
+// EnvDiffResult compares the source's env with the target's: Added are set
+// on the source but missing from the target, Removed are only on the target,
+// and Changed are on both with different values
+type EnvDiffResult struct {
+	Added   []EnvDiffEntry `json:"added"`
+	Removed []EnvDiffEntry `json:"removed"`
+	Changed []EnvDiffEntry `json:"changed"`
+}
+
+// Empty reports whether the two envs match
+func (r EnvDiffResult) Empty() bool {
+	return len(r.Added) == 0 && len(r.Removed) == 0 && len(r.Changed) == 0
+}
+
+// ForEnvironment narrows the diff to one environment, keeping differences
+// in variables that aren't scoped by environment since they affect it too
+func (r EnvDiffResult) ForEnvironment(env string) EnvDiffResult {
+	keep := func(entries []EnvDiffEntry) []EnvDiffEntry {
+		kept := []EnvDiffEntry{}
+		for _, e := range entries {
+			if e.Environment == allEnvironments || e.Environment == env {
+				kept = append(kept, e)
+			}
+		}
+		return kept
+	}
+	return EnvDiffResult{Added: keep(r.Added), Removed: keep(r.Removed), Changed: keep(r.Changed)}
+}
+
+// envValues maps each key to its value per environment
+type envValues map[string]map[string]string
+
+func indexEnv(vars []EnvVar) envValues {
+	index := make(envValues)
+	for _, v := range vars {
+		if index[v.Key] == nil {
+			index[v.Key] = make(map[string]string)
+		}
+		if len(v.Target) == 0 {
+			index[v.Key][allEnvironments] = v.Value
+			continue
+		}
+		for _, env := range v.Target {
+			index[v.Key][env] = v.Value
+		}
+	}
+	return index
+}
+
+// scoped reports whether every value of the key is tied to an environment
+func scoped(values map[string]string) bool {
+	_, unscoped := values[allEnvironments]
+	return !unscoped
+}
+
+// EnvDiff compares the env vars of two fetched configs. Variables scoped to
+// environments (EnvVar.Target) are compared per environment. When either side
+// doesn't scope a key, as with providers that have a single environment, the
+// key is compared as a whole: it changed if any of its values differ.
+func EnvDiff(source, target *FetchConfigData) EnvDiffResult {
+	var srcVars, dstVars []EnvVar
+	if source != nil {
+		srcVars = source.Env
+	}
+	if target != nil {
+		dstVars = target.Env
+	}
+	src, dst := indexEnv(srcVars), indexEnv(dstVars)
+
+	result := EnvDiffResult{Added: []EnvDiffEntry{}, Removed: []EnvDiffEntry{}, Changed: []EnvDiffEntry{}}
+	for _, key := range unionKeys(src, dst) {
+		srcValues, inSource := src[key]
+		dstValues, inTarget := dst[key]
+
+		switch {
+		case !inTarget:
+			result.Added = append(result.Added, entries(key, srcValues, true)...)
+		case !inSource:
+			result.Removed = append(result.Removed, entries(key, dstValues, false)...)
+		case scoped(srcValues) && scoped(dstValues):
+			for _, env := range unionEnvs(srcValues, dstValues) {
+				srcValue, inSrcEnv := srcValues[env]
+				dstValue, inDstEnv := dstValues[env]
+				entry := EnvDiffEntry{Key: key, Environment: env}
+				switch {
+				case !inDstEnv:
+					entry.SourceValue = maskEnvValue(srcValue)
+					result.Added = append(result.Added, entry)
+				case !inSrcEnv:
+					entry.TargetValue = maskEnvValue(dstValue)
+					result.Removed = append(result.Removed, entry)
+				case srcValue != dstValue:
+					entry.SourceValue, entry.TargetValue = maskEnvValue(srcValue), maskEnvValue(dstValue)
+					result.Changed = append(result.Changed, entry)
+				}
+			}
+		case !sameValues(srcValues, dstValues):
+			result.Changed = append(result.Changed, EnvDiffEntry{
+				Key:         key,
+				SourceValue: maskedEnvValue,
+				TargetValue: maskedEnvValue,
+			})
+		}
+	}
+	return result
+}
+
+// entries lists a key that is only on one side, one entry per environment
+func entries(key string, values map[string]string, onSource bool) []EnvDiffEntry {
+	var list []EnvDiffEntry
+	for _, env := range unionEnvs(values, nil) {
+		entry := EnvDiffEntry{Key: key, Environment: env}
+		if onSource {
+			entry.SourceValue = maskEnvValue(values[env])
+		} else {
+			entry.TargetValue = maskEnvValue(values[env])
+		}
+		list = append(list, entry)
+	}
+	return list
+}
+
+// sameValues reports whether every value on either side equals every value
+// on the other, for keys compared without regard to environment
+func sameValues(a, b map[string]string) bool {
+	var first string
+	seen := false
+	for _, values := range []map[string]string{a, b} {
+		for _, v := range values {
+			if !seen {
+				first, seen = v, true
+			} else if v != first {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// maskEnvValue hides a value, keeping only whether it is empty
+func maskEnvValue(value string) string {
+	if value == "" {
+		return "(empty)"
+	}
+	return maskedEnvValue
+}
+
+// unionKeys returns the keys of both indexes, sorted
+func unionKeys(a, b envValues) []string {
+	set := make(map[string]bool, len(a)+len(b))
+	for k := range a {
+		set[k] = true
+	}
+	for k := range b {
+		set[k] = true
+	}
+	return sortedSet(set)
+}
+
+// unionEnvs returns the environments of both value maps, sorted
+func unionEnvs(a, b map[string]string) []string {
+	set := make(map[string]bool, len(a)+len(b))
+	for env := range a {
+		set[env] = true
+	}
+	for env := range b {
+		set[env] = true
+	}
+	return sortedSet(set)
+}
+
+func sortedSet(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
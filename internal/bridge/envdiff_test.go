@@ -0,0 +1,102 @@
+package bridge
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestEnvDiff(t *testing.T) {
+	prod := []string{EnvProduction}
+	preview := []string{EnvPreview}
+
+	tests := []struct {
+		name    string
+		source  []EnvVar
+		target  []EnvVar
+		added   string
+		removed string
+		changed string
+	}{
+		{
+			name:   "identical",
+			source: []EnvVar{{Key: "A", Value: "1"}},
+			target: []EnvVar{{Key: "A", Value: "1"}},
+		},
+		{
+			name:    "unscoped",
+			source:  []EnvVar{{Key: "A", Value: "1"}, {Key: "B", Value: "2"}, {Key: "EMPTY"}},
+			target:  []EnvVar{{Key: "B", Value: "3"}, {Key: "C", Value: "4"}},
+			added:   "[{A  •••••••• } {EMPTY  (empty) }]",
+			removed: "[{C   ••••••••}]",
+			changed: "[{B  •••••••• ••••••••}]",
+		},
+		{
+			name:    "scoped on both sides",
+			source:  []EnvVar{{Key: "A", Value: "1", Target: prod}, {Key: "A", Value: "2", Target: preview}},
+			target:  []EnvVar{{Key: "A", Value: "1", Target: prod}, {Key: "A", Value: "9", Target: []string{EnvDevelopment}}},
+			added:   "[{A preview •••••••• }]",
+			removed: "[{A development  ••••••••}]",
+		},
+		{
+			name:    "scoped value changed",
+			source:  []EnvVar{{Key: "A", Value: "1", Target: prod}},
+			target:  []EnvVar{{Key: "A", Value: "2", Target: prod}},
+			changed: "[{A production •••••••• ••••••••}]",
+		},
+		{
+			// A single-environment target is compared by key
+			name:   "scoped against unscoped, same value",
+			source: []EnvVar{{Key: "A", Value: "1", Target: prod}, {Key: "A", Value: "1", Target: preview}},
+			target: []EnvVar{{Key: "A", Value: "1"}},
+		},
+		{
+			name:    "scoped against unscoped, different values",
+			source:  []EnvVar{{Key: "A", Value: "1", Target: prod}, {Key: "A", Value: "2", Target: preview}},
+			target:  []EnvVar{{Key: "A", Value: "1"}},
+			changed: "[{A  •••••••• ••••••••}]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := EnvDiff(&FetchConfigData{Env: tt.source}, &FetchConfigData{Env: tt.target})
+			for _, c := range []struct {
+				name      string
+				got, want string
+			}{
+				{"Added", fmt.Sprint(got.Added), tt.added},
+				{"Removed", fmt.Sprint(got.Removed), tt.removed},
+				{"Changed", fmt.Sprint(got.Changed), tt.changed},
+			} {
+				if c.want == "" {
+					c.want = "[]"
+				}
+				if c.got != c.want {
+					t.Errorf("EnvDiff().%s = %s, want %s", c.name, c.got, c.want)
+				}
+			}
+			if empty := tt.added == "" && tt.removed == "" && tt.changed == ""; got.Empty() != empty {
+				t.Errorf("Empty() = %v, want %v", got.Empty(), empty)
+			}
+		})
+	}
+}
+
+func TestEnvDiffForEnvironment(t *testing.T) {
+	diff := EnvDiff(
+		&FetchConfigData{Env: []EnvVar{
+			{Key: "A", Value: "1", Target: []string{EnvProduction}},
+			{Key: "B", Value: "1", Target: []string{EnvPreview}},
+			{Key: "C", Value: "1"},
+		}},
+		nil,
+	)
+
+	got := diff.ForEnvironment(EnvProduction)
+	if want := "[{A production •••••••• } {C  •••••••• }]"; fmt.Sprint(got.Added) != want {
+		t.Errorf("ForEnvironment(production).Added = %v, want %s", got.Added, want)
+	}
+	if got := diff.ForEnvironment(EnvDevelopment); fmt.Sprint(got.Added) != "[{C  •••••••• }]" {
+		t.Errorf("ForEnvironment(development).Added = %v, want only the unscoped C", got.Added)
+	}
+}
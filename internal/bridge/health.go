@@ -0,0 +1,109 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Health statuses, in a HealthResult
+const (
+	HealthOK = "ok"
+	// HealthDegraded means the provider answered, but slowly or rate limited
+	HealthDegraded = "degraded"
+	HealthDown     = "down"
+)
+
+// slowHealthLatency is the latency past which a provider counts as degraded
+const slowHealthLatency = 2 * time.Second
+
+// maxHealthWorkers bounds how many adapters HealthAll runs at once
+const maxHealthWorkers = 4
+
+// HealthResult is the outcome of checking one provider. Err is set when the
+// provider is down or rate limited.
+type HealthResult struct {
+	Provider Provider
+	Status   string
+	Latency  time.Duration
+	Err      error
+}
+
+// Health checks that provider is reachable and accepts token, by fetching
+// config without a project: INVALID_PARAMS for the missing project means the
+// call got through. The check always runs the adapter, bypassing the cache,
+// and never refreshes the token.
+func (b *Bridge) Health(ctx context.Context, provider Provider, token string) HealthResult {
+	params := FetchConfigParams{Provider: provider, Token: token}
+	// Checked here, since the INVALID_PARAMS it would fail with means healthy
+	if err := params.Validate(); err != nil {
+		return HealthResult{Provider: provider, Status: HealthDown, Err: invalidParams("invalid health check: %s", err)}
+	}
+
+	ctx = WithoutCache(WithoutRefresh(ctx))
+	started := time.Now()
+	_, err := b.FetchConfig(ctx, params)
+	result := HealthResult{Provider: provider, Latency: time.Since(started)}
+
+	var bridgeErr *BridgeError
+	if errors.As(err, &bridgeErr) && bridgeErr.Code == ErrInvalidParams {
+		err = nil
+	}
+
+	switch {
+	case err == nil && result.Latency >= slowHealthLatency:
+		result.Status = HealthDegraded
+	case err == nil:
+		result.Status = HealthOK
+	case errors.As(err, &bridgeErr) && bridgeErr.Code == ErrRateLimited:
+		result.Status, result.Err = HealthDegraded, err
+	default:
+		result.Status, result.Err = HealthDown, err
+	}
+	return result
+}
+
+// HealthAll checks each provider in tokens concurrently with its token.
+// Results are sorted by provider; providers not yet started when ctx is
+// cancelled are down with ctx's error.
+func (b *Bridge) HealthAll(ctx context.Context, tokens map[Provider]string) []HealthResult {
+	providers := make([]Provider, 0, len(tokens))
+	for p := range tokens {
+		providers = append(providers, p)
+	}
+	sort.Slice(providers, func(i, j int) bool { return providers[i] < providers[j] })
+
+	results := make([]HealthResult, len(providers))
+	jobs := make(chan int)
+
+	workers := maxHealthWorkers
+	if len(providers) < workers {
+		workers = len(providers)
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				provider := providers[i]
+				if err := ctx.Err(); err != nil {
+					results[i] = HealthResult{Provider: provider, Status: HealthDown, Err: err}
+					continue
+				}
+				results[i] = b.Health(ctx, provider, tokens[provider])
+			}
+		}()
+	}
+
+	for i := range providers {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
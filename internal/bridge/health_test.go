@@ -0,0 +1,109 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// healthScript answers fetch:config differently for each provider's adapter
+const healthScript = `cat > /dev/null
+case "$3" in
+capabilities) echo '{"ok":true,"data":{"adapter_name":"fake","adapter_version":"1.0.0","auth_type":"token"}}' ;;
+*)
+	case "$2" in
+	*/vercel/*) echo '{"ok":false,"error":{"code":"INVALID_PARAMS","message":"no project"}}' ;;
+	*/netlify/*) echo '{"ok":false,"error":{"code":"AUTH_FAILED","message":"bad token"}}' ;;
+	*/cloudflare/*) echo '{"ok":false,"error":{"code":"RATE_LIMITED","message":"slow down"}}' ;;
+	*) echo '{"ok":true,"data":{}}' ;;
+	esac ;;
+esac
+`
+
+func TestHealth(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider Provider
+		token    string
+		want     string
+		// wantCode is the error code reported, "" when there is no error
+		wantCode ErrorCode
+	}{
+		{name: "missing project means reachable", provider: ProviderVercel, token: "tok_1", want: HealthOK},
+		{name: "rejected token", provider: ProviderNetlify, token: "tok_1", want: HealthDown, wantCode: ErrAuthFailed},
+		{name: "rate limited", provider: ProviderCloudflare, token: "tok_1", want: HealthDegraded, wantCode: ErrRateLimited},
+		{name: "no token", provider: ProviderVercel, want: HealthDown, wantCode: ErrInvalidParams},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, _ := scriptBridge(t, healthScript, ProviderVercel, ProviderNetlify, ProviderCloudflare)
+
+			got := b.Health(context.Background(), tt.provider, tt.token)
+			if got.Provider != tt.provider || got.Status != tt.want {
+				t.Errorf("Health(%s) = %s %s, want %s", tt.provider, got.Provider, got.Status, tt.want)
+			}
+			if tt.wantCode == "" {
+				if got.Err != nil {
+					t.Errorf("Health(%s) error = %v, want none", tt.provider, got.Err)
+				}
+				return
+			}
+			var bridgeErr *BridgeError
+			if !errors.As(got.Err, &bridgeErr) || bridgeErr.Code != tt.wantCode {
+				t.Errorf("Health(%s) error = %v, want %s", tt.provider, got.Err, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestHealthAll(t *testing.T) {
+	b, calls := scriptBridge(t, healthScript, ProviderVercel, ProviderNetlify, ProviderCloudflare)
+	tokens := map[Provider]string{ProviderVercel: "tok_v", ProviderNetlify: "tok_n", ProviderCloudflare: "tok_c"}
+
+	results := b.HealthAll(context.Background(), tokens)
+	want := []struct {
+		provider Provider
+		status   string
+	}{
+		{ProviderCloudflare, HealthDegraded},
+		{ProviderNetlify, HealthDown},
+		{ProviderVercel, HealthOK},
+	}
+	if len(results) != len(want) {
+		t.Fatalf("HealthAll() returned %d results, want %d", len(results), len(want))
+	}
+	for i, w := range want {
+		if results[i].Provider != w.provider || results[i].Status != w.status {
+			t.Errorf("result %d = %s %s, want %s %s", i, results[i].Provider, results[i].Status, w.provider, w.status)
+		}
+	}
+
+	// The check always runs the adapter, so a second one isn't cached
+	b.HealthAll(context.Background(), tokens)
+	runs := 0
+	for _, verb := range calledVerbs(t, calls) {
+		if verb == "fetch:config" {
+			runs++
+		}
+	}
+	if runs != 2*len(tokens) {
+		t.Errorf("fetch:config ran %d times for two checks, want %d", runs, 2*len(tokens))
+	}
+}
+
+func TestHealthAllCancelled(t *testing.T) {
+	b, calls := scriptBridge(t, healthScript, ProviderVercel, ProviderNetlify)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results := b.HealthAll(ctx, map[Provider]string{ProviderVercel: "tok_v", ProviderNetlify: "tok_n"})
+	for _, r := range results {
+		if r.Status != HealthDown || !errors.Is(r.Err, context.Canceled) {
+			t.Errorf("%s = %s %v, want down with the context's error", r.Provider, r.Status, r.Err)
+		}
+	}
+	if verbs := calledVerbs(t, calls); len(verbs) != 0 {
+		t.Errorf("ran %v after cancelling, want nothing", verbs)
+	}
+}
@@ -0,0 +1,54 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Progress kinds, in a Progress event
+const (
+	// ProgressWaiting means a rate limited call is waiting before retrying
+	ProgressWaiting = "waiting"
+	// ProgressRetrying means a call is being run again
+	ProgressRetrying = "retrying"
+)
+
+// Progress is an intermediate event from a call that is taking longer than
+// one adapter run, e.g. because it was rate limited
+type Progress struct {
+	Kind     string
+	Provider Provider
+	Verb     string
+	// Attempt is the retry about to run, from 1 to MaxAttempts
+	Attempt     int
+	MaxAttempts int
+	// Wait is how long the call is waiting, for ProgressWaiting
+	Wait time.Duration
+}
+
+// String describes the event for a status line
+func (p Progress) String() string {
+	switch p.Kind {
+	case ProgressWaiting:
+		return fmt.Sprintf("waiting %s for rate limit...", p.Wait.Round(100*time.Millisecond))
+	case ProgressRetrying:
+		return fmt.Sprintf("retrying (%d/%d)...", p.Attempt, p.MaxAttempts)
+	}
+	return p.Kind
+}
+
+type progressKey struct{}
+
+// WithProgress makes calls made with ctx report retries and rate limit
+// waits to fn. fn runs on the calling goroutine and must not block.
+func WithProgress(ctx context.Context, fn func(Progress)) context.Context {
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// reportProgress passes p to ctx's progress func, if any
+func reportProgress(ctx context.Context, p Progress) {
+	if fn, ok := ctx.Value(progressKey{}).(func(Progress)); ok {
+		fn(p)
+	}
+}
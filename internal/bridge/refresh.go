@@ -0,0 +1,89 @@
+package bridge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// TokenStore is the credential storage used by auto refresh
+type TokenStore interface {
+	GetRefreshToken(provider string) (string, error)
+	StoreToken(provider, token string, expiresAt *time.Time) error
+}
+
+// WithAutoRefresh makes verbs that fail with AUTH_REQUIRED or AUTH_FAILED
+// refresh the token using tokens' refresh token, store the new token, and
+// retry once
+func (b *Bridge) WithAutoRefresh(tokens TokenStore) *Bridge {
+	b.tokens = tokens
+	return b
+}
+
+type noRefreshKey struct{}
+
+// WithoutRefresh disables auto refresh for calls made with ctx, e.g. when
+// verifying a token the user just entered, where falling back to an older
+// stored credential would hide that the new one is bad
+func WithoutRefresh(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noRefreshKey{}, true)
+}
+
+// shouldRefresh reports whether err is an auth failure worth refreshing for
+func (b *Bridge) shouldRefresh(ctx context.Context, verb string, err error) bool {
+	if b.tokens == nil || ctx.Value(noRefreshKey{}) != nil {
+		return false
+	}
+	if verb == "capabilities" || strings.HasPrefix(verb, "auth:") {
+		return false
+	}
+
+	var bridgeErr *BridgeError
+	if !errors.As(err, &bridgeErr) {
+		return false
+	}
+	return bridgeErr.Code == ErrAuthRequired || bridgeErr.Code == ErrAuthFailed
+}
+
+// refreshToken gets and stores a new token, returning params with it swapped
+// in. ok is false when there's nothing to refresh with or the refresh failed.
+func (b *Bridge) refreshToken(ctx context.Context, provider Provider, stdinData []byte) (retryData []byte, ok bool) {
+	var params map[string]interface{}
+	if err := json.Unmarshal(stdinData, &params); err != nil {
+		return nil, false
+	}
+	if _, hasToken := params["token"]; !hasToken {
+		return nil, false
+	}
+
+	refreshToken, err := b.tokens.GetRefreshToken(string(provider))
+	if err != nil || refreshToken == "" {
+		return nil, false
+	}
+
+	refreshed, err := b.AuthRefresh(ctx, AuthRefreshParams{
+		Provider:     provider,
+		RefreshToken: refreshToken,
+	})
+	if err != nil || refreshed.Token == "" {
+		return nil, false
+	}
+
+	var expiresAt *time.Time
+	if refreshed.ExpiresAt > 0 {
+		t := time.Unix(refreshed.ExpiresAt, 0).UTC()
+		expiresAt = &t
+	}
+	if err := b.tokens.StoreToken(string(provider), refreshed.Token, expiresAt); err != nil {
+		return nil, false
+	}
+
+	params["token"] = refreshed.Token
+	retryData, err = json.Marshal(params)
+	if err != nil {
+		return nil, false
+	}
+	return retryData, true
+}
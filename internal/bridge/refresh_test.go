@@ -0,0 +1,135 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+// refreshScript accepts only tok_new; auth:refresh hands it out for ref_ok
+const refreshScript = `input=$(cat)
+case "$3" in
+capabilities) echo '{"ok":true,"data":{"adapter_name":"fake","adapter_version":"1.0.0","auth_type":"token"}}' ;;
+auth:refresh)
+	case "$input" in
+	*ref_ok*) echo '{"ok":true,"data":{"token":"tok_new","expires_at":1767225600}}' ;;
+	*) echo '{"ok":false,"error":{"code":"AUTH_FAILED","message":"refresh token revoked"}}' ;;
+	esac ;;
+*)
+	case "$input" in
+	*tok_new*) echo '{"ok":true,"data":{"env":[{"key":"API_KEY","value":"1","target":[]}]}}' ;;
+	*) echo '{"ok":false,"error":{"code":"AUTH_FAILED","message":"token expired"}}' ;;
+	esac ;;
+esac
+`
+
+// fakeTokens is an in-memory TokenStore
+type fakeTokens struct {
+	refresh   string
+	stored    string
+	expiresAt *time.Time
+}
+
+func (f *fakeTokens) GetRefreshToken(provider string) (string, error) {
+	if f.refresh == "" {
+		return "", errors.New("no refresh token")
+	}
+	return f.refresh, nil
+}
+
+func (f *fakeTokens) StoreToken(provider, token string, expiresAt *time.Time) error {
+	f.stored, f.expiresAt = token, expiresAt
+	return nil
+}
+
+func TestAutoRefresh(t *testing.T) {
+	tests := []struct {
+		name    string
+		refresh string
+		ctx     func(context.Context) context.Context
+		wantOK  bool
+		// wantVerbs are the adapter calls made, after capabilities
+		wantVerbs []string
+	}{
+		{
+			name:      "refreshes and retries once",
+			refresh:   "ref_ok",
+			wantOK:    true,
+			wantVerbs: []string{"fetch:config", "auth:refresh", "fetch:config"},
+		},
+		{
+			name:      "no refresh token",
+			wantVerbs: []string{"fetch:config"},
+		},
+		{
+			name:      "refresh fails",
+			refresh:   "ref_revoked",
+			wantVerbs: []string{"fetch:config", "auth:refresh"},
+		},
+		{
+			name:      "disabled for the call",
+			refresh:   "ref_ok",
+			ctx:       WithoutRefresh,
+			wantVerbs: []string{"fetch:config"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, calls := scriptBridge(t, refreshScript, "vercel")
+			tokens := &fakeTokens{refresh: tt.refresh}
+			b.WithAutoRefresh(tokens)
+
+			ctx := context.Background()
+			if tt.ctx != nil {
+				ctx = tt.ctx(ctx)
+			}
+			data, err := b.FetchConfig(ctx, FetchConfigParams{Provider: "vercel", Token: "tok_old"})
+
+			if tt.wantOK {
+				if err != nil || len(data.Env) != 1 {
+					t.Fatalf("FetchConfig = %+v, %v; want the retried data", data, err)
+				}
+				if tokens.stored != "tok_new" || tokens.expiresAt == nil || tokens.expiresAt.Unix() != 1767225600 {
+					t.Errorf("stored %q expiring %v, want tok_new with its expiry", tokens.stored, tokens.expiresAt)
+				}
+			} else {
+				var bridgeErr *BridgeError
+				if !errors.As(err, &bridgeErr) || bridgeErr.Code != ErrAuthFailed {
+					t.Errorf("FetchConfig error = %v, want the original AUTH_FAILED", err)
+				}
+				if tokens.stored != "" {
+					t.Errorf("stored %q although nothing was refreshed", tokens.stored)
+				}
+			}
+
+			got := slices.DeleteFunc(calledVerbs(t, calls), func(v string) bool { return v == "capabilities" })
+			if !slices.Equal(got, tt.wantVerbs) {
+				t.Errorf("adapter calls = %v, want %v", got, tt.wantVerbs)
+			}
+		})
+	}
+}
+
+func TestAutoRefreshOncePerCall(t *testing.T) {
+	// The refreshed token is rejected too, so the retry fails again
+	script := `cat > /dev/null
+case "$3" in
+capabilities) echo '{"ok":true,"data":{"adapter_name":"fake","adapter_version":"1.0.0","auth_type":"token"}}' ;;
+auth:refresh) echo '{"ok":true,"data":{"token":"tok_new"}}' ;;
+*) echo '{"ok":false,"error":{"code":"AUTH_REQUIRED","message":"token expired"}}' ;;
+esac
+`
+	b, calls := scriptBridge(t, script, "vercel")
+	b.WithAutoRefresh(&fakeTokens{refresh: "ref_ok"})
+
+	if _, err := b.FetchConfig(context.Background(), FetchConfigParams{Provider: "vercel", Token: "tok_old"}); err == nil {
+		t.Fatal("FetchConfig succeeded with a rejected token")
+	}
+	got := slices.DeleteFunc(calledVerbs(t, calls), func(v string) bool { return v == "capabilities" })
+	if want := []string{"fetch:config", "auth:refresh", "fetch:config"}; !slices.Equal(got, want) {
+		t.Errorf("adapter calls = %v, want %v", got, want)
+	}
+}
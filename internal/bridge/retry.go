@@ -0,0 +1,84 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// maxRetryAfter caps how long a single rate limit wait can be
+const maxRetryAfter = time.Minute
+
+// WithRetries makes calls that fail with RATE_LIMITED wait for the adapter's
+// details.retry_after (in seconds, capped at a minute) and retry, up to 3
+// times. Rate limits without a retry_after, and other recoverable errors,
+// are returned as is.
+func (b *Bridge) WithRetries() *Bridge {
+	b.retries = maxRetries
+	return b
+}
+
+// retryAfter returns how long to wait before retrying err, and false when
+// err isn't a rate limit that says when to retry
+func retryAfter(err error) (time.Duration, bool) {
+	var bridgeErr *BridgeError
+	if !errors.As(err, &bridgeErr) || bridgeErr.Code != ErrRateLimited {
+		return 0, false
+	}
+
+	var seconds float64
+	switch v := bridgeErr.Details["retry_after"].(type) {
+	case float64:
+		seconds = v
+	case string:
+		parsed, parseErr := strconv.ParseFloat(v, 64)
+		if parseErr != nil {
+			return 0, false
+		}
+		seconds = parsed
+	default:
+		return 0, false
+	}
+	if seconds < 0 {
+		seconds = 0
+	}
+
+	wait := time.Duration(seconds * float64(time.Second))
+	if wait > maxRetryAfter {
+		wait = maxRetryAfter
+	}
+	return wait, true
+}
+
+// runWithRetries runs the adapter, waiting out rate limits when retries are
+// enabled and reporting each wait and retry (see WithProgress). Cancelling
+// ctx during a wait returns ctx's error.
+func (b *Bridge) runWithRetries(ctx context.Context, provider Provider, adapterPath, verb string, stdinData []byte) (*Response, error) {
+	resp, err := b.run(ctx, adapterPath, verb, stdinData)
+	for attempt := 0; attempt < b.retries && err != nil; attempt++ {
+		wait, ok := retryAfter(err)
+		if !ok {
+			break
+		}
+
+		progress := Progress{Provider: provider, Verb: verb, Attempt: attempt + 1, MaxAttempts: b.retries}
+		if wait > 0 {
+			progress.Kind, progress.Wait = ProgressWaiting, wait
+			reportProgress(ctx, progress)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+
+		progress.Kind, progress.Wait = ProgressRetrying, 0
+		reportProgress(ctx, progress)
+		resp, err = b.run(ctx, adapterPath, verb, stdinData)
+	}
+	return resp, err
+}
@@ -0,0 +1,167 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRetryAfter(t *testing.T) {
+	rateLimited := func(details map[string]interface{}) error {
+		return &BridgeError{Code: ErrRateLimited, Message: "slow down", Details: details}
+	}
+
+	tests := []struct {
+		name   string
+		err    error
+		want   time.Duration
+		wantOK bool
+	}{
+		{name: "seconds", err: rateLimited(map[string]interface{}{"retry_after": float64(7)}), want: 7 * time.Second, wantOK: true},
+		{name: "fractional", err: rateLimited(map[string]interface{}{"retry_after": 0.25}), want: 250 * time.Millisecond, wantOK: true},
+		{name: "string", err: rateLimited(map[string]interface{}{"retry_after": "3"}), want: 3 * time.Second, wantOK: true},
+		{name: "negative waits nothing", err: rateLimited(map[string]interface{}{"retry_after": float64(-5)}), want: 0, wantOK: true},
+		{name: "capped", err: rateLimited(map[string]interface{}{"retry_after": float64(3600)}), want: maxRetryAfter, wantOK: true},
+		{name: "unparseable", err: rateLimited(map[string]interface{}{"retry_after": "soon"})},
+		{name: "no retry_after", err: rateLimited(nil)},
+		{name: "other code", err: &BridgeError{Code: ErrNetworkError, Details: map[string]interface{}{"retry_after": float64(1)}}},
+		{name: "wrapped", err: fmt.Errorf("fetch: %w", rateLimited(map[string]interface{}{"retry_after": float64(2)})), want: 2 * time.Second, wantOK: true},
+		{name: "not a bridge error", err: errors.New("boom")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := retryAfter(tt.err)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("retryAfter() = %v, %v; want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+// rateLimitScript is rate limited for the first $limit fetch:config calls,
+// asking to wait $wait seconds ("" for no retry_after)
+func rateLimitScript(limit int, wait string) string {
+	details := ""
+	if wait != "" {
+		details = `,"details":{"retry_after":` + wait + `}`
+	}
+	return fmt.Sprintf(`cat > /dev/null
+case "$3" in
+capabilities) echo '{"ok":true,"data":{"adapter_name":"fake","adapter_version":"1.0.0","auth_type":"token"}}' ;;
+*)
+	if [ "$(grep -c '^fetch:config$' "$calls")" -le %d ]; then
+		echo '{"ok":false,"error":{"code":"RATE_LIMITED","message":"slow down","recoverable":true%s}}'
+	else
+		echo '{"ok":true,"data":{}}'
+	fi ;;
+esac
+`, limit, details)
+}
+
+func TestRunWithRetries(t *testing.T) {
+	tests := []struct {
+		name    string
+		limit   int
+		wait    string
+		retries bool
+		wantOK  bool
+		// wantRuns counts fetch:config runs
+		wantRuns int
+	}{
+		{name: "retries until it succeeds", limit: 2, wait: "0", retries: true, wantOK: true, wantRuns: 3},
+		{name: "gives up after the cap", limit: 10, wait: "0", retries: true, wantRuns: 1 + maxRetries},
+		{name: "no retry_after", limit: 1, retries: true, wantRuns: 1},
+		{name: "retries off", limit: 1, wait: "0", wantRuns: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, calls := scriptBridge(t, rateLimitScript(tt.limit, tt.wait), "vercel")
+			if tt.retries {
+				b.WithRetries()
+			}
+
+			_, err := b.FetchConfig(context.Background(), FetchConfigParams{Provider: "vercel", Token: "tok_1"})
+			if tt.wantOK && err != nil {
+				t.Errorf("FetchConfig error: %v", err)
+			}
+			var bridgeErr *BridgeError
+			if !tt.wantOK && (!errors.As(err, &bridgeErr) || bridgeErr.Code != ErrRateLimited) {
+				t.Errorf("FetchConfig error = %v, want RATE_LIMITED", err)
+			}
+
+			runs := 0
+			for _, verb := range calledVerbs(t, calls) {
+				if verb == "fetch:config" {
+					runs++
+				}
+			}
+			if runs != tt.wantRuns {
+				t.Errorf("fetch:config ran %d times, want %d", runs, tt.wantRuns)
+			}
+		})
+	}
+}
+
+func TestRunWithRetriesWaits(t *testing.T) {
+	b, _ := scriptBridge(t, rateLimitScript(1, "0.2"), "vercel")
+	b.WithRetries()
+
+	started := time.Now()
+	if _, err := b.FetchConfig(context.Background(), FetchConfigParams{Provider: "vercel", Token: "tok_1"}); err != nil {
+		t.Fatalf("FetchConfig error: %v", err)
+	}
+	if elapsed := time.Since(started); elapsed < 200*time.Millisecond {
+		t.Errorf("retried after %s, want at least the 200ms retry_after", elapsed)
+	}
+
+	// Cancelling during the wait stops the retries
+	b, _ = scriptBridge(t, rateLimitScript(1, "30"), "vercel")
+	b.WithRetries()
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := b.FetchConfig(ctx, FetchConfigParams{Provider: "vercel", Token: "tok_1"}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("FetchConfig error = %v, want the context's deadline", err)
+	}
+}
+
+func TestRunWithRetriesReportsProgress(t *testing.T) {
+	b, _ := scriptBridge(t, rateLimitScript(2, "0.01"), "vercel")
+	b.WithRetries()
+
+	var got []string
+	ctx := WithProgress(context.Background(), func(p Progress) {
+		if p.Provider != "vercel" || p.Verb != "fetch:config" {
+			t.Errorf("progress for %s %s, want vercel fetch:config", p.Provider, p.Verb)
+		}
+		got = append(got, fmt.Sprintf("%s %d/%d", p.Kind, p.Attempt, p.MaxAttempts))
+	})
+	if _, err := b.FetchConfig(ctx, FetchConfigParams{Provider: "vercel", Token: "tok_1"}); err != nil {
+		t.Fatalf("FetchConfig error: %v", err)
+	}
+
+	want := fmt.Sprintf("waiting 1/%[1]d,retrying 1/%[1]d,waiting 2/%[1]d,retrying 2/%[1]d", b.retries)
+	if joined := strings.Join(got, ","); joined != want {
+		t.Errorf("progress = %s, want %s", joined, want)
+	}
+}
+
+func TestProgressString(t *testing.T) {
+	tests := []struct {
+		progress Progress
+		want     string
+	}{
+		{progress: Progress{Kind: ProgressWaiting, Wait: 1234 * time.Millisecond}, want: "waiting 1.2s for rate limit..."},
+		{progress: Progress{Kind: ProgressRetrying, Attempt: 2, MaxAttempts: 3}, want: "retrying (2/3)..."},
+	}
+
+	for _, tt := range tests {
+		if got := tt.progress.String(); got != tt.want {
+			t.Errorf("%+v.String() = %q, want %q", tt.progress, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package bridge
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os/exec"
+)
+
+// RuntimeInstallHint tells the user how to get a runtime for the adapters
+const RuntimeInstallHint = "Install Bun from https://bun.sh, or set DEPLOY_TUNNEL_RUNTIME (or runtime in the config file) to the path of your bun executable."
+
+// runtimeDetail is the Details key naming a runtime that couldn't be started
+const runtimeDetail = "missing_runtime"
+
+// runtimeMissing reports whether a failed cmd.Run never started the runtime
+// because its executable doesn't exist. Adapter files that are missing are
+// reported by the runtime itself, so a not-found here is always the runtime.
+func runtimeMissing(cmd *exec.Cmd, err error) bool {
+	if errors.Is(err, exec.ErrNotFound) {
+		return true
+	}
+	// An explicit path (./bun, /opt/bun/bin/bun) skips the PATH lookup and
+	// fails in fork/exec instead
+	return cmd.ProcessState == nil && errors.Is(err, fs.ErrNotExist)
+}
+
+// runtimeMissingError is the UNSUPPORTED error for a runtime that isn't
+// installed
+func runtimeMissingError(runtime string) *BridgeError {
+	return &BridgeError{
+		Code:    ErrUnsupported,
+		Message: fmt.Sprintf("the Bun runtime (%s) is not installed or not on your PATH, so adapters can't run. %s", runtime, RuntimeInstallHint),
+		Details: map[string]interface{}{runtimeDetail: runtime},
+	}
+}
+
+// MissingRuntime returns the runtime that err says couldn't be found, if err
+// is (or wraps) the bridge's runtime-not-installed error
+func MissingRuntime(err error) (string, bool) {
+	var bridgeErr *BridgeError
+	if !errors.As(err, &bridgeErr) || bridgeErr.Code != ErrUnsupported {
+		return "", false
+	}
+	runtime, ok := bridgeErr.Details[runtimeDetail].(string)
+	return runtime, ok
+}
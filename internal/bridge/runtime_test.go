@@ -0,0 +1,72 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRuntimeMissing(t *testing.T) {
+	tests := []struct {
+		name    string
+		runtime string
+		// missing is whether the error names runtime as not installed
+		missing bool
+	}{
+		{name: "not on PATH", runtime: "no-such-bun-runtime", missing: true},
+		{name: "explicit path", runtime: filepath.Join(t.TempDir(), "bin", "bun"), missing: true},
+		{name: "installed but failing", runtime: "false"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, _ := scriptBridge(t, "", "vercel")
+			b.SetRuntime(tt.runtime)
+
+			_, err := b.Execute(context.Background(), "vercel", "capabilities", nil)
+			if err == nil {
+				t.Fatal("Execute() succeeded, want an error")
+			}
+			runtime, missing := MissingRuntime(err)
+			if missing != tt.missing {
+				t.Fatalf("MissingRuntime(%v) = %v, want %v", err, missing, tt.missing)
+			}
+			if !tt.missing {
+				return
+			}
+			if runtime != tt.runtime {
+				t.Errorf("MissingRuntime() runtime = %q, want %q", runtime, tt.runtime)
+			}
+			var bridgeErr *BridgeError
+			if !errors.As(err, &bridgeErr) || bridgeErr.Code != ErrUnsupported {
+				t.Errorf("error = %v, want %s", err, ErrUnsupported)
+			}
+			if !strings.Contains(err.Error(), RuntimeInstallHint) {
+				t.Errorf("error = %q, want the install hint", err)
+			}
+		})
+	}
+}
+
+func TestMissingRuntimeOtherErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "wrapped", err: fmt.Errorf("fetch: %w", runtimeMissingError("bun")), want: true},
+		{name: "plain", err: errors.New("exit status 1")},
+		{name: "unsupported verb", err: &BridgeError{Code: ErrUnsupported, Message: "vercel adapter does not support dns:list"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, got := MissingRuntime(tt.err); got != tt.want {
+				t.Errorf("MissingRuntime(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// capsCache remembers each adapter's capabilities for the life of the bridge,
+// since an installed adapter doesn't change while dt runs. Failures aren't
+// cached so a flaky adapter is asked again next time.
+type capsCache struct {
+	mu   sync.Mutex
+	caps map[Provider]*CapabilitiesData
+}
+
+func (c *capsCache) get(provider Provider) (*CapabilitiesData, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	caps, ok := c.caps[provider]
+	return caps, ok
+}
+
+func (c *capsCache) put(provider Provider, caps *CapabilitiesData) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.caps == nil {
+		c.caps = make(map[Provider]*CapabilitiesData)
+	}
+	c.caps[provider] = caps
+}
+
+func (c *capsCache) forget(provider Provider) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.caps, provider)
+}
+
+// Supports reports whether provider's adapter handles verb. An adapter that
+// doesn't list its supported verbs is assumed to handle everything.
+func (b *Bridge) Supports(ctx context.Context, provider Provider, verb string) (bool, error) {
+	caps, err := b.Capabilities(ctx, provider)
+	if err != nil {
+		return false, err
+	}
+	if len(caps.SupportedVerbs) == 0 {
+		return true, nil
+	}
+	for _, v := range caps.SupportedVerbs {
+		if v == verb {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// requireVerb fails with UNSUPPORTED when provider's adapter doesn't list
+// verb, so the call isn't spawned just to fail. If the capabilities can't be
+// fetched the call goes ahead and reports its own error.
+func (b *Bridge) requireVerb(ctx context.Context, provider Provider, verb string) error {
+	if verb == "capabilities" {
+		return nil
+	}
+	ok, err := b.Supports(ctx, provider, verb)
+	if err != nil || ok {
+		return nil
+	}
+	return &BridgeError{
+		Code:    ErrUnsupported,
+		Message: fmt.Sprintf("%s adapter does not support %s", provider, verb),
+	}
+}
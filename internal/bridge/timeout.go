@@ -0,0 +1,71 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Timeout sources, in the details of a TIMEOUT error
+const (
+	// TimeoutBridge means the adapter ran longer than the bridge timeout
+	TimeoutBridge = "bridge"
+	// TimeoutCaller means the caller's context was canceled or hit its
+	// deadline first, e.g. because the user quit the TUI
+	TimeoutCaller = "caller"
+)
+
+// timeoutDetail is the Details key holding the timeout source
+const timeoutDetail = "timeout_source"
+
+// callTimeout is how long one adapter run may take: the bridge timeout, or
+// whatever is left of ctx's deadline if that comes sooner
+func (b *Bridge) callTimeout(ctx context.Context) time.Duration {
+	if deadline, ok := ctx.Deadline(); ok {
+		if remaining := time.Until(deadline); remaining < b.timeout {
+			return remaining
+		}
+	}
+	return b.timeout
+}
+
+// timeoutError explains why a run was cut short, or returns nil if it
+// wasn't. The caller's context is checked first, since its deadline can
+// also expire the derived run context.
+func (b *Bridge) timeoutError(ctx, runCtx context.Context, timeout, elapsed time.Duration) *BridgeError {
+	switch {
+	case errors.Is(ctx.Err(), context.Canceled):
+		return &BridgeError{
+			Code:    ErrTimeout,
+			Message: fmt.Sprintf("adapter call was canceled after %s, before the adapter finished", elapsed.Round(time.Millisecond)),
+			Details: map[string]interface{}{timeoutDetail: TimeoutCaller},
+		}
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return &BridgeError{
+			Code:        ErrTimeout,
+			Message:     fmt.Sprintf("adapter call ran out of time after %s: the caller's deadline passed before the bridge timeout of %s", timeout.Round(time.Millisecond), b.timeout),
+			Recoverable: true,
+			Details:     map[string]interface{}{timeoutDetail: TimeoutCaller},
+		}
+	case errors.Is(runCtx.Err(), context.DeadlineExceeded):
+		return &BridgeError{
+			Code:        ErrTimeout,
+			Message:     fmt.Sprintf("adapter command timed out after %s", b.timeout),
+			Recoverable: true,
+			Details:     map[string]interface{}{timeoutDetail: TimeoutBridge},
+		}
+	}
+	return nil
+}
+
+// TimeoutSource returns TimeoutBridge or TimeoutCaller if err is a TIMEOUT
+// from the bridge, saying which limit stopped the adapter
+func TimeoutSource(err error) (string, bool) {
+	var bridgeErr *BridgeError
+	if !errors.As(err, &bridgeErr) || bridgeErr.Code != ErrTimeout {
+		return "", false
+	}
+	source, ok := bridgeErr.Details[timeoutDetail].(string)
+	return source, ok
+}
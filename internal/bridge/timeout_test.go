@@ -0,0 +1,99 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// slowScript answers capabilities at once and hangs on every other verb;
+// exec replaces the shell so the timeout kills the sleep too
+const slowScript = `cat > /dev/null
+case "$3" in
+capabilities) echo '{"ok":true,"data":{"adapter_name":"fake","adapter_version":"1.0.0","auth_type":"token"}}' ;;
+*) exec sleep 5 ;;
+esac
+`
+
+func TestTimeoutSource(t *testing.T) {
+	tests := []struct {
+		name string
+		// ctx returns the caller's context for the call
+		ctx         func() (context.Context, context.CancelFunc)
+		wantSource  string
+		recoverable bool
+		want        string
+	}{
+		{
+			name:        "bridge timeout",
+			ctx:         func() (context.Context, context.CancelFunc) { return context.WithCancel(context.Background()) },
+			wantSource:  TimeoutBridge,
+			recoverable: true,
+			want:        "adapter command timed out after 200ms",
+		},
+		{
+			name: "caller deadline",
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), 100*time.Millisecond)
+			},
+			wantSource:  TimeoutCaller,
+			recoverable: true,
+			want:        "the caller's deadline passed before the bridge timeout of 200ms",
+		},
+		{
+			name: "caller canceled",
+			ctx: func() (context.Context, context.CancelFunc) {
+				ctx, cancel := context.WithCancel(context.Background())
+				time.AfterFunc(100*time.Millisecond, cancel)
+				return ctx, cancel
+			},
+			wantSource: TimeoutCaller,
+			want:       "adapter call was canceled after",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, _ := scriptBridge(t, slowScript, "vercel")
+			b.SetTimeout(200 * time.Millisecond)
+			// Warm the capabilities cache so only fetch:config runs under ctx
+			if _, err := b.Capabilities(context.Background(), "vercel"); err != nil {
+				t.Fatal(err)
+			}
+			ctx, cancel := tt.ctx()
+			defer cancel()
+
+			started := time.Now()
+			_, err := b.FetchConfig(ctx, FetchConfigParams{Provider: "vercel", Token: "tok_slow"})
+			if elapsed := time.Since(started); elapsed > 2*time.Second {
+				t.Errorf("FetchConfig() took %s; the adapter wasn't stopped", elapsed)
+			}
+
+			source, ok := TimeoutSource(err)
+			if !ok || source != tt.wantSource {
+				t.Fatalf("TimeoutSource(%v) = %q, %v, want %q", err, source, ok, tt.wantSource)
+			}
+			var bridgeErr *BridgeError
+			if errors.As(err, &bridgeErr) && bridgeErr.Recoverable != tt.recoverable {
+				t.Errorf("recoverable = %v, want %v", bridgeErr.Recoverable, tt.recoverable)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %q, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestTimeoutSourceOtherErrors(t *testing.T) {
+	for _, err := range []error{
+		errors.New("exit status 1"),
+		&BridgeError{Code: ErrTimeout, Message: "an adapter's own timeout"},
+		&BridgeError{Code: ErrRateLimited, Details: map[string]interface{}{timeoutDetail: TimeoutBridge}},
+	} {
+		if source, ok := TimeoutSource(err); ok {
+			t.Errorf("TimeoutSource(%v) = %q, want no source", err, source)
+		}
+	}
+}
@@ -1,5 +1,11 @@
 package bridge
 
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
 // Provider types
 type Provider string
 
@@ -10,6 +16,27 @@ const (
 	ProviderNetlify    Provider = "netlify"
 )
 
+// AllProviders lists the built-in providers in display order. Adapters for
+// other providers can be installed alongside them; see InstalledAdapters.
+var AllProviders = []Provider{
+	ProviderVercel,
+	ProviderCloudflare,
+	ProviderRender,
+	ProviderNetlify,
+}
+
+// providerNamePattern keeps provider names safe to use as directory names
+var providerNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+
+// ValidProviderName checks name could be an adapter's directory under the
+// adapters path; it doesn't check the adapter is installed
+func ValidProviderName(name string) error {
+	if !providerNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid provider %q", name)
+	}
+	return nil
+}
+
 // Error codes
 type ErrorCode string
 
@@ -81,6 +108,44 @@ type EnvVar struct {
 	Target []string `json:"target"`
 }
 
+// Environments an env var can be set for
+const (
+	EnvProduction  = "production"
+	EnvPreview     = "preview"
+	EnvDevelopment = "development"
+)
+
+// AllEnvironments is what a variable without a Target is set for
+var AllEnvironments = []string{EnvProduction, EnvPreview, EnvDevelopment}
+
+// ParseEnvironment validates an environment name
+func ParseEnvironment(name string) (string, error) {
+	for _, env := range AllEnvironments {
+		if name == env {
+			return env, nil
+		}
+	}
+	return "", fmt.Errorf("unknown environment %q (want %s)", name, strings.Join(AllEnvironments, ", "))
+}
+
+// SyncEnvironments returns the environments to sync a variable to: the ones
+// the source declared (all of them when none were), narrowed to only when
+// it's set. It returns nil when the variable isn't set for only.
+func SyncEnvironments(declared []string, only string) []string {
+	if len(declared) == 0 {
+		declared = AllEnvironments
+	}
+	if only == "" {
+		return append([]string(nil), declared...)
+	}
+	for _, env := range declared {
+		if env == only {
+			return []string{only}
+		}
+	}
+	return nil
+}
+
 type Project struct {
 	ID        string `json:"id"`
 	Name      string `json:"name"`
@@ -129,6 +194,20 @@ type DeployPreviewData struct {
 	BuildTime    *int   `json:"build_time,omitempty"`
 }
 
+type DeployStatusParams struct {
+	Provider     Provider `json:"provider"`
+	Token        string   `json:"token"`
+	DeploymentID string   `json:"deployment_id"`
+}
+
+// Deployment states reported by deploy:preview and deploy:status
+const (
+	DeploymentQueued   = "queued"
+	DeploymentBuilding = "building"
+	DeploymentReady    = "ready"
+	DeploymentError    = "error"
+)
+
 // DNS types
 type DnsUpdateParams struct {
 	Provider    Provider `json:"provider"`
@@ -158,6 +237,72 @@ type DnsRollbackData struct {
 	CurrentValue string `json:"current_value"`
 }
 
+// Domain types
+type DomainsListParams struct {
+	Provider Provider `json:"provider"`
+	Token    string   `json:"token"`
+}
+
+// Domain is a domain registered with a provider. ProjectID is empty for
+// domains not attached to a project.
+type Domain struct {
+	Name      string `json:"name"`
+	Verified  bool   `json:"verified"`
+	ProjectID string `json:"project_id,omitempty"`
+}
+
+type DomainsListData struct {
+	Domains []Domain `json:"domains"`
+}
+
+type DnsListParams struct {
+	Provider Provider `json:"provider"`
+	Token    string   `json:"token"`
+	Domain   string   `json:"domain"`
+}
+
+// DnsRecord is a record currently in a domain's zone. Type can be any
+// record type, including ones dns:update can't set such as MX and NS.
+type DnsRecord struct {
+	ID    string `json:"id"`
+	Type  string `json:"type"`
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	TTL   int    `json:"ttl"`
+}
+
+// DnsRecordTypes are the record types adapters accept
+var DnsRecordTypes = []string{"A", "AAAA", "CNAME", "TXT"}
+
+// ParseRecordType upper-cases a record type and checks adapters accept it
+func ParseRecordType(s string) (string, error) {
+	recordType := strings.ToUpper(s)
+	for _, t := range DnsRecordTypes {
+		if recordType == t {
+			return recordType, nil
+		}
+	}
+	return "", fmt.Errorf("%q is not a supported record type (valid: %s)", s, strings.Join(DnsRecordTypes, ", "))
+}
+
+// ParseRecordSpec parses a record written as TYPE:NAME:VALUE. It splits at
+// most twice so AAAA values keep their colons.
+func ParseRecordSpec(s string) (DnsRecord, error) {
+	parts := strings.SplitN(s, ":", 3)
+	if len(parts) != 3 || parts[1] == "" || parts[2] == "" {
+		return DnsRecord{}, fmt.Errorf("want TYPE:NAME:VALUE, got %q", s)
+	}
+	recordType, err := ParseRecordType(parts[0])
+	if err != nil {
+		return DnsRecord{}, err
+	}
+	return DnsRecord{Type: recordType, Name: parts[1], Value: parts[2]}, nil
+}
+
+type DnsListData struct {
+	Records []DnsRecord `json:"records"`
+}
+
 // Capabilities types
 type CapabilitiesData struct {
 	AdapterName    string   `json:"adapter_name"`
@@ -0,0 +1,163 @@
+package bridge
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Validator is implemented by params that can be checked before an adapter runs
+type Validator interface {
+	Validate() error
+}
+
+// invalidParams builds the error returned for params rejected locally
+func invalidParams(format string, args ...interface{}) *BridgeError {
+	return &BridgeError{
+		Code:    ErrInvalidParams,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+// validateParams runs params' Validate and checks its provider matches the
+// adapter being called, so mistakes fail without spawning a subprocess
+func validateParams(provider Provider, verb string, params interface{}, stdinData []byte) error {
+	if v, ok := params.(Validator); ok {
+		if err := v.Validate(); err != nil {
+			return invalidParams("invalid %s params: %s", verb, err)
+		}
+	}
+
+	var p struct {
+		Provider Provider `json:"provider"`
+	}
+	if json.Unmarshal(stdinData, &p) == nil && p.Provider != "" && p.Provider != provider {
+		return invalidParams("invalid %s params: provider %q doesn't match adapter %q", verb, p.Provider, provider)
+	}
+	return nil
+}
+
+// requireFields returns an error naming every field whose value is empty
+func requireFields(fields ...[2]string) error {
+	var missing []string
+	for _, f := range fields {
+		if strings.TrimSpace(f[1]) == "" {
+			missing = append(missing, f[0])
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing required fields: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+func validateProvider(p Provider) error {
+	if p == "" {
+		return fmt.Errorf("missing required fields: provider")
+	}
+	return ValidProviderName(string(p))
+}
+
+// Validate checks the provider name
+func (p AuthStartParams) Validate() error {
+	return validateProvider(p.Provider)
+}
+
+// Validate checks the provider and refresh token
+func (p AuthRefreshParams) Validate() error {
+	if err := validateProvider(p.Provider); err != nil {
+		return err
+	}
+	return requireFields([2]string{"refresh_token", p.RefreshToken})
+}
+
+// Validate checks the provider and token
+func (p FetchConfigParams) Validate() error {
+	if err := validateProvider(p.Provider); err != nil {
+		return err
+	}
+	return requireFields([2]string{"token", p.Token})
+}
+
+// Validate checks the provider, token, and that every variable has a key
+func (p SyncEnvParams) Validate() error {
+	if err := validateProvider(p.Provider); err != nil {
+		return err
+	}
+	if err := requireFields([2]string{"token", p.Token}); err != nil {
+		return err
+	}
+	for i, e := range p.EnvVars {
+		if strings.TrimSpace(e.Key) == "" {
+			return fmt.Errorf("env_vars[%d] has no key", i)
+		}
+	}
+	return nil
+}
+
+// Validate checks the provider, token, and project
+func (p DeployPreviewParams) Validate() error {
+	if err := validateProvider(p.Provider); err != nil {
+		return err
+	}
+	return requireFields([2]string{"token", p.Token}, [2]string{"project_id", p.ProjectID})
+}
+
+// Validate checks the provider, token, and deployment
+func (p DeployStatusParams) Validate() error {
+	if err := validateProvider(p.Provider); err != nil {
+		return err
+	}
+	return requireFields([2]string{"token", p.Token}, [2]string{"deployment_id", p.DeploymentID})
+}
+
+// Validate checks the provider, token, and record fields
+func (p DnsUpdateParams) Validate() error {
+	if err := validateProvider(p.Provider); err != nil {
+		return err
+	}
+	if err := requireFields(
+		[2]string{"token", p.Token},
+		[2]string{"domain", p.Domain},
+		[2]string{"record_type", p.RecordType},
+		[2]string{"record_name", p.RecordName},
+		[2]string{"record_value", p.RecordValue},
+	); err != nil {
+		return err
+	}
+	if p.TTL < 0 {
+		return fmt.Errorf("ttl must not be negative")
+	}
+	return nil
+}
+
+// Validate checks the provider, token, record, and rollback value
+func (p DnsRollbackParams) Validate() error {
+	if err := validateProvider(p.Provider); err != nil {
+		return err
+	}
+	return requireFields(
+		[2]string{"token", p.Token},
+		[2]string{"record_id", p.RecordID},
+		[2]string{"rollback_to", p.RollbackTo},
+	)
+}
+
+// Validate checks the provider and token
+func (p DomainsListParams) Validate() error {
+	if err := validateProvider(p.Provider); err != nil {
+		return err
+	}
+	return requireFields([2]string{"token", p.Token})
+}
+
+// Validate checks the provider, token, and domain
+func (p DnsListParams) Validate() error {
+	if err := validateProvider(p.Provider); err != nil {
+		return err
+	}
+	return requireFields(
+		[2]string{"token", p.Token},
+		[2]string{"domain", p.Domain},
+	)
+}
@@ -0,0 +1,71 @@
+package bridge
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateParams(t *testing.T) {
+	dns := DnsUpdateParams{
+		Provider:    "vercel",
+		Token:       "tok_1",
+		Domain:      "example.com",
+		RecordType:  "CNAME",
+		RecordName:  "www",
+		RecordValue: "app.example.dev",
+	}
+
+	tests := []struct {
+		name   string
+		verb   string
+		params interface{}
+		// wantErr is a substring of the expected error, "" when params are valid
+		wantErr string
+	}{
+		{name: "valid dns update", verb: "dns:update", params: dns},
+		{
+			name:    "missing record fields",
+			verb:    "dns:update",
+			params:  DnsUpdateParams{Provider: "vercel", Token: "tok_1", Domain: "example.com"},
+			wantErr: "missing required fields: record_type, record_name, record_value",
+		},
+		{
+			name:    "negative ttl",
+			verb:    "dns:update",
+			params:  func() DnsUpdateParams { p := dns; p.TTL = -1; return p }(),
+			wantErr: "ttl must not be negative",
+		},
+		{name: "blank token", verb: "fetch:config", params: FetchConfigParams{Provider: "vercel", Token: "  "}, wantErr: "missing required fields: token"},
+		{name: "invalid provider", verb: "fetch:config", params: FetchConfigParams{Provider: "../vercel", Token: "tok_1"}, wantErr: `invalid provider "../vercel"`},
+		{name: "provider mismatch", verb: "fetch:config", params: FetchConfigParams{Provider: "netlify", Token: "tok_1"}, wantErr: `provider "netlify" doesn't match adapter "vercel"`},
+		{name: "env var without key", verb: "sync:env", params: SyncEnvParams{Provider: "vercel", Token: "tok_1", EnvVars: []EnvVar{{Key: "A"}, {Key: " "}}}, wantErr: "env_vars[1] has no key"},
+		{name: "rollback without value", verb: "dns:rollback", params: DnsRollbackParams{Provider: "vercel", Token: "tok_1", RecordID: "rec_1"}, wantErr: "rollback_to"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, calls := scriptBridge(t, `cat > /dev/null
+echo '{"ok":true,"data":{}}'
+`, "vercel")
+
+			_, err := b.Execute(context.Background(), "vercel", tt.verb, tt.params)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Execute error: %v", err)
+				}
+				return
+			}
+
+			var bridgeErr *BridgeError
+			if !errors.As(err, &bridgeErr) || bridgeErr.Code != ErrInvalidParams || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Execute error = %v, want %s mentioning %q", err, ErrInvalidParams, tt.wantErr)
+			}
+			// Rejected before anything was spawned, capabilities included
+			if verbs := calledVerbs(t, calls); len(verbs) != 0 {
+				t.Errorf("adapter ran %v for invalid params", verbs)
+			}
+		})
+	}
+}
@@ -0,0 +1,257 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/johnhorton/deploy-tunnel/internal/bridge"
+	"github.com/johnhorton/deploy-tunnel/internal/config"
+	"github.com/johnhorton/deploy-tunnel/internal/registry"
+	"github.com/johnhorton/deploy-tunnel/ui"
+)
+
+func init() {
+	register(command{name: "adapters", run: (*runner).adapters, help: []helpLine{
+		{"adapters install", "Install provider adapters"},
+		{"adapters update", "Update installed adapters"},
+	}})
+}
+
+func (r *runner) adapters(ctx context.Context, args []string) error {
+	sub, args, err := subcommand("adapters", args, "install", "update")
+	if err != nil {
+		return err
+	}
+
+	parse := ParseAdaptersInstallFlags
+	if sub == "update" {
+		parse = ParseAdaptersUpdateFlags
+	}
+	opts, err := parse(args)
+	if err != nil {
+		return err
+	}
+	cmd := NewAdaptersCommand(r.cfg)
+	cmd.JSON = r.global.JSON
+	if sub == "update" {
+		return cmd.Update(ctx, opts)
+	}
+	return cmd.Install(ctx, opts)
+}
+
+type AdaptersCommand struct {
+	cfg config.Config
+
+	// JSON prints the install results as JSON
+	JSON bool
+}
+
+// NewAdaptersCommand takes the whole config: installs go to its adapters
+// path and are verified with a bridge built from it
+func NewAdaptersCommand(cfg config.Config) *AdaptersCommand {
+	return &AdaptersCommand{
+		cfg: cfg,
+	}
+}
+
+// AdapterSpec is a provider and an optional version, from provider[@version]
+type AdapterSpec struct {
+	Provider string
+	Version  string
+}
+
+// AdaptersOptions holds the arguments for `dt adapters install` and
+// `dt adapters update`
+type AdaptersOptions struct {
+	Specs []AdapterSpec
+}
+
+// ParseAdaptersInstallFlags parses `dt adapters install <provider>[@version]...`
+func ParseAdaptersInstallFlags(args []string) (AdaptersOptions, error) {
+	opts, err := parseAdapterSpecs("adapters install", args, true)
+	if err != nil {
+		return opts, err
+	}
+	if len(opts.Specs) == 0 {
+		return opts, fmt.Errorf("missing provider (usage: dt adapters install <provider>[@version]...)")
+	}
+	return opts, nil
+}
+
+// ParseAdaptersUpdateFlags parses `dt adapters update [provider...]`
+func ParseAdaptersUpdateFlags(args []string) (AdaptersOptions, error) {
+	return parseAdapterSpecs("adapters update", args, false)
+}
+
+func parseAdapterSpecs(name string, args []string, allowVersion bool) (AdaptersOptions, error) {
+	var opts AdaptersOptions
+
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return opts, err
+	}
+
+	for _, arg := range fs.Args() {
+		provider, version, hasVersion := strings.Cut(arg, "@")
+		if err := bridge.ValidProviderName(provider); err != nil {
+			return opts, err
+		}
+		if hasVersion && (!allowVersion || version == "") {
+			return opts, fmt.Errorf("unexpected arguments: %s", arg)
+		}
+		opts.Specs = append(opts.Specs, AdapterSpec{Provider: provider, Version: version})
+	}
+	return opts, nil
+}
+
+// adapterInstallResult is the JSON form of one installed or updated adapter
+type adapterInstallResult struct {
+	Provider string `json:"provider"`
+	Version  string `json:"version,omitempty"`
+	// Previous is the version an update replaced, if it was recorded
+	Previous string `json:"previous,omitempty"`
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Install downloads each adapter from the registry, verifies it answers
+// capabilities, and records the installed version
+func (c *AdaptersCommand) Install(ctx context.Context, opts AdaptersOptions) error {
+	return c.install(ctx, opts.Specs, false)
+}
+
+// Update installs the registry's latest release of each named adapter, or of
+// every adapter that was installed from the registry when none are named.
+// Adapters already on the latest release are left alone.
+func (c *AdaptersCommand) Update(ctx context.Context, opts AdaptersOptions) error {
+	specs := opts.Specs
+	if len(specs) == 0 {
+		adaptersPath, err := bridge.InstallAdaptersPath(c.cfg.AdaptersPath)
+		if err != nil {
+			return err
+		}
+		if _, err := os.Stat(adaptersPath); err == nil {
+			providers, err := bridge.InstalledAdapters(adaptersPath)
+			if err != nil {
+				return err
+			}
+			for _, p := range providers {
+				if installed, _ := registry.ReadInstalled(adaptersPath, string(p)); installed != nil {
+					specs = append(specs, AdapterSpec{Provider: string(p)})
+				}
+			}
+		}
+		if len(specs) == 0 {
+			if c.JSON {
+				return printJSON([]adapterInstallResult{})
+			}
+			printHeader()
+			fmt.Println(ui.Info("No adapters were installed from the registry; run: dt adapters install <provider>"))
+			return nil
+		}
+	}
+	return c.install(ctx, specs, true)
+}
+
+// install fetches the registry index once, then installs each spec in turn,
+// carrying on past failures. With update set, adapters already on the
+// latest release are skipped.
+func (c *AdaptersCommand) install(ctx context.Context, specs []AdapterSpec, update bool) error {
+	adaptersPath, err := bridge.InstallAdaptersPath(c.cfg.AdaptersPath)
+	if err != nil {
+		return err
+	}
+	client, err := registry.NewClient(c.cfg.RegistryURL)
+	if err != nil {
+		return err
+	}
+
+	if !c.JSON {
+		printHeader()
+		printProgress("Fetching the adapter registry index...")
+	}
+	index, err := client.FetchIndex(ctx)
+	if err != nil {
+		return err
+	}
+
+	results := make([]adapterInstallResult, 0, len(specs))
+	failed := 0
+	for _, spec := range specs {
+		result := adapterInstallResult{Provider: spec.Provider}
+		previous, _ := registry.ReadInstalled(adaptersPath, spec.Provider)
+		if previous != nil {
+			result.Previous = previous.Version
+		}
+
+		if update && previous != nil {
+			if latest, _, err := index.Resolve(spec.Provider, ""); err == nil && latest == previous.Version {
+				result.Version = latest
+				result.Status = "up to date"
+				results = append(results, result)
+				continue
+			}
+		}
+
+		if !c.JSON {
+			printProgress(fmt.Sprintf("Installing %s adapter...", spec.Provider))
+		}
+		installed, err := client.Install(ctx, index, adaptersPath, spec.Provider, spec.Version, c.verifier(adaptersPath))
+		if err != nil {
+			failed++
+			result.Status = "failed"
+			result.Error = err.Error()
+		} else {
+			result.Version = installed.Version
+			result.Status = "installed"
+			if previous != nil && previous.Version != installed.Version {
+				result.Status = "updated"
+			}
+		}
+		results = append(results, result)
+	}
+
+	if c.JSON {
+		if err := printJSON(results); err != nil {
+			return err
+		}
+	} else {
+		rows := make([][]string, len(results))
+		for i, r := range results {
+			rows[i] = []string{r.Provider, orDash(r.Version), orDash(r.Previous), r.Status}
+		}
+		fmt.Println()
+		fmt.Println(ui.Table([]string{"ADAPTER", "VERSION", "PREVIOUS", "STATUS"}, rows))
+		fmt.Println()
+		for _, r := range results {
+			if r.Error != "" {
+				fmt.Println(ui.Error(r.Error))
+			}
+		}
+		fmt.Println(ui.KeyValue("Adapters path", adaptersPath))
+		fmt.Println()
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to install %d of %d adapters", failed, len(specs))
+	}
+	return nil
+}
+
+// verifier checks an installed adapter answers capabilities, using a fresh
+// bridge so no cached capabilities from the old adapter are reused
+func (c *AdaptersCommand) verifier(adaptersPath string) registry.Verifier {
+	return func(ctx context.Context, provider string) error {
+		cfg := c.cfg
+		cfg.AdaptersPath = adaptersPath
+		br, err := cfg.NewBridge()
+		if err != nil {
+			return err
+		}
+		_, err = br.Capabilities(ctx, bridge.Provider(provider))
+		return err
+	}
+}
@@ -3,19 +3,72 @@ package cli
 import (
 	"bufio"
 	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/johnhorton/deploy-tunnel/internal/bridge"
 	"github.com/johnhorton/deploy-tunnel/internal/keychain"
+	"github.com/johnhorton/deploy-tunnel/internal/redact"
+	"github.com/johnhorton/deploy-tunnel/internal/validate"
 	"github.com/johnhorton/deploy-tunnel/ui"
 )
 
+func init() {
+	register(command{name: "auth", run: (*runner).auth, help: []helpLine{
+		{"auth <provider>...", "Store provider credentials"},
+		{"auth list", "List stored credentials"},
+		{"auth revoke", "Remove stored credentials"},
+	}})
+}
+
+func (r *runner) auth(ctx context.Context, args []string) error {
+	if len(args) > 0 && args[0] == "list" {
+		cmd := NewAuthCommand(nil)
+		cmd.JSON = r.global.JSON
+		return cmd.List()
+	}
+	if len(args) > 0 && args[0] == "revoke" {
+		opts, err := ParseRevokeFlags(args[1:])
+		if err != nil {
+			return err
+		}
+		cmd := NewAuthCommand(nil)
+		cmd.JSON = r.global.JSON
+		return cmd.RunRevoke(opts)
+	}
+
+	opts, err := ParseAuthFlags(args)
+	if err != nil {
+		return err
+	}
+	br, err := r.newBridge()
+	if err != nil {
+		return err
+	}
+	cmd := NewAuthCommand(br)
+	cmd.JSON = r.global.JSON
+	return cmd.RunWithOptions(ctx, opts)
+}
+
 type AuthCommand struct {
 	bridge *bridge.Bridge
+
+	// JSON makes every subcommand print machine-readable output
+	JSON bool
+}
+
+// providerStatus is the JSON form of one `auth list` entry
+type providerStatus struct {
+	Provider      string              `json:"provider"`
+	Authenticated bool                `json:"authenticated"`
+	Meta          *keychain.TokenMeta `json:"meta,omitempty"`
 }
 
 func NewAuthCommand(br *bridge.Bridge) *AuthCommand {
@@ -24,38 +77,277 @@ func NewAuthCommand(br *bridge.Bridge) *AuthCommand {
 	}
 }
 
+// AuthOptions holds the arguments for `dt auth <provider>...`
+type AuthOptions struct {
+	Provider string
+	// Providers lists every provider named, in order; Provider is the first.
+	// With more than one, each is authenticated in turn.
+	Providers  []string
+	TokenStdin bool
+	Label      string
+}
+
+// ParseAuthFlags parses `dt auth <provider>... [--token-stdin] [--label name]`
+func ParseAuthFlags(args []string) (AuthOptions, error) {
+	var opts AuthOptions
+
+	// flag stops at the first positional, so take leading providers first
+	for len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		opts.Providers = appendProvider(opts.Providers, args[0])
+		args = args[1:]
+	}
+
+	fs := flag.NewFlagSet("auth", flag.ContinueOnError)
+	fs.BoolVar(&opts.TokenStdin, "token-stdin", false, "read the token from the first line of stdin")
+	fs.StringVar(&opts.Label, "label", "", "account label shown by auth list")
+
+	if err := fs.Parse(args); err != nil {
+		return opts, err
+	}
+	for _, arg := range fs.Args() {
+		if strings.HasPrefix(arg, "-") {
+			return opts, fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+		}
+		opts.Providers = appendProvider(opts.Providers, arg)
+	}
+	if len(opts.Providers) == 0 {
+		return opts, fmt.Errorf("missing provider (usage: dt auth <provider>... [--token-stdin])")
+	}
+	if opts.TokenStdin && len(opts.Providers) > 1 {
+		return opts, fmt.Errorf("--token-stdin takes a single provider; set DEPLOY_TUNNEL_<PROVIDER>_TOKEN for each instead")
+	}
+	opts.Provider = opts.Providers[0]
+	return opts, nil
+}
+
+// appendProvider adds name unless it's already listed
+func appendProvider(providers []string, name string) []string {
+	for _, p := range providers {
+		if p == name {
+			return providers
+		}
+	}
+	return append(providers, name)
+}
+
+// TokenEnvVar is the environment variable that supplies a provider's token,
+// e.g. DEPLOY_TUNNEL_VERCEL_TOKEN
+func TokenEnvVar(provider bridge.Provider) string {
+	return "DEPLOY_TUNNEL_" + strings.ToUpper(string(provider)) + "_TOKEN"
+}
+
 func (c *AuthCommand) Run(ctx context.Context, provider string) error {
-	fmt.Println(ui.Header())
+	return c.RunWithOptions(ctx, AuthOptions{Provider: provider})
+}
+
+// RunWithOptions authenticates a provider, or each of opts.Providers in turn.
+// A token from --token-stdin or the provider's env var skips the prompt and
+// browser, so it can run unattended.
+func (c *AuthCommand) RunWithOptions(ctx context.Context, opts AuthOptions) error {
+	if len(opts.Providers) > 1 {
+		return c.runMany(ctx, opts)
+	}
+
+	prov, err := c.bridge.ParseProvider(opts.Provider)
+	if err != nil {
+		return err
+	}
+
+	token, found, err := nonInteractiveToken(prov, opts.TokenStdin, os.Stdin)
+	if err != nil {
+		return err
+	}
+	if !found && c.JSON {
+		return fmt.Errorf("--json cannot prompt for a token; pass --token-stdin or set %s", TokenEnvVar(prov))
+	}
+	if !found && !isTerminal(os.Stdin) {
+		return fmt.Errorf("stdin is not a terminal; pass --token-stdin or set %s", TokenEnvVar(prov))
+	}
+
+	if !c.JSON {
+		printHeader()
+	}
+	result, err := c.authenticate(ctx, prov, token, found, opts.Label)
+	if err != nil {
+		return err
+	}
+	if c.JSON {
+		return printJSON(result)
+	}
+	return nil
+}
+
+// authResult is the JSON form of one `auth <provider>` outcome
+type authResult struct {
+	Provider       string `json:"provider"`
+	Authenticated  bool   `json:"authenticated"`
+	AdapterName    string `json:"adapter_name,omitempty"`
+	AdapterVersion string `json:"adapter_version,omitempty"`
+	// Backend is where the token was stored, e.g. keychain or file
+	Backend string `json:"backend,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// runMany authenticates each provider in opts.Providers, carrying on past
+// failures, then prints which ones succeeded
+func (c *AuthCommand) runMany(ctx context.Context, opts AuthOptions) error {
+	providers := make([]bridge.Provider, len(opts.Providers))
+	for i, name := range opts.Providers {
+		prov, err := c.bridge.ParseProvider(name)
+		if err != nil {
+			return err
+		}
+		providers[i] = prov
+	}
+
+	if !c.JSON {
+		printHeader()
+	}
+
+	results := make([]authResult, len(providers))
+	failures := make(map[bridge.Provider]error)
+	for i, prov := range providers {
+		if !c.JSON {
+			fmt.Println(ui.KeyStyle.Render(fmt.Sprintf("[%d/%d] %s", i+1, len(providers), prov)))
+		}
+
+		token, found, err := nonInteractiveToken(prov, false, os.Stdin)
+		switch {
+		case err != nil:
+		case !found && c.JSON:
+			err = fmt.Errorf("--json cannot prompt for a token; set %s", TokenEnvVar(prov))
+		case !found && !isTerminal(os.Stdin):
+			err = fmt.Errorf("stdin is not a terminal; set %s", TokenEnvVar(prov))
+		}
+		if err == nil {
+			results[i], err = c.authenticate(ctx, prov, token, found, opts.Label)
+		}
+		if err != nil {
+			failures[prov] = err
+			results[i] = authResult{Provider: string(prov), Error: redact.String(errorText(err))}
+			if !c.JSON {
+				fmt.Println(ui.Error(err.Error()))
+				fmt.Println()
+			}
+		}
+	}
+
+	if c.JSON {
+		if err := printJSON(results); err != nil {
+			return err
+		}
+		if len(failures) > 0 {
+			return reported(fmt.Errorf("failed to authenticate %d of %d providers", len(failures), len(providers)))
+		}
+		return nil
+	}
+
+	rows := make([][]string, len(providers))
+	for i, prov := range providers {
+		rows[i] = []string{string(prov), "authenticated", ""}
+		if err, failed := failures[prov]; failed {
+			rows[i] = []string{string(prov), "failed", err.Error()}
+		}
+	}
+	fmt.Println(ui.Table([]string{"PROVIDER", "RESULT", "ERROR"}, rows))
 	fmt.Println()
 
-	prov := bridge.Provider(provider)
+	if len(failures) > 0 {
+		return fmt.Errorf("failed to authenticate %d of %d providers", len(failures), len(providers))
+	}
+	return nil
+}
+
+// authenticate checks the adapter, prompts for a token unless one was found,
+// verifies it, and stores it. In JSON mode it prints nothing and never
+// prompts; the caller prints the result.
+func (c *AuthCommand) authenticate(ctx context.Context, prov bridge.Provider, token string, found bool, label string) (authResult, error) {
+	provider := string(prov)
 
 	// Check capabilities
-	fmt.Println(ui.Info(fmt.Sprintf("Checking %s adapter capabilities...", provider)))
+	if !c.JSON {
+		printProgress(fmt.Sprintf("Checking %s adapter capabilities...", provider))
+	}
 	caps, err := c.bridge.Capabilities(ctx, prov)
 	if err != nil {
-		return fmt.Errorf("failed to get capabilities: %w", err)
+		return authResult{}, fmt.Errorf("failed to get capabilities: %w", err)
+	}
+
+	if !c.JSON {
+		fmt.Println(ui.Success(fmt.Sprintf("Adapter: %s v%s", caps.AdapterName, caps.AdapterVersion)))
+		fmt.Println(ui.KeyValue("Auth Type", caps.AuthType))
+		fmt.Println()
+	}
+
+	if !found {
+		if token, err = c.promptToken(ctx, prov); err != nil {
+			return authResult{}, err
+		}
 	}
 
-	fmt.Println(ui.Success(fmt.Sprintf("Adapter: %s v%s", caps.AdapterName, caps.AdapterVersion)))
-	fmt.Println(ui.KeyValue("Auth Type", caps.AuthType))
+	if token == "" {
+		return authResult{}, fmt.Errorf("token cannot be empty")
+	}
+	// Only a heuristic, so let the provider have the final say
+	if warning := validate.TokenFormat(provider, token); warning != "" && !c.JSON {
+		fmt.Println(ui.Warning(warning))
+	}
+
+	// Verify token before persisting anything
+	if !c.JSON {
+		fmt.Println()
+		printProgress("Verifying credentials...")
+	}
+	if err := verifyToken(ctx, c.bridge, prov, token); err != nil {
+		return authResult{}, fmt.Errorf("failed to verify token: %w", err)
+	}
+
+	// Store token in keychain only once it is known to work
+	if !c.JSON {
+		printProgress("Storing credentials securely...")
+	}
+	meta := keychain.TokenMeta{CreatedAt: time.Now().UTC(), Label: label}
+	if err := keychain.StoreWithMeta(provider, token, meta); err != nil {
+		return authResult{}, fmt.Errorf("failed to store token: %w", err)
+	}
+
+	result := authResult{
+		Provider:       provider,
+		Authenticated:  true,
+		AdapterName:    caps.AdapterName,
+		AdapterVersion: caps.AdapterVersion,
+		Backend:        keychain.BackendName(),
+	}
+	if c.JSON {
+		return result, nil
+	}
+
+	fmt.Println(ui.Success("Authentication successful!"))
+	fmt.Println()
+	if keychain.BackendName() == keychain.BackendFile {
+		fmt.Println(ui.Info(fmt.Sprintf("Your credentials have been stored in an encrypted file in %s (system keychain unavailable)", keychain.Dir())))
+	} else {
+		fmt.Println(ui.Info("Your credentials have been securely stored in the system keychain"))
+	}
 	fmt.Println()
 
-	// Start auth flow
-	fmt.Println(ui.Info("Starting authentication..."))
+	return result, nil
+}
+
+// promptToken starts the provider's auth flow and reads the token from the terminal
+func (c *AuthCommand) promptToken(ctx context.Context, prov bridge.Provider) (string, error) {
+	printProgress("Starting authentication...")
 	authData, err := c.bridge.AuthStart(ctx, bridge.AuthStartParams{
 		Provider: prov,
 	})
 	if err != nil {
-		return fmt.Errorf("failed to start auth: %w", err)
+		return "", fmt.Errorf("failed to start auth: %w", err)
 	}
 
-	var token string
-
+	fmt.Println()
 	if authData.AuthURL != "" {
 		// OAuth flow
-		fmt.Println()
-		fmt.Println(ui.Info("Opening browser for authentication..."))
+		printProgress("Opening browser for authentication...")
 		fmt.Println(ui.KeyValue("URL", authData.AuthURL))
 		fmt.Println()
 
@@ -66,74 +358,115 @@ func (c *AuthCommand) Run(ctx context.Context, provider string) error {
 
 		fmt.Println()
 		fmt.Print(ui.KeyStyle.Render("? ") + "Paste the token from your browser: ")
-
-		reader := bufio.NewReader(os.Stdin)
-		token, err = reader.ReadString('\n')
-		if err != nil {
-			return fmt.Errorf("failed to read token: %w", err)
-		}
-		token = strings.TrimSpace(token)
 	} else {
 		// Direct token input
-		fmt.Println()
 		fmt.Println(ui.Info("This provider requires a personal access token"))
 		fmt.Print(ui.KeyStyle.Render("? ") + "Enter your token: ")
+	}
 
-		reader := bufio.NewReader(os.Stdin)
-		token, err = reader.ReadString('\n')
-		if err != nil {
-			return fmt.Errorf("failed to read token: %w", err)
+	token, err := readLine(os.Stdin)
+	if err != nil {
+		return "", fmt.Errorf("failed to read token: %w", err)
+	}
+	return token, nil
+}
+
+// nonInteractiveToken returns a token from stdin when fromStdin is set,
+// otherwise from the provider's env var; found is false if neither applies
+func nonInteractiveToken(provider bridge.Provider, fromStdin bool, stdin io.Reader) (token string, found bool, err error) {
+	if fromStdin {
+		token, err := readLine(stdin)
+		if err != nil && err != io.EOF {
+			return "", false, fmt.Errorf("failed to read token from stdin: %w", err)
 		}
-		token = strings.TrimSpace(token)
+		if token == "" {
+			return "", false, fmt.Errorf("--token-stdin was set but stdin had no token")
+		}
+		return token, true, nil
 	}
 
-	if token == "" {
-		return fmt.Errorf("token cannot be empty")
+	if token := strings.TrimSpace(os.Getenv(TokenEnvVar(provider))); token != "" {
+		return token, true, nil
 	}
+	return "", false, nil
+}
 
-	// Store token in keychain
-	fmt.Println()
-	fmt.Println(ui.Info("Storing credentials securely..."))
-	if err := keychain.Store(provider, token); err != nil {
-		return fmt.Errorf("failed to store token: %w", err)
+// readLine reads one line, trimmed; a final line without a newline is returned with io.EOF
+func readLine(r io.Reader) (string, error) {
+	line, err := bufio.NewReader(r).ReadString('\n')
+	return strings.TrimSpace(line), err
+}
+
+// isTerminal reports whether f is an interactive terminal rather than a pipe or file
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
 	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
 
-	// Verify token by fetching capabilities with it
-	fmt.Println(ui.Info("Verifying credentials..."))
-	_, err = c.bridge.FetchConfig(ctx, bridge.FetchConfigParams{
-		Provider: prov,
+// verifyToken checks a token against the provider by fetching config.
+// INVALID_PARAMS means the token was accepted and only the project_id is missing.
+func verifyToken(ctx context.Context, br *bridge.Bridge, provider bridge.Provider, token string) error {
+	// Refreshing would verify the stored credential instead of this one
+	_, err := br.FetchConfig(bridge.WithoutRefresh(ctx), bridge.FetchConfigParams{
+		Provider: provider,
 		Token:    token,
 	})
-	if err != nil {
-		// If fetch fails due to missing project_id, that's OK - token is valid
-		if bridgeErr, ok := err.(*bridge.BridgeError); ok && bridgeErr.Code == bridge.ErrInvalidParams {
-			fmt.Println(ui.Success("Authentication successful!"))
-			fmt.Println()
-			return nil
-		}
-		// Otherwise, token might be invalid
-		return fmt.Errorf("failed to verify token: %w", err)
+	var bridgeErr *bridge.BridgeError
+	if errors.As(err, &bridgeErr) && bridgeErr.Code == bridge.ErrInvalidParams {
+		return nil
 	}
+	return err
+}
 
-	fmt.Println(ui.Success("Authentication successful!"))
-	fmt.Println()
-	fmt.Println(ui.Info("Your credentials have been securely stored in the system keychain"))
-	fmt.Println()
-
-	return nil
+// loadToken reads a provider's stored token, explaining how to add one if missing
+func loadToken(provider bridge.Provider) (string, error) {
+	token, err := keychain.Get(string(provider))
+	if err != nil || token == "" {
+		return "", &bridge.BridgeError{
+			Code:    bridge.ErrAuthRequired,
+			Message: fmt.Sprintf("no credentials for %s (run: dt auth %s)", provider, provider),
+		}
+	}
+	return token, nil
 }
 
 func (c *AuthCommand) List() error {
-	fmt.Println(ui.Header())
-	fmt.Println()
-	fmt.Println(ui.Info("Stored credentials:"))
-	fmt.Println()
-
 	providers, err := keychain.List()
 	if err != nil {
 		return fmt.Errorf("failed to list credentials: %w", err)
 	}
 
+	if c.JSON {
+		stored := make(map[string]bool, len(providers))
+		for _, p := range providers {
+			stored[p] = true
+		}
+		statuses := make([]providerStatus, 0, len(bridge.AllProviders))
+		for _, p := range bridge.AllProviders {
+			status := providerStatus{Provider: string(p), Authenticated: stored[string(p)]}
+			if status.Authenticated {
+				status.Meta, _ = keychain.GetMeta(string(p))
+			}
+			statuses = append(statuses, status)
+			delete(stored, string(p))
+		}
+		// Providers with stored credentials but no built-in support
+		for _, p := range providers {
+			if stored[p] {
+				meta, _ := keychain.GetMeta(p)
+				statuses = append(statuses, providerStatus{Provider: p, Authenticated: true, Meta: meta})
+			}
+		}
+		return printJSON(statuses)
+	}
+
+	printHeader()
+	fmt.Println(ui.Info("Stored credentials:"))
+	fmt.Println()
+
 	if len(providers) == 0 {
 		fmt.Println(ui.Warning("No credentials stored"))
 		fmt.Println()
@@ -142,19 +475,71 @@ func (c *AuthCommand) List() error {
 		return nil
 	}
 
+	now := time.Now()
 	for _, provider := range providers {
-		fmt.Println(ui.Success(provider))
+		meta, _ := keychain.GetMeta(provider)
+		if meta == nil {
+			fmt.Println(ui.Success(provider))
+			continue
+		}
+		line := ui.Success(provider) + " " + ui.InfoStyle.Render(meta.Describe(now))
+		if meta.Expired(now) {
+			line = ui.Warning(provider) + " " + ui.InfoStyle.Render(meta.Describe(now))
+		}
+		fmt.Println(line)
 	}
 	fmt.Println()
 
 	return nil
 }
 
-func (c *AuthCommand) Revoke(provider string) error {
-	fmt.Println(ui.Header())
-	fmt.Println()
+// Revoke removes a provider's credentials, metadata, and refresh token after
+// a y/N confirmation. yes skips the prompt for scripting (the --yes flag).
+func (c *AuthCommand) Revoke(provider string, yes bool) error {
+	// No adapter is needed, so credentials outlive an uninstalled one
+	if err := bridge.ValidProviderName(provider); err != nil {
+		return err
+	}
+
+	if c.JSON && !yes {
+		return fmt.Errorf("--json cannot prompt for confirmation; pass --yes")
+	}
+
+	stored, err := keychain.Exists(provider)
+	if err != nil {
+		return fmt.Errorf("failed to read credentials: %w", err)
+	}
+
+	if c.JSON {
+		if stored {
+			if err := keychain.Purge(provider); err != nil {
+				return fmt.Errorf("failed to delete credentials: %w", err)
+			}
+		}
+		return printJSON(map[string]interface{}{"provider": provider, "revoked": stored})
+	}
+
+	printHeader()
+
+	if !stored {
+		fmt.Println(ui.Warning(fmt.Sprintf("No stored credentials for %s", provider)))
+		fmt.Println()
+		return nil
+	}
+
+	if !yes {
+		ok, err := confirm(fmt.Sprintf("Remove stored credentials for %s?", provider))
+		if err != nil {
+			return fmt.Errorf("failed to read confirmation: %w", err)
+		}
+		if !ok {
+			fmt.Println(ui.Info("Revoke cancelled, credentials left unchanged"))
+			fmt.Println()
+			return nil
+		}
+	}
 
-	if err := keychain.Delete(provider); err != nil {
+	if err := keychain.Purge(provider); err != nil {
 		return fmt.Errorf("failed to delete credentials: %w", err)
 	}
 
@@ -164,6 +549,149 @@ func (c *AuthCommand) Revoke(provider string) error {
 	return nil
 }
 
+// RevokeOptions holds the arguments for `dt auth revoke`
+type RevokeOptions struct {
+	Provider string
+	// All revokes every stored provider instead of Provider
+	All bool
+	Yes bool
+}
+
+// ParseRevokeFlags parses `dt auth revoke <provider>|--all [--yes]`
+func ParseRevokeFlags(args []string) (RevokeOptions, error) {
+	var opts RevokeOptions
+
+	// flag stops at the first positional, so take the provider first
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		opts.Provider = args[0]
+		args = args[1:]
+	}
+
+	fs := flag.NewFlagSet("auth revoke", flag.ContinueOnError)
+	fs.BoolVar(&opts.All, "all", false, "revoke every stored provider")
+	fs.BoolVar(&opts.Yes, "yes", false, "skip the confirmation prompt")
+
+	if err := fs.Parse(args); err != nil {
+		return opts, err
+	}
+	if fs.NArg() == 1 && opts.Provider == "" {
+		opts.Provider = fs.Arg(0)
+	} else if fs.NArg() > 0 {
+		return opts, fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+
+	switch {
+	case opts.All && opts.Provider != "":
+		return opts, fmt.Errorf("--all revokes every provider; don't name one")
+	case !opts.All && opts.Provider == "":
+		return opts, fmt.Errorf("missing provider (usage: dt auth revoke <provider>|--all [--yes])")
+	}
+	return opts, nil
+}
+
+// RunRevoke revokes one provider or, with --all, every stored one
+func (c *AuthCommand) RunRevoke(opts RevokeOptions) error {
+	if opts.All {
+		return c.RevokeAll(opts.Yes)
+	}
+	return c.Revoke(opts.Provider, opts.Yes)
+}
+
+// revokeResult is the JSON form of one `auth revoke --all` entry
+type revokeResult struct {
+	Provider string `json:"provider"`
+	Revoked  bool   `json:"revoked"`
+	Error    string `json:"error,omitempty"`
+}
+
+// RevokeAll removes the credentials, metadata, and refresh tokens of every
+// stored provider after one y/N confirmation, carrying on past failures.
+// yes skips the prompt. In JSON mode each entry reports its own result,
+// and the command still fails if any provider could not be revoked.
+func (c *AuthCommand) RevokeAll(yes bool) error {
+	if c.JSON && !yes {
+		return fmt.Errorf("--json cannot prompt for confirmation; pass --yes")
+	}
+
+	providers, err := keychain.List()
+	if err != nil {
+		return fmt.Errorf("failed to list credentials: %w", err)
+	}
+
+	if !c.JSON {
+		printHeader()
+		if len(providers) == 0 {
+			fmt.Println(ui.Warning("No credentials stored"))
+			fmt.Println()
+			return nil
+		}
+		if !yes {
+			ok, err := confirm(fmt.Sprintf("Remove stored credentials for all %d providers (%s)?", len(providers), strings.Join(providers, ", ")))
+			if err != nil {
+				return fmt.Errorf("failed to read confirmation: %w", err)
+			}
+			if !ok {
+				fmt.Println(ui.Info("Revoke cancelled, credentials left unchanged"))
+				fmt.Println()
+				return nil
+			}
+		}
+	}
+
+	results := make([]revokeResult, len(providers))
+	failed := 0
+	for i, provider := range providers {
+		results[i] = revokeResult{Provider: provider, Revoked: true}
+		if err := keychain.Purge(provider); err != nil {
+			results[i] = revokeResult{Provider: provider, Error: redact.String(err.Error())}
+			failed++
+		}
+	}
+
+	if c.JSON {
+		if err := printJSON(results); err != nil {
+			return err
+		}
+		if failed > 0 {
+			return reported(fmt.Errorf("failed to revoke %d of %d providers", failed, len(providers)))
+		}
+		return nil
+	}
+
+	rows := make([][]string, len(results))
+	for i, r := range results {
+		rows[i] = []string{r.Provider, "revoked", ""}
+		if !r.Revoked {
+			rows[i] = []string{r.Provider, "failed", r.Error}
+		}
+	}
+	fmt.Println(ui.Table([]string{"PROVIDER", "RESULT", "ERROR"}, rows))
+	fmt.Println()
+
+	if failed > 0 {
+		return fmt.Errorf("failed to revoke %d of %d providers", failed, len(providers))
+	}
+	return nil
+}
+
+// confirm asks a y/N question on stdin; anything other than y/yes declines
+func confirm(message string) (bool, error) {
+	fmt.Print(ui.Confirm(message) + " ")
+
+	reader := bufio.NewReader(os.Stdin)
+	input, err := reader.ReadString('\n')
+	if err != nil {
+		return false, err
+	}
+
+	switch strings.ToLower(strings.TrimSpace(input)) {
+	case "y", "yes":
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
 // openBrowser opens a URL in the system's default browser
 func openBrowser(url string) error {
 	var cmd *exec.Cmd
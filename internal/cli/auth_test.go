@@ -0,0 +1,387 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/johnhorton/deploy-tunnel/internal/bridge"
+	"github.com/johnhorton/deploy-tunnel/internal/keychain"
+)
+
+func TestRevokeAll(t *testing.T) {
+	providers := []string{"cloudflare", "netlify", "vercel"}
+	for _, provider := range providers {
+		storeToken(t, provider)
+	}
+
+	cmd := NewAuthCommand(nil)
+	cmd.JSON = true
+	var runErr error
+	out := captureStdout(t, func() { runErr = cmd.RunRevoke(RevokeOptions{All: true, Yes: true}) })
+	if runErr != nil {
+		t.Fatalf("RunRevoke() = %v", runErr)
+	}
+
+	var results []revokeResult
+	if err := json.Unmarshal([]byte(out), &results); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, out)
+	}
+	if len(results) != len(providers) {
+		t.Fatalf("got %d results, want %d:\n%s", len(results), len(providers), out)
+	}
+	for i, r := range results {
+		if r.Provider != providers[i] || !r.Revoked || r.Error != "" {
+			t.Errorf("result %d = %+v, want %s revoked", i, r, providers[i])
+		}
+	}
+
+	stored, err := keychain.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored) != 0 {
+		t.Errorf("providers still stored after --all: %v", stored)
+	}
+}
+
+func TestRevoke(t *testing.T) {
+	tests := []struct {
+		name   string
+		answer string
+		yes    bool
+		// revoked is whether the credentials should be gone afterwards
+		revoked bool
+		want    string
+	}{
+		{name: "confirmed", answer: "y\n", revoked: true, want: "have been removed"},
+		{name: "declined", answer: "n\n", want: "Revoke cancelled"},
+		{name: "empty answer declines", answer: "\n", want: "Revoke cancelled"},
+		{name: "yes skips the prompt", yes: true, revoked: true, want: "have been removed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storeToken(t, "vercel")
+			if err := keychain.StoreRefreshToken("vercel", "rt_vercel"); err != nil {
+				t.Fatal(err)
+			}
+			withStdin(t, tt.answer)
+
+			var err error
+			out := captureStdout(t, func() { err = NewAuthCommand(nil).Revoke("vercel", tt.yes) })
+			if err != nil {
+				t.Fatalf("Revoke() = %v", err)
+			}
+			if !strings.Contains(out, tt.want) {
+				t.Errorf("output is missing %q:\n%s", tt.want, out)
+			}
+
+			stored, err := keychain.Exists("vercel")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stored == tt.revoked {
+				t.Errorf("credentials stored = %v, want %v", stored, !tt.revoked)
+			}
+			refresh, _ := keychain.GetRefreshToken("vercel")
+			if (refresh == "") != tt.revoked {
+				t.Errorf("refresh token = %q, want removed only when revoked", refresh)
+			}
+		})
+	}
+}
+
+func TestRevokeJSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		stored bool
+	}{
+		{name: "stored", stored: true},
+		{name: "nothing stored"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.stored {
+				storeToken(t, "vercel")
+			}
+			r := &runner{stateDB: newTestState(t)}
+
+			var got map[string]interface{}
+			runJSON(t, r, &got, "auth", "revoke", "vercel", "--yes")
+			if got["provider"] != "vercel" || got["revoked"] != tt.stored {
+				t.Errorf("auth revoke vercel = %v, want provider vercel, revoked %v", got, tt.stored)
+			}
+			if stored, err := keychain.Exists("vercel"); err != nil || stored {
+				t.Errorf("Exists(vercel) = %v, %v after revoke, want false", stored, err)
+			}
+		})
+	}
+
+	// JSON can't prompt, so --yes is required
+	r := &runner{global: GlobalOptions{JSON: true}, stateDB: newTestState(t)}
+	err := r.command(context.Background(), []string{"auth", "revoke", "vercel"})
+	if err == nil || !strings.Contains(err.Error(), "pass --yes") {
+		t.Errorf("auth revoke vercel --json without --yes = %v, want a --yes error", err)
+	}
+}
+
+func TestRevokeNothingStored(t *testing.T) {
+	var err error
+	out := captureStdout(t, func() { err = NewAuthCommand(nil).Revoke("netlify", true) })
+	if err != nil {
+		t.Fatalf("Revoke() = %v", err)
+	}
+	if !strings.Contains(out, "No stored credentials for netlify") {
+		t.Errorf("output doesn't report nothing stored:\n%s", out)
+	}
+}
+
+func TestRevokeInvalidProvider(t *testing.T) {
+	err := NewAuthCommand(nil).Revoke("../vercel", true)
+	if err == nil || !strings.Contains(err.Error(), "invalid provider") {
+		t.Errorf("Revoke(../vercel) = %v, want an invalid provider error", err)
+	}
+}
+
+func TestRevokeWithoutAdapter(t *testing.T) {
+	// Credentials stored for an adapter that's since been uninstalled
+	storeToken(t, "fly")
+
+	var err error
+	captureStdout(t, func() { err = NewAuthCommand(nil).Revoke("fly", true) })
+	if err != nil {
+		t.Fatalf("Revoke(fly) error: %v", err)
+	}
+	if exists, _ := keychain.Exists("fly"); exists {
+		t.Error("fly's credentials are still stored")
+	}
+}
+
+func TestAuthenticateStoresOnlyVerifiedTokens(t *testing.T) {
+	tests := []struct {
+		name string
+		// code is fetch:config's error code; empty means it succeeds
+		code   string
+		stored bool
+	}{
+		{name: "accepted", stored: true},
+		{name: "accepted without a project", code: "INVALID_PARAMS", stored: true},
+		{name: "rejected", code: "AUTH_FAILED"},
+		{name: "provider down", code: "PROVIDER_ERROR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetch := `echo '{"ok":true,"data":{"project":{"id":"prj_1","name":"app"}}}'`
+			if tt.code != "" {
+				fetch = fmt.Sprintf(`echo '{"ok":false,"error":{"code":"%s","message":"no"}}'`, tt.code)
+			}
+			br := fakeAdapter(t, "netlify", `case "$3" in
+capabilities) echo '{"ok":true,"data":{"adapter_name":"netlify","adapter_version":"1.0.0","supported_verbs":["capabilities","fetch:config"],"auth_type":"token"}}' ;;
+fetch:config) `+fetch+` ;;
+esac`)
+			t.Cleanup(func() { keychain.Purge("netlify") })
+
+			cmd := NewAuthCommand(br)
+			var err error
+			captureStdout(t, func() {
+				_, err = cmd.authenticate(context.Background(), bridge.ProviderNetlify, "nfp_0123456789abcdef", true, "")
+			})
+			if (err == nil) != tt.stored {
+				t.Errorf("authenticate() = %v, want success %v", err, tt.stored)
+			}
+
+			exists, existsErr := keychain.Exists("netlify")
+			if existsErr != nil {
+				t.Fatal(existsErr)
+			}
+			if exists != tt.stored {
+				t.Errorf("token stored = %v, want %v", exists, tt.stored)
+			}
+		})
+	}
+}
+
+func TestNonInteractiveToken(t *testing.T) {
+	tests := []struct {
+		name      string
+		fromStdin bool
+		stdin     string
+		env       string
+		want      string
+		found     bool
+		wantErr   string
+	}{
+		{name: "stdin is trimmed", fromStdin: true, stdin: "  tok_stdin \n", want: "tok_stdin", found: true},
+		{name: "stdin without newline", fromStdin: true, stdin: "tok_stdin", want: "tok_stdin", found: true},
+		{name: "only the first line", fromStdin: true, stdin: "tok_first\ntok_second\n", want: "tok_first", found: true},
+		{name: "stdin beats env", fromStdin: true, stdin: "tok_stdin\n", env: "tok_env", want: "tok_stdin", found: true},
+		{name: "empty stdin", fromStdin: true, stdin: " \n", env: "tok_env", wantErr: "stdin had no token"},
+		{name: "env is trimmed", env: " tok_env\n", want: "tok_env", found: true},
+		{name: "stdin ignored without the flag", stdin: "tok_stdin\n", env: "tok_env", want: "tok_env", found: true},
+		{name: "blank env", env: "  "},
+		{name: "nothing"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(TokenEnvVar(bridge.ProviderVercel), tt.env)
+
+			token, found, err := nonInteractiveToken(bridge.ProviderVercel, tt.fromStdin, strings.NewReader(tt.stdin))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("nonInteractiveToken() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || token != tt.want || found != tt.found {
+				t.Errorf("nonInteractiveToken() = %q, %v, %v, want %q, %v", token, found, err, tt.want, tt.found)
+			}
+		})
+	}
+}
+
+func TestParseAuthFlags(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string
+		wantErr string
+	}{
+		{name: "one provider", args: []string{"vercel", "--token-stdin"}, want: []string{"vercel"}},
+		{name: "providers around flags", args: []string{"vercel", "--label", "work", "netlify", "vercel"}, want: []string{"vercel", "netlify"}},
+		{name: "no provider", args: []string{"--token-stdin"}, wantErr: "missing provider"},
+		{name: "stdin with two providers", args: []string{"vercel", "netlify", "--token-stdin"}, wantErr: "--token-stdin takes a single provider"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := ParseAuthFlags(tt.args)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("ParseAuthFlags(%v) error = %v, want %q", tt.args, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || strings.Join(opts.Providers, ",") != strings.Join(tt.want, ",") || opts.Provider != tt.want[0] {
+				t.Errorf("ParseAuthFlags(%v) = %+v, %v, want providers %v", tt.args, opts, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthTokenFromEnv(t *testing.T) {
+	t.Setenv(TokenEnvVar(bridge.ProviderNetlify), "  tok_netlify_from_env_0123\n")
+	t.Cleanup(func() { keychain.Purge("netlify") })
+	// The adapter accepts any token
+	br := fakeAdapter(t, "netlify", `case "$3" in
+capabilities) echo '{"ok":true,"data":{"adapter_name":"netlify","adapter_version":"1.0.0","supported_verbs":["capabilities","fetch:config"],"auth_type":"token"}}' ;;
+*) echo '{"ok":true,"data":{"project":{"id":"p","name":"p","domain":""},"build":{"command":"","output_dir":""},"env":[]}}' ;;
+esac`)
+	// A pipe, not a terminal, so a missing token would fail rather than prompt
+	withStdin(t, "")
+
+	var err error
+	captureStdout(t, func() {
+		err = NewAuthCommand(br).RunWithOptions(context.Background(), AuthOptions{Provider: "netlify"})
+	})
+	if err != nil {
+		t.Fatalf("RunWithOptions() error: %v", err)
+	}
+	if token, err := keychain.Get("netlify"); err != nil || token != "tok_netlify_from_env_0123" {
+		t.Errorf("stored %q, %v, want the trimmed env token", token, err)
+	}
+}
+
+func TestAuthManyCarriesOnPastFailures(t *testing.T) {
+	// vercel has no token and stdin is a pipe, so only netlify can succeed
+	t.Setenv(TokenEnvVar(bridge.ProviderVercel), "")
+	t.Setenv(TokenEnvVar(bridge.ProviderNetlify), "tok_netlify_from_env_0123")
+	t.Cleanup(func() { keychain.Purge("netlify") })
+	withStdin(t, "")
+
+	adaptersPath, runtime := fakeAdapterFiles(t, "netlify", `case "$3" in
+capabilities) echo '{"ok":true,"data":{"adapter_name":"fake","adapter_version":"1.0.0","supported_verbs":["capabilities","fetch:config"],"auth_type":"token"}}' ;;
+*) echo '{"ok":true,"data":{"project":{"id":"p","name":"p","domain":""},"build":{"command":"","output_dir":""},"env":[]}}' ;;
+esac`)
+	installAdapter(t, adaptersPath, "vercel")
+	br := bridge.NewBridge(adaptersPath)
+	br.SetRuntime(runtime)
+
+	var err error
+	out := captureStdout(t, func() {
+		err = NewAuthCommand(br).RunWithOptions(context.Background(), AuthOptions{Provider: "vercel", Providers: []string{"vercel", "netlify"}})
+	})
+	if err == nil || !strings.Contains(err.Error(), "failed to authenticate 1 of 2 providers") {
+		t.Errorf("RunWithOptions() error = %v, want one of two failed", err)
+	}
+	if token, err := keychain.Get("netlify"); err != nil || token != "tok_netlify_from_env_0123" {
+		t.Errorf("Get(netlify) = %q, %v; vercel's failure stopped netlify", token, err)
+	}
+	if exists, _ := keychain.Exists("vercel"); exists {
+		t.Error("vercel was stored without a token")
+	}
+	for _, want := range []string{"[1/2] vercel", "[2/2] netlify", "stdin is not a terminal", "authenticated"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output is missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestAuthJSON(t *testing.T) {
+	const script = `case "$3" in
+capabilities) echo '{"ok":true,"data":{"adapter_name":"netlify","adapter_version":"1.2.0","supported_verbs":["capabilities","fetch:config"],"auth_type":"token"}}' ;;
+fetch:config) echo '{"ok":false,"error":{"code":"INVALID_PARAMS","message":"no project"}}' ;;
+esac`
+	t.Setenv(TokenEnvVar(bridge.ProviderNetlify), "nfp_0123456789abcdef")
+	t.Cleanup(func() { keychain.Purge("netlify") })
+	adaptersPath, runtime := fakeAdapterFiles(t, "netlify", script)
+	installAdapter(t, adaptersPath, "vercel")
+	r := &runner{br: bridge.NewBridge(adaptersPath)}
+	r.br.SetRuntime(runtime)
+
+	// runJSON fails unless stdout is only the JSON result
+	var result authResult
+	runJSON(t, r, &result, "auth", "netlify")
+	if !result.Authenticated || result.Provider != "netlify" || result.AdapterVersion != "1.2.0" || result.Backend == "" {
+		t.Errorf("auth netlify = %+v, want netlify authenticated with its adapter and backend", result)
+	}
+	if token, err := keychain.Get("netlify"); err != nil || token != "nfp_0123456789abcdef" {
+		t.Errorf("Get(netlify) = %q, %v, want the env token stored", token, err)
+	}
+
+	// Several providers give one entry each, and fail if any did
+	t.Setenv(TokenEnvVar(bridge.ProviderVercel), "")
+	var err error
+	out := captureStdout(t, func() {
+		err = r.command(context.Background(), []string{"auth", "netlify", "vercel"})
+	})
+	if err == nil || !strings.Contains(err.Error(), "failed to authenticate 1 of 2 providers") {
+		t.Errorf("auth netlify vercel error = %v, want one of two failed", err)
+	}
+	var results []authResult
+	if err := json.Unmarshal([]byte(out), &results); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, out)
+	}
+	if len(results) != 2 || !results[0].Authenticated || results[1].Authenticated || !strings.Contains(results[1].Error, "--json cannot prompt") {
+		t.Errorf("auth netlify vercel = %+v, want netlify authenticated and vercel refused a prompt", results)
+	}
+}
+
+func TestAuthJSONWontPrompt(t *testing.T) {
+	t.Setenv(TokenEnvVar(bridge.ProviderVercel), "")
+	r := &runner{global: GlobalOptions{JSON: true}, br: fakeAdapter(t, "vercel", "")}
+
+	var err error
+	out := captureStdout(t, func() { err = r.command(context.Background(), []string{"auth", "vercel"}) })
+	if err == nil || !strings.Contains(err.Error(), "--json cannot prompt for a token") {
+		t.Errorf("auth vercel error = %v, want a refusal to prompt", err)
+	}
+	if out != "" {
+		t.Errorf("printed %q before failing, want nothing", out)
+	}
+}
@@ -0,0 +1,189 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/johnhorton/deploy-tunnel/internal/state"
+	"github.com/johnhorton/deploy-tunnel/internal/tui"
+	"github.com/johnhorton/deploy-tunnel/ui"
+)
+
+func init() {
+	register(command{name: "config", run: (*runner).config, help: []helpLine{
+		{"config menu", "Reorder or hide dashboard menu items"},
+	}})
+}
+
+func (r *runner) config(ctx context.Context, args []string) error {
+	_, args, err := subcommand("config", args, "menu")
+	if err != nil {
+		return err
+	}
+	opts, err := ParseConfigMenuFlags(args)
+	if err != nil {
+		return err
+	}
+	// With no flags it only prints the menu
+	open := r.openState
+	if opts.Reset || opts.Order != nil || opts.Hide != nil {
+		open = func() (*state.DB, error) { return r.openWritable("dt config menu") }
+	}
+	db, err := open()
+	if err != nil {
+		return err
+	}
+	cmd := NewConfigCommand(db)
+	cmd.JSON = r.global.JSON
+	return cmd.Menu(opts)
+}
+
+type ConfigCommand struct {
+	state *state.DB
+
+	// JSON prints the resulting menu as JSON
+	JSON bool
+}
+
+func NewConfigCommand(stateDB *state.DB) *ConfigCommand {
+	return &ConfigCommand{
+		state: stateDB,
+	}
+}
+
+// ConfigMenuOptions holds the flags for `dt config menu`
+type ConfigMenuOptions struct {
+	// Order lists menu keys to show first; nil leaves the saved order alone
+	Order []string
+	// Hide lists menu keys to drop; nil leaves the saved list alone
+	Hide  []string
+	Reset bool
+}
+
+// ParseConfigMenuFlags parses `dt config menu [--order k1,k2] [--hide k1,k2] [--reset]`
+func ParseConfigMenuFlags(args []string) (ConfigMenuOptions, error) {
+	var opts ConfigMenuOptions
+	var order, hide string
+	known := strings.Join(tui.MenuKeys(), ", ")
+
+	fs := flag.NewFlagSet("config menu", flag.ContinueOnError)
+	fs.StringVar(&order, "order", "", "comma-separated menu keys to show first ("+known+")")
+	fs.StringVar(&hide, "hide", "", "comma-separated menu keys to hide; pass \"\" to show everything")
+	fs.BoolVar(&opts.Reset, "reset", false, "restore the default menu")
+
+	if err := fs.Parse(args); err != nil {
+		return opts, err
+	}
+	if fs.NArg() > 0 {
+		return opts, fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	if opts.Reset && (set["order"] || set["hide"]) {
+		return opts, fmt.Errorf("--reset can't be combined with --order or --hide")
+	}
+
+	var err error
+	if set["order"] {
+		if opts.Order, err = parseMenuKeys("--order", order); err != nil {
+			return opts, err
+		}
+	}
+	if set["hide"] {
+		if opts.Hide, err = parseMenuKeys("--hide", hide); err != nil {
+			return opts, err
+		}
+		for _, key := range opts.Hide {
+			if key == "quit" {
+				return opts, fmt.Errorf("invalid --hide: the quit item can't be hidden")
+			}
+		}
+	}
+	return opts, nil
+}
+
+// parseMenuKeys splits a comma-separated list of menu keys, rejecting
+// unknown ones. An empty list is returned as non-nil so it clears the pref.
+func parseMenuKeys(flagName, raw string) ([]string, error) {
+	known := make(map[string]bool)
+	for _, key := range tui.MenuKeys() {
+		known[key] = true
+	}
+
+	keys := []string{}
+	for _, key := range strings.Split(raw, ",") {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+		if !known[key] {
+			return nil, fmt.Errorf("invalid %s: unknown menu item %q (want one of %s)", flagName, key, strings.Join(tui.MenuKeys(), ", "))
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// configMenuResult is the JSON form of `dt config menu`
+type configMenuResult struct {
+	Order  []string `json:"order"`
+	Hidden []string `json:"hidden"`
+	Menu   []string `json:"menu"`
+}
+
+// Menu saves the dashboard menu order and hidden items, then prints the
+// resulting menu. With no flags it only prints the current menu.
+func (c *ConfigCommand) Menu(opts ConfigMenuOptions) error {
+	prefs, err := c.state.GetMenuPrefs()
+	if err != nil {
+		return err
+	}
+
+	changed := opts.Reset || opts.Order != nil || opts.Hide != nil
+	if opts.Reset {
+		prefs = state.MenuPrefs{}
+	}
+	if opts.Order != nil {
+		prefs.Order = opts.Order
+	}
+	if opts.Hide != nil {
+		prefs.Hidden = opts.Hide
+	}
+	if changed {
+		if err := c.state.SetMenuPrefs(prefs); err != nil {
+			return err
+		}
+	}
+
+	menu := tui.OrderMenuKeys(prefs)
+	if c.JSON {
+		return printJSON(configMenuResult{
+			Order:  nonNil(prefs.Order),
+			Hidden: nonNil(prefs.Hidden),
+			Menu:   menu,
+		})
+	}
+
+	printHeader()
+	if changed {
+		fmt.Println(ui.Success("Saved dashboard menu preferences"))
+		fmt.Println()
+	}
+
+	shown := make(map[string]bool, len(menu))
+	rows := make([][]string, 0, len(tui.MenuKeys()))
+	for i, key := range menu {
+		shown[key] = true
+		rows = append(rows, []string{fmt.Sprintf("%d", i+1), key, "shown"})
+	}
+	for _, key := range tui.MenuKeys() {
+		if !shown[key] {
+			rows = append(rows, []string{"-", key, "hidden"})
+		}
+	}
+	fmt.Println(ui.Table([]string{"#", "ITEM", "STATUS"}, rows))
+	return nil
+}
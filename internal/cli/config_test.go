@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+// menuKeys describes a parsed key list, telling nil (leave the pref alone)
+// from empty (clear it)
+func menuKeys(keys []string) string {
+	if keys == nil {
+		return "nil"
+	}
+	return "[" + strings.Join(keys, ",") + "]"
+}
+
+func TestParseConfigMenuFlags(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		wantOrder string
+		wantHide  string
+		wantReset bool
+		// wantErr is a substring of the expected error, "" when parsing succeeds
+		wantErr string
+	}{
+		{name: "show", wantOrder: "nil", wantHide: "nil"},
+		{name: "order", args: []string{"--order", "current, auth"}, wantOrder: "[current,auth]", wantHide: "nil"},
+		{name: "clear hidden", args: []string{"--hide", ""}, wantOrder: "nil", wantHide: "[]"},
+		{name: "reset", args: []string{"--reset"}, wantOrder: "nil", wantHide: "nil", wantReset: true},
+		{name: "unknown item", args: []string{"--order", "settings"}, wantErr: `unknown menu item "settings"`},
+		{name: "hide quit", args: []string{"--hide", "caps,quit"}, wantErr: "quit item can't be hidden"},
+		{name: "reset with order", args: []string{"--reset", "--order", "auth"}, wantErr: "--reset can't be combined"},
+		{name: "extra argument", args: []string{"auth"}, wantErr: "unexpected arguments: auth"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseConfigMenuFlags(tt.args)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("ParseConfigMenuFlags(%v) error = %v, want %q", tt.args, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseConfigMenuFlags(%v) error: %v", tt.args, err)
+			}
+			if menuKeys(got.Order) != tt.wantOrder || menuKeys(got.Hide) != tt.wantHide || got.Reset != tt.wantReset {
+				t.Errorf("ParseConfigMenuFlags(%v) = order %s, hide %s, reset %v; want %s, %s, %v",
+					tt.args, menuKeys(got.Order), menuKeys(got.Hide), got.Reset, tt.wantOrder, tt.wantHide, tt.wantReset)
+			}
+		})
+	}
+}
+
+func TestConfigMenu(t *testing.T) {
+	db := newTestState(t)
+	cmd := NewConfigCommand(db)
+	cmd.JSON = true
+
+	steps := []struct {
+		name string
+		opts ConfigMenuOptions
+		want string
+	}{
+		{name: "favorites", opts: ConfigMenuOptions{Order: []string{"current"}}, want: `"menu": ["current","init","list","auth","caps","quit"]`},
+		// An unset flag keeps the saved order
+		{name: "hide", opts: ConfigMenuOptions{Hide: []string{"caps"}}, want: `"menu": ["current","init","list","auth","quit"]`},
+		{name: "show", want: `"menu": ["current","init","list","auth","quit"]`},
+		{name: "reset", opts: ConfigMenuOptions{Reset: true}, want: `"menu": ["init","list","auth","caps","current","quit"]`},
+	}
+
+	for _, step := range steps {
+		var err error
+		out := captureStdout(t, func() { err = cmd.Menu(step.opts) })
+		if err != nil {
+			t.Fatalf("%s: Menu() error: %v", step.name, err)
+		}
+		compact := strings.Join(strings.Fields(out), "")
+		if want := strings.Join(strings.Fields(step.want), ""); !strings.Contains(compact, want) {
+			t.Errorf("%s: output = %s, want %s", step.name, out, step.want)
+		}
+	}
+}
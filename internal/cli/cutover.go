@@ -0,0 +1,545 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/johnhorton/deploy-tunnel/internal/bridge"
+	"github.com/johnhorton/deploy-tunnel/internal/dns"
+	"github.com/johnhorton/deploy-tunnel/internal/state"
+	"github.com/johnhorton/deploy-tunnel/ui"
+)
+
+func init() {
+	register(command{name: "cutover", run: (*runner).cutover, help: []helpLine{
+		{"cutover", "Point DNS at the target"},
+	}})
+}
+
+func (r *runner) cutover(ctx context.Context, args []string) error {
+	opts, err := ParseCutoverFlags(args)
+	if err != nil {
+		return err
+	}
+	db, br, err := r.open("dt cutover")
+	if err != nil {
+		return err
+	}
+	cmd := NewCutoverCommand(db, br)
+	cmd.JSON = r.global.JSON
+	return cmd.Run(ctx, opts)
+}
+
+// Bounds on how long cutover waits for a record to propagate
+const (
+	minPropagationWait = time.Minute
+	maxPropagationWait = 10 * time.Minute
+)
+
+// cutoverBridge is the bridge calls CutoverCommand needs
+type cutoverBridge interface {
+	DeployStatus(ctx context.Context, params bridge.DeployStatusParams) (*bridge.DeployPreviewData, error)
+	DnsUpdateBatch(ctx context.Context, provider bridge.Provider, token string, records []bridge.DnsUpdateParams) ([]bridge.DnsUpdateResult, error)
+	DnsRollback(ctx context.Context, params bridge.DnsRollbackParams) (*bridge.DnsRollbackData, error)
+	ListDnsRecords(ctx context.Context, provider bridge.Provider, token, domain string) ([]bridge.DnsRecord, error)
+	ParseProvider(name string) (bridge.Provider, error)
+}
+
+// propagationWaiter blocks until an updated record is live, or fails after timeout
+type propagationWaiter func(ctx context.Context, record state.DnsRecord, timeout time.Duration) error
+
+type CutoverCommand struct {
+	state       *state.DB
+	bridge      cutoverBridge
+	propagation propagationWaiter
+	// confirm asks before risky DNS changes; replaceable for scripting
+	confirm func(message string) (bool, error)
+
+	// JSON prints the cutover result as JSON
+	JSON bool
+}
+
+func NewCutoverCommand(stateDB *state.DB, br cutoverBridge) *CutoverCommand {
+	return &CutoverCommand{
+		state:       stateDB,
+		bridge:      br,
+		propagation: waitResolvers,
+		confirm:     confirm,
+	}
+}
+
+// waitResolvers polls public resolvers until the record resolves to its new value
+func waitResolvers(ctx context.Context, record state.DnsRecord, timeout time.Duration) error {
+	return dns.WaitForPropagation(ctx, record.Domain, record.RecordType, record.RecordName, record.RecordValue, nil, timeout)
+}
+
+// propagationTimeout allows for the provider's estimate plus the longest TTL
+// of the records being replaced at name, since resolvers keep serving those
+// until their TTL runs out. Without a diff the old TTLs are unknown, so it
+// allows the maximum.
+func propagationTimeout(estimate time.Duration, diff []bridge.DnsChange, name string) time.Duration {
+	if diff == nil {
+		return maxPropagationWait
+	}
+
+	oldTTL := 0
+	for _, change := range diff {
+		if change.Name == name && change.OldTTL > oldTTL {
+			oldTTL = change.OldTTL
+		}
+	}
+	timeout := estimate + time.Duration(oldTTL)*time.Second
+	if timeout < minPropagationWait {
+		timeout = minPropagationWait
+	}
+	if timeout > maxPropagationWait {
+		timeout = maxPropagationWait
+	}
+	return timeout
+}
+
+// CutoverRecord is a DNS change requested with --record TYPE:NAME:VALUE
+type CutoverRecord struct {
+	Type  string `json:"type"`
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// cutoverRecords collects repeated --record flags
+type cutoverRecords []CutoverRecord
+
+func (r *cutoverRecords) String() string {
+	parts := make([]string, len(*r))
+	for i, rec := range *r {
+		parts[i] = rec.Type + ":" + rec.Name + ":" + rec.Value
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (r *cutoverRecords) Set(s string) error {
+	record, err := bridge.ParseRecordSpec(s)
+	if err != nil {
+		return err
+	}
+	*r = append(*r, CutoverRecord{Type: record.Type, Name: record.Name, Value: record.Value})
+	return nil
+}
+
+// CutoverOptions holds the flags for `dt cutover`
+type CutoverOptions struct {
+	MigrationID  string
+	DeploymentID string
+	Records      []CutoverRecord
+	TTL          int
+	DryRun       bool
+	// Yes applies risky DNS changes without asking
+	Yes bool
+}
+
+// ParseCutoverFlags parses `dt cutover [--migration id] [--deployment id] --record TYPE:NAME:VALUE... [--ttl s] [--dry-run] [--yes]`
+func ParseCutoverFlags(args []string) (CutoverOptions, error) {
+	var opts CutoverOptions
+	var records cutoverRecords
+
+	fs := flag.NewFlagSet("cutover", flag.ContinueOnError)
+	fs.StringVar(&opts.MigrationID, "migration", "", "migration to cut over (defaults to the current one)")
+	fs.StringVar(&opts.DeploymentID, "deployment", "", "target deployment to switch to (defaults to the recorded preview)")
+	fs.Var(&records, "record", "DNS change as TYPE:NAME:VALUE; repeatable, at least one required")
+	fs.IntVar(&opts.TTL, "ttl", 300, "TTL in seconds for updated records")
+	fs.BoolVar(&opts.DryRun, "dry-run", false, "show the planned changes without making them")
+	fs.BoolVar(&opts.Yes, "yes", false, "apply risky DNS changes without asking")
+
+	if err := fs.Parse(args); err != nil {
+		return opts, err
+	}
+	if fs.NArg() > 0 {
+		return opts, fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+	if opts.TTL <= 0 {
+		return opts, fmt.Errorf("--ttl must be positive")
+	}
+	opts.Records = records
+	return opts, nil
+}
+
+// cutoverResult is the JSON form of `dt cutover`
+type cutoverResult struct {
+	MigrationID string                    `json:"migration_id"`
+	DryRun      bool                      `json:"dry_run"`
+	Status      string                    `json:"status"`
+	Deployment  *bridge.DeployPreviewData `json:"deployment"`
+	Planned     []CutoverRecord           `json:"planned"`
+	// Diff compares Planned with the zone; absent if it couldn't be read
+	Diff    []bridge.DnsChange `json:"diff,omitempty"`
+	Updated []state.DnsRecord  `json:"updated"`
+}
+
+// Run switches the migration's DNS to the target: it checks the target
+// deployment is ready, updates each record, and waits for propagation. If a
+// step fails, records already changed are rolled back and the migration is
+// marked failed.
+func (c *CutoverCommand) Run(ctx context.Context, opts CutoverOptions) error {
+	migration, err := loadMigration(ctx, c.state, opts.MigrationID)
+	if err != nil {
+		return err
+	}
+	// The migration's domain is the zone, so the obvious default, a CNAME to
+	// the deployment, would sit at the apex, where DNS doesn't allow one
+	if len(opts.Records) == 0 {
+		return fmt.Errorf("pass --record: %s is a zone apex, which can't be a CNAME to the deployment; e.g. --record A:@:<address> or --record CNAME:www:<deployment host>", migration.Domain)
+	}
+	provider, err := c.bridge.ParseProvider(migration.Target)
+	if err != nil {
+		return err
+	}
+	token, err := loadToken(provider)
+	if err != nil {
+		return err
+	}
+
+	deploymentID := opts.DeploymentID
+	if deploymentID == "" {
+		if deploymentID, err = c.recordedDeployment(ctx, migration.ID); err != nil {
+			return err
+		}
+	}
+
+	if !c.JSON {
+		printHeader()
+		if opts.DryRun {
+			printProgress(fmt.Sprintf("Planning cutover of %s to %s (dry run)...", migration.Domain, provider))
+		} else {
+			printProgress(fmt.Sprintf("Cutting %s over to %s...", migration.Domain, provider))
+		}
+		printGap()
+	}
+
+	result := cutoverResult{MigrationID: migration.ID, DryRun: opts.DryRun, Updated: []state.DnsRecord{}}
+
+	// Step 1: the target deployment must be live before anything points at it
+	c.step(migration.ID, "info", fmt.Sprintf("checking deployment %s", deploymentID))
+	deploy, err := c.bridge.DeployStatus(ctx, bridge.DeployStatusParams{
+		Provider:     provider,
+		Token:        token,
+		DeploymentID: deploymentID,
+	})
+	if err != nil {
+		return c.fail(migration, fmt.Errorf("failed to get deployment status: %w", err), opts.DryRun)
+	}
+	result.Deployment = deploy
+	if deploy.Status != bridge.DeploymentReady {
+		return c.fail(migration, fmt.Errorf("deployment %s is %s, not %s", deploy.DeploymentID, deploy.Status, bridge.DeploymentReady), opts.DryRun)
+	}
+
+	result.Planned = opts.Records
+
+	params := make([]bridge.DnsUpdateParams, len(result.Planned))
+	for i, planned := range result.Planned {
+		params[i] = bridge.DnsUpdateParams{
+			Domain:      migration.Domain,
+			RecordType:  planned.Type,
+			RecordName:  planned.Name,
+			RecordValue: planned.Value,
+			TTL:         opts.TTL,
+		}
+	}
+	result.Diff = c.diff(ctx, migration, provider, token, params)
+
+	if opts.DryRun {
+		result.Status = "planned"
+		if c.JSON {
+			return printJSON(result)
+		}
+		fmt.Println(ui.Success(fmt.Sprintf("Deployment %s is ready", deploy.DeploymentID)))
+		fmt.Println()
+		if result.Diff != nil {
+			c.printDiff(result.Diff)
+		} else {
+			c.printPlan(result.Planned, opts.TTL)
+		}
+		fmt.Println(ui.Info("Dry run: no DNS records were changed"))
+		fmt.Println()
+		return nil
+	}
+
+	risky := 0
+	for _, change := range result.Diff {
+		if change.Dangerous() {
+			risky++
+		}
+	}
+	if risky > 0 && !opts.Yes && c.JSON {
+		return fmt.Errorf("cutover makes %d risky DNS change(s); review them with --dry-run and pass --yes to apply", risky)
+	}
+	if !c.JSON && result.Diff != nil {
+		c.printDiff(result.Diff)
+	}
+	if risky > 0 && !opts.Yes {
+		ok, err := c.confirm(fmt.Sprintf("Apply %d risky DNS change(s) to %s?", risky, migration.Domain))
+		if err != nil {
+			return fmt.Errorf("failed to read confirmation: %w", err)
+		}
+		if !ok {
+			fmt.Println(ui.Info("Cutover cancelled, DNS left unchanged"))
+			fmt.Println()
+			return nil
+		}
+	}
+
+	if err := c.state.UpdateMigrationStatus(migration.ID, "in_progress"); err != nil {
+		return fmt.Errorf("failed to update migration status: %w", err)
+	}
+
+	// Step 2: update the records together, keeping each previous value for rollback
+	for _, planned := range result.Planned {
+		c.step(migration.ID, "info", fmt.Sprintf("updating %s %s to %s", planned.Type, planned.Name, planned.Value))
+	}
+	updates, _ := c.bridge.DnsUpdateBatch(ctx, provider, token, params)
+
+	type applied struct {
+		record   state.DnsRecord
+		estimate time.Duration
+	}
+	var changes []applied
+	var failures []string
+	for _, update := range updates {
+		if update.Err != nil {
+			failures = append(failures, fmt.Sprintf("%s %s: %s", update.Record.RecordType, update.Record.RecordName, update.Err))
+			continue
+		}
+
+		migrationID := migration.ID
+		record := state.DnsRecord{
+			ProviderRecordID: update.Data.RecordID,
+			MigrationID:      &migrationID,
+			Domain:           migration.Domain,
+			RecordType:       update.Record.RecordType,
+			RecordName:       update.Record.RecordName,
+			RecordValue:      update.Record.RecordValue,
+			TTL:              update.Record.TTL,
+			PreviousValue:    update.Data.PreviousValue,
+		}
+		// Track the change before saving so a save failure still rolls it
+		// back; saving in place keeps the local ID SaveDnsRecord assigns
+		result.Updated = append(result.Updated, record)
+		saved := &result.Updated[len(result.Updated)-1]
+		if err := c.state.SaveDnsRecord(saved); err != nil {
+			failures = append(failures, fmt.Sprintf("failed to save DNS record %s: %s", saved.ProviderRecordID, err))
+			continue
+		}
+		changes = append(changes, applied{record: *saved, estimate: time.Duration(update.Data.PropagationTime) * time.Second})
+	}
+	if len(failures) > 0 {
+		return c.abort(ctx, migration, provider, token, result.Updated, fmt.Errorf("failed to update DNS: %s", strings.Join(failures, "; ")))
+	}
+
+	// Step 3: wait for each change to propagate
+	for _, change := range changes {
+		c.step(migration.ID, "info", fmt.Sprintf("verifying %s %s propagation", change.record.RecordType, change.record.RecordName))
+		timeout := propagationTimeout(change.estimate, result.Diff, dns.FQDN(change.record.Domain, change.record.RecordName))
+		if err := c.propagation(ctx, change.record, timeout); err != nil {
+			return c.abort(ctx, migration, provider, token, result.Updated, fmt.Errorf("%s %s did not propagate: %w", change.record.RecordType, change.record.RecordName, err))
+		}
+	}
+
+	if err := c.state.UpdateMigrationStatus(migration.ID, "completed"); err != nil {
+		return fmt.Errorf("cutover succeeded but the migration could not be updated: %w", err)
+	}
+	data, _ := json.Marshal(result.Updated)
+	if err := c.state.SetStep(migration.ID, state.StepCutover, data); err != nil {
+		return fmt.Errorf("cutover succeeded but the checkpoint could not be saved: %w", err)
+	}
+	c.step(migration.ID, "info", "cutover completed")
+	result.Status = "completed"
+
+	if c.JSON {
+		return printJSON(result)
+	}
+	fmt.Println(ui.Success(fmt.Sprintf("%s now points at %s", migration.Domain, provider)))
+	fmt.Println()
+	for _, r := range result.Updated {
+		fmt.Println(ui.KeyValue(r.RecordType+" "+r.RecordName, r.RecordValue))
+	}
+	fmt.Println()
+	return nil
+}
+
+// recordedDeployment reads the deployment ID from the deploy_preview checkpoint
+func (c *CutoverCommand) recordedDeployment(ctx context.Context, migrationID string) (string, error) {
+	steps, err := c.state.GetStepsContext(ctx, migrationID)
+	if err != nil {
+		return "", fmt.Errorf("failed to load workflow steps: %w", err)
+	}
+	for _, s := range steps {
+		if s.Step != state.StepDeployPreview {
+			continue
+		}
+		var deploy bridge.DeployPreviewData
+		if err := json.Unmarshal(s.Data, &deploy); err != nil || deploy.DeploymentID == "" {
+			break
+		}
+		return deploy.DeploymentID, nil
+	}
+	return "", fmt.Errorf("no preview deployment recorded for migration %s; pass --deployment", migrationID)
+}
+
+// abort rolls back the records cutover changed, then fails with err. Records
+// the cutover created have nothing to roll back to, so the error names them
+// for removal by hand rather than reporting the DNS as restored.
+func (c *CutoverCommand) abort(ctx context.Context, migration *state.Migration, provider bridge.Provider, token string, updated []state.DnsRecord, err error) error {
+	restored, created := c.rollback(ctx, migration, provider, token, updated)
+	if restored > 0 {
+		err = fmt.Errorf("%w (rolled back %d of %d DNS record(s))", err, restored, len(updated))
+	}
+	if len(created) > 0 {
+		err = fmt.Errorf("%w; the cutover created %s, which can't be rolled back: delete them in %s by hand", err, strings.Join(created, ", "), provider)
+	}
+	return c.fail(migration, err, false)
+}
+
+// rollback restores the given records newest first and returns how many it
+// restored, plus the records that were created rather than changed and so
+// were left in place. Failures are logged but don't stop the remaining
+// rollbacks.
+func (c *CutoverCommand) rollback(ctx context.Context, migration *state.Migration, provider bridge.Provider, token string, updated []state.DnsRecord) (int, []string) {
+	// Roll back even if the cutover was cancelled
+	ctx = context.WithoutCancel(ctx)
+
+	restoredCount := 0
+	var created []string
+	for i := len(updated) - 1; i >= 0; i-- {
+		record := updated[i]
+		if record.PreviousValue == nil {
+			c.step(migration.ID, "error", fmt.Sprintf("%s %s was created by the cutover and can't be rolled back; delete it by hand", record.RecordType, record.RecordName))
+			created = append(created, fmt.Sprintf("%s %s → %s", record.RecordType, record.RecordName, record.RecordValue))
+			continue
+		}
+
+		c.step(migration.ID, "warn", fmt.Sprintf("rolling back %s %s to %s", record.RecordType, record.RecordName, *record.PreviousValue))
+		restored, err := c.bridge.DnsRollback(ctx, bridge.DnsRollbackParams{
+			Provider:   provider,
+			Token:      token,
+			RecordID:   record.ProviderRecordID,
+			RollbackTo: *record.PreviousValue,
+		})
+		if err == nil && !restored.Restored {
+			err = errors.New("provider did not restore the record")
+		}
+		if err != nil {
+			c.step(migration.ID, "error", fmt.Sprintf("failed to roll back %s %s: %s", record.RecordType, record.RecordName, err))
+			continue
+		}
+
+		rollback := state.DnsRecord{
+			ProviderRecordID: record.ProviderRecordID,
+			MigrationID:      record.MigrationID,
+			Domain:           record.Domain,
+			RecordType:       record.RecordType,
+			RecordName:       record.RecordName,
+			RecordValue:      restored.CurrentValue,
+			TTL:              record.TTL,
+			RollbackID:       &updated[i].ID,
+			PreviousValue:    &updated[i].RecordValue,
+		}
+		if err := c.state.SaveDnsRecord(&rollback); err != nil {
+			c.step(migration.ID, "error", fmt.Sprintf("rolled back %s but could not record it: %s", record.ID, err))
+		}
+		restoredCount++
+	}
+	return restoredCount, created
+}
+
+// fail marks the migration failed (unless this is a dry run) and returns err
+func (c *CutoverCommand) fail(migration *state.Migration, err error, dryRun bool) error {
+	// Logged, not echoed: the caller reports the returned error
+	migrationID := migration.ID
+	c.state.LogJSON(&migrationID, "error", fmt.Sprintf("cutover failed: %s", err), map[string]interface{}{"step": state.StepCutover})
+	if !dryRun {
+		if statusErr := c.state.UpdateMigrationStatus(migration.ID, "failed"); statusErr != nil {
+			return fmt.Errorf("%w (and failed to mark the migration failed: %v)", err, statusErr)
+		}
+	}
+	return err
+}
+
+// step logs a cutover step and echoes it in text mode
+func (c *CutoverCommand) step(migrationID, level, message string) {
+	c.state.LogJSON(&migrationID, level, message, map[string]interface{}{"step": state.StepCutover})
+	if c.JSON {
+		return
+	}
+	switch level {
+	case "error":
+		fmt.Println(ui.Error(message))
+	case "warn":
+		fmt.Println(ui.Warning(message))
+	default:
+		printProgress(message)
+	}
+}
+
+// diff compares the planned updates with the domain's current records. It
+// returns nil, after a warning, when the records can't be read, e.g. because
+// the adapter doesn't support dns:list; cutover then goes ahead unreviewed
+// as before.
+func (c *CutoverCommand) diff(ctx context.Context, migration *state.Migration, provider bridge.Provider, token string, planned []bridge.DnsUpdateParams) []bridge.DnsChange {
+	current, err := c.bridge.ListDnsRecords(ctx, provider, token, migration.Domain)
+	if err != nil {
+		c.step(migration.ID, "warn", fmt.Sprintf("couldn't read the current DNS records to compare: %s", err))
+		return nil
+	}
+	return bridge.DnsDiff(migration.Domain, current, planned)
+}
+
+// printDiff shows each record's old and new value and TTL, then why any
+// risky change is risky
+func (c *CutoverCommand) printDiff(changes []bridge.DnsChange) {
+	rows := make([][]string, len(changes))
+	for i, ch := range changes {
+		rows[i] = []string{ch.Action, ch.Type, ch.Name, diffCell(ch.OldValue, ch.NewValue, ch.Action), diffCell(ttlText(ch.OldTTL), ttlText(ch.NewTTL), ch.Action)}
+	}
+	fmt.Println(ui.Table([]string{"ACTION", "TYPE", "NAME", "VALUE", "TTL"}, rows))
+	warned := false
+	for _, ch := range changes {
+		if ch.Dangerous() {
+			fmt.Println(ui.Warning(fmt.Sprintf("%s %s %s: %s", ch.Action, ch.Type, ch.Name, ch.Warning)))
+			warned = true
+		}
+	}
+	if warned {
+		fmt.Println()
+	}
+}
+
+// diffCell renders old → new, or whichever side the action has
+func diffCell(old, new, action string) string {
+	switch {
+	case action == bridge.DnsChangeCreate:
+		return orDash(new)
+	case action == bridge.DnsChangeRemove:
+		return orDash(old)
+	case old == new || new == "":
+		return orDash(old)
+	}
+	return orDash(old) + " → " + orDash(new)
+}
+
+func ttlText(ttl int) string {
+	if ttl == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d", ttl)
+}
+
+func (c *CutoverCommand) printPlan(records []CutoverRecord, ttl int) {
+	rows := make([][]string, len(records))
+	for i, r := range records {
+		rows[i] = []string{r.Type, r.Name, r.Value, fmt.Sprintf("%d", ttl)}
+	}
+	fmt.Println(ui.Table([]string{"TYPE", "NAME", "VALUE", "TTL"}, rows))
+}
@@ -0,0 +1,226 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/johnhorton/deploy-tunnel/internal/bridge"
+	"github.com/johnhorton/deploy-tunnel/internal/state"
+)
+
+// fakeCutoverBridge answers cutover's bridge calls. Records named in failName
+// fail to update and those named in newName are created with no previous
+// value; every other update reports "old-<name>" as its previous value.
+type fakeCutoverBridge struct {
+	builtinProviders
+	failName  string
+	newName   string
+	rollbacks []bridge.DnsRollbackParams
+}
+
+func (f *fakeCutoverBridge) DeployStatus(ctx context.Context, params bridge.DeployStatusParams) (*bridge.DeployPreviewData, error) {
+	return &bridge.DeployPreviewData{DeploymentID: params.DeploymentID, URL: "https://app.example.dev", Status: bridge.DeploymentReady}, nil
+}
+
+func (f *fakeCutoverBridge) DnsUpdateBatch(ctx context.Context, provider bridge.Provider, token string, records []bridge.DnsUpdateParams) ([]bridge.DnsUpdateResult, error) {
+	results := make([]bridge.DnsUpdateResult, len(records))
+	var err error
+	for i, record := range records {
+		results[i].Record = record
+		if record.RecordName == f.failName {
+			results[i].Err = &bridge.BridgeError{Code: bridge.ErrProviderError, Message: "record locked"}
+			err = results[i].Err
+			continue
+		}
+		results[i].Data = &bridge.DnsUpdateData{RecordID: "prov-" + record.RecordName}
+		if record.RecordName != f.newName {
+			previous := "old-" + record.RecordName
+			results[i].Data.PreviousValue = &previous
+		}
+	}
+	return results, err
+}
+
+func (f *fakeCutoverBridge) DnsRollback(ctx context.Context, params bridge.DnsRollbackParams) (*bridge.DnsRollbackData, error) {
+	f.rollbacks = append(f.rollbacks, params)
+	return &bridge.DnsRollbackData{Restored: true, CurrentValue: params.RollbackTo}, nil
+}
+
+func (f *fakeCutoverBridge) ListDnsRecords(ctx context.Context, provider bridge.Provider, token, domain string) ([]bridge.DnsRecord, error) {
+	return nil, &bridge.BridgeError{Code: bridge.ErrUnsupported, Message: "dns:list not supported"}
+}
+
+func TestCutover(t *testing.T) {
+	records := []CutoverRecord{
+		{Type: "A", Name: "@", Value: "203.0.113.10"},
+		{Type: "CNAME", Name: "www", Value: "app.example.dev"},
+	}
+
+	tests := []struct {
+		name         string
+		failName     string
+		newName      string
+		propagateErr error
+		// wantErr is a substring of the expected error, "" when cutover succeeds
+		wantErr    string
+		status     string
+		rolledBack []string
+		// saved counts DNS rows: one per applied update plus one per rollback
+		saved int
+	}{
+		{name: "happy path", status: "completed", saved: 2},
+		{name: "update fails", failName: "www", wantErr: "rolled back 1 of 1", status: "failed", rolledBack: []string{"prov-@"}, saved: 2},
+		{name: "propagation fails", propagateErr: errors.New("timed out"), wantErr: "rolled back 2 of 2", status: "failed", rolledBack: []string{"prov-www", "prov-@"}, saved: 4},
+		{
+			name:         "created record left behind",
+			newName:      "www",
+			propagateErr: errors.New("timed out"),
+			wantErr:      "created CNAME www → app.example.dev, which can't be rolled back",
+			status:       "failed",
+			rolledBack:   []string{"prov-@"},
+			saved:        3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestState(t)
+			storeToken(t, "vercel")
+			if err := db.CreateMigration("m1", "netlify", "vercel", "example.com"); err != nil {
+				t.Fatal(err)
+			}
+
+			br := &fakeCutoverBridge{failName: tt.failName, newName: tt.newName}
+			cmd := NewCutoverCommand(db, br)
+			cmd.JSON = true
+			cmd.propagation = func(ctx context.Context, record state.DnsRecord, timeout time.Duration) error {
+				return tt.propagateErr
+			}
+
+			var err error
+			captureStdout(t, func() {
+				err = cmd.Run(context.Background(), CutoverOptions{MigrationID: "m1", DeploymentID: "dpl_1", Records: records, TTL: 300})
+			})
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("Run error: %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("Run error = %v, want %q", err, tt.wantErr)
+			}
+
+			migration, err := db.GetMigration("m1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if migration.Status != tt.status {
+				t.Errorf("migration status = %q, want %q", migration.Status, tt.status)
+			}
+
+			if len(br.rollbacks) != len(tt.rolledBack) {
+				t.Fatalf("rolled back %d record(s), want %d", len(br.rollbacks), len(tt.rolledBack))
+			}
+			for i, params := range br.rollbacks {
+				if params.RecordID != tt.rolledBack[i] {
+					t.Errorf("rollback %d used record %q, want %q", i, params.RecordID, tt.rolledBack[i])
+				}
+				if params.RollbackTo != "old-"+params.RecordID[len("prov-"):] {
+					t.Errorf("rollback %d restored %q", i, params.RollbackTo)
+				}
+			}
+
+			saved, err := db.GetDnsRecords("m1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(saved) != tt.saved {
+				t.Errorf("saved %d DNS record(s), want %d", len(saved), tt.saved)
+			}
+		})
+	}
+}
+
+func TestCutoverRequiresRecord(t *testing.T) {
+	db := newTestState(t)
+	storeToken(t, "vercel")
+	if err := db.CreateMigration("m1", "netlify", "vercel", "example.com"); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := NewCutoverCommand(db, &fakeCutoverBridge{})
+	cmd.JSON = true
+	err := cmd.Run(context.Background(), CutoverOptions{MigrationID: "m1", DeploymentID: "dpl_1", TTL: 300, DryRun: true})
+	if err == nil || !strings.Contains(err.Error(), "pass --record: example.com is a zone apex") {
+		t.Fatalf("Run without --record error = %v, want --record required", err)
+	}
+
+	migration, err := db.GetMigration("m1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if migration.Status != "pending" {
+		t.Errorf("migration status = %q, want it left pending", migration.Status)
+	}
+}
+
+func TestCutoverRetryAfterRollback(t *testing.T) {
+	db := newTestState(t)
+	storeToken(t, "vercel")
+	if err := db.CreateMigration("m1", "netlify", "vercel", "example.com"); err != nil {
+		t.Fatal(err)
+	}
+	opts := CutoverOptions{MigrationID: "m1", DeploymentID: "dpl_1", Records: []CutoverRecord{{Type: "A", Name: "@", Value: "203.0.113.10"}}, TTL: 300}
+
+	cmd := NewCutoverCommand(db, &fakeCutoverBridge{})
+	cmd.JSON = true
+	attempts := 0
+	cmd.propagation = func(ctx context.Context, record state.DnsRecord, timeout time.Duration) error {
+		attempts++
+		if attempts == 1 {
+			return errors.New("timed out")
+		}
+		return nil
+	}
+
+	// The provider reuses the record ID on the second attempt
+	captureStdout(t, func() {
+		if err := cmd.Run(context.Background(), opts); err == nil {
+			t.Error("first attempt succeeded")
+		}
+		if err := cmd.Run(context.Background(), opts); err != nil {
+			t.Errorf("retry failed: %v", err)
+		}
+	})
+}
+
+func TestPropagationTimeout(t *testing.T) {
+	diff := []bridge.DnsChange{
+		{Action: bridge.DnsChangeUpdate, Type: "A", Name: "example.com", OldTTL: 300, NewTTL: 60},
+		{Action: bridge.DnsChangeRemove, Type: "AAAA", Name: "example.com", OldTTL: 480},
+		{Action: bridge.DnsChangeCreate, Type: "CNAME", Name: "www.example.com", NewTTL: 3600},
+		{Action: bridge.DnsChangeUpdate, Type: "A", Name: "old.example.com", OldTTL: 86400, NewTTL: 60},
+	}
+
+	tests := []struct {
+		name     string
+		diff     []bridge.DnsChange
+		record   string
+		estimate time.Duration
+		want     time.Duration
+	}{
+		{name: "longest old TTL at the name", diff: diff, record: "example.com", estimate: 30 * time.Second, want: 510 * time.Second},
+		{name: "new record ignores its new TTL", diff: diff, record: "www.example.com", estimate: 30 * time.Second, want: minPropagationWait},
+		{name: "capped", diff: diff, record: "old.example.com", want: maxPropagationWait},
+		{name: "zone unknown", diff: nil, record: "example.com", want: maxPropagationWait},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := propagationTimeout(tt.estimate, tt.diff, tt.record); got != tt.want {
+				t.Errorf("propagationTimeout = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,421 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/johnhorton/deploy-tunnel/internal/bridge"
+	"github.com/johnhorton/deploy-tunnel/internal/state"
+	"github.com/johnhorton/deploy-tunnel/ui"
+)
+
+func init() {
+	register(command{name: "deploy", run: (*runner).deploy, help: []helpLine{
+		{"deploy preview", "Create a preview deployment"},
+		{"deploy list", "List recorded deployments"},
+		{"deploy open <id>", "Open a deployment in the browser"},
+	}})
+}
+
+func (r *runner) deploy(ctx context.Context, args []string) error {
+	sub, args, err := subcommand("deploy", args, "preview", "list", "open")
+	if err != nil {
+		return err
+	}
+	switch sub {
+	case "list":
+		opts, err := ParseDeployListFlags(args)
+		if err != nil {
+			return err
+		}
+		db, _, err := r.open("")
+		if err != nil {
+			return err
+		}
+		cmd := NewDeployCommand(db, nil)
+		cmd.JSON = r.global.JSON
+		return cmd.List(ctx, opts)
+	case "open":
+		if len(args) > 1 {
+			return fmt.Errorf("usage: dt deploy open <deployment-id>")
+		}
+		db, err := r.openState()
+		if err != nil {
+			return err
+		}
+		var id string
+		if len(args) == 1 {
+			id = args[0]
+		}
+		return NewDeployCommand(db, nil).Open(ctx, id)
+	}
+
+	opts, err := ParseDeployPreviewFlags(args)
+	if err != nil {
+		return err
+	}
+	db, br, err := r.open("dt deploy preview")
+	if err != nil {
+		return err
+	}
+	cmd := NewDeployCommand(db, br)
+	cmd.JSON = r.global.JSON
+	return cmd.Preview(ctx, opts)
+}
+
+// deployPollInterval is how often --wait checks the deployment status
+const deployPollInterval = 5 * time.Second
+
+// deployer is the bridge calls DeployCommand needs
+type deployer interface {
+	DeployPreview(ctx context.Context, params bridge.DeployPreviewParams) (*bridge.DeployPreviewData, error)
+	DeployStatus(ctx context.Context, params bridge.DeployStatusParams) (*bridge.DeployPreviewData, error)
+	Supports(ctx context.Context, provider bridge.Provider, verb string) (bool, error)
+	ParseProvider(name string) (bridge.Provider, error)
+}
+
+type DeployCommand struct {
+	state        *state.DB
+	bridge       deployer
+	pollInterval time.Duration
+
+	// JSON prints the deployment as JSON
+	JSON bool
+}
+
+func NewDeployCommand(stateDB *state.DB, br deployer) *DeployCommand {
+	return &DeployCommand{
+		state:        stateDB,
+		bridge:       br,
+		pollInterval: deployPollInterval,
+	}
+}
+
+// DeployPreviewOptions holds the flags for `dt deploy preview`
+type DeployPreviewOptions struct {
+	Provider string
+	Project  string
+	Branch   string
+	Wait     bool
+	// MigrationID records the deployment under this migration instead of the current one
+	MigrationID string
+}
+
+// ParseDeployPreviewFlags parses `dt deploy preview --provider p --project id [--branch b] [--wait] [--migration id]`
+func ParseDeployPreviewFlags(args []string) (DeployPreviewOptions, error) {
+	var opts DeployPreviewOptions
+
+	fs := flag.NewFlagSet("deploy preview", flag.ContinueOnError)
+	fs.StringVar(&opts.Provider, "provider", defaults.DefaultTarget, "provider to deploy on")
+	fs.StringVar(&opts.Project, "project", "", "project ID")
+	fs.StringVar(&opts.Branch, "branch", "", "git branch to deploy")
+	fs.BoolVar(&opts.Wait, "wait", false, "wait until the deployment is ready or fails")
+	fs.StringVar(&opts.MigrationID, "migration", "", "migration to record the deployment under (defaults to the current one)")
+
+	if err := fs.Parse(args); err != nil {
+		return opts, err
+	}
+	if fs.NArg() > 0 {
+		return opts, fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+
+	var missing []string
+	if opts.Provider == "" {
+		missing = append(missing, "--provider")
+	}
+	if opts.Project == "" {
+		missing = append(missing, "--project")
+	}
+	if len(missing) > 0 {
+		return opts, fmt.Errorf("missing required flags: %s", strings.Join(missing, ", "))
+	}
+	return opts, nil
+}
+
+// Preview creates a preview deployment, optionally waiting for it to finish.
+// The deployment is recorded under the migration so `dt deploy list` can
+// show it later; with no migration at all it is not recorded.
+func (c *DeployCommand) Preview(ctx context.Context, opts DeployPreviewOptions) error {
+	provider, err := c.bridge.ParseProvider(opts.Provider)
+	if err != nil {
+		return err
+	}
+	token, err := loadToken(provider)
+	if err != nil {
+		return err
+	}
+	migration, err := c.previewMigration(ctx, opts.MigrationID)
+	if err != nil {
+		return err
+	}
+	// Checked first so an adapter that can't report status doesn't leave a
+	// deployment behind that --wait can never finish
+	if opts.Wait {
+		if ok, err := c.bridge.Supports(ctx, provider, "deploy:status"); err == nil && !ok {
+			return fmt.Errorf("%s adapter does not support deploy:status, so --wait can't follow the deployment; run without --wait", provider)
+		}
+	}
+
+	if !c.JSON {
+		printHeader()
+		printProgress(fmt.Sprintf("Creating preview deployment on %s...", provider))
+	}
+
+	deploy, err := c.bridge.DeployPreview(ctx, bridge.DeployPreviewParams{
+		Provider:  provider,
+		Token:     token,
+		ProjectID: opts.Project,
+		Branch:    opts.Branch,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create preview: %w", err)
+	}
+	if err := c.record(ctx, migration, provider, deploy); err != nil {
+		return err
+	}
+
+	if opts.Wait {
+		if deploy, err = c.wait(ctx, provider, token, opts.Project, deploy); err != nil {
+			return err
+		}
+		if err := c.record(ctx, migration, provider, deploy); err != nil {
+			return err
+		}
+	}
+	c.recordBuildTime(ctx, provider, opts.Project, deploy)
+
+	failed := deploy.Status == bridge.DeploymentError
+	if c.JSON {
+		if err := printJSON(deploy); err != nil {
+			return err
+		}
+		if failed {
+			return reported(fmt.Errorf("deployment %s failed", deploy.DeploymentID))
+		}
+		return nil
+	}
+
+	fmt.Println()
+	fmt.Println(ui.KeyValue("Deployment", deploy.DeploymentID))
+	fmt.Println(ui.KeyValue("URL", deploy.URL))
+	fmt.Println(ui.KeyValue("Status", deploy.Status))
+	if deploy.BuildTime != nil {
+		fmt.Println(ui.KeyValue("Build Time", (time.Duration(*deploy.BuildTime) * time.Second).String()))
+	}
+	fmt.Println()
+
+	if failed {
+		return fmt.Errorf("deployment %s failed", deploy.DeploymentID)
+	}
+	return nil
+}
+
+// wait polls until the deployment is ready or has failed. Progress is shown
+// against the project's average build time, or as a spinner with no history.
+func (c *DeployCommand) wait(ctx context.Context, provider bridge.Provider, token, projectID string, deploy *bridge.DeployPreviewData) (*bridge.DeployPreviewData, error) {
+	ticker := time.NewTicker(c.pollInterval)
+	defer ticker.Stop()
+
+	estimate := c.buildEstimate(ctx, provider, projectID)
+	started := time.Now()
+	showProgress := !c.JSON && !ui.Quiet()
+	printed := false
+	defer func() {
+		if printed {
+			fmt.Println()
+		}
+	}()
+
+	for frame := 0; deploy.Status != bridge.DeploymentReady && deploy.Status != bridge.DeploymentError; frame++ {
+		if showProgress {
+			fmt.Printf("\r%s", deployProgressLine(deploy.Status, time.Since(started), estimate, frame))
+			printed = true
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("stopped waiting for deployment %s: %w", deploy.DeploymentID, ctx.Err())
+		case <-ticker.C:
+		}
+
+		next, err := c.bridge.DeployStatus(ctx, bridge.DeployStatusParams{
+			Provider:     provider,
+			Token:        token,
+			DeploymentID: deploy.DeploymentID,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get deployment status: %w", err)
+		}
+		deploy = next
+	}
+
+	return deploy, nil
+}
+
+// buildEstimate returns the project's average build time, or 0 when there is
+// no history to go on
+func (c *DeployCommand) buildEstimate(ctx context.Context, provider bridge.Provider, projectID string) time.Duration {
+	if c.state == nil {
+		return 0
+	}
+	avg, _, err := c.state.AverageBuildTimeContext(ctx, string(provider), projectID)
+	if err != nil {
+		return 0
+	}
+	return avg
+}
+
+// deployProgressLine renders one --wait status line: a bar filled by elapsed
+// against estimate, held at 100% until the deployment is actually ready, or a
+// spinner when estimate is 0. It is padded so a shorter line fully
+// overwrites a longer one.
+func deployProgressLine(status string, elapsed, estimate time.Duration, frame int) string {
+	elapsed = elapsed.Round(time.Second)
+	var line string
+	switch {
+	case estimate <= 0:
+		spinner := ui.SpinnerStyle.Render(ui.SpinnerFrames[frame%len(ui.SpinnerFrames)])
+		line = fmt.Sprintf("%s Deployment is %s... %s", spinner, status, elapsed)
+	case elapsed < estimate:
+		left := (estimate - elapsed).Round(time.Second)
+		line = fmt.Sprintf("%s Deployment is %s, about %s left", ui.ProgressBar(int(elapsed.Milliseconds()), int(estimate.Milliseconds()), 30), status, left)
+	default:
+		line = fmt.Sprintf("%s Deployment is %s, taking longer than usual (%s)", ui.ProgressBar(1, 1, 30), status, elapsed)
+	}
+	return line + strings.Repeat(" ", 8)
+}
+
+// recordBuildTime adds a finished deployment's build time to the project's
+// history for later estimates. It is best effort: a failure only warns.
+func (c *DeployCommand) recordBuildTime(ctx context.Context, provider bridge.Provider, projectID string, deploy *bridge.DeployPreviewData) {
+	if c.state == nil || deploy.Status != bridge.DeploymentReady || deploy.BuildTime == nil {
+		return
+	}
+	buildTime := time.Duration(*deploy.BuildTime) * time.Second
+	if err := c.state.RecordBuildTimeContext(ctx, string(provider), projectID, buildTime); err != nil && !c.JSON {
+		fmt.Println(ui.Warning(fmt.Sprintf("Failed to save build time: %s", err)))
+	}
+}
+
+// previewMigration returns the migration a new deployment belongs to: the
+// given one, else the current one, else nil
+func (c *DeployCommand) previewMigration(ctx context.Context, id string) (*state.Migration, error) {
+	if c.state == nil {
+		return nil, nil
+	}
+	if id != "" {
+		return loadMigration(ctx, c.state, id)
+	}
+	migration, err := c.state.CurrentMigration()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load current migration: %w", err)
+	}
+	return migration, nil
+}
+
+// record saves the deployment under the migration, if there is one
+func (c *DeployCommand) record(ctx context.Context, migration *state.Migration, provider bridge.Provider, deploy *bridge.DeployPreviewData) error {
+	if migration == nil {
+		return nil
+	}
+	return c.state.SaveDeploymentContext(ctx, &state.Deployment{
+		ID:          deploy.DeploymentID,
+		MigrationID: migration.ID,
+		Provider:    string(provider),
+		URL:         deploy.URL,
+		Status:      deploy.Status,
+		BuildTime:   deploy.BuildTime,
+	})
+}
+
+// DeployListOptions holds the arguments for `dt deploy list`
+type DeployListOptions struct {
+	MigrationID string
+}
+
+// ParseDeployListFlags parses `dt deploy list [migration-id]`
+func ParseDeployListFlags(args []string) (DeployListOptions, error) {
+	var opts DeployListOptions
+
+	fs := flag.NewFlagSet("deploy list", flag.ContinueOnError)
+	if err := fs.Parse(args); err != nil {
+		return opts, err
+	}
+	switch {
+	case fs.NArg() == 1:
+		opts.MigrationID = fs.Arg(0)
+	case fs.NArg() > 1:
+		return opts, fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args()[1:], " "))
+	}
+	return opts, nil
+}
+
+// List prints the deployments recorded for a migration, the current one by default
+func (c *DeployCommand) List(ctx context.Context, opts DeployListOptions) error {
+	migration, err := loadMigration(ctx, c.state, opts.MigrationID)
+	if err != nil {
+		return err
+	}
+	deployments, err := c.state.ListDeploymentsContext(ctx, migration.ID)
+	if err != nil {
+		return fmt.Errorf("failed to load deployments: %w", err)
+	}
+
+	if c.JSON {
+		if deployments == nil {
+			deployments = []state.Deployment{}
+		}
+		return printJSON(deployments)
+	}
+
+	printHeader()
+	if len(deployments) == 0 {
+		fmt.Println(ui.Info(fmt.Sprintf("No deployments recorded for migration %s", migration.ID)))
+		return nil
+	}
+
+	rows := make([][]string, 0, len(deployments))
+	for _, d := range deployments {
+		buildTime := "-"
+		if d.BuildTime != nil {
+			buildTime = (time.Duration(*d.BuildTime) * time.Second).String()
+		}
+		rows = append(rows, []string{
+			d.ID,
+			d.Provider,
+			d.Status,
+			buildTime,
+			d.CreatedAt.Local().Format("2006-01-02 15:04"),
+			d.URL,
+		})
+	}
+	fmt.Println(ui.Table([]string{"DEPLOYMENT", "PROVIDER", "STATUS", "BUILD", "CREATED", "URL"}, rows))
+	return nil
+}
+
+// Open opens a recorded deployment's URL in the browser
+func (c *DeployCommand) Open(ctx context.Context, deploymentID string) error {
+	if deploymentID == "" {
+		return fmt.Errorf("usage: dt deploy open <deployment-id>")
+	}
+	deployment, err := c.state.GetDeploymentContext(ctx, deploymentID)
+	if err != nil {
+		return fmt.Errorf("failed to load deployment: %w", err)
+	}
+	if deployment == nil {
+		return fmt.Errorf("deployment not found: %s (see: dt deploy list)", deploymentID)
+	}
+	if deployment.URL == "" {
+		return fmt.Errorf("deployment %s has no URL", deploymentID)
+	}
+
+	fmt.Println(ui.KeyValue("URL", deployment.URL))
+	if err := openBrowser(deployment.URL); err != nil {
+		fmt.Println(ui.Warning("Failed to open browser automatically"))
+		fmt.Println(ui.Info("Please visit the URL above manually"))
+	}
+	return nil
+}
@@ -0,0 +1,258 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/johnhorton/deploy-tunnel/internal/bridge"
+	"github.com/johnhorton/deploy-tunnel/internal/state"
+)
+
+// fakeDeployer creates dpl_1 and reports statuses in turn on each poll
+type fakeDeployer struct {
+	builtinProviders
+	statuses    []string
+	noStatus    bool
+	created     int
+	polls       int
+	cancelAfter context.CancelFunc
+}
+
+func (f *fakeDeployer) DeployPreview(ctx context.Context, params bridge.DeployPreviewParams) (*bridge.DeployPreviewData, error) {
+	f.created++
+	return &bridge.DeployPreviewData{DeploymentID: "dpl_1", URL: "https://dpl-1.example.app", Status: bridge.DeploymentQueued}, nil
+}
+
+func (f *fakeDeployer) DeployStatus(ctx context.Context, params bridge.DeployStatusParams) (*bridge.DeployPreviewData, error) {
+	if params.DeploymentID != "dpl_1" {
+		return nil, &bridge.BridgeError{Code: bridge.ErrNotFound, Message: "no such deployment"}
+	}
+	status := f.statuses[min(f.polls, len(f.statuses)-1)]
+	f.polls++
+	if f.cancelAfter != nil {
+		f.cancelAfter()
+	}
+	deploy := &bridge.DeployPreviewData{DeploymentID: "dpl_1", URL: "https://dpl-1.example.app", Status: status}
+	if status == bridge.DeploymentReady {
+		buildTime := 42
+		deploy.BuildTime = &buildTime
+	}
+	return deploy, nil
+}
+
+func (f *fakeDeployer) Supports(ctx context.Context, provider bridge.Provider, verb string) (bool, error) {
+	return !f.noStatus, nil
+}
+
+// newDeployCommand returns a deploy command that polls without waiting, on a
+// state DB with a current migration
+func newDeployCommand(t *testing.T, br *fakeDeployer) (*DeployCommand, *state.DB) {
+	t.Helper()
+	storeToken(t, "netlify")
+	db := newTestState(t)
+	if err := db.CreateMigration("m1", "vercel", "netlify", "example.com"); err != nil {
+		t.Fatal(err)
+	}
+	cmd := NewDeployCommand(db, br)
+	cmd.pollInterval = time.Millisecond
+	cmd.JSON = true
+	return cmd, db
+}
+
+func TestDeployPreviewWait(t *testing.T) {
+	br := &fakeDeployer{statuses: []string{bridge.DeploymentBuilding, bridge.DeploymentBuilding, bridge.DeploymentReady}}
+	cmd, db := newDeployCommand(t, br)
+
+	var err error
+	out := captureStdout(t, func() {
+		err = cmd.Preview(context.Background(), DeployPreviewOptions{Provider: "netlify", Project: "prj_1", Wait: true})
+	})
+	if err != nil {
+		t.Fatalf("Preview() error: %v", err)
+	}
+	if br.polls != 3 {
+		t.Errorf("polled %d times, want 3 (until ready)", br.polls)
+	}
+	if !strings.Contains(out, `"status": "ready"`) {
+		t.Errorf("output doesn't show the final status:\n%s", out)
+	}
+
+	deployments, err := db.ListDeployments("m1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deployments) != 1 || deployments[0].Status != bridge.DeploymentReady {
+		t.Errorf("recorded %+v, want dpl_1 updated to ready", deployments)
+	}
+	if avg, n, _ := db.AverageBuildTime("netlify", "prj_1"); n != 1 || avg != 42*time.Second {
+		t.Errorf("build time history = %s over %d builds, want 42s over 1", avg, n)
+	}
+}
+
+func TestDeployPreviewWaitFails(t *testing.T) {
+	br := &fakeDeployer{statuses: []string{bridge.DeploymentBuilding, bridge.DeploymentError}}
+	cmd, db := newDeployCommand(t, br)
+
+	var err error
+	out := captureStdout(t, func() {
+		err = cmd.Preview(context.Background(), DeployPreviewOptions{Provider: "netlify", Project: "prj_1", Wait: true})
+	})
+	var report *reportedError
+	if !errors.As(err, &report) || !strings.Contains(out, `"status": "error"`) {
+		t.Errorf("Preview() --json = %v, printing:\n%s\nwant the failed deployment in the JSON and a nonzero exit", err, out)
+	}
+
+	cmd.JSON = false
+	br.polls = 0
+	captureStdout(t, func() {
+		err = cmd.Preview(context.Background(), DeployPreviewOptions{Provider: "netlify", Project: "prj_1", Wait: true})
+	})
+	if err == nil || !strings.Contains(err.Error(), "deployment dpl_1 failed") {
+		t.Errorf("Preview() error = %v, want the failed deployment reported", err)
+	}
+	if deployments, _ := db.ListDeployments("m1"); len(deployments) != 1 || deployments[0].Status != bridge.DeploymentError {
+		t.Errorf("recorded %+v, want dpl_1 as error", deployments)
+	}
+	if _, n, _ := db.AverageBuildTime("netlify", "prj_1"); n != 0 {
+		t.Errorf("a failed build was added to the build time history")
+	}
+}
+
+func TestDeployPreviewWaitNeedsStatus(t *testing.T) {
+	br := &fakeDeployer{noStatus: true}
+	cmd, _ := newDeployCommand(t, br)
+
+	err := cmd.Preview(context.Background(), DeployPreviewOptions{Provider: "netlify", Project: "prj_1", Wait: true})
+	if err == nil || !strings.Contains(err.Error(), "does not support deploy:status") {
+		t.Errorf("Preview() error = %v, want deploy:status unsupported", err)
+	}
+	if br.created != 0 {
+		t.Error("a deployment was created that --wait couldn't follow")
+	}
+}
+
+func TestDeployPreviewWaitCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	br := &fakeDeployer{statuses: []string{bridge.DeploymentBuilding}, cancelAfter: cancel}
+	cmd, _ := newDeployCommand(t, br)
+
+	var err error
+	captureStdout(t, func() {
+		err = cmd.Preview(ctx, DeployPreviewOptions{Provider: "netlify", Project: "prj_1", Wait: true})
+	})
+	if !errors.Is(err, context.Canceled) || !strings.Contains(err.Error(), "stopped waiting for deployment dpl_1") {
+		t.Errorf("Preview() error = %v, want it to stop waiting on cancel", err)
+	}
+	if br.polls != 1 {
+		t.Errorf("polled %d times after cancel, want 1", br.polls)
+	}
+}
+
+func TestDeployProgressLine(t *testing.T) {
+	tests := []struct {
+		name     string
+		elapsed  time.Duration
+		estimate time.Duration
+		want     string
+	}{
+		{name: "no history", elapsed: 12 * time.Second, want: "Deployment is building... 12s"},
+		{name: "estimate", elapsed: 20 * time.Second, estimate: time.Minute, want: "about 40s left"},
+		{name: "overdue", elapsed: 90 * time.Second, estimate: time.Minute, want: "taking longer than usual (1m30s)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := deployProgressLine(bridge.DeploymentBuilding, tt.elapsed, tt.estimate, 0); !strings.Contains(got, tt.want) {
+				t.Errorf("deployProgressLine() = %q, want it to contain %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeployPreviewWaitEstimate(t *testing.T) {
+	tests := []struct {
+		name    string
+		history []time.Duration
+		want    string
+	}{
+		{name: "no history", want: "Deployment is building..."},
+		{name: "history", history: []time.Duration{50 * time.Minute, 70 * time.Minute}, want: "about 1h0m0s left"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			br := &fakeDeployer{statuses: []string{bridge.DeploymentBuilding, bridge.DeploymentReady}}
+			cmd, db := newDeployCommand(t, br)
+			cmd.JSON = false
+			for _, buildTime := range tt.history {
+				if err := db.RecordBuildTime("netlify", "prj_1", buildTime); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var err error
+			out := captureStdout(t, func() {
+				err = cmd.Preview(context.Background(), DeployPreviewOptions{Provider: "netlify", Project: "prj_1", Wait: true})
+			})
+			if err != nil {
+				t.Fatalf("Preview() error: %v", err)
+			}
+			if !strings.Contains(out, tt.want) {
+				t.Errorf("output is missing %q:\n%s", tt.want, out)
+			}
+		})
+	}
+}
+
+func TestDeployListAndOpen(t *testing.T) {
+	db := newTestState(t)
+	if err := db.CreateMigration("m1", "vercel", "netlify", "example.com"); err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range []*state.Deployment{
+		{ID: "dpl_1", MigrationID: "m1", Provider: "netlify", URL: "https://dpl-1.example.app", Status: bridge.DeploymentReady},
+		{ID: "dpl_2", MigrationID: "m1", Provider: "netlify", Status: bridge.DeploymentQueued},
+	} {
+		if err := db.SaveDeployment(d); err != nil {
+			t.Fatal(err)
+		}
+	}
+	r := &runner{global: GlobalOptions{JSON: true}, stateDB: db, br: fakeAdapter(t, "netlify", "exit 1")}
+
+	var err error
+	out := captureStdout(t, func() { err = r.command(context.Background(), []string{"deploy", "list"}) })
+	if err != nil {
+		t.Fatalf("dt deploy list error: %v", err)
+	}
+	for _, id := range []string{`"id": "dpl_1"`, `"id": "dpl_2"`} {
+		if !strings.Contains(out, id) {
+			t.Errorf("dt deploy list output doesn't include %s:\n%s", id, out)
+		}
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		// wantErr is a substring of the expected error
+		wantErr string
+	}{
+		{name: "no id", args: []string{"deploy", "open"}, wantErr: "usage: dt deploy open"},
+		{name: "two ids", args: []string{"deploy", "open", "dpl_1", "dpl_2"}, wantErr: "usage: dt deploy open"},
+		{name: "unknown id", args: []string{"deploy", "open", "dpl_9"}, wantErr: "deployment not found: dpl_9"},
+		{name: "no URL yet", args: []string{"deploy", "open", "dpl_2"}, wantErr: "deployment dpl_2 has no URL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			captureStdout(t, func() { err = r.command(context.Background(), tt.args) })
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("dt %s error = %v, want %q", strings.Join(tt.args, " "), err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,331 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/johnhorton/deploy-tunnel/internal/bridge"
+	"github.com/johnhorton/deploy-tunnel/internal/state"
+	"github.com/johnhorton/deploy-tunnel/internal/validate"
+	"github.com/johnhorton/deploy-tunnel/ui"
+)
+
+func init() {
+	register(command{name: "dns", run: (*runner).dns, help: []helpLine{
+		{"dns update", "Change one DNS record"},
+		{"dns rollback", "Restore DNS records"},
+	}})
+}
+
+func (r *runner) dns(ctx context.Context, args []string) error {
+	sub, args, err := subcommand("dns", args, "update", "rollback")
+	if err != nil {
+		return err
+	}
+
+	if sub == "update" {
+		opts, err := ParseDNSUpdateFlags(args)
+		if err != nil {
+			return err
+		}
+		db, br, err := r.open("dt dns update")
+		if err != nil {
+			return err
+		}
+		cmd := NewDNSCommand(db, br)
+		cmd.JSON = r.global.JSON
+		return cmd.Update(ctx, opts)
+	}
+
+	opts, err := ParseDNSRollbackFlags(args)
+	if err != nil {
+		return err
+	}
+	db, br, err := r.open("dt dns rollback")
+	if err != nil {
+		return err
+	}
+	cmd := NewDNSCommand(db, br)
+	cmd.JSON = r.global.JSON
+	return cmd.Rollback(ctx, opts)
+}
+
+// dnsUpdater is the bridge calls DNSCommand needs
+type dnsUpdater interface {
+	DnsUpdate(ctx context.Context, params bridge.DnsUpdateParams) (*bridge.DnsUpdateData, error)
+	DnsRollback(ctx context.Context, params bridge.DnsRollbackParams) (*bridge.DnsRollbackData, error)
+	ParseProvider(name string) (bridge.Provider, error)
+}
+
+type DNSCommand struct {
+	state  *state.DB
+	bridge dnsUpdater
+
+	// JSON prints results as JSON
+	JSON bool
+}
+
+func NewDNSCommand(stateDB *state.DB, br dnsUpdater) *DNSCommand {
+	return &DNSCommand{
+		state:  stateDB,
+		bridge: br,
+	}
+}
+
+// DNSUpdateOptions holds the flags for `dt dns update`
+type DNSUpdateOptions struct {
+	Provider string
+	Domain   string
+	Type     string
+	Name     string
+	Value    string
+	TTL      int
+}
+
+// ParseDNSUpdateFlags parses `dt dns update --provider p --domain d --type t --name n --value v [--ttl s]`
+func ParseDNSUpdateFlags(args []string) (DNSUpdateOptions, error) {
+	var opts DNSUpdateOptions
+
+	fs := flag.NewFlagSet("dns update", flag.ContinueOnError)
+	fs.StringVar(&opts.Provider, "provider", defaults.DefaultTarget, "provider hosting the zone")
+	fs.StringVar(&opts.Domain, "domain", "", "zone to update")
+	fs.StringVar(&opts.Type, "type", "", "record type ("+strings.Join(bridge.DnsRecordTypes, ", ")+")")
+	fs.StringVar(&opts.Name, "name", "", "record name (@ for the apex)")
+	fs.StringVar(&opts.Value, "value", "", "record value")
+	fs.IntVar(&opts.TTL, "ttl", 300, "TTL in seconds")
+
+	if err := fs.Parse(args); err != nil {
+		return opts, err
+	}
+	if fs.NArg() > 0 {
+		return opts, fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+
+	var missing []string
+	for _, f := range []struct{ name, value string }{
+		{"--provider", opts.Provider},
+		{"--domain", opts.Domain},
+		{"--type", opts.Type},
+		{"--name", opts.Name},
+		{"--value", opts.Value},
+	} {
+		if f.value == "" {
+			missing = append(missing, f.name)
+		}
+	}
+	if len(missing) > 0 {
+		return opts, fmt.Errorf("missing required flags: %s", strings.Join(missing, ", "))
+	}
+
+	recordType, err := bridge.ParseRecordType(opts.Type)
+	if err != nil {
+		return opts, fmt.Errorf("invalid --type: %w", err)
+	}
+	opts.Type = recordType
+	if opts.TTL <= 0 {
+		return opts, fmt.Errorf("--ttl must be positive")
+	}
+	return opts, nil
+}
+
+// DNSRollbackOptions holds the flags for `dt dns rollback`
+type DNSRollbackOptions struct {
+	RecordID string
+	Provider string
+}
+
+// ParseDNSRollbackFlags parses `dt dns rollback --record id [--provider p]`
+func ParseDNSRollbackFlags(args []string) (DNSRollbackOptions, error) {
+	var opts DNSRollbackOptions
+
+	fs := flag.NewFlagSet("dns rollback", flag.ContinueOnError)
+	fs.StringVar(&opts.RecordID, "record", "", "ID of the record to roll back")
+	fs.StringVar(&opts.Provider, "provider", "", "provider hosting the zone (defaults to the migration's target)")
+
+	if err := fs.Parse(args); err != nil {
+		return opts, err
+	}
+	if fs.NArg() > 0 {
+		return opts, fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+	if opts.RecordID == "" {
+		return opts, fmt.Errorf("missing required flag: --record")
+	}
+	return opts, nil
+}
+
+// dnsUpdateResult is the JSON form of `dt dns update`
+type dnsUpdateResult struct {
+	Record          state.DnsRecord `json:"record"`
+	PropagationTime int             `json:"propagation_time"`
+}
+
+// Update changes a DNS record and stores it with its previous value for rollback
+func (c *DNSCommand) Update(ctx context.Context, opts DNSUpdateOptions) error {
+	provider, err := c.bridge.ParseProvider(opts.Provider)
+	if err != nil {
+		return err
+	}
+	if err := validate.Domain(opts.Domain); err != nil {
+		return fmt.Errorf("invalid --domain: %w", err)
+	}
+	domain := validate.NormalizeDomain(opts.Domain)
+
+	token, err := loadToken(provider)
+	if err != nil {
+		return err
+	}
+
+	if !c.JSON {
+		printHeader()
+		printProgress(fmt.Sprintf("Updating %s %s on %s...", opts.Type, opts.Name, provider))
+	}
+
+	params := bridge.DnsUpdateParams{
+		Provider:    provider,
+		Token:       token,
+		Domain:      domain,
+		RecordType:  opts.Type,
+		RecordName:  opts.Name,
+		RecordValue: opts.Value,
+		TTL:         opts.TTL,
+	}
+	result, err := c.bridge.DnsUpdate(ctx, params)
+	if err != nil {
+		return fmt.Errorf("failed to update DNS: %w", err)
+	}
+
+	record := state.DnsRecord{
+		ProviderRecordID: result.RecordID,
+		Domain:           domain,
+		RecordType:       params.RecordType,
+		RecordName:       params.RecordName,
+		RecordValue:      params.RecordValue,
+		TTL:              params.TTL,
+		PreviousValue:    result.PreviousValue,
+	}
+	// Tie the record to the current migration when it's for the same domain
+	if current, err := c.state.CurrentMigration(); err == nil && current != nil && current.Domain == domain {
+		record.MigrationID = &current.ID
+	}
+	if err := c.state.SaveDnsRecord(&record); err != nil {
+		return fmt.Errorf("DNS was updated but the record could not be saved: %w", err)
+	}
+	if record.MigrationID != nil {
+		c.state.LogJSON(record.MigrationID, "info", fmt.Sprintf("updated %s %s to %s", record.RecordType, record.RecordName, record.RecordValue), map[string]interface{}{"record_id": record.ID})
+	}
+
+	if c.JSON {
+		return printJSON(dnsUpdateResult{Record: record, PropagationTime: result.PropagationTime})
+	}
+
+	fmt.Println(ui.Success("DNS record updated"))
+	fmt.Println()
+	fmt.Println(ui.KeyValue("Record ID", record.ID))
+	fmt.Println(ui.KeyValue("Value", record.RecordValue))
+	if record.PreviousValue != nil {
+		fmt.Println(ui.KeyValue("Previous", *record.PreviousValue))
+	}
+	fmt.Println(ui.KeyValue("Propagation", fmt.Sprintf("~%ds", result.PropagationTime)))
+	fmt.Println()
+	fmt.Println(ui.Info(fmt.Sprintf("Undo with: dt dns rollback --record %s --provider %s", record.ID, provider)))
+	fmt.Println()
+
+	return nil
+}
+
+// dnsRollbackResult is the JSON form of `dt dns rollback`
+type dnsRollbackResult struct {
+	RecordID     string `json:"record_id"`
+	Restored     bool   `json:"restored"`
+	CurrentValue string `json:"current_value"`
+}
+
+// Rollback restores a stored record's previous value
+func (c *DNSCommand) Rollback(ctx context.Context, opts DNSRollbackOptions) error {
+	record, err := c.state.GetDnsRecordContext(ctx, opts.RecordID)
+	if err != nil {
+		return fmt.Errorf("failed to load DNS record: %w", err)
+	}
+	if record == nil {
+		return fmt.Errorf("DNS record not found: %s", opts.RecordID)
+	}
+	if record.PreviousValue == nil {
+		return fmt.Errorf("record %s has no previous value to roll back to", record.ID)
+	}
+
+	provider, err := c.rollbackProvider(ctx, record, opts.Provider)
+	if err != nil {
+		return err
+	}
+	token, err := loadToken(provider)
+	if err != nil {
+		return err
+	}
+
+	if !c.JSON {
+		printHeader()
+		printProgress(fmt.Sprintf("Rolling back %s %s to %s...", record.RecordType, record.RecordName, *record.PreviousValue))
+	}
+
+	result, err := c.bridge.DnsRollback(ctx, bridge.DnsRollbackParams{
+		Provider:   provider,
+		Token:      token,
+		RecordID:   record.ProviderRecordID,
+		RollbackTo: *record.PreviousValue,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to roll back DNS: %w", err)
+	}
+	if !result.Restored {
+		return fmt.Errorf("provider did not restore record %s (current value: %s)", record.ID, result.CurrentValue)
+	}
+
+	// Record the rollback as a new entry pointing at the record it undid
+	rollback := state.DnsRecord{
+		ProviderRecordID: record.ProviderRecordID,
+		MigrationID:      record.MigrationID,
+		Domain:           record.Domain,
+		RecordType:       record.RecordType,
+		RecordName:       record.RecordName,
+		RecordValue:      result.CurrentValue,
+		TTL:              record.TTL,
+		RollbackID:       &record.ID,
+		PreviousValue:    &record.RecordValue,
+	}
+	if err := c.state.SaveDnsRecord(&rollback); err != nil {
+		return fmt.Errorf("DNS was rolled back but could not be recorded: %w", err)
+	}
+	if record.MigrationID != nil {
+		c.state.LogJSON(record.MigrationID, "info", fmt.Sprintf("rolled back %s %s to %s", record.RecordType, record.RecordName, result.CurrentValue), map[string]interface{}{"record_id": record.ID})
+	}
+
+	if c.JSON {
+		return printJSON(dnsRollbackResult{RecordID: record.ID, Restored: true, CurrentValue: result.CurrentValue})
+	}
+
+	fmt.Println(ui.Success(fmt.Sprintf("Restored %s %s to %s", record.RecordType, record.RecordName, result.CurrentValue)))
+	fmt.Println()
+	return nil
+}
+
+// rollbackProvider uses the flag when given, otherwise the owning migration's target
+func (c *DNSCommand) rollbackProvider(ctx context.Context, record *state.DnsRecord, flagValue string) (bridge.Provider, error) {
+	if flagValue != "" {
+		return c.bridge.ParseProvider(flagValue)
+	}
+	if record.MigrationID == nil {
+		return "", fmt.Errorf("record %s isn't tied to a migration; pass --provider", record.ID)
+	}
+
+	migration, err := c.state.GetMigrationContext(ctx, *record.MigrationID)
+	if err != nil {
+		return "", fmt.Errorf("failed to load migration: %w", err)
+	}
+	if migration == nil {
+		return "", fmt.Errorf("migration %s not found; pass --provider", *record.MigrationID)
+	}
+	return c.bridge.ParseProvider(migration.Target)
+}
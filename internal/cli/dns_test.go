@@ -0,0 +1,191 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/johnhorton/deploy-tunnel/internal/bridge"
+	"github.com/johnhorton/deploy-tunnel/internal/state"
+)
+
+// fakeDNS answers updates with rec_1 and a previous value of 192.0.2.1
+type fakeDNS struct {
+	builtinProviders
+	updates   []bridge.DnsUpdateParams
+	rollbacks []bridge.DnsRollbackParams
+	// notRestored makes rollbacks report the record unchanged
+	notRestored bool
+}
+
+func (f *fakeDNS) DnsUpdate(ctx context.Context, params bridge.DnsUpdateParams) (*bridge.DnsUpdateData, error) {
+	f.updates = append(f.updates, params)
+	previous := "192.0.2.1"
+	return &bridge.DnsUpdateData{RecordID: "rec_1", PreviousValue: &previous, PropagationTime: 60}, nil
+}
+
+func (f *fakeDNS) DnsRollback(ctx context.Context, params bridge.DnsRollbackParams) (*bridge.DnsRollbackData, error) {
+	f.rollbacks = append(f.rollbacks, params)
+	if f.notRestored {
+		return &bridge.DnsRollbackData{CurrentValue: "203.0.113.10"}, nil
+	}
+	return &bridge.DnsRollbackData{Restored: true, CurrentValue: params.RollbackTo}, nil
+}
+
+// newDNSCommand returns a JSON dns command on a state DB whose current
+// migration targets netlify for example.com
+func newDNSCommand(t *testing.T, br *fakeDNS) (*DNSCommand, *state.DB) {
+	t.Helper()
+	storeToken(t, "netlify")
+	db := newTestState(t)
+	if err := db.CreateMigration("m1", "vercel", "netlify", "example.com"); err != nil {
+		t.Fatal(err)
+	}
+	cmd := NewDNSCommand(db, br)
+	cmd.JSON = true
+	return cmd, db
+}
+
+func TestDNSUpdateAndRollback(t *testing.T) {
+	br := &fakeDNS{}
+	cmd, db := newDNSCommand(t, br)
+
+	var err error
+	out := captureStdout(t, func() {
+		err = cmd.Update(context.Background(), DNSUpdateOptions{
+			Provider: "netlify", Domain: "Example.com", Type: "A", Name: "@", Value: "203.0.113.10", TTL: 300,
+		})
+	})
+	if err != nil {
+		t.Fatalf("Update() error: %v", err)
+	}
+	var updated dnsUpdateResult
+	if err := json.Unmarshal([]byte(out), &updated); err != nil {
+		t.Fatalf("invalid JSON (%v):\n%s", err, out)
+	}
+	record := updated.Record
+	if record.ProviderRecordID != "rec_1" || record.Domain != "example.com" || record.PreviousValue == nil || *record.PreviousValue != "192.0.2.1" {
+		t.Errorf("saved record = %+v, want rec_1 on example.com with its previous value", record)
+	}
+	if record.MigrationID == nil || *record.MigrationID != "m1" {
+		t.Errorf("record migration = %v, want m1", record.MigrationID)
+	}
+	if br.updates[0].Token != "tok_netlify_0123456789" || br.updates[0].Domain != "example.com" {
+		t.Errorf("DnsUpdate got %+v, want the stored token and normalized domain", br.updates[0])
+	}
+
+	// No --provider: the migration's target is used
+	out = captureStdout(t, func() {
+		err = cmd.Rollback(context.Background(), DNSRollbackOptions{RecordID: record.ID})
+	})
+	if err != nil {
+		t.Fatalf("Rollback() error: %v", err)
+	}
+	if len(br.rollbacks) != 1 || br.rollbacks[0].Provider != bridge.ProviderNetlify || br.rollbacks[0].RecordID != "rec_1" || br.rollbacks[0].RollbackTo != "192.0.2.1" {
+		t.Errorf("DnsRollback got %+v, want rec_1 on netlify back to 192.0.2.1", br.rollbacks)
+	}
+	if !strings.Contains(out, `"restored": true`) {
+		t.Errorf("rollback output:\n%s", out)
+	}
+
+	records, err := db.GetDnsRecords("m1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var undone bool
+	for _, r := range records {
+		undone = undone || (r.RollbackID != nil && *r.RollbackID == record.ID && r.RecordValue == "192.0.2.1")
+	}
+	if !undone {
+		t.Errorf("the rollback wasn't recorded against %s: %+v", record.ID, records)
+	}
+}
+
+func TestDNSRollbackRefused(t *testing.T) {
+	previous := "192.0.2.1"
+	tests := []struct {
+		name string
+		// record is saved before rolling back; nil rolls back an unknown ID
+		record      *state.DnsRecord
+		provider    string
+		notRestored bool
+		wantErr     string
+		// wantCalled is whether the adapter is asked to roll back
+		wantCalled bool
+	}{
+		{name: "unknown record", wantErr: "DNS record not found: rec_missing"},
+		{
+			name:    "created record",
+			record:  &state.DnsRecord{ProviderRecordID: "rec_2", Domain: "example.com", RecordType: "A", RecordName: "@", RecordValue: "203.0.113.10"},
+			wantErr: "has no previous value",
+		},
+		{
+			name:    "no migration or provider",
+			record:  &state.DnsRecord{ProviderRecordID: "rec_3", Domain: "other.com", RecordType: "A", RecordName: "@", RecordValue: "203.0.113.10", PreviousValue: &previous},
+			wantErr: "pass --provider",
+		},
+		{
+			name:        "provider didn't restore",
+			record:      &state.DnsRecord{ProviderRecordID: "rec_4", Domain: "other.com", RecordType: "A", RecordName: "@", RecordValue: "203.0.113.10", PreviousValue: &previous},
+			provider:    "netlify",
+			notRestored: true,
+			wantErr:     "did not restore",
+			wantCalled:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			br := &fakeDNS{notRestored: tt.notRestored}
+			cmd, db := newDNSCommand(t, br)
+			id := "rec_missing"
+			if tt.record != nil {
+				if err := db.SaveDnsRecord(tt.record); err != nil {
+					t.Fatal(err)
+				}
+				id = tt.record.ID
+			}
+
+			var err error
+			captureStdout(t, func() {
+				err = cmd.Rollback(context.Background(), DNSRollbackOptions{RecordID: id, Provider: tt.provider})
+			})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Rollback() error = %v, want %q", err, tt.wantErr)
+			}
+			if called := len(br.rollbacks) > 0; called != tt.wantCalled {
+				t.Errorf("adapter called = %v, want %v", called, tt.wantCalled)
+			}
+		})
+	}
+}
+
+func TestParseDNSUpdateFlags(t *testing.T) {
+	base := []string{"--provider", "netlify", "--domain", "example.com", "--name", "@", "--value", "203.0.113.10"}
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{name: "lowercase type", args: append([]string{"--type", "cname"}, base...)},
+		{name: "missing flags", args: []string{"--provider", "netlify", "--type", "A"}, wantErr: "missing required flags: --domain, --name, --value"},
+		{name: "unknown type", args: append([]string{"--type", "MX"}, base...), wantErr: "invalid --type"},
+		{name: "zero ttl", args: append([]string{"--type", "A", "--ttl", "0"}, base...), wantErr: "--ttl must be positive"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := ParseDNSUpdateFlags(tt.args)
+			if tt.wantErr == "" {
+				if err != nil || opts.Type != "CNAME" || opts.TTL != 300 {
+					t.Errorf("ParseDNSUpdateFlags() = %+v, %v, want type CNAME and TTL 300", opts, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseDNSUpdateFlags() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
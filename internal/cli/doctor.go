@@ -0,0 +1,299 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/johnhorton/deploy-tunnel/internal/config"
+	"github.com/johnhorton/deploy-tunnel/internal/keychain"
+	"github.com/johnhorton/deploy-tunnel/internal/state"
+	"github.com/johnhorton/deploy-tunnel/ui"
+)
+
+func init() {
+	register(command{name: "doctor", run: (*runner).doctor, help: []helpLine{
+		{"doctor", "Check the environment"},
+	}})
+}
+
+func (r *runner) doctor(ctx context.Context, args []string) error {
+	cmd := NewDoctorCommand(r.cfg, r.lister())
+	cmd.JSON = r.global.JSON
+	return cmd.Run(ctx)
+}
+
+// runtimeVersionTimeout bounds `<runtime> --version`
+const runtimeVersionTimeout = 5 * time.Second
+
+// Check results
+const (
+	CheckPass = "pass"
+	CheckWarn = "warn"
+	CheckFail = "fail"
+)
+
+// DoctorCheck is the outcome of one environment check; a fail is blocking
+type DoctorCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail"`
+}
+
+// doctorResult is the JSON form of `dt doctor`
+type doctorResult struct {
+	OK     bool          `json:"ok"`
+	Checks []DoctorCheck `json:"checks"`
+}
+
+type DoctorCommand struct {
+	cfg    config.Config
+	bridge adapterLister
+
+	// Environment probes, replaceable to simulate failures
+	lookPath       func(file string) (string, error)
+	runtimeVersion func(ctx context.Context, path string) (string, error)
+	keychainStatus func() (backend string, available bool)
+	openState      func() (*state.DB, error)
+
+	// JSON prints the checks as JSON
+	JSON bool
+}
+
+func NewDoctorCommand(cfg config.Config, br adapterLister) *DoctorCommand {
+	return &DoctorCommand{
+		cfg:            cfg,
+		bridge:         br,
+		lookPath:       exec.LookPath,
+		runtimeVersion: runtimeVersion,
+		keychainStatus: func() (string, bool) { return keychain.BackendName(), keychain.Available() },
+		openState:      cfg.OpenState,
+	}
+}
+
+// runtimeVersion runs `<path> --version`
+func runtimeVersion(ctx context.Context, path string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, runtimeVersionTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, path, "--version").Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// Run checks the runtime, adapters, keychain, state database, and config
+// directory, and fails if any check fails. With --json the result is printed
+// instead, with ok set to false, and the command still fails.
+func (c *DoctorCommand) Run(ctx context.Context) error {
+	var checks []DoctorCheck
+	checks = append(checks, c.checkRuntime(ctx))
+	checks = append(checks, c.checkAdapters(ctx)...)
+	checks = append(checks, c.checkKeychain())
+	checks = append(checks, c.checkDatabase(ctx)...)
+	checks = append(checks, c.checkConfigDir())
+
+	result := doctorResult{OK: true, Checks: checks}
+	failed := 0
+	for _, check := range checks {
+		if check.Status == CheckFail {
+			result.OK = false
+			failed++
+		}
+	}
+
+	if c.JSON {
+		if err := printJSON(result); err != nil {
+			return err
+		}
+		if failed > 0 {
+			return reported(fmt.Errorf("%d check(s) failed", failed))
+		}
+		return nil
+	}
+
+	printHeader()
+
+	rows := make([][]string, len(checks))
+	for i, check := range checks {
+		rows[i] = []string{checkStatusLabel(check.Status), check.Name, check.Detail}
+	}
+	fmt.Println(ui.Table([]string{"STATUS", "CHECK", "DETAIL"}, rows))
+
+	if failed > 0 {
+		return fmt.Errorf("%d check(s) failed", failed)
+	}
+	fmt.Println(ui.Success("Environment looks good"))
+	fmt.Println()
+	return nil
+}
+
+func checkStatusLabel(status string) string {
+	switch status {
+	case CheckPass:
+		return ui.SuccessStyle.Render(status)
+	case CheckWarn:
+		return ui.WarningStyle.Render(status)
+	default:
+		return ui.ErrorStyle.Render(status)
+	}
+}
+
+func (c *DoctorCommand) checkRuntime(ctx context.Context) DoctorCheck {
+	check := DoctorCheck{Name: "runtime"}
+
+	path, err := c.lookPath(c.cfg.Runtime)
+	if err != nil {
+		check.Status = CheckFail
+		check.Detail = fmt.Sprintf("%s not found on PATH (install Bun from https://bun.sh or set runtime in the config)", c.cfg.Runtime)
+		return check
+	}
+
+	version, err := c.runtimeVersion(ctx, path)
+	if err != nil {
+		check.Status = CheckFail
+		check.Detail = fmt.Sprintf("%s --version failed: %s", path, err)
+		return check
+	}
+
+	check.Status = CheckPass
+	check.Detail = fmt.Sprintf("%s %s", path, version)
+	return check
+}
+
+// checkAdapters reports one row per installed adapter
+func (c *DoctorCommand) checkAdapters(ctx context.Context) []DoctorCheck {
+	adapters, err := c.bridge.ListAdapters(ctx)
+	if err != nil {
+		return []DoctorCheck{{Name: "adapters", Status: CheckFail, Detail: err.Error()}}
+	}
+	if len(adapters) == 0 {
+		return []DoctorCheck{{Name: "adapters", Status: CheckFail, Detail: fmt.Sprintf("no adapters found in %s", c.cfg.AdaptersPath)}}
+	}
+
+	checks := make([]DoctorCheck, len(adapters))
+	for i, a := range adapters {
+		checks[i] = DoctorCheck{Name: "adapter " + string(a.Provider)}
+		if a.Available() {
+			checks[i].Status = CheckPass
+			checks[i].Detail = fmt.Sprintf("%s v%s", a.Capabilities.AdapterName, a.Capabilities.AdapterVersion)
+			continue
+		}
+		// One broken adapter only matters when migrating with that provider
+		checks[i].Status = CheckWarn
+		checks[i].Detail = "capabilities failed: " + errorText(a.Err)
+	}
+	return checks
+}
+
+func (c *DoctorCommand) checkKeychain() DoctorCheck {
+	check := DoctorCheck{Name: "keychain"}
+	backend, available := c.keychainStatus()
+
+	switch {
+	case backend == keychain.BackendSystem && available:
+		check.Status = CheckPass
+		check.Detail = "system keychain"
+	case backend == keychain.BackendSystem:
+		check.Status = CheckFail
+		check.Detail = "system keychain is unavailable or locked"
+	case os.Getenv(keychain.EnvBackend) == keychain.BackendFile:
+		check.Status = CheckPass
+		check.Detail = fmt.Sprintf("encrypted file (%s=%s)", keychain.EnvBackend, keychain.BackendFile)
+	default:
+		check.Status = CheckWarn
+		check.Detail = "system keychain unavailable; using the encrypted file fallback"
+	}
+	return check
+}
+
+func (c *DoctorCommand) checkDatabase(ctx context.Context) []DoctorCheck {
+	db, err := c.openState()
+	if err != nil {
+		return []DoctorCheck{{Name: "database", Status: CheckFail, Detail: err.Error()}}
+	}
+	defer db.Close()
+
+	checks := []DoctorCheck{{Name: "database", Status: CheckPass, Detail: db.Path()}}
+	if reason := db.Fallback(); reason != nil {
+		// Commands still run, but nothing they record is kept
+		checks[0].Status = CheckFail
+		checks[0].Detail = fmt.Sprintf("using an in-memory database, nothing will be saved: %s", reason)
+		return checks
+	}
+	if err := db.CheckIntegrity(ctx); err != nil {
+		checks[0].Status = CheckFail
+		checks[0].Detail = err.Error()
+	}
+
+	schema := DoctorCheck{Name: "schema version"}
+	// Opening the database already migrated an older schema, so only a
+	// newer one can be out of step
+	version, err := db.SchemaVersion(ctx)
+	latest := state.LatestSchemaVersion()
+	switch {
+	case err != nil:
+		schema.Status = CheckFail
+		schema.Detail = fmt.Sprintf("failed to read schema version: %s", err)
+	case version > latest:
+		schema.Status = CheckFail
+		schema.Detail = fmt.Sprintf("database is at version %d but this build only knows %d; upgrade deploy-tunnel", version, latest)
+	default:
+		schema.Status = CheckPass
+		schema.Detail = fmt.Sprintf("version %d", version)
+	}
+	return append(checks, schema)
+}
+
+// sensitiveFiles are the credentials file backend's files, which must be
+// readable only by the user
+var sensitiveFiles = []string{"credentials.enc", "credentials.key"}
+
+func (c *DoctorCommand) checkConfigDir() DoctorCheck {
+	check := DoctorCheck{Name: "config dir"}
+
+	dir, err := state.ResolveConfigDir(c.cfg.StateDir)
+	if err != nil {
+		check.Status = CheckFail
+		check.Detail = err.Error()
+		return check
+	}
+
+	probe, err := os.CreateTemp(dir, ".doctor-*")
+	if err != nil {
+		check.Status = CheckFail
+		check.Detail = fmt.Sprintf("%s is not writable: %s", dir, err)
+		return check
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+
+	var exposed []string
+	for _, name := range sensitiveFiles {
+		info, err := os.Stat(filepath.Join(keychain.Dir(), name))
+		if err == nil && info.Mode().Perm()&0077 != 0 {
+			exposed = append(exposed, fmt.Sprintf("%s (%04o)", name, info.Mode().Perm()))
+		}
+	}
+	if len(exposed) > 0 {
+		check.Status = CheckWarn
+		check.Detail = fmt.Sprintf("readable by other users: %s; run chmod 600", strings.Join(exposed, ", "))
+		return check
+	}
+
+	check.Status = CheckPass
+	check.Detail = dir
+	return check
+}
+
+func errorText(err error) string {
+	if err == nil {
+		return "no response"
+	}
+	return strings.SplitN(err.Error(), "\n", 2)[0]
+}
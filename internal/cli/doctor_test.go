@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/johnhorton/deploy-tunnel/internal/bridge"
+	"github.com/johnhorton/deploy-tunnel/internal/config"
+	"github.com/johnhorton/deploy-tunnel/internal/keychain"
+	"github.com/johnhorton/deploy-tunnel/internal/state"
+)
+
+// fakeAdapterLister reports one working adapter
+type fakeAdapterLister struct{}
+
+func (fakeAdapterLister) ListAdapters(ctx context.Context) ([]bridge.ProviderCapabilities, error) {
+	caps := &bridge.CapabilitiesData{AdapterName: "vercel", AdapterVersion: "1.0.0"}
+	return []bridge.ProviderCapabilities{{Provider: "vercel", CapResult: bridge.CapResult{Capabilities: caps}}}, nil
+}
+
+func TestDoctorJSON(t *testing.T) {
+	tests := []struct {
+		name      string
+		lookPath  func(string) (string, error)
+		ok        bool
+		wantError bool
+	}{
+		{name: "all pass", lookPath: func(string) (string, error) { return "/usr/bin/bun", nil }, ok: true},
+		{name: "runtime missing", lookPath: func(string) (string, error) { return "", errors.New("not found") }, ok: false, wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			cmd := NewDoctorCommand(config.Config{Runtime: "bun", StateDir: dir}, fakeAdapterLister{})
+			cmd.JSON = true
+			cmd.lookPath = tt.lookPath
+			cmd.runtimeVersion = func(ctx context.Context, path string) (string, error) { return "1.1.0", nil }
+			cmd.keychainStatus = func() (string, bool) { return keychain.BackendSystem, true }
+			cmd.openState = func() (*state.DB, error) { return state.OpenWithKey(dir, make([]byte, 32)) }
+
+			var err error
+			out := captureStdout(t, func() { err = cmd.Run(context.Background()) })
+
+			var result doctorResult
+			if jsonErr := json.Unmarshal([]byte(out), &result); jsonErr != nil {
+				t.Fatalf("output is not JSON: %v\n%s", jsonErr, out)
+			}
+			if result.OK != tt.ok {
+				t.Errorf("ok = %v, want %v: %+v", result.OK, tt.ok, result.Checks)
+			}
+			if (err != nil) != tt.wantError {
+				t.Fatalf("Run error = %v, wantError %v", err, tt.wantError)
+			}
+			if err != nil && ExitCode(err) == 0 {
+				t.Error("failed checks exit 0")
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/johnhorton/deploy-tunnel/internal/bridge"
+	"github.com/johnhorton/deploy-tunnel/internal/state"
+	"github.com/johnhorton/deploy-tunnel/ui"
+)
+
+func init() {
+	register(command{name: "env", run: (*runner).env, help: []helpLine{
+		{"env diff", "Compare source and target env vars"},
+	}})
+}
+
+func (r *runner) env(ctx context.Context, args []string) error {
+	_, args, err := subcommand("env", args, "diff")
+	if err != nil {
+		return err
+	}
+	opts, err := ParseEnvDiffFlags(args)
+	if err != nil {
+		return err
+	}
+	db, br, err := r.open("")
+	if err != nil {
+		return err
+	}
+	cmd := NewEnvCommand(db, br)
+	cmd.JSON = r.global.JSON
+	return cmd.Diff(ctx, opts)
+}
+
+type EnvCommand struct {
+	state  *state.DB
+	bridge configFetcher
+
+	// JSON prints the diff as JSON
+	JSON bool
+}
+
+func NewEnvCommand(stateDB *state.DB, br configFetcher) *EnvCommand {
+	return &EnvCommand{
+		state:  stateDB,
+		bridge: br,
+	}
+}
+
+// EnvDiffOptions holds the flags for `dt env diff`
+type EnvDiffOptions struct {
+	MigrationID   string
+	SourceProject string
+	TargetProject string
+	// Environment limits the diff to one environment
+	Environment string
+}
+
+// ParseEnvDiffFlags parses `dt env diff [--migration id] [--source-project id] [--target-project id] [--env name]`
+func ParseEnvDiffFlags(args []string) (EnvDiffOptions, error) {
+	var opts EnvDiffOptions
+
+	fs := flag.NewFlagSet("env diff", flag.ContinueOnError)
+	fs.StringVar(&opts.MigrationID, "migration", "", "migration to compare (defaults to the current one)")
+	fs.StringVar(&opts.SourceProject, "source-project", "", "source project ID")
+	fs.StringVar(&opts.TargetProject, "target-project", "", "target project ID")
+	fs.StringVar(&opts.Environment, "env", "", "only compare this environment (production, preview, or development)")
+
+	if err := fs.Parse(args); err != nil {
+		return opts, err
+	}
+	if fs.NArg() > 0 {
+		return opts, fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+	if opts.Environment != "" {
+		env, err := bridge.ParseEnvironment(opts.Environment)
+		if err != nil {
+			return opts, fmt.Errorf("invalid --env: %w", err)
+		}
+		opts.Environment = env
+	}
+	return opts, nil
+}
+
+// envDiffResult is the JSON form of `dt env diff`
+type envDiffResult struct {
+	MigrationID string `json:"migration_id"`
+	bridge.EnvDiffResult
+}
+
+// Diff fetches both sides' env vars and lists the keys the target is
+// missing, has extra, or holds a different value for. Values are masked.
+func (c *EnvCommand) Diff(ctx context.Context, opts EnvDiffOptions) error {
+	migration, err := loadMigration(ctx, c.state, opts.MigrationID)
+	if err != nil {
+		return err
+	}
+
+	if !c.JSON {
+		printHeader()
+		printProgress(fmt.Sprintf("Comparing %s and %s env vars...", migration.Source, migration.Target))
+		printGap()
+	}
+
+	source, err := fetchProviderConfig(ctx, c.bridge, migration.Source, opts.SourceProject)
+	if err != nil {
+		return err
+	}
+	target, err := fetchProviderConfig(ctx, c.bridge, migration.Target, opts.TargetProject)
+	if err != nil {
+		return err
+	}
+
+	diff := bridge.EnvDiff(source, target)
+	if opts.Environment != "" {
+		diff = diff.ForEnvironment(opts.Environment)
+	}
+	if c.JSON {
+		return printJSON(envDiffResult{MigrationID: migration.ID, EnvDiffResult: diff})
+	}
+
+	if diff.Empty() {
+		fmt.Println(ui.Success("Source and target env vars match"))
+		fmt.Println()
+		return nil
+	}
+	fmt.Println(ui.Table([]string{"CHANGE", "KEY", "ENVIRONMENT", "SOURCE", "TARGET"}, envDiffRows(diff)))
+	fmt.Println(ui.Info(fmt.Sprintf("%d missing on %s, %d only on %s, %d different",
+		len(diff.Added), migration.Target, len(diff.Removed), migration.Target, len(diff.Changed))))
+	fmt.Println()
+	return nil
+}
+
+// envDiffRows lays out a diff as CHANGE, KEY, ENVIRONMENT, SOURCE, TARGET
+// table rows
+func envDiffRows(diff bridge.EnvDiffResult) [][]string {
+	var rows [][]string
+	add := func(change string, entries []bridge.EnvDiffEntry) {
+		for _, e := range entries {
+			env := e.Environment
+			if env == "" {
+				env = "all"
+			}
+			rows = append(rows, []string{change, e.Key, env, orDash(e.SourceValue), orDash(e.TargetValue)})
+		}
+	}
+	add("added", diff.Added)
+	add("changed", diff.Changed)
+	add("removed", diff.Removed)
+	return rows
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
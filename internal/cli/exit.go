@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"context"
+	"errors"
+
+	"github.com/johnhorton/deploy-tunnel/internal/bridge"
+)
+
+// Exit codes, so scripts can tell failures apart
+const (
+	ExitUnknown       = 1
+	ExitAuthRequired  = 10
+	ExitNetwork       = 11
+	ExitInvalidParams = 12
+	ExitNotFound      = 13
+	ExitTimeout       = 14
+)
+
+// ExitCode returns the process exit code for a command's error: 0 for nil,
+// a specific code when a *BridgeError in the chain has a code that maps to
+// one, and ExitUnknown otherwise. Call it after WriteError.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	var bridgeErr *bridge.BridgeError
+	if errors.As(err, &bridgeErr) {
+		switch bridgeErr.Code {
+		case bridge.ErrAuthRequired, bridge.ErrAuthFailed:
+			return ExitAuthRequired
+		case bridge.ErrNetworkError:
+			return ExitNetwork
+		case bridge.ErrInvalidParams:
+			return ExitInvalidParams
+		case bridge.ErrNotFound:
+			return ExitNotFound
+		case bridge.ErrTimeout:
+			return ExitTimeout
+		}
+		return ExitUnknown
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ExitTimeout
+	}
+	return ExitUnknown
+}
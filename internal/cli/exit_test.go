@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/johnhorton/deploy-tunnel/internal/config"
+	"github.com/johnhorton/deploy-tunnel/internal/keychain"
+)
+
+func TestMainExitCodes(t *testing.T) {
+	tests := []struct {
+		code string
+		want int
+	}{
+		{code: "AUTH_REQUIRED", want: ExitAuthRequired},
+		{code: "NETWORK_ERROR", want: ExitNetwork},
+		{code: "NOT_FOUND", want: ExitNotFound},
+		{code: "INTERNAL", want: ExitUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			stateDir := t.TempDir()
+			t.Setenv(config.EnvStateDir, stateDir)
+			t.Setenv(config.EnvConfigPath, filepath.Join(stateDir, "config.json"))
+
+			// Main keeps credentials in the state dir it's given
+			previous, previousDefaults := keychain.Dir(), defaults
+			keychain.SetDir(stateDir)
+			t.Cleanup(func() {
+				keychain.SetDir(previous)
+				defaults = previousDefaults
+			})
+			storeToken(t, "netlify")
+
+			// The adapter describes itself, then fails every other verb
+			script := fmt.Sprintf(`case "$3" in
+capabilities) echo '{"ok":true,"data":{"adapter_name":"netlify","adapter_version":"1.0.0","supported_verbs":["capabilities","fetch:config"],"auth_type":"token"}}' ;;
+*) echo '{"ok":false,"error":{"code":"%s","message":"adapter failed"}}' ;;
+esac`, tt.code)
+			adaptersPath, runtime := fakeAdapterFiles(t, "netlify", script)
+
+			var code int
+			out := captureStdout(t, func() {
+				code = Main([]string{
+					"--json",
+					"--adapters-path", adaptersPath,
+					"--runtime", runtime,
+					"fetch", "config", "--provider", "netlify", "--project", "prj_1",
+				})
+			})
+
+			if code != tt.want {
+				t.Errorf("Main() = %d, want %d\n%s", code, tt.want, out)
+			}
+			if !strings.Contains(out, `"code": "`+tt.code+`"`) {
+				t.Errorf("output has no %s error:\n%s", tt.code, out)
+			}
+		})
+	}
+}
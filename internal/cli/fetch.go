@@ -0,0 +1,202 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/johnhorton/deploy-tunnel/internal/bridge"
+	"github.com/johnhorton/deploy-tunnel/internal/state"
+	"github.com/johnhorton/deploy-tunnel/ui"
+)
+
+func init() {
+	register(command{name: "fetch", run: (*runner).fetch, help: []helpLine{
+		{"fetch config", "Fetch a provider project's config"},
+	}})
+}
+
+func (r *runner) fetch(ctx context.Context, args []string) error {
+	_, args, err := subcommand("fetch", args, "config")
+	if err != nil {
+		return err
+	}
+	opts, err := ParseFetchConfigFlags(args)
+	if err != nil {
+		return err
+	}
+	db, br, err := r.open("dt fetch config")
+	if err != nil {
+		return err
+	}
+	cmd := NewFetchCommand(db, br)
+	cmd.JSON = r.global.JSON
+	return cmd.Config(ctx, opts)
+}
+
+// configFetcher is the bridge calls FetchCommand needs
+type configFetcher interface {
+	FetchConfig(ctx context.Context, params bridge.FetchConfigParams) (*bridge.FetchConfigData, error)
+	ParseProvider(name string) (bridge.Provider, error)
+}
+
+type FetchCommand struct {
+	state  *state.DB
+	bridge configFetcher
+
+	// JSON prints the fetched config as JSON
+	JSON bool
+}
+
+func NewFetchCommand(stateDB *state.DB, br configFetcher) *FetchCommand {
+	return &FetchCommand{
+		state:  stateDB,
+		bridge: br,
+	}
+}
+
+// FetchConfigOptions holds the flags for `dt fetch config`
+type FetchConfigOptions struct {
+	Provider string
+	Project  string
+}
+
+// ParseFetchConfigFlags parses `dt fetch config --provider p [--project id]`
+func ParseFetchConfigFlags(args []string) (FetchConfigOptions, error) {
+	var opts FetchConfigOptions
+
+	fs := flag.NewFlagSet("fetch config", flag.ContinueOnError)
+	fs.StringVar(&opts.Provider, "provider", defaults.DefaultSource, "provider to fetch from")
+	fs.StringVar(&opts.Project, "project", "", "project ID (defaults to the provider's default project)")
+
+	if err := fs.Parse(args); err != nil {
+		return opts, err
+	}
+	if fs.NArg() > 0 {
+		return opts, fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+	if opts.Provider == "" {
+		return opts, fmt.Errorf("missing required flag: --provider")
+	}
+	return opts, nil
+}
+
+// fetchConfigResult is the JSON form of `dt fetch config`; env values are never included
+type fetchConfigResult struct {
+	Project     bridge.Project     `json:"project"`
+	Build       bridge.BuildConfig `json:"build"`
+	EnvKeys     []string           `json:"env_keys"`
+	MigrationID string             `json:"migration_id,omitempty"`
+	Saved       int                `json:"saved"`
+}
+
+// Config fetches a project's configuration and saves its env vars to the
+// current migration when the provider is that migration's source
+func (c *FetchCommand) Config(ctx context.Context, opts FetchConfigOptions) error {
+	provider, err := c.bridge.ParseProvider(opts.Provider)
+	if err != nil {
+		return err
+	}
+	token, err := loadToken(provider)
+	if err != nil {
+		return err
+	}
+
+	if !c.JSON {
+		printHeader()
+		printProgress(fmt.Sprintf("Fetching configuration from %s...", provider))
+		printGap()
+	}
+
+	config, err := c.bridge.FetchConfig(ctx, bridge.FetchConfigParams{
+		Provider:  provider,
+		Token:     token,
+		ProjectID: opts.Project,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to fetch config: %w", err)
+	}
+
+	migration, saved, note, err := c.saveEnvVars(provider, config.Env)
+	if err != nil {
+		return err
+	}
+
+	if c.JSON {
+		result := fetchConfigResult{
+			Project: config.Project,
+			Build:   config.Build,
+			EnvKeys: make([]string, len(config.Env)),
+			Saved:   saved,
+		}
+		for i, e := range config.Env {
+			result.EnvKeys[i] = e.Key
+		}
+		if migration != nil {
+			result.MigrationID = migration.ID
+		}
+		return printJSON(result)
+	}
+
+	fmt.Println(ui.KeyValue("Project", fmt.Sprintf("%s (%s)", config.Project.Name, config.Project.ID)))
+	if config.Project.Domain != "" {
+		fmt.Println(ui.KeyValue("Domain", config.Project.Domain))
+	}
+	if config.Project.Framework != "" {
+		fmt.Println(ui.KeyValue("Framework", config.Project.Framework))
+	}
+	fmt.Println(ui.KeyValue("Build Command", config.Build.Command))
+	fmt.Println(ui.KeyValue("Output Dir", config.Build.OutputDir))
+	if config.Build.InstallCommand != "" {
+		fmt.Println(ui.KeyValue("Install Command", config.Build.InstallCommand))
+	}
+	fmt.Println()
+
+	rows := make([][]string, len(config.Env))
+	for i, e := range config.Env {
+		rows[i] = []string{e.Key, "********", strings.Join(e.Target, ", ")}
+	}
+	fmt.Println(ui.Table([]string{"KEY", "VALUE", "TARGETS"}, rows))
+
+	switch {
+	case note != "":
+		fmt.Println(ui.Warning(note))
+	case saved > 0:
+		fmt.Println(ui.Success(fmt.Sprintf("Saved %d env vars to migration %s", saved, migration.ID)))
+	}
+	fmt.Println()
+
+	return nil
+}
+
+// saveEnvVars stores fetched vars on the current migration. note explains
+// why nothing was saved when the vars were skipped.
+func (c *FetchCommand) saveEnvVars(provider bridge.Provider, env []bridge.EnvVar) (migration *state.Migration, saved int, note string, err error) {
+	migration, err = c.state.CurrentMigration()
+	if err != nil {
+		return nil, 0, "", fmt.Errorf("failed to load current migration: %w", err)
+	}
+	if migration == nil {
+		return nil, 0, "No migration to save env vars to (run: dt init)", nil
+	}
+	if migration.Source != string(provider) {
+		return migration, 0, fmt.Sprintf("Env vars not saved: the current migration's source is %s", migration.Source), nil
+	}
+
+	// Re-fetching must not duplicate vars saved by an earlier fetch
+	existing, err := c.state.GetEnvVars(migration.ID)
+	if err != nil {
+		return nil, 0, "", fmt.Errorf("failed to load env vars: %w", err)
+	}
+	if len(existing) > 0 {
+		return migration, 0, fmt.Sprintf("Env vars not saved: migration %s already has %d", migration.ID, len(existing)), nil
+	}
+
+	for _, e := range env {
+		if err := c.state.SaveEnvVar(migration.ID, e.Key, e.Value, e.Key, e.Target); err != nil {
+			return nil, 0, "", fmt.Errorf("failed to save %s: %w", e.Key, err)
+		}
+	}
+	return migration, len(env), "", nil
+}
@@ -0,0 +1,157 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/johnhorton/deploy-tunnel/internal/bridge"
+)
+
+// fakeFetcher returns a sample config, or err
+type fakeFetcher struct {
+	builtinProviders
+	err    error
+	params bridge.FetchConfigParams
+}
+
+func (f *fakeFetcher) FetchConfig(ctx context.Context, params bridge.FetchConfigParams) (*bridge.FetchConfigData, error) {
+	f.params = params
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &bridge.FetchConfigData{
+		Project: bridge.Project{ID: "prj_1", Name: "shop", Domain: "shop.example.com"},
+		Build:   bridge.BuildConfig{Command: "npm run build", OutputDir: "dist"},
+		Env: []bridge.EnvVar{
+			{Key: "API_KEY", Value: "sk_live_secret", Target: []string{"production"}},
+			{Key: "DEBUG", Value: "debug_value", Target: []string{"preview", "development"}},
+		},
+	}, nil
+}
+
+func TestFetchConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		// source is the current migration's source, "" for no migration
+		source string
+		json   bool
+		// wantSaved is how many env vars end up on the migration
+		wantSaved int
+		wantNote  string
+	}{
+		{name: "saves to the current migration", source: "vercel", wantSaved: 2},
+		{name: "json", source: "vercel", json: true, wantSaved: 2},
+		{name: "other source", source: "netlify", wantNote: "current migration's source is netlify"},
+		{name: "no migration", wantNote: "No migration to save env vars to"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storeToken(t, "vercel")
+			db := newTestState(t)
+			if tt.source != "" {
+				if err := db.CreateMigration("m1", tt.source, "cloudflare", "example.com"); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			fetcher := &fakeFetcher{}
+			cmd := NewFetchCommand(db, fetcher)
+			cmd.JSON = tt.json
+			var err error
+			out := captureStdout(t, func() {
+				err = cmd.Config(context.Background(), FetchConfigOptions{Provider: "vercel", Project: "prj_1"})
+			})
+			if err != nil {
+				t.Fatalf("Config() error: %v", err)
+			}
+			if fetcher.params.Token != "tok_vercel_0123456789" || fetcher.params.ProjectID != "prj_1" {
+				t.Errorf("FetchConfig got %+v, want the stored token and project", fetcher.params)
+			}
+			for _, secret := range []string{"sk_live_secret", "debug_value"} {
+				if strings.Contains(out, secret) {
+					t.Errorf("output shows the value %q:\n%s", secret, out)
+				}
+			}
+
+			if tt.json {
+				var result fetchConfigResult
+				if err := json.Unmarshal([]byte(out), &result); err != nil {
+					t.Fatalf("invalid JSON (%v):\n%s", err, out)
+				}
+				if result.Project.ID != "prj_1" || strings.Join(result.EnvKeys, ",") != "API_KEY,DEBUG" || result.Saved != tt.wantSaved {
+					t.Errorf("JSON = %+v", result)
+				}
+			} else {
+				for _, want := range []string{"shop (prj_1)", "npm run build", "API_KEY", "preview, development", tt.wantNote} {
+					if !strings.Contains(out, want) {
+						t.Errorf("output is missing %q:\n%s", want, out)
+					}
+				}
+			}
+
+			if tt.source == "" {
+				return
+			}
+			vars, err := db.GetEnvVars("m1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(vars) != tt.wantSaved {
+				t.Fatalf("saved %d env vars, want %d", len(vars), tt.wantSaved)
+			}
+			if tt.wantSaved > 0 && (vars[0].Key != "API_KEY" || vars[0].Value != "sk_live_secret") {
+				t.Errorf("saved %+v, want API_KEY with its value", vars[0])
+			}
+		})
+	}
+}
+
+func TestFetchConfigKeepsEarlierVars(t *testing.T) {
+	storeToken(t, "vercel")
+	db := newTestState(t)
+	if err := db.CreateMigration("m1", "vercel", "netlify", "example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.SaveEnvVar("m1", "OLD", "1", "OLD", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	out := captureStdout(t, func() {
+		if err := NewFetchCommand(db, &fakeFetcher{}).Config(context.Background(), FetchConfigOptions{Provider: "vercel"}); err != nil {
+			t.Fatalf("Config() error: %v", err)
+		}
+	})
+	if !strings.Contains(out, "already has 1") {
+		t.Errorf("output doesn't say why nothing was saved:\n%s", out)
+	}
+	if vars, _ := db.GetEnvVars("m1"); len(vars) != 1 {
+		t.Errorf("migration has %d env vars after re-fetching, want 1", len(vars))
+	}
+}
+
+func TestFetchConfigErrors(t *testing.T) {
+	db := newTestState(t)
+
+	err := NewFetchCommand(db, &fakeFetcher{}).Config(context.Background(), FetchConfigOptions{Provider: "vercel"})
+	var bridgeErr *bridge.BridgeError
+	if !errors.As(err, &bridgeErr) || bridgeErr.Code != bridge.ErrAuthRequired {
+		t.Errorf("without a token, Config() = %v, want %s", err, bridge.ErrAuthRequired)
+	}
+
+	storeToken(t, "vercel")
+	failed := &bridge.BridgeError{Code: bridge.ErrNotFound, Message: "project not found"}
+	captureStdout(t, func() {
+		err = NewFetchCommand(db, &fakeFetcher{err: failed}).Config(context.Background(), FetchConfigOptions{Provider: "vercel"})
+	})
+	if !errors.As(err, &bridgeErr) || bridgeErr.Code != bridge.ErrNotFound {
+		t.Errorf("Config() = %v, want the adapter's %s", err, bridge.ErrNotFound)
+	}
+
+	if err := NewFetchCommand(db, &fakeFetcher{}).Config(context.Background(), FetchConfigOptions{Provider: "heroku"}); err == nil {
+		t.Error("Config() with an unknown provider succeeded")
+	}
+}
@@ -0,0 +1,181 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/johnhorton/deploy-tunnel/internal/bridge"
+	"github.com/johnhorton/deploy-tunnel/internal/keychain"
+	"github.com/johnhorton/deploy-tunnel/ui"
+)
+
+func init() {
+	register(command{name: "health", run: (*runner).health, help: []helpLine{
+		{"health", "Check each adapter"},
+	}})
+}
+
+func (r *runner) health(ctx context.Context, args []string) error {
+	opts, err := ParseHealthFlags(args)
+	if err != nil {
+		return err
+	}
+	br, err := r.newBridge()
+	if err != nil {
+		return err
+	}
+	cmd := NewHealthCommand(br)
+	cmd.JSON = r.global.JSON
+	return cmd.Run(ctx, opts)
+}
+
+// healthChecker is the bridge calls HealthCommand needs
+type healthChecker interface {
+	HealthAll(ctx context.Context, tokens map[bridge.Provider]string) []bridge.HealthResult
+	ParseProvider(name string) (bridge.Provider, error)
+}
+
+type HealthCommand struct {
+	bridge healthChecker
+
+	// JSON prints the results as JSON
+	JSON bool
+}
+
+func NewHealthCommand(br healthChecker) *HealthCommand {
+	return &HealthCommand{
+		bridge: br,
+	}
+}
+
+// HealthOptions holds the arguments for `dt health`
+type HealthOptions struct {
+	// Providers limits the check; empty checks every authenticated provider
+	Providers []string
+}
+
+// ParseHealthFlags parses `dt health [provider...]`
+func ParseHealthFlags(args []string) (HealthOptions, error) {
+	var opts HealthOptions
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "-") {
+			return opts, fmt.Errorf("unexpected arguments: %s", strings.Join(args, " "))
+		}
+		opts.Providers = appendProvider(opts.Providers, arg)
+	}
+	return opts, nil
+}
+
+// providerHealth is the JSON form of one `dt health` entry
+type providerHealth struct {
+	Provider  string `json:"provider"`
+	Status    string `json:"status"`
+	LatencyMs int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+// healthResult is the JSON form of `dt health`
+type healthResult struct {
+	OK        bool             `json:"ok"`
+	Providers []providerHealth `json:"providers"`
+}
+
+// Run checks every authenticated provider (or the ones named) at once and
+// prints each one's status and latency. It fails if any provider is down;
+// degraded ones only warn. With --json the result is printed instead, with
+// ok set to false.
+func (c *HealthCommand) Run(ctx context.Context, opts HealthOptions) error {
+	tokens, err := healthTokens(c.bridge, opts.Providers)
+	if err != nil {
+		return err
+	}
+
+	results := c.bridge.HealthAll(ctx, tokens)
+	result := healthResult{OK: true, Providers: make([]providerHealth, len(results))}
+	down := 0
+	for i, r := range results {
+		result.Providers[i] = providerHealth{
+			Provider:  string(r.Provider),
+			Status:    r.Status,
+			LatencyMs: r.Latency.Milliseconds(),
+		}
+		if r.Err != nil {
+			result.Providers[i].Error = errorText(r.Err)
+		}
+		if r.Status == bridge.HealthDown {
+			result.OK = false
+			down++
+		}
+	}
+
+	if c.JSON {
+		return printJSON(result)
+	}
+
+	printHeader()
+
+	if len(results) == 0 {
+		fmt.Println(ui.Warning("No credentials stored"))
+		fmt.Println()
+		fmt.Println(ui.Info("Run: dt auth <provider>"))
+		fmt.Println()
+		return nil
+	}
+
+	rows := make([][]string, len(results))
+	for i, r := range results {
+		rows[i] = []string{healthStatusLabel(r.Status), string(r.Provider), r.Latency.Round(time.Millisecond).String(), orDash(result.Providers[i].Error)}
+	}
+	fmt.Println(ui.Table([]string{"STATUS", "PROVIDER", "LATENCY", "ERROR"}, rows))
+
+	if down > 0 {
+		return fmt.Errorf("%d of %d providers down", down, len(results))
+	}
+	fmt.Println(ui.Success("All providers reachable"))
+	fmt.Println()
+	return nil
+}
+
+// healthTokens loads the token of each named provider, or of every
+// authenticated one when none are named. Stored credentials for providers
+// with no installed adapter are skipped.
+func healthTokens(br healthChecker, names []string) (map[bridge.Provider]string, error) {
+	if len(names) == 0 {
+		stored, err := keychain.List()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list credentials: %w", err)
+		}
+		for _, name := range stored {
+			if _, err := br.ParseProvider(name); err == nil {
+				names = append(names, name)
+			}
+		}
+	}
+
+	tokens := make(map[bridge.Provider]string, len(names))
+	for _, name := range names {
+		provider, err := br.ParseProvider(name)
+		if err != nil {
+			return nil, err
+		}
+		token, err := loadToken(provider)
+		if err != nil {
+			return nil, err
+		}
+		tokens[provider] = token
+	}
+	return tokens, nil
+}
+
+func healthStatusLabel(status string) string {
+	switch status {
+	case bridge.HealthOK:
+		return ui.SuccessStyle.Render(status)
+	case bridge.HealthDegraded:
+		return ui.WarningStyle.Render(status)
+	default:
+		return ui.ErrorStyle.Render(status)
+	}
+}
@@ -0,0 +1,163 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/johnhorton/deploy-tunnel/internal/bridge"
+)
+
+// fakeHealth answers HealthAll with a fixed status for each provider, sorted
+// by provider as the bridge does
+type fakeHealth struct {
+	builtinProviders
+	statuses map[bridge.Provider]string
+	// checked is the tokens of the last HealthAll call
+	checked map[bridge.Provider]string
+}
+
+func (f *fakeHealth) HealthAll(ctx context.Context, tokens map[bridge.Provider]string) []bridge.HealthResult {
+	f.checked = tokens
+	var results []bridge.HealthResult
+	for _, p := range slices.Sorted(maps.Keys(tokens)) {
+		r := bridge.HealthResult{Provider: p, Status: f.statuses[p]}
+		if r.Status != bridge.HealthOK {
+			r.Err = errors.New(string(p) + " is " + r.Status)
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+func TestParseHealthFlags(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want []string
+		// wantErr is a substring of the expected error, "" when parsing succeeds
+		wantErr string
+	}{
+		{name: "every provider", args: nil},
+		{name: "named", args: []string{"vercel", "netlify"}, want: []string{"vercel", "netlify"}},
+		{name: "repeated", args: []string{"vercel", "vercel"}, want: []string{"vercel"}},
+		{name: "flag", args: []string{"--all"}, wantErr: "unexpected arguments: --all"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseHealthFlags(tt.args)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("ParseHealthFlags(%v) error = %v, want %q", tt.args, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseHealthFlags(%v) error: %v", tt.args, err)
+			}
+			if !slices.Equal(got.Providers, tt.want) {
+				t.Errorf("ParseHealthFlags(%v) = %v, want %v", tt.args, got.Providers, tt.want)
+			}
+		})
+	}
+}
+
+func TestHealthRun(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses map[bridge.Provider]string
+		wantOK   bool
+		// wantErr is a substring of the expected error, "" when every provider is up
+		wantErr string
+	}{
+		{
+			name:     "all ok",
+			statuses: map[bridge.Provider]string{"netlify": bridge.HealthOK, "vercel": bridge.HealthOK},
+			wantOK:   true,
+		},
+		{
+			name:     "degraded only warns",
+			statuses: map[bridge.Provider]string{"netlify": bridge.HealthDegraded, "vercel": bridge.HealthOK},
+			wantOK:   true,
+		},
+		{
+			name:     "down fails",
+			statuses: map[bridge.Provider]string{"netlify": bridge.HealthDown, "vercel": bridge.HealthOK},
+			wantErr:  "1 of 2 providers down",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storeToken(t, "netlify")
+			storeToken(t, "vercel")
+			checker := &fakeHealth{statuses: tt.statuses}
+
+			cmd := NewHealthCommand(checker)
+			var err error
+			out := captureStdout(t, func() { err = cmd.Run(context.Background(), HealthOptions{}) })
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Run() error = %v, want %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Errorf("Run() error: %v", err)
+			}
+			if checker.checked["vercel"] != "tok_vercel_0123456789" || len(checker.checked) != 2 {
+				t.Errorf("checked %v, want every stored provider with its token", checker.checked)
+			}
+			if !strings.Contains(out, "netlify") || !strings.Contains(out, "vercel") {
+				t.Errorf("output doesn't list every provider:\n%s", out)
+			}
+
+			// JSON reports the same outcome in ok, without failing
+			cmd.JSON = true
+			out = captureStdout(t, func() { err = cmd.Run(context.Background(), HealthOptions{}) })
+			if err != nil {
+				t.Fatalf("Run() with JSON error: %v", err)
+			}
+			var result healthResult
+			if err := json.Unmarshal([]byte(out), &result); err != nil {
+				t.Fatalf("output is not JSON: %v\n%s", err, out)
+			}
+			if result.OK != tt.wantOK || len(result.Providers) != 2 {
+				t.Errorf("JSON = %+v, want ok %v for 2 providers", result, tt.wantOK)
+			}
+			if p := result.Providers[0]; p.Provider != "netlify" || p.Status != tt.statuses["netlify"] {
+				t.Errorf("netlify = %+v, want status %s", p, tt.statuses["netlify"])
+			}
+		})
+	}
+}
+
+func TestHealthRunNamedProviders(t *testing.T) {
+	storeToken(t, "netlify")
+	storeToken(t, "vercel")
+	checker := &fakeHealth{statuses: map[bridge.Provider]string{"vercel": bridge.HealthOK}}
+
+	cmd := NewHealthCommand(checker)
+	cmd.JSON = true
+	captureStdout(t, func() {
+		if err := cmd.Run(context.Background(), HealthOptions{Providers: []string{"vercel"}}); err != nil {
+			t.Errorf("Run() error: %v", err)
+		}
+	})
+	if len(checker.checked) != 1 || checker.checked["vercel"] == "" {
+		t.Errorf("checked %v, want only vercel", checker.checked)
+	}
+
+	// A named provider without credentials fails before anything is checked
+	checker.checked = nil
+	err := cmd.Run(context.Background(), HealthOptions{Providers: []string{"cloudflare"}})
+	if err == nil || !strings.Contains(err.Error(), "no credentials for cloudflare") {
+		t.Errorf("Run(cloudflare) error = %v, want a missing credentials error", err)
+	}
+	if checker.checked != nil {
+		t.Errorf("checked %v, want nothing", checker.checked)
+	}
+}
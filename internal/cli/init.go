@@ -3,6 +3,7 @@ package cli
 import (
 	"bufio"
 	"context"
+	"flag"
 	"fmt"
 	"os"
 	"strconv"
@@ -12,12 +13,36 @@ import (
 	"github.com/johnhorton/deploy-tunnel/internal/bridge"
 	"github.com/johnhorton/deploy-tunnel/internal/keychain"
 	"github.com/johnhorton/deploy-tunnel/internal/state"
+	"github.com/johnhorton/deploy-tunnel/internal/validate"
 	"github.com/johnhorton/deploy-tunnel/ui"
 )
 
+func init() {
+	register(command{name: "init", run: (*runner).init, help: []helpLine{
+		{"init", "Create a migration"},
+	}})
+}
+
+func (r *runner) init(ctx context.Context, args []string) error {
+	opts, err := ParseInitFlags(args)
+	if err != nil {
+		return err
+	}
+	db, br, err := r.open("dt init")
+	if err != nil {
+		return err
+	}
+	cmd := NewInitCommand(db, br)
+	cmd.JSON = r.global.JSON
+	return cmd.RunWithOptions(ctx, opts)
+}
+
 type InitCommand struct {
 	state  *state.DB
 	bridge *bridge.Bridge
+
+	// JSON prints the created migration as JSON; it requires the flag-driven mode
+	JSON bool
 }
 
 func NewInitCommand(stateDB *state.DB, br *bridge.Bridge) *InitCommand {
@@ -27,9 +52,147 @@ func NewInitCommand(stateDB *state.DB, br *bridge.Bridge) *InitCommand {
 	}
 }
 
+// InitOptions holds the flags for non-interactive init
+type InitOptions struct {
+	Source string
+	Target string
+	Domain string
+	Yes    bool
+	// From is a migration to copy source, target, and domain from
+	From string
+	// WithEnv also copies From's env var mappings
+	WithEnv bool
+}
+
+// Interactive reports whether no migration flags were given, so init should prompt
+func (o InitOptions) Interactive() bool {
+	return o.Source == "" && o.Target == "" && o.Domain == "" && o.From == ""
+}
+
+// ParseInitFlags parses `dt init [--source p] [--target p] [--domain d] [--yes]`
+// or `dt init --from <migration> [--with-env]`
+func ParseInitFlags(args []string) (InitOptions, error) {
+	var opts InitOptions
+
+	fs := flag.NewFlagSet("init", flag.ContinueOnError)
+	fs.StringVar(&opts.Source, "source", "", "provider to migrate from")
+	fs.StringVar(&opts.Target, "target", "", "provider to migrate to")
+	fs.StringVar(&opts.Domain, "domain", "", "domain to migrate")
+	fs.BoolVar(&opts.Yes, "yes", false, "accept warnings without prompting")
+	fs.StringVar(&opts.From, "from", "", "migration to copy settings from")
+	fs.BoolVar(&opts.WithEnv, "with-env", false, "with --from, also copy its env var mappings")
+
+	if err := fs.Parse(args); err != nil {
+		return opts, err
+	}
+	if fs.NArg() > 0 {
+		return opts, fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+	if opts.From != "" && (opts.Source != "" || opts.Target != "" || opts.Domain != "") {
+		return opts, fmt.Errorf("--from can't be combined with --source, --target, or --domain")
+	}
+	if opts.WithEnv && opts.From == "" {
+		return opts, fmt.Errorf("--with-env requires --from")
+	}
+	return opts, nil
+}
+
+// RunWithOptions creates a migration from flags, or falls back to the
+// interactive prompts when none were given
+func (c *InitCommand) RunWithOptions(ctx context.Context, opts InitOptions) error {
+	if opts.Interactive() {
+		if c.JSON {
+			return fmt.Errorf("--json requires --source, --target, and --domain")
+		}
+		return c.Run(ctx)
+	}
+	if opts.From != "" {
+		return c.clone(ctx, opts)
+	}
+
+	if opts.Source == "" {
+		opts.Source = defaults.DefaultSource
+	}
+	if opts.Target == "" {
+		opts.Target = defaults.DefaultTarget
+	}
+
+	var missing []string
+	if opts.Source == "" {
+		missing = append(missing, "--source")
+	}
+	if opts.Target == "" {
+		missing = append(missing, "--target")
+	}
+	if opts.Domain == "" {
+		missing = append(missing, "--domain")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing required flags: %s", strings.Join(missing, ", "))
+	}
+
+	source, err := c.bridge.ParseProvider(opts.Source)
+	if err != nil {
+		return fmt.Errorf("invalid --source: %w", err)
+	}
+	target, err := c.bridge.ParseProvider(opts.Target)
+	if err != nil {
+		return fmt.Errorf("invalid --target: %w", err)
+	}
+	if source == target && !opts.Yes {
+		return fmt.Errorf("source and target are both %s; pass --yes to continue anyway", source)
+	}
+	if err := validate.Domain(opts.Domain); err != nil {
+		return fmt.Errorf("invalid --domain: %w", err)
+	}
+	domain := validate.NormalizeDomain(opts.Domain)
+
+	migrationID := uuid.New().String()
+	if err := c.state.CreateMigration(migrationID, string(source), string(target), domain); err != nil {
+		return fmt.Errorf("failed to create migration: %w", err)
+	}
+	return c.printCreated(migrationID)
+}
+
+// clone starts a migration from an existing one's settings
+func (c *InitCommand) clone(ctx context.Context, opts InitOptions) error {
+	from, err := loadMigration(ctx, c.state, opts.From)
+	if err != nil {
+		return err
+	}
+
+	clone := c.state.CloneMigration
+	if opts.WithEnv {
+		clone = c.state.CloneMigrationWithEnv
+	}
+	migrationID, err := clone(from.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create migration: %w", err)
+	}
+	c.state.LogJSON(&migrationID, "info", "Migration cloned", map[string]interface{}{
+		"from":     from.ID,
+		"with_env": opts.WithEnv,
+	})
+	return c.printCreated(migrationID)
+}
+
+// printCreated reports a migration made in flag mode
+func (c *InitCommand) printCreated(migrationID string) error {
+	if c.JSON {
+		migration, err := c.state.GetMigration(migrationID)
+		if err != nil {
+			return fmt.Errorf("failed to load migration: %w", err)
+		}
+		return printJSON(migration)
+	}
+
+	// Print only the ID so scripts can capture it
+	fmt.Println(migrationID)
+	return nil
+}
+
 func (c *InitCommand) Run(ctx context.Context) error {
-	fmt.Println(ui.Header())
-	fmt.Println()
+	printHeader()
 	fmt.Println(ui.Info("Let's set up your migration"))
 	fmt.Println()
 
@@ -52,13 +215,13 @@ func (c *InitCommand) Run(ctx context.Context) error {
 	}
 
 	// Prompt for domain
-	domain, err := c.promptString("Domain name to migrate")
+	domain, err := c.promptDomain("Domain name to migrate")
 	if err != nil {
 		return fmt.Errorf("failed to get domain: %w", err)
 	}
 
 	fmt.Println()
-	fmt.Println(ui.Info("Creating migration configuration..."))
+	printProgress("Creating migration configuration...")
 
 	// Create migration record
 	migrationID := uuid.New().String()
@@ -75,35 +238,21 @@ func (c *InitCommand) Run(ctx context.Context) error {
 	fmt.Println()
 
 	// Check authentication
-	fmt.Println(ui.Info("Checking authentication status..."))
-	fmt.Println()
-
-	sourceAuth, _ := keychain.Get(string(source))
-	targetAuth, _ := keychain.Get(string(target))
+	printProgress("Checking authentication status...")
+	printGap()
 
-	if sourceAuth == "" {
-		fmt.Println(ui.Warning(fmt.Sprintf("No credentials found for %s", source)))
-		fmt.Println(ui.Info(fmt.Sprintf("Run: dt auth %s", source)))
-	} else {
-		fmt.Println(ui.Success(fmt.Sprintf("%s is authenticated", source)))
-	}
-
-	if targetAuth == "" {
-		fmt.Println(ui.Warning(fmt.Sprintf("No credentials found for %s", target)))
-		fmt.Println(ui.Info(fmt.Sprintf("Run: dt auth %s", target)))
-	} else {
-		fmt.Println(ui.Success(fmt.Sprintf("%s is authenticated", target)))
-	}
+	printAuthStatus(source)
+	printAuthStatus(target)
 
 	fmt.Println()
 	fmt.Println(ui.Info("Next steps:"))
 	fmt.Println(ui.List([]string{
 		fmt.Sprintf("Authenticate providers: dt auth %s && dt auth %s", source, target),
-		"Fetch source configuration: dt fetch:config",
-		"Sync environment variables: dt sync env",
+		fmt.Sprintf("Fetch source configuration: dt fetch config --provider %s", source),
+		fmt.Sprintf("Sync environment variables: dt sync env --provider %s", target),
 		"Create preview tunnel: dt tunnel create --preview",
 		"Verify routes: dt verify",
-		"Cutover when ready: dt cutover",
+		"Cutover when ready: dt cutover --record TYPE:NAME:VALUE",
 	}))
 	fmt.Println()
 
@@ -140,6 +289,19 @@ func (c *InitCommand) selectProvider(prompt string) (bridge.Provider, error) {
 	return providers[choice-1], nil
 }
 
+func (c *InitCommand) promptDomain(prompt string) (string, error) {
+	input, err := c.promptString(prompt)
+	if err != nil {
+		return "", err
+	}
+
+	if err := validate.Domain(input); err != nil {
+		return "", fmt.Errorf("invalid domain: %w", err)
+	}
+
+	return validate.NormalizeDomain(input), nil
+}
+
 func (c *InitCommand) promptString(prompt string) (string, error) {
 	fmt.Printf("%s %s: ", ui.KeyStyle.Render("?"), prompt)
 
@@ -151,3 +313,18 @@ func (c *InitCommand) promptString(prompt string) (string, error) {
 
 	return strings.TrimSpace(input), nil
 }
+
+// printAuthStatus reports whether credentials are stored for provider,
+// saying so when the keychain can't be read rather than claiming they're missing
+func printAuthStatus(provider bridge.Provider) {
+	exists, err := keychain.Exists(string(provider))
+	switch {
+	case err != nil:
+		fmt.Println(ui.Warning(fmt.Sprintf("Couldn't check credentials for %s: %s", provider, err)))
+	case exists:
+		fmt.Println(ui.Success(fmt.Sprintf("%s is authenticated", provider)))
+	default:
+		fmt.Println(ui.Warning(fmt.Sprintf("No credentials found for %s", provider)))
+		fmt.Println(ui.Info(fmt.Sprintf("Run: dt auth %s", provider)))
+	}
+}
@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/johnhorton/deploy-tunnel/internal/bridge"
+)
+
+func TestParseInitFlags(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want InitOptions
+		// wantErr is a substring of the expected error, "" when parsing succeeds
+		wantErr string
+	}{
+		{name: "interactive", args: nil},
+		{
+			name: "all flags",
+			args: []string{"--source", "vercel", "--target", "netlify", "--domain", "example.com", "--yes"},
+			want: InitOptions{Source: "vercel", Target: "netlify", Domain: "example.com", Yes: true},
+		},
+		{name: "clone", args: []string{"--from", "m1", "--with-env"}, want: InitOptions{From: "m1", WithEnv: true}},
+		{name: "extra argument", args: []string{"--source", "vercel", "netlify"}, wantErr: "unexpected arguments: netlify"},
+		{name: "from with source", args: []string{"--from", "m1", "--source", "vercel"}, wantErr: "--from can't be combined"},
+		{name: "from with domain", args: []string{"--from", "m1", "--domain", "example.com"}, wantErr: "--from can't be combined"},
+		{name: "with-env alone", args: []string{"--with-env"}, wantErr: "--with-env requires --from"},
+		{name: "unknown flag", args: []string{"--provider", "vercel"}, wantErr: "provider"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseInitFlags(tt.args)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("ParseInitFlags(%v) error = %v, want %q", tt.args, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseInitFlags(%v) error: %v", tt.args, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseInitFlags(%v) = %+v, want %+v", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInitWithOptions(t *testing.T) {
+	tests := []struct {
+		name string
+		opts InitOptions
+		json bool
+		// wantErr is a substring of the expected error, "" when a migration is created
+		wantErr string
+	}{
+		{name: "created", opts: InitOptions{Source: "vercel", Target: "netlify", Domain: "Example.COM"}},
+		{name: "created as json", opts: InitOptions{Source: "vercel", Target: "netlify", Domain: "example.com"}, json: true},
+		{name: "same provider with yes", opts: InitOptions{Source: "vercel", Target: "vercel", Domain: "example.com", Yes: true}},
+		{name: "missing flags", opts: InitOptions{Source: "vercel"}, wantErr: "missing required flags: --target, --domain"},
+		{name: "installed adapter", opts: InitOptions{Source: "vercel", Target: "fly", Domain: "example.com"}},
+		{name: "invalid source", opts: InitOptions{Source: "heroku", Target: "netlify", Domain: "example.com"}, wantErr: `invalid --source: unknown provider "heroku" (installed: fly, netlify, vercel)`},
+		{name: "invalid target", opts: InitOptions{Source: "vercel", Target: "heroku", Domain: "example.com"}, wantErr: "invalid --target"},
+		{name: "same provider", opts: InitOptions{Source: "vercel", Target: "vercel", Domain: "example.com"}, wantErr: "pass --yes"},
+		{name: "invalid domain", opts: InitOptions{Source: "vercel", Target: "netlify", Domain: "https://example.com"}, wantErr: "invalid --domain"},
+		{name: "json without flags", json: true, wantErr: "--json requires"},
+	}
+
+	// fly isn't built in, but its adapter is installed
+	adaptersPath := t.TempDir()
+	for _, provider := range []string{"vercel", "netlify", "fly"} {
+		installAdapter(t, adaptersPath, provider)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestState(t)
+			cmd := NewInitCommand(db, bridge.NewBridge(adaptersPath))
+			cmd.JSON = tt.json
+
+			var err error
+			out := captureStdout(t, func() { err = cmd.RunWithOptions(context.Background(), tt.opts) })
+			migrations, listErr := db.ListMigrations("", true)
+			if listErr != nil {
+				t.Fatal(listErr)
+			}
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("RunWithOptions(%+v) error = %v, want %q", tt.opts, err, tt.wantErr)
+				}
+				if len(migrations) != 0 {
+					t.Errorf("a rejected init created %d migrations", len(migrations))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RunWithOptions(%+v) error: %v", tt.opts, err)
+			}
+			if len(migrations) != 1 {
+				t.Fatalf("created %d migrations, want 1", len(migrations))
+			}
+			m := migrations[0]
+			if m.Domain != "example.com" {
+				t.Errorf("domain = %q, want it normalized to example.com", m.Domain)
+			}
+			if tt.json {
+				if !strings.Contains(out, `"id": "`+m.ID+`"`) {
+					t.Errorf("JSON output doesn't describe the migration:\n%s", out)
+				}
+			} else if strings.TrimSpace(out) != m.ID {
+				t.Errorf("output = %q, want only the migration ID %q", out, m.ID)
+			}
+		})
+	}
+}
@@ -0,0 +1,240 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/johnhorton/deploy-tunnel/internal/state"
+	"github.com/johnhorton/deploy-tunnel/ui"
+)
+
+func init() {
+	register(command{name: "logs", run: (*runner).logs, help: []helpLine{
+		{"logs", "Print a migration's logs"},
+	}})
+}
+
+func (r *runner) logs(ctx context.Context, args []string) error {
+	opts, err := ParseLogsFlags(args)
+	if err != nil {
+		return err
+	}
+	db, err := r.openState()
+	if err != nil {
+		return err
+	}
+	cmd := NewLogsCommand(db)
+	cmd.JSON = r.global.JSON
+	return cmd.Show(ctx, opts)
+}
+
+// logsPollInterval is how often --follow checks for new entries
+const logsPollInterval = 2 * time.Second
+
+// logLevels are the known levels, lowest first
+var logLevels = []string{"debug", "info", "warn", "error"}
+
+type LogsCommand struct {
+	state        *state.DB
+	pollInterval time.Duration
+
+	// JSON prints one JSON object per entry (NDJSON)
+	JSON bool
+}
+
+func NewLogsCommand(stateDB *state.DB) *LogsCommand {
+	return &LogsCommand{
+		state:        stateDB,
+		pollInterval: logsPollInterval,
+	}
+}
+
+// LogsOptions holds the arguments for `dt logs`
+type LogsOptions struct {
+	MigrationID string
+	Level       string
+	Since       time.Duration
+	Grep        string
+	Limit       int
+	Follow      bool
+}
+
+// ParseLogsFlags parses `dt logs [migrationID] [--level l] [--since d] [--grep text] [--limit n] [--follow]`
+func ParseLogsFlags(args []string) (LogsOptions, error) {
+	var opts LogsOptions
+
+	// flag stops at the first positional, so take a leading migration ID first
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		opts.MigrationID = args[0]
+		args = args[1:]
+	}
+
+	fs := flag.NewFlagSet("logs", flag.ContinueOnError)
+	fs.StringVar(&opts.Level, "level", "", "minimum level to show ("+strings.Join(logLevels, ", ")+")")
+	fs.DurationVar(&opts.Since, "since", 0, "only show entries newer than this, e.g. 30m or 2h")
+	fs.StringVar(&opts.Grep, "grep", "", "only show messages containing this text")
+	fs.IntVar(&opts.Limit, "limit", 100, "maximum number of entries to show")
+	fs.BoolVar(&opts.Follow, "follow", false, "keep printing new entries as they are written")
+
+	if err := fs.Parse(args); err != nil {
+		return opts, err
+	}
+	switch {
+	case fs.NArg() == 1 && opts.MigrationID == "":
+		opts.MigrationID = fs.Arg(0)
+	case fs.NArg() > 0:
+		return opts, fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+
+	if opts.Level != "" {
+		opts.Level = strings.ToLower(opts.Level)
+		if _, err := levelsAtLeast(opts.Level); err != nil {
+			return opts, err
+		}
+	}
+	if opts.Since < 0 {
+		return opts, fmt.Errorf("--since must be positive")
+	}
+	if opts.Limit <= 0 {
+		return opts, fmt.Errorf("--limit must be positive")
+	}
+	return opts, nil
+}
+
+// Query builds the state query for the options, with --since taken relative to now
+func (o LogsOptions) Query(now time.Time) state.LogQuery {
+	query := state.LogQuery{
+		Contains: o.Grep,
+		Limit:    o.Limit,
+	}
+	if o.Level != "" {
+		query.Levels, _ = levelsAtLeast(o.Level)
+	}
+	if o.Since > 0 {
+		query.Since = now.Add(-o.Since)
+	}
+	return query
+}
+
+// levelsAtLeast expands a minimum level into the levels to fetch
+func levelsAtLeast(level string) ([]string, error) {
+	for i, l := range logLevels {
+		if l != level {
+			continue
+		}
+
+		var levels []string
+		for _, l := range logLevels[i:] {
+			levels = append(levels, l)
+			if l == "warn" {
+				levels = append(levels, "warning")
+			}
+		}
+		return levels, nil
+	}
+	return nil, fmt.Errorf("invalid --level %q (valid: %s)", level, strings.Join(logLevels, ", "))
+}
+
+// Show prints a migration's logs, oldest first, and with --follow keeps
+// polling for new entries until ctx is cancelled
+func (c *LogsCommand) Show(ctx context.Context, opts LogsOptions) error {
+	migration, err := loadMigration(ctx, c.state, opts.MigrationID)
+	if err != nil {
+		return err
+	}
+	migrationID := migration.ID
+
+	query := opts.Query(time.Now())
+	entries, err := c.state.GetLogsFilteredContext(ctx, migrationID, query)
+	if err != nil {
+		return fmt.Errorf("failed to load logs: %w", err)
+	}
+
+	if len(entries) == 0 && !opts.Follow && !c.JSON {
+		fmt.Println(ui.Info("No matching log entries"))
+		return nil
+	}
+
+	lastID := 0
+	printAll := func(entries []state.LogEntry) error {
+		// Entries come newest first
+		for i := len(entries) - 1; i >= 0; i-- {
+			if err := c.printEntry(entries[i]); err != nil {
+				return err
+			}
+			if entries[i].ID > lastID {
+				lastID = entries[i].ID
+			}
+		}
+		return nil
+	}
+	if err := printAll(entries); err != nil {
+		return err
+	}
+	if !opts.Follow {
+		return nil
+	}
+
+	// --since only bounds the initial backlog; new entries are matched by ID
+	query.Since = time.Time{}
+	ticker := time.NewTicker(c.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		query.AfterID = lastID
+		entries, err := c.state.GetLogsFilteredContext(ctx, migrationID, query)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("failed to load logs: %w", err)
+		}
+		if err := printAll(entries); err != nil {
+			return err
+		}
+	}
+}
+
+func (c *LogsCommand) printEntry(e state.LogEntry) error {
+	if c.JSON {
+		data, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(os.Stdout, string(data))
+		return err
+	}
+
+	fmt.Printf("%s %s %s\n",
+		ui.InfoStyle.Render(e.Timestamp.Local().Format("Jan 2 15:04:05")),
+		logLevelStyle(e.Level).Render(fmt.Sprintf("%-5s", e.Level)),
+		e.Message,
+	)
+	return nil
+}
+
+// logLevelStyle colors a level for terminal output
+func logLevelStyle(level string) lipgloss.Style {
+	switch level {
+	case "error":
+		return ui.ErrorStyle
+	case "warn", "warning":
+		return ui.WarningStyle
+	case "info":
+		return ui.KeyStyle
+	default:
+		return ui.InfoStyle
+	}
+}
@@ -0,0 +1,162 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/johnhorton/deploy-tunnel/internal/state"
+)
+
+// newLogsState seeds m1 with one entry per level, and m2 with one entry
+func newLogsState(t *testing.T) *state.DB {
+	t.Helper()
+	db := newTestState(t)
+	for _, id := range []string{"m1", "m2"} {
+		if err := db.CreateMigration(id, "vercel", "netlify", "example.com"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m1, m2 := "m1", "m2"
+	for _, l := range []struct{ level, message string }{
+		{"debug", "cache hit"},
+		{"info", "deploy started"},
+		{"warning", "slow build"},
+		{"error", "deploy failed"},
+	} {
+		if err := db.Log(&m1, l.level, l.message, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.Log(&m2, "info", "other migration", ""); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// showLogs runs `dt logs --json` and returns the printed messages in order
+func showLogs(t *testing.T, cmd *LogsCommand, ctx context.Context, opts LogsOptions) []string {
+	t.Helper()
+	cmd.JSON = true
+	var err error
+	out := captureStdout(t, func() { err = cmd.Show(ctx, opts) })
+	if err != nil {
+		t.Fatalf("Show() error: %v", err)
+	}
+
+	var messages []string
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		if line == "" {
+			continue
+		}
+		var e state.LogEntry
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			t.Fatalf("invalid NDJSON line %q: %v", line, err)
+		}
+		messages = append(messages, e.Message)
+	}
+	return messages
+}
+
+func TestLogsFilters(t *testing.T) {
+	tests := []struct {
+		name string
+		opts LogsOptions
+		want []string
+	}{
+		{name: "all", opts: LogsOptions{MigrationID: "m1", Limit: 100}, want: []string{"cache hit", "deploy started", "slow build", "deploy failed"}},
+		{name: "level", opts: LogsOptions{MigrationID: "m1", Level: "warn", Limit: 100}, want: []string{"slow build", "deploy failed"}},
+		{name: "grep ignores case", opts: LogsOptions{MigrationID: "m1", Grep: "DEPLOY", Limit: 100}, want: []string{"deploy started", "deploy failed"}},
+		{name: "level and grep", opts: LogsOptions{MigrationID: "m1", Level: "error", Grep: "deploy", Limit: 100}, want: []string{"deploy failed"}},
+		{name: "limit keeps the newest", opts: LogsOptions{MigrationID: "m1", Limit: 2}, want: []string{"slow build", "deploy failed"}},
+		{name: "since", opts: LogsOptions{MigrationID: "m1", Since: time.Hour, Limit: 100}, want: []string{"cache hit", "deploy started", "slow build", "deploy failed"}},
+		{name: "other migration", opts: LogsOptions{MigrationID: "m2", Limit: 100}, want: []string{"other migration"}},
+		{name: "no match", opts: LogsOptions{MigrationID: "m1", Grep: "dns", Limit: 100}},
+	}
+
+	db := newLogsState(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := showLogs(t, NewLogsCommand(db), context.Background(), tt.opts)
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("shown = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogsFollow(t *testing.T) {
+	db := newLogsState(t)
+	cmd := NewLogsCommand(db)
+	cmd.pollInterval = time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		m1 := "m1"
+		time.Sleep(20 * time.Millisecond)
+		db.Log(&m1, "debug", "too quiet", "")
+		db.Log(&m1, "error", "dns failed", "")
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+
+	got := showLogs(t, cmd, ctx, LogsOptions{MigrationID: "m1", Level: "error", Limit: 100, Follow: true})
+	want := []string{"deploy failed", "dns failed"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("followed = %v, want %v", got, want)
+	}
+}
+
+func TestParseLogsFlags(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    LogsOptions
+		wantErr string
+	}{
+		{name: "defaults", want: LogsOptions{Limit: 100}},
+		{name: "leading id", args: []string{"m1", "--level", "WARN"}, want: LogsOptions{MigrationID: "m1", Level: "warn", Limit: 100}},
+		{name: "trailing id", args: []string{"--grep", "dns", "m1"}, want: LogsOptions{MigrationID: "m1", Grep: "dns", Limit: 100}},
+		{name: "since and follow", args: []string{"--since", "30m", "--follow"}, want: LogsOptions{Since: 30 * time.Minute, Follow: true, Limit: 100}},
+		{name: "two ids", args: []string{"m1", "m2"}, wantErr: "unexpected arguments: m2"},
+		{name: "unknown level", args: []string{"--level", "fatal"}, wantErr: "invalid --level"},
+		{name: "negative since", args: []string{"--since", "-1h"}, wantErr: "--since must be positive"},
+		{name: "zero limit", args: []string{"--limit", "0"}, wantErr: "--limit must be positive"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLogsFlags(tt.args)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("ParseLogsFlags(%v) error = %v, want %q", tt.args, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("ParseLogsFlags(%v) = %+v, %v, want %+v", tt.args, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogsQuery(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	query := LogsOptions{Level: "warn", Since: 2 * time.Hour, Grep: "dns", Limit: 10}.Query(now)
+
+	if got := strings.Join(query.Levels, ","); got != "warn,warning,error" {
+		t.Errorf("Levels = %s, want warn, its warning alias and error", got)
+	}
+	if !query.Since.Equal(now.Add(-2 * time.Hour)) {
+		t.Errorf("Since = %s, want two hours before now", query.Since)
+	}
+	if query.Contains != "dns" || query.Limit != 10 {
+		t.Errorf("query = %+v", query)
+	}
+	if q := (LogsOptions{Limit: 10}).Query(now); q.Levels != nil || !q.Since.IsZero() {
+		t.Errorf("unfiltered query = %+v, want no level or time bound", q)
+	}
+}
@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/johnhorton/deploy-tunnel/internal/state"
+	"github.com/johnhorton/deploy-tunnel/ui"
+)
+
+func init() {
+	register(command{name: "ls", run: (*runner).ls, help: []helpLine{
+		{"ls", "List migrations"},
+	}})
+}
+
+func (r *runner) ls(ctx context.Context, args []string) error {
+	opts, err := ParseLsFlags(args)
+	if err != nil {
+		return err
+	}
+	db, err := r.openState()
+	if err != nil {
+		return err
+	}
+	cmd := NewLsCommand(db)
+	cmd.JSON = r.global.JSON
+	return cmd.Run(ctx, opts)
+}
+
+type LsCommand struct {
+	state *state.DB
+
+	// JSON prints the migrations as JSON
+	JSON bool
+}
+
+func NewLsCommand(stateDB *state.DB) *LsCommand {
+	return &LsCommand{
+		state: stateDB,
+	}
+}
+
+// LsOptions holds the flags for `dt ls`
+type LsOptions struct {
+	Status string
+	Grep   string
+}
+
+// ParseLsFlags parses `dt ls [--status s] [--grep text]`
+func ParseLsFlags(args []string) (LsOptions, error) {
+	var opts LsOptions
+
+	fs := flag.NewFlagSet("ls", flag.ContinueOnError)
+	fs.StringVar(&opts.Status, "status", "", "only show migrations with this status, e.g. in_progress")
+	fs.StringVar(&opts.Grep, "grep", "", "only show migrations whose domain, source, or target contains this text")
+
+	if err := fs.Parse(args); err != nil {
+		return opts, err
+	}
+	if fs.NArg() > 0 {
+		return opts, fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+	opts.Status = strings.ToLower(strings.TrimSpace(opts.Status))
+	return opts, nil
+}
+
+// Run prints the unarchived migrations matching the filters, newest first
+func (c *LsCommand) Run(ctx context.Context, opts LsOptions) error {
+	migrations, err := c.find(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	if c.JSON {
+		if migrations == nil {
+			migrations = []state.Migration{}
+		}
+		return printJSON(migrations)
+	}
+
+	printHeader()
+	if len(migrations) == 0 {
+		fmt.Println(ui.Info("No matching migrations"))
+		return nil
+	}
+
+	rows := make([][]string, len(migrations))
+	for i, m := range migrations {
+		rows[i] = []string{
+			m.ID,
+			m.Domain,
+			m.Source + " → " + m.Target,
+			m.Status,
+			m.CreatedAt.Local().Format("2006-01-02 15:04"),
+		}
+	}
+	fmt.Println(ui.Table([]string{"MIGRATION", "DOMAIN", "ROUTE", "STATUS", "CREATED"}, rows))
+	return nil
+}
+
+// find runs the search, narrowed to opts.Status when set
+func (c *LsCommand) find(ctx context.Context, opts LsOptions) ([]state.Migration, error) {
+	if opts.Grep == "" {
+		migrations, err := c.state.ListMigrationsContext(ctx, opts.Status, false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list migrations: %w", err)
+		}
+		return migrations, nil
+	}
+
+	found, err := c.state.SearchMigrationsContext(ctx, opts.Grep)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Status == "" {
+		return found, nil
+	}
+	var migrations []state.Migration
+	for _, m := range found {
+		if m.Status == opts.Status {
+			migrations = append(migrations, m)
+		}
+	}
+	return migrations, nil
+}
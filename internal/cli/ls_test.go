@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/johnhorton/deploy-tunnel/internal/state"
+)
+
+func TestParseLsFlags(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want LsOptions
+		// wantErr is a substring of the expected error, "" when parsing succeeds
+		wantErr string
+	}{
+		{name: "no filters", args: nil},
+		{name: "both filters", args: []string{"--status", " In_Progress ", "--grep", "shop"}, want: LsOptions{Status: "in_progress", Grep: "shop"}},
+		{name: "extra argument", args: []string{"shop"}, wantErr: "unexpected arguments: shop"},
+		{name: "unknown flag", args: []string{"--domain", "shop"}, wantErr: "domain"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLsFlags(tt.args)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("ParseLsFlags(%v) error = %v, want %q", tt.args, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseLsFlags(%v) error: %v", tt.args, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseLsFlags(%v) = %+v, want %+v", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLs(t *testing.T) {
+	db := newTestState(t)
+	for _, m := range []struct{ id, source, target, domain string }{
+		{"m1", "vercel", "netlify", "shop.example.com"},
+		{"m2", "netlify", "cloudflare", "blog.example.com"},
+		{"m3", "vercel", "cloudflare", "shop.example.org"},
+	} {
+		if err := db.CreateMigration(m.id, m.source, m.target, m.domain); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.UpdateMigrationStatus("m3", "completed"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{name: "everything", args: []string{"ls"}, want: []string{"m3", "m2", "m1"}},
+		{name: "status", args: []string{"ls", "--status", "completed"}, want: []string{"m3"}},
+		{name: "grep", args: []string{"ls", "--grep", "SHOP"}, want: []string{"m3", "m1"}},
+		{name: "grep by provider", args: []string{"ls", "--grep", "netlify"}, want: []string{"m2", "m1"}},
+		{name: "grep and status", args: []string{"ls", "--grep", "shop", "--status", "pending"}, want: []string{"m1"}},
+		{name: "no match", args: []string{"ls", "--grep", "docs"}, want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &runner{stateDB: db}
+			var migrations []state.Migration
+			runJSON(t, r, &migrations, tt.args...)
+
+			got := []string{}
+			for _, m := range migrations {
+				got = append(got, m.ID)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("dt %s = %v, want %v", strings.Join(tt.args, " "), got, tt.want)
+			}
+		})
+	}
+
+	// Without --json, an empty result says so
+	var err error
+	out := captureStdout(t, func() { err = NewLsCommand(db).Run(context.Background(), LsOptions{Grep: "docs"}) })
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+	if !strings.Contains(out, "No matching migrations") {
+		t.Errorf("output doesn't report no matches:\n%s", out)
+	}
+}
@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/johnhorton/deploy-tunnel/internal/bridge"
+	"github.com/johnhorton/deploy-tunnel/internal/keychain"
+	"github.com/johnhorton/deploy-tunnel/internal/state"
+)
+
+// TestMain keeps credentials in a throwaway encrypted file instead of the
+// system keychain
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "dt-cli-test")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv(keychain.EnvBackend, keychain.BackendFile)
+	os.Setenv(keychain.EnvKey, "test")
+	keychain.SetDir(dir)
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// builtinProviders gives fake bridges a ParseProvider that accepts the
+// built-in providers, as if just their adapters were installed
+type builtinProviders struct{}
+
+func (builtinProviders) ParseProvider(name string) (bridge.Provider, error) {
+	for _, p := range bridge.AllProviders {
+		if string(p) == name {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("unknown provider %q", name)
+}
+
+// newTestState opens a fresh on-disk state database
+func newTestState(t *testing.T) *state.DB {
+	t.Helper()
+	db, err := state.OpenWithKey(t.TempDir(), make([]byte, 32))
+	if err != nil {
+		t.Fatalf("failed to open state: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// storeToken stores a credential for provider, removed when the test ends
+func storeToken(t *testing.T, provider string) {
+	t.Helper()
+	if err := keychain.Store(provider, "tok_"+provider+"_0123456789"); err != nil {
+		t.Fatalf("failed to store token: %v", err)
+	}
+	t.Cleanup(func() { keychain.Purge(provider) })
+}
+
+// withStdin feeds input to os.Stdin until the test ends
+func withStdin(t *testing.T, input string) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.WriteString(input); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+
+	stdin := os.Stdin
+	os.Stdin = r
+	t.Cleanup(func() {
+		os.Stdin = stdin
+		r.Close()
+	})
+}
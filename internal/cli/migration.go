@@ -0,0 +1,31 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/johnhorton/deploy-tunnel/internal/state"
+)
+
+// loadMigration resolves a migration ID flag, defaulting to the current migration
+func loadMigration(ctx context.Context, stateDB *state.DB, id string) (*state.Migration, error) {
+	if id == "" {
+		m, err := stateDB.CurrentMigration()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load current migration: %w", err)
+		}
+		if m == nil {
+			return nil, fmt.Errorf("no migration found (run: dt init)")
+		}
+		return m, nil
+	}
+
+	m, err := stateDB.GetMigrationContext(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load migration: %w", err)
+	}
+	if m == nil {
+		return nil, fmt.Errorf("migration not found: %s", id)
+	}
+	return m, nil
+}
@@ -0,0 +1,241 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/johnhorton/deploy-tunnel/internal/bridge"
+	"github.com/johnhorton/deploy-tunnel/internal/config"
+	"github.com/johnhorton/deploy-tunnel/internal/keychain"
+	"github.com/johnhorton/deploy-tunnel/internal/redact"
+	"github.com/johnhorton/deploy-tunnel/ui"
+)
+
+// GlobalOptions holds flags accepted by every command
+type GlobalOptions struct {
+	JSON bool
+	// Quiet drops the banner and progress output (--quiet, -q)
+	Quiet bool
+	// Color is auto, always, or never (--color)
+	Color string
+	// Image forces how the logo is drawn (--image); empty defers to
+	// DEPLOY_TUNNEL_IMAGE
+	Image string
+
+	// ConfigPath overrides the config file location (--config)
+	ConfigPath string
+	// Overrides holds config values given as flags, which beat env and file values
+	Overrides config.Config
+}
+
+// globalValueFlags are the global flags that take a value
+var globalValueFlags = []string{"config", "adapters-path", "timeout", "runtime", "color", "image"}
+
+// ParseGlobalFlags removes global flags from args wherever they appear and
+// returns the remaining arguments for the command's own parser
+func ParseGlobalFlags(args []string) (GlobalOptions, []string, error) {
+	var opts GlobalOptions
+	rest := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--json" || arg == "-json" {
+			opts.JSON = true
+			continue
+		}
+		if arg == "--quiet" || arg == "-quiet" || arg == "-q" {
+			opts.Quiet = true
+			continue
+		}
+
+		name, value, ok := globalValueFlag(arg)
+		if !ok {
+			rest = append(rest, arg)
+			continue
+		}
+		if value == nil {
+			if i+1 >= len(args) {
+				return opts, nil, fmt.Errorf("flag needs an argument: --%s", name)
+			}
+			i++
+			value = &args[i]
+		}
+		if err := opts.set(name, *value); err != nil {
+			return opts, nil, err
+		}
+	}
+	return opts, rest, nil
+}
+
+// globalValueFlag matches --name or --name=value for a global value flag;
+// value is nil when it's in the next argument
+func globalValueFlag(arg string) (name string, value *string, ok bool) {
+	trimmed := strings.TrimLeft(arg, "-")
+	if trimmed == arg || len(arg)-len(trimmed) > 2 {
+		return "", nil, false
+	}
+	name, v, hasValue := strings.Cut(trimmed, "=")
+	for _, f := range globalValueFlags {
+		if name == f {
+			if hasValue {
+				return name, &v, true
+			}
+			return name, nil, true
+		}
+	}
+	return "", nil, false
+}
+
+func (o *GlobalOptions) set(name, value string) error {
+	switch name {
+	case "config":
+		o.ConfigPath = value
+	case "adapters-path":
+		o.Overrides.AdaptersPath = value
+	case "runtime":
+		o.Overrides.Runtime = value
+	case "color":
+		mode, err := ui.ParseColorMode(value)
+		if err != nil {
+			return err
+		}
+		o.Color = mode
+	case "image":
+		mode, err := ui.ParseImageMode(value)
+		if err != nil {
+			return err
+		}
+		o.Image = mode
+	case "timeout":
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout <= 0 {
+			return fmt.Errorf("invalid --timeout %q: want a positive duration like 45s", value)
+		}
+		o.Overrides.Timeout = timeout
+	}
+	return nil
+}
+
+// LoadConfig resolves the config with flags over env over the config file
+func (o GlobalOptions) LoadConfig() (config.Config, error) {
+	cfg, err := config.Load(o.ConfigPath)
+	if err != nil {
+		return config.Config{}, err
+	}
+	return cfg.Merge(o.Overrides), nil
+}
+
+// ApplyOutput applies the output flags to the ui package; call it before
+// printing anything
+func (o GlobalOptions) ApplyOutput() {
+	ui.SetQuiet(o.Quiet)
+	ui.SetColorMode(o.Color)
+	if o.Color == ui.ColorAlways {
+		// Asking for color when piping means a pager or log viewer that
+		// renders it, so keep the borders too
+		ui.SetPlain(false)
+	}
+	ui.SetImageMode(o.Image)
+}
+
+// defaults supplies provider flag defaults; set it with UseConfig
+var defaults = config.Default()
+
+// UseConfig makes the config's default source and target the default values
+// of commands' provider flags, and keeps the credentials file in its state dir
+func UseConfig(cfg config.Config) {
+	defaults = cfg
+	keychain.SetDir(cfg.StateDir)
+}
+
+type jsonErrorBody struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type jsonError struct {
+	Error jsonErrorBody `json:"error"`
+}
+
+// WriteError reports a command failure, with credentials masked: as
+// {"error":{...}} on stdout in JSON mode so scripts can parse it, otherwise as
+// styled text on stderr. Exit with ExitCode(err) afterwards.
+func WriteError(err error, jsonMode bool) {
+	message := redact.String(err.Error())
+	if !jsonMode {
+		if runtime, ok := bridge.MissingRuntime(err); ok {
+			// Often the first thing a new user sees, so lead with what to do
+			fmt.Fprintln(os.Stderr, ui.Error(fmt.Sprintf("Bun is not installed: %s was not found, so adapters can't run", runtime)))
+			fmt.Fprintln(os.Stderr, ui.Info(bridge.RuntimeInstallHint))
+			return
+		}
+		fmt.Fprintln(os.Stderr, ui.Error(message))
+		return
+	}
+
+	var reported *reportedError
+	if errors.As(err, &reported) {
+		return
+	}
+
+	code := string(bridge.ErrUnknown)
+	var bridgeErr *bridge.BridgeError
+	if errors.As(err, &bridgeErr) {
+		code = string(bridgeErr.Code)
+	}
+	writeJSON(os.Stdout, jsonError{Error: jsonErrorBody{Code: code, Message: message}})
+}
+
+// reportedError is a failure the command already described in its JSON
+// output, so WriteError adds nothing in JSON mode; it still sets the exit code
+type reportedError struct {
+	err error
+}
+
+func (e *reportedError) Error() string { return e.err.Error() }
+func (e *reportedError) Unwrap() error { return e.err }
+
+// reported marks err as already described in the command's JSON output
+func reported(err error) error {
+	return &reportedError{err: err}
+}
+
+// printHeader prints the banner and a blank line, unless quiet
+func printHeader() {
+	if ui.Quiet() {
+		return
+	}
+	fmt.Println(ui.Header())
+	fmt.Println()
+}
+
+// printProgress prints a line about work under way, unless quiet
+func printProgress(message string) {
+	if ui.Quiet() {
+		return
+	}
+	fmt.Println(ui.Info(message))
+}
+
+// printGap prints the blank line after progress output, unless quiet
+func printGap() {
+	if ui.Quiet() {
+		return
+	}
+	fmt.Println()
+}
+
+// printJSON writes v to stdout as indented JSON
+func printJSON(v interface{}) error {
+	return writeJSON(os.Stdout, v)
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -0,0 +1,274 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/johnhorton/deploy-tunnel/internal/bridge"
+	"github.com/johnhorton/deploy-tunnel/internal/config"
+	"github.com/johnhorton/deploy-tunnel/internal/state"
+	"github.com/johnhorton/deploy-tunnel/ui"
+)
+
+// captureStdout returns what fn writes to stdout
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	return capture(t, &os.Stdout, fn)
+}
+
+// captureStderr returns what fn writes to stderr
+func captureStderr(t *testing.T, fn func()) string {
+	t.Helper()
+	return capture(t, &os.Stderr, fn)
+}
+
+// capture redirects *file to a pipe while fn runs and returns what it wrote
+func capture(t *testing.T, file **os.File, fn func()) string {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := *file
+	*file = w
+	defer func() { *file = saved }()
+
+	fn()
+	w.Close()
+	out, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(out)
+}
+
+func TestWriteErrorMissingRuntime(t *testing.T) {
+	br := fakeAdapter(t, "vercel", "")
+	br.SetRuntime("no-such-bun-runtime")
+	_, runErr := br.Capabilities(context.Background(), "vercel")
+
+	out := captureStderr(t, func() { WriteError(fmt.Errorf("auth: %w", runErr), false) })
+	for _, want := range []string{"Bun is not installed: no-such-bun-runtime was not found", bridge.RuntimeInstallHint} {
+		if !strings.Contains(out, want) {
+			t.Errorf("stderr is missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteErrorJSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		output string
+	}{
+		{name: "plain error", err: errors.New("boom"), output: `"message": "boom"`},
+		{name: "already reported", err: reported(errors.New("verification failed")), output: ""},
+		{name: "wrapped report", err: fmt.Errorf("verify: %w", reported(errors.New("verification failed"))), output: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := captureStdout(t, func() { WriteError(tt.err, true) })
+			if tt.output == "" && out != "" {
+				t.Errorf("WriteError printed %q for a reported error", out)
+			}
+			if !strings.Contains(out, tt.output) {
+				t.Errorf("WriteError printed %q, want it to contain %q", out, tt.output)
+			}
+			if code := ExitCode(tt.err); code == 0 {
+				t.Error("ExitCode = 0 for a failure")
+			}
+		})
+	}
+}
+
+// runJSON runs a command with --json and decodes its whole output into v,
+// which fails if anything but JSON (a banner, progress lines) was printed
+func runJSON(t *testing.T, r *runner, v interface{}, args ...string) {
+	t.Helper()
+	r.global.JSON = true
+	var err error
+	out := captureStdout(t, func() { err = r.command(context.Background(), args) })
+	if err != nil {
+		t.Fatalf("dt %s error: %v", strings.Join(args, " "), err)
+	}
+	if err := json.Unmarshal([]byte(out), v); err != nil {
+		t.Fatalf("dt %s printed invalid JSON (%v):\n%s", strings.Join(args, " "), err, out)
+	}
+}
+
+func TestJSONOutput(t *testing.T) {
+	storeToken(t, "vercel")
+	adaptersPath, runtime := fakeAdapterFiles(t, "netlify", "exit 1")
+	installAdapter(t, adaptersPath, "vercel")
+	r := &runner{stateDB: newTestState(t), br: bridge.NewBridge(adaptersPath)}
+	r.br.SetRuntime(runtime)
+
+	var statuses []providerStatus
+	runJSON(t, r, &statuses, "auth", "list")
+	if len(statuses) != len(bridge.AllProviders) {
+		t.Fatalf("auth list = %+v, want one entry per provider", statuses)
+	}
+	for _, s := range statuses {
+		if want := s.Provider == "vercel"; s.Authenticated != want {
+			t.Errorf("auth list: %s authenticated = %v, want %v", s.Provider, s.Authenticated, want)
+		}
+	}
+
+	var created state.Migration
+	runJSON(t, r, &created, "init", "--source", "vercel", "--target", "netlify", "--domain", "example.com")
+	if created.ID == "" || created.Source != "vercel" || created.Target != "netlify" || created.Domain != "example.com" {
+		t.Errorf("init = %+v, want the created migration", created)
+	}
+
+	var migrations []state.Migration
+	runJSON(t, r, &migrations, "ls")
+	if len(migrations) != 1 || migrations[0].ID != created.ID {
+		t.Errorf("ls = %+v, want the one created migration", migrations)
+	}
+}
+
+func TestWriteErrorJSONShape(t *testing.T) {
+	err := fmt.Errorf("fetch config: %w", &bridge.BridgeError{Code: bridge.ErrNotFound, Message: "project not found"})
+	out := captureStdout(t, func() { WriteError(err, true) })
+
+	var got jsonError
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("WriteError printed invalid JSON (%v):\n%s", err, out)
+	}
+	if got.Error.Code != string(bridge.ErrNotFound) || !strings.Contains(got.Error.Message, "project not found") {
+		t.Errorf("WriteError = %+v, want code %s and the message", got, bridge.ErrNotFound)
+	}
+}
+
+func TestGlobalFlagsBeatEnv(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(path, []byte(`{"timeout": "45s", "runtime": "/file/bun"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(config.EnvTimeout, "2m")
+	t.Setenv(config.EnvRuntime, "/env/bun")
+
+	global, rest, err := ParseGlobalFlags([]string{"ls", "--timeout=10s", "--config", path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(rest, " ") != "ls" {
+		t.Errorf("rest = %v, want only the command", rest)
+	}
+	cfg, err := global.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Timeout != 10*time.Second || cfg.Runtime != "/env/bun" {
+		t.Errorf("timeout = %s, runtime = %s, want the flag's 10s and the env's runtime", cfg.Timeout, cfg.Runtime)
+	}
+
+	if _, _, err := ParseGlobalFlags([]string{"--timeout", "soon"}); err == nil {
+		t.Error("ParseGlobalFlags accepted an invalid --timeout")
+	}
+}
+
+// setQuiet turns quiet mode on until the test ends
+func setQuiet(t *testing.T) {
+	t.Helper()
+	ui.SetQuiet(true)
+	t.Cleanup(func() { ui.SetQuiet(false) })
+}
+
+func TestQuietOutput(t *testing.T) {
+	for _, args := range [][]string{{"--quiet", "verify"}, {"verify", "-q"}} {
+		global, rest, err := ParseGlobalFlags(args)
+		if err != nil || !global.Quiet || strings.Join(rest, " ") != "verify" {
+			t.Errorf("ParseGlobalFlags(%v) = %+v, %v, %v; want quiet and only the command left", args, global, rest, err)
+		}
+	}
+
+	verify := func() string {
+		storeToken(t, "vercel")
+		storeToken(t, "netlify")
+		db := newTestState(t)
+		if err := db.CreateMigration("m1", "vercel", "netlify", "example.com"); err != nil {
+			t.Fatal(err)
+		}
+		cmd := NewVerifyCommand(db, configsByProvider{"vercel": sourceConfig(), "netlify": sourceConfig()})
+		var err error
+		out := captureStdout(t, func() { err = cmd.Run(context.Background(), VerifyOptions{}) })
+		if err != nil {
+			t.Fatalf("verify: %v", err)
+		}
+		return out
+	}
+
+	loud := verify()
+	if !strings.Contains(loud, "DEPLOY") || !strings.Contains(loud, "Comparing vercel and netlify") {
+		t.Fatalf("normal output lacks the header and progress:\n%s", loud)
+	}
+
+	setQuiet(t)
+	quiet := verify()
+	if strings.Contains(quiet, "DEPLOY") || strings.Contains(quiet, "Comparing") {
+		t.Errorf("quiet output has the header or progress:\n%s", quiet)
+	}
+	if !strings.Contains(quiet, "Source and target configs match") {
+		t.Errorf("quiet output dropped the result:\n%s", quiet)
+	}
+	if strings.HasPrefix(quiet, "\n") {
+		t.Errorf("quiet output starts with a blank line:\n%q", quiet)
+	}
+}
+
+func TestColorFlag(t *testing.T) {
+	tests := []struct {
+		args    []string
+		want    string
+		wantErr bool
+	}{
+		{args: []string{"ls"}, want: ""},
+		{args: []string{"ls", "--color", "never"}, want: ui.ColorNever},
+		{args: []string{"--color=always", "ls"}, want: ui.ColorAlways},
+		{args: []string{"ls", "--color=rainbow"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		global, rest, err := ParseGlobalFlags(tt.args)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseGlobalFlags(%v) error = %v, wantErr %v", tt.args, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && (global.Color != tt.want || strings.Join(rest, " ") != "ls") {
+			t.Errorf("ParseGlobalFlags(%v) = color %q, rest %v; want %q and [ls]", tt.args, global.Color, rest, tt.want)
+		}
+	}
+}
+
+func TestApplyOutputPlain(t *testing.T) {
+	profile := lipgloss.ColorProfile()
+	plain := ui.Plain()
+	t.Cleanup(func() {
+		lipgloss.SetColorProfile(profile)
+		ui.SetPlain(plain)
+	})
+
+	// Piped output starts plain; --color=always keeps the borders with the color
+	ui.SetPlain(true)
+	GlobalOptions{Color: ui.ColorNever}.ApplyOutput()
+	if !ui.Plain() {
+		t.Error("--color=never turned plain mode off")
+	}
+	GlobalOptions{Color: ui.ColorAlways}.ApplyOutput()
+	if ui.Plain() {
+		t.Error("--color=always left plain mode on")
+	}
+}
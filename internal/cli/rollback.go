@@ -0,0 +1,278 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/johnhorton/deploy-tunnel/internal/bridge"
+	"github.com/johnhorton/deploy-tunnel/internal/state"
+	"github.com/johnhorton/deploy-tunnel/ui"
+)
+
+func init() {
+	register(command{name: "rollback", run: (*runner).rollback, help: []helpLine{
+		{"rollback", "Restore every record changed by cutover"},
+	}})
+}
+
+func (r *runner) rollback(ctx context.Context, args []string) error {
+	opts, err := ParseRollbackFlags(args)
+	if err != nil {
+		return err
+	}
+	db, br, err := r.open("dt rollback")
+	if err != nil {
+		return err
+	}
+	cmd := NewRollbackCommand(db, br)
+	cmd.JSON = r.global.JSON
+	return cmd.Run(ctx, opts)
+}
+
+// Migration statuses set by rollback
+const (
+	StatusRolledBack = "rolled_back"
+	StatusFailed     = "failed"
+)
+
+// dnsRollbacker is the bridge calls RollbackCommand needs
+type dnsRollbacker interface {
+	DnsRollback(ctx context.Context, params bridge.DnsRollbackParams) (*bridge.DnsRollbackData, error)
+	ParseProvider(name string) (bridge.Provider, error)
+}
+
+type RollbackCommand struct {
+	state  *state.DB
+	bridge dnsRollbacker
+	// confirm asks before anything is changed; replaceable for scripting
+	confirm func(message string) (bool, error)
+
+	// JSON prints the rollback result as JSON
+	JSON bool
+}
+
+func NewRollbackCommand(stateDB *state.DB, br dnsRollbacker) *RollbackCommand {
+	return &RollbackCommand{
+		state:   stateDB,
+		bridge:  br,
+		confirm: confirm,
+	}
+}
+
+// RollbackOptions are the flags for `dt rollback`
+type RollbackOptions struct {
+	MigrationID string
+	// Provider overrides the migration's target as the DNS provider
+	Provider string
+	Yes      bool
+}
+
+// ParseRollbackFlags parses `dt rollback [migration-id] [--provider <p>] [--yes]`
+func ParseRollbackFlags(args []string) (RollbackOptions, error) {
+	var opts RollbackOptions
+
+	// flag stops at the first positional, so take a leading migration ID first
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		opts.MigrationID = args[0]
+		args = args[1:]
+	}
+
+	fs := flag.NewFlagSet("rollback", flag.ContinueOnError)
+	fs.StringVar(&opts.Provider, "provider", "", "DNS provider (defaults to the migration's target)")
+	fs.BoolVar(&opts.Yes, "yes", false, "skip the confirmation prompt")
+
+	if err := fs.Parse(args); err != nil {
+		return opts, err
+	}
+	switch {
+	case fs.NArg() == 1 && opts.MigrationID == "":
+		opts.MigrationID = fs.Arg(0)
+	case fs.NArg() > 0:
+		return opts, fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+	return opts, nil
+}
+
+// RecordRollback is the outcome for one DNS record
+type RecordRollback struct {
+	RecordID     string `json:"record_id"`
+	Type         string `json:"type"`
+	Name         string `json:"name"`
+	RollbackTo   string `json:"rollback_to"`
+	Restored     bool   `json:"restored"`
+	CurrentValue string `json:"current_value,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
+
+// rollbackResult is the JSON form of `dt rollback`
+type rollbackResult struct {
+	MigrationID string           `json:"migration_id"`
+	Status      string           `json:"status"`
+	Records     []RecordRollback `json:"records"`
+}
+
+// pendingRollbacks returns the migration's DNS changes that can be undone and
+// haven't been yet, newest first
+func pendingRollbacks(records []state.DnsRecord) []state.DnsRecord {
+	undone := make(map[string]bool)
+	for _, r := range records {
+		if r.RollbackID != nil {
+			undone[*r.RollbackID] = true
+		}
+	}
+
+	var pending []state.DnsRecord
+	for i := len(records) - 1; i >= 0; i-- {
+		r := records[i]
+		if r.RollbackID != nil || r.PreviousValue == nil || undone[r.ID] {
+			continue
+		}
+		pending = append(pending, r)
+	}
+	return pending
+}
+
+// Run restores every DNS record the migration changed to its previous value,
+// newest first. A failed rollback doesn't stop the rest; the migration ends up
+// rolled_back if all were restored, otherwise failed.
+func (c *RollbackCommand) Run(ctx context.Context, opts RollbackOptions) error {
+	if c.JSON && !opts.Yes {
+		return fmt.Errorf("--json cannot prompt for confirmation; pass --yes")
+	}
+
+	migration, err := loadMigration(ctx, c.state, opts.MigrationID)
+	if err != nil {
+		return err
+	}
+
+	records, err := c.state.GetDnsRecordsContext(ctx, migration.ID)
+	if err != nil {
+		return fmt.Errorf("failed to load DNS records: %w", err)
+	}
+	pending := pendingRollbacks(records)
+	if len(pending) == 0 {
+		return fmt.Errorf("migration %s has no DNS changes to roll back", migration.ID)
+	}
+
+	providerName := opts.Provider
+	if providerName == "" {
+		providerName = migration.Target
+	}
+	provider, err := c.bridge.ParseProvider(providerName)
+	if err != nil {
+		return err
+	}
+	token, err := loadToken(provider)
+	if err != nil {
+		return err
+	}
+
+	if !c.JSON {
+		printHeader()
+		rows := make([][]string, len(pending))
+		for i, r := range pending {
+			rows[i] = []string{r.RecordType, r.RecordName, r.RecordValue, *r.PreviousValue}
+		}
+		fmt.Println(ui.Table([]string{"TYPE", "NAME", "CURRENT", "RESTORE TO"}, rows))
+
+		if !opts.Yes {
+			ok, err := c.confirm(fmt.Sprintf("Roll back %d DNS record(s) for migration %s on %s?", len(pending), migration.ID, provider))
+			if err != nil {
+				return fmt.Errorf("failed to read confirmation: %w", err)
+			}
+			if !ok {
+				fmt.Println(ui.Info("Rollback cancelled, DNS left unchanged"))
+				fmt.Println()
+				return nil
+			}
+		}
+	}
+
+	result := rollbackResult{MigrationID: migration.ID, Status: StatusRolledBack}
+	failed := 0
+	for _, record := range pending {
+		outcome := c.rollbackRecord(ctx, migration.ID, provider, token, record)
+		if !outcome.Restored {
+			failed++
+			result.Status = StatusFailed
+		}
+		result.Records = append(result.Records, outcome)
+	}
+
+	if err := c.state.UpdateMigrationStatus(migration.ID, result.Status); err != nil {
+		return fmt.Errorf("failed to update migration status: %w", err)
+	}
+	c.state.LogJSON(&migration.ID, "info", fmt.Sprintf("rollback restored %d of %d DNS record(s)", len(pending)-failed, len(pending)), map[string]interface{}{"status": result.Status})
+
+	if c.JSON {
+		if err := printJSON(result); err != nil {
+			return err
+		}
+	} else {
+		for _, r := range result.Records {
+			if r.Restored {
+				fmt.Println(ui.Success(fmt.Sprintf("Restored %s %s to %s", r.Type, r.Name, r.CurrentValue)))
+			} else {
+				fmt.Println(ui.Error(fmt.Sprintf("Failed to restore %s %s: %s", r.Type, r.Name, r.Error)))
+			}
+		}
+		fmt.Println()
+	}
+
+	if failed > 0 {
+		err := fmt.Errorf("%d of %d DNS record(s) could not be rolled back", failed, len(pending))
+		if c.JSON {
+			return reported(err)
+		}
+		return err
+	}
+	return nil
+}
+
+// rollbackRecord restores one record and records the rollback
+func (c *RollbackCommand) rollbackRecord(ctx context.Context, migrationID string, provider bridge.Provider, token string, record state.DnsRecord) RecordRollback {
+	outcome := RecordRollback{
+		RecordID:   record.ID,
+		Type:       record.RecordType,
+		Name:       record.RecordName,
+		RollbackTo: *record.PreviousValue,
+	}
+
+	restored, err := c.bridge.DnsRollback(ctx, bridge.DnsRollbackParams{
+		Provider:   provider,
+		Token:      token,
+		RecordID:   record.ProviderRecordID,
+		RollbackTo: *record.PreviousValue,
+	})
+	if err == nil && !restored.Restored {
+		err = errors.New("provider did not restore the record")
+	}
+	if err != nil {
+		outcome.Error = err.Error()
+		c.state.LogJSON(&migrationID, "error", fmt.Sprintf("failed to roll back %s %s: %s", record.RecordType, record.RecordName, err), map[string]interface{}{"record_id": record.ID})
+		return outcome
+	}
+
+	outcome.Restored = true
+	outcome.CurrentValue = restored.CurrentValue
+	c.state.LogJSON(&migrationID, "info", fmt.Sprintf("rolled back %s %s to %s", record.RecordType, record.RecordName, restored.CurrentValue), map[string]interface{}{"record_id": record.ID})
+
+	rollback := state.DnsRecord{
+		ProviderRecordID: record.ProviderRecordID,
+		MigrationID:      record.MigrationID,
+		Domain:           record.Domain,
+		RecordType:       record.RecordType,
+		RecordName:       record.RecordName,
+		RecordValue:      restored.CurrentValue,
+		TTL:              record.TTL,
+		RollbackID:       &record.ID,
+		PreviousValue:    &record.RecordValue,
+	}
+	if err := c.state.SaveDnsRecord(&rollback); err != nil {
+		c.state.LogJSON(&migrationID, "error", fmt.Sprintf("rolled back %s but could not record it: %s", record.ID, err), nil)
+	}
+	return outcome
+}
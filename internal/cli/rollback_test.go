@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/johnhorton/deploy-tunnel/internal/bridge"
+	"github.com/johnhorton/deploy-tunnel/internal/state"
+)
+
+// fakeRollbacker fails to restore the provider records in fail
+type fakeRollbacker struct {
+	builtinProviders
+	fail map[string]bool
+}
+
+func (f fakeRollbacker) DnsRollback(ctx context.Context, params bridge.DnsRollbackParams) (*bridge.DnsRollbackData, error) {
+	if f.fail[params.RecordID] {
+		return nil, &bridge.BridgeError{Code: bridge.ErrProviderError, Message: "record locked"}
+	}
+	return &bridge.DnsRollbackData{Restored: true, CurrentValue: params.RollbackTo}, nil
+}
+
+func TestRollbackJSON(t *testing.T) {
+	tests := []struct {
+		name      string
+		fail      map[string]bool
+		status    string
+		wantError bool
+	}{
+		{name: "all restored", status: StatusRolledBack},
+		{name: "partial failure", fail: map[string]bool{"prov-www": true}, status: StatusFailed, wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestState(t)
+			storeToken(t, "vercel")
+			if err := db.CreateMigration("m1", "netlify", "vercel", "example.com"); err != nil {
+				t.Fatal(err)
+			}
+			for _, name := range []string{"@", "www"} {
+				migrationID, previous := "m1", "old-"+name
+				record := state.DnsRecord{
+					ProviderRecordID: "prov-" + name,
+					MigrationID:      &migrationID,
+					Domain:           "example.com",
+					RecordType:       "CNAME",
+					RecordName:       name,
+					RecordValue:      "app.example.dev",
+					TTL:              300,
+					PreviousValue:    &previous,
+				}
+				if err := db.SaveDnsRecord(&record); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			cmd := NewRollbackCommand(db, fakeRollbacker{fail: tt.fail})
+			cmd.JSON = true
+			var err error
+			out := captureStdout(t, func() { err = cmd.Run(context.Background(), RollbackOptions{MigrationID: "m1", Yes: true}) })
+
+			var result rollbackResult
+			if jsonErr := json.Unmarshal([]byte(out), &result); jsonErr != nil {
+				t.Fatalf("output is not JSON: %v\n%s", jsonErr, out)
+			}
+			if result.Status != tt.status {
+				t.Errorf("status = %q, want %q", result.Status, tt.status)
+			}
+			if (err != nil) != tt.wantError {
+				t.Fatalf("Run error = %v, wantError %v", err, tt.wantError)
+			}
+			var reportedErr *reportedError
+			if err != nil && (!errors.As(err, &reportedErr) || ExitCode(err) == 0) {
+				t.Errorf("partial failure error %v should be reported and exit nonzero", err)
+			}
+		})
+	}
+}
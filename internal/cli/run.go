@@ -0,0 +1,238 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/johnhorton/deploy-tunnel/internal/bridge"
+	"github.com/johnhorton/deploy-tunnel/internal/config"
+	"github.com/johnhorton/deploy-tunnel/internal/state"
+	"github.com/johnhorton/deploy-tunnel/internal/tui"
+)
+
+// usageHeader and usageFooter frame the command list in `dt help`
+const usageHeader = `Usage: dt [global flags] <command> [flags]
+
+Run dt with no command to open the dashboard.
+
+Commands:
+`
+
+const usageFooter = `
+Global flags:
+  --json, --quiet (-q), --config, --adapters-path, --runtime, --timeout,
+  --color, --image
+`
+
+// command is a top-level dt command. Each command registers itself, with
+// its lines in `dt help`, from an init function in its own file.
+type command struct {
+	name string
+	help []helpLine
+	// run parses the command's flags and runs it
+	run func(r *runner, ctx context.Context, args []string) error
+}
+
+// helpLine is one entry in the command list of `dt help`
+type helpLine struct {
+	usage   string
+	summary string
+}
+
+var commands = map[string]command{}
+
+func register(c command) {
+	commands[c.name] = c
+}
+
+// writeUsage prints `dt help`, listing the registered commands by name
+func writeUsage(w io.Writer) {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprint(w, usageHeader)
+	for _, name := range names {
+		for _, line := range commands[name].help {
+			fmt.Fprintf(w, "  %-20s %s\n", line.usage, line.summary)
+		}
+	}
+	fmt.Fprint(w, usageFooter)
+}
+
+// Main runs dt with args, the command line without the program name, and
+// returns the process exit code. The first SIGINT or SIGTERM cancels the
+// command; the state DB is closed through Shutdown either way.
+func Main(args []string) int {
+	global, rest, err := ParseGlobalFlags(args)
+	if err != nil {
+		WriteError(err, global.JSON)
+		return ExitCode(err)
+	}
+	global.ApplyOutput()
+
+	ctx, stop := HandleSignals(context.Background())
+	defer stop()
+
+	r := &runner{global: global}
+	err = r.run(ctx, rest)
+	if r.stateDB != nil {
+		if closeErr := Shutdown(ctx, r.stateDB); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		WriteError(err, global.JSON)
+	}
+	if err != nil && ctx.Err() != nil {
+		return interruptExitCode
+	}
+	return ExitCode(err)
+}
+
+// runner opens the state DB and bridge on first use, so commands that need
+// neither work without adapters installed
+type runner struct {
+	global  GlobalOptions
+	cfg     config.Config
+	stateDB *state.DB
+	br      *bridge.Bridge
+}
+
+func (r *runner) openState() (*state.DB, error) {
+	if r.stateDB == nil {
+		db, err := r.cfg.OpenState()
+		if err != nil {
+			return nil, err
+		}
+		r.stateDB = db
+	}
+	return r.stateDB, nil
+}
+
+func (r *runner) newBridge() (*bridge.Bridge, error) {
+	if r.br == nil {
+		br, err := r.cfg.NewBridge()
+		if err != nil {
+			return nil, err
+		}
+		r.br = br
+	}
+	return r.br, nil
+}
+
+// openWritable opens the state DB for a command that changes state, which
+// refuses to run on the in-memory fallback: what it did (a migration, a DNS
+// record's old value) would be lost when the process exits
+func (r *runner) openWritable(command string) (*state.DB, error) {
+	db, err := r.openState()
+	if err != nil {
+		return nil, err
+	}
+	if db.InMemory() {
+		return nil, fmt.Errorf("%s changes saved state, which can't be written here: %w", command, db.Fallback())
+	}
+	return db, nil
+}
+
+// open returns the state DB and bridge, for commands that need both; those
+// that change state name themselves in command so the in-memory fallback is
+// refused
+func (r *runner) open(command string) (*state.DB, *bridge.Bridge, error) {
+	br, err := r.newBridge()
+	if err != nil {
+		return nil, nil, err
+	}
+	var db *state.DB
+	if command == "" {
+		db, err = r.openState()
+	} else {
+		db, err = r.openWritable(command)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return db, br, nil
+}
+
+// lister returns the bridge, or one whose ListAdapters reports why it
+// couldn't be created, so version and doctor can still describe the problem
+func (r *runner) lister() adapterLister {
+	br, err := r.newBridge()
+	if err != nil {
+		return failedLister{err: err}
+	}
+	return br
+}
+
+type failedLister struct {
+	err error
+}
+
+func (l failedLister) ListAdapters(ctx context.Context) ([]bridge.ProviderCapabilities, error) {
+	return nil, l.err
+}
+
+func (r *runner) run(ctx context.Context, args []string) error {
+	if len(args) > 0 && (args[0] == "help" || args[0] == "-h" || args[0] == "--help") {
+		writeUsage(os.Stdout)
+		return nil
+	}
+
+	cfg, err := r.global.LoadConfig()
+	if err != nil {
+		return err
+	}
+	UseConfig(cfg)
+	if err := cfg.ApplyTheme(); err != nil {
+		return err
+	}
+	r.cfg = cfg
+	return r.command(ctx, args)
+}
+
+// command runs the command named by args' first word, or the dashboard
+// when there is none
+func (r *runner) command(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		if r.global.JSON {
+			return fmt.Errorf("--json needs a command (see: dt help)")
+		}
+		return r.dashboard(ctx)
+	}
+
+	name, args := args[0], args[1:]
+	if c, ok := commands[name]; ok {
+		return c.run(r, ctx, args)
+	}
+	return fmt.Errorf("unknown command %q (see: dt help)", name)
+}
+
+// subcommand splits off args' subcommand, which must be one of names
+func subcommand(command string, args []string, names ...string) (string, []string, error) {
+	if len(args) > 0 {
+		for _, name := range names {
+			if args[0] == name {
+				return name, args[1:], nil
+			}
+		}
+		return "", nil, fmt.Errorf("unknown command \"%s %s\" (see: dt help)", command, args[0])
+	}
+	return "", nil, fmt.Errorf("missing subcommand (usage: dt %s %s)", command, strings.Join(names, "|"))
+}
+
+func (r *runner) dashboard(ctx context.Context) error {
+	db, br, err := r.open("the dashboard")
+	if err != nil {
+		return err
+	}
+	s := tui.NewSession(ctx, db, br)
+	defer s.Cancel()
+	return tui.RunDashboardTUI(s)
+}
@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/johnhorton/deploy-tunnel/internal/state"
+)
+
+func TestInMemoryStateRefusesChanges(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		// refused is whether the command must not run on in-memory state
+		refused bool
+	}{
+		{name: "init", args: []string{"init", "--source", "vercel", "--target", "netlify", "--domain", "example.com"}, refused: true},
+		{name: "cutover", args: []string{"cutover", "--dry-run"}, refused: true},
+		{name: "dns update", args: []string{"dns", "update", "--provider", "netlify", "--domain", "example.com", "--type", "A", "--name", "@", "--value", "203.0.113.10"}, refused: true},
+		{name: "rollback", args: []string{"rollback", "--yes"}, refused: true},
+		{name: "config menu change", args: []string{"config", "menu", "--reset"}, refused: true},
+		{name: "config menu show", args: []string{"config", "menu"}},
+		{name: "ls", args: []string{"ls"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mem, err := state.OpenInMemory()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer mem.Close()
+
+			r := &runner{
+				global:  GlobalOptions{JSON: true},
+				stateDB: mem,
+				br:      fakeAdapter(t, "netlify", "exit 1"),
+			}
+			var runErr error
+			captureStdout(t, func() { runErr = r.command(context.Background(), tt.args) })
+
+			refused := runErr != nil && strings.Contains(runErr.Error(), "changes saved state")
+			if refused != tt.refused {
+				t.Errorf("%s: error = %v, want refused = %v", strings.Join(tt.args, " "), runErr, tt.refused)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/johnhorton/deploy-tunnel/internal/events"
+	"github.com/johnhorton/deploy-tunnel/internal/state"
+	"github.com/johnhorton/deploy-tunnel/ui"
+)
+
+// interruptExitCode is what a forced exit returns, as a shell does for SIGINT
+const interruptExitCode = 130
+
+// eventFlushTimeout bounds how long Shutdown waits for queued webhook events
+const eventFlushTimeout = 5 * time.Second
+
+// forceExit ends the process on a second signal
+var forceExit = os.Exit
+
+// HandleSignals returns a context canceled by the first SIGINT or SIGTERM.
+// Adapters run under exec.CommandContext, so canceling it kills any bun
+// process still running and lets the command unwind and close the state DB.
+// A second signal exits at once. Call stop when the command returns.
+func HandleSignals(parent context.Context) (ctx context.Context, stop func()) {
+	ctx, cancel := context.WithCancel(parent)
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	done := make(chan struct{})
+
+	go func() {
+		select {
+		case <-signals:
+		case <-done:
+			return
+		}
+		fmt.Fprintln(os.Stderr, ui.Warning("Interrupted, stopping adapters and saving state (press ctrl+c again to force quit)"))
+		cancel()
+
+		select {
+		case <-signals:
+			forceExit(interruptExitCode)
+		case <-done:
+		}
+	}()
+
+	return ctx, func() {
+		signal.Stop(signals)
+		close(done)
+		cancel()
+	}
+}
+
+// Shutdown closes the state DB once a command has returned. If ctx was
+// canceled by HandleSignals, migrations the command left in progress are
+// marked failed first so they can be resumed. Queued migration events get
+// a few seconds to be delivered.
+func Shutdown(ctx context.Context, stateDB *state.DB) error {
+	var markErr error
+	if ctx.Err() != nil {
+		_, markErr = stateDB.MarkInterrupted("command was canceled")
+	}
+	if !events.Flush(eventFlushTimeout) {
+		fmt.Fprintln(os.Stderr, ui.Warning("Some migration events were not delivered to the webhook"))
+	}
+	if err := stateDB.Close(); err != nil {
+		return fmt.Errorf("failed to close state database: %w", err)
+	}
+	return markErr
+}
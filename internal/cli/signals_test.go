@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/johnhorton/deploy-tunnel/internal/bridge"
+	"github.com/johnhorton/deploy-tunnel/internal/state"
+)
+
+// fakeAdapter installs provider as an adapter run by a stand-in runtime
+// script, called as: runtime run <adapter path> <verb>
+func fakeAdapter(t *testing.T, provider, script string) *bridge.Bridge {
+	t.Helper()
+	adaptersPath, runtime := fakeAdapterFiles(t, provider, script)
+	br := bridge.NewBridge(adaptersPath)
+	br.SetRuntime(runtime)
+	return br
+}
+
+// fakeAdapterFiles writes fakeAdapter's adapters dir and runtime script
+func fakeAdapterFiles(t *testing.T, provider, script string) (adaptersPath, runtime string) {
+	t.Helper()
+	adaptersPath = t.TempDir()
+	installAdapter(t, adaptersPath, provider)
+
+	runtime = filepath.Join(t.TempDir(), "runtime")
+	if err := os.WriteFile(runtime, []byte("#!/bin/sh\n"+script+"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return adaptersPath, runtime
+}
+
+// installAdapter writes an empty <provider>/index.ts under adaptersPath
+func installAdapter(t *testing.T, adaptersPath, provider string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Join(adaptersPath, provider), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(adaptersPath, provider, "index.ts"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestInterruptStopsAdapterAndClosesState(t *testing.T) {
+	// exec replaces the shell, so killing the runtime kills the sleep
+	br := fakeAdapter(t, "slow", "exec sleep 60")
+
+	dir := t.TempDir()
+	key := make([]byte, 32)
+	db, err := state.OpenWithKey(dir, key)
+	if err != nil {
+		t.Fatalf("failed to open state: %v", err)
+	}
+	if err := db.CreateMigration("m1", "vercel", "netlify", "example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.UpdateMigrationStatus("m1", "in_progress"); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, stop := HandleSignals(context.Background())
+	defer stop()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := br.Capabilities(ctx, "slow")
+		done <- err
+	}()
+
+	// Let the adapter start before interrupting it
+	time.Sleep(200 * time.Millisecond)
+	if err := syscall.Kill(os.Getpid(), syscall.SIGINT); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Fatal("adapter call succeeded after interrupt, want an error")
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("adapter still running 10s after interrupt")
+	}
+
+	if err := Shutdown(ctx, db); err != nil {
+		t.Fatalf("Shutdown() = %v, want nil", err)
+	}
+
+	reopened, err := state.OpenWithKey(dir, key)
+	if err != nil {
+		t.Fatalf("failed to reopen state: %v", err)
+	}
+	defer reopened.Close()
+	migration, err := reopened.GetMigration("m1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if migration.Status != "failed" {
+		t.Errorf("migration status = %q after interrupt, want failed", migration.Status)
+	}
+}
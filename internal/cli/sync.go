@@ -0,0 +1,306 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/johnhorton/deploy-tunnel/internal/bridge"
+	"github.com/johnhorton/deploy-tunnel/internal/state"
+	"github.com/johnhorton/deploy-tunnel/ui"
+)
+
+func init() {
+	register(command{name: "sync", run: (*runner).sync, help: []helpLine{
+		{"sync env", "Copy env vars to a provider"},
+	}})
+}
+
+func (r *runner) sync(ctx context.Context, args []string) error {
+	_, args, err := subcommand("sync", args, "env")
+	if err != nil {
+		return err
+	}
+	opts, err := ParseSyncEnvFlags(args)
+	if err != nil {
+		return err
+	}
+	db, br, err := r.open("dt sync env")
+	if err != nil {
+		return err
+	}
+	cmd := NewSyncCommand(db, br)
+	cmd.JSON = r.global.JSON
+	return cmd.Env(ctx, opts)
+}
+
+// envSyncer is the bridge calls SyncCommand needs
+type envSyncer interface {
+	SyncEnvBatched(ctx context.Context, params bridge.SyncEnvParams, batchSize int, onBatch func(bridge.SyncProgress)) (*bridge.SyncEnvData, error)
+	ParseProvider(name string) (bridge.Provider, error)
+}
+
+type SyncCommand struct {
+	state  *state.DB
+	bridge envSyncer
+
+	// JSON prints the sync result as JSON
+	JSON bool
+}
+
+func NewSyncCommand(stateDB *state.DB, br envSyncer) *SyncCommand {
+	return &SyncCommand{
+		state:  stateDB,
+		bridge: br,
+	}
+}
+
+// SyncEnvOptions holds the flags for `dt sync env`
+type SyncEnvOptions struct {
+	Provider    string
+	Project     string
+	MigrationID string
+	DryRun      bool
+	// Force re-sends variables already synced to the target
+	Force bool
+	// Environment limits the sync to one environment; empty syncs each
+	// variable to the environments the source sets it for
+	Environment string
+}
+
+// ParseSyncEnvFlags parses `dt sync env --provider p [--project id] [--from id] [--env name] [--force] [--dry-run]`
+func ParseSyncEnvFlags(args []string) (SyncEnvOptions, error) {
+	var opts SyncEnvOptions
+
+	fs := flag.NewFlagSet("sync env", flag.ContinueOnError)
+	fs.StringVar(&opts.Provider, "provider", defaults.DefaultTarget, "target provider to sync to")
+	fs.StringVar(&opts.Project, "project", "", "target project ID")
+	fs.StringVar(&opts.MigrationID, "from", "", "migration to read env vars from (defaults to the current one)")
+	fs.BoolVar(&opts.DryRun, "dry-run", false, "list what would sync without calling the provider")
+	fs.BoolVar(&opts.Force, "force", false, "re-send variables already synced to the target")
+	fs.StringVar(&opts.Environment, "env", "", "only sync to this environment (production, preview, or development)")
+
+	if err := fs.Parse(args); err != nil {
+		return opts, err
+	}
+	if fs.NArg() > 0 {
+		return opts, fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+	if opts.Provider == "" {
+		return opts, fmt.Errorf("missing required flag: --provider")
+	}
+	if opts.Environment != "" {
+		env, err := bridge.ParseEnvironment(opts.Environment)
+		if err != nil {
+			return opts, fmt.Errorf("invalid --env: %w", err)
+		}
+		opts.Environment = env
+	}
+	return opts, nil
+}
+
+// syncEnvResult is the JSON form of `dt sync env`
+type syncEnvResult struct {
+	MigrationID string   `json:"migration_id"`
+	DryRun      bool     `json:"dry_run"`
+	Keys        []string `json:"keys"`
+	Skipped     []string `json:"skipped"`
+	Synced      int      `json:"synced"`
+	Failed      []string `json:"failed"`
+}
+
+// Env pushes a migration's included env vars to the target provider. Vars
+// already synced there with the same value and environments are skipped
+// unless opts.Force is set, so a re-run after a partial failure only sends
+// what's left.
+func (c *SyncCommand) Env(ctx context.Context, opts SyncEnvOptions) error {
+	provider, err := c.bridge.ParseProvider(opts.Provider)
+	if err != nil {
+		return err
+	}
+
+	migration, err := loadMigration(ctx, c.state, opts.MigrationID)
+	if err != nil {
+		return err
+	}
+
+	stored, err := c.state.GetEnvVarsContext(ctx, migration.ID)
+	if err != nil {
+		return fmt.Errorf("failed to load env vars: %w", err)
+	}
+
+	synced, err := c.state.SyncedEnvContext(ctx, migration.ID, string(provider))
+	if err != nil {
+		return err
+	}
+	if opts.Force {
+		synced = map[string]string{}
+	}
+
+	var envVars []bridge.EnvVar
+	var keys, skipped []string
+	for _, e := range stored {
+		environments := bridge.SyncEnvironments(e.Environments, opts.Environment)
+		if e.Excluded || environments == nil {
+			continue
+		}
+		if synced[e.SyncKey()] == c.state.EnvFingerprint(e.Value, environments) {
+			skipped = append(skipped, e.SyncKey())
+			continue
+		}
+		envVars = append(envVars, bridge.EnvVar{
+			Key:    e.SyncKey(),
+			Value:  e.Value,
+			Target: environments,
+		})
+		keys = append(keys, e.SyncKey())
+	}
+
+	if !c.JSON {
+		printHeader()
+		if migration.Target != string(provider) {
+			fmt.Println(ui.Warning(fmt.Sprintf("Migration %s targets %s, not %s", migration.ID, migration.Target, provider)))
+			fmt.Println()
+		}
+	}
+
+	if opts.DryRun {
+		return c.printDryRun(migration, stored, keys, skipped, opts.Environment)
+	}
+
+	token, err := loadToken(provider)
+	if err != nil {
+		return err
+	}
+
+	if !c.JSON {
+		if len(skipped) > 0 {
+			fmt.Println(ui.Info(fmt.Sprintf("Skipping %d env vars already synced (use --force to re-send)", len(skipped))))
+		}
+		printProgress(fmt.Sprintf("Syncing %d env vars to %s...", len(envVars), provider))
+	}
+
+	result, err := c.bridge.SyncEnvBatched(ctx, bridge.SyncEnvParams{
+		Provider:  provider,
+		Token:     token,
+		ProjectID: opts.Project,
+		EnvVars:   envVars,
+	}, bridge.DefaultEnvBatchSize, func(p bridge.SyncProgress) {
+		if !c.JSON {
+			fmt.Printf("\r%s %d/%d", ui.ProgressBar(p.Done(), p.Total, 30), p.Done(), p.Total)
+		}
+	})
+	if !c.JSON && len(envVars) > 0 {
+		fmt.Println()
+	}
+
+	// Record what made it even when a later batch failed, so the next run
+	// picks up where this one stopped
+	done := make(map[string]string)
+	for _, e := range bridge.SyncedVars(envVars, result) {
+		done[e.Key] = c.state.EnvFingerprint(e.Value, e.Target)
+	}
+	recordErr := c.state.RecordSyncedEnvContext(ctx, migration.ID, string(provider), done)
+
+	migrationID := migration.ID
+	if err != nil {
+		c.state.LogJSON(&migrationID, "error", fmt.Sprintf("env sync to %s failed: %s", provider, err), map[string]interface{}{"provider": provider})
+		return fmt.Errorf("failed to sync env vars: %w", err)
+	}
+	if recordErr != nil {
+		return recordErr
+	}
+	c.state.LogJSON(&migrationID, logLevelFor(result), fmt.Sprintf("synced %d env vars to %s", result.Synced, provider), map[string]interface{}{
+		"provider": provider,
+		"synced":   result.Synced,
+		"skipped":  len(skipped),
+		"failed":   result.Failed,
+	})
+
+	if c.JSON {
+		if err := printJSON(syncEnvResult{
+			MigrationID: migration.ID,
+			Keys:        nonNil(keys),
+			Skipped:     nonNil(skipped),
+			Synced:      result.Synced,
+			Failed:      nonNil(result.Failed),
+		}); err != nil {
+			return err
+		}
+		if len(result.Failed) > 0 {
+			return reported(fmt.Errorf("failed to sync %d variable(s)", len(result.Failed)))
+		}
+		return nil
+	}
+
+	fmt.Println()
+	summary := fmt.Sprintf("%d skipped, %d synced, %d failed", len(skipped), result.Synced, len(result.Failed))
+	if len(result.Failed) > 0 {
+		fmt.Println(ui.Warning(summary))
+	} else {
+		fmt.Println(ui.Success(summary))
+	}
+	if len(result.Failed) > 0 {
+		fmt.Println(ui.Error(fmt.Sprintf("%d failed:", len(result.Failed))))
+		fmt.Println(ui.List(result.Failed))
+		fmt.Println()
+		return fmt.Errorf("failed to sync %d variable(s)", len(result.Failed))
+	}
+	fmt.Println()
+
+	return nil
+}
+
+func (c *SyncCommand) printDryRun(migration *state.Migration, stored []state.EnvVar, keys, skipped []string, only string) error {
+	if c.JSON {
+		return printJSON(syncEnvResult{
+			MigrationID: migration.ID,
+			DryRun:      true,
+			Keys:        nonNil(keys),
+			Skipped:     nonNil(skipped),
+			Failed:      []string{},
+		})
+	}
+
+	alreadySynced := make(map[string]bool, len(skipped))
+	for _, key := range skipped {
+		alreadySynced[key] = true
+	}
+
+	rows := make([][]string, len(stored))
+	for i, e := range stored {
+		environments := bridge.SyncEnvironments(e.Environments, only)
+		action := "sync"
+		switch {
+		case e.Excluded:
+			action = "skip (excluded)"
+		case environments == nil:
+			action = fmt.Sprintf("skip (not set for %s)", only)
+			environments = bridge.SyncEnvironments(e.Environments, "")
+		case alreadySynced[e.SyncKey()]:
+			action = "skip (already synced)"
+		}
+		rows[i] = []string{e.Key, e.SyncKey(), strings.Join(environments, ", "), action}
+	}
+
+	fmt.Println(ui.Info(fmt.Sprintf("Dry run for migration %s, nothing was sent:", migration.ID)))
+	fmt.Println()
+	fmt.Println(ui.Table([]string{"KEY", "TARGET KEY", "ENVIRONMENTS", "ACTION"}, rows))
+	return nil
+}
+
+func logLevelFor(result *bridge.SyncEnvData) string {
+	if len(result.Failed) > 0 {
+		return "warn"
+	}
+	return "info"
+}
+
+// nonNil keeps empty lists as [] rather than null in JSON output
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
@@ -0,0 +1,251 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/johnhorton/deploy-tunnel/internal/bridge"
+	"github.com/johnhorton/deploy-tunnel/internal/state"
+)
+
+// fakeSyncer records what it was sent and fails the keys in fail
+type fakeSyncer struct {
+	builtinProviders
+	fail  map[string]bool
+	calls int
+	sent  []bridge.EnvVar
+	token string
+}
+
+func (f *fakeSyncer) SyncEnvBatched(ctx context.Context, params bridge.SyncEnvParams, batchSize int, onBatch func(bridge.SyncProgress)) (*bridge.SyncEnvData, error) {
+	f.calls++
+	f.sent = params.EnvVars
+	f.token = params.Token
+
+	result := &bridge.SyncEnvData{Failed: []string{}}
+	for _, e := range params.EnvVars {
+		if f.fail[e.Key] {
+			result.Failed = append(result.Failed, e.Key)
+		} else {
+			result.Synced++
+		}
+	}
+	onBatch(bridge.SyncProgress{Synced: result.Synced, Failed: result.Failed, Total: len(params.EnvVars)})
+	return result, nil
+}
+
+// newSyncState seeds a migration whose API_KEY is renamed and SECRET excluded
+func newSyncState(t *testing.T) *state.DB {
+	t.Helper()
+	storeToken(t, "netlify")
+	db := newTestState(t)
+	if err := db.CreateMigration("m1", "vercel", "netlify", "example.com"); err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range []struct{ key, value, targetKey string }{
+		{"API_KEY", "sk_live_1", "NEW_API_KEY"},
+		{"DEBUG", "1", "DEBUG"},
+		{"SECRET", "hunter2", "SECRET"},
+	} {
+		if err := db.SaveEnvVar("m1", e.key, e.value, e.targetKey, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	vars, err := db.GetEnvVars("m1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range vars {
+		if e.Key == "SECRET" {
+			if err := db.SetEnvVarExcluded(e.ID, true); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	return db
+}
+
+// syncEnv runs `dt sync env --json` against syncer and decodes the result
+func syncEnv(t *testing.T, db *state.DB, syncer *fakeSyncer, opts SyncEnvOptions) (syncEnvResult, error) {
+	t.Helper()
+	cmd := NewSyncCommand(db, syncer)
+	cmd.JSON = true
+	opts.Provider = "netlify"
+
+	var err error
+	out := captureStdout(t, func() { err = cmd.Env(context.Background(), opts) })
+	var result syncEnvResult
+	if jsonErr := json.Unmarshal([]byte(out), &result); jsonErr != nil {
+		t.Fatalf("invalid JSON (%v):\n%s", jsonErr, out)
+	}
+	return result, err
+}
+
+// sentKeys lists the keys syncer was last sent
+func sentKeys(syncer *fakeSyncer) string {
+	keys := make([]string, len(syncer.sent))
+	for i, e := range syncer.sent {
+		keys[i] = e.Key
+	}
+	return strings.Join(keys, ",")
+}
+
+func TestSyncEnvRenamesAndExcludes(t *testing.T) {
+	db := newSyncState(t)
+	syncer := &fakeSyncer{}
+
+	result, err := syncEnv(t, db, syncer, SyncEnvOptions{})
+	if err != nil {
+		t.Fatalf("Env() error: %v", err)
+	}
+	if got := sentKeys(syncer); got != "NEW_API_KEY,DEBUG" {
+		t.Errorf("sent %s, want the renamed key and DEBUG, not the excluded SECRET", got)
+	}
+	if syncer.sent[0].Value != "sk_live_1" || syncer.token != "tok_netlify_0123456789" {
+		t.Errorf("sent %+v with token %q, want the stored value and token", syncer.sent[0], syncer.token)
+	}
+	if result.Synced != 2 || len(result.Failed) != 0 {
+		t.Errorf("result = %+v, want 2 synced", result)
+	}
+
+	// Unchanged vars aren't sent again
+	result, err = syncEnv(t, db, syncer, SyncEnvOptions{})
+	if err != nil {
+		t.Fatalf("second Env() error: %v", err)
+	}
+	if sentKeys(syncer) != "" || strings.Join(result.Skipped, ",") != "NEW_API_KEY,DEBUG" {
+		t.Errorf("second run sent %q and skipped %v, want both skipped", sentKeys(syncer), result.Skipped)
+	}
+
+	// --force re-sends them
+	if _, err := syncEnv(t, db, syncer, SyncEnvOptions{Force: true}); err != nil {
+		t.Fatalf("forced Env() error: %v", err)
+	}
+	if got := sentKeys(syncer); got != "NEW_API_KEY,DEBUG" {
+		t.Errorf("--force sent %s, want both again", got)
+	}
+}
+
+func TestSyncEnvPartialFailure(t *testing.T) {
+	db := newSyncState(t)
+	syncer := &fakeSyncer{fail: map[string]bool{"DEBUG": true}}
+
+	result, err := syncEnv(t, db, syncer, SyncEnvOptions{})
+	if err == nil || !strings.Contains(err.Error(), "failed to sync 1 variable") {
+		t.Errorf("Env() error = %v, want one failed variable", err)
+	}
+	if result.Synced != 1 || strings.Join(result.Failed, ",") != "DEBUG" {
+		t.Errorf("result = %+v, want NEW_API_KEY synced and DEBUG failed", result)
+	}
+
+	// The retry only sends what failed
+	syncer.fail = nil
+	if _, err := syncEnv(t, db, syncer, SyncEnvOptions{}); err != nil {
+		t.Fatalf("retry error: %v", err)
+	}
+	if got := sentKeys(syncer); got != "DEBUG" {
+		t.Errorf("retry sent %s, want only DEBUG", got)
+	}
+
+	logs, err := db.GetLogs("m1", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var warned bool
+	for _, l := range logs {
+		warned = warned || (l.Level == "warn" && strings.Contains(l.Message, "synced 1 env vars to netlify"))
+	}
+	if !warned {
+		t.Errorf("the partial failure wasn't logged as a warning: %+v", logs)
+	}
+}
+
+func TestSyncEnvDryRun(t *testing.T) {
+	db := newSyncState(t)
+	syncer := &fakeSyncer{}
+
+	result, err := syncEnv(t, db, syncer, SyncEnvOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("Env() error: %v", err)
+	}
+	if syncer.calls != 0 {
+		t.Error("a dry run called the provider")
+	}
+	if !result.DryRun || strings.Join(result.Keys, ",") != "NEW_API_KEY,DEBUG" {
+		t.Errorf("result = %+v, want NEW_API_KEY and DEBUG listed", result)
+	}
+
+	out := captureStdout(t, func() {
+		err = NewSyncCommand(db, syncer).Env(context.Background(), SyncEnvOptions{Provider: "netlify", DryRun: true})
+	})
+	if err != nil {
+		t.Fatalf("Env() error: %v", err)
+	}
+	for _, want := range []string{"NEW_API_KEY", "skip (excluded)", "nothing was sent"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("dry run output is missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "hunter2") || strings.Contains(out, "sk_live_1") {
+		t.Errorf("dry run output shows values:\n%s", out)
+	}
+}
+
+func TestSyncEnvEnvironments(t *testing.T) {
+	tests := []struct {
+		name string
+		env  string
+		// want lists each sent key with its environments
+		want string
+	}{
+		{name: "as declared", want: "PROD_ONLY=[production] PREVIEW_DEV=[preview development] EVERYWHERE=[production preview development]"},
+		{name: "production", env: "production", want: "PROD_ONLY=[production] EVERYWHERE=[production]"},
+		{name: "development", env: "development", want: "PREVIEW_DEV=[development] EVERYWHERE=[development]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storeToken(t, "netlify")
+			db := newTestState(t)
+			if err := db.CreateMigration("m1", "vercel", "netlify", "example.com"); err != nil {
+				t.Fatal(err)
+			}
+			for _, e := range []struct {
+				key          string
+				environments []string
+			}{
+				{"PROD_ONLY", []string{"production"}},
+				{"PREVIEW_DEV", []string{"preview", "development"}},
+				{"EVERYWHERE", nil},
+			} {
+				if err := db.SaveEnvVar("m1", e.key, "v", "", e.environments); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			syncer := &fakeSyncer{}
+			if _, err := syncEnv(t, db, syncer, SyncEnvOptions{Environment: tt.env}); err != nil {
+				t.Fatalf("Env() error: %v", err)
+			}
+			sent := make([]string, len(syncer.sent))
+			for i, e := range syncer.sent {
+				sent[i] = fmt.Sprintf("%s=%v", e.Key, e.Target)
+			}
+			if got := strings.Join(sent, " "); got != tt.want {
+				t.Errorf("sent %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseSyncEnvFlagsEnvironment(t *testing.T) {
+	if opts, err := ParseSyncEnvFlags([]string{"--provider", "netlify", "--env", "preview"}); err != nil || opts.Environment != "preview" {
+		t.Errorf("ParseSyncEnvFlags(--env preview) = %+v, %v", opts, err)
+	}
+	if _, err := ParseSyncEnvFlags([]string{"--provider", "netlify", "--env", "staging"}); err == nil || !strings.Contains(err.Error(), "invalid --env") {
+		t.Errorf("ParseSyncEnvFlags(--env staging) error = %v, want invalid --env", err)
+	}
+}
@@ -0,0 +1,278 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/johnhorton/deploy-tunnel/internal/bridge"
+	"github.com/johnhorton/deploy-tunnel/internal/state"
+	"github.com/johnhorton/deploy-tunnel/ui"
+)
+
+func init() {
+	register(command{name: "verify", run: (*runner).verify, help: []helpLine{
+		{"verify", "Compare source and target routes"},
+	}})
+}
+
+func (r *runner) verify(ctx context.Context, args []string) error {
+	opts, err := ParseVerifyFlags(args)
+	if err != nil {
+		return err
+	}
+	db, br, err := r.open("")
+	if err != nil {
+		return err
+	}
+	cmd := NewVerifyCommand(db, br)
+	cmd.JSON = r.global.JSON
+	return cmd.Run(ctx, opts)
+}
+
+// Diff severities
+const (
+	SeverityBlocking = "blocking"
+	SeverityWarning  = "warning"
+)
+
+type VerifyCommand struct {
+	state  *state.DB
+	bridge configFetcher
+
+	// JSON prints the diffs as JSON
+	JSON bool
+}
+
+func NewVerifyCommand(stateDB *state.DB, br configFetcher) *VerifyCommand {
+	return &VerifyCommand{
+		state:  stateDB,
+		bridge: br,
+	}
+}
+
+// VerifyOptions holds the flags for `dt verify`
+type VerifyOptions struct {
+	MigrationID   string
+	SourceProject string
+	TargetProject string
+}
+
+// ParseVerifyFlags parses `dt verify [--migration id] [--source-project id] [--target-project id]`
+func ParseVerifyFlags(args []string) (VerifyOptions, error) {
+	var opts VerifyOptions
+
+	fs := flag.NewFlagSet("verify", flag.ContinueOnError)
+	fs.StringVar(&opts.MigrationID, "migration", "", "migration to verify (defaults to the current one)")
+	fs.StringVar(&opts.SourceProject, "source-project", "", "source project ID")
+	fs.StringVar(&opts.TargetProject, "target-project", "", "target project ID")
+
+	if err := fs.Parse(args); err != nil {
+		return opts, err
+	}
+	if fs.NArg() > 0 {
+		return opts, fmt.Errorf("unexpected arguments: %s", strings.Join(fs.Args(), " "))
+	}
+	return opts, nil
+}
+
+// ConfigDiff is one difference between the source and target configs
+type ConfigDiff struct {
+	Severity string `json:"severity"`
+	Check    string `json:"check"`
+	Source   string `json:"source"`
+	Target   string `json:"target"`
+}
+
+// verifyResult is the JSON form of `dt verify`
+type verifyResult struct {
+	MigrationID string       `json:"migration_id"`
+	Blocking    int          `json:"blocking"`
+	Warnings    int          `json:"warnings"`
+	Diffs       []ConfigDiff `json:"diffs"`
+}
+
+// Run fetches both sides' configs and reports where the target differs from
+// the source. It fails when any difference is blocking, except with --json,
+// where the blocking count is in the output.
+func (c *VerifyCommand) Run(ctx context.Context, opts VerifyOptions) error {
+	migration, err := loadMigration(ctx, c.state, opts.MigrationID)
+	if err != nil {
+		return err
+	}
+
+	if !c.JSON {
+		printHeader()
+		printProgress(fmt.Sprintf("Comparing %s and %s configs...", migration.Source, migration.Target))
+		printGap()
+	}
+
+	source, err := fetchProviderConfig(ctx, c.bridge, migration.Source, opts.SourceProject)
+	if err != nil {
+		return err
+	}
+	target, err := fetchProviderConfig(ctx, c.bridge, migration.Target, opts.TargetProject)
+	if err != nil {
+		return err
+	}
+
+	stored, err := c.state.GetEnvVarsContext(ctx, migration.ID)
+	if err != nil {
+		return fmt.Errorf("failed to load env vars: %w", err)
+	}
+
+	diffs := DiffConfigs(source, target, stored)
+	result := verifyResult{MigrationID: migration.ID, Diffs: diffs}
+	for _, d := range diffs {
+		if d.Severity == SeverityBlocking {
+			result.Blocking++
+		} else {
+			result.Warnings++
+		}
+	}
+
+	migrationID := migration.ID
+	level := "info"
+	if result.Blocking > 0 {
+		level = "error"
+	} else if result.Warnings > 0 {
+		level = "warn"
+	}
+	c.state.LogJSON(&migrationID, level, fmt.Sprintf("verify found %d blocking and %d warning differences", result.Blocking, result.Warnings), map[string]interface{}{
+		"blocking": result.Blocking,
+		"warnings": result.Warnings,
+	})
+
+	if c.JSON {
+		if result.Diffs == nil {
+			result.Diffs = []ConfigDiff{}
+		}
+		if err := printJSON(result); err != nil {
+			return err
+		}
+	} else {
+		c.print(result)
+	}
+
+	if result.Blocking > 0 {
+		err := fmt.Errorf("verification failed with %d blocking difference(s)", result.Blocking)
+		if c.JSON {
+			return reported(err)
+		}
+		return err
+	}
+	return nil
+}
+
+// fetchProviderConfig fetches a project's config from the named provider
+// using its stored token
+func fetchProviderConfig(ctx context.Context, br configFetcher, name, project string) (*bridge.FetchConfigData, error) {
+	provider, err := br.ParseProvider(name)
+	if err != nil {
+		return nil, err
+	}
+	token, err := loadToken(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := br.FetchConfig(ctx, bridge.FetchConfigParams{
+		Provider:  provider,
+		Token:     token,
+		ProjectID: project,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s config: %w", provider, err)
+	}
+	return config, nil
+}
+
+func (c *VerifyCommand) print(result verifyResult) {
+	if len(result.Diffs) == 0 {
+		fmt.Println(ui.Success("Source and target configs match"))
+		fmt.Println()
+		return
+	}
+
+	rows := make([][]string, len(result.Diffs))
+	for i, d := range result.Diffs {
+		rows[i] = []string{d.Severity, d.Check, d.Source, d.Target}
+	}
+	fmt.Println(ui.Table([]string{"SEVERITY", "CHECK", "SOURCE", "TARGET"}, rows))
+
+	if result.Blocking > 0 {
+		fmt.Println(ui.Error(fmt.Sprintf("%d blocking, %d warning", result.Blocking, result.Warnings)))
+	} else {
+		fmt.Println(ui.Warning(fmt.Sprintf("%d warning(s), nothing blocking", result.Warnings)))
+	}
+	fmt.Println()
+}
+
+// DiffConfigs compares target against source. Env keys are expected under
+// their synced names, honoring the migration's remaps and exclusions in
+// stored; a missing key or output dir blocks cutover, other differences warn.
+func DiffConfigs(source, target *bridge.FetchConfigData, stored []state.EnvVar) []ConfigDiff {
+	var diffs []ConfigDiff
+	compare := func(severity, check, src, dst string) {
+		if src != dst {
+			diffs = append(diffs, ConfigDiff{Severity: severity, Check: check, Source: src, Target: dst})
+		}
+	}
+
+	compare(SeverityBlocking, "build output dir", source.Build.OutputDir, target.Build.OutputDir)
+	compare(SeverityWarning, "build command", source.Build.Command, target.Build.Command)
+	compare(SeverityWarning, "install command", source.Build.InstallCommand, target.Build.InstallCommand)
+	compare(SeverityWarning, "framework", source.Project.Framework, target.Project.Framework)
+	compare(SeverityWarning, "project domain", source.Project.Domain, target.Project.Domain)
+
+	// Map source keys to the names they sync under, dropping excluded ones
+	rename := make(map[string]string, len(stored))
+	excluded := make(map[string]bool)
+	for _, e := range stored {
+		if e.Excluded {
+			excluded[e.Key] = true
+			continue
+		}
+		rename[e.Key] = e.SyncKey()
+	}
+
+	expected := make(map[string]bool)
+	for _, e := range source.Env {
+		if excluded[e.Key] {
+			continue
+		}
+		key := e.Key
+		if synced, ok := rename[key]; ok {
+			key = synced
+		}
+		expected[key] = true
+	}
+	actual := make(map[string]bool, len(target.Env))
+	for _, e := range target.Env {
+		actual[e.Key] = true
+	}
+
+	for _, key := range sortedKeys(expected) {
+		if !actual[key] {
+			diffs = append(diffs, ConfigDiff{Severity: SeverityBlocking, Check: "env " + key, Source: "set", Target: "missing"})
+		}
+	}
+	for _, key := range sortedKeys(actual) {
+		if !expected[key] {
+			diffs = append(diffs, ConfigDiff{Severity: SeverityWarning, Check: "env " + key, Source: "missing", Target: "set"})
+		}
+	}
+
+	return diffs
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
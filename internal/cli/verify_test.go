@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/johnhorton/deploy-tunnel/internal/bridge"
+	"github.com/johnhorton/deploy-tunnel/internal/state"
+)
+
+// configsByProvider returns each provider's config from a map
+type configsByProvider map[bridge.Provider]*bridge.FetchConfigData
+
+func (c configsByProvider) FetchConfig(ctx context.Context, params bridge.FetchConfigParams) (*bridge.FetchConfigData, error) {
+	return c[params.Provider], nil
+}
+
+func (c configsByProvider) ParseProvider(name string) (bridge.Provider, error) {
+	return builtinProviders{}.ParseProvider(name)
+}
+
+func sourceConfig() *bridge.FetchConfigData {
+	return &bridge.FetchConfigData{
+		Project: bridge.Project{Domain: "shop.example.com", Framework: "nextjs"},
+		Build:   bridge.BuildConfig{Command: "npm run build", OutputDir: ".next"},
+		Env: []bridge.EnvVar{
+			{Key: "API_KEY", Value: "sk_live_1"},
+			{Key: "DATABASE_URL", Value: "postgres://db"},
+			{Key: "VERCEL_ANALYTICS", Value: "1"},
+		},
+	}
+}
+
+func TestDiffConfigs(t *testing.T) {
+	// The migration renames DATABASE_URL and leaves VERCEL_ANALYTICS behind
+	stored := []state.EnvVar{
+		{Key: "DATABASE_URL", TargetKey: "DB_URL"},
+		{Key: "VERCEL_ANALYTICS", Excluded: true},
+	}
+
+	tests := []struct {
+		name   string
+		target func(*bridge.FetchConfigData)
+		want   []ConfigDiff
+	}{
+		{
+			name: "matching",
+			target: func(c *bridge.FetchConfigData) {
+				c.Env = []bridge.EnvVar{{Key: "API_KEY"}, {Key: "DB_URL"}}
+			},
+		},
+		{
+			name: "missing env key and other build command",
+			target: func(c *bridge.FetchConfigData) {
+				c.Build.Command = "pnpm build"
+				c.Env = []bridge.EnvVar{{Key: "DB_URL"}, {Key: "NETLIFY_ONLY"}}
+			},
+			want: []ConfigDiff{
+				{Severity: SeverityWarning, Check: "build command", Source: "npm run build", Target: "pnpm build"},
+				{Severity: SeverityBlocking, Check: "env API_KEY", Source: "set", Target: "missing"},
+				{Severity: SeverityWarning, Check: "env NETLIFY_ONLY", Source: "missing", Target: "set"},
+			},
+		},
+		{
+			name: "other output dir",
+			target: func(c *bridge.FetchConfigData) {
+				c.Build.OutputDir = "out"
+				c.Env = []bridge.EnvVar{{Key: "API_KEY"}, {Key: "DB_URL"}}
+			},
+			want: []ConfigDiff{
+				{Severity: SeverityBlocking, Check: "build output dir", Source: ".next", Target: "out"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := sourceConfig()
+			tt.target(target)
+			if got := DiffConfigs(sourceConfig(), target, stored); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiffConfigs() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyJSON(t *testing.T) {
+	storeToken(t, "vercel")
+	storeToken(t, "netlify")
+	db := newTestState(t)
+	if err := db.CreateMigration("m1", "vercel", "netlify", "example.com"); err != nil {
+		t.Fatal(err)
+	}
+	target := sourceConfig()
+	target.Build.Command = "pnpm build"
+	target.Env = target.Env[:1]
+
+	cmd := NewVerifyCommand(db, configsByProvider{"vercel": sourceConfig(), "netlify": target})
+	cmd.JSON = true
+	var err error
+	out := captureStdout(t, func() { err = cmd.Run(context.Background(), VerifyOptions{}) })
+
+	var reportedErr *reportedError
+	if !errors.As(err, &reportedErr) {
+		t.Errorf("Run() error = %v, want a blocking failure already reported", err)
+	}
+	var result verifyResult
+	if err := json.Unmarshal([]byte(out), &result); err != nil {
+		t.Fatalf("output isn't JSON: %v\n%s", err, out)
+	}
+	if result.MigrationID != "m1" || result.Blocking != 2 || result.Warnings != 1 {
+		t.Errorf("result = %+v, want m1 with 2 blocking and 1 warning", result)
+	}
+}
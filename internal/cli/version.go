@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/johnhorton/deploy-tunnel/internal/bridge"
+	"github.com/johnhorton/deploy-tunnel/internal/version"
+	"github.com/johnhorton/deploy-tunnel/ui"
+)
+
+func init() {
+	register(command{name: "version", run: (*runner).version, help: []helpLine{
+		{"version", "Print version information"},
+	}})
+}
+
+func (r *runner) version(ctx context.Context, args []string) error {
+	cmd := NewVersionCommand(r.lister())
+	cmd.JSON = r.global.JSON
+	return cmd.Run(ctx)
+}
+
+// adapterLister is the bridge call VersionCommand needs
+type adapterLister interface {
+	ListAdapters(ctx context.Context) ([]bridge.ProviderCapabilities, error)
+}
+
+type VersionCommand struct {
+	bridge adapterLister
+
+	// JSON prints the versions as JSON
+	JSON bool
+}
+
+func NewVersionCommand(br adapterLister) *VersionCommand {
+	return &VersionCommand{
+		bridge: br,
+	}
+}
+
+// adapterVersion is the JSON form of one installed adapter
+type adapterVersion struct {
+	Provider string `json:"provider"`
+	Name     string `json:"name,omitempty"`
+	Version  string `json:"version,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// versionInfo is the JSON form of `dt version`
+type versionInfo struct {
+	Version  string           `json:"version"`
+	Commit   string           `json:"commit"`
+	Date     string           `json:"date"`
+	Protocol protocolRange    `json:"protocol"`
+	Adapters []adapterVersion `json:"adapters"`
+
+	// AdaptersError is set when the adapters directory couldn't be read
+	AdaptersError string `json:"adapters_error,omitempty"`
+}
+
+type protocolRange struct {
+	Min string `json:"min"`
+	Max string `json:"max"`
+}
+
+// Run prints the build version, the bridge protocol range, and each
+// installed adapter's version. Adapters that fail to respond are reported
+// rather than failing the command.
+func (c *VersionCommand) Run(ctx context.Context) error {
+	info := versionInfo{
+		Version:  version.Version,
+		Commit:   version.Commit,
+		Date:     version.Date,
+		Protocol: protocolRange{Min: bridge.MinProtocolVersion, Max: bridge.MaxProtocolVersion},
+		Adapters: []adapterVersion{},
+	}
+
+	adapters, listErr := c.bridge.ListAdapters(ctx)
+	if listErr != nil {
+		info.AdaptersError = listErr.Error()
+	}
+	for _, a := range adapters {
+		v := adapterVersion{Provider: string(a.Provider)}
+		if a.Available() {
+			v.Name = a.Capabilities.AdapterName
+			v.Version = a.Capabilities.AdapterVersion
+		} else if a.Err != nil {
+			v.Error = a.Err.Error()
+		}
+		info.Adapters = append(info.Adapters, v)
+	}
+
+	if c.JSON {
+		return printJSON(info)
+	}
+
+	fmt.Println(ui.KeyValue("Version", version.Short()))
+	fmt.Println(ui.KeyValue("Commit", info.Commit))
+	fmt.Println(ui.KeyValue("Built", info.Date))
+	fmt.Println(ui.KeyValue("Protocol", fmt.Sprintf("%s - %s", info.Protocol.Min, info.Protocol.Max)))
+	fmt.Println()
+
+	if listErr != nil {
+		fmt.Println(ui.Warning(fmt.Sprintf("Could not list adapters: %s", listErr)))
+		return nil
+	}
+	if len(info.Adapters) == 0 {
+		fmt.Println(ui.Warning("No adapters installed"))
+		return nil
+	}
+
+	rows := make([][]string, len(info.Adapters))
+	for i, a := range info.Adapters {
+		if a.Error != "" {
+			rows[i] = []string{a.Provider, "-", "unavailable: " + a.Error}
+			continue
+		}
+		rows[i] = []string{a.Provider, a.Name, a.Version}
+	}
+	fmt.Println(ui.Table([]string{"ADAPTER", "NAME", "VERSION"}, rows))
+
+	return nil
+}
@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/johnhorton/deploy-tunnel/internal/bridge"
+	"github.com/johnhorton/deploy-tunnel/internal/version"
+)
+
+// listerFunc adapts a function to adapterLister
+type listerFunc func(ctx context.Context) ([]bridge.ProviderCapabilities, error)
+
+func (f listerFunc) ListAdapters(ctx context.Context) ([]bridge.ProviderCapabilities, error) {
+	return f(ctx)
+}
+
+// setVersion sets the build metadata until the test ends
+func setVersion(t *testing.T, v, commit, date string) {
+	t.Helper()
+	previous := []string{version.Version, version.Commit, version.Date}
+	version.Version, version.Commit, version.Date = v, commit, date
+	t.Cleanup(func() { version.Version, version.Commit, version.Date = previous[0], previous[1], previous[2] })
+}
+
+func TestVersionJSON(t *testing.T) {
+	setVersion(t, "1.2.0", "abc1234", "2025-06-01")
+	lister := listerFunc(func(ctx context.Context) ([]bridge.ProviderCapabilities, error) {
+		return []bridge.ProviderCapabilities{
+			{Provider: "vercel", CapResult: bridge.CapResult{Capabilities: &bridge.CapabilitiesData{AdapterName: "vercel", AdapterVersion: "1.0.0"}}},
+			{Provider: "netlify", CapResult: bridge.CapResult{Err: errors.New("exit status 1")}},
+		}, nil
+	})
+
+	cmd := NewVersionCommand(lister)
+	cmd.JSON = true
+	var err error
+	out := captureStdout(t, func() { err = cmd.Run(context.Background()) })
+	if err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	var info versionInfo
+	if err := json.Unmarshal([]byte(out), &info); err != nil {
+		t.Fatalf("invalid JSON (%v):\n%s", err, out)
+	}
+	if info.Version != "1.2.0" || info.Commit != "abc1234" || info.Date != "2025-06-01" {
+		t.Errorf("build info = %+v", info)
+	}
+	if info.Protocol.Min != bridge.MinProtocolVersion || info.Protocol.Max != bridge.MaxProtocolVersion {
+		t.Errorf("protocol = %+v, want %s - %s", info.Protocol, bridge.MinProtocolVersion, bridge.MaxProtocolVersion)
+	}
+	want := []adapterVersion{
+		{Provider: "vercel", Name: "vercel", Version: "1.0.0"},
+		{Provider: "netlify", Error: "exit status 1"},
+	}
+	if len(info.Adapters) != len(want) {
+		t.Fatalf("adapters = %+v, want %+v", info.Adapters, want)
+	}
+	for i := range want {
+		if info.Adapters[i] != want[i] {
+			t.Errorf("adapter %d = %+v, want %+v", i, info.Adapters[i], want[i])
+		}
+	}
+}
+
+func TestVersionWithoutAdapters(t *testing.T) {
+	setVersion(t, "1.2.0", "abc1234", "2025-06-01")
+	lister := failedLister{err: errors.New("adapters directory not found")}
+
+	cmd := NewVersionCommand(lister)
+	var err error
+	out := captureStdout(t, func() { err = cmd.Run(context.Background()) })
+	if err != nil {
+		t.Fatalf("Run() error: %v, want the version printed anyway", err)
+	}
+	for _, want := range []string{"v1.2.0", "abc1234", "Could not list adapters: adapters directory not found"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output is missing %q:\n%s", want, out)
+		}
+	}
+
+	cmd.JSON = true
+	out = captureStdout(t, func() { err = cmd.Run(context.Background()) })
+	var info versionInfo
+	if err := json.Unmarshal([]byte(out), &info); err != nil {
+		t.Fatalf("invalid JSON (%v):\n%s", err, out)
+	}
+	if info.AdaptersError != "adapters directory not found" || info.Adapters == nil || len(info.Adapters) != 0 {
+		t.Errorf("JSON = %+v, want the error and an empty adapter list", info)
+	}
+}
@@ -0,0 +1,407 @@
+// Package config loads user defaults from ~/.deploy-tunnel/config.json.
+// Values resolve in order: flags > environment > config file > defaults.
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/johnhorton/deploy-tunnel/internal/bridge"
+	"github.com/johnhorton/deploy-tunnel/internal/events"
+	"github.com/johnhorton/deploy-tunnel/internal/keychain"
+	"github.com/johnhorton/deploy-tunnel/internal/registry"
+	"github.com/johnhorton/deploy-tunnel/internal/state"
+	"github.com/johnhorton/deploy-tunnel/ui"
+)
+
+// FileName is the config file inside the deploy-tunnel directory
+const FileName = "config.json"
+
+// Environment variables that override the config file
+const (
+	EnvConfigPath    = "DEPLOY_TUNNEL_CONFIG"
+	EnvAdaptersPath  = "DEPLOY_TUNNEL_ADAPTERS_PATH"
+	EnvAdapters      = "DEPLOY_TUNNEL_ADAPTERS" // alias; EnvAdaptersPath wins
+	EnvTimeout       = "DEPLOY_TUNNEL_TIMEOUT"
+	EnvDefaultSource = "DEPLOY_TUNNEL_DEFAULT_SOURCE"
+	EnvDefaultTarget = "DEPLOY_TUNNEL_DEFAULT_TARGET"
+	EnvRuntime       = "DEPLOY_TUNNEL_RUNTIME"
+	EnvStateDir      = "DEPLOY_TUNNEL_STATE_DIR"
+	EnvAuditLog      = "DEPLOY_TUNNEL_AUDIT_LOG"
+	EnvWebhookURL    = "DEPLOY_TUNNEL_WEBHOOK_URL"
+	EnvRegistryURL   = "DEPLOY_TUNNEL_REGISTRY_URL"
+	EnvCacheTTL      = "DEPLOY_TUNNEL_CACHE_TTL"
+	EnvTheme         = "DEPLOY_TUNNEL_THEME"
+)
+
+// CacheOff is the CacheTTL that turns off response caching; a zero CacheTTL
+// means "not set" like every other field
+const CacheOff time.Duration = -1
+
+// Config holds user defaults. Empty fields mean "not set" so configs can be
+// layered with Merge.
+type Config struct {
+	// AdaptersPath is the directory holding <provider>/index.ts adapters
+	AdaptersPath string
+	// Timeout bounds each adapter call
+	Timeout time.Duration
+	// DefaultSource is used when a command needs the source provider and none was given
+	DefaultSource string
+	// DefaultTarget is used when a command needs the target provider and none was given
+	DefaultTarget string
+	// Runtime is the Bun executable that runs adapters
+	Runtime string
+	// StateDir holds the state database
+	StateDir string
+	// AuditLog, when set, is a JSONL file recording every adapter call
+	AuditLog string
+	// WebhookURL, when set, receives a POST for every migration event
+	WebhookURL string
+	// RegistryURL is the base URL `dt adapters install` downloads adapters from
+	RegistryURL string
+	// CacheTTL is how long read-only adapter responses are reused; CacheOff
+	// disables the cache
+	CacheTTL time.Duration
+	// Theme names the built-in color theme; theme.json can override it
+	Theme string
+
+	// path is the config file this was loaded from, if any
+	path string
+}
+
+// fileConfig is the on-disk form; Timeout is a Go duration string like "45s"
+type fileConfig struct {
+	AdaptersPath  string `json:"adapters_path"`
+	Timeout       string `json:"timeout"`
+	DefaultSource string `json:"default_source"`
+	DefaultTarget string `json:"default_target"`
+	Runtime       string `json:"runtime"`
+	StateDir      string `json:"state_dir"`
+	AuditLog      string `json:"audit_log"`
+	WebhookURL    string `json:"webhook_url"`
+	RegistryURL   string `json:"registry_url"`
+	CacheTTL      string `json:"cache_ttl"`
+	Theme         string `json:"theme"`
+}
+
+// Default returns the built-in defaults
+func Default() Config {
+	return Config{
+		Timeout:  30 * time.Second,
+		Runtime:  "bun",
+		CacheTTL: 60 * time.Second,
+	}
+}
+
+// DefaultPath returns the config file location, honoring DEPLOY_TUNNEL_CONFIG
+func DefaultPath() (string, error) {
+	if path := os.Getenv(EnvConfigPath); path != "" {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home dir: %w", err)
+	}
+	return filepath.Join(home, ".deploy-tunnel", FileName), nil
+}
+
+// Load reads the config file at path (DefaultPath when empty) and applies
+// environment overrides on top. A missing file is not an error.
+func Load(path string) (Config, error) {
+	if path == "" {
+		var err error
+		if path, err = DefaultPath(); err != nil {
+			return Config{}, err
+		}
+	}
+	return load(path, os.Getenv)
+}
+
+func load(path string, getenv func(string) string) (Config, error) {
+	cfg := Default()
+
+	file, err := readFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+	cfg = cfg.Merge(file)
+
+	env, err := fromEnv(getenv)
+	if err != nil {
+		return Config{}, err
+	}
+	cfg = cfg.Merge(env)
+	cfg.path = path
+	return cfg, nil
+}
+
+// readFile parses the config file, returning an empty Config if it doesn't exist
+func readFile(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return Config{}, nil
+	}
+	if err != nil {
+		return Config{}, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	var fc fileConfig
+	if err := json.Unmarshal(data, &fc); err != nil {
+		return Config{}, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+
+	cfg := Config{
+		AdaptersPath:  fc.AdaptersPath,
+		DefaultSource: fc.DefaultSource,
+		DefaultTarget: fc.DefaultTarget,
+		Runtime:       fc.Runtime,
+		StateDir:      fc.StateDir,
+		AuditLog:      fc.AuditLog,
+		WebhookURL:    fc.WebhookURL,
+		RegistryURL:   fc.RegistryURL,
+		Theme:         fc.Theme,
+	}
+	if fc.Timeout != "" {
+		if cfg.Timeout, err = parseTimeout(fc.Timeout); err != nil {
+			return Config{}, fmt.Errorf("invalid config %s: timeout: %w", path, err)
+		}
+	}
+	if fc.CacheTTL != "" {
+		if cfg.CacheTTL, err = parseCacheTTL(fc.CacheTTL); err != nil {
+			return Config{}, fmt.Errorf("invalid config %s: cache_ttl: %w", path, err)
+		}
+	}
+	if err := checkWebhookURL(cfg.WebhookURL); err != nil {
+		return Config{}, fmt.Errorf("invalid config %s: webhook_url: %w", path, err)
+	}
+	if err := checkRegistryURL(cfg.RegistryURL); err != nil {
+		return Config{}, fmt.Errorf("invalid config %s: registry_url: %w", path, err)
+	}
+	if err := checkTheme(cfg.Theme); err != nil {
+		return Config{}, fmt.Errorf("invalid config %s: theme: %w", path, err)
+	}
+	return cfg, nil
+}
+
+func fromEnv(getenv func(string) string) (Config, error) {
+	cfg := Config{
+		AdaptersPath:  getenv(EnvAdaptersPath),
+		DefaultSource: getenv(EnvDefaultSource),
+		DefaultTarget: getenv(EnvDefaultTarget),
+		Runtime:       getenv(EnvRuntime),
+		StateDir:      getenv(EnvStateDir),
+		AuditLog:      getenv(EnvAuditLog),
+		WebhookURL:    getenv(EnvWebhookURL),
+		RegistryURL:   getenv(EnvRegistryURL),
+		Theme:         getenv(EnvTheme),
+	}
+	if cfg.AdaptersPath == "" {
+		cfg.AdaptersPath = getenv(EnvAdapters)
+	}
+	if raw := getenv(EnvTimeout); raw != "" {
+		timeout, err := parseTimeout(raw)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s: %w", EnvTimeout, err)
+		}
+		cfg.Timeout = timeout
+	}
+	if raw := getenv(EnvCacheTTL); raw != "" {
+		ttl, err := parseCacheTTL(raw)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid %s: %w", EnvCacheTTL, err)
+		}
+		cfg.CacheTTL = ttl
+	}
+	if err := checkWebhookURL(cfg.WebhookURL); err != nil {
+		return Config{}, fmt.Errorf("invalid %s: %w", EnvWebhookURL, err)
+	}
+	if err := checkRegistryURL(cfg.RegistryURL); err != nil {
+		return Config{}, fmt.Errorf("invalid %s: %w", EnvRegistryURL, err)
+	}
+	if err := checkTheme(cfg.Theme); err != nil {
+		return Config{}, fmt.Errorf("invalid %s: %w", EnvTheme, err)
+	}
+	return cfg, nil
+}
+
+// parseTimeout parses a positive Go duration such as "45s" or "2m"
+func parseTimeout(raw string) (time.Duration, error) {
+	timeout, err := time.ParseDuration(raw)
+	if err != nil {
+		return 0, err
+	}
+	if timeout <= 0 {
+		return 0, fmt.Errorf("must be positive")
+	}
+	return timeout, nil
+}
+
+// parseCacheTTL parses a Go duration such as "30s"; "0" turns the cache off
+func parseCacheTTL(raw string) (time.Duration, error) {
+	ttl, err := time.ParseDuration(raw)
+	if err != nil {
+		return 0, err
+	}
+	if ttl < 0 {
+		return 0, fmt.Errorf("must not be negative")
+	}
+	if ttl == 0 {
+		return CacheOff, nil
+	}
+	return ttl, nil
+}
+
+// checkWebhookURL accepts an empty URL or an absolute http(s) one
+func checkWebhookURL(raw string) error {
+	if raw == "" {
+		return nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("must be an http or https URL")
+	}
+	return nil
+}
+
+// checkRegistryURL accepts an empty URL or an absolute http(s) or file one
+func checkRegistryURL(raw string) error {
+	if raw == "" {
+		return nil
+	}
+	return registry.CheckURL(raw)
+}
+
+// checkTheme accepts an empty name or a built-in theme's
+func checkTheme(name string) error {
+	if name == "" {
+		return nil
+	}
+	_, err := ui.BuiltinTheme(name)
+	return err
+}
+
+// Merge returns c with every non-empty field of overrides applied on top
+func (c Config) Merge(overrides Config) Config {
+	if overrides.AdaptersPath != "" {
+		c.AdaptersPath = overrides.AdaptersPath
+	}
+	if overrides.Timeout != 0 {
+		c.Timeout = overrides.Timeout
+	}
+	if overrides.DefaultSource != "" {
+		c.DefaultSource = overrides.DefaultSource
+	}
+	if overrides.DefaultTarget != "" {
+		c.DefaultTarget = overrides.DefaultTarget
+	}
+	if overrides.Runtime != "" {
+		c.Runtime = overrides.Runtime
+	}
+	if overrides.StateDir != "" {
+		c.StateDir = overrides.StateDir
+	}
+	if overrides.AuditLog != "" {
+		c.AuditLog = overrides.AuditLog
+	}
+	if overrides.WebhookURL != "" {
+		c.WebhookURL = overrides.WebhookURL
+	}
+	if overrides.RegistryURL != "" {
+		c.RegistryURL = overrides.RegistryURL
+	}
+	if overrides.CacheTTL != 0 {
+		c.CacheTTL = overrides.CacheTTL
+	}
+	if overrides.Theme != "" {
+		c.Theme = overrides.Theme
+	}
+	return c
+}
+
+// ThemePath returns the theme file location, next to the config file c was
+// loaded from (so --config moves it too), or next to DefaultPath
+func (c Config) ThemePath() (string, error) {
+	path := c.path
+	if path == "" {
+		var err error
+		if path, err = DefaultPath(); err != nil {
+			return "", err
+		}
+	}
+	return filepath.Join(filepath.Dir(path), ui.ThemeFileName), nil
+}
+
+// ApplyTheme loads theme.json on top of the configured theme and makes it
+// the active theme. Call it at startup, before anything is rendered.
+func (c Config) ApplyTheme() error {
+	path, err := c.ThemePath()
+	if err != nil {
+		return err
+	}
+	theme, err := ui.LoadTheme(path, c.Theme)
+	if err != nil {
+		return err
+	}
+	ui.SetTheme(theme)
+	return nil
+}
+
+// NewBridge creates a bridge using the configured adapters path, timeout,
+// runtime, cache TTL, and audit log, refreshing expired tokens from the
+// keychain and retrying rate limited calls. It fails if the adapters path
+// (or, when unset, every default location) holds no adapters.
+func (c Config) NewBridge() (*bridge.Bridge, error) {
+	adaptersPath, err := bridge.ResolveAdaptersPath(c.AdaptersPath)
+	if err != nil {
+		return nil, err
+	}
+
+	br := bridge.NewBridge(adaptersPath).WithAutoRefresh(keychain.RefreshStore{}).WithRetries()
+	if c.Timeout > 0 {
+		br.SetTimeout(c.Timeout)
+	}
+	if c.Runtime != "" {
+		br.SetRuntime(c.Runtime)
+	}
+	if c.CacheTTL != 0 {
+		br.SetCacheTTL(c.CacheTTL)
+	}
+	br.SetAuditLog(c.AuditLog)
+	return br, nil
+}
+
+// webhookOnce keeps repeated OpenState calls from posting each event twice;
+// fallbackOnce keeps the in-memory warning to one per run
+var webhookOnce, fallbackOnce sync.Once
+
+// OpenState opens the state database in the configured directory. The first
+// call also subscribes the configured webhook to migration events. If the
+// directory or database can't be written (e.g. a read-only mount), state is
+// kept in memory instead, with a warning on stderr that it won't be saved;
+// any other failure is returned.
+func (c Config) OpenState() (*state.DB, error) {
+	if c.WebhookURL != "" {
+		webhookOnce.Do(func() {
+			events.RegisterHandler(events.NewWebhookHandler(c.WebhookURL))
+		})
+	}
+
+	db, err := state.OpenOrInMemory(c.StateDir)
+	if err != nil {
+		return nil, err
+	}
+	if reason := db.Fallback(); reason != nil {
+		fallbackOnce.Do(func() {
+			fmt.Fprintln(os.Stderr, ui.Warning(fmt.Sprintf("State is in memory and won't be saved: %s", reason)))
+		})
+	}
+	return db, nil
+}
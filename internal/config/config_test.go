@@ -0,0 +1,161 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/johnhorton/deploy-tunnel/ui"
+)
+
+// writeConfig writes a config file to a temp dir and returns its path
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), FileName)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// envMap serves getenv from a map
+func envMap(env map[string]string) func(string) string {
+	return func(key string) string { return env[key] }
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	file := writeConfig(t, `{
+		"adapters_path": "/file/adapters",
+		"timeout": "45s",
+		"default_source": "vercel",
+		"default_target": "netlify",
+		"runtime": "/file/bun"
+	}`)
+
+	tests := []struct {
+		name      string
+		path      string
+		env       map[string]string
+		overrides Config
+		want      Config
+	}{
+		{
+			name: "defaults",
+			path: filepath.Join(t.TempDir(), "missing.json"),
+			want: Config{Timeout: 30 * time.Second, Runtime: "bun", CacheTTL: time.Minute},
+		},
+		{
+			name: "file over defaults",
+			path: file,
+			want: Config{AdaptersPath: "/file/adapters", Timeout: 45 * time.Second, DefaultSource: "vercel", DefaultTarget: "netlify", Runtime: "/file/bun", CacheTTL: time.Minute},
+		},
+		{
+			name: "env over file",
+			path: file,
+			env:  map[string]string{EnvTimeout: "2m", EnvDefaultTarget: "cloudflare", EnvCacheTTL: "0"},
+			want: Config{AdaptersPath: "/file/adapters", Timeout: 2 * time.Minute, DefaultSource: "vercel", DefaultTarget: "cloudflare", Runtime: "/file/bun", CacheTTL: CacheOff},
+		},
+		{
+			name:      "flags over env",
+			path:      file,
+			env:       map[string]string{EnvTimeout: "2m", EnvRuntime: "/env/bun"},
+			overrides: Config{Timeout: 10 * time.Second, AdaptersPath: "/flag/adapters"},
+			want:      Config{AdaptersPath: "/flag/adapters", Timeout: 10 * time.Second, DefaultSource: "vercel", DefaultTarget: "netlify", Runtime: "/env/bun", CacheTTL: time.Minute},
+		},
+		{
+			name: "adapters alias",
+			path: file,
+			env:  map[string]string{EnvAdapters: "/alias/adapters"},
+			want: Config{AdaptersPath: "/alias/adapters", Timeout: 45 * time.Second, DefaultSource: "vercel", DefaultTarget: "netlify", Runtime: "/file/bun", CacheTTL: time.Minute},
+		},
+		{
+			name: "adapters path beats its alias",
+			path: file,
+			env:  map[string]string{EnvAdapters: "/alias/adapters", EnvAdaptersPath: "/env/adapters"},
+			want: Config{AdaptersPath: "/env/adapters", Timeout: 45 * time.Second, DefaultSource: "vercel", DefaultTarget: "netlify", Runtime: "/file/bun", CacheTTL: time.Minute},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := load(tt.path, envMap(tt.env))
+			if err != nil {
+				t.Fatalf("load() error: %v", err)
+			}
+			cfg = cfg.Merge(tt.overrides)
+			cfg.path = ""
+			if cfg != tt.want {
+				t.Errorf("config = %+v\nwant     %+v", cfg, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		env     map[string]string
+		wantErr string
+	}{
+		{name: "malformed file", file: `{"timeout": "45s",`, wantErr: "invalid config"},
+		{name: "wrong type", file: `{"timeout": 45}`, wantErr: "invalid config"},
+		{name: "bad file timeout", file: `{"timeout": "soon"}`, wantErr: "timeout"},
+		{name: "negative file timeout", file: `{"timeout": "-5s"}`, wantErr: "must be positive"},
+		{name: "bad webhook", file: `{"webhook_url": "ftp://example.com"}`, wantErr: "webhook_url"},
+		{name: "unknown theme", file: `{"theme": "neon"}`, wantErr: "theme"},
+		{name: "bad env timeout", file: `{}`, env: map[string]string{EnvTimeout: "0s"}, wantErr: EnvTimeout},
+		{name: "negative env cache ttl", file: `{}`, env: map[string]string{EnvCacheTTL: "-1s"}, wantErr: EnvCacheTTL},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeConfig(t, tt.file)
+			_, err := load(path, envMap(tt.env))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("load() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestThemePathFollowsConfig(t *testing.T) {
+	path := writeConfig(t, `{}`)
+	cfg, err := load(path, envMap(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := cfg.ThemePath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Dir(got) != filepath.Dir(path) {
+		t.Errorf("ThemePath() = %s, want it next to %s", got, path)
+	}
+}
+
+func TestApplyThemeInvalidFile(t *testing.T) {
+	path := writeConfig(t, `{"theme": "light"}`)
+	cfg, err := load(path, envMap(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	themePath, err := cfg.ThemePath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(themePath, []byte(`{"coral": "orange"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	before := ui.ActiveTheme()
+	err = cfg.ApplyTheme()
+	if err == nil || !strings.Contains(err.Error(), "invalid theme") {
+		t.Errorf("ApplyTheme() error = %v, want an invalid theme error", err)
+	}
+	if got := ui.ActiveTheme(); got != before {
+		t.Errorf("ActiveTheme() = %+v after a failed ApplyTheme, want %+v kept", got, before)
+	}
+}
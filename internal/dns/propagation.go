@@ -0,0 +1,204 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/johnhorton/deploy-tunnel/internal/validate"
+)
+
+// DefaultResolvers are public resolvers queried when none are given
+var DefaultResolvers = []string{"1.1.1.1:53", "8.8.8.8:53", "9.9.9.9:53"}
+
+// pollInterval is how often WaitForPropagation re-queries pending resolvers
+var pollInterval = 5 * time.Second
+
+// Resolver answers lookups against a single DNS server
+type Resolver interface {
+	LookupHost(ctx context.Context, host string) ([]string, error)
+	LookupCNAME(ctx context.Context, host string) (string, error)
+	LookupTXT(ctx context.Context, host string) ([]string, error)
+}
+
+// newResolver returns a Resolver that queries addr; a var so a stub can stand in
+var newResolver = func(addr string) Resolver {
+	var d net.Dialer
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return d.DialContext(ctx, network, addr)
+		},
+	}
+}
+
+// PropagationError lists the resolvers that never returned the expected value
+type PropagationError struct {
+	Name     string
+	Expected string
+	// Pending maps each lagging resolver to what it last answered
+	Pending map[string]string
+	Total   int
+}
+
+func (e *PropagationError) Error() string {
+	resolvers := make([]string, 0, len(e.Pending))
+	for r := range e.Pending {
+		resolvers = append(resolvers, r)
+	}
+	sort.Strings(resolvers)
+
+	parts := make([]string, len(resolvers))
+	for i, r := range resolvers {
+		parts[i] = fmt.Sprintf("%s answered %s", r, e.Pending[r])
+	}
+	return fmt.Sprintf("%s not %s on %d of %d resolvers: %s",
+		e.Name, e.Expected, len(e.Pending), e.Total, strings.Join(parts, "; "))
+}
+
+// WaitForPropagation polls each resolver until every one returns
+// expectedValue for the record, or fails with a *PropagationError after
+// timeout. A CNAME also counts as propagated when the name resolves to the
+// same addresses as its target, since providers flatten CNAMEs at the apex.
+func WaitForPropagation(ctx context.Context, domain, recordType, recordName, expectedValue string, resolvers []string, timeout time.Duration) error {
+	if len(resolvers) == 0 {
+		resolvers = DefaultResolvers
+	}
+	name := FQDN(domain, recordName)
+	recordType = strings.ToUpper(recordType)
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	pending := make(map[string]string, len(resolvers))
+	clients := make(map[string]Resolver, len(resolvers))
+	for _, addr := range resolvers {
+		pending[addr] = "nothing"
+		clients[addr] = newResolver(addr)
+	}
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		for addr := range pending {
+			ok, answer := matches(ctx, clients[addr], recordType, name, expectedValue)
+			if ok {
+				delete(pending, addr)
+				continue
+			}
+			pending[addr] = answer
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return &PropagationError{Name: name, Expected: expectedValue, Pending: pending, Total: len(resolvers)}
+		case <-ticker.C:
+		}
+	}
+}
+
+// FQDN expands a record name relative to its zone; "@" and "" are the apex
+func FQDN(domain, recordName string) string {
+	domain = validate.NormalizeDomain(domain)
+	name := validate.NormalizeDomain(recordName)
+	switch {
+	case name == "" || name == "@" || name == domain:
+		return domain
+	case strings.HasSuffix(name, "."+domain):
+		return name
+	}
+	return name + "." + domain
+}
+
+// matches reports whether r answers expected for the record, along with a
+// summary of the answer for error messages
+func matches(ctx context.Context, r Resolver, recordType, name, expected string) (bool, string) {
+	switch recordType {
+	case "A", "AAAA":
+		addrs, err := r.LookupHost(ctx, name)
+		if err != nil {
+			return false, lookupError(err)
+		}
+		want := net.ParseIP(expected)
+		for _, a := range addrs {
+			if ip := net.ParseIP(a); ip != nil && want != nil && ip.Equal(want) {
+				return true, a
+			}
+		}
+		return false, answers(addrs)
+
+	case "CNAME":
+		want := validate.NormalizeDomain(expected)
+		cname, err := r.LookupCNAME(ctx, name)
+		if err == nil && validate.NormalizeDomain(cname) == want {
+			return true, cname
+		}
+
+		// Flattened: no CNAME is served, but the addresses match the target's
+		got, err := r.LookupHost(ctx, name)
+		if err != nil {
+			return false, lookupError(err)
+		}
+		target, err := r.LookupHost(ctx, want)
+		if err != nil {
+			return false, fmt.Sprintf("%s (target %s: %s)", answers(got), want, lookupError(err))
+		}
+		if overlaps(got, target) {
+			return true, answers(got)
+		}
+		if cname != "" && validate.NormalizeDomain(cname) != name {
+			return false, cname
+		}
+		return false, answers(got)
+
+	case "TXT":
+		records, err := r.LookupTXT(ctx, name)
+		if err != nil {
+			return false, lookupError(err)
+		}
+		for _, txt := range records {
+			if txt == expected {
+				return true, txt
+			}
+		}
+		return false, answers(records)
+	}
+
+	return false, fmt.Sprintf("unsupported record type %s", recordType)
+}
+
+// overlaps reports whether two address lists share an address
+func overlaps(a, b []string) bool {
+	seen := make(map[string]bool, len(a))
+	for _, addr := range a {
+		seen[addr] = true
+	}
+	for _, addr := range b {
+		if seen[addr] {
+			return true
+		}
+	}
+	return false
+}
+
+// answers summarizes a lookup result for error messages
+func answers(values []string) string {
+	if len(values) == 0 {
+		return "nothing"
+	}
+	return strings.Join(values, ", ")
+}
+
+func lookupError(err error) string {
+	if dnsErr, ok := err.(*net.DNSError); ok && dnsErr.IsNotFound {
+		return "NXDOMAIN"
+	}
+	return "error: " + err.Error()
+}
@@ -0,0 +1,127 @@
+package dns
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+// stubResolver answers from fixed tables; missing names are NXDOMAIN
+type stubResolver struct {
+	hosts  map[string][]string
+	cnames map[string]string
+	txts   map[string][]string
+}
+
+func notFound(host string) error {
+	return &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+}
+
+func (s stubResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	if addrs, ok := s.hosts[host]; ok {
+		return addrs, nil
+	}
+	return nil, notFound(host)
+}
+
+func (s stubResolver) LookupCNAME(ctx context.Context, host string) (string, error) {
+	if cname, ok := s.cnames[host]; ok {
+		return cname, nil
+	}
+	return "", notFound(host)
+}
+
+func (s stubResolver) LookupTXT(ctx context.Context, host string) ([]string, error) {
+	if txts, ok := s.txts[host]; ok {
+		return txts, nil
+	}
+	return nil, notFound(host)
+}
+
+// useResolvers makes WaitForPropagation query the stubs by address
+func useResolvers(t *testing.T, stubs map[string]stubResolver) {
+	t.Helper()
+	prevResolver, prevInterval := newResolver, pollInterval
+	newResolver = func(addr string) Resolver { return stubs[addr] }
+	pollInterval = time.Millisecond
+	t.Cleanup(func() { newResolver, pollInterval = prevResolver, prevInterval })
+}
+
+func TestWaitForPropagation(t *testing.T) {
+	updated := stubResolver{
+		hosts:  map[string][]string{"example.com": {"203.0.113.10"}, "app.example.dev": {"203.0.113.10"}},
+		cnames: map[string]string{"www.example.com": "app.example.dev."},
+		txts:   map[string][]string{"_verify.example.com": {"token=abc"}},
+	}
+	stale := stubResolver{
+		hosts: map[string][]string{"example.com": {"198.51.100.1"}, "app.example.dev": {"203.0.113.10"}},
+	}
+
+	tests := []struct {
+		name       string
+		resolvers  map[string]stubResolver
+		recordType string
+		recordName string
+		expected   string
+		pending    []string
+	}{
+		{name: "A everywhere", resolvers: map[string]stubResolver{"r1": updated, "r2": updated}, recordType: "A", recordName: "@", expected: "203.0.113.10"},
+		{name: "CNAME", resolvers: map[string]stubResolver{"r1": updated}, recordType: "CNAME", recordName: "www", expected: "app.example.dev"},
+		{name: "flattened CNAME at the apex", resolvers: map[string]stubResolver{"r1": updated}, recordType: "CNAME", recordName: "@", expected: "app.example.dev"},
+		{name: "TXT", resolvers: map[string]stubResolver{"r1": updated}, recordType: "txt", recordName: "_verify", expected: "token=abc"},
+		{name: "partial propagation", resolvers: map[string]stubResolver{"r1": updated, "r2": stale}, recordType: "A", recordName: "@", expected: "203.0.113.10", pending: []string{"r2"}},
+		{name: "not served anywhere", resolvers: map[string]stubResolver{"r1": stale}, recordType: "CNAME", recordName: "www", expected: "app.example.dev", pending: []string{"r1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useResolvers(t, tt.resolvers)
+			addrs := make([]string, 0, len(tt.resolvers))
+			for addr := range tt.resolvers {
+				addrs = append(addrs, addr)
+			}
+
+			err := WaitForPropagation(context.Background(), "example.com", tt.recordType, tt.recordName, tt.expected, addrs, 20*time.Millisecond)
+			if len(tt.pending) == 0 {
+				if err != nil {
+					t.Fatalf("WaitForPropagation: %v", err)
+				}
+				return
+			}
+
+			var propErr *PropagationError
+			if !errors.As(err, &propErr) {
+				t.Fatalf("error = %v, want a *PropagationError", err)
+			}
+			if len(propErr.Pending) != len(tt.pending) {
+				t.Errorf("pending = %v, want %v", propErr.Pending, tt.pending)
+			}
+			for _, addr := range tt.pending {
+				if _, ok := propErr.Pending[addr]; !ok {
+					t.Errorf("%s not reported as pending: %v", addr, propErr.Pending)
+				}
+			}
+			if propErr.Total != len(tt.resolvers) {
+				t.Errorf("total = %d, want %d", propErr.Total, len(tt.resolvers))
+			}
+		})
+	}
+}
+
+func TestFQDN(t *testing.T) {
+	tests := []struct {
+		domain, name, want string
+	}{
+		{"example.com", "@", "example.com"},
+		{"example.com", "", "example.com"},
+		{"Example.com.", "WWW", "www.example.com"},
+		{"example.com", "www.example.com.", "www.example.com"},
+	}
+	for _, tt := range tests {
+		if got := FQDN(tt.domain, tt.name); got != tt.want {
+			t.Errorf("FQDN(%q, %q) = %q, want %q", tt.domain, tt.name, got, tt.want)
+		}
+	}
+}
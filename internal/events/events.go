@@ -0,0 +1,133 @@
+// Package events publishes migration lifecycle changes to registered
+// handlers, e.g. to ping a webhook when a migration starts, fails, or
+// completes.
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// Event types
+const (
+	TypeMigrationCreated = "migration.created"
+	TypeStatusChanged    = "migration.status_changed"
+	TypeMigrationDeleted = "migration.deleted"
+)
+
+// MigrationEvent describes a migration being created, changing status, or
+// being deleted
+type MigrationEvent struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	Status    string    `json:"status"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Handler receives published events
+type Handler func(MigrationEvent)
+
+// queueSize is how many events can wait for delivery before new ones are dropped
+const queueSize = 64
+
+var (
+	mu       sync.RWMutex
+	handlers []Handler
+
+	startOnce sync.Once
+	queue     chan queued
+)
+
+// queued is an event waiting for delivery, or a Flush marker closed once
+// everything before it was delivered
+type queued struct {
+	event   MigrationEvent
+	flushed chan struct{}
+}
+
+// RegisterHandler subscribes h to every event published after this call
+func RegisterHandler(h Handler) {
+	mu.Lock()
+	handlers = append(handlers, h)
+	mu.Unlock()
+}
+
+// Publish queues e for every registered handler and returns at once, so a
+// slow webhook can't hold up the state write that published it. Handlers
+// run one event at a time, in publish order; call Flush before exiting so
+// queued events are delivered. When the queue is full the event is
+// dropped. A zero Timestamp is set to now.
+func Publish(e MigrationEvent) {
+	if e.Timestamp.IsZero() {
+		e.Timestamp = time.Now().UTC()
+	}
+
+	mu.RLock()
+	subscribed := len(handlers)
+	mu.RUnlock()
+	if subscribed == 0 {
+		return
+	}
+
+	select {
+	case start() <- queued{event: e}:
+	default:
+	}
+}
+
+// start launches the delivery goroutine on first use and returns its queue
+func start() chan queued {
+	startOnce.Do(func() {
+		queue = make(chan queued, queueSize)
+		go deliver()
+	})
+	return queue
+}
+
+// deliver calls every registered handler with each queued event, in
+// registration order
+func deliver() {
+	for q := range queue {
+		if q.flushed != nil {
+			close(q.flushed)
+			continue
+		}
+
+		mu.RLock()
+		subscribed := make([]Handler, len(handlers))
+		copy(subscribed, handlers)
+		mu.RUnlock()
+
+		for _, h := range subscribed {
+			h(q.event)
+		}
+	}
+}
+
+// Flush waits up to timeout for the events published so far to be
+// delivered and reports whether they were
+func Flush(timeout time.Duration) bool {
+	mu.RLock()
+	subscribed := len(handlers)
+	mu.RUnlock()
+	if subscribed == 0 {
+		return true
+	}
+
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+
+	flushed := make(chan struct{})
+	select {
+	case start() <- queued{flushed: flushed}:
+	case <-deadline.C:
+		return false
+	}
+
+	select {
+	case <-flushed:
+		return true
+	case <-deadline.C:
+		return false
+	}
+}
@@ -0,0 +1,82 @@
+package events
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// subscribe replaces the registered handlers with h for one test
+func subscribe(t *testing.T, h Handler) {
+	t.Helper()
+	mu.Lock()
+	handlers = []Handler{h}
+	mu.Unlock()
+	t.Cleanup(func() {
+		Flush(time.Second)
+		mu.Lock()
+		handlers = nil
+		mu.Unlock()
+	})
+}
+
+func TestPublishDoesNotWaitForHandlers(t *testing.T) {
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var got []string
+	subscribe(t, func(e MigrationEvent) {
+		<-release
+		mu.Lock()
+		got = append(got, e.Status)
+		mu.Unlock()
+	})
+
+	started := time.Now()
+	for _, status := range []string{"pending", "in_progress", "completed"} {
+		Publish(MigrationEvent{ID: "m1", Type: TypeStatusChanged, Status: status})
+	}
+	if elapsed := time.Since(started); elapsed > 100*time.Millisecond {
+		t.Errorf("Publish blocked for %s on a slow handler", elapsed)
+	}
+
+	if Flush(20 * time.Millisecond) {
+		t.Error("Flush reported delivery while the handler was blocked")
+	}
+
+	close(release)
+	if !Flush(time.Second) {
+		t.Fatal("Flush timed out after the handler was released")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []string{"pending", "in_progress", "completed"}
+	if len(got) != len(want) {
+		t.Fatalf("delivered %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("delivered %v, want %v in publish order", got, want)
+			break
+		}
+	}
+}
+
+func TestPublishSetsTimestamp(t *testing.T) {
+	delivered := make(chan MigrationEvent, 1)
+	subscribe(t, func(e MigrationEvent) { delivered <- e })
+
+	Publish(MigrationEvent{ID: "m1", Type: TypeMigrationCreated, Status: "pending"})
+	if !Flush(time.Second) {
+		t.Fatal("Flush timed out")
+	}
+	if e := <-delivered; e.Timestamp.IsZero() {
+		t.Error("Timestamp not set")
+	}
+}
+
+func TestFlushWithoutHandlers(t *testing.T) {
+	if !Flush(0) {
+		t.Error("Flush with no handlers reported undelivered events")
+	}
+}
@@ -0,0 +1,80 @@
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	webhookTimeout  = 10 * time.Second
+	webhookAttempts = 3
+)
+
+// webhookBackoff is the wait before the second attempt, doubled after each
+// failure
+var webhookBackoff = time.Second
+
+// NewWebhookHandler returns a handler that POSTs each event as JSON to url,
+// retrying network errors, 429s, and 5xx responses. Delivery failures are
+// dropped so a broken webhook never fails a migration.
+func NewWebhookHandler(url string) Handler {
+	client := &http.Client{Timeout: webhookTimeout}
+	return func(e MigrationEvent) {
+		postEvent(client, url, e)
+	}
+}
+
+// postEvent delivers e, returning the last error once attempts run out
+func postEvent(client *http.Client, url string, e MigrationEvent) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	wait := webhookBackoff
+	for attempt := 1; ; attempt++ {
+		err = post(client, url, body)
+		if err == nil || attempt == webhookAttempts {
+			return err
+		}
+		if _, permanent := err.(permanentError); permanent {
+			return err
+		}
+		time.Sleep(wait)
+		wait *= 2
+	}
+}
+
+// permanentError is a failure that retrying won't fix
+type permanentError struct{ err error }
+
+func (e permanentError) Error() string {
+	return e.err.Error()
+}
+
+func post(client *http.Client, url string, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return permanentError{fmt.Errorf("invalid webhook URL: %w", err)}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "deploy-tunnel")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post webhook: %w", err)
+	}
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return fmt.Errorf("webhook returned %d", resp.StatusCode)
+	default:
+		return permanentError{fmt.Errorf("webhook returned %d", resp.StatusCode)}
+	}
+}
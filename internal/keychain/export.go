@@ -0,0 +1,66 @@
+package keychain
+
+import (
+	"strings"
+	"time"
+)
+
+// ExportedCred describes stored credentials without revealing the token,
+// for sharing in support bundles
+type ExportedCred struct {
+	Provider  string     `json:"provider"`
+	Account   string     `json:"account,omitempty"`
+	Masked    string     `json:"masked"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// ExportMasked lists every stored credential with its token masked. It reads
+// only the providers index and each token's metadata, never the token itself.
+func ExportMasked() ([]ExportedCred, error) {
+	providers, err := List()
+	if err != nil {
+		return nil, err
+	}
+
+	creds := make([]ExportedCred, 0, len(providers))
+	for _, provider := range providers {
+		// Tokens stored without metadata have no recorded mask
+		cred := ExportedCred{Provider: provider, Masked: "(unknown)"}
+
+		meta, err := GetMeta(provider)
+		if err != nil {
+			// Listed but unreadable; report it rather than failing the export
+			cred.Masked = "(unavailable)"
+		} else if meta != nil {
+			if meta.Masked != "" {
+				cred.Masked = meta.Masked
+			}
+			cred.Account = meta.Label
+			if !meta.CreatedAt.IsZero() {
+				created := meta.CreatedAt
+				cred.CreatedAt = &created
+			}
+			cred.ExpiresAt = meta.ExpiresAt
+		}
+		creds = append(creds, cred)
+	}
+	return creds, nil
+}
+
+// MaskToken keeps a recognizable prefix (up to the first "_", e.g. "ghp_")
+// and the last 4 characters, e.g. "ghp_****abcd". Tokens too short to show
+// any of that safely are fully masked.
+func MaskToken(token string) string {
+	const tail = 4
+
+	prefix := ""
+	if i := strings.Index(token, "_"); i >= 0 && i <= 8 {
+		prefix = token[:i+1]
+	}
+	// Require enough hidden characters that the mask means something
+	if len(token)-len(prefix) < 3*tail {
+		return prefix + "****"
+	}
+	return prefix + "****" + token[len(token)-tail:]
+}
@@ -0,0 +1,92 @@
+package keychain
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+// tokenGuard fails the test if a token entry is read
+type tokenGuard struct {
+	backend
+	t *testing.T
+}
+
+func (g tokenGuard) Get(key string) (string, error) {
+	if strings.HasSuffix(key, "-token") {
+		g.t.Errorf("read %s while exporting", key)
+	}
+	return g.backend.Get(key)
+}
+
+func TestExportMasked(t *testing.T) {
+	useBackend(t, "", nil)
+	const (
+		vercelToken = "vcp_9f8e7d6c5b4a39281706abcd"
+		renderToken = "rnd_0123456789abcdefwxyz"
+	)
+	created := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	if err := StoreWithMeta("vercel", vercelToken, TokenMeta{CreatedAt: created, Label: "work"}); err != nil {
+		t.Fatal(err)
+	}
+	// Stored before metadata existed
+	if err := Store("render", renderToken); err != nil {
+		t.Fatal(err)
+	}
+	active = tokenGuard{backend: store(), t: t}
+
+	creds, err := ExportMasked()
+	if err != nil {
+		t.Fatalf("ExportMasked: %v", err)
+	}
+	want := []ExportedCred{
+		{Provider: "vercel", Account: "work", Masked: "vcp_****abcd", CreatedAt: &created},
+		{Provider: "render", Masked: "(unknown)"},
+	}
+	if len(creds) != len(want) {
+		t.Fatalf("ExportMasked = %+v, want %d credentials", creds, len(want))
+	}
+	for i, got := range creds {
+		w := want[i]
+		if got.Provider != w.Provider || got.Account != w.Account || got.Masked != w.Masked {
+			t.Errorf("creds[%d] = %+v, want %+v", i, got, w)
+		}
+		if (got.CreatedAt == nil) != (w.CreatedAt == nil) || (got.CreatedAt != nil && !got.CreatedAt.Equal(*w.CreatedAt)) {
+			t.Errorf("creds[%d].CreatedAt = %v, want %v", i, got.CreatedAt, w.CreatedAt)
+		}
+	}
+
+	out, err := json.Marshal(creds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, token := range []string{vercelToken, renderToken} {
+		if strings.Contains(string(out), token) {
+			t.Errorf("export contains the full token %q:\n%s", token, out)
+		}
+	}
+}
+
+func TestMaskToken(t *testing.T) {
+	tests := []struct {
+		token string
+		want  string
+	}{
+		{token: "ghp_0123456789abcdefwxyz", want: "ghp_****wxyz"},
+		{token: "0123456789abcdefwxyz", want: "****wxyz"},
+		// Too short to show the last 4 without giving most of it away
+		{token: "ghp_0123abcd", want: "ghp_****"},
+		{token: "short", want: "****"},
+		// An underscore late in the token isn't a prefix
+		{token: "abcdefghijkl_0123456789", want: "****6789"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.token, func(t *testing.T) {
+			if got := MaskToken(tt.token); got != tt.want {
+				t.Errorf("MaskToken(%q) = %q, want %q", tt.token, got, tt.want)
+			}
+		})
+	}
+}
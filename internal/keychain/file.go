@@ -0,0 +1,199 @@
+package keychain
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/zalando/go-keyring"
+)
+
+const (
+	credentialsFileName = "credentials.enc"
+	credentialsKeyName  = "credentials.key"
+
+	// EnvKey supplies the file backend's encryption key (any string; it is hashed)
+	EnvKey = "DEPLOY_TUNNEL_KEY"
+)
+
+// fileBackend stores entries as an AES-GCM encrypted JSON object in the
+// config dir, for headless machines with no secret service
+type fileBackend struct {
+	dir string
+
+	mu sync.Mutex
+}
+
+func newFileBackend(dir string) *fileBackend {
+	return &fileBackend{dir: dir}
+}
+
+func (f *fileBackend) Set(key, value string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	entries, err := f.load()
+	if err != nil {
+		return err
+	}
+	entries[key] = value
+	return f.save(entries)
+}
+
+func (f *fileBackend) Get(key string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	entries, err := f.load()
+	if err != nil {
+		return "", err
+	}
+	value, ok := entries[key]
+	if !ok {
+		return "", keyring.ErrNotFound
+	}
+	return value, nil
+}
+
+func (f *fileBackend) Delete(key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	entries, err := f.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := entries[key]; !ok {
+		return keyring.ErrNotFound
+	}
+	delete(entries, key)
+	return f.save(entries)
+}
+
+// load decrypts the credentials file; a missing file is an empty store
+func (f *fileBackend) load() (map[string]string, error) {
+	entries := map[string]string{}
+
+	data, err := os.ReadFile(filepath.Join(f.dir, credentialsFileName))
+	if os.IsNotExist(err) {
+		return entries, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read credentials file: %w", err)
+	}
+
+	gcm, err := f.cipher()
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, fmt.Errorf("credentials file is corrupt")
+	}
+	nonce, sealed := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	plain, err := gcm.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt credentials file (wrong %s?): %w", EnvKey, err)
+	}
+
+	if err := json.Unmarshal(plain, &entries); err != nil {
+		return nil, fmt.Errorf("credentials file is corrupt: %w", err)
+	}
+	return entries, nil
+}
+
+// save encrypts entries and atomically replaces the credentials file
+func (f *fileBackend) save(entries map[string]string) error {
+	plain, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+
+	gcm, err := f.cipher()
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	data := gcm.Seal(nonce, nonce, plain, nil)
+
+	if err := os.MkdirAll(f.dir, 0700); err != nil {
+		return fmt.Errorf("failed to create config dir: %w", err)
+	}
+	tmp, err := os.CreateTemp(f.dir, credentialsFileName+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write credentials file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write credentials file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write credentials file: %w", err)
+	}
+	// CreateTemp already uses 0600, but be explicit about the contract
+	if err := os.Chmod(tmp.Name(), 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(f.dir, credentialsFileName)); err != nil {
+		return fmt.Errorf("failed to write credentials file: %w", err)
+	}
+	return nil
+}
+
+func (f *fileBackend) cipher() (cipher.AEAD, error) {
+	key, err := f.key()
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// key derives the AES-256 key from DEPLOY_TUNNEL_KEY, or else reads (and on
+// first use generates) a random key file readable only by the user
+func (f *fileBackend) key() ([]byte, error) {
+	if secret := os.Getenv(EnvKey); secret != "" {
+		sum := sha256.Sum256([]byte(secret))
+		return sum[:], nil
+	}
+
+	path := filepath.Join(f.dir, credentialsKeyName)
+	encoded, err := os.ReadFile(path)
+	if err == nil {
+		key, err := base64.StdEncoding.DecodeString(string(encoded))
+		if err != nil || len(key) != 32 {
+			return nil, fmt.Errorf("credentials key file %s is corrupt", path)
+		}
+		return key, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read credentials key: %w", err)
+	}
+
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate credentials key: %w", err)
+	}
+	if err := os.MkdirAll(f.dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create config dir: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(base64.StdEncoding.EncodeToString(key)), 0600); err != nil {
+		return nil, fmt.Errorf("failed to write credentials key: %w", err)
+	}
+	return key, nil
+}
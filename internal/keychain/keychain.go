@@ -1,64 +1,392 @@
 package keychain
 
 import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
 
+	"github.com/johnhorton/deploy-tunnel/internal/redact"
 	"github.com/zalando/go-keyring"
 )
 
 const (
 	serviceName = "deploy-tunnel"
+	dbKeyName   = "db-encryption"
+	probeName   = "availability-probe"
+	indexName   = "providers-index"
 )
 
-// Store stores a credential in the system keychain
+// legacyProviders are probed once to build the index for tokens stored
+// before it existed
+var legacyProviders = []string{"vercel", "cloudflare", "render", "netlify"}
+
+// EnvBackend forces a backend: "system" or "file"
+const EnvBackend = "DEPLOY_TUNNEL_KEYCHAIN"
+
+// Backend names reported by BackendName
+const (
+	BackendSystem = "system"
+	BackendFile   = "file"
+)
+
+var (
+	availableOnce sync.Once
+	available     bool
+
+	backendOnce sync.Once
+	active      backend
+	activeName  string
+
+	dirMu sync.Mutex
+	dir   string
+)
+
+// systemMarkerName records in the state dir that credentials have been
+// written to the system keychain
+const systemMarkerName = "keychain-system"
+
+// backend is where entries live. Both implementations report missing
+// entries as keyring.ErrNotFound.
+type backend interface {
+	Set(key, value string) error
+	Get(key string) (string, error)
+	Delete(key string) error
+}
+
+// systemBackend is the OS keychain via go-keyring. The first write leaves a
+// marker so a later session never silently falls back to the file.
+type systemBackend struct {
+	marker string
+}
+
+func (s systemBackend) Set(key, value string) error {
+	if err := keyring.Set(serviceName, key, value); err != nil {
+		return err
+	}
+	if _, err := os.Stat(s.marker); os.IsNotExist(err) {
+		if err := os.MkdirAll(filepath.Dir(s.marker), 0700); err == nil {
+			os.WriteFile(s.marker, nil, 0600)
+		}
+	}
+	return nil
+}
+
+func (systemBackend) Get(key string) (string, error) { return keyring.Get(serviceName, key) }
+func (systemBackend) Delete(key string) error        { return keyring.Delete(serviceName, key) }
+
+// unavailableBackend fails every call; it stands in for a system keychain
+// that already holds credentials but can't be reached right now
+type unavailableBackend struct{}
+
+func (unavailableBackend) Set(key, value string) error    { return errSystemUnavailable() }
+func (unavailableBackend) Get(key string) (string, error) { return "", errSystemUnavailable() }
+func (unavailableBackend) Delete(key string) error        { return errSystemUnavailable() }
+
+func errSystemUnavailable() error {
+	return fmt.Errorf("the system keychain is unavailable, but credentials were stored there; unlock it and retry, or set %s=%s to start over with the encrypted file", EnvBackend, BackendFile)
+}
+
+// SetDir sets the state dir that holds the encrypted credentials file. It
+// must be called before the first keychain operation; "" means ~/.deploy-tunnel.
+func SetDir(stateDir string) {
+	dirMu.Lock()
+	defer dirMu.Unlock()
+	dir = stateDir
+}
+
+// Dir returns the state dir holding the encrypted credentials file
+func Dir() string {
+	return stateDir()
+}
+
+// stateDir returns the configured state dir, defaulting like the state package
+func stateDir() string {
+	dirMu.Lock()
+	defer dirMu.Unlock()
+	if dir != "" {
+		return dir
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(home, ".deploy-tunnel")
+	}
+	return ".deploy-tunnel"
+}
+
+// store returns the active backend: the one named by DEPLOY_TUNNEL_KEYCHAIN,
+// else the system keychain when it works. The encrypted file is only a
+// fallback while nothing has ever been written to the system keychain, so a
+// briefly locked keychain can't hide the stored credentials and database key.
+func store() backend {
+	backendOnce.Do(func() {
+		switch os.Getenv(EnvBackend) {
+		case BackendFile:
+			active, activeName = fileStore(), BackendFile
+		case BackendSystem:
+			active, activeName = systemStore(), BackendSystem
+		default:
+			switch {
+			case Available():
+				active, activeName = systemStore(), BackendSystem
+			case systemUsed():
+				active, activeName = unavailableBackend{}, BackendSystem
+			default:
+				active, activeName = fileStore(), BackendFile
+			}
+		}
+	})
+	return active
+}
+
+func systemStore() backend {
+	return systemBackend{marker: filepath.Join(stateDir(), systemMarkerName)}
+}
+
+// fileStore keeps the encrypted file in the state dir, next to the database
+func fileStore() backend {
+	return newFileBackend(stateDir())
+}
+
+// systemUsed reports whether credentials were ever written to the system
+// keychain, which leaves a marker in the state dir
+func systemUsed() bool {
+	return exists(filepath.Join(stateDir(), systemMarkerName))
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// BackendName reports which backend holds credentials: BackendSystem or BackendFile
+func BackendName() string {
+	store()
+	return activeName
+}
+
+func tokenKey(provider string) string {
+	return fmt.Sprintf("%s-token", provider)
+}
+
+func refreshKey(provider string) string {
+	return fmt.Sprintf("%s-refresh-token", provider)
+}
+
+// Store stores a credential in the keychain. Any metadata from a previous
+// token is dropped; use StoreWithMeta to record it.
 func Store(provider, token string) error {
-	key := fmt.Sprintf("%s-token", provider)
-	return keyring.Set(serviceName, key, token)
+	redact.Register(token)
+	key := tokenKey(provider)
+	if err := store().Set(key, token); err != nil {
+		return err
+	}
+	if err := deleteMeta(provider); err != nil {
+		return err
+	}
+	return updateIndex(func(providers []string) []string {
+		for _, p := range providers {
+			if p == provider {
+				return providers
+			}
+		}
+		return append(providers, provider)
+	})
 }
 
-// Get retrieves a credential from the system keychain
+// Get retrieves a credential from the keychain
 func Get(provider string) (string, error) {
-	key := fmt.Sprintf("%s-token", provider)
-	token, err := keyring.Get(serviceName, key)
+	key := tokenKey(provider)
+	token, err := store().Get(key)
 	if err == keyring.ErrNotFound {
 		return "", fmt.Errorf("no credentials found for %s", provider)
 	}
+	redact.Register(token)
 	return token, err
 }
 
-// Delete removes a credential from the system keychain
+// Exists reports whether credentials are stored for provider. Unlike probing
+// with Get, an error means presence couldn't be determined (e.g. a locked
+// keyring), not that the credentials are missing.
+func Exists(provider string) (bool, error) {
+	_, err := store().Get(tokenKey(provider))
+	if err == keyring.ErrNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Delete removes a credential from the keychain
 func Delete(provider string) error {
-	key := fmt.Sprintf("%s-token", provider)
-	return keyring.Delete(serviceName, key)
+	key := tokenKey(provider)
+	err := store().Delete(key)
+	if err != nil && err != keyring.ErrNotFound {
+		return err
+	}
+	if err := deleteMeta(provider); err != nil {
+		return err
+	}
+
+	// Drop the index entry even if the token was already gone
+	if indexErr := updateIndex(func(providers []string) []string {
+		kept := providers[:0]
+		for _, p := range providers {
+			if p != provider {
+				kept = append(kept, p)
+			}
+		}
+		return kept
+	}); indexErr != nil {
+		return indexErr
+	}
+	return err
+}
+
+// Purge removes everything stored for a provider: the credential, its
+// metadata, and its refresh token
+func Purge(provider string) error {
+	if err := store().Delete(refreshKey(provider)); err != nil && err != keyring.ErrNotFound {
+		return fmt.Errorf("failed to delete refresh token: %w", err)
+	}
+	err := Delete(provider)
+	if err == keyring.ErrNotFound {
+		return nil
+	}
+	return err
+}
+
+// Available reports whether the system keychain can store credentials.
+// When it can't, credentials fall back to the encrypted file.
+// It round-trips a sentinel entry once and caches the result for the session.
+func Available() bool {
+	availableOnce.Do(func() {
+		available = probe()
+	})
+	return available
 }
 
-// List returns all stored provider keys
+func probe() bool {
+	if err := keyring.Set(serviceName, probeName, "ok"); err != nil {
+		return false
+	}
+	defer keyring.Delete(serviceName, probeName)
+
+	value, err := keyring.Get(serviceName, probeName)
+	return err == nil && value == "ok"
+}
+
+// List returns the providers with stored credentials, in the order they
+// were first stored
 func List() ([]string, error) {
-	// Note: keyring doesn't provide a list function, so we'll try common providers
-	providers := []string{"vercel", "cloudflare", "render", "netlify"}
-	var found []string
+	indexMu.Lock()
+	defer indexMu.Unlock()
+
+	providers, err := readIndex()
+	if err == keyring.ErrNotFound {
+		return migrateIndex()
+	}
+	return providers, err
+}
 
-	for _, provider := range providers {
-		if _, err := Get(provider); err == nil {
+// indexMu serializes read-modify-write updates of the providers index
+var indexMu sync.Mutex
+
+// readIndex returns the providers index, or keyring.ErrNotFound if it was never written
+func readIndex() ([]string, error) {
+	encoded, err := store().Get(indexName)
+	if err != nil {
+		return nil, err
+	}
+	var providers []string
+	if err := json.Unmarshal([]byte(encoded), &providers); err != nil {
+		return nil, fmt.Errorf("providers index is corrupt: %w", err)
+	}
+	return providers, nil
+}
+
+func writeIndex(providers []string) error {
+	encoded, err := json.Marshal(providers)
+	if err != nil {
+		return err
+	}
+	if err := store().Set(indexName, string(encoded)); err != nil {
+		return fmt.Errorf("failed to update providers index: %w", err)
+	}
+	return nil
+}
+
+// updateIndex applies change to the index, building it from legacy entries first if needed
+func updateIndex(change func([]string) []string) error {
+	indexMu.Lock()
+	defer indexMu.Unlock()
+
+	providers, err := readIndex()
+	if err == keyring.ErrNotFound {
+		providers, err = migrateIndex()
+	}
+	if err != nil {
+		return err
+	}
+	return writeIndex(change(providers))
+}
+
+// migrateIndex builds the index by probing the providers known before it existed
+func migrateIndex() ([]string, error) {
+	var found []string
+	for _, provider := range legacyProviders {
+		if _, err := store().Get(tokenKey(provider)); err == nil {
 			found = append(found, provider)
 		}
 	}
-
+	if err := writeIndex(found); err != nil {
+		return nil, err
+	}
 	return found, nil
 }
 
 // StoreRefreshToken stores a refresh token
 func StoreRefreshToken(provider, token string) error {
-	key := fmt.Sprintf("%s-refresh-token", provider)
-	return keyring.Set(serviceName, key, token)
+	redact.Register(token)
+	key := refreshKey(provider)
+	return store().Set(key, token)
 }
 
 // GetRefreshToken retrieves a refresh token
 func GetRefreshToken(provider string) (string, error) {
-	key := fmt.Sprintf("%s-refresh-token", provider)
-	token, err := keyring.Get(serviceName, key)
+	key := refreshKey(provider)
+	token, err := store().Get(key)
 	if err == keyring.ErrNotFound {
 		return "", fmt.Errorf("no refresh token found for %s", provider)
 	}
+	redact.Register(token)
 	return token, err
 }
+
+// GetOrCreateDBKey returns the state database encryption key, generating and
+// storing a random 32-byte key on first use
+func GetOrCreateDBKey() ([]byte, error) {
+	encoded, err := store().Get(dbKeyName)
+	if err == nil {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("stored database key is corrupt: %w", err)
+		}
+		return key, nil
+	}
+	if err != keyring.ErrNotFound {
+		return nil, err
+	}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate database key: %w", err)
+	}
+	if err := store().Set(dbKeyName, base64.StdEncoding.EncodeToString(key)); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
@@ -0,0 +1,300 @@
+package keychain
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/zalando/go-keyring"
+)
+
+// useBackend resets the cached backend choice and points the keychain at a
+// fresh state dir. systemErr nil mocks a working system keychain.
+func useBackend(t *testing.T, forced string, systemErr error) string {
+	t.Helper()
+
+	if systemErr == nil {
+		keyring.MockInit()
+	} else {
+		keyring.MockInitWithError(systemErr)
+	}
+	t.Setenv(EnvBackend, forced)
+	t.Setenv(EnvKey, "")
+
+	availableOnce, available = sync.Once{}, false
+	backendOnce, active, activeName = sync.Once{}, nil, ""
+
+	dir := t.TempDir()
+	SetDir(dir)
+	t.Cleanup(func() { SetDir("") })
+	return dir
+}
+
+func touch(t *testing.T, path string) {
+	t.Helper()
+	if err := os.WriteFile(path, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFileBackendRoundTrip(t *testing.T) {
+	dir := useBackend(t, BackendFile, nil)
+
+	if err := Store("vercel", "tok_vercel_123"); err != nil {
+		t.Fatalf("Store: %v", err)
+	}
+	if err := Store("netlify", "tok_netlify_456"); err != nil {
+		t.Fatalf("Store: %v", err)
+	}
+
+	token, err := Get("vercel")
+	if err != nil || token != "tok_vercel_123" {
+		t.Fatalf("Get = %q, %v; want tok_vercel_123", token, err)
+	}
+	providers, err := List()
+	if err != nil || len(providers) != 2 {
+		t.Fatalf("List = %v, %v; want two providers", providers, err)
+	}
+
+	info, err := os.Stat(filepath.Join(dir, credentialsFileName))
+	if err != nil {
+		t.Fatalf("credentials file not in state dir: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("credentials file mode = %o, want 600", perm)
+	}
+
+	if err := Delete("vercel"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if ok, err := Exists("vercel"); err != nil || ok {
+		t.Errorf("Exists after Delete = %v, %v; want false", ok, err)
+	}
+	if token, err := Get("netlify"); err != nil || token != "tok_netlify_456" {
+		t.Errorf("Get(netlify) = %q, %v after deleting vercel", token, err)
+	}
+}
+
+func TestFileBackendWrongKey(t *testing.T) {
+	useBackend(t, BackendFile, nil)
+	t.Setenv(EnvKey, "first")
+	if err := Store("vercel", "tok_vercel_123"); err != nil {
+		t.Fatalf("Store: %v", err)
+	}
+
+	t.Setenv(EnvKey, "second")
+	if _, err := Get("vercel"); err == nil {
+		t.Error("Get with a different DEPLOY_TUNNEL_KEY succeeded")
+	}
+}
+
+func TestAvailable(t *testing.T) {
+	t.Run("working keychain", func(t *testing.T) {
+		useBackend(t, "", nil)
+		if !Available() {
+			t.Error("Available() = false with a working keychain")
+		}
+		// The sentinel is cleaned up after the probe
+		if _, err := keyring.Get(serviceName, probeName); !errors.Is(err, keyring.ErrNotFound) {
+			t.Errorf("probe entry left behind: %v", err)
+		}
+	})
+
+	t.Run("locked keychain", func(t *testing.T) {
+		useBackend(t, "", errors.New("keychain locked"))
+		if Available() {
+			t.Error("Available() = true with a failing keychain")
+		}
+	})
+
+	t.Run("result is cached", func(t *testing.T) {
+		useBackend(t, "", nil)
+		if !Available() {
+			t.Fatal("Available() = false with a working keychain")
+		}
+		keyring.MockInitWithError(errors.New("keychain locked"))
+		if !Available() {
+			t.Error("Available() probed again instead of using the cached result")
+		}
+	})
+}
+
+func TestBackendSelection(t *testing.T) {
+	locked := errors.New("keychain locked")
+
+	tests := []struct {
+		name      string
+		systemErr error
+		files     []string
+		backend   string
+		wantErr   bool
+	}{
+		{name: "system works", backend: BackendSystem},
+		{name: "fresh headless machine", systemErr: locked, backend: BackendFile},
+		{name: "file fallback already in use", systemErr: locked, files: []string{"state.db", credentialsFileName}, backend: BackendFile},
+		{name: "system used before", systemErr: locked, files: []string{systemMarkerName}, backend: BackendSystem, wantErr: true},
+		{name: "upgrade with a plaintext database", systemErr: locked, files: []string{"state.db"}, backend: BackendFile},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := useBackend(t, "", tt.systemErr)
+			for _, name := range tt.files {
+				if name == credentialsFileName {
+					if err := newFileBackend(dir).Set(indexName, "[]"); err != nil {
+						t.Fatal(err)
+					}
+					continue
+				}
+				touch(t, filepath.Join(dir, name))
+			}
+
+			if got := BackendName(); got != tt.backend {
+				t.Errorf("BackendName = %q, want %q", got, tt.backend)
+			}
+			_, err := Exists("vercel")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Exists error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSystemWriteLeavesMarker(t *testing.T) {
+	dir := useBackend(t, "", nil)
+	if err := Store("vercel", "tok_vercel_123"); err != nil {
+		t.Fatalf("Store: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, systemMarkerName)); err != nil {
+		t.Errorf("no marker after writing to the system keychain: %v", err)
+	}
+}
+
+func TestGetOrCreateDBKey(t *testing.T) {
+	locked := errors.New("keychain locked")
+
+	t.Run("mints and reuses", func(t *testing.T) {
+		useBackend(t, "", locked)
+		first, err := GetOrCreateDBKey()
+		if err != nil || len(first) != 32 {
+			t.Fatalf("GetOrCreateDBKey = %d bytes, %v", len(first), err)
+		}
+		second, err := GetOrCreateDBKey()
+		if err != nil || string(second) != string(first) {
+			t.Errorf("second call returned a different key (%v)", err)
+		}
+	})
+	t.Run("upgrade with a plaintext database", func(t *testing.T) {
+		// state.db from before encryption, and no keyring on this machine
+		dir := useBackend(t, "", locked)
+		touch(t, filepath.Join(dir, "state.db"))
+		key, err := GetOrCreateDBKey()
+		if err != nil || len(key) != 32 {
+			t.Fatalf("GetOrCreateDBKey = %d bytes, %v", len(key), err)
+		}
+		if got := BackendName(); got != BackendFile {
+			t.Errorf("BackendName = %q, want %q", got, BackendFile)
+		}
+		if _, err := os.Stat(filepath.Join(dir, credentialsFileName)); err != nil {
+			t.Errorf("key not stored in the credentials file: %v", err)
+		}
+	})
+}
+
+func TestListIndex(t *testing.T) {
+	t.Run("new providers appear in store order", func(t *testing.T) {
+		useBackend(t, "", nil)
+		for _, provider := range []string{"fly", "vercel", "railway"} {
+			if err := Store(provider, "tok_"+provider); err != nil {
+				t.Fatalf("Store(%s): %v", provider, err)
+			}
+		}
+		// Storing again doesn't duplicate or reorder
+		if err := Store("fly", "tok_fly_2"); err != nil {
+			t.Fatal(err)
+		}
+		if got, err := List(); err != nil || !slices.Equal(got, []string{"fly", "vercel", "railway"}) {
+			t.Errorf("List = %v, %v; want [fly vercel railway]", got, err)
+		}
+
+		if err := Delete("vercel"); err != nil {
+			t.Fatal(err)
+		}
+		if got, err := List(); err != nil || !slices.Equal(got, []string{"fly", "railway"}) {
+			t.Errorf("List after Delete = %v, %v; want [fly railway]", got, err)
+		}
+	})
+
+	t.Run("legacy entries are migrated", func(t *testing.T) {
+		useBackend(t, "", nil)
+		// Tokens stored before the index existed
+		for _, provider := range []string{"netlify", "vercel"} {
+			if err := keyring.Set(serviceName, tokenKey(provider), "tok_"+provider); err != nil {
+				t.Fatal(err)
+			}
+		}
+		got, err := List()
+		if err != nil || !slices.Equal(got, []string{"vercel", "netlify"}) {
+			t.Fatalf("List = %v, %v; want [vercel netlify]", got, err)
+		}
+		if _, err := readIndex(); err != nil {
+			t.Errorf("index not written by the migration: %v", err)
+		}
+
+		if err := Store("fly", "tok_fly"); err != nil {
+			t.Fatal(err)
+		}
+		if got, err := List(); err != nil || !slices.Equal(got, []string{"vercel", "netlify", "fly"}) {
+			t.Errorf("List = %v, %v; want [vercel netlify fly]", got, err)
+		}
+	})
+}
+
+// brokenBackend fails every operation, like a keyring that went away mid-session
+type brokenBackend struct{ err error }
+
+func (b brokenBackend) Set(key, value string) error    { return b.err }
+func (b brokenBackend) Get(key string) (string, error) { return "", b.err }
+func (b brokenBackend) Delete(key string) error        { return b.err }
+
+func TestExists(t *testing.T) {
+	locked := errors.New("keychain locked")
+
+	tests := []struct {
+		name    string
+		stored  bool
+		broken  bool
+		want    bool
+		wantErr bool
+	}{
+		{name: "found", stored: true, want: true},
+		{name: "not found"},
+		{name: "backend error", stored: true, broken: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useBackend(t, "", nil)
+			if tt.stored {
+				if err := Store("vercel", "tok_vercel"); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.broken {
+				store()
+				active = brokenBackend{err: locked}
+			}
+
+			got, err := Exists("vercel")
+			if got != tt.want || (err != nil) != tt.wantErr {
+				t.Errorf("Exists = %v, %v; want %v, error %v", got, err, tt.want, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, locked) {
+				t.Errorf("Exists error = %v, want the backend error", err)
+			}
+		})
+	}
+}
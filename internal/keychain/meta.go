@@ -0,0 +1,113 @@
+package keychain
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/zalando/go-keyring"
+)
+
+// TokenMeta describes a stored token. It lives in a companion entry next to
+// the token, so tokens stored before it existed simply have none.
+type TokenMeta struct {
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Label     string     `json:"label,omitempty"`
+	// Masked is the token as MaskToken shows it, recorded when it's stored
+	// so listing credentials never has to read the token itself
+	Masked string `json:"masked,omitempty"`
+}
+
+// Expired reports whether the token has a known expiry at or before now
+func (m TokenMeta) Expired(now time.Time) bool {
+	return m.ExpiresAt != nil && !m.ExpiresAt.After(now)
+}
+
+// Describe summarizes the metadata for display, e.g.
+// "work account · added Jan 2 2026 · expires Mar 1 2026"
+func (m TokenMeta) Describe(now time.Time) string {
+	var parts []string
+	if m.Label != "" {
+		parts = append(parts, m.Label)
+	}
+	if !m.CreatedAt.IsZero() {
+		parts = append(parts, "added "+m.CreatedAt.Local().Format("Jan 2 2006"))
+	}
+	if m.ExpiresAt != nil {
+		verb := "expires "
+		if m.Expired(now) {
+			verb = "expired "
+		}
+		parts = append(parts, verb+m.ExpiresAt.Local().Format("Jan 2 2006"))
+	}
+	return strings.Join(parts, " · ")
+}
+
+func metaKey(provider string) string {
+	return fmt.Sprintf("%s-meta", provider)
+}
+
+// StoreWithMeta stores a credential along with its metadata
+func StoreWithMeta(provider, token string, meta TokenMeta) error {
+	if meta.CreatedAt.IsZero() {
+		meta.CreatedAt = time.Now().UTC()
+	}
+	meta.Masked = MaskToken(token)
+	encoded, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+
+	if err := Store(provider, token); err != nil {
+		return err
+	}
+	if err := store().Set(metaKey(provider), string(encoded)); err != nil {
+		return fmt.Errorf("failed to store token metadata: %w", err)
+	}
+	return nil
+}
+
+// GetMeta returns a credential's metadata, or nil if none was stored
+// (tokens saved before metadata existed)
+func GetMeta(provider string) (*TokenMeta, error) {
+	encoded, err := store().Get(metaKey(provider))
+	if err == keyring.ErrNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var meta TokenMeta
+	if err := json.Unmarshal([]byte(encoded), &meta); err != nil {
+		return nil, fmt.Errorf("token metadata for %s is corrupt: %w", provider, err)
+	}
+	return &meta, nil
+}
+
+// deleteMeta removes a credential's metadata; a missing entry is fine
+func deleteMeta(provider string) error {
+	if err := store().Delete(metaKey(provider)); err != nil && err != keyring.ErrNotFound {
+		return err
+	}
+	return nil
+}
+
+// RefreshStore adapts the keychain for bridge.WithAutoRefresh
+type RefreshStore struct{}
+
+// GetRefreshToken returns the provider's stored refresh token
+func (RefreshStore) GetRefreshToken(provider string) (string, error) {
+	return GetRefreshToken(provider)
+}
+
+// StoreToken saves a refreshed token, keeping the existing label
+func (RefreshStore) StoreToken(provider, token string, expiresAt *time.Time) error {
+	meta := TokenMeta{CreatedAt: time.Now().UTC(), ExpiresAt: expiresAt}
+	if existing, err := GetMeta(provider); err == nil && existing != nil {
+		meta.Label = existing.Label
+	}
+	return StoreWithMeta(provider, token, meta)
+}
@@ -0,0 +1,93 @@
+package keychain
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetaRoundTrip(t *testing.T) {
+	useBackend(t, "", nil)
+	created := time.Date(2026, 1, 2, 15, 0, 0, 0, time.UTC)
+	expires := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	meta := TokenMeta{CreatedAt: created, ExpiresAt: &expires, Label: "work account"}
+	if err := StoreWithMeta("vercel", "tok_vercel_123", meta); err != nil {
+		t.Fatalf("StoreWithMeta: %v", err)
+	}
+	got, err := GetMeta("vercel")
+	if err != nil || got == nil {
+		t.Fatalf("GetMeta = %v, %v", got, err)
+	}
+	if !got.CreatedAt.Equal(created) || got.ExpiresAt == nil || !got.ExpiresAt.Equal(expires) || got.Label != "work account" {
+		t.Errorf("GetMeta = %+v, want %+v", got, meta)
+	}
+	if token, err := Get("vercel"); err != nil || token != "tok_vercel_123" {
+		t.Errorf("Get = %q, %v; want the token stored with the metadata", token, err)
+	}
+
+	// A plain Store replaces the token, so the old expiry no longer applies
+	if err := Store("vercel", "tok_vercel_456"); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := GetMeta("vercel"); err != nil || got != nil {
+		t.Errorf("GetMeta after Store = %+v, %v; want none", got, err)
+	}
+}
+
+func TestMetaDefaultsCreatedAt(t *testing.T) {
+	useBackend(t, "", nil)
+	before := time.Now().UTC()
+	if err := StoreWithMeta("netlify", "tok_netlify", TokenMeta{}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := GetMeta("netlify")
+	if err != nil || got == nil {
+		t.Fatalf("GetMeta = %v, %v", got, err)
+	}
+	if got.CreatedAt.Before(before) || got.ExpiresAt != nil {
+		t.Errorf("GetMeta = %+v, want CreatedAt set to now and no expiry", got)
+	}
+}
+
+func TestMetaLegacyToken(t *testing.T) {
+	useBackend(t, "", nil)
+	if err := Store("render", "tok_render"); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := GetMeta("render"); err != nil || got != nil {
+		t.Errorf("GetMeta for a token without metadata = %+v, %v; want nil, nil", got, err)
+	}
+}
+
+func TestMetaExpiry(t *testing.T) {
+	now := time.Date(2026, 2, 1, 12, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+	future := now.Add(24 * time.Hour)
+
+	tests := []struct {
+		name        string
+		meta        TokenMeta
+		wantExpired bool
+	}{
+		{name: "no expiry", meta: TokenMeta{CreatedAt: now}},
+		{name: "expires later", meta: TokenMeta{ExpiresAt: &future}},
+		{name: "expired", meta: TokenMeta{ExpiresAt: &past}, wantExpired: true},
+		{name: "expires now", meta: TokenMeta{ExpiresAt: &now}, wantExpired: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.meta.Expired(now); got != tt.wantExpired {
+				t.Errorf("Expired() = %v, want %v", got, tt.wantExpired)
+			}
+			verb := "expires "
+			if tt.wantExpired {
+				verb = "expired "
+			}
+			if described := tt.meta.Describe(now); tt.meta.ExpiresAt != nil && !strings.Contains(described, verb) {
+				t.Errorf("Describe() = %q, want it to say %q", described, verb)
+			}
+		})
+	}
+}
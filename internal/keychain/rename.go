@@ -0,0 +1,86 @@
+package keychain
+
+import (
+	"fmt"
+
+	"github.com/zalando/go-keyring"
+)
+
+// Rename moves a provider's token, metadata, and refresh token to a new
+// provider name. It is all or nothing: if any write or delete fails, the
+// entries are put back the way they were.
+func Rename(oldProvider, newProvider string) error {
+	if oldProvider == newProvider {
+		return nil
+	}
+	if _, err := store().Get(tokenKey(newProvider)); err == nil {
+		return fmt.Errorf("credentials already exist for %s", newProvider)
+	} else if err != keyring.ErrNotFound {
+		return err
+	}
+
+	// Collect the old entries; only the token is required
+	type entry struct{ oldKey, newKey, value string }
+	var entries []entry
+	for _, keys := range [][2]string{
+		{tokenKey(oldProvider), tokenKey(newProvider)},
+		{metaKey(oldProvider), metaKey(newProvider)},
+		{refreshKey(oldProvider), refreshKey(newProvider)},
+	} {
+		value, err := store().Get(keys[0])
+		if err == keyring.ErrNotFound {
+			if len(entries) == 0 {
+				return fmt.Errorf("no credentials found for %s", oldProvider)
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		entries = append(entries, entry{keys[0], keys[1], value})
+	}
+
+	// undo reverses completed steps, newest first
+	var undo []func()
+	rollback := func() {
+		for i := len(undo) - 1; i >= 0; i-- {
+			undo[i]()
+		}
+	}
+
+	for _, e := range entries {
+		if err := store().Set(e.newKey, e.value); err != nil {
+			rollback()
+			return fmt.Errorf("failed to write credentials for %s: %w", newProvider, err)
+		}
+		key := e.newKey
+		undo = append(undo, func() { store().Delete(key) })
+	}
+	for _, e := range entries {
+		if err := store().Delete(e.oldKey); err != nil {
+			rollback()
+			return fmt.Errorf("failed to remove credentials for %s: %w", oldProvider, err)
+		}
+		key, value := e.oldKey, e.value
+		undo = append(undo, func() { store().Set(key, value) })
+	}
+
+	if err := updateIndex(func(providers []string) []string {
+		renamed := make([]string, 0, len(providers)+1)
+		found := false
+		for _, p := range providers {
+			if p == oldProvider {
+				p, found = newProvider, true
+			}
+			renamed = append(renamed, p)
+		}
+		if !found {
+			renamed = append(renamed, newProvider)
+		}
+		return renamed
+	}); err != nil {
+		rollback()
+		return err
+	}
+	return nil
+}
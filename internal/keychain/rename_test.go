@@ -0,0 +1,105 @@
+package keychain
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/zalando/go-keyring"
+)
+
+// failingBackend fails writes to one key and passes everything else through
+type failingBackend struct {
+	backend
+	failKey string
+}
+
+func (b failingBackend) Set(key, value string) error {
+	if key == b.failKey {
+		return errors.New("keychain write refused")
+	}
+	return b.backend.Set(key, value)
+}
+
+// storeFull stores a token with metadata and a refresh token
+func storeFull(t *testing.T, provider string) {
+	t.Helper()
+	if err := StoreWithMeta(provider, "tok_"+provider, TokenMeta{CreatedAt: time.Now().UTC(), Label: "work"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := StoreRefreshToken(provider, "ref_"+provider); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRename(t *testing.T) {
+	useBackend(t, "", nil)
+	storeFull(t, "vercel")
+	storeFull(t, "netlify")
+
+	if err := Rename("vercel", "vercel-work"); err != nil {
+		t.Fatalf("Rename: %v", err)
+	}
+
+	if token, err := Get("vercel-work"); err != nil || token != "tok_vercel" {
+		t.Errorf("Get(vercel-work) = %q, %v; want the moved token", token, err)
+	}
+	if meta, err := GetMeta("vercel-work"); err != nil || meta == nil || meta.Label != "work" {
+		t.Errorf("GetMeta(vercel-work) = %+v, %v; want the moved metadata", meta, err)
+	}
+	if token, err := GetRefreshToken("vercel-work"); err != nil || token != "ref_vercel" {
+		t.Errorf("GetRefreshToken(vercel-work) = %q, %v; want the moved refresh token", token, err)
+	}
+	for _, key := range []string{tokenKey("vercel"), metaKey("vercel"), refreshKey("vercel")} {
+		if _, err := store().Get(key); err != keyring.ErrNotFound {
+			t.Errorf("%s still stored after the rename (%v)", key, err)
+		}
+	}
+	// The renamed provider keeps its place in the index
+	if got, err := List(); err != nil || !slices.Equal(got, []string{"vercel-work", "netlify"}) {
+		t.Errorf("List = %v, %v; want [vercel-work netlify]", got, err)
+	}
+}
+
+func TestRenameRefused(t *testing.T) {
+	useBackend(t, "", nil)
+	storeFull(t, "vercel")
+	storeFull(t, "netlify")
+
+	if err := Rename("vercel", "netlify"); err == nil {
+		t.Error("Rename onto an existing provider succeeded")
+	}
+	if err := Rename("render", "render-work"); err == nil {
+		t.Error("Rename of a provider without credentials succeeded")
+	}
+	if token, err := Get("netlify"); err != nil || token != "tok_netlify" {
+		t.Errorf("Get(netlify) = %q, %v; want it untouched", token, err)
+	}
+}
+
+func TestRenameRollsBack(t *testing.T) {
+	useBackend(t, "", nil)
+	storeFull(t, "vercel")
+	// The token and metadata copy, then the refresh token write fails
+	active = failingBackend{backend: store(), failKey: refreshKey("vercel-work")}
+
+	if err := Rename("vercel", "vercel-work"); err == nil {
+		t.Fatal("Rename succeeded although a write failed")
+	}
+
+	if token, err := Get("vercel"); err != nil || token != "tok_vercel" {
+		t.Errorf("Get(vercel) = %q, %v; want the old token kept", token, err)
+	}
+	if token, err := GetRefreshToken("vercel"); err != nil || token != "ref_vercel" {
+		t.Errorf("GetRefreshToken(vercel) = %q, %v; want it kept", token, err)
+	}
+	for _, key := range []string{tokenKey("vercel-work"), metaKey("vercel-work")} {
+		if _, err := store().Get(key); err != keyring.ErrNotFound {
+			t.Errorf("%s left behind by the failed rename (%v)", key, err)
+		}
+	}
+	if got, err := List(); err != nil || !slices.Equal(got, []string{"vercel"}) {
+		t.Errorf("List = %v, %v; want [vercel]", got, err)
+	}
+}
@@ -0,0 +1,86 @@
+// Package redact scrubs credentials from text before it's shown to the user
+// or written to the logs table.
+package redact
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Mask replaces every redacted value
+const Mask = "[REDACTED]"
+
+// minSecretLength keeps short values (which would match ordinary words)
+// from being registered as secrets
+const minSecretLength = 8
+
+var (
+	mu      sync.RWMutex
+	secrets = map[string]bool{}
+)
+
+// pattern is a token shape; repl keeps any surrounding context groups
+type pattern struct {
+	re   *regexp.Regexp
+	repl string
+}
+
+// secretFields are key names whose values are credentials
+const secretFields = `token|access_token|refresh_token|api_key|apikey|secret|client_secret|password`
+
+var patterns = []pattern{
+	// Authorization headers
+	{regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9._~+/=-]+`), "${1}" + Mask},
+	// JSON fields: "token": "..."
+	{regexp.MustCompile(`(?i)("(?:` + secretFields + `)"\s*:\s*")[^"]*(")`), "${1}" + Mask + "${2}"},
+	// Query strings and env assignments: token=...
+	{regexp.MustCompile(`(?i)\b((?:` + secretFields + `)=)[^\s&"']+`), "${1}" + Mask},
+	// JWTs
+	{regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+`), Mask},
+	// GitHub tokens
+	{regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{20,}`), Mask},
+}
+
+// Register marks secret as a credential to scrub wherever it appears.
+// Values shorter than 8 characters are ignored.
+func Register(secret string) {
+	secret = strings.TrimSpace(secret)
+	if len(secret) < minSecretLength {
+		return
+	}
+	mu.Lock()
+	secrets[secret] = true
+	mu.Unlock()
+}
+
+// String masks registered secrets, any extra values, and known token shapes in s
+func String(s string, extra ...string) string {
+	if s == "" {
+		return s
+	}
+
+	mu.RLock()
+	values := make([]string, 0, len(secrets)+len(extra))
+	for secret := range secrets {
+		values = append(values, secret)
+	}
+	mu.RUnlock()
+	for _, e := range extra {
+		if len(strings.TrimSpace(e)) >= minSecretLength {
+			values = append(values, e)
+		}
+	}
+
+	// Longest first so a secret containing another is masked whole
+	sort.Slice(values, func(i, j int) bool { return len(values[i]) > len(values[j]) })
+	for _, v := range values {
+		s = strings.ReplaceAll(s, v, Mask)
+	}
+
+	for _, p := range patterns {
+		s = p.re.ReplaceAllString(s, p.repl)
+	}
+	return s
+}
@@ -0,0 +1,243 @@
+package registry
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// InstalledFile records, inside an adapter's directory, which release it
+// was installed from
+const InstalledFile = ".installed.json"
+
+// Installed describes an adapter installed from a registry
+type Installed struct {
+	Provider    string    `json:"provider"`
+	Version     string    `json:"version"`
+	SHA256      string    `json:"sha256"`
+	Registry    string    `json:"registry"`
+	InstalledAt time.Time `json:"installed_at"`
+}
+
+// Verifier checks that a just-installed adapter responds, e.g. to
+// capabilities; an error rolls the install back
+type Verifier func(ctx context.Context, provider string) error
+
+// ReadInstalled returns the install record for a provider's adapter, or nil
+// if it wasn't installed from a registry
+func ReadInstalled(adaptersPath, provider string) (*Installed, error) {
+	data, err := os.ReadFile(filepath.Join(adaptersPath, provider, InstalledFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read install record for %s: %w", provider, err)
+	}
+
+	var installed Installed
+	if err := json.Unmarshal(data, &installed); err != nil {
+		return nil, fmt.Errorf("invalid install record for %s: %w", provider, err)
+	}
+	return &installed, nil
+}
+
+// Install downloads a provider's release (the latest when version is empty)
+// into adaptersPath/<provider>, replacing any existing adapter. The tarball
+// mirrors the adapters directory: it must hold <provider>/index.ts, and
+// top-level shared files such as base.ts are added only where missing. If
+// verify fails, the previous adapter is put back.
+func (c *Client) Install(ctx context.Context, index *Index, adaptersPath, provider, version string, verify Verifier) (*Installed, error) {
+	version, release, err := index.Resolve(provider, version)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := c.Download(ctx, release)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(adaptersPath, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create adapters directory: %w", err)
+	}
+	// Dot-prefixed, so the bridge never mistakes it for an adapter
+	staging, err := os.MkdirTemp(adaptersPath, ".staging-"+provider+"-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+	defer os.RemoveAll(staging)
+
+	if err := extractTarGz(data, staging); err != nil {
+		return nil, fmt.Errorf("failed to unpack %s@%s: %w", provider, version, err)
+	}
+	staged := filepath.Join(staging, provider)
+	if _, err := os.Stat(filepath.Join(staged, "index.ts")); err != nil {
+		return nil, fmt.Errorf("release %s@%s has no %s/index.ts", provider, version, provider)
+	}
+	if err := copyMissingFiles(staging, adaptersPath); err != nil {
+		return nil, err
+	}
+
+	installed := &Installed{
+		Provider:    provider,
+		Version:     version,
+		SHA256:      release.SHA256,
+		Registry:    c.baseURL,
+		InstalledAt: time.Now().UTC(),
+	}
+	record, err := json.MarshalIndent(installed, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(staged, InstalledFile), record, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write install record: %w", err)
+	}
+
+	if err := swapIn(ctx, staged, adaptersPath, provider, verify); err != nil {
+		return nil, fmt.Errorf("failed to install %s@%s: %w", provider, version, err)
+	}
+	return installed, nil
+}
+
+// swapIn moves staged into adaptersPath/<provider>, keeping the old adapter
+// aside until verify passes
+func swapIn(ctx context.Context, staged, adaptersPath, provider string, verify Verifier) error {
+	target := filepath.Join(adaptersPath, provider)
+	previous := filepath.Join(adaptersPath, "."+provider+".previous")
+
+	if err := os.RemoveAll(previous); err != nil {
+		return err
+	}
+	hadPrevious := false
+	if _, err := os.Stat(target); err == nil {
+		if err := os.Rename(target, previous); err != nil {
+			return fmt.Errorf("failed to move the current adapter aside: %w", err)
+		}
+		hadPrevious = true
+	}
+
+	restore := func() {
+		os.RemoveAll(target)
+		if hadPrevious {
+			os.Rename(previous, target)
+		}
+	}
+
+	if err := os.Rename(staged, target); err != nil {
+		restore()
+		return err
+	}
+	if verify != nil {
+		if err := verify(ctx, provider); err != nil {
+			restore()
+			if hadPrevious {
+				return fmt.Errorf("adapter failed verification, previous version restored: %w", err)
+			}
+			return fmt.Errorf("adapter failed verification: %w", err)
+		}
+	}
+	return os.RemoveAll(previous)
+}
+
+// copyMissingFiles copies the regular files at the top of src into dst,
+// leaving any dst already has untouched
+func copyMissingFiles(src, dst string) error {
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		target := filepath.Join(dst, entry.Name())
+		if _, err := os.Stat(target); err == nil {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(src, entry.Name()))
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(target, data, 0644); err != nil {
+			return fmt.Errorf("failed to install %s: %w", entry.Name(), err)
+		}
+	}
+	return nil
+}
+
+// maxExtracted bounds the total size of an adapter's unpacked files, so a
+// small tarball that decompresses enormously can't fill the disk
+var maxExtracted int64 = 200 << 20
+
+// extractTarGz unpacks a gzipped tarball into dir. Only regular files and
+// directories are allowed, no entry may land outside dir, and the files may
+// total at most maxExtracted bytes.
+func extractTarGz(data []byte, dir string) error {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	var remaining int64 = maxExtracted
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		name := filepath.Clean(filepath.FromSlash(hdr.Name))
+		if filepath.IsAbs(name) || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("entry %s escapes the adapter directory", hdr.Name)
+		}
+		path := filepath.Join(dir, name)
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(path, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return err
+			}
+			// Read one byte past the budget to tell a file that fits exactly
+			// from one that doesn't, whatever its header claims
+			written, err := writeFile(path, io.LimitReader(tr, remaining+1), os.FileMode(hdr.Mode).Perm()|0644)
+			if err != nil {
+				return err
+			}
+			if remaining -= written; remaining < 0 {
+				return fmt.Errorf("adapter unpacks to more than %d MB", maxExtracted>>20)
+			}
+		default:
+			return fmt.Errorf("entry %s is not a regular file or directory", hdr.Name)
+		}
+	}
+}
+
+// writeFile copies r into a new file at path and returns how many bytes it wrote
+func writeFile(path string, r io.Reader, mode os.FileMode) (int64, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return 0, err
+	}
+	written, err := io.Copy(f, r)
+	if err != nil {
+		f.Close()
+		return written, err
+	}
+	return written, f.Close()
+}
@@ -0,0 +1,91 @@
+package registry
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type tarEntry struct {
+	name     string
+	typeflag byte
+	size     int
+}
+
+// buildTarGz packs entries whose files hold size zero bytes
+func buildTarGz(t *testing.T, entries []tarEntry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.name, Typeflag: e.typeflag, Mode: 0644, Size: int64(e.size)}
+		if e.typeflag == tar.TypeSymlink {
+			hdr.Linkname = "/etc/passwd"
+			hdr.Size = 0
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if e.typeflag == tar.TypeReg {
+			if _, err := tw.Write(make([]byte, e.size)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestExtractTarGz(t *testing.T) {
+	prev := maxExtracted
+	maxExtracted = 1000
+	t.Cleanup(func() { maxExtracted = prev })
+
+	tests := []struct {
+		name    string
+		entries []tarEntry
+		wantErr string
+	}{
+		{name: "files and dirs", entries: []tarEntry{{"vercel/", tar.TypeDir, 0}, {"vercel/index.ts", tar.TypeReg, 400}}},
+		{name: "exactly at the cap", entries: []tarEntry{{"a.ts", tar.TypeReg, 600}, {"b.ts", tar.TypeReg, 400}}},
+		{name: "one file over the cap", entries: []tarEntry{{"index.ts", tar.TypeReg, 1001}}, wantErr: "more than"},
+		{name: "files together over the cap", entries: []tarEntry{{"a.ts", tar.TypeReg, 600}, {"b.ts", tar.TypeReg, 600}}, wantErr: "more than"},
+		{name: "escapes the directory", entries: []tarEntry{{"../evil.ts", tar.TypeReg, 10}}, wantErr: "escapes"},
+		{name: "symlink", entries: []tarEntry{{"link", tar.TypeSymlink, 0}}, wantErr: "not a regular file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			err := extractTarGz(buildTarGz(t, tt.entries), dir)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("extractTarGz: %v", err)
+				}
+				for _, e := range tt.entries {
+					if e.typeflag != tar.TypeReg {
+						continue
+					}
+					info, err := os.Stat(filepath.Join(dir, e.name))
+					if err != nil || info.Size() != int64(e.size) {
+						t.Errorf("%s not extracted intact: %v", e.name, err)
+					}
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+// Package registry installs and updates adapters from a registry: a base URL
+// (http, https, or file) serving an index.json that lists each provider's
+// releases as checksummed tarballs.
+package registry
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// IndexFile is the registry's release listing, relative to its base URL
+	IndexFile = "index.json"
+
+	fetchTimeout = 60 * time.Second
+	// maxDownload bounds a release tarball so a bad URL can't fill the disk
+	maxDownload = 50 << 20
+)
+
+// Index is the registry's index.json
+type Index struct {
+	Adapters map[string]IndexEntry `json:"adapters"`
+}
+
+// IndexEntry lists one provider's releases by version
+type IndexEntry struct {
+	Latest   string             `json:"latest"`
+	Versions map[string]Release `json:"versions"`
+}
+
+// Release is one downloadable adapter version. URL may be relative to the
+// index; SHA256 is the hex digest of the tarball.
+type Release struct {
+	URL    string `json:"url"`
+	SHA256 string `json:"sha256"`
+}
+
+// Client reads a registry
+type Client struct {
+	baseURL string
+	http    *http.Client
+}
+
+// NewClient creates a client for the registry at baseURL
+func NewClient(baseURL string) (*Client, error) {
+	if baseURL == "" {
+		return nil, fmt.Errorf("no adapter registry configured; set registry_url in the config file or DEPLOY_TUNNEL_REGISTRY_URL")
+	}
+	if err := CheckURL(baseURL); err != nil {
+		return nil, fmt.Errorf("invalid registry URL %s: %w", baseURL, err)
+	}
+	return &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/") + "/",
+		http:    &http.Client{Timeout: fetchTimeout},
+	}, nil
+}
+
+// CheckURL accepts an absolute http, https, or file URL
+func CheckURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	switch u.Scheme {
+	case "http", "https":
+		if u.Host == "" {
+			return fmt.Errorf("missing host")
+		}
+		return nil
+	case "file":
+		if u.Path == "" {
+			return fmt.Errorf("missing path")
+		}
+		return nil
+	}
+	return fmt.Errorf("must be an http, https, or file URL")
+}
+
+// FetchIndex downloads and parses the registry's index.json
+func (c *Client) FetchIndex(ctx context.Context) (*Index, error) {
+	data, err := c.fetch(ctx, c.baseURL+IndexFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch registry index: %w", err)
+	}
+
+	var index Index
+	if err := json.Unmarshal(data, &index); err != nil {
+		return nil, fmt.Errorf("invalid registry index: %w", err)
+	}
+	return &index, nil
+}
+
+// Resolve picks a provider's release; an empty version means the latest
+func (idx *Index) Resolve(provider, version string) (string, Release, error) {
+	entry, ok := idx.Adapters[provider]
+	if !ok {
+		return "", Release{}, fmt.Errorf("registry has no %s adapter", provider)
+	}
+	if version == "" {
+		version = entry.Latest
+	}
+	release, ok := entry.Versions[version]
+	if !ok {
+		return "", Release{}, fmt.Errorf("registry has no %s adapter version %s (available: %s)", provider, version, strings.Join(entry.versionList(), ", "))
+	}
+	if release.URL == "" || release.SHA256 == "" {
+		return "", Release{}, fmt.Errorf("registry entry for %s@%s is missing its url or sha256", provider, version)
+	}
+	return version, release, nil
+}
+
+func (e IndexEntry) versionList() []string {
+	versions := make([]string, 0, len(e.Versions))
+	for v := range e.Versions {
+		versions = append(versions, v)
+	}
+	sort.Strings(versions)
+	return versions
+}
+
+// Download fetches a release tarball and checks it against its checksum
+func (c *Client) Download(ctx context.Context, release Release) ([]byte, error) {
+	ref, err := url.Parse(release.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid release URL %s: %w", release.URL, err)
+	}
+	base, _ := url.Parse(c.baseURL)
+	location := base.ResolveReference(ref).String()
+
+	data, err := c.fetch(ctx, location)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", location, err)
+	}
+
+	sum := sha256.Sum256(data)
+	if got := hex.EncodeToString(sum[:]); !strings.EqualFold(got, release.SHA256) {
+		return nil, fmt.Errorf("checksum mismatch for %s: expected %s, got %s", location, release.SHA256, got)
+	}
+	return data, nil
+}
+
+// fetch reads a file or http(s) URL
+func (c *Client) fetch(ctx context.Context, location string) ([]byte, error) {
+	u, err := url.Parse(location)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.Scheme == "file" {
+		f, err := os.Open(u.Path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return readCapped(f)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return readCapped(resp.Body)
+}
+
+// readCapped reads r, failing if it's larger than maxDownload
+func readCapped(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxDownload+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxDownload {
+		return nil, fmt.Errorf("larger than %d MB", maxDownload>>20)
+	}
+	return data, nil
+}
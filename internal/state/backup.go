@@ -0,0 +1,126 @@
+package state
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Backup writes a consistent snapshot of the database to destPath.
+// VACUUM INTO reads through a single transaction, so it is safe while
+// other connections are writing. destPath must not already exist.
+func (d *DB) Backup(destPath string) error {
+	if _, err := os.Stat(destPath); err == nil {
+		return fmt.Errorf("backup destination already exists: %s", destPath)
+	}
+	if _, err := d.db.Exec("VACUUM INTO ?", destPath); err != nil {
+		return fmt.Errorf("failed to back up database: %w", err)
+	}
+	return nil
+}
+
+// Restore replaces the state database in configDir with the backup at srcPath.
+// The current database, if any, is first snapshotted to state.db.bak.
+// Any open DB for configDir must be closed before calling Restore.
+func Restore(srcPath, configDir string) error {
+	if err := validateBackup(srcPath); err != nil {
+		return err
+	}
+
+	configDir, err := ResolveConfigDir(configDir)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(configDir, 0755); err != nil {
+		return fmt.Errorf("failed to create config dir: %w", err)
+	}
+
+	dbPath := filepath.Join(configDir, dbFileName)
+	if _, err := os.Stat(dbPath); err == nil {
+		if err := snapshotCurrent(dbPath, dbPath+".bak"); err != nil {
+			return fmt.Errorf("refusing to restore without a backup of the current database: %w", err)
+		}
+	}
+
+	// Copy to a temp file first so a failed copy never leaves a half-written state.db
+	tmpPath := dbPath + ".restore"
+	if err := copyFile(srcPath, tmpPath); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to copy backup: %w", err)
+	}
+
+	// Stale WAL files belong to the old database and would corrupt the restored one
+	os.Remove(dbPath + "-wal")
+	os.Remove(dbPath + "-shm")
+
+	if err := os.Rename(tmpPath, dbPath); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to replace database: %w", err)
+	}
+
+	return nil
+}
+
+// validateBackup checks that path is a readable deploy-tunnel database
+func validateBackup(path string) error {
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("backup not found: %w", err)
+	}
+
+	db, err := sql.Open("sqlite3", path+"?mode=ro")
+	if err != nil {
+		return fmt.Errorf("failed to open backup: %w", err)
+	}
+	defer db.Close()
+
+	var check string
+	if err := db.QueryRow("PRAGMA quick_check").Scan(&check); err != nil {
+		return fmt.Errorf("backup is not a valid SQLite database: %w", err)
+	}
+	if check != "ok" {
+		return fmt.Errorf("backup failed integrity check: %s", check)
+	}
+
+	var tables int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'migrations'`).Scan(&tables); err != nil {
+		return fmt.Errorf("failed to inspect backup: %w", err)
+	}
+	if tables == 0 {
+		return fmt.Errorf("backup is not a deploy-tunnel database: missing migrations table")
+	}
+
+	return nil
+}
+
+// snapshotCurrent writes a consistent copy of dbPath (including unflushed WAL pages) to bakPath
+func snapshotCurrent(dbPath, bakPath string) error {
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	os.Remove(bakPath)
+	_, err = db.Exec("VACUUM INTO ?", bakPath)
+	return err
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,129 @@
+package state
+
+import (
+	"bytes"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBackupRestoreRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	key := bytes.Repeat([]byte{1}, 32)
+	db, err := OpenWithKey(dir, key)
+	if err != nil {
+		t.Fatalf("failed to open state: %v", err)
+	}
+	if err := db.CreateMigration("m1", "vercel", "netlify", "example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.SaveEnvVar("m1", "API_KEY", "sk_secret", "", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	backup := filepath.Join(t.TempDir(), "backup.db")
+	if err := db.Backup(backup); err != nil {
+		t.Fatalf("Backup: %v", err)
+	}
+	if err := db.Backup(backup); err == nil {
+		t.Error("Backup overwrote an existing file")
+	}
+
+	// Changes after the backup are undone by the restore
+	if err := db.CreateMigration("m2", "vercel", "netlify", "example.org"); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	if err := Restore(backup, dir); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+
+	restored, err := OpenWithKey(dir, key)
+	if err != nil {
+		t.Fatalf("failed to open restored state: %v", err)
+	}
+	defer restored.Close()
+	if m, _ := restored.GetMigration("m1"); m == nil {
+		t.Error("m1 missing after restore")
+	}
+	if m, _ := restored.GetMigration("m2"); m != nil {
+		t.Error("m2, created after the backup, survived the restore")
+	}
+	vars, err := restored.GetEnvVars("m1")
+	if err != nil || len(vars) != 1 || vars[0].Value != "sk_secret" {
+		t.Errorf("GetEnvVars after restore = %+v, %v", vars, err)
+	}
+
+	// The replaced database was kept next to it
+	elsewhere := t.TempDir()
+	if err := Restore(filepath.Join(dir, dbFileName+".bak"), elsewhere); err != nil {
+		t.Fatalf("state.db.bak isn't a valid backup: %v", err)
+	}
+	bak, err := OpenWithKey(elsewhere, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bak.Close()
+	if m, _ := bak.GetMigration("m2"); m == nil {
+		t.Error("state.db.bak doesn't hold the database the restore replaced")
+	}
+}
+
+func TestRestoreRejectsInvalidBackup(t *testing.T) {
+	files := t.TempDir()
+	notSQLite := filepath.Join(files, "notes.txt")
+	if err := os.WriteFile(notSQLite, []byte("definitely not a database"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	otherDB := filepath.Join(files, "other.db")
+	other, err := sql.Open("sqlite3", otherDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := other.Exec(`CREATE TABLE notes (body TEXT)`); err != nil {
+		t.Fatal(err)
+	}
+	other.Close()
+
+	tests := []struct {
+		name string
+		path string
+	}{
+		{name: "missing", path: filepath.Join(files, "missing.db")},
+		{name: "not SQLite", path: notSQLite},
+		{name: "not a deploy-tunnel database", path: otherDB},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			key := bytes.Repeat([]byte{1}, 32)
+			db, err := OpenWithKey(dir, key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := db.CreateMigration("m1", "vercel", "netlify", "example.com"); err != nil {
+				t.Fatal(err)
+			}
+			db.Close()
+
+			if err := Restore(tt.path, dir); err == nil {
+				t.Fatal("Restore accepted an invalid backup")
+			}
+			if _, err := os.Stat(filepath.Join(dir, dbFileName+".bak")); !os.IsNotExist(err) {
+				t.Error("Restore touched the current database before rejecting the backup")
+			}
+
+			kept, err := OpenWithKey(dir, key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer kept.Close()
+			if m, _ := kept.GetMigration("m1"); m == nil {
+				t.Error("the current database was overwritten")
+			}
+		})
+	}
+}
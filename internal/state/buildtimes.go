@@ -0,0 +1,72 @@
+package state
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// buildTimeSamples is how many recent builds per project are kept and
+// averaged, so the estimate follows a project as its build changes
+const buildTimeSamples = 10
+
+// RecordBuildTime stores how long a preview build of a provider's project
+// took, dropping samples older than the last buildTimeSamples
+func (d *DB) RecordBuildTime(provider, projectID string, buildTime time.Duration) error {
+	return d.RecordBuildTimeContext(context.Background(), provider, projectID, buildTime)
+}
+
+// RecordBuildTimeContext is RecordBuildTime with a context for cancellation
+func (d *DB) RecordBuildTimeContext(ctx context.Context, provider, projectID string, buildTime time.Duration) error {
+	if buildTime < 0 {
+		return fmt.Errorf("invalid build time: %s", buildTime)
+	}
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO build_times (provider, project_id, seconds) VALUES (?, ?, ?)
+	`, provider, projectID, int64(buildTime/time.Second)); err != nil {
+		return fmt.Errorf("failed to record build time: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM build_times
+		WHERE provider = ? AND project_id = ? AND id NOT IN (
+			SELECT id FROM build_times WHERE provider = ? AND project_id = ?
+			ORDER BY id DESC LIMIT ?
+		)
+	`, provider, projectID, provider, projectID, buildTimeSamples); err != nil {
+		return fmt.Errorf("failed to prune build times: %w", err)
+	}
+	return tx.Commit()
+}
+
+// AverageBuildTime returns the mean of a provider's project's recent build
+// times and how many builds it covers, or 0 and 0 with no history
+func (d *DB) AverageBuildTime(provider, projectID string) (time.Duration, int, error) {
+	return d.AverageBuildTimeContext(context.Background(), provider, projectID)
+}
+
+// AverageBuildTimeContext is AverageBuildTime with a context for cancellation
+func (d *DB) AverageBuildTimeContext(ctx context.Context, provider, projectID string) (time.Duration, int, error) {
+	var avg sql.NullFloat64
+	var samples int
+	err := d.db.QueryRowContext(ctx, `
+		SELECT AVG(seconds), COUNT(*) FROM (
+			SELECT seconds FROM build_times WHERE provider = ? AND project_id = ?
+			ORDER BY id DESC LIMIT ?
+		)
+	`, provider, projectID, buildTimeSamples).Scan(&avg, &samples)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to load build times: %w", err)
+	}
+	if !avg.Valid {
+		return 0, 0, nil
+	}
+	return time.Duration(avg.Float64 * float64(time.Second)), samples, nil
+}
@@ -0,0 +1,77 @@
+package state
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestAverageBuildTime(t *testing.T) {
+	db := openWithKey(t, bytes.Repeat([]byte{1}, 32))
+
+	if avg, n, err := db.AverageBuildTime("netlify", "prj_1"); err != nil || avg != 0 || n != 0 {
+		t.Errorf("AverageBuildTime() with no history = %s, %d, %v, want 0, 0", avg, n, err)
+	}
+
+	for _, buildTime := range []time.Duration{30 * time.Second, 45 * time.Second, 1500 * time.Millisecond} {
+		if err := db.RecordBuildTime("netlify", "prj_1", buildTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Other projects and providers don't count
+	if err := db.RecordBuildTime("netlify", "prj_2", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.RecordBuildTime("vercel", "prj_1", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	// Build times are stored in whole seconds
+	avg, n, err := db.AverageBuildTime("netlify", "prj_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if avg != (30+45+1)*time.Second/3 || n != 3 {
+		t.Errorf("AverageBuildTime() = %s over %d builds, want %s over 3", avg, n, (30+45+1)*time.Second/3)
+	}
+}
+
+func TestRecordBuildTimeKeepsRecentSamples(t *testing.T) {
+	db := openWithKey(t, bytes.Repeat([]byte{1}, 32))
+
+	// An old slow build, then a full window of fast ones
+	if err := db.RecordBuildTime("netlify", "prj_1", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	for range buildTimeSamples {
+		if err := db.RecordBuildTime("netlify", "prj_1", 10*time.Second); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	avg, n, err := db.AverageBuildTime("netlify", "prj_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if avg != 10*time.Second || n != buildTimeSamples {
+		t.Errorf("AverageBuildTime() = %s over %d builds, want 10s over %d", avg, n, buildTimeSamples)
+	}
+	var stored int
+	if err := db.db.QueryRow(`SELECT COUNT(*) FROM build_times`).Scan(&stored); err != nil {
+		t.Fatal(err)
+	}
+	if stored != buildTimeSamples {
+		t.Errorf("%d build times stored, want the oldest pruned to %d", stored, buildTimeSamples)
+	}
+}
+
+func TestRecordBuildTimeRejectsNegative(t *testing.T) {
+	db := openWithKey(t, bytes.Repeat([]byte{1}, 32))
+
+	if err := db.RecordBuildTime("netlify", "prj_1", -time.Second); err == nil {
+		t.Error("RecordBuildTime(-1s) succeeded, want an error")
+	}
+	if _, n, _ := db.AverageBuildTime("netlify", "prj_1"); n != 0 {
+		t.Errorf("%d build times stored after a rejected one, want 0", n)
+	}
+}
@@ -0,0 +1,197 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const bundleVersion = 1
+
+// MigrationBundle is the portable JSON form of a migration and its history
+type MigrationBundle struct {
+	Version        int         `json:"version"`
+	ExportedAt     time.Time   `json:"exported_at"`
+	ValuesIncluded bool        `json:"values_included"`
+	Migration      Migration   `json:"migration"`
+	EnvVars        []EnvVar    `json:"env_vars"`
+	DnsRecords     []DnsRecord `json:"dns_records"`
+	Logs           []LogEntry  `json:"logs"`
+}
+
+// ExportMigration produces a JSON bundle for a migration with env var values masked
+func (d *DB) ExportMigration(id string) ([]byte, error) {
+	return d.exportMigration(id, false)
+}
+
+// ExportMigrationWithValues produces a JSON bundle that includes decrypted env var values.
+// The output contains secrets and should be handled accordingly.
+func (d *DB) ExportMigrationWithValues(id string) ([]byte, error) {
+	return d.exportMigration(id, true)
+}
+
+func (d *DB) exportMigration(id string, includeValues bool) ([]byte, error) {
+	m, err := d.GetMigration(id)
+	if err != nil {
+		return nil, err
+	}
+	if m == nil {
+		return nil, fmt.Errorf("migration not found: %s", id)
+	}
+
+	envVars, err := d.GetEnvVars(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load env vars: %w", err)
+	}
+	if !includeValues {
+		for i := range envVars {
+			envVars[i].Value = ""
+		}
+	}
+
+	records, err := d.GetDnsRecords(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load DNS records: %w", err)
+	}
+
+	logs, err := d.allLogs(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load logs: %w", err)
+	}
+
+	return json.MarshalIndent(MigrationBundle{
+		Version:        bundleVersion,
+		ExportedAt:     time.Now().UTC(),
+		ValuesIncluded: includeValues,
+		Migration:      *m,
+		EnvVars:        envVars,
+		DnsRecords:     records,
+		Logs:           logs,
+	}, "", "  ")
+}
+
+// ImportMigration inserts a bundle under a fresh migration ID and returns that ID.
+// DNS record IDs that already exist locally are reassigned, and rollback
+// references within the bundle are remapped to match. A bundle whose env var
+// values were masked is rejected, since importing it would store blank values
+// that a later sync would push to the target.
+func (d *DB) ImportMigration(data []byte) (string, error) {
+	var bundle MigrationBundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return "", fmt.Errorf("invalid migration bundle: %w", err)
+	}
+	if bundle.Version != bundleVersion {
+		return "", fmt.Errorf("unsupported bundle version: %d", bundle.Version)
+	}
+	if !bundle.ValuesIncluded && len(bundle.EnvVars) > 0 {
+		return "", fmt.Errorf("bundle was exported without env var values; export it with values to import it")
+	}
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	newID := uuid.New().String()
+	m := bundle.Migration
+	// An archived migration stays archived on the other side
+	var archivedAt interface{}
+	if m.ArchivedAt != nil {
+		archivedAt = m.ArchivedAt.UTC().Format(sqliteTimeFormat)
+	}
+	if _, err := tx.Exec(`
+		INSERT INTO migrations (id, source, target, domain, status, created_at, updated_at, archived_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, newID, m.Source, m.Target, m.Domain, m.Status, m.CreatedAt.UTC().Format(sqliteTimeFormat), m.UpdatedAt.UTC().Format(sqliteTimeFormat), archivedAt); err != nil {
+		return "", fmt.Errorf("failed to import migration: %w", err)
+	}
+
+	for _, e := range bundle.EnvVars {
+		value, err := d.cipher.encrypt(e.Value)
+		if err != nil {
+			return "", err
+		}
+		if _, err := tx.Exec(`
+			INSERT INTO env_vars (migration_id, key, value, target_key, excluded, environments)
+			VALUES (?, ?, ?, ?, ?, ?)
+		`, newID, e.Key, value, e.TargetKey, e.Excluded, joinEnvironments(e.Environments)); err != nil {
+			return "", fmt.Errorf("failed to import env var %s: %w", e.Key, err)
+		}
+	}
+
+	// Bundles from before provider_record_id used the provider's ID as the
+	// row ID, and rollback rows carried the ID of the change they undid
+	providerIDs := make(map[string]string)
+	for i, r := range bundle.DnsRecords {
+		if r.ProviderRecordID == "" && r.RollbackID == nil {
+			bundle.DnsRecords[i].ProviderRecordID = r.ID
+		}
+		providerIDs[r.ID] = bundle.DnsRecords[i].ProviderRecordID
+	}
+	for i, r := range bundle.DnsRecords {
+		if r.ProviderRecordID == "" && r.RollbackID != nil {
+			bundle.DnsRecords[i].ProviderRecordID = providerIDs[*r.RollbackID]
+		}
+	}
+
+	// Reassign colliding record IDs before inserting so rollback links stay consistent
+	idMap := make(map[string]string)
+	for _, r := range bundle.DnsRecords {
+		var exists int
+		if err := tx.QueryRow(`SELECT COUNT(*) FROM dns_records WHERE id = ?`, r.ID).Scan(&exists); err != nil {
+			return "", err
+		}
+		if exists > 0 {
+			idMap[r.ID] = uuid.New().String()
+		}
+	}
+
+	for _, r := range bundle.DnsRecords {
+		if mapped, ok := idMap[r.ID]; ok {
+			r.ID = mapped
+		}
+		if r.RollbackID != nil {
+			if mapped, ok := idMap[*r.RollbackID]; ok {
+				r.RollbackID = &mapped
+			}
+		}
+		if _, err := tx.Exec(`
+			INSERT INTO dns_records (id, provider_record_id, migration_id, domain, record_type, record_name, record_value, ttl, rollback_id, previous_value, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, r.ID, r.ProviderRecordID, newID, r.Domain, r.RecordType, r.RecordName, r.RecordValue, r.TTL, r.RollbackID, r.PreviousValue, r.CreatedAt.UTC().Format(sqliteTimeFormat)); err != nil {
+			return "", fmt.Errorf("failed to import DNS record %s: %w", r.ID, err)
+		}
+	}
+
+	for _, l := range bundle.Logs {
+		if _, err := tx.Exec(`
+			INSERT INTO logs (migration_id, level, message, metadata, ts)
+			VALUES (?, ?, ?, ?, ?)
+		`, newID, l.Level, l.Message, l.Metadata, l.Timestamp.UTC().Format(sqliteTimeFormat)); err != nil {
+			return "", fmt.Errorf("failed to import log entry: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", err
+	}
+	return newID, nil
+}
+
+// allLogs returns every log entry for a migration in chronological order
+func (d *DB) allLogs(migrationID string) ([]LogEntry, error) {
+	rows, err := d.db.Query(`
+		SELECT id, migration_id, level, message, metadata, ts
+		FROM logs WHERE migration_id = ?
+		ORDER BY ts ASC, id ASC
+	`, migrationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanLogs(rows)
+}
@@ -0,0 +1,205 @@
+package state
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+)
+
+// bundledMigration creates migration m1 with env vars, a DNS change and its
+// rollback, and logs
+func bundledMigration(t *testing.T, db *DB) {
+	t.Helper()
+	if err := db.CreateMigration("m1", "vercel", "netlify", "example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.UpdateMigrationStatus("m1", "in_progress"); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.SaveEnvVar("m1", "API_KEY", "sk_secret", "NEW_API_KEY", []string{"production", "preview"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.SaveEnvVar("m1", "DEBUG", "1", "", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	m1 := "m1"
+	previous := "198.51.100.1"
+	change := &DnsRecord{ID: "dns-1", ProviderRecordID: "rec_1", MigrationID: &m1, Domain: "example.com", RecordType: "A", RecordName: "@", RecordValue: "203.0.113.10", TTL: 300, PreviousValue: &previous}
+	if err := db.SaveDnsRecord(change); err != nil {
+		t.Fatal(err)
+	}
+	rollback := &DnsRecord{ID: "dns-2", ProviderRecordID: "rec_1", MigrationID: &m1, Domain: "example.com", RecordType: "A", RecordName: "@", RecordValue: previous, TTL: 300, RollbackID: &change.ID}
+	if err := db.SaveDnsRecord(rollback); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := db.LogJSON(&m1, "info", "synced env", map[string]interface{}{"count": 2}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Log(&m1, "error", "cutover failed", ""); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMigrationBundleRoundTrip(t *testing.T) {
+	src := openWithKey(t, bytes.Repeat([]byte{1}, 32))
+	bundledMigration(t, src)
+
+	data, err := src.ExportMigrationWithValues("m1")
+	if err != nil {
+		t.Fatalf("ExportMigrationWithValues: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		dst  *DB
+		// collides is whether dst already holds the bundle's DNS record IDs
+		collides bool
+	}{
+		{name: "other machine", dst: openWithKey(t, bytes.Repeat([]byte{2}, 32))},
+		{name: "same database", dst: src, collides: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, err := tt.dst.ImportMigration(data)
+			if err != nil {
+				t.Fatalf("ImportMigration: %v", err)
+			}
+			if id == "m1" {
+				t.Fatal("import reused the exported migration ID")
+			}
+
+			want, _ := src.GetMigration("m1")
+			got, err := tt.dst.GetMigration(id)
+			if err != nil || got == nil {
+				t.Fatalf("GetMigration(%s) = %v, %v", id, got, err)
+			}
+			if got.Source != want.Source || got.Target != want.Target || got.Domain != want.Domain || got.Status != want.Status {
+				t.Errorf("imported migration = %+v, want %+v", got, want)
+			}
+			if !got.CreatedAt.Equal(want.CreatedAt) {
+				t.Errorf("CreatedAt = %v, want %v", got.CreatedAt, want.CreatedAt)
+			}
+
+			wantVars, _ := src.GetEnvVars("m1")
+			gotVars, err := tt.dst.GetEnvVars(id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(gotVars) != len(wantVars) {
+				t.Fatalf("imported %d env vars, want %d", len(gotVars), len(wantVars))
+			}
+			for i, w := range wantVars {
+				g := gotVars[i]
+				if g.Key != w.Key || g.Value != w.Value || g.TargetKey != w.TargetKey || !slices.Equal(g.Environments, w.Environments) {
+					t.Errorf("env var %d = %+v, want %+v", i, g, w)
+				}
+			}
+
+			gotRecords, err := tt.dst.GetDnsRecords(id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(gotRecords) != 2 {
+				t.Fatalf("imported %d DNS records, want 2", len(gotRecords))
+			}
+			change, rollback := gotRecords[0], gotRecords[1]
+			if change.ProviderRecordID != "rec_1" || change.PreviousValue == nil || *change.PreviousValue != "198.51.100.1" {
+				t.Errorf("imported change = %+v", change)
+			}
+			if rollback.RollbackID == nil || *rollback.RollbackID != change.ID {
+				t.Errorf("rollback points at %v, want the imported change %s", rollback.RollbackID, change.ID)
+			}
+			if collided := change.ID != "dns-1"; collided != tt.collides {
+				t.Errorf("change ID = %s, want reassigned = %v", change.ID, tt.collides)
+			}
+
+			gotLogs, err := tt.dst.allLogs(id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(gotLogs) != 2 || gotLogs[0].Message != "synced env" || gotLogs[1].Level != "error" {
+				t.Errorf("imported logs = %+v", gotLogs)
+			}
+			if len(gotLogs) > 0 && gotLogs[0].Fields["count"] != float64(2) {
+				t.Errorf("log fields = %v, want count 2", gotLogs[0].Fields)
+			}
+		})
+	}
+}
+
+func TestImportMaskedBundle(t *testing.T) {
+	src := openWithKey(t, bytes.Repeat([]byte{1}, 32))
+	bundledMigration(t, src)
+
+	masked, err := src.ExportMigration("m1")
+	if err != nil {
+		t.Fatalf("ExportMigration: %v", err)
+	}
+	if bytes.Contains(masked, []byte("sk_secret")) {
+		t.Fatal("masked bundle contains an env var value")
+	}
+
+	dst := openWithKey(t, bytes.Repeat([]byte{2}, 32))
+	if id, err := dst.ImportMigration(masked); err == nil {
+		t.Fatalf("ImportMigration(masked) = %s, want an error", id)
+	}
+	migrations, err := dst.ListMigrations("", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) != 0 {
+		t.Errorf("rejected import left %d migration(s) behind", len(migrations))
+	}
+
+	// Without env vars there's nothing to push blank, so masking doesn't matter
+	if err := src.CreateMigration("m2", "vercel", "netlify", "example.org"); err != nil {
+		t.Fatal(err)
+	}
+	noVars, err := src.ExportMigration("m2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := dst.ImportMigration(noVars); err != nil {
+		t.Errorf("ImportMigration(masked, no env vars) = %v", err)
+	}
+}
+
+func TestImportRejectsUnknownVersion(t *testing.T) {
+	db := openWithKey(t, bytes.Repeat([]byte{1}, 32))
+	if _, err := db.ImportMigration([]byte(`{"version": 99}`)); err == nil {
+		t.Error("ImportMigration accepted bundle version 99")
+	}
+}
+
+func TestImportKeepsArchived(t *testing.T) {
+	src := openWithKey(t, bytes.Repeat([]byte{1}, 32))
+	if err := src.CreateMigration("m1", "vercel", "netlify", "example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if err := src.ArchiveMigration("m1"); err != nil {
+		t.Fatal(err)
+	}
+	data, err := src.ExportMigration("m1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dst := openWithKey(t, bytes.Repeat([]byte{2}, 32))
+	id, err := dst.ImportMigration(data)
+	if err != nil {
+		t.Fatalf("ImportMigration: %v", err)
+	}
+	imported, err := dst.GetMigration(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if imported.ArchivedAt == nil {
+		t.Fatal("archived migration was imported unarchived")
+	}
+	if listed, _ := dst.ListMigrations("", false); len(listed) != 0 {
+		t.Errorf("imported archived migration is listed by default: %+v", listed)
+	}
+}
@@ -0,0 +1,61 @@
+package state
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/johnhorton/deploy-tunnel/internal/events"
+)
+
+// CloneMigration starts a new pending migration with the same source, target,
+// and domain as id, and returns the new ID. DNS records, logs, steps, and
+// deployments stay with the original.
+func (d *DB) CloneMigration(id string) (string, error) {
+	return d.cloneMigration(id, false)
+}
+
+// CloneMigrationWithEnv is CloneMigration that also copies the env var
+// mappings: values, target keys, exclusions, and environments
+func (d *DB) CloneMigrationWithEnv(id string) (string, error) {
+	return d.cloneMigration(id, true)
+}
+
+func (d *DB) cloneMigration(id string, withEnv bool) (string, error) {
+	tx, err := d.db.Begin()
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	newID := uuid.New().String()
+	result, err := tx.Exec(`
+		INSERT INTO migrations (id, source, target, domain, status)
+		SELECT ?, source, target, domain, 'pending' FROM migrations WHERE id = ?
+	`, newID, id)
+	if err != nil {
+		return "", fmt.Errorf("failed to clone migration: %w", err)
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return "", err
+	} else if n == 0 {
+		return "", fmt.Errorf("migration not found: %s", id)
+	}
+
+	if withEnv {
+		// Values are copied still encrypted; both rows share this DB's key
+		if _, err := tx.Exec(`
+			INSERT INTO env_vars (migration_id, key, value, target_key, excluded, environments)
+			SELECT ?, key, value, target_key, excluded, environments
+			FROM env_vars WHERE migration_id = ? ORDER BY id
+		`, newID, id); err != nil {
+			return "", fmt.Errorf("failed to clone env vars: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", err
+	}
+
+	events.Publish(events.MigrationEvent{ID: newID, Type: events.TypeMigrationCreated, Status: "pending"})
+	return newID, nil
+}
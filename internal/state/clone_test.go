@@ -0,0 +1,88 @@
+package state
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCloneMigration(t *testing.T) {
+	tests := []struct {
+		name    string
+		withEnv bool
+	}{
+		{name: "settings only"},
+		{name: "with env", withEnv: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := openWithKey(t, bytes.Repeat([]byte{1}, 32))
+			bundledMigration(t, db)
+			vars, err := db.GetEnvVars("m1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := db.SetEnvVarExcluded(vars[1].ID, true); err != nil {
+				t.Fatal(err)
+			}
+
+			clone := db.CloneMigration
+			if tt.withEnv {
+				clone = db.CloneMigrationWithEnv
+			}
+			id, err := clone("m1")
+			if err != nil {
+				t.Fatalf("clone error: %v", err)
+			}
+
+			got, err := db.GetMigration(id)
+			if err != nil || got == nil {
+				t.Fatalf("GetMigration(%s) = %v, %v", id, got, err)
+			}
+			if got.Source != "vercel" || got.Target != "netlify" || got.Domain != "example.com" || got.Status != "pending" {
+				t.Errorf("clone = %+v, want the settings of m1 and status pending", got)
+			}
+
+			want, _ := db.GetEnvVars("m1")
+			if !tt.withEnv {
+				want = nil
+			}
+			cloned, err := db.GetEnvVars(id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(cloned) != len(want) {
+				t.Fatalf("cloned %d env vars, want %d", len(cloned), len(want))
+			}
+			for i, w := range want {
+				g := cloned[i]
+				if g.Key != w.Key || g.Value != w.Value || g.TargetKey != w.TargetKey || g.Excluded != w.Excluded || !slices.Equal(g.Environments, w.Environments) {
+					t.Errorf("env var %d = %+v, want %+v", i, g, w)
+				}
+			}
+
+			// DNS changes and logs belong to the original run
+			if records, err := db.GetDnsRecords(id); err != nil || len(records) != 0 {
+				t.Errorf("clone has DNS records %v, %v, want none", records, err)
+			}
+			if logs, err := db.allLogs(id); err != nil || len(logs) != 0 {
+				t.Errorf("clone has logs %v, %v, want none", logs, err)
+			}
+			if records, _ := db.GetDnsRecords("m1"); len(records) != 2 {
+				t.Errorf("original has %d DNS records after cloning, want 2", len(records))
+			}
+		})
+	}
+}
+
+func TestCloneMissingMigration(t *testing.T) {
+	db := openWithKey(t, bytes.Repeat([]byte{1}, 32))
+	if _, err := db.CloneMigrationWithEnv("nope"); err == nil || !strings.Contains(err.Error(), "migration not found: nope") {
+		t.Errorf("CloneMigrationWithEnv(nope) error = %v, want not found", err)
+	}
+	if migrations, _ := db.ListMigrations("", true); len(migrations) != 0 {
+		t.Errorf("a failed clone left %d migrations", len(migrations))
+	}
+}
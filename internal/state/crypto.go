@@ -0,0 +1,74 @@
+package state
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// encryptedPrefix marks values that have been encrypted at rest.
+// Rows without it are legacy plaintext and get encrypted on open.
+const encryptedPrefix = "enc:v1:"
+
+// cipherBox encrypts env var values with AES-GCM using a fresh nonce per value
+type cipherBox struct {
+	aead cipher.AEAD
+	// macKey keys env fingerprints, derived from the encryption key
+	macKey []byte
+}
+
+func newCipherBox(key []byte) (*cipherBox, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("deploy-tunnel env fingerprint"))
+	return &cipherBox{aead: aead, macKey: mac.Sum(nil)}, nil
+}
+
+// encrypt returns the prefixed, base64-encoded nonce+ciphertext
+func (c *cipherBox) encrypt(plaintext string) (string, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := c.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// decrypt reverses encrypt. Unprefixed values are returned as-is.
+func (c *cipherBox) decrypt(value string) (string, error) {
+	if !isEncrypted(value) {
+		return value, nil
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, encryptedPrefix))
+	if err != nil {
+		return "", fmt.Errorf("failed to decode encrypted value: %w", err)
+	}
+
+	nonceSize := c.aead.NonceSize()
+	if len(sealed) < nonceSize {
+		return "", fmt.Errorf("encrypted value is truncated")
+	}
+
+	plaintext, err := c.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt value: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+func isEncrypted(value string) bool {
+	return strings.HasPrefix(value, encryptedPrefix)
+}
@@ -0,0 +1,82 @@
+package state
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestEnvVarsEncryptedAtRest(t *testing.T) {
+	dir := t.TempDir()
+	key := bytes.Repeat([]byte{1}, 32)
+	db, err := OpenWithKey(dir, key)
+	if err != nil {
+		t.Fatalf("failed to open state: %v", err)
+	}
+	if err := db.CreateMigration("m1", "vercel", "netlify", "example.com"); err != nil {
+		t.Fatal(err)
+	}
+	const secret = "sk_live_plaintext_secret"
+	if err := db.SaveEnvVar("m1", "API_KEY", secret, "", nil); err != nil {
+		t.Fatalf("SaveEnvVar: %v", err)
+	}
+
+	var raw string
+	if err := db.db.QueryRow(`SELECT value FROM env_vars WHERE key = 'API_KEY'`).Scan(&raw); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(raw, encryptedPrefix) {
+		t.Errorf("stored value %q has no %q prefix", raw, encryptedPrefix)
+	}
+	if strings.Contains(raw, secret) {
+		t.Errorf("stored value %q contains the plaintext", raw)
+	}
+
+	vars, err := db.GetEnvVars("m1")
+	if err != nil {
+		t.Fatalf("GetEnvVars: %v", err)
+	}
+	if len(vars) != 1 || vars[0].Value != secret {
+		t.Errorf("GetEnvVars() = %+v, want API_KEY = %q", vars, secret)
+	}
+	db.Close()
+
+	if _, err := OpenWithKey(dir, bytes.Repeat([]byte{2}, 32)); err == nil || !strings.Contains(err.Error(), "different key") {
+		t.Errorf("OpenWithKey with the wrong key error = %v, want it refused", err)
+	}
+}
+
+func TestLegacyEnvVarsEncryptedOnOpen(t *testing.T) {
+	dir := t.TempDir()
+	db, err := OpenWithKey(dir, bytes.Repeat([]byte{1}, 32))
+	if err != nil {
+		t.Fatalf("failed to open state: %v", err)
+	}
+	if err := db.CreateMigration("m1", "vercel", "netlify", "example.com"); err != nil {
+		t.Fatal(err)
+	}
+	// A database from before encryption holds plaintext values only
+	if _, err := db.db.Exec(`INSERT INTO env_vars (migration_id, key, value, target_key) VALUES ('m1', 'API_KEY', 'sk_legacy', '')`); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	// Any fresh key opens it, since nothing was encrypted yet
+	reopened, err := OpenWithKey(dir, bytes.Repeat([]byte{2}, 32))
+	if err != nil {
+		t.Fatalf("failed to reopen a plaintext database with a new key: %v", err)
+	}
+	defer reopened.Close()
+
+	var raw string
+	if err := reopened.db.QueryRow(`SELECT value FROM env_vars WHERE key = 'API_KEY'`).Scan(&raw); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(raw, encryptedPrefix) {
+		t.Errorf("legacy value stored as %q after open, want it encrypted", raw)
+	}
+	vars, err := reopened.GetEnvVars("m1")
+	if err != nil || len(vars) != 1 || vars[0].Value != "sk_legacy" {
+		t.Errorf("GetEnvVars() = %+v, %v, want API_KEY = sk_legacy", vars, err)
+	}
+}
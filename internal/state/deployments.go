@@ -0,0 +1,95 @@
+package state
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Deployment is a preview deployment made for a migration
+type Deployment struct {
+	ID          string    `json:"id"`
+	MigrationID string    `json:"migration_id"`
+	Provider    string    `json:"provider"`
+	URL         string    `json:"url"`
+	Status      string    `json:"status"`
+	BuildTime   *int      `json:"build_time,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// SaveDeployment records a deployment. Saving the same ID again updates its
+// URL, status, and build time, e.g. once a build finishes.
+func (d *DB) SaveDeployment(deployment *Deployment) error {
+	return d.SaveDeploymentContext(context.Background(), deployment)
+}
+
+// SaveDeploymentContext is SaveDeployment with a context for cancellation
+func (d *DB) SaveDeploymentContext(ctx context.Context, deployment *Deployment) error {
+	_, err := d.db.ExecContext(ctx, `
+		INSERT INTO deployments (id, migration_id, provider, url, status, build_time)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE
+		SET url = excluded.url, status = excluded.status, build_time = excluded.build_time,
+			updated_at = CURRENT_TIMESTAMP
+	`, deployment.ID, deployment.MigrationID, deployment.Provider, deployment.URL, deployment.Status, deployment.BuildTime)
+	if err != nil {
+		return fmt.Errorf("failed to save deployment %s: %w", deployment.ID, err)
+	}
+	return nil
+}
+
+// ListDeployments returns a migration's deployments, oldest first
+func (d *DB) ListDeployments(migrationID string) ([]Deployment, error) {
+	return d.ListDeploymentsContext(context.Background(), migrationID)
+}
+
+// ListDeploymentsContext is ListDeployments with a context for cancellation
+func (d *DB) ListDeploymentsContext(ctx context.Context, migrationID string) ([]Deployment, error) {
+	rows, err := d.db.QueryContext(ctx, `
+		SELECT id, migration_id, provider, url, status, build_time, created_at, updated_at
+		FROM deployments WHERE migration_id = ?
+		ORDER BY created_at, rowid
+	`, migrationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deployments []Deployment
+	for rows.Next() {
+		dep, err := scanDeployment(rows)
+		if err != nil {
+			return nil, err
+		}
+		deployments = append(deployments, *dep)
+	}
+	return deployments, rows.Err()
+}
+
+// GetDeploymentContext returns a deployment by ID, or nil if it isn't recorded
+func (d *DB) GetDeploymentContext(ctx context.Context, id string) (*Deployment, error) {
+	row := d.db.QueryRowContext(ctx, `
+		SELECT id, migration_id, provider, url, status, build_time, created_at, updated_at
+		FROM deployments WHERE id = ?
+	`, id)
+	dep, err := scanDeployment(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return dep, err
+}
+
+func scanDeployment(row interface{ Scan(...interface{}) error }) (*Deployment, error) {
+	var dep Deployment
+	var buildTime sql.NullInt64
+	if err := row.Scan(&dep.ID, &dep.MigrationID, &dep.Provider, &dep.URL, &dep.Status, &buildTime, &dep.CreatedAt, &dep.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if buildTime.Valid {
+		seconds := int(buildTime.Int64)
+		dep.BuildTime = &seconds
+	}
+	return &dep, nil
+}
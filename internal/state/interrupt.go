@@ -0,0 +1,53 @@
+package state
+
+import (
+	"fmt"
+	"sync"
+)
+
+// runningSet tracks the migrations this process has set in_progress, so an
+// interrupted command knows which ones it left half done
+type runningSet struct {
+	mu  sync.Mutex
+	ids map[string]bool
+}
+
+// track records a status change made through this DB
+func (r *runningSet) track(id, status string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if status != "in_progress" {
+		delete(r.ids, id)
+		return
+	}
+	if r.ids == nil {
+		r.ids = make(map[string]bool)
+	}
+	r.ids[id] = true
+}
+
+func (r *runningSet) list() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ids := make([]string, 0, len(r.ids))
+	for id := range r.ids {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// MarkInterrupted fails every migration this DB set in_progress and hasn't
+// moved on since, logging reason against each, so a resume starts from its
+// last checkpoint instead of treating it as still running. It returns the
+// IDs it marked.
+func (d *DB) MarkInterrupted(reason string) ([]string, error) {
+	ids := d.running.list()
+	for _, id := range ids {
+		migrationID := id
+		d.LogJSON(&migrationID, "error", fmt.Sprintf("interrupted: %s", reason), nil)
+		if err := d.UpdateMigrationStatus(id, "failed"); err != nil {
+			return ids, fmt.Errorf("failed to mark migration %s interrupted: %w", id, err)
+		}
+	}
+	return ids, nil
+}
@@ -0,0 +1,116 @@
+package state
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"syscall"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// memoryPath is what Path reports for an in-memory database
+const memoryPath = ":memory:"
+
+// OpenInMemory opens an empty database that lives only as long as the DB,
+// with a random encryption key. Nothing written to it persists.
+func OpenInMemory() (*DB, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate encryption key: %w", err)
+	}
+	box, err := newCipherBox(key)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open("sqlite3", memoryPath+"?_foreign_keys=on")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	// Each connection to :memory: is its own database, so keep exactly one
+	// open for the life of the DB
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(1)
+	db.SetConnMaxLifetime(0)
+
+	return setup(db, memoryPath, box)
+}
+
+// OpenOrInMemory opens the state database like Open, falling back to an
+// in-memory one only when the config dir or database can't be written
+// (e.g. a read-only mount), so commands that don't need saved state still
+// run. Any other error, such as a missing key or a corrupt database, is
+// returned. Fallback reports why it fell back.
+func OpenOrInMemory(configDir string) (*DB, error) {
+	d, err := Open(configDir)
+	if err == nil {
+		return d, nil
+	}
+	if !notWritable(err, configDir) {
+		return nil, err
+	}
+
+	mem, memErr := OpenInMemory()
+	if memErr != nil {
+		return nil, err
+	}
+	mem.fallback = err
+	return mem, nil
+}
+
+// notWritable reports whether err means the config dir or the database in
+// it refuses writes
+func notWritable(err error, configDir string) bool {
+	if isPermission(err) {
+		return true
+	}
+	var sqliteErr sqlite3.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	switch sqliteErr.Code {
+	case sqlite3.ErrReadonly, sqlite3.ErrPerm:
+		return true
+	case sqlite3.ErrCantOpen:
+		// SQLite doesn't say why it couldn't create the file, so check
+		// whether the dir takes new files at all
+		return !dirWritable(configDir)
+	}
+	return false
+}
+
+func isPermission(err error) bool {
+	return errors.Is(err, fs.ErrPermission) || errors.Is(err, syscall.EROFS)
+}
+
+// dirWritable reports whether a file can be created in the config dir; it
+// is only false when that is refused
+func dirWritable(configDir string) bool {
+	dir, err := ResolveConfigDir(configDir)
+	if err != nil {
+		return true
+	}
+	f, err := os.CreateTemp(dir, ".write-test-*")
+	if err != nil {
+		return !isPermission(err)
+	}
+	f.Close()
+	os.Remove(f.Name())
+	return true
+}
+
+// InMemory reports whether the database is in memory, so nothing written
+// to it outlives the process
+func (d *DB) InMemory() bool {
+	return d.path == memoryPath
+}
+
+// Fallback returns why OpenOrInMemory couldn't use the on-disk database,
+// or nil if it did
+func (d *DB) Fallback() error {
+	return d.fallback
+}
@@ -0,0 +1,93 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/johnhorton/deploy-tunnel/internal/keychain"
+	"github.com/mattn/go-sqlite3"
+)
+
+func TestOpenOrInMemoryReadOnlyDir(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root can write to a read-only dir")
+	}
+	t.Setenv(keychain.EnvBackend, keychain.BackendFile)
+	t.Setenv(keychain.EnvKey, "test")
+	keychain.SetDir(t.TempDir())
+
+	dir := filepath.Join(t.TempDir(), "state")
+	if err := os.Mkdir(dir, 0500); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chmod(dir, 0700) })
+
+	db, err := OpenOrInMemory(dir)
+	if err != nil {
+		t.Fatalf("OpenOrInMemory() = %v, want an in-memory fallback", err)
+	}
+	defer db.Close()
+
+	if !db.InMemory() {
+		t.Errorf("db is at %s, want in memory", db.Path())
+	}
+	if db.Fallback() == nil {
+		t.Error("Fallback() = nil, want the open error")
+	}
+	if err := db.CreateMigration("m1", "vercel", "netlify", "example.com"); err != nil {
+		t.Errorf("in-memory db rejected a write: %v", err)
+	}
+}
+
+func TestNotWritable(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "permission denied",
+			err:  fmt.Errorf("failed to create config dir: %w", &fs.PathError{Op: "mkdir", Path: dir, Err: syscall.EACCES}),
+			want: true,
+		},
+		{
+			name: "read-only file system",
+			err:  fmt.Errorf("failed to write credentials key: %w", &fs.PathError{Op: "open", Path: dir, Err: syscall.EROFS}),
+			want: true,
+		},
+		{
+			name: "read-only database",
+			err:  fmt.Errorf("failed to create schema: %w", sqlite3.Error{Code: sqlite3.ErrReadonly}),
+			want: true,
+		},
+		{
+			name: "can't open in a writable dir",
+			err:  fmt.Errorf("failed to open database: %w", sqlite3.Error{Code: sqlite3.ErrCantOpen}),
+			want: false,
+		},
+		{
+			name: "corrupt database",
+			err:  fmt.Errorf("failed to open database: %w", sqlite3.Error{Code: sqlite3.ErrCorrupt}),
+			want: false,
+		},
+		{
+			name: "missing key",
+			err:  fmt.Errorf("failed to load database encryption key: %w", errors.New("the system keychain is unavailable")),
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := notWritable(tt.err, dir); got != tt.want {
+				t.Errorf("notWritable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package state
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// schemaMigrations alter the base schema in order. Entry i brings the
+// database to version i+1, which is recorded in PRAGMA user_version.
+// Append only: never edit or reorder entries that have shipped.
+var schemaMigrations = []string{
+	// 1: soft-delete for migrations
+	`ALTER TABLE migrations ADD COLUMN archived_at TIMESTAMP`,
+
+	// 2: workflow checkpoints for resuming interrupted migrations
+	`CREATE TABLE migration_steps (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		migration_id TEXT NOT NULL,
+		step TEXT NOT NULL,
+		data TEXT,
+		completed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE (migration_id, step),
+		FOREIGN KEY (migration_id) REFERENCES migrations(id) ON DELETE CASCADE
+	)`,
+
+	// 3: per-variable opt-out from the env sync
+	`ALTER TABLE env_vars ADD COLUMN excluded INTEGER NOT NULL DEFAULT 0`,
+
+	// 4: small key/value store for UI preferences such as the last menu item
+	`CREATE TABLE ui_state (
+		key TEXT PRIMARY KEY,
+		value TEXT NOT NULL,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	)`,
+
+	// 5: the value a DNS record held before an update, so it can be rolled
+	// back, and the provider's ID for the record apart from the local row ID,
+	// so the same provider record can be updated more than once
+	`ALTER TABLE dns_records ADD COLUMN previous_value TEXT;
+	ALTER TABLE dns_records ADD COLUMN provider_record_id TEXT`,
+
+	// 6: preview deployments, so their URLs can be revisited
+	`CREATE TABLE deployments (
+		id TEXT PRIMARY KEY,
+		migration_id TEXT NOT NULL,
+		provider TEXT NOT NULL,
+		url TEXT NOT NULL,
+		status TEXT NOT NULL,
+		build_time INTEGER,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		FOREIGN KEY (migration_id) REFERENCES migrations(id) ON DELETE CASCADE
+	)`,
+
+	// 7: list a migration's deployments without a table scan
+	`CREATE INDEX idx_deployments_migration ON deployments(migration_id)`,
+
+	// 8: the environments a variable is set for on the source, comma separated
+	`ALTER TABLE env_vars ADD COLUMN environments TEXT`,
+
+	// 9: env vars already pushed to a target, so a re-run sync can skip them.
+	// fingerprint is EnvFingerprint's HMAC, keyed from the database key, never
+	// a plain hash of the value.
+	`CREATE TABLE synced_env (
+		migration_id TEXT NOT NULL,
+		provider TEXT NOT NULL,
+		key TEXT NOT NULL,
+		fingerprint TEXT NOT NULL,
+		synced_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (migration_id, provider, key),
+		FOREIGN KEY (migration_id) REFERENCES migrations(id) ON DELETE CASCADE
+	)`,
+
+	// 10: how long past preview builds took, to estimate the next one
+	`CREATE TABLE build_times (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		provider TEXT NOT NULL,
+		project_id TEXT NOT NULL,
+		seconds INTEGER NOT NULL,
+		recorded_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	)`,
+
+	// 11: look up a project's build times without a table scan
+	`CREATE INDEX idx_build_times_project ON build_times(provider, project_id)`,
+}
+
+// applySchemaMigrations runs any migrations newer than the database's version
+func applySchemaMigrations(db *sql.DB) error {
+	var version int
+	if err := db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return err
+	}
+
+	for i := version; i < len(schemaMigrations); i++ {
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(schemaMigrations[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("schema migration %d: %w", i+1, err)
+		}
+		if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", i+1)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("schema migration %d: %w", i+1, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// LatestSchemaVersion is the schema version this build migrates databases to
+func LatestSchemaVersion() int {
+	return len(schemaMigrations)
+}
+
+// SchemaVersion returns the schema version recorded in the database. It is
+// higher than LatestSchemaVersion when a newer build last opened the file.
+func (d *DB) SchemaVersion(ctx context.Context) (int, error) {
+	var version int
+	if err := d.db.QueryRowContext(ctx, "PRAGMA user_version").Scan(&version); err != nil {
+		return 0, err
+	}
+	return version, nil
+}
+
+// CheckIntegrity runs SQLite's quick_check and returns an error describing
+// the first problem found, if any
+func (d *DB) CheckIntegrity(ctx context.Context) error {
+	var result string
+	if err := d.db.QueryRowContext(ctx, "PRAGMA quick_check").Scan(&result); err != nil {
+		return fmt.Errorf("failed to run integrity check: %w", err)
+	}
+	if result != "ok" {
+		return fmt.Errorf("database is corrupt: %s", result)
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package state
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"unicode/utf8"
+)
+
+// ftsSchema mirrors logs(message, metadata) into an external-content FTS5
+// table kept in sync by triggers. The trigram tokenizer matches substrings
+// ignoring case, as the LIKE fallback does.
+const ftsSchema = `
+CREATE VIRTUAL TABLE IF NOT EXISTS logs_fts USING fts5(
+	message, metadata, content='logs', content_rowid='id', tokenize='trigram'
+);
+
+CREATE TRIGGER IF NOT EXISTS logs_fts_insert AFTER INSERT ON logs BEGIN
+	INSERT INTO logs_fts(rowid, message, metadata) VALUES (new.id, new.message, new.metadata);
+END;
+
+CREATE TRIGGER IF NOT EXISTS logs_fts_delete AFTER DELETE ON logs BEGIN
+	INSERT INTO logs_fts(logs_fts, rowid, message, metadata) VALUES ('delete', old.id, old.message, old.metadata);
+END;
+
+CREATE TRIGGER IF NOT EXISTS logs_fts_update AFTER UPDATE ON logs BEGIN
+	INSERT INTO logs_fts(logs_fts, rowid, message, metadata) VALUES ('delete', old.id, old.message, old.metadata);
+	INSERT INTO logs_fts(rowid, message, metadata) VALUES (new.id, new.message, new.metadata);
+END;
+`
+
+// minFTSTerm is the shortest term the trigram index can match
+const minFTSTerm = 3
+
+// setupFTS creates the full-text index if the driver was built with FTS5
+// (the sqlite_fts5 build tag). It reports false, without error, when FTS5 is
+// unavailable.
+func setupFTS(db *sql.DB) (bool, error) {
+	// Without the insert trigger the index is missing or stale, e.g. after
+	// a build without FTS5 wrote logs
+	var synced int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'trigger' AND name = 'logs_fts_insert'`).Scan(&synced); err != nil {
+		return false, err
+	}
+
+	if _, err := db.Exec(ftsSchema); err != nil {
+		if strings.Contains(err.Error(), "no such module") {
+			// A database indexed by an FTS5-enabled build would otherwise
+			// fail every log insert through these triggers
+			_, err := db.Exec(`
+				DROP TRIGGER IF EXISTS logs_fts_insert;
+				DROP TRIGGER IF EXISTS logs_fts_delete;
+				DROP TRIGGER IF EXISTS logs_fts_update;
+			`)
+			return false, err
+		}
+		return false, err
+	}
+
+	if synced == 0 {
+		if _, err := db.Exec(`INSERT INTO logs_fts(logs_fts) VALUES ('rebuild')`); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// SearchLogs returns logs for a migration whose message or metadata match query.
+// Bare words must all match; double-quoted text matches as a phrase.
+func (d *DB) SearchLogs(migrationID, query string, limit int) ([]LogEntry, error) {
+	return d.SearchLogsContext(context.Background(), migrationID, query, limit)
+}
+
+// SearchLogsContext is SearchLogs with a context for cancellation
+func (d *DB) SearchLogsContext(ctx context.Context, migrationID, query string, limit int) ([]LogEntry, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+
+	terms := parseSearchTerms(query)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	var rows *sql.Rows
+	var err error
+	if d.fts && ftsSearchable(terms) {
+		quoted := make([]string, len(terms))
+		for i, term := range terms {
+			quoted[i] = `"` + strings.ReplaceAll(term, `"`, `""`) + `"`
+		}
+
+		rows, err = d.db.QueryContext(ctx, `
+			SELECT l.id, l.migration_id, l.level, l.message, l.metadata, l.ts
+			FROM logs l JOIN logs_fts f ON f.rowid = l.id
+			WHERE logs_fts MATCH ? AND l.migration_id = ?
+			ORDER BY l.ts DESC LIMIT ?
+		`, strings.Join(quoted, " "), migrationID, limit)
+	} else {
+		sqlQuery := "SELECT id, migration_id, level, message, metadata, ts FROM logs WHERE migration_id = ?"
+		args := []interface{}{migrationID}
+		for _, term := range terms {
+			pattern := "%" + escapeLike(term) + "%"
+			sqlQuery += ` AND (message LIKE ? ESCAPE '\' OR metadata LIKE ? ESCAPE '\')`
+			args = append(args, pattern, pattern)
+		}
+		sqlQuery += " ORDER BY ts DESC LIMIT ?"
+		args = append(args, limit)
+
+		rows, err = d.db.QueryContext(ctx, sqlQuery, args...)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanLogs(rows)
+}
+
+// ftsSearchable reports whether the trigram index can match every term;
+// shorter ones are searched with LIKE instead
+func ftsSearchable(terms []string) bool {
+	for _, term := range terms {
+		if utf8.RuneCountInString(term) < minFTSTerm {
+			return false
+		}
+	}
+	return true
+}
+
+// parseSearchTerms splits a query into bare words and "quoted phrases"
+func parseSearchTerms(query string) []string {
+	var terms []string
+	for i, part := range strings.Split(query, `"`) {
+		if i%2 == 1 {
+			// Inside quotes: keep the phrase intact
+			if phrase := strings.TrimSpace(part); phrase != "" {
+				terms = append(terms, phrase)
+			}
+			continue
+		}
+		terms = append(terms, strings.Fields(part)...)
+	}
+	return terms
+}
+
+func escapeLike(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+	return r.Replace(s)
+}
@@ -0,0 +1,91 @@
+//go:build sqlite_fts5
+
+package state
+
+import (
+	"bytes"
+	"testing"
+)
+
+// searchMessages returns the messages of m1's logs matching query
+func searchMessages(t *testing.T, db *DB, query string) []string {
+	t.Helper()
+	logs, err := db.SearchLogs("m1", query, 0)
+	if err != nil {
+		t.Fatalf("SearchLogs(%q): %v", query, err)
+	}
+	var got []string
+	for _, l := range logs {
+		got = append(got, l.Message)
+	}
+	return got
+}
+
+func TestLogSearchIndexStaysInSync(t *testing.T) {
+	db := openWithKey(t, bytes.Repeat([]byte{1}, 32))
+	if !db.fts {
+		t.Fatal("a sqlite_fts5 build didn't create the log search index")
+	}
+	if err := db.CreateMigration("m1", "vercel", "netlify", "example.com"); err != nil {
+		t.Fatal(err)
+	}
+	m1 := "m1"
+	if err := db.Log(&m1, "info", "cutover started", ""); err != nil {
+		t.Fatal(err)
+	}
+	if got := searchMessages(t, db, "cutover"); len(got) != 1 {
+		t.Fatalf("search after insert = %q, want the new log", got)
+	}
+
+	if _, err := db.db.Exec(`UPDATE logs SET message = 'rollback started'`); err != nil {
+		t.Fatal(err)
+	}
+	if got := searchMessages(t, db, "cutover"); len(got) != 0 {
+		t.Errorf("search for the old message = %q after update, want nothing", got)
+	}
+	if got := searchMessages(t, db, "rollback"); len(got) != 1 {
+		t.Errorf("search for the new message = %q after update, want it", got)
+	}
+
+	if _, err := db.db.Exec(`DELETE FROM logs`); err != nil {
+		t.Fatal(err)
+	}
+	if got := searchMessages(t, db, "rollback"); len(got) != 0 {
+		t.Errorf("search = %q after delete, want nothing", got)
+	}
+}
+
+func TestLogSearchIndexRebuilt(t *testing.T) {
+	dir := t.TempDir()
+	key := bytes.Repeat([]byte{1}, 32)
+	db, err := OpenWithKey(dir, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.CreateMigration("m1", "vercel", "netlify", "example.com"); err != nil {
+		t.Fatal(err)
+	}
+
+	// As a build without FTS5 leaves it: no triggers, so logs go unindexed
+	if _, err := db.db.Exec(`
+		DROP TRIGGER logs_fts_insert;
+		DROP TRIGGER logs_fts_delete;
+		DROP TRIGGER logs_fts_update;
+	`); err != nil {
+		t.Fatal(err)
+	}
+	m1 := "m1"
+	if err := db.Log(&m1, "info", "written without the index", ""); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	db, err = OpenWithKey(dir, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if got := searchMessages(t, db, "without"); len(got) != 1 {
+		t.Errorf("search after reopening = %q, want the unindexed log found", got)
+	}
+}
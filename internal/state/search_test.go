@@ -0,0 +1,128 @@
+package state
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+)
+
+func TestSearchLogs(t *testing.T) {
+	db := openWithKey(t, bytes.Repeat([]byte{1}, 32))
+	if err := db.CreateMigration("m1", "vercel", "netlify", "example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.CreateMigration("m2", "vercel", "netlify", "example.org"); err != nil {
+		t.Fatal(err)
+	}
+	m1, m2 := "m1", "m2"
+	entries := []struct {
+		migrationID *string
+		message     string
+		metadata    map[string]interface{}
+	}{
+		{migrationID: &m1, message: "DNS update failed for www"},
+		{migrationID: &m1, message: "env sync progress 100% done"},
+		{migrationID: &m1, message: "env sync progress: 50 done"},
+		{migrationID: &m1, message: "deployed preview", metadata: map[string]interface{}{"url": "https://app_preview.example.dev"}},
+		{migrationID: &m1, message: `path C:\deploy\app`},
+		{migrationID: &m2, message: "DNS update failed for api"},
+	}
+	for _, e := range entries {
+		if err := db.LogJSON(e.migrationID, "info", e.message, e.metadata); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{name: "word", query: "failed", want: []string{"DNS update failed for www"}},
+		{name: "ignores case", query: "dns", want: []string{"DNS update failed for www"}},
+		{name: "all words must match", query: "env 100%", want: []string{"env sync progress 100% done"}},
+		{name: "phrase", query: `"update failed"`, want: []string{"DNS update failed for www"}},
+		{name: "phrase order matters", query: `"failed update"`},
+		{name: "metadata", query: "app_preview", want: []string{"deployed preview"}},
+		{name: "percent is literal", query: "%", want: []string{"env sync progress 100% done"}},
+		{name: "underscore is literal", query: "_", want: []string{"deployed preview"}},
+		{name: "backslash is literal", query: `C:\deploy`, want: []string{`path C:\deploy\app`}},
+		{name: "no match", query: "cutover"},
+		{name: "other migration", query: "api"},
+		{name: "empty query", query: `  "" `},
+	}
+
+	// Both search paths must agree; the index only exists in sqlite_fts5 builds
+	modes := map[string]bool{"like": false}
+	if db.fts {
+		modes["fts5"] = true
+	}
+	defer func(fts bool) { db.fts = fts }(db.fts)
+
+	for mode, fts := range modes {
+		db.fts = fts
+		for _, tt := range tests {
+			t.Run(mode+"/"+tt.name, func(t *testing.T) {
+				logs, err := db.SearchLogs("m1", tt.query, 0)
+				if err != nil {
+					t.Fatalf("SearchLogs(%q): %v", tt.query, err)
+				}
+				var got []string
+				for _, l := range logs {
+					got = append(got, l.Message)
+				}
+				if !slices.Equal(got, tt.want) {
+					t.Errorf("SearchLogs(%q) = %q, want %q", tt.query, got, tt.want)
+				}
+			})
+		}
+	}
+}
+
+func TestSearchMigrations(t *testing.T) {
+	db := openWithKey(t, bytes.Repeat([]byte{1}, 32))
+	for _, m := range []struct{ id, source, target, domain string }{
+		{"m1", "vercel", "netlify", "Shop.Example.com"},
+		{"m2", "netlify", "cloudflare", "blog.example.org"},
+		{"m3", "vercel", "cloudflare", "my_site.dev"},
+		{"m4", "vercel", "netlify", "archived.example.com"},
+	} {
+		if err := db.CreateMigration(m.id, m.source, m.target, m.domain); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.ArchiveMigration("m4"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{name: "domain ignores case", query: "SHOP", want: []string{"m1"}},
+		{name: "source or target", query: "netlify", want: []string{"m2", "m1"}},
+		{name: "newest first", query: "example", want: []string{"m2", "m1"}},
+		{name: "underscore is literal", query: "_", want: []string{"m3"}},
+		{name: "percent is literal", query: "%"},
+		{name: "trimmed", query: "  blog ", want: []string{"m2"}},
+		{name: "archived excluded", query: "archived"},
+		{name: "empty matches every one", query: "", want: []string{"m3", "m2", "m1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrations, err := db.SearchMigrations(tt.query)
+			if err != nil {
+				t.Fatalf("SearchMigrations(%q): %v", tt.query, err)
+			}
+			var got []string
+			for _, m := range migrations {
+				got = append(got, m.ID)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("SearchMigrations(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,18 +1,27 @@
 package state
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/johnhorton/deploy-tunnel/internal/events"
+	"github.com/johnhorton/deploy-tunnel/internal/keychain"
+	"github.com/johnhorton/deploy-tunnel/internal/redact"
 	_ "github.com/mattn/go-sqlite3"
 )
 
 const (
-	dbFileName = "state.db"
-	schema     = `
+	dbFileName    = "state.db"
+	busyTimeoutMs = 5000
+	maxOpenConns  = 4
+	schema        = `
 CREATE TABLE IF NOT EXISTS migrations (
 	id TEXT PRIMARY KEY,
 	source TEXT NOT NULL,
@@ -65,19 +74,28 @@ CREATE INDEX IF NOT EXISTS idx_logs_ts ON logs(ts);
 
 // DB wraps the SQLite database
 type DB struct {
-	db   *sql.DB
-	path string
+	db     *sql.DB
+	path   string
+	cipher *cipherBox
+	// fts is whether logs have a full-text index; SearchLogs uses LIKE without
+	fts bool
+	// running is what MarkInterrupted fails on shutdown
+	running runningSet
+	// fallback is why the on-disk database couldn't be used, when this one
+	// is in memory instead; see OpenOrInMemory
+	fallback error
 }
 
 // Migration represents a migration record
 type Migration struct {
-	ID        string    `json:"id"`
-	Source    string    `json:"source"`
-	Target    string    `json:"target"`
-	Domain    string    `json:"domain"`
-	Status    string    `json:"status"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID         string     `json:"id"`
+	Source     string     `json:"source"`
+	Target     string     `json:"target"`
+	Domain     string     `json:"domain"`
+	Status     string     `json:"status"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
 }
 
 // EnvVar represents an environment variable mapping
@@ -87,39 +105,69 @@ type EnvVar struct {
 	Key         string `json:"key"`
 	Value       string `json:"value"`
 	TargetKey   string `json:"target_key,omitempty"`
+	Excluded    bool   `json:"excluded,omitempty"`
+	// Environments the source sets the variable for; empty means all of them
+	Environments []string `json:"environments,omitempty"`
+}
+
+// SyncKey is the key the variable is written under on the target
+func (e EnvVar) SyncKey() string {
+	if e.TargetKey != "" {
+		return e.TargetKey
+	}
+	return e.Key
 }
 
 // DnsRecord represents a DNS record
 type DnsRecord struct {
-	ID          string    `json:"id"`
-	MigrationID *string   `json:"migration_id,omitempty"`
-	Domain      string    `json:"domain"`
-	RecordType  string    `json:"record_type"`
-	RecordName  string    `json:"record_name"`
-	RecordValue string    `json:"record_value"`
-	TTL         int       `json:"ttl"`
-	RollbackID  *string   `json:"rollback_id,omitempty"`
-	CreatedAt   time.Time `json:"created_at"`
+	// ID is local; SaveDnsRecord fills it in when empty
+	ID string `json:"id"`
+	// ProviderRecordID is the record's ID at the provider, for provider calls
+	ProviderRecordID string  `json:"provider_record_id"`
+	MigrationID      *string `json:"migration_id,omitempty"`
+	Domain           string  `json:"domain"`
+	RecordType       string  `json:"record_type"`
+	RecordName       string  `json:"record_name"`
+	RecordValue      string  `json:"record_value"`
+	TTL              int     `json:"ttl"`
+	RollbackID       *string `json:"rollback_id,omitempty"`
+	// PreviousValue is what the record held before this change, for rollback
+	PreviousValue *string   `json:"previous_value,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
 }
 
 // LogEntry represents a log entry
 type LogEntry struct {
-	ID          int       `json:"id"`
-	MigrationID *string   `json:"migration_id,omitempty"`
-	Level       string    `json:"level"`
-	Message     string    `json:"message"`
-	Metadata    *string   `json:"metadata,omitempty"`
-	Timestamp   time.Time `json:"timestamp"`
+	ID          int                    `json:"id"`
+	MigrationID *string                `json:"migration_id,omitempty"`
+	Level       string                 `json:"level"`
+	Message     string                 `json:"message"`
+	Metadata    *string                `json:"metadata,omitempty"`
+	Fields      map[string]interface{} `json:"fields,omitempty"`
+	Timestamp   time.Time              `json:"timestamp"`
 }
 
-// Open opens or creates the state database
+// Open opens or creates the state database, using the encryption key
+// stored in the system keychain
 func Open(configDir string) (*DB, error) {
-	if configDir == "" {
-		home, err := os.UserHomeDir()
-		if err != nil {
-			return nil, fmt.Errorf("failed to get home dir: %w", err)
-		}
-		configDir = filepath.Join(home, ".deploy-tunnel")
+	key, err := keychain.GetOrCreateDBKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load database encryption key: %w", err)
+	}
+	return OpenWithKey(configDir, key)
+}
+
+// OpenWithKey opens or creates the state database using the given
+// AES key (16, 24 or 32 bytes) for env var encryption
+func OpenWithKey(configDir string, key []byte) (*DB, error) {
+	box, err := newCipherBox(key)
+	if err != nil {
+		return nil, err
+	}
+
+	configDir, err = ResolveConfigDir(configDir)
+	if err != nil {
+		return nil, err
 	}
 
 	// Ensure config directory exists
@@ -128,15 +176,28 @@ func Open(configDir string) (*DB, error) {
 	}
 
 	dbPath := filepath.Join(configDir, dbFileName)
-	db, err := sql.Open("sqlite3", dbPath)
+
+	// Pragmas go in the DSN so every pooled connection gets them, not just the first.
+	// WAL lets readers proceed during a write; busy_timeout waits out brief write locks.
+	dsn := fmt.Sprintf("%s?_foreign_keys=on&_journal_mode=WAL&_busy_timeout=%d", dbPath, busyTimeoutMs)
+	db, err := sql.Open("sqlite3", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
-	// Enable foreign keys
-	if _, err := db.Exec("PRAGMA foreign_keys = ON"); err != nil {
+	// SQLite serializes writers anyway; a small pool is enough for concurrent reads
+	db.SetMaxOpenConns(maxOpenConns)
+	db.SetMaxIdleConns(maxOpenConns)
+	db.SetConnMaxLifetime(time.Hour)
+
+	return setup(db, dbPath, box)
+}
+
+// setup creates or migrates the schema on a freshly opened database
+func setup(db *sql.DB, dbPath string, box *cipherBox) (*DB, error) {
+	if err := db.Ping(); err != nil {
 		db.Close()
-		return nil, fmt.Errorf("failed to enable foreign keys: %w", err)
+		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
 	// Create schema
@@ -145,7 +206,44 @@ func Open(configDir string) (*DB, error) {
 		return nil, fmt.Errorf("failed to create schema: %w", err)
 	}
 
-	return &DB{db: db, path: dbPath}, nil
+	if err := applySchemaMigrations(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate schema: %w", err)
+	}
+
+	// Full-text log search, when the driver supports it
+	fts, err := setupFTS(db)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create log search index: %w", err)
+	}
+
+	d := &DB{db: db, path: dbPath, cipher: box, fts: fts}
+
+	if err := d.checkKey(); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	// Encrypt any plaintext values left over from before encryption existed
+	if err := d.encryptLegacyEnvVars(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to encrypt existing env vars: %w", err)
+	}
+
+	return d, nil
+}
+
+// ResolveConfigDir defaults an empty config dir to ~/.deploy-tunnel
+func ResolveConfigDir(configDir string) (string, error) {
+	if configDir != "" {
+		return configDir, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home dir: %w", err)
+	}
+	return filepath.Join(home, ".deploy-tunnel"), nil
 }
 
 // Close closes the database connection
@@ -164,16 +262,26 @@ func (d *DB) CreateMigration(id, source, target, domain string) error {
 		INSERT INTO migrations (id, source, target, domain, status)
 		VALUES (?, ?, ?, ?, 'pending')
 	`, id, source, target, domain)
-	return err
+	if err != nil {
+		return err
+	}
+
+	events.Publish(events.MigrationEvent{ID: id, Type: events.TypeMigrationCreated, Status: "pending"})
+	return nil
 }
 
 // GetMigration retrieves a migration by ID
 func (d *DB) GetMigration(id string) (*Migration, error) {
+	return d.GetMigrationContext(context.Background(), id)
+}
+
+// GetMigrationContext is GetMigration with a context for cancellation
+func (d *DB) GetMigrationContext(ctx context.Context, id string) (*Migration, error) {
 	var m Migration
-	err := d.db.QueryRow(`
-		SELECT id, source, target, domain, status, created_at, updated_at
+	err := d.db.QueryRowContext(ctx, `
+		SELECT id, source, target, domain, status, created_at, updated_at, archived_at
 		FROM migrations WHERE id = ?
-	`, id).Scan(&m.ID, &m.Source, &m.Target, &m.Domain, &m.Status, &m.CreatedAt, &m.UpdatedAt)
+	`, id).Scan(&m.ID, &m.Source, &m.Target, &m.Domain, &m.Status, &m.CreatedAt, &m.UpdatedAt, &m.ArchivedAt)
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -184,38 +292,127 @@ func (d *DB) GetMigration(id string) (*Migration, error) {
 	return &m, nil
 }
 
-// UpdateMigrationStatus updates the status of a migration
+// UpdateMigrationStatus updates the status of a migration, publishing a
+// status change event if the migration exists
 func (d *DB) UpdateMigrationStatus(id, status string) error {
-	_, err := d.db.Exec(`
+	result, err := d.db.Exec(`
 		UPDATE migrations
 		SET status = ?, updated_at = CURRENT_TIMESTAMP
 		WHERE id = ?
 	`, status, id)
+	if err != nil {
+		return err
+	}
+
+	if n, err := result.RowsAffected(); err == nil && n > 0 {
+		d.running.track(id, status)
+		events.Publish(events.MigrationEvent{ID: id, Type: events.TypeStatusChanged, Status: status})
+	}
+	return nil
+}
+
+// ArchiveMigration hides a migration from default listings without deleting its history
+func (d *DB) ArchiveMigration(id string) error {
+	_, err := d.db.Exec(`
+		UPDATE migrations
+		SET archived_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`, id)
+	return err
+}
+
+// UnarchiveMigration restores an archived migration to default listings
+func (d *DB) UnarchiveMigration(id string) error {
+	_, err := d.db.Exec(`
+		UPDATE migrations
+		SET archived_at = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = ?
+	`, id)
 	return err
 }
 
-// ListMigrations lists all migrations, optionally filtered by status
-func (d *DB) ListMigrations(status string) ([]Migration, error) {
-	query := "SELECT id, source, target, domain, status, created_at, updated_at FROM migrations"
+// DeleteMigration removes a migration along with its steps, env vars, and
+// deployments. Logs and DNS records are kept, detached from it.
+func (d *DB) DeleteMigration(id string) error {
+	result, err := d.db.Exec(`DELETE FROM migrations WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete migration: %w", err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("migration not found: %s", id)
+	}
+
+	d.running.track(id, "deleted")
+	events.Publish(events.MigrationEvent{ID: id, Type: events.TypeMigrationDeleted, Status: "deleted"})
+	return nil
+}
+
+// ListMigrations lists migrations, optionally filtered by status.
+// Archived migrations are excluded unless includeArchived is set.
+func (d *DB) ListMigrations(status string, includeArchived bool) ([]Migration, error) {
+	return d.ListMigrationsContext(context.Background(), status, includeArchived)
+}
+
+// ListMigrationsContext is ListMigrations with a context for cancellation
+func (d *DB) ListMigrationsContext(ctx context.Context, status string, includeArchived bool) ([]Migration, error) {
+	query := "SELECT id, source, target, domain, status, created_at, updated_at, archived_at FROM migrations"
+	var conditions []string
 	var args []interface{}
 
 	if status != "" {
-		query += " WHERE status = ?"
+		conditions = append(conditions, "status = ?")
 		args = append(args, status)
 	}
+	if !includeArchived {
+		conditions = append(conditions, "archived_at IS NULL")
+	}
 
-	query += " ORDER BY created_at DESC"
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
 
-	rows, err := d.db.Query(query, args...)
+	query += " ORDER BY created_at DESC, rowid DESC"
+
+	rows, err := d.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
+	return scanMigrations(rows)
+}
+
+// SearchMigrations returns unarchived migrations whose domain, source, or
+// target contains query, ignoring case, newest first. An empty query
+// matches every one.
+func (d *DB) SearchMigrations(query string) ([]Migration, error) {
+	return d.SearchMigrationsContext(context.Background(), query)
+}
+
+// SearchMigrationsContext is SearchMigrations with a context for cancellation
+func (d *DB) SearchMigrationsContext(ctx context.Context, query string) ([]Migration, error) {
+	pattern := "%" + escapeLike(strings.ToLower(strings.TrimSpace(query))) + "%"
+	rows, err := d.db.QueryContext(ctx, `
+		SELECT id, source, target, domain, status, created_at, updated_at, archived_at
+		FROM migrations
+		WHERE archived_at IS NULL AND (
+			LOWER(domain) LIKE ? ESCAPE '\' OR LOWER(source) LIKE ? ESCAPE '\' OR LOWER(target) LIKE ? ESCAPE '\'
+		)
+		ORDER BY created_at DESC, rowid DESC
+	`, pattern, pattern, pattern)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search migrations: %w", err)
+	}
+	defer rows.Close()
+
+	return scanMigrations(rows)
+}
+
+func scanMigrations(rows *sql.Rows) ([]Migration, error) {
 	var migrations []Migration
 	for rows.Next() {
 		var m Migration
-		if err := rows.Scan(&m.ID, &m.Source, &m.Target, &m.Domain, &m.Status, &m.CreatedAt, &m.UpdatedAt); err != nil {
+		if err := rows.Scan(&m.ID, &m.Source, &m.Target, &m.Domain, &m.Status, &m.CreatedAt, &m.UpdatedAt, &m.ArchivedAt); err != nil {
 			return nil, err
 		}
 		migrations = append(migrations, m)
@@ -224,20 +421,80 @@ func (d *DB) ListMigrations(status string) ([]Migration, error) {
 	return migrations, rows.Err()
 }
 
-// SaveEnvVar saves an environment variable mapping
-func (d *DB) SaveEnvVar(migrationID, key, value, targetKey string) error {
-	_, err := d.db.Exec(`
-		INSERT INTO env_vars (migration_id, key, value, target_key)
-		VALUES (?, ?, ?, ?)
-	`, migrationID, key, value, targetKey)
+// CurrentMigration returns the most recently created unarchived migration, or nil if there is none
+func (d *DB) CurrentMigration() (*Migration, error) {
+	migrations, err := d.ListMigrations("", false)
+	if err != nil {
+		return nil, err
+	}
+	if len(migrations) == 0 {
+		return nil, nil
+	}
+	return &migrations[0], nil
+}
+
+// SaveEnvVar saves an environment variable mapping along with the
+// environments the source sets it for (nil for all). The value is encrypted
+// at rest.
+func (d *DB) SaveEnvVar(migrationID, key, value, targetKey string, environments []string) error {
+	encrypted, err := d.cipher.encrypt(value)
+	if err != nil {
+		return err
+	}
+
+	_, err = d.db.Exec(`
+		INSERT INTO env_vars (migration_id, key, value, target_key, environments)
+		VALUES (?, ?, ?, ?, ?)
+	`, migrationID, key, encrypted, targetKey, joinEnvironments(environments))
+	return err
+}
+
+// joinEnvironments stores environments as a comma separated list, or NULL
+// when the variable isn't scoped
+func joinEnvironments(environments []string) interface{} {
+	if len(environments) == 0 {
+		return nil
+	}
+	return strings.Join(environments, ",")
+}
+
+func splitEnvironments(stored sql.NullString) []string {
+	if !stored.Valid || stored.String == "" {
+		return nil
+	}
+	return strings.Split(stored.String, ",")
+}
+
+// UpdateEnvVar updates the value and target key of an existing mapping
+func (d *DB) UpdateEnvVar(id int, value, targetKey string) error {
+	encrypted, err := d.cipher.encrypt(value)
+	if err != nil {
+		return err
+	}
+
+	_, err = d.db.Exec(`
+		UPDATE env_vars SET value = ?, target_key = ? WHERE id = ?
+	`, encrypted, targetKey, id)
+	return err
+}
+
+// SetEnvVarExcluded marks whether a mapping is left out of the env sync
+func (d *DB) SetEnvVarExcluded(id int, excluded bool) error {
+	_, err := d.db.Exec(`UPDATE env_vars SET excluded = ? WHERE id = ?`, excluded, id)
 	return err
 }
 
 // GetEnvVars retrieves all environment variables for a migration
 func (d *DB) GetEnvVars(migrationID string) ([]EnvVar, error) {
-	rows, err := d.db.Query(`
-		SELECT id, migration_id, key, value, target_key
+	return d.GetEnvVarsContext(context.Background(), migrationID)
+}
+
+// GetEnvVarsContext is GetEnvVars with a context for cancellation
+func (d *DB) GetEnvVarsContext(ctx context.Context, migrationID string) ([]EnvVar, error) {
+	rows, err := d.db.QueryContext(ctx, `
+		SELECT id, migration_id, key, value, target_key, excluded, environments
 		FROM env_vars WHERE migration_id = ?
+		ORDER BY id
 	`, migrationID)
 	if err != nil {
 		return nil, err
@@ -247,29 +504,122 @@ func (d *DB) GetEnvVars(migrationID string) ([]EnvVar, error) {
 	var envVars []EnvVar
 	for rows.Next() {
 		var e EnvVar
-		if err := rows.Scan(&e.ID, &e.MigrationID, &e.Key, &e.Value, &e.TargetKey); err != nil {
+		var environments sql.NullString
+		if err := rows.Scan(&e.ID, &e.MigrationID, &e.Key, &e.Value, &e.TargetKey, &e.Excluded, &environments); err != nil {
 			return nil, err
 		}
+		e.Environments = splitEnvironments(environments)
+		if e.Value, err = d.cipher.decrypt(e.Value); err != nil {
+			return nil, fmt.Errorf("env var %s: %w", e.Key, err)
+		}
 		envVars = append(envVars, e)
 	}
 
 	return envVars, rows.Err()
 }
 
-// SaveDnsRecord saves a DNS record
+// checkKey makes sure the key can decrypt the values already encrypted in
+// the database, so a lost or different key fails here instead of mixing
+// values encrypted under two keys
+func (d *DB) checkKey() error {
+	var value string
+	err := d.db.QueryRow(`SELECT value FROM env_vars WHERE value LIKE ? LIMIT 1`, encryptedPrefix+"%").Scan(&value)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := d.cipher.decrypt(value); err != nil {
+		return fmt.Errorf("env vars in %s were encrypted with a different key; unlock the keychain that holds its key and retry, or move the database aside to start fresh", d.path)
+	}
+	return nil
+}
+
+// encryptLegacyEnvVars encrypts plaintext env var values in place
+func (d *DB) encryptLegacyEnvVars() error {
+	rows, err := d.db.Query(`SELECT id, value FROM env_vars WHERE value NOT LIKE ?`, encryptedPrefix+"%")
+	if err != nil {
+		return err
+	}
+
+	type legacyRow struct {
+		id    int
+		value string
+	}
+	var legacy []legacyRow
+	for rows.Next() {
+		var r legacyRow
+		if err := rows.Scan(&r.id, &r.value); err != nil {
+			rows.Close()
+			return err
+		}
+		legacy = append(legacy, r)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if len(legacy) == 0 {
+		return nil
+	}
+
+	tx, err := d.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, r := range legacy {
+		encrypted, err := d.cipher.encrypt(r.value)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`UPDATE env_vars SET value = ? WHERE id = ?`, encrypted, r.id); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// dnsRecordColumns are the columns scanDnsRecord reads, in order
+const dnsRecordColumns = "id, migration_id, domain, record_type, record_name, record_value, ttl, rollback_id, previous_value, COALESCE(provider_record_id, ''), created_at"
+
+func scanDnsRecord(row interface{ Scan(...interface{}) error }) (*DnsRecord, error) {
+	var r DnsRecord
+	if err := row.Scan(&r.ID, &r.MigrationID, &r.Domain, &r.RecordType, &r.RecordName, &r.RecordValue, &r.TTL, &r.RollbackID, &r.PreviousValue, &r.ProviderRecordID, &r.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// SaveDnsRecord saves a DNS record as a new row, giving it a local ID when
+// it has none
 func (d *DB) SaveDnsRecord(record *DnsRecord) error {
+	if record.ID == "" {
+		record.ID = uuid.New().String()
+	}
 	_, err := d.db.Exec(`
-		INSERT INTO dns_records (id, migration_id, domain, record_type, record_name, record_value, ttl, rollback_id)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
-	`, record.ID, record.MigrationID, record.Domain, record.RecordType, record.RecordName, record.RecordValue, record.TTL, record.RollbackID)
+		INSERT INTO dns_records (id, provider_record_id, migration_id, domain, record_type, record_name, record_value, ttl, rollback_id, previous_value)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, record.ID, record.ProviderRecordID, record.MigrationID, record.Domain, record.RecordType, record.RecordName, record.RecordValue, record.TTL, record.RollbackID, record.PreviousValue)
 	return err
 }
 
-// GetDnsRecords retrieves DNS records for a migration
+// GetDnsRecords retrieves DNS records for a migration, oldest first
 func (d *DB) GetDnsRecords(migrationID string) ([]DnsRecord, error) {
-	rows, err := d.db.Query(`
-		SELECT id, migration_id, domain, record_type, record_name, record_value, ttl, rollback_id, created_at
+	return d.GetDnsRecordsContext(context.Background(), migrationID)
+}
+
+// GetDnsRecordsContext is GetDnsRecords with a context for cancellation;
+// records are returned oldest first
+func (d *DB) GetDnsRecordsContext(ctx context.Context, migrationID string) ([]DnsRecord, error) {
+	rows, err := d.db.QueryContext(ctx, `
+		SELECT `+dnsRecordColumns+`
 		FROM dns_records WHERE migration_id = ?
+		ORDER BY created_at, rowid
 	`, migrationID)
 	if err != nil {
 		return nil, err
@@ -278,49 +628,195 @@ func (d *DB) GetDnsRecords(migrationID string) ([]DnsRecord, error) {
 
 	var records []DnsRecord
 	for rows.Next() {
-		var r DnsRecord
-		if err := rows.Scan(&r.ID, &r.MigrationID, &r.Domain, &r.RecordType, &r.RecordName, &r.RecordValue, &r.TTL, &r.RollbackID, &r.CreatedAt); err != nil {
+		r, err := scanDnsRecord(rows)
+		if err != nil {
 			return nil, err
 		}
-		records = append(records, r)
+		records = append(records, *r)
 	}
 
 	return records, rows.Err()
 }
 
-// Log adds a log entry
+// GetDnsRecord retrieves a single DNS record by ID
+func (d *DB) GetDnsRecord(id string) (*DnsRecord, error) {
+	return d.GetDnsRecordContext(context.Background(), id)
+}
+
+// GetDnsRecordContext is GetDnsRecord with a context for cancellation
+func (d *DB) GetDnsRecordContext(ctx context.Context, id string) (*DnsRecord, error) {
+	r, err := scanDnsRecord(d.db.QueryRowContext(ctx, `
+		SELECT `+dnsRecordColumns+`
+		FROM dns_records WHERE id = ?
+	`, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// ListDnsRecords lists DNS records across all migrations, optionally filtered by domain
+func (d *DB) ListDnsRecords(domain string) ([]DnsRecord, error) {
+	return d.ListDnsRecordsContext(context.Background(), domain)
+}
+
+// ListDnsRecordsContext is ListDnsRecords with a context for cancellation
+func (d *DB) ListDnsRecordsContext(ctx context.Context, domain string) ([]DnsRecord, error) {
+	query := "SELECT " + dnsRecordColumns + " FROM dns_records"
+	var args []interface{}
+
+	if domain != "" {
+		query += " WHERE domain = ?"
+		args = append(args, domain)
+	}
+
+	query += " ORDER BY created_at DESC"
+
+	rows, err := d.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []DnsRecord
+	for rows.Next() {
+		r, err := scanDnsRecord(rows)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, *r)
+	}
+
+	return records, rows.Err()
+}
+
+// Log adds a log entry with credentials masked. The metadata string is
+// otherwise stored as-is without validation; prefer LogJSON for structured
+// metadata.
 func (d *DB) Log(migrationID *string, level, message, metadata string) error {
 	_, err := d.db.Exec(`
 		INSERT INTO logs (migration_id, level, message, metadata)
 		VALUES (?, ?, ?, ?)
-	`, migrationID, level, message, metadata)
+	`, migrationID, level, redact.String(message), redact.String(metadata))
 	return err
 }
 
-// GetLogs retrieves logs for a migration
-func (d *DB) GetLogs(migrationID string, limit int) ([]LogEntry, error) {
-	if limit <= 0 {
-		limit = 100
+// LogJSON adds a log entry with structured metadata, marshaled as a JSON
+// object; credentials are masked as in Log
+func (d *DB) LogJSON(migrationID *string, level, message string, metadata map[string]interface{}) error {
+	var stored *string
+	if metadata != nil {
+		data, err := json.Marshal(metadata)
+		if err != nil {
+			return fmt.Errorf("invalid log metadata: %w", err)
+		}
+		encoded := redact.String(string(data))
+		stored = &encoded
 	}
 
-	rows, err := d.db.Query(`
-		SELECT id, migration_id, level, message, metadata, ts
-		FROM logs WHERE migration_id = ?
-		ORDER BY ts DESC LIMIT ?
-	`, migrationID, limit)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
+	_, err := d.db.Exec(`
+		INSERT INTO logs (migration_id, level, message, metadata)
+		VALUES (?, ?, ?, ?)
+	`, migrationID, level, redact.String(message), stored)
+	return err
+}
 
+// scanLogs reads log rows, decoding metadata into Fields when it holds a JSON object
+func scanLogs(rows *sql.Rows) ([]LogEntry, error) {
 	var logs []LogEntry
 	for rows.Next() {
 		var l LogEntry
 		if err := rows.Scan(&l.ID, &l.MigrationID, &l.Level, &l.Message, &l.Metadata, &l.Timestamp); err != nil {
 			return nil, err
 		}
+		if l.Metadata != nil && *l.Metadata != "" {
+			// Legacy raw metadata may not be JSON; leave Fields empty in that case
+			var fields map[string]interface{}
+			if err := json.Unmarshal([]byte(*l.Metadata), &fields); err == nil {
+				l.Fields = fields
+			}
+		}
 		logs = append(logs, l)
 	}
 
 	return logs, rows.Err()
 }
+
+// LogQuery filters log retrieval. Zero values disable the corresponding filter.
+type LogQuery struct {
+	Levels []string
+	Since  time.Time
+	Until  time.Time
+	Limit  int
+
+	// Contains matches messages containing the text, ignoring case
+	Contains string
+	// AfterID only returns entries newer than this ID, for tailing
+	AfterID int
+}
+
+// sqliteTimeFormat matches the format CURRENT_TIMESTAMP writes
+const sqliteTimeFormat = "2006-01-02 15:04:05"
+
+// GetLogs retrieves logs for a migration
+func (d *DB) GetLogs(migrationID string, limit int) ([]LogEntry, error) {
+	return d.GetLogsContext(context.Background(), migrationID, limit)
+}
+
+// GetLogsContext is GetLogs with a context for cancellation
+func (d *DB) GetLogsContext(ctx context.Context, migrationID string, limit int) ([]LogEntry, error) {
+	return d.GetLogsFilteredContext(ctx, migrationID, LogQuery{Limit: limit})
+}
+
+// GetLogsFiltered retrieves logs for a migration filtered by level and time range
+func (d *DB) GetLogsFiltered(migrationID string, opts LogQuery) ([]LogEntry, error) {
+	return d.GetLogsFilteredContext(context.Background(), migrationID, opts)
+}
+
+// GetLogsFilteredContext is GetLogsFiltered with a context for cancellation
+func (d *DB) GetLogsFilteredContext(ctx context.Context, migrationID string, opts LogQuery) ([]LogEntry, error) {
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = 100
+	}
+
+	query := "SELECT id, migration_id, level, message, metadata, ts FROM logs WHERE migration_id = ?"
+	args := []interface{}{migrationID}
+
+	if len(opts.Levels) > 0 {
+		query += " AND level IN (?" + strings.Repeat(", ?", len(opts.Levels)-1) + ")"
+		for _, level := range opts.Levels {
+			args = append(args, level)
+		}
+	}
+	if !opts.Since.IsZero() {
+		query += " AND ts >= ?"
+		args = append(args, opts.Since.UTC().Format(sqliteTimeFormat))
+	}
+	if !opts.Until.IsZero() {
+		query += " AND ts <= ?"
+		args = append(args, opts.Until.UTC().Format(sqliteTimeFormat))
+	}
+	if opts.Contains != "" {
+		query += " AND instr(lower(message), lower(?)) > 0"
+		args = append(args, opts.Contains)
+	}
+	if opts.AfterID > 0 {
+		query += " AND id > ?"
+		args = append(args, opts.AfterID)
+	}
+
+	query += " ORDER BY ts DESC, id DESC LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := d.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanLogs(rows)
+}
@@ -0,0 +1,430 @@
+package state
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// insertLog adds a log entry with a fixed timestamp
+func insertLog(t *testing.T, db *DB, migrationID, level, message string, ts time.Time) {
+	t.Helper()
+	_, err := db.db.Exec(`INSERT INTO logs (migration_id, level, message, ts) VALUES (?, ?, ?, ?)`,
+		migrationID, level, message, ts.UTC().Format(sqliteTimeFormat))
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGetLogsFiltered(t *testing.T) {
+	db := openWithKey(t, bytes.Repeat([]byte{1}, 32))
+	if err := db.CreateMigration("m1", "vercel", "netlify", "example.com"); err != nil {
+		t.Fatal(err)
+	}
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	insertLog(t, db, "m1", "info", "first", base)
+	insertLog(t, db, "m1", "warn", "second", base.Add(time.Minute))
+	insertLog(t, db, "m1", "error", "third", base.Add(2*time.Minute))
+	insertLog(t, db, "m1", "info", "fourth", base.Add(3*time.Minute))
+
+	tests := []struct {
+		name  string
+		query LogQuery
+		// want is the matching messages, newest first
+		want []string
+	}{
+		{name: "empty filter", query: LogQuery{}, want: []string{"fourth", "third", "second", "first"}},
+		{name: "one level", query: LogQuery{Levels: []string{"info"}}, want: []string{"fourth", "first"}},
+		{name: "several levels", query: LogQuery{Levels: []string{"warn", "error"}}, want: []string{"third", "second"}},
+		{name: "since is inclusive", query: LogQuery{Since: base.Add(2 * time.Minute)}, want: []string{"fourth", "third"}},
+		{name: "until is inclusive", query: LogQuery{Until: base.Add(time.Minute)}, want: []string{"second", "first"}},
+		{name: "range", query: LogQuery{Since: base.Add(time.Minute), Until: base.Add(2 * time.Minute)}, want: []string{"third", "second"}},
+		{name: "range in another zone", query: LogQuery{Since: base.Add(3 * time.Minute).In(time.FixedZone("EST", -5*3600))}, want: []string{"fourth"}},
+		{name: "level and range", query: LogQuery{Levels: []string{"info"}, Since: base.Add(time.Second)}, want: []string{"fourth"}},
+		{name: "empty range", query: LogQuery{Since: base.Add(time.Hour)}, want: nil},
+		{name: "limit", query: LogQuery{Limit: 2}, want: []string{"fourth", "third"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs, err := db.GetLogsFiltered("m1", tt.query)
+			if err != nil {
+				t.Fatalf("GetLogsFiltered: %v", err)
+			}
+			var got []string
+			for _, l := range logs {
+				got = append(got, l.Message)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("GetLogsFiltered(%+v) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDnsRecordLookups(t *testing.T) {
+	db := openWithKey(t, bytes.Repeat([]byte{1}, 32))
+	if err := db.CreateMigration("m1", "vercel", "netlify", "example.com"); err != nil {
+		t.Fatal(err)
+	}
+	m1 := "m1"
+	previous := "198.51.100.1"
+	records := []*DnsRecord{
+		{ProviderRecordID: "rec_1", MigrationID: &m1, Domain: "example.com", RecordType: "A", RecordName: "@", RecordValue: "203.0.113.10", TTL: 300, PreviousValue: &previous},
+		{ProviderRecordID: "rec_2", MigrationID: &m1, Domain: "example.com", RecordType: "CNAME", RecordName: "www", RecordValue: "app.example.dev", TTL: 60},
+		{ProviderRecordID: "rec_3", Domain: "example.org", RecordType: "A", RecordName: "@", RecordValue: "203.0.113.11", TTL: 300},
+	}
+	for _, r := range records {
+		if err := db.SaveDnsRecord(r); err != nil {
+			t.Fatalf("SaveDnsRecord: %v", err)
+		}
+		if r.ID == "" {
+			t.Fatal("SaveDnsRecord didn't assign an ID")
+		}
+	}
+
+	got, err := db.GetDnsRecord(records[0].ID)
+	if err != nil {
+		t.Fatalf("GetDnsRecord: %v", err)
+	}
+	if got == nil || got.ProviderRecordID != "rec_1" || got.RecordValue != "203.0.113.10" {
+		t.Errorf("GetDnsRecord(%s) = %+v, want rec_1", records[0].ID, got)
+	}
+	if got != nil && (got.PreviousValue == nil || *got.PreviousValue != previous) {
+		t.Errorf("PreviousValue = %v, want %s", got.PreviousValue, previous)
+	}
+
+	missing, err := db.GetDnsRecord("no-such-record")
+	if err != nil || missing != nil {
+		t.Errorf("GetDnsRecord(unknown) = %+v, %v, want nil, nil", missing, err)
+	}
+
+	tests := []struct {
+		domain string
+		want   []string
+	}{
+		{domain: "example.com", want: []string{"rec_1", "rec_2"}},
+		{domain: "example.org", want: []string{"rec_3"}},
+		{domain: "", want: []string{"rec_1", "rec_2", "rec_3"}},
+		{domain: "example.net"},
+	}
+	for _, tt := range tests {
+		listed, err := db.ListDnsRecords(tt.domain)
+		if err != nil {
+			t.Fatalf("ListDnsRecords(%q): %v", tt.domain, err)
+		}
+		var ids []string
+		for _, r := range listed {
+			ids = append(ids, r.ProviderRecordID)
+		}
+		// Saved within the same second, so only the set is stable
+		slices.Sort(ids)
+		if !slices.Equal(ids, tt.want) {
+			t.Errorf("ListDnsRecords(%q) = %v, want %v", tt.domain, ids, tt.want)
+		}
+	}
+}
+
+func TestConnectionPragmas(t *testing.T) {
+	db := openWithKey(t, bytes.Repeat([]byte{1}, 32))
+	ctx := context.Background()
+
+	// Hold every pooled connection at once, so each is checked, not just the first
+	for i := 0; i < maxOpenConns; i++ {
+		conn, err := db.db.Conn(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+
+		var mode string
+		if err := conn.QueryRowContext(ctx, "PRAGMA journal_mode").Scan(&mode); err != nil {
+			t.Fatal(err)
+		}
+		if mode != "wal" {
+			t.Errorf("connection %d: journal_mode = %q, want wal", i, mode)
+		}
+		var timeout int
+		if err := conn.QueryRowContext(ctx, "PRAGMA busy_timeout").Scan(&timeout); err != nil {
+			t.Fatal(err)
+		}
+		if timeout != busyTimeoutMs {
+			t.Errorf("connection %d: busy_timeout = %d, want %d", i, timeout, busyTimeoutMs)
+		}
+	}
+}
+
+func TestConcurrentWriters(t *testing.T) {
+	dir := t.TempDir()
+	key := bytes.Repeat([]byte{1}, 32)
+	// Two handles stand in for two dt processes sharing the state dir
+	var handles []*DB
+	for i := 0; i < 2; i++ {
+		db, err := OpenWithKey(dir, key)
+		if err != nil {
+			t.Fatalf("failed to open state: %v", err)
+		}
+		defer db.Close()
+		handles = append(handles, db)
+	}
+	if err := handles[0].CreateMigration("m1", "vercel", "netlify", "example.com"); err != nil {
+		t.Fatal(err)
+	}
+
+	const writers, writes = 8, 25
+	m1 := "m1"
+	var wg sync.WaitGroup
+	errs := make(chan error, writers*writes)
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(db *DB, w int) {
+			defer wg.Done()
+			for i := 0; i < writes; i++ {
+				if err := db.Log(&m1, "info", fmt.Sprintf("writer %d entry %d", w, i), ""); err != nil {
+					errs <- err
+				}
+				if _, err := db.GetLogs("m1", 10); err != nil {
+					errs <- err
+				}
+			}
+		}(handles[w%2], w)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("concurrent access failed: %v", err)
+	}
+
+	var count int
+	if err := handles[1].db.QueryRow(`SELECT COUNT(*) FROM logs WHERE migration_id = 'm1'`).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+	if count != writers*writes {
+		t.Errorf("stored %d log entries, want %d", count, writers*writes)
+	}
+}
+
+func TestArchiveMigration(t *testing.T) {
+	db := openWithKey(t, bytes.Repeat([]byte{1}, 32))
+	for _, id := range []string{"m1", "m2"} {
+		if err := db.CreateMigration(id, "vercel", "netlify", id+".example.com"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m1 := "m1"
+	if err := db.Log(&m1, "info", "history", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	listed := func(includeArchived bool) []string {
+		t.Helper()
+		migrations, err := db.ListMigrations("", includeArchived)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ids []string
+		for _, m := range migrations {
+			ids = append(ids, m.ID)
+		}
+		slices.Sort(ids)
+		return ids
+	}
+
+	if err := db.ArchiveMigration("m1"); err != nil {
+		t.Fatalf("ArchiveMigration: %v", err)
+	}
+	if got := listed(false); !slices.Equal(got, []string{"m2"}) {
+		t.Errorf("listed %v after archiving m1, want [m2]", got)
+	}
+	if got := listed(true); !slices.Equal(got, []string{"m1", "m2"}) {
+		t.Errorf("listed %v with archived, want [m1 m2]", got)
+	}
+	if found, _ := db.SearchMigrations("m1"); len(found) != 0 {
+		t.Errorf("search found archived migration: %+v", found)
+	}
+
+	archived, err := db.GetMigration("m1")
+	if err != nil || archived == nil {
+		t.Fatalf("GetMigration(archived) = %v, %v", archived, err)
+	}
+	if archived.ArchivedAt == nil {
+		t.Error("ArchivedAt is nil after archiving")
+	}
+	if logs, _ := db.GetLogs("m1", 0); len(logs) != 1 {
+		t.Errorf("archived migration has %d log entries, want its history kept", len(logs))
+	}
+
+	if err := db.UnarchiveMigration("m1"); err != nil {
+		t.Fatalf("UnarchiveMigration: %v", err)
+	}
+	if got := listed(false); !slices.Equal(got, []string{"m1", "m2"}) {
+		t.Errorf("listed %v after unarchiving m1, want [m1 m2]", got)
+	}
+	if restored, _ := db.GetMigration("m1"); restored.ArchivedAt != nil {
+		t.Errorf("ArchivedAt = %v after unarchiving, want nil", restored.ArchivedAt)
+	}
+}
+
+func TestLogJSON(t *testing.T) {
+	db := openWithKey(t, bytes.Repeat([]byte{1}, 32))
+	if err := db.CreateMigration("m1", "vercel", "netlify", "example.com"); err != nil {
+		t.Fatal(err)
+	}
+	m1 := "m1"
+
+	tests := []struct {
+		name     string
+		metadata map[string]interface{}
+		wantErr  bool
+		// fields are the decoded metadata expected back, nil for none
+		fields map[string]interface{}
+	}{
+		{name: "object", metadata: map[string]interface{}{"count": 3, "provider": "netlify"}, fields: map[string]interface{}{"count": float64(3), "provider": "netlify"}},
+		{name: "nested", metadata: map[string]interface{}{"record": map[string]interface{}{"type": "A"}}, fields: map[string]interface{}{"record": map[string]interface{}{"type": "A"}}},
+		{name: "secret masked", metadata: map[string]interface{}{"token": "tok_abcdef123456"}, fields: map[string]interface{}{"token": "[REDACTED]"}},
+		{name: "no metadata"},
+		{name: "unencodable value", metadata: map[string]interface{}{"callback": func() {}}, wantErr: true},
+		{name: "NaN", metadata: map[string]interface{}{"ratio": math.NaN()}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := db.db.Exec(`DELETE FROM logs`); err != nil {
+				t.Fatal(err)
+			}
+
+			err := db.LogJSON(&m1, "info", tt.name, tt.metadata)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LogJSON() = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			logs, err := db.GetLogs("m1", 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantErr {
+				if len(logs) != 0 {
+					t.Errorf("rejected metadata was stored: %+v", logs)
+				}
+				return
+			}
+			if len(logs) != 1 {
+				t.Fatalf("stored %d entries, want 1", len(logs))
+			}
+			l := logs[0]
+			if tt.fields == nil {
+				if l.Metadata != nil {
+					t.Errorf("Metadata = %q, want none", *l.Metadata)
+				}
+				return
+			}
+			if l.Metadata == nil || !json.Valid([]byte(*l.Metadata)) {
+				t.Fatalf("stored metadata %v is not valid JSON", l.Metadata)
+			}
+			if !reflect.DeepEqual(l.Fields, tt.fields) {
+				t.Errorf("Fields = %v, want %v", l.Fields, tt.fields)
+			}
+		})
+	}
+}
+
+func TestCanceledContextAbortsQueries(t *testing.T) {
+	db := openWithKey(t, bytes.Repeat([]byte{1}, 32))
+	if err := db.CreateMigration("m1", "vercel", "netlify", "example.com"); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name  string
+		query func() error
+	}{
+		{name: "GetMigrationContext", query: func() error { _, err := db.GetMigrationContext(ctx, "m1"); return err }},
+		{name: "ListMigrationsContext", query: func() error { _, err := db.ListMigrationsContext(ctx, "", true); return err }},
+		{name: "GetEnvVarsContext", query: func() error { _, err := db.GetEnvVarsContext(ctx, "m1"); return err }},
+		{name: "GetDnsRecordsContext", query: func() error { _, err := db.GetDnsRecordsContext(ctx, "m1"); return err }},
+		{name: "GetLogsFilteredContext", query: func() error { _, err := db.GetLogsFilteredContext(ctx, "m1", LogQuery{}); return err }},
+		{name: "SearchLogsContext", query: func() error { _, err := db.SearchLogsContext(ctx, "m1", "failed", 0); return err }},
+		{name: "StatsContext", query: func() error { _, err := db.StatsContext(ctx); return err }},
+		{name: "GetStepsContext", query: func() error { _, err := db.GetStepsContext(ctx, "m1"); return err }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.query(); !errors.Is(err, context.Canceled) {
+				t.Errorf("%s with a canceled context = %v, want context.Canceled", tt.name, err)
+			}
+		})
+	}
+
+	// The same queries still work with a live context
+	if _, err := db.GetMigrationContext(context.Background(), "m1"); err != nil {
+		t.Errorf("GetMigrationContext after cancellations: %v", err)
+	}
+}
+
+func TestEnvVarEnvironments(t *testing.T) {
+	db := openWithKey(t, bytes.Repeat([]byte{1}, 32))
+	if err := db.CreateMigration("m1", "vercel", "netlify", "example.com"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		key          string
+		environments []string
+	}{
+		{key: "UNSCOPED", environments: nil},
+		{key: "EMPTY", environments: []string{}},
+		{key: "PROD", environments: []string{"production"}},
+		{key: "TWO", environments: []string{"preview", "development"}},
+	}
+	for _, tt := range tests {
+		if err := db.SaveEnvVar("m1", tt.key, "v", "", tt.environments); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	vars, err := db.GetEnvVars("m1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vars) != len(tests) {
+		t.Fatalf("GetEnvVars() returned %d vars, want %d", len(vars), len(tests))
+	}
+	for i, tt := range tests {
+		want := tt.environments
+		if len(want) == 0 {
+			// Unscoped is stored as NULL and read back as nil
+			want = nil
+		}
+		if got := vars[i].Environments; !slices.Equal(got, want) || (got == nil) != (want == nil) {
+			t.Errorf("%s environments = %#v, want %#v", tt.key, got, want)
+		}
+	}
+
+	var stored []interface{}
+	rows, err := db.db.Query(`SELECT environments FROM env_vars ORDER BY id`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var v interface{}
+		if err := rows.Scan(&v); err != nil {
+			t.Fatal(err)
+		}
+		stored = append(stored, v)
+	}
+	if want := "[<nil> <nil> production preview,development]"; fmt.Sprint(stored) != want {
+		t.Errorf("environments column = %v, want %s", stored, want)
+	}
+}
@@ -0,0 +1,69 @@
+package state
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// Stats summarizes the state database for the dashboard
+type Stats struct {
+	Total           int            `json:"total"`
+	ByStatus        map[string]int `json:"by_status"`
+	LastCreatedAt   time.Time      `json:"last_created_at"`
+	TotalEnvVars    int            `json:"total_env_vars"`
+	TotalDnsRecords int            `json:"total_dns_records"`
+}
+
+// Stats returns aggregate counts over non-archived migrations
+func (d *DB) Stats() (Stats, error) {
+	return d.StatsContext(context.Background())
+}
+
+// StatsContext is Stats with a context for cancellation
+func (d *DB) StatsContext(ctx context.Context) (Stats, error) {
+	stats := Stats{ByStatus: make(map[string]int)}
+
+	rows, err := d.db.QueryContext(ctx, `
+		SELECT status, COUNT(*) FROM migrations
+		WHERE archived_at IS NULL
+		GROUP BY status
+	`)
+	if err != nil {
+		return stats, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return stats, err
+		}
+		stats.ByStatus[status] = count
+		stats.Total += count
+	}
+	if err := rows.Err(); err != nil {
+		return stats, err
+	}
+
+	// Aggregates lose the column's TIMESTAMP type, so MAX comes back as text
+	var lastCreated sql.NullString
+	err = d.db.QueryRowContext(ctx, `
+		SELECT MAX(created_at),
+			(SELECT COUNT(*) FROM env_vars e JOIN migrations m ON m.id = e.migration_id WHERE m.archived_at IS NULL),
+			(SELECT COUNT(*) FROM dns_records r JOIN migrations m ON m.id = r.migration_id WHERE m.archived_at IS NULL)
+		FROM migrations WHERE archived_at IS NULL
+	`).Scan(&lastCreated, &stats.TotalEnvVars, &stats.TotalDnsRecords)
+	if err != nil {
+		return stats, err
+	}
+
+	if lastCreated.Valid {
+		if t, err := time.Parse(sqliteTimeFormat, lastCreated.String); err == nil {
+			stats.LastCreatedAt = t
+		}
+	}
+
+	return stats, nil
+}
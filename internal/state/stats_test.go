@@ -0,0 +1,87 @@
+package state
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestStats(t *testing.T) {
+	db := openWithKey(t, bytes.Repeat([]byte{1}, 32))
+
+	empty, err := db.Stats()
+	if err != nil {
+		t.Fatalf("Stats() on an empty database: %v", err)
+	}
+	if empty.Total != 0 || !empty.LastCreatedAt.IsZero() || empty.TotalEnvVars != 0 || empty.TotalDnsRecords != 0 {
+		t.Errorf("Stats() on an empty database = %+v", empty)
+	}
+
+	migrations := []struct {
+		id, status string
+		created    time.Time
+		envVars    int
+		dnsRecords int
+		archived   bool
+	}{
+		{id: "m1", status: "pending", created: time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC), envVars: 2},
+		{id: "m2", status: "completed", created: time.Date(2026, 2, 1, 9, 0, 0, 0, time.UTC), dnsRecords: 1},
+		{id: "m3", status: "failed", created: time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC), envVars: 1, dnsRecords: 2},
+		{id: "m4", status: "completed", created: time.Date(2026, 4, 1, 9, 0, 0, 0, time.UTC)},
+		// Archived migrations are left out of every count
+		{id: "m5", status: "failed", created: time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC), envVars: 3, dnsRecords: 1, archived: true},
+	}
+	for _, m := range migrations {
+		if err := db.CreateMigration(m.id, "vercel", "netlify", m.id+".example.com"); err != nil {
+			t.Fatal(err)
+		}
+		if err := db.UpdateMigrationStatus(m.id, m.status); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := db.db.Exec(`UPDATE migrations SET created_at = ? WHERE id = ?`, m.created.Format(sqliteTimeFormat), m.id); err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < m.envVars; i++ {
+			if err := db.SaveEnvVar(m.id, "KEY", "value", "", nil); err != nil {
+				t.Fatal(err)
+			}
+		}
+		id := m.id
+		for i := 0; i < m.dnsRecords; i++ {
+			if err := db.SaveDnsRecord(&DnsRecord{MigrationID: &id, Domain: m.id + ".example.com", RecordType: "A", RecordName: "@", RecordValue: "203.0.113.10"}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if m.archived {
+			if err := db.ArchiveMigration(m.id); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	stats, err := db.Stats()
+	if err != nil {
+		t.Fatalf("Stats(): %v", err)
+	}
+	if stats.Total != 4 {
+		t.Errorf("Total = %d, want 4", stats.Total)
+	}
+	wantByStatus := map[string]int{"pending": 1, "completed": 2, "failed": 1}
+	if len(stats.ByStatus) != len(wantByStatus) {
+		t.Errorf("ByStatus = %v, want %v", stats.ByStatus, wantByStatus)
+	}
+	for status, want := range wantByStatus {
+		if stats.ByStatus[status] != want {
+			t.Errorf("ByStatus[%s] = %d, want %d", status, stats.ByStatus[status], want)
+		}
+	}
+	if want := migrations[3].created; !stats.LastCreatedAt.Equal(want) {
+		t.Errorf("LastCreatedAt = %v, want %v", stats.LastCreatedAt, want)
+	}
+	if stats.TotalEnvVars != 3 {
+		t.Errorf("TotalEnvVars = %d, want 3", stats.TotalEnvVars)
+	}
+	if stats.TotalDnsRecords != 3 {
+		t.Errorf("TotalDnsRecords = %d, want 3", stats.TotalDnsRecords)
+	}
+}
@@ -0,0 +1,147 @@
+package state
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Step is a stage of the migration workflow
+type Step string
+
+const (
+	StepFetchConfig   Step = "fetch_config"
+	StepSyncEnv       Step = "sync_env"
+	StepDeployPreview Step = "deploy_preview"
+	StepDnsUpdate     Step = "dns_update"
+	StepVerify        Step = "verify"
+	StepCutover       Step = "cutover"
+)
+
+// StepOrder is the order in which workflow steps run
+var StepOrder = []Step{
+	StepFetchConfig,
+	StepSyncEnv,
+	StepDeployPreview,
+	StepDnsUpdate,
+	StepVerify,
+	StepCutover,
+}
+
+// StepRecord is a completed workflow step checkpoint
+type StepRecord struct {
+	MigrationID string          `json:"migration_id"`
+	Step        Step            `json:"step"`
+	Data        json.RawMessage `json:"data,omitempty"`
+	CompletedAt time.Time       `json:"completed_at"`
+}
+
+func stepIndex(step Step) int {
+	for i, s := range StepOrder {
+		if s == step {
+			return i
+		}
+	}
+	return -1
+}
+
+// SetStep records that a workflow step completed, with optional step output.
+// Recording the same step again replaces the earlier checkpoint.
+func (d *DB) SetStep(migrationID string, step Step, data json.RawMessage) error {
+	if stepIndex(step) < 0 {
+		return fmt.Errorf("unknown step: %s", step)
+	}
+	if data != nil && !json.Valid(data) {
+		return fmt.Errorf("step data for %s is not valid JSON", step)
+	}
+
+	var stored interface{}
+	if data != nil {
+		stored = string(data)
+	}
+
+	_, err := d.db.Exec(`
+		INSERT INTO migration_steps (migration_id, step, data)
+		VALUES (?, ?, ?)
+		ON CONFLICT (migration_id, step) DO UPDATE
+		SET data = excluded.data, completed_at = CURRENT_TIMESTAMP
+	`, migrationID, string(step), stored)
+	return err
+}
+
+// GetLastStep returns the furthest completed step for a migration, or nil if none
+func (d *DB) GetLastStep(migrationID string) (*StepRecord, error) {
+	return d.GetLastStepContext(context.Background(), migrationID)
+}
+
+// GetLastStepContext is GetLastStep with a context for cancellation
+func (d *DB) GetLastStepContext(ctx context.Context, migrationID string) (*StepRecord, error) {
+	records, err := d.GetStepsContext(ctx, migrationID)
+	if err != nil || len(records) == 0 {
+		return nil, err
+	}
+	return &records[len(records)-1], nil
+}
+
+// GetSteps returns every completed step checkpoint for a migration in workflow order
+func (d *DB) GetSteps(migrationID string) ([]StepRecord, error) {
+	return d.GetStepsContext(context.Background(), migrationID)
+}
+
+// GetStepsContext is GetSteps with a context for cancellation
+func (d *DB) GetStepsContext(ctx context.Context, migrationID string) ([]StepRecord, error) {
+	rows, err := d.db.QueryContext(ctx, `
+		SELECT migration_id, step, data, completed_at
+		FROM migration_steps WHERE migration_id = ?
+	`, migrationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []StepRecord
+	for rows.Next() {
+		var r StepRecord
+		var data sql.NullString
+		if err := rows.Scan(&r.MigrationID, &r.Step, &data, &r.CompletedAt); err != nil {
+			return nil, err
+		}
+		if data.Valid {
+			r.Data = json.RawMessage(data.String)
+		}
+		records = append(records, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return stepIndex(records[i].Step) < stepIndex(records[j].Step)
+	})
+	return records, nil
+}
+
+// NextStep returns the step a migration should resume at, or "" once all steps are done
+func (d *DB) NextStep(migrationID string) (Step, error) {
+	return d.NextStepContext(context.Background(), migrationID)
+}
+
+// NextStepContext is NextStep with a context for cancellation
+func (d *DB) NextStepContext(ctx context.Context, migrationID string) (Step, error) {
+	last, err := d.GetLastStepContext(ctx, migrationID)
+	if err != nil {
+		return "", err
+	}
+	if last == nil {
+		return StepOrder[0], nil
+	}
+
+	next := stepIndex(last.Step) + 1
+	if next >= len(StepOrder) {
+		return "", nil
+	}
+	return StepOrder[next], nil
+}
@@ -0,0 +1,67 @@
+package state
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestStepCheckpointResume(t *testing.T) {
+	dir := t.TempDir()
+	key := bytes.Repeat([]byte{1}, 32)
+	db, err := OpenWithKey(dir, key)
+	if err != nil {
+		t.Fatalf("failed to open state: %v", err)
+	}
+	if err := db.CreateMigration("m1", "vercel", "netlify", "example.com"); err != nil {
+		t.Fatal(err)
+	}
+
+	if next, err := db.NextStep("m1"); err != nil || next != StepFetchConfig {
+		t.Errorf("NextStep() before any checkpoint = %q, %v, want %q", next, err, StepFetchConfig)
+	}
+	if err := db.SetStep("m1", StepFetchConfig, json.RawMessage(`{"project_id":"prj_1"}`)); err != nil {
+		t.Fatalf("SetStep(fetch_config): %v", err)
+	}
+	if err := db.SetStep("m1", StepSyncEnv, nil); err != nil {
+		t.Fatalf("SetStep(sync_env): %v", err)
+	}
+	if err := db.SetStep("m1", "teleport", nil); err == nil {
+		t.Error("SetStep accepted an unknown step")
+	}
+	if err := db.SetStep("m1", StepDeployPreview, json.RawMessage(`{not json`)); err == nil {
+		t.Error("SetStep accepted invalid JSON data")
+	}
+	// The process is interrupted here
+	db.Close()
+
+	resumed, err := OpenWithKey(dir, key)
+	if err != nil {
+		t.Fatalf("failed to reopen state: %v", err)
+	}
+	defer resumed.Close()
+
+	next, err := resumed.NextStep("m1")
+	if err != nil || next != StepDeployPreview {
+		t.Errorf("NextStep() after reopening = %q, %v, want %q", next, err, StepDeployPreview)
+	}
+	steps, err := resumed.GetSteps("m1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(steps) != 2 || steps[0].Step != StepFetchConfig || steps[1].Step != StepSyncEnv {
+		t.Fatalf("GetSteps() = %+v, want fetch_config then sync_env", steps)
+	}
+	if string(steps[0].Data) != `{"project_id":"prj_1"}` {
+		t.Errorf("fetch_config data = %s", steps[0].Data)
+	}
+
+	for _, step := range StepOrder {
+		if err := resumed.SetStep("m1", step, nil); err != nil {
+			t.Fatalf("SetStep(%s): %v", step, err)
+		}
+	}
+	if next, err := resumed.NextStep("m1"); err != nil || next != "" {
+		t.Errorf("NextStep() after every step = %q, %v, want none", next, err)
+	}
+}
@@ -0,0 +1,78 @@
+package state
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// EnvFingerprint identifies what was synced for a variable without storing
+// its value, so a later change to the value or its environments syncs again.
+// It is an HMAC keyed from the database key, so a copy of the database
+// can't be used to confirm guesses at a value.
+func (d *DB) EnvFingerprint(value string, environments []string) string {
+	mac := hmac.New(sha256.New, d.cipher.macKey)
+	mac.Write([]byte(strings.Join(environments, ",") + "\x00" + value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SyncedEnv returns the fingerprint of each variable already synced to
+// provider for a migration, by the key it was synced under
+func (d *DB) SyncedEnv(migrationID, provider string) (map[string]string, error) {
+	return d.SyncedEnvContext(context.Background(), migrationID, provider)
+}
+
+// SyncedEnvContext is SyncedEnv with a context for cancellation
+func (d *DB) SyncedEnvContext(ctx context.Context, migrationID, provider string) (map[string]string, error) {
+	rows, err := d.db.QueryContext(ctx, `
+		SELECT key, fingerprint FROM synced_env WHERE migration_id = ? AND provider = ?
+	`, migrationID, provider)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load synced env vars: %w", err)
+	}
+	defer rows.Close()
+
+	synced := make(map[string]string)
+	for rows.Next() {
+		var key, fingerprint string
+		if err := rows.Scan(&key, &fingerprint); err != nil {
+			return nil, err
+		}
+		synced[key] = fingerprint
+	}
+	return synced, rows.Err()
+}
+
+// RecordSyncedEnv marks variables, given as key to fingerprint, as synced
+// to provider, replacing earlier records for the same keys
+func (d *DB) RecordSyncedEnv(migrationID, provider string, synced map[string]string) error {
+	return d.RecordSyncedEnvContext(context.Background(), migrationID, provider, synced)
+}
+
+// RecordSyncedEnvContext is RecordSyncedEnv with a context for cancellation
+func (d *DB) RecordSyncedEnvContext(ctx context.Context, migrationID, provider string, synced map[string]string) error {
+	if len(synced) == 0 {
+		return nil
+	}
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for key, fingerprint := range synced {
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO synced_env (migration_id, provider, key, fingerprint)
+			VALUES (?, ?, ?, ?)
+			ON CONFLICT (migration_id, provider, key) DO UPDATE
+			SET fingerprint = excluded.fingerprint, synced_at = CURRENT_TIMESTAMP
+		`, migrationID, provider, key, fingerprint); err != nil {
+			return fmt.Errorf("failed to record synced env var %s: %w", key, err)
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,59 @@
+package state
+
+import (
+	"bytes"
+	"testing"
+)
+
+func openWithKey(t *testing.T, key []byte) *DB {
+	t.Helper()
+	db, err := OpenWithKey(t.TempDir(), key)
+	if err != nil {
+		t.Fatalf("failed to open state: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestEnvFingerprint(t *testing.T) {
+	db := openWithKey(t, bytes.Repeat([]byte{1}, 32))
+	other := openWithKey(t, bytes.Repeat([]byte{2}, 32))
+	base := db.EnvFingerprint("secret", []string{"production"})
+
+	tests := []struct {
+		name string
+		got  string
+		same bool
+	}{
+		{name: "same input", got: db.EnvFingerprint("secret", []string{"production"}), same: true},
+		{name: "different value", got: db.EnvFingerprint("secret2", []string{"production"})},
+		{name: "different environments", got: db.EnvFingerprint("secret", []string{"production", "preview"})},
+		{name: "different database key", got: other.EnvFingerprint("secret", []string{"production"})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if (tt.got == base) != tt.same {
+				t.Errorf("fingerprint equal = %v, want %v", tt.got == base, tt.same)
+			}
+		})
+	}
+}
+
+func TestSyncedEnvRoundTrip(t *testing.T) {
+	db := openWithKey(t, bytes.Repeat([]byte{1}, 32))
+	if err := db.CreateMigration("m1", "netlify", "vercel", "example.com"); err != nil {
+		t.Fatal(err)
+	}
+
+	fingerprint := db.EnvFingerprint("secret", nil)
+	if err := db.RecordSyncedEnv("m1", "vercel", map[string]string{"API_KEY": fingerprint}); err != nil {
+		t.Fatalf("RecordSyncedEnv: %v", err)
+	}
+	synced, err := db.SyncedEnv("m1", "vercel")
+	if err != nil {
+		t.Fatalf("SyncedEnv: %v", err)
+	}
+	if synced["API_KEY"] != fingerprint {
+		t.Errorf("synced[API_KEY] = %q, want %q", synced["API_KEY"], fingerprint)
+	}
+}
@@ -0,0 +1,83 @@
+package state
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// GetUIState returns a persisted UI preference, or "" if it was never set
+func (d *DB) GetUIState(key string) (string, error) {
+	var value string
+	err := d.db.QueryRow(`SELECT value FROM ui_state WHERE key = ?`, key).Scan(&value)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get ui state %s: %w", key, err)
+	}
+	return value, nil
+}
+
+// SetUIState persists a UI preference
+func (d *DB) SetUIState(key, value string) error {
+	_, err := d.db.Exec(`
+		INSERT INTO ui_state (key, value, updated_at) VALUES (?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(key) DO UPDATE SET value = excluded.value, updated_at = excluded.updated_at
+	`, key, value)
+	if err != nil {
+		return fmt.Errorf("failed to set ui state %s: %w", key, err)
+	}
+	return nil
+}
+
+// ui_state keys holding the dashboard menu preferences
+const (
+	menuOrderKey  = "dashboard.menu_order"
+	menuHiddenKey = "dashboard.menu_hidden"
+)
+
+// MenuPrefs customizes the dashboard menu. Order lists item keys to show
+// first; items it leaves out follow in their default order. Hidden items are
+// dropped from the menu.
+type MenuPrefs struct {
+	Order  []string
+	Hidden []string
+}
+
+// GetMenuPrefs returns the saved dashboard menu preferences, empty if none
+// were saved
+func (d *DB) GetMenuPrefs() (MenuPrefs, error) {
+	order, err := d.GetUIState(menuOrderKey)
+	if err != nil {
+		return MenuPrefs{}, err
+	}
+	hidden, err := d.GetUIState(menuHiddenKey)
+	if err != nil {
+		return MenuPrefs{}, err
+	}
+	return MenuPrefs{
+		Order:  splitKeys(order),
+		Hidden: splitKeys(hidden),
+	}, nil
+}
+
+// SetMenuPrefs saves the dashboard menu preferences; empty prefs restore the
+// default menu
+func (d *DB) SetMenuPrefs(prefs MenuPrefs) error {
+	if err := d.SetUIState(menuOrderKey, strings.Join(prefs.Order, ",")); err != nil {
+		return err
+	}
+	return d.SetUIState(menuHiddenKey, strings.Join(prefs.Hidden, ","))
+}
+
+// splitKeys parses a comma-separated list, dropping blanks
+func splitKeys(raw string) []string {
+	var keys []string
+	for _, key := range strings.Split(raw, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
@@ -2,9 +2,11 @@ package tui
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os/exec"
 	"runtime"
+	"time"
 
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/spinner"
@@ -14,6 +16,7 @@ import (
 	"github.com/johnhorton/deploy-tunnel/internal/bridge"
 	"github.com/johnhorton/deploy-tunnel/internal/keychain"
 	"github.com/johnhorton/deploy-tunnel/internal/state"
+	"github.com/johnhorton/deploy-tunnel/internal/validate"
 )
 
 type authStep int
@@ -24,31 +27,53 @@ const (
 	authStepFetchingCapabilities
 	authStepEnterToken
 	authStepVerifying
+	authStepRevokeSelect
+	authStepRevokeConfirm
+	authStepRevoking
 	authStepComplete
 	authStepError
 )
 
 type AuthModel struct {
-	step               authStep
-	menuList           list.Model
-	providerList       list.Model
-	tokenInput         textinput.Model
-	spinner            spinner.Model
-	selectedAction     string
-	selectedProvider   bridge.Provider
-	capabilities       *bridge.CapabilitiesData
-	authData           *bridge.AuthStartData
-	token              string
-	err                error
-	successMessage     string
+	step             authStep
+	menuList         list.Model
+	providerList     list.Model
+	revokeList       list.Model
+	tokenInput       textinput.Model
+	status           statusSpinner
+	progressCh       chan bridge.Progress
+	selectedAction   string
+	selectedProvider bridge.Provider
+	capabilities     *bridge.CapabilitiesData
+	authData         *bridge.AuthStartData
+	token            string
+	// tokenWarning is set when the token doesn't look like the provider's
+	tokenWarning   string
+	err            error
+	successMessage string
+	// canUndo is set while the revoke just made can be undone with u
+	canUndo bool
+	undoErr error
+	// authQueue holds providers still to authenticate after selectedProvider
+	// when several were picked; authResults records each one finished so far
+	authQueue          []bridge.Provider
+	authResults        []authResult
 	width              int
 	height             int
+	showHelp           bool
+	session            *Session
 	stateDB            *state.DB
 	bridge             *bridge.Bridge
 	ctx                context.Context
 	authenticatedProvs []string
 }
 
+// authResult is the outcome of authenticating one provider
+type authResult struct {
+	provider bridge.Provider
+	err      error
+}
+
 type authMenuItem struct {
 	title string
 	desc  string
@@ -64,20 +89,38 @@ type providerItem struct {
 	desc   string
 	value  bridge.Provider
 	authed bool
+	// selectable shows a checkbox, and checked marks the provider for a
+	// multi-provider auth run
+	selectable bool
+	checked    bool
+	// unavailable is why the provider's adapter didn't respond, if it didn't
+	unavailable string
 }
 
 func (i providerItem) Title() string {
+	var box string
+	switch {
+	case i.checked:
+		box = PromptStyle.Render("[x] ")
+	case i.selectable:
+		box = "[ ] "
+	}
 	if i.authed {
-		return GreenStyle.Render("✓ ") + i.title
+		return box + GreenStyle.Render("✓ ") + i.title
 	}
-	return "  " + i.title
+	return box + "  " + i.title
+}
+func (i providerItem) Description() string {
+	if i.unavailable != "" {
+		return RedStyle.Render("adapter unavailable: " + i.unavailable)
+	}
+	return i.desc
 }
-func (i providerItem) Description() string { return i.desc }
 func (i providerItem) FilterValue() string { return i.title }
 
-func NewAuthModel(stateDB *state.DB, br *bridge.Bridge) AuthModel {
+func NewAuthModel(s *Session) AuthModel {
 	// Get authenticated providers
-	authedProviders, _ := keychain.List()
+	authedProviders := s.Authed()
 	authedMap := make(map[string]bool)
 	for _, p := range authedProviders {
 		authedMap[p] = true
@@ -116,37 +159,48 @@ func NewAuthModel(stateDB *state.DB, br *bridge.Bridge) AuthModel {
 	// Provider items
 	providerItems := []list.Item{
 		providerItem{
-			title:  "Vercel",
-			desc:   "Deploy in seconds with Vercel",
-			value:  bridge.ProviderVercel,
-			authed: authedMap["vercel"],
+			title:      "Vercel",
+			desc:       "Deploy in seconds with Vercel",
+			value:      bridge.ProviderVercel,
+			authed:     authedMap["vercel"],
+			selectable: true,
 		},
 		providerItem{
-			title:  "Cloudflare",
-			desc:   "Pages & Workers at the edge",
-			value:  bridge.ProviderCloudflare,
-			authed: authedMap["cloudflare"],
+			title:      "Cloudflare",
+			desc:       "Pages & Workers at the edge",
+			value:      bridge.ProviderCloudflare,
+			authed:     authedMap["cloudflare"],
+			selectable: true,
 		},
 		providerItem{
-			title:  "Render",
-			desc:   "Unified cloud for web services",
-			value:  bridge.ProviderRender,
-			authed: authedMap["render"],
+			title:      "Render",
+			desc:       "Unified cloud for web services",
+			value:      bridge.ProviderRender,
+			authed:     authedMap["render"],
+			selectable: true,
 		},
 		providerItem{
-			title:  "Netlify",
-			desc:   "All-in-one platform for web projects",
-			value:  bridge.ProviderNetlify,
-			authed: authedMap["netlify"],
+			title:      "Netlify",
+			desc:       "All-in-one platform for web projects",
+			value:      bridge.ProviderNetlify,
+			authed:     authedMap["netlify"],
+			selectable: true,
 		},
 	}
 
 	providerList := list.New(providerItems, list.NewDefaultDelegate(), 0, 0)
-	providerList.Title = "Select Provider"
+	providerList.Title = "Select Providers"
 	providerList.SetShowStatusBar(false)
 	providerList.SetFilteringEnabled(false)
 	providerList.Styles.Title = TitleStyle
 
+	// Revoke list, populated from authenticated providers when opened
+	revokeList := list.New(nil, list.NewDefaultDelegate(), 0, 0)
+	revokeList.Title = "Revoke Credentials"
+	revokeList.SetShowStatusBar(false)
+	revokeList.SetFilteringEnabled(false)
+	revokeList.Styles.Title = TitleStyle
+
 	// Token input
 	tokenInput := textinput.New()
 	tokenInput.Placeholder = "Paste your token here"
@@ -157,32 +211,42 @@ func NewAuthModel(stateDB *state.DB, br *bridge.Bridge) AuthModel {
 	tokenInput.Width = 60
 	tokenInput.Prompt = PromptStyle.Render("► ")
 
-	// Spinner
-	s := spinner.New()
-	s.Spinner = spinner.Dot
-	s.Style = lipgloss.NewStyle().Foreground(Coral)
-
 	return AuthModel{
 		step:               authStepMenu,
 		menuList:           menuList,
 		providerList:       providerList,
+		revokeList:         revokeList,
 		tokenInput:         tokenInput,
-		spinner:            s,
-		stateDB:            stateDB,
-		bridge:             br,
-		ctx:                context.Background(),
+		status:             newStatusSpinner(true),
+		session:            s,
+		stateDB:            s.StateDB,
+		bridge:             s.Bridge,
+		ctx:                s.Ctx,
 		authenticatedProvs: authedProviders,
 	}
 }
 
 func (m AuthModel) Init() tea.Cmd {
-	return m.spinner.Tick
+	return fetchProviderHealthCmd(m.bridge, m.ctx)
 }
 
 func (m AuthModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if m.showHelp && msg.String() != "ctrl+c" {
+			if msg.String() == "?" || msg.String() == "esc" {
+				m.showHelp = false
+			}
+			return m, nil
+		}
 		switch msg.String() {
+		case "?":
+			// Tokens may contain "?", so the overlay is unavailable while typing one
+			if m.step != authStepEnterToken {
+				m.showHelp = true
+				return m, nil
+			}
+
 		case "ctrl+c":
 			return m, tea.Quit
 
@@ -191,40 +255,95 @@ func (m AuthModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, tea.Quit
 			}
 
+		case "y":
+			if m.step == authStepRevokeConfirm {
+				m.step = authStepRevoking
+				return m, tea.Batch(m.status.Start("Revoking credentials..."), revokeCmd(m.selectedProvider))
+			}
+
+		case "u":
+			if m.step == authStepComplete && m.canUndo {
+				return m.undo(), nil
+			}
+
+		case "n":
+			if m.step == authStepRevokeConfirm {
+				m.step = authStepMenu
+				return m, nil
+			}
+
+		case " ":
+			if m.step == authStepSelectProvider {
+				m.toggleProvider()
+				return m, nil
+			}
+
+		case "esc":
+			// Handled before the token input sees it, so typing never traps the user
+			return m.goUp()
+
 		case "enter":
 			return m.handleEnter()
 		}
 
 	case tea.WindowSizeMsg:
+		if m.width != 0 && msg.Width != m.width {
+			// Re-render the logo for the new width
+			ClearImageCache()
+		}
 		m.width = msg.Width
 		m.height = msg.Height
 		m.menuList.SetSize(msg.Width-4, msg.Height-10)
 		m.providerList.SetSize(msg.Width-4, msg.Height-10)
+		m.revokeList.SetSize(msg.Width-4, msg.Height-10)
 		return m, nil
 
 	case spinner.TickMsg:
 		var cmd tea.Cmd
-		m.spinner, cmd = m.spinner.Update(msg)
+		m.status, cmd = m.status.Update(msg)
 		return m, cmd
 
+	case bridgeProgressMsg:
+		if msg.ch != m.progressCh || !m.status.Active() {
+			return m, nil
+		}
+		m.status.SetDetail(msg.progress.String())
+		return m, waitForBridgeProgress(m.progressCh)
+
+	case providerHealthMsg:
+		m.markUnavailable(msg.results)
+		return m, nil
+
 	case capabilitiesMsg:
+		m.status.Stop()
 		m.capabilities = msg.caps
 		m.authData = msg.authData
 		if msg.err != nil {
-			m.err = msg.err
-			m.step = authStepError
-		} else {
-			m.step = authStepEnterToken
+			return m.finishProvider(msg.err)
 		}
+		m.step = authStepEnterToken
 		return m, nil
 
 	case verifyMsg:
+		m.status.Stop()
+		return m.finishProvider(msg.err)
+
+	case revokeMsg:
+		m.status.Stop()
 		if msg.err != nil {
-			m.err = msg.err
+			m.err = fmt.Errorf("failed to revoke %s: %w", msg.provider, msg.err)
 			m.step = authStepError
 		} else {
-			m.successMessage = fmt.Sprintf("✓ Successfully authenticated with %s!", m.selectedProvider)
+			m.successMessage = fmt.Sprintf("✓ Credentials for %s have been removed", msg.provider)
 			m.step = authStepComplete
+			m.refreshAuthenticated()
+			if msg.token != "" {
+				provider, token, refresh, meta := msg.provider, msg.token, msg.refresh, msg.meta
+				m.session.pushUndo("revoke "+string(provider), func() error {
+					return restoreCredential(provider, token, refresh, meta)
+				})
+				m.canUndo = true
+			}
 		}
 		return m, nil
 	}
@@ -236,6 +355,8 @@ func (m AuthModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.menuList, cmd = m.menuList.Update(msg)
 	case authStepSelectProvider:
 		m.providerList, cmd = m.providerList.Update(msg)
+	case authStepRevokeSelect:
+		m.revokeList, cmd = m.revokeList.Update(msg)
 	case authStepEnterToken:
 		m.tokenInput, cmd = m.tokenInput.Update(msg)
 	}
@@ -243,6 +364,28 @@ func (m AuthModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+// goUp returns to the parent step, quitting only from the top-level menu.
+// Steps waiting on a command ignore esc so its result isn't dropped.
+func (m AuthModel) goUp() (tea.Model, tea.Cmd) {
+	switch m.step {
+	case authStepMenu:
+		return m, tea.Quit
+	case authStepSelectProvider, authStepRevokeSelect, authStepComplete, authStepError:
+		m.err = nil
+		m.tokenWarning = ""
+		m.step = authStepMenu
+	case authStepEnterToken:
+		// Abandons any providers still queued
+		m.tokenInput.Reset()
+		m.authQueue = nil
+		m.authResults = nil
+		m.step = authStepSelectProvider
+	case authStepRevokeConfirm:
+		m.step = authStepRevokeSelect
+	}
+	return m, nil
+}
+
 func (m AuthModel) handleEnter() (tea.Model, tea.Cmd) {
 	switch m.step {
 	case authStepMenu:
@@ -260,25 +403,68 @@ func (m AuthModel) handleEnter() (tea.Model, tea.Cmd) {
 					m.successMessage = "No providers authenticated yet."
 				} else {
 					m.successMessage = "Authenticated providers:\n\n"
+					now := time.Now()
 					for _, p := range m.authenticatedProvs {
-						m.successMessage += GreenStyle.Render("✓ ") + p + "\n"
+						m.successMessage += GreenStyle.Render("✓ ") + p
+						if meta, _ := keychain.GetMeta(p); meta != nil {
+							m.successMessage += "  " + HelpStyle.Render(meta.Describe(now))
+						}
+						m.successMessage += "\n"
 					}
 				}
+			case "revoke":
+				if len(m.authenticatedProvs) == 0 {
+					m.step = authStepComplete
+					m.successMessage = "No providers authenticated yet."
+					break
+				}
+				items := make([]list.Item, len(m.authenticatedProvs))
+				for idx, p := range m.authenticatedProvs {
+					items[idx] = providerItem{
+						title:  p,
+						desc:   "Remove stored credentials",
+						value:  bridge.Provider(p),
+						authed: true,
+					}
+				}
+				m.revokeList.SetItems(items)
+				m.revokeList.ResetSelected()
+				m.step = authStepRevokeSelect
 			}
 		}
 
-	case authStepSelectProvider:
-		if i, ok := m.providerList.SelectedItem().(providerItem); ok {
+	case authStepRevokeSelect:
+		if i, ok := m.revokeList.SelectedItem().(providerItem); ok {
 			m.selectedProvider = i.value
-			m.step = authStepFetchingCapabilities
-			return m, fetchCapabilitiesCmd(m.bridge, m.ctx, m.selectedProvider)
+			m.step = authStepRevokeConfirm
+		}
+
+	case authStepSelectProvider:
+		// Checked providers are authenticated in turn; with none checked,
+		// enter authenticates the highlighted one
+		providers := m.checkedProviders()
+		if len(providers) == 0 {
+			if i, ok := m.providerList.SelectedItem().(providerItem); ok {
+				providers = []bridge.Provider{i.value}
+			}
+		}
+		if len(providers) > 0 {
+			m.authQueue = providers[1:]
+			m.authResults = nil
+			return m.startProvider(providers[0])
 		}
 
 	case authStepEnterToken:
 		m.token = m.tokenInput.Value()
 		if m.token != "" {
+			m.tokenWarning = validate.TokenFormat(string(m.selectedProvider), m.token)
 			m.step = authStepVerifying
-			return m, verifyTokenCmd(m.bridge, m.ctx, m.selectedProvider, m.token)
+			m.progressCh = newBridgeProgressCh()
+			return m, tea.Batch(
+				m.status.Start("Verifying credentials..."),
+				verifyTokenCmd(m.bridge, m.ctx, m.progressCh, m.selectedProvider, m.token),
+				waitForBridgeProgress(m.progressCh),
+			)
 		}
 
 	case authStepComplete, authStepError:
@@ -293,6 +479,10 @@ func (m AuthModel) View() string {
 		return "Loading..."
 	}
 
+	if m.showHelp {
+		return renderHelpOverlay(m.width, m.height, authKeys(m.step))
+	}
+
 	header := Header()
 	var content string
 
@@ -303,16 +493,14 @@ func (m AuthModel) View() string {
 	case authStepSelectProvider:
 		content = lipgloss.JoinVertical(
 			lipgloss.Left,
-			PromptStyle.Render("Select provider to authenticate:"),
+			PromptStyle.Render("Select providers to authenticate:"),
+			HelpStyle.Render("space to toggle • enter to authenticate the checked providers, or the highlighted one"),
 			"",
 			m.providerList.View(),
 		)
 
 	case authStepFetchingCapabilities:
-		content = lipgloss.JoinVertical(
-			lipgloss.Left,
-			m.spinner.View()+" Fetching provider capabilities...",
-		)
+		content = m.status.View()
 
 	case authStepEnterToken:
 		var instructions string
@@ -337,6 +525,7 @@ func (m AuthModel) View() string {
 
 		content = lipgloss.JoinVertical(
 			lipgloss.Left,
+			m.authProgress(),
 			SuccessStyle.Render(fmt.Sprintf("✓ Adapter: %s v%s", m.capabilities.AdapterName, m.capabilities.AdapterVersion)),
 			PromptStyle.Render(fmt.Sprintf("Auth Type: %s", m.capabilities.AuthType)),
 			"",
@@ -344,33 +533,59 @@ func (m AuthModel) View() string {
 			PromptStyle.Render("Paste your token:"),
 			m.tokenInput.View(),
 			"",
-			HelpStyle.Render("Press Enter to continue • Token will be stored securely in your system keychain"),
+			HelpStyle.Render("Press Enter to continue • esc to go back • Token will be stored securely"),
 		)
 
 	case authStepVerifying:
-		content = lipgloss.JoinVertical(
-			lipgloss.Left,
-			m.spinner.View()+" Verifying credentials...",
-		)
+		content = m.status.View()
+		if m.tokenWarning != "" {
+			content = lipgloss.JoinVertical(lipgloss.Left, YellowStyle.Render("⚠ "+m.tokenWarning), "", content)
+		}
 
-	case authStepComplete:
+	case authStepRevokeSelect:
 		content = lipgloss.JoinVertical(
 			lipgloss.Left,
-			SuccessStyle.Render(m.successMessage),
+			PromptStyle.Render("Select provider to revoke:"),
 			"",
-			HelpStyle.Render("Press q to return to dashboard"),
+			m.revokeList.View(),
 		)
 
-	case authStepError:
+	case authStepRevokeConfirm:
 		content = lipgloss.JoinVertical(
 			lipgloss.Left,
-			ErrorStyle.Render(fmt.Sprintf("✗ Error: %s", m.err)),
+			YellowStyle.Render(fmt.Sprintf("⚠ Remove stored credentials for %s?", m.selectedProvider)),
 			"",
-			HelpStyle.Render("Press q to return"),
+			HelpStyle.Render("You will need to authenticate again to use this provider."),
+			"",
+			PromptStyle.Render("Press y to revoke • n to cancel"),
 		)
+
+	case authStepRevoking:
+		content = m.status.View()
+
+	case authStepComplete:
+		lines := []string{SuccessStyle.Render(m.successMessage), ""}
+		if m.undoErr != nil {
+			lines = append(lines, ErrorStyle.Render(m.undoErr.Error()), "")
+		}
+		help := "Press q to return to dashboard"
+		if m.canUndo {
+			help = m.session.undoHint() + " • " + help
+		}
+		lines = append(lines, HelpStyle.Render(help))
+		content = lipgloss.JoinVertical(lipgloss.Left, lines...)
+
+	case authStepError:
+		lines := []string{ErrorStyle.Render(fmt.Sprintf("✗ Error: %s", m.err))}
+		if m.tokenWarning != "" {
+			lines = append(lines, YellowStyle.Render("⚠ "+m.tokenWarning))
+		}
+		lines = append(lines, "", HelpStyle.Render("Press q to return"))
+		content = lipgloss.JoinVertical(lipgloss.Left, lines...)
 	}
 
-	footer := StatusBarStyle.Render(" Deploy Tunnel Auth | q: back ")
+	footer := StatusBarStyle.Render(" Deploy Tunnel Auth | esc: back • ?: help ")
+	footer = withKeychainWarning(footer)
 
 	return lipgloss.JoinVertical(
 		lipgloss.Left,
@@ -392,9 +607,22 @@ type verifyMsg struct {
 	err error
 }
 
+type revokeMsg struct {
+	provider bridge.Provider
+	// token, refresh, and meta are what was removed, kept so the revoke can
+	// be undone
+	token   string
+	refresh string
+	meta    *keychain.TokenMeta
+	err     error
+}
+
 // Commands
-func fetchCapabilitiesCmd(br *bridge.Bridge, ctx context.Context, provider bridge.Provider) tea.Cmd {
+func fetchCapabilitiesCmd(br *bridge.Bridge, ctx context.Context, progressCh chan bridge.Progress, provider bridge.Provider) tea.Cmd {
 	return func() tea.Msg {
+		defer close(progressCh)
+		ctx := withBridgeProgress(ctx, progressCh)
+
 		caps, err := br.Capabilities(ctx, provider)
 		if err != nil {
 			return capabilitiesMsg{err: err}
@@ -411,28 +639,27 @@ func fetchCapabilitiesCmd(br *bridge.Bridge, ctx context.Context, provider bridg
 	}
 }
 
-func verifyTokenCmd(br *bridge.Bridge, ctx context.Context, provider bridge.Provider, token string) tea.Cmd {
+func verifyTokenCmd(br *bridge.Bridge, ctx context.Context, progressCh chan bridge.Progress, provider bridge.Provider, token string) tea.Cmd {
 	return func() tea.Msg {
-		// Store in keychain
-		if err := keychain.Store(string(provider), token); err != nil {
-			return verifyMsg{err: err}
-		}
+		defer close(progressCh)
+		ctx := withBridgeProgress(ctx, progressCh)
 
-		// Verify by fetching config (will fail with INVALID_PARAMS if no project, but token is valid)
-		_, err := br.FetchConfig(ctx, bridge.FetchConfigParams{
+		// Verify by fetching config (will fail with INVALID_PARAMS if no project, but token is valid).
+		// No auto refresh, or a stored credential could pass in place of this one.
+		_, err := br.FetchConfig(bridge.WithoutRefresh(ctx), bridge.FetchConfigParams{
 			Provider: provider,
 			Token:    token,
 		})
 
 		// INVALID_PARAMS means token works, just no project specified
-		if err != nil {
-			if bridgeErr, ok := err.(*bridge.BridgeError); ok {
-				if bridgeErr.Code == bridge.ErrInvalidParams {
-					return verifyMsg{err: nil}
-				}
-			}
-			// Delete token if verification failed
-			keychain.Delete(string(provider))
+		var bridgeErr *bridge.BridgeError
+		if err != nil && !(errors.As(err, &bridgeErr) && bridgeErr.Code == bridge.ErrInvalidParams) {
+			// Never persist a token that failed verification
+			return verifyMsg{err: err}
+		}
+
+		// Store in keychain only after the token is verified
+		if err := keychain.StoreWithMeta(string(provider), token, keychain.TokenMeta{CreatedAt: time.Now().UTC()}); err != nil {
 			return verifyMsg{err: err}
 		}
 
@@ -440,6 +667,192 @@ func verifyTokenCmd(br *bridge.Bridge, ctx context.Context, provider bridge.Prov
 	}
 }
 
+// revokeCmd removes a provider's credentials along with its metadata and
+// refresh token
+func revokeCmd(provider bridge.Provider) tea.Cmd {
+	return func() tea.Msg {
+		msg := revokeMsg{provider: provider}
+		// Without the old token the revoke just can't be undone
+		if token, err := keychain.Get(string(provider)); err == nil {
+			msg.token = token
+			msg.meta, _ = keychain.GetMeta(string(provider))
+			msg.refresh, _ = keychain.GetRefreshToken(string(provider))
+		}
+		msg.err = keychain.Purge(string(provider))
+		return msg
+	}
+}
+
+// restoreCredential stores a revoked token again, with its refresh token
+// and metadata if it had them
+func restoreCredential(provider bridge.Provider, token, refresh string, meta *keychain.TokenMeta) error {
+	if refresh != "" {
+		if err := keychain.StoreRefreshToken(string(provider), refresh); err != nil {
+			return err
+		}
+	}
+	if meta != nil {
+		return keychain.StoreWithMeta(string(provider), token, *meta)
+	}
+	return keychain.Store(string(provider), token)
+}
+
+// toggleProvider checks or unchecks the highlighted provider
+func (m *AuthModel) toggleProvider() {
+	if i, ok := m.providerList.SelectedItem().(providerItem); ok {
+		i.checked = !i.checked
+		m.providerList.SetItem(m.providerList.Index(), i)
+	}
+}
+
+// uncheckProviders clears every check once a run is over
+func (m *AuthModel) uncheckProviders() {
+	items := m.providerList.Items()
+	for i, it := range items {
+		if p, ok := it.(providerItem); ok {
+			p.checked = false
+			items[i] = p
+		}
+	}
+	m.providerList.SetItems(items)
+}
+
+// checkedProviders returns the checked providers in list order
+func (m AuthModel) checkedProviders() []bridge.Provider {
+	var providers []bridge.Provider
+	for _, it := range m.providerList.Items() {
+		if p, ok := it.(providerItem); ok && p.checked {
+			providers = append(providers, p.value)
+		}
+	}
+	return providers
+}
+
+// startProvider begins the token flow for one provider
+func (m AuthModel) startProvider(provider bridge.Provider) (tea.Model, tea.Cmd) {
+	m.selectedProvider = provider
+	m.capabilities = nil
+	m.authData = nil
+	m.tokenWarning = ""
+	m.tokenInput.Reset()
+	m.step = authStepFetchingCapabilities
+	m.progressCh = newBridgeProgressCh()
+	return m, tea.Batch(
+		m.status.Start(fmt.Sprintf("Fetching %s capabilities...", provider)),
+		fetchCapabilitiesCmd(m.bridge, m.ctx, m.progressCh, provider),
+		waitForBridgeProgress(m.progressCh),
+	)
+}
+
+// finishProvider records how the current provider went and starts the next
+// queued one. A single provider ends as before; several end with a summary.
+func (m AuthModel) finishProvider(err error) (tea.Model, tea.Cmd) {
+	m.authResults = append(m.authResults, authResult{provider: m.selectedProvider, err: err})
+	if err == nil {
+		m.refreshAuthenticated()
+	}
+
+	if len(m.authQueue) > 0 {
+		next := m.authQueue[0]
+		m.authQueue = m.authQueue[1:]
+		return m.startProvider(next)
+	}
+	m.uncheckProviders()
+
+	if len(m.authResults) == 1 {
+		if err != nil {
+			m.err = err
+			m.step = authStepError
+			return m, nil
+		}
+		m.successMessage = fmt.Sprintf("✓ Successfully authenticated with %s!", m.selectedProvider)
+		m.step = authStepComplete
+		return m, nil
+	}
+
+	m.successMessage = authSummary(m.authResults)
+	m.tokenWarning = ""
+	m.step = authStepComplete
+	return m, nil
+}
+
+// authProgress says which provider of a multi-provider run is being
+// authenticated, or is empty for a single one
+func (m AuthModel) authProgress() string {
+	total := len(m.authResults) + 1 + len(m.authQueue)
+	if total == 1 {
+		return ""
+	}
+	return HelpStyle.Render(fmt.Sprintf("Provider %d of %d", len(m.authResults)+1, total))
+}
+
+// authSummary lists which providers authenticated and which failed
+func authSummary(results []authResult) string {
+	succeeded := 0
+	for _, r := range results {
+		if r.err == nil {
+			succeeded++
+		}
+	}
+
+	summary := fmt.Sprintf("Authenticated %d of %d providers:\n\n", succeeded, len(results))
+	for _, r := range results {
+		if r.err != nil {
+			summary += RedStyle.Render("✗ ") + string(r.provider) + "  " + HelpStyle.Render(r.err.Error()) + "\n"
+		} else {
+			summary += GreenStyle.Render("✓ ") + string(r.provider) + "\n"
+		}
+	}
+	return summary
+}
+
+// undo reverses the revoke just made, putting the credentials back
+func (m AuthModel) undo() AuthModel {
+	if _, err := m.session.Undo(); err != nil {
+		m.undoErr = err
+		// A failed restore can be retried; an expired one can't
+		_, m.canUndo = m.session.lastUndo()
+		return m
+	}
+	m.undoErr = nil
+	m.canUndo = false
+	m.successMessage = fmt.Sprintf("↶ Credentials for %s have been restored", m.selectedProvider)
+	m.refreshAuthenticated()
+	return m
+}
+
+// refreshAuthenticated reloads stored credentials and updates the ✓ marks,
+// here and, through the session, on the dashboard this returns to
+func (m *AuthModel) refreshAuthenticated() {
+	m.authenticatedProvs = m.session.RefreshAuthed()
+	authed := make(map[string]bool)
+	for _, p := range m.authenticatedProvs {
+		authed[p] = true
+	}
+
+	items := m.providerList.Items()
+	for i, it := range items {
+		if p, ok := it.(providerItem); ok {
+			p.authed = authed[string(p.value)]
+			items[i] = p
+		}
+	}
+	m.providerList.SetItems(items)
+}
+
+// markUnavailable flags providers whose adapter didn't respond
+func (m *AuthModel) markUnavailable(results []bridge.ProviderCapabilities) {
+	unavailable := unavailableProviders(results)
+	items := m.providerList.Items()
+	for i, it := range items {
+		if p, ok := it.(providerItem); ok {
+			p.unavailable = unavailable[p.value]
+			items[i] = p
+		}
+	}
+	m.providerList.SetItems(items)
+}
+
 func openBrowser(url string) {
 	var cmd *exec.Cmd
 	switch runtime.GOOS {
@@ -455,17 +868,18 @@ func openBrowser(url string) {
 	cmd.Start()
 }
 
-// RunAuthTUI runs the interactive auth TUI
-func RunAuthTUI(stateDB *state.DB, br *bridge.Bridge) error {
+// RunAuthTUI runs the interactive auth TUI, then returns to the dashboard
+func RunAuthTUI(s *Session) error {
 	p := tea.NewProgram(
-		NewAuthModel(stateDB, br),
+		NewAuthModel(s),
 		tea.WithAltScreen(),
+		tea.WithContext(s.Ctx),
 	)
 
-	if _, err := p.Run(); err != nil {
-		return err
+	if _, err := p.Run(); err != nil || s.done() {
+		return runErr(s, err)
 	}
 
 	// Return to dashboard
-	return RunDashboardTUI(stateDB, br)
+	return RunDashboardTUI(s)
 }
@@ -0,0 +1,165 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/johnhorton/deploy-tunnel/internal/bridge"
+	"github.com/johnhorton/deploy-tunnel/internal/keychain"
+)
+
+// storeCredential stores a token for provider, removed again when the test ends
+func storeCredential(t *testing.T, provider, token string) {
+	t.Helper()
+	if err := keychain.Store(provider, token); err != nil {
+		t.Fatalf("Store(%s) error: %v", provider, err)
+	}
+	t.Cleanup(func() { keychain.Purge(provider) })
+}
+
+// revokeConfirm opens the revoke flow and selects the only stored provider
+func revokeConfirm(t *testing.T, m AuthModel) AuthModel {
+	t.Helper()
+	m, _ = press(t, m, "down", "down", "enter")
+	if m.step != authStepRevokeSelect {
+		t.Fatalf("after choosing revoke, step = %v, want %v", m.step, authStepRevokeSelect)
+	}
+	m, _ = press(t, m, "enter")
+	if m.step != authStepRevokeConfirm {
+		t.Fatalf("after choosing a provider, step = %v, want %v", m.step, authStepRevokeConfirm)
+	}
+	if m.selectedProvider != bridge.ProviderVercel {
+		t.Fatalf("selectedProvider = %q, want %q", m.selectedProvider, bridge.ProviderVercel)
+	}
+	return m
+}
+
+func TestAuthRevokeDeclined(t *testing.T) {
+	storeCredential(t, "vercel", "tok_declined")
+
+	m := revokeConfirm(t, sized(t, NewAuthModel(newTestSession(t, nil))))
+	m, cmd := press(t, m, "n")
+	if m.step != authStepMenu {
+		t.Errorf("after n, step = %v, want %v", m.step, authStepMenu)
+	}
+	if cmd != nil {
+		t.Error("declining returned a command, want none")
+	}
+	if token, err := keychain.Get("vercel"); err != nil || token != "tok_declined" {
+		t.Errorf("Get(vercel) = %q, %v, want the stored token kept", token, err)
+	}
+}
+
+func TestAuthRevokeAndUndo(t *testing.T) {
+	storeCredential(t, "vercel", "tok_revoked")
+	if err := keychain.StoreRefreshToken("vercel", "rt_revoked"); err != nil {
+		t.Fatal(err)
+	}
+
+	m := revokeConfirm(t, sized(t, NewAuthModel(newTestSession(t, nil))))
+	m, cmd := press(t, m, "y")
+	if m.step != authStepRevoking {
+		t.Fatalf("after y, step = %v, want %v", m.step, authStepRevoking)
+	}
+	if cmd == nil {
+		t.Fatal("confirming returned no command")
+	}
+
+	updated, _ := m.Update(revokeCmd(m.selectedProvider)())
+	m = updated.(AuthModel)
+	if m.step != authStepComplete {
+		t.Fatalf("after revoking, step = %v, want %v (err %v)", m.step, authStepComplete, m.err)
+	}
+	if ok, err := keychain.Exists("vercel"); err != nil || ok {
+		t.Errorf("Exists(vercel) = %v, %v after revoke, want false", ok, err)
+	}
+	if _, err := keychain.GetRefreshToken("vercel"); err == nil {
+		t.Error("vercel's refresh token survived the revoke")
+	}
+	if len(m.authenticatedProvs) != 0 {
+		t.Errorf("authenticatedProvs = %v after revoke, want none", m.authenticatedProvs)
+	}
+	if !m.canUndo {
+		t.Fatal("canUndo = false after revoke, want true")
+	}
+
+	m, _ = press(t, m, "u")
+	if m.undoErr != nil {
+		t.Fatalf("undo error: %v", m.undoErr)
+	}
+	if token, err := keychain.Get("vercel"); err != nil || token != "tok_revoked" {
+		t.Errorf("Get(vercel) = %q, %v after undo, want the token restored", token, err)
+	}
+	if refresh, err := keychain.GetRefreshToken("vercel"); err != nil || refresh != "rt_revoked" {
+		t.Errorf("GetRefreshToken(vercel) = %q, %v after undo, want it restored", refresh, err)
+	}
+	if m.canUndo {
+		t.Error("canUndo = true after undo, want false")
+	}
+}
+
+func TestAuthRevokeWithNothingStored(t *testing.T) {
+	m := sized(t, NewAuthModel(newTestSession(t, nil)))
+	m, _ = press(t, m, "down", "down", "enter")
+	if m.step != authStepComplete {
+		t.Errorf("step = %v, want %v", m.step, authStepComplete)
+	}
+	if m.successMessage != "No providers authenticated yet." {
+		t.Errorf("successMessage = %q", m.successMessage)
+	}
+}
+
+func TestAuthEscGoesUp(t *testing.T) {
+	tests := []struct {
+		name string
+		from authStep
+		want authStep
+	}{
+		{name: "provider select", from: authStepSelectProvider, want: authStepMenu},
+		{name: "token entry", from: authStepEnterToken, want: authStepSelectProvider},
+		{name: "revoke select", from: authStepRevokeSelect, want: authStepMenu},
+		{name: "revoke confirm", from: authStepRevokeConfirm, want: authStepRevokeSelect},
+		{name: "complete", from: authStepComplete, want: authStepMenu},
+		{name: "error", from: authStepError, want: authStepMenu},
+		// A running command's result would be dropped, so esc waits
+		{name: "verifying", from: authStepVerifying, want: authStepVerifying},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := sized(t, NewAuthModel(newTestSession(t, nil)))
+			m.step = tt.from
+
+			m, cmd := press(t, m, "esc")
+			if isQuit(cmd) {
+				t.Fatal("esc quit from a nested step")
+			}
+			if m.step != tt.want {
+				t.Errorf("step = %v, want %v", m.step, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthEscNotTakenByTokenInput(t *testing.T) {
+	m := sized(t, NewAuthModel(newTestSession(t, nil)))
+	m.step = authStepEnterToken
+	m.authQueue = []bridge.Provider{bridge.ProviderNetlify}
+
+	m, _ = press(t, m, "tok_typed", "esc")
+	if m.step != authStepSelectProvider {
+		t.Fatalf("step = %v, want %v", m.step, authStepSelectProvider)
+	}
+	if m.tokenInput.Value() != "" {
+		t.Errorf("token input = %q after esc, want it cleared", m.tokenInput.Value())
+	}
+	if len(m.authQueue) != 0 {
+		t.Errorf("auth queue = %v after esc, want it abandoned", m.authQueue)
+	}
+}
+
+func TestAuthEscQuitsFromMenu(t *testing.T) {
+	m := sized(t, NewAuthModel(newTestSession(t, nil)))
+	if _, cmd := press(t, m, "esc"); !isQuit(cmd) {
+		t.Error("esc on the auth menu didn't quit")
+	}
+}
@@ -22,46 +22,120 @@ func (i menuItem) Description() string { return i.desc }
 func (i menuItem) FilterValue() string { return i.title }
 
 type DashboardModel struct {
-	list      list.Model
-	stateDB   *state.DB
-	bridge    *bridge.Bridge
-	ctx       context.Context
-	width     int
-	height    int
-	selected  string
-	quitting  bool
-	migration *state.Migration
+	list             list.Model
+	session          *Session
+	stateDB          *state.DB
+	bridge           *bridge.Bridge
+	ctx              context.Context
+	cancel           context.CancelFunc
+	width            int
+	height           int
+	selected         string
+	quitting         bool
+	migration        *state.Migration
+	stats            *state.Stats
+	providerHealth   []bridge.ProviderCapabilities
+	showCapabilities bool
+	showHelp         bool
+}
+
+// lastMenuKey is the ui_state key holding the last chosen dashboard item
+const lastMenuKey = "dashboard.last_menu"
+
+// defaultMenu is the dashboard menu before user preferences are applied
+var defaultMenu = []menuItem{
+	{
+		title: "Start New Migration",
+		desc:  "Initialize a new migration between providers",
+		key:   "init",
+	},
+	{
+		title: "View Migrations",
+		desc:  "See your migration history and status",
+		key:   "list",
+	},
+	{
+		title: "Manage Auth",
+		desc:  "Authenticate with providers",
+		key:   "auth",
+	},
+	{
+		title: "Provider Capabilities",
+		desc:  "Compare what each provider adapter supports",
+		key:   "caps",
+	},
+	{
+		title: "Current Migration",
+		desc:  "Continue working on your active migration",
+		key:   "current",
+	},
+	{
+		title: "Exit",
+		desc:  "Quit Deploy Tunnel",
+		key:   "quit",
+	},
+}
+
+// MenuKeys returns the dashboard menu item keys in their default order
+func MenuKeys() []string {
+	keys := make([]string, len(defaultMenu))
+	for i, it := range defaultMenu {
+		keys[i] = it.key
+	}
+	return keys
+}
+
+// OrderMenuKeys applies prefs to the default menu: keys in prefs.Order come
+// first, the rest keep their default order, and hidden keys are dropped.
+// Unknown keys are ignored, and Exit is always kept.
+func OrderMenuKeys(prefs state.MenuPrefs) []string {
+	known := make(map[string]bool, len(defaultMenu))
+	for _, it := range defaultMenu {
+		known[it.key] = true
+	}
+	hidden := make(map[string]bool, len(prefs.Hidden))
+	for _, key := range prefs.Hidden {
+		hidden[key] = key != "quit"
+	}
+
+	var keys []string
+	placed := make(map[string]bool)
+	add := func(key string) {
+		if known[key] && !hidden[key] && !placed[key] {
+			placed[key] = true
+			keys = append(keys, key)
+		}
+	}
+	for _, key := range prefs.Order {
+		add(key)
+	}
+	for _, it := range defaultMenu {
+		add(it.key)
+	}
+	return keys
 }
 
-func NewDashboardModel(stateDB *state.DB, br *bridge.Bridge) DashboardModel {
-	items := []list.Item{
-		menuItem{
-			title: "Start New Migration",
-			desc:  "Initialize a new migration between providers",
-			key:   "init",
-		},
-		menuItem{
-			title: "View Migrations",
-			desc:  "See your migration history and status",
-			key:   "list",
-		},
-		menuItem{
-			title: "Manage Auth",
-			desc:  "Authenticate with providers",
-			key:   "auth",
-		},
-		menuItem{
-			title: "Current Migration",
-			desc:  "Continue working on your active migration",
-			key:   "current",
-		},
-		menuItem{
-			title: "Exit",
-			desc:  "Quit Deploy Tunnel",
-			key:   "quit",
-		},
+// menuItems builds the dashboard menu from the saved preferences, falling
+// back to the default menu if they can't be read
+func menuItems(stateDB *state.DB) []list.Item {
+	prefs, _ := stateDB.GetMenuPrefs()
+
+	byKey := make(map[string]menuItem, len(defaultMenu))
+	for _, it := range defaultMenu {
+		byKey[it.key] = it
 	}
 
+	var items []list.Item
+	for _, key := range OrderMenuKeys(prefs) {
+		items = append(items, byKey[key])
+	}
+	return items
+}
+
+func NewDashboardModel(s *Session) DashboardModel {
+	stateDB := s.StateDB
+	items := menuItems(stateDB)
+
 	delegate := list.NewDefaultDelegate()
 	delegate.Styles.SelectedTitle = SelectedItemStyle
 	delegate.Styles.SelectedDesc = lipgloss.NewStyle().Foreground(LightGray)
@@ -73,45 +147,88 @@ func NewDashboardModel(stateDB *state.DB, br *bridge.Bridge) DashboardModel {
 	l.Styles.Title = TitleStyle
 	l.Styles.HelpStyle = HelpStyle
 
-	// Try to load the most recent migration
-	migrations, _ := stateDB.ListMigrations("")
+	// Start where the user left off; unknown keys keep the first item selected
+	if last, err := stateDB.GetUIState(lastMenuKey); err == nil && last != "" {
+		for i, it := range items {
+			if it.(menuItem).key == last {
+				l.Select(i)
+				break
+			}
+		}
+	}
+
+	// Canceled on quit so in-flight queries and adapter calls abort
+	ctx, cancel := context.WithCancel(s.Ctx)
+
+	// Try to load the most recent migration, skipping archived ones
+	migrations, _ := stateDB.ListMigrationsContext(ctx, "", false)
 	var currentMigration *state.Migration
 	if len(migrations) > 0 {
 		currentMigration = &migrations[0]
 	}
 
+	var stats *state.Stats
+	if s, err := stateDB.StatsContext(ctx); err == nil {
+		stats = &s
+	}
+
 	return DashboardModel{
 		list:      l,
+		session:   s,
 		stateDB:   stateDB,
-		bridge:    br,
-		ctx:       context.Background(),
+		bridge:    s.Bridge,
+		ctx:       ctx,
+		cancel:    cancel,
 		migration: currentMigration,
+		stats:     stats,
 	}
 }
 
 func (m DashboardModel) Init() tea.Cmd {
-	return nil
+	return fetchProviderHealthCmd(m.bridge, m.ctx)
 }
 
 func (m DashboardModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if m.showHelp && msg.String() != "ctrl+c" {
+			if msg.String() == "?" || msg.String() == "esc" {
+				m.showHelp = false
+			}
+			return m, nil
+		}
 		switch msg.String() {
+		case "?":
+			m.showHelp = true
+			return m, nil
+
 		case "ctrl+c":
 			m.quitting = true
+			m.cancel()
 			return m, tea.Quit
 
 		case "q":
 			m.quitting = true
+			m.cancel()
 			return m, tea.Quit
 
 		case "enter":
+			if m.showCapabilities {
+				m.showCapabilities = false
+				return m, nil
+			}
+
 			if i, ok := m.list.SelectedItem().(menuItem); ok {
 				m.selected = i.key
+				if i.key != "quit" {
+					// Best effort: losing the preference shouldn't block navigation
+					m.stateDB.SetUIState(lastMenuKey, i.key)
+				}
 
 				switch i.key {
 				case "quit":
 					m.quitting = true
+					m.cancel()
 					return m, tea.Quit
 
 				case "init":
@@ -139,6 +256,9 @@ func (m DashboardModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					return m, func() tea.Msg {
 						return switchToListMsg{}
 					}
+
+				case "caps":
+					m.showCapabilities = true
 				}
 			}
 			// Don't propagate enter to list if we handled it
@@ -146,10 +266,22 @@ func (m DashboardModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 	case tea.WindowSizeMsg:
+		if m.width != 0 && msg.Width != m.width {
+			// Re-render the logo for the new width
+			ClearImageCache()
+		}
 		m.width = msg.Width
 		m.height = msg.Height
 		m.list.SetSize(msg.Width-4, msg.Height-15)
 		return m, nil
+
+	case providerHealthMsg:
+		m.providerHealth = msg.results
+		return m, nil
+
+	case switchToInitMsg, switchToAuthMsg, switchToListMsg, switchToMigrationMsg:
+		// Exit so RunDashboardTUI can start the selected TUI
+		return m, tea.Quit
 	}
 
 	// Update list for other keys (arrow keys, etc)
@@ -167,17 +299,16 @@ func (m DashboardModel) View() string {
 		return "Loading..."
 	}
 
+	if m.showHelp {
+		return renderHelpOverlay(m.width, m.height, dashboardKeys(m.showCapabilities))
+	}
+
 	header := Header()
 
 	// Show current migration info if exists
 	var migrationInfo string
 	if m.migration != nil {
-		statusStyle := YellowStyle
-		if m.migration.Status == "completed" {
-			statusStyle = GreenStyle
-		} else if m.migration.Status == "failed" {
-			statusStyle = RedStyle
-		}
+		statusStyle := StatusStyle(m.migration.Status)
 
 		migrationInfo = BoxStyle.Render(lipgloss.JoinVertical(
 			lipgloss.Left,
@@ -194,16 +325,41 @@ func (m DashboardModel) View() string {
 		)
 	}
 
+	if m.showCapabilities {
+		content := lipgloss.JoinVertical(
+			lipgloss.Left,
+			PromptStyle.Render("Provider Capabilities"),
+			"",
+			renderCapabilityMatrix(m.providerHealth),
+			"",
+			HelpStyle.Render("Press Enter to return to menu"),
+		)
+
+		return lipgloss.JoinVertical(
+			lipgloss.Left,
+			header,
+			content,
+		)
+	}
+
 	content := lipgloss.JoinVertical(
 		lipgloss.Left,
-		migrationInfo,
+		lipgloss.JoinHorizontal(
+			lipgloss.Top,
+			migrationInfo,
+			"  ",
+			renderStatsSummary(m.stats),
+			"  ",
+			renderHealthPanel(m.providerHealth, m.session.authedSet()),
+		),
 		"",
 		m.list.View(),
 	)
 
 	footer := StatusBarStyle.Render(
-		fmt.Sprintf(" Deploy Tunnel v1.0 | ↑↓ navigate • enter select • q quit "),
+		fmt.Sprintf(" %s | ↑↓ navigate • enter select • ? help • q quit ", appTitle()),
 	)
+	footer = withKeychainWarning(footer)
 
 	return lipgloss.JoinVertical(
 		lipgloss.Left,
@@ -214,6 +370,28 @@ func (m DashboardModel) View() string {
 	)
 }
 
+// renderStatsSummary renders migration counts for the dashboard
+func renderStatsSummary(stats *state.Stats) string {
+	if stats == nil {
+		return BoxStyle.Render(HelpStyle.Render("Summary unavailable"))
+	}
+
+	lines := []string{
+		PromptStyle.Render("Summary"),
+		"",
+		fmt.Sprintf("Migrations:  %s", InputStyle.Render(fmt.Sprintf("%d", stats.Total))),
+		fmt.Sprintf("Completed:   %s", GreenStyle.Render(fmt.Sprintf("%d", stats.ByStatus["completed"]))),
+		fmt.Sprintf("Failed:      %s", RedStyle.Render(fmt.Sprintf("%d", stats.ByStatus["failed"]))),
+		fmt.Sprintf("Env vars:    %s", InputStyle.Render(fmt.Sprintf("%d", stats.TotalEnvVars))),
+		fmt.Sprintf("DNS records: %s", InputStyle.Render(fmt.Sprintf("%d", stats.TotalDnsRecords))),
+	}
+	if !stats.LastCreatedAt.IsZero() {
+		lines = append(lines, fmt.Sprintf("Last:        %s", InputStyle.Render(stats.LastCreatedAt.Local().Format("Jan 2 15:04"))))
+	}
+
+	return BoxStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
+
 // Messages for switching between TUIs
 type switchToInitMsg struct{}
 type switchToAuthMsg struct{}
@@ -222,25 +400,36 @@ type switchToMigrationMsg struct {
 	migration *state.Migration
 }
 
-// RunDashboardTUI runs the main dashboard TUI
-func RunDashboardTUI(stateDB *state.DB, br *bridge.Bridge) error {
+// RunDashboardTUI runs the main dashboard TUI, then whichever TUI is
+// picked from it, all within s
+func RunDashboardTUI(s *Session) error {
+	dashboard := NewDashboardModel(s)
+	defer dashboard.cancel()
+
 	p := tea.NewProgram(
-		NewDashboardModel(stateDB, br),
+		dashboard,
 		tea.WithAltScreen(),
+		tea.WithContext(s.Ctx),
 	)
 
 	model, err := p.Run()
-	if err != nil {
-		return err
+	if err != nil || s.done() {
+		return runErr(s, err)
 	}
 
 	// Check if we need to switch to another TUI
-	if m, ok := model.(DashboardModel); ok {
+	if m, ok := model.(DashboardModel); ok && !m.quitting {
 		switch m.selected {
 		case "init":
-			return RunInitTUI(stateDB, br)
+			return RunInitTUI(s)
 		case "auth":
-			return RunAuthTUI(stateDB, br)
+			return RunAuthTUI(s)
+		case "list":
+			return RunListTUI(s)
+		case "current":
+			if m.migration != nil {
+				return RunMigrationTUI(s, m.migration)
+			}
 			// Add more cases as we build more TUIs
 		}
 	}
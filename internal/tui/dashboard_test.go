@@ -0,0 +1,119 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/johnhorton/deploy-tunnel/internal/state"
+)
+
+// selectedKey returns the key of the dashboard's highlighted menu item
+func selectedKey(m DashboardModel) string {
+	if i, ok := m.list.SelectedItem().(menuItem); ok {
+		return i.key
+	}
+	return ""
+}
+
+func TestDashboardRestoresLastMenuItem(t *testing.T) {
+	s := newTestSession(t, nil)
+
+	m := sized(t, NewDashboardModel(s))
+	if got := selectedKey(m); got != "init" {
+		t.Fatalf("first launch starts on %q, want init", got)
+	}
+	// Provider Capabilities, which stays on the dashboard when chosen
+	m, _ = press(t, m, "down", "down", "down", "enter")
+	m.cancel()
+	if got, _ := s.StateDB.GetUIState(lastMenuKey); got != "caps" {
+		t.Fatalf("saved menu item = %q, want caps", got)
+	}
+
+	next := sized(t, NewDashboardModel(s))
+	defer next.cancel()
+	if got := selectedKey(next); got != "caps" {
+		t.Errorf("next launch starts on %q, want caps", got)
+	}
+}
+
+func TestDashboardLastMenuItemFallback(t *testing.T) {
+	tests := []struct {
+		name  string
+		saved string
+	}{
+		{name: "removed item", saved: "settings"},
+		{name: "nothing saved", saved: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestSession(t, nil)
+			if err := s.StateDB.SetUIState(lastMenuKey, tt.saved); err != nil {
+				t.Fatal(err)
+			}
+
+			m := NewDashboardModel(s)
+			defer m.cancel()
+			if got := selectedKey(m); got != "init" {
+				t.Errorf("starts on %q, want the first item", got)
+			}
+		})
+	}
+}
+
+func TestDashboardQuitIsNotRemembered(t *testing.T) {
+	s := newTestSession(t, nil)
+	if err := s.StateDB.SetUIState(lastMenuKey, "auth"); err != nil {
+		t.Fatal(err)
+	}
+
+	m := sized(t, NewDashboardModel(s))
+	defer m.cancel()
+	m, cmd := press(t, m, "down", "down", "down", "enter")
+	if selectedKey(m) != "quit" || !isQuit(cmd) {
+		t.Fatalf("expected to quit from %q", selectedKey(m))
+	}
+	if got, _ := s.StateDB.GetUIState(lastMenuKey); got != "auth" {
+		t.Errorf("saved menu item = %q after quitting, want auth kept", got)
+	}
+}
+
+func TestOrderMenuKeys(t *testing.T) {
+	tests := []struct {
+		name  string
+		prefs state.MenuPrefs
+		want  string
+	}{
+		{name: "default", want: "init,list,auth,caps,current,quit"},
+		{name: "favorites first", prefs: state.MenuPrefs{Order: []string{"current", "auth"}}, want: "current,auth,init,list,caps,quit"},
+		{name: "hidden", prefs: state.MenuPrefs{Hidden: []string{"caps", "list"}}, want: "init,auth,current,quit"},
+		{name: "ordered and hidden", prefs: state.MenuPrefs{Order: []string{"caps", "init"}, Hidden: []string{"caps"}}, want: "init,list,auth,current,quit"},
+		{name: "exit can't be hidden", prefs: state.MenuPrefs{Hidden: []string{"quit"}}, want: "init,list,auth,caps,current,quit"},
+		{name: "unknown and repeated keys", prefs: state.MenuPrefs{Order: []string{"settings", "auth", "auth"}}, want: "auth,init,list,caps,current,quit"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := strings.Join(OrderMenuKeys(tt.prefs), ","); got != tt.want {
+				t.Errorf("OrderMenuKeys(%+v) = %s, want %s", tt.prefs, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDashboardFollowsMenuPrefs(t *testing.T) {
+	s := newTestSession(t, nil)
+	if err := s.StateDB.SetMenuPrefs(state.MenuPrefs{Order: []string{"current"}, Hidden: []string{"init"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewDashboardModel(s)
+	defer m.cancel()
+	var keys []string
+	for _, it := range m.list.Items() {
+		keys = append(keys, it.(menuItem).key)
+	}
+	if got, want := strings.Join(keys, ","), "current,list,auth,caps,quit"; got != want {
+		t.Errorf("dashboard menu = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,293 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/johnhorton/deploy-tunnel/internal/state"
+)
+
+// maskedValue stands in for env var values until they are revealed
+const maskedValue = "••••••••"
+
+// EnvModel reviews fetched env vars before they are synced: rows can be
+// excluded and their target key remapped
+type EnvModel struct {
+	migrationID string
+	vars        []state.EnvVar
+	dirty       map[int]bool
+	cursor      int
+	offset      int
+	editing     bool
+	keyInput    textinput.Model
+	reveal      bool
+	loading     bool
+	saving      bool
+	err         error
+	width       int
+	height      int
+	stateDB     *state.DB
+	ctx         context.Context
+}
+
+// envReviewDoneMsg reports that the review was confirmed (and saved) or cancelled
+type envReviewDoneMsg struct {
+	confirmed bool
+	err       error
+}
+
+type envVarsLoadedMsg struct {
+	vars []state.EnvVar
+	err  error
+}
+
+func NewEnvModel(stateDB *state.DB, ctx context.Context, migrationID string, width, height int) EnvModel {
+	ki := textinput.New()
+	ki.CharLimit = 255
+	ki.Width = 40
+	ki.Prompt = PromptStyle.Render("target key ► ")
+	ki.TextStyle = InputStyle
+
+	return EnvModel{
+		migrationID: migrationID,
+		dirty:       make(map[int]bool),
+		keyInput:    ki,
+		loading:     true,
+		width:       width,
+		height:      height,
+		stateDB:     stateDB,
+		ctx:         ctx,
+	}
+}
+
+func (m EnvModel) Init() tea.Cmd {
+	stateDB, ctx, migrationID := m.stateDB, m.ctx, m.migrationID
+	return func() tea.Msg {
+		vars, err := stateDB.GetEnvVarsContext(ctx, migrationID)
+		return envVarsLoadedMsg{vars: vars, err: err}
+	}
+}
+
+func (m EnvModel) Update(msg tea.Msg) (EnvModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case envVarsLoadedMsg:
+		m.loading = false
+		m.vars = msg.vars
+		m.err = msg.err
+		return m, nil
+
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.clampOffset()
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.loading || m.saving {
+			return m, nil
+		}
+		if m.editing {
+			return m.updateEditing(msg)
+		}
+
+		switch msg.String() {
+		case "esc":
+			return m, func() tea.Msg { return envReviewDoneMsg{} }
+
+		case "up", "k":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+			m.clampOffset()
+
+		case "down", "j":
+			if m.cursor < len(m.vars)-1 {
+				m.cursor++
+			}
+			m.clampOffset()
+
+		case " ", "x":
+			if len(m.vars) > 0 {
+				m.vars[m.cursor].Excluded = !m.vars[m.cursor].Excluded
+				m.dirty[m.cursor] = true
+			}
+
+		case "e":
+			if len(m.vars) > 0 {
+				m.editing = true
+				m.keyInput.SetValue(m.vars[m.cursor].SyncKey())
+				m.keyInput.CursorEnd()
+				return m, m.keyInput.Focus()
+			}
+
+		case "v":
+			m.reveal = !m.reveal
+
+		case "enter":
+			if m.err != nil {
+				return m, nil
+			}
+			m.saving = true
+			return m, m.saveCmd()
+		}
+		return m, nil
+	}
+
+	return m, nil
+}
+
+func (m EnvModel) updateEditing(msg tea.KeyMsg) (EnvModel, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		key := strings.TrimSpace(m.keyInput.Value())
+		if key == "" {
+			key = m.vars[m.cursor].Key
+		}
+		m.vars[m.cursor].TargetKey = key
+		m.dirty[m.cursor] = true
+		m.editing = false
+		m.keyInput.Blur()
+		return m, nil
+
+	case "esc":
+		m.editing = false
+		m.keyInput.Blur()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.keyInput, cmd = m.keyInput.Update(msg)
+	return m, cmd
+}
+
+// saveCmd persists edited rows and reports the review as confirmed
+func (m EnvModel) saveCmd() tea.Cmd {
+	var changed []state.EnvVar
+	for i := range m.vars {
+		if m.dirty[i] {
+			changed = append(changed, m.vars[i])
+		}
+	}
+	stateDB := m.stateDB
+
+	return func() tea.Msg {
+		for _, e := range changed {
+			if err := stateDB.UpdateEnvVar(e.ID, e.Value, e.TargetKey); err != nil {
+				return envReviewDoneMsg{err: fmt.Errorf("failed to save %s: %w", e.Key, err)}
+			}
+			if err := stateDB.SetEnvVarExcluded(e.ID, e.Excluded); err != nil {
+				return envReviewDoneMsg{err: fmt.Errorf("failed to save %s: %w", e.Key, err)}
+			}
+		}
+		return envReviewDoneMsg{confirmed: true}
+	}
+}
+
+func (m EnvModel) View() string {
+	if m.loading {
+		return HelpStyle.Render("Loading environment variables...")
+	}
+	if m.err != nil {
+		return ErrorStyle.Render(fmt.Sprintf("✗ Error: %s", m.err))
+	}
+	if len(m.vars) == 0 {
+		return HelpStyle.Render("No environment variables were fetched. Press Enter to continue.")
+	}
+
+	included := 0
+	keyWidth := len("KEY")
+	for _, e := range m.vars {
+		if !e.Excluded {
+			included++
+		}
+		if len(e.Key) > keyWidth {
+			keyWidth = len(e.Key)
+		}
+	}
+
+	lines := []string{
+		PromptStyle.Render(fmt.Sprintf("Review environment variables (%d of %d included)", included, len(m.vars))),
+		"",
+		HelpStyle.Render(fmt.Sprintf("      %-*s  %s", keyWidth, "KEY", "TARGET KEY = VALUE")),
+	}
+
+	end := m.offset + m.pageSize()
+	if end > len(m.vars) {
+		end = len(m.vars)
+	}
+	for i := m.offset; i < end; i++ {
+		lines = append(lines, m.renderRow(i, keyWidth))
+	}
+	if len(m.vars) > m.pageSize() {
+		lines = append(lines, HelpStyle.Render(fmt.Sprintf("      %d–%d of %d", m.offset+1, end, len(m.vars))))
+	}
+
+	if m.editing {
+		lines = append(lines, "", m.keyInput.View())
+	}
+	if m.saving {
+		lines = append(lines, "", HelpStyle.Render("Saving..."))
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+func (m EnvModel) renderRow(i, keyWidth int) string {
+	e := m.vars[i]
+
+	check := GreenStyle.Render("[x]")
+	if e.Excluded {
+		check = RedStyle.Render("[ ]")
+	}
+
+	value := maskedValue
+	if m.reveal {
+		value = e.Value
+	}
+
+	target := e.SyncKey()
+	mapping := InputStyle.Render(target)
+	if target != e.Key {
+		mapping = YellowStyle.Render("→ " + target)
+	}
+
+	row := fmt.Sprintf("%s %-*s  %s = %s", check, keyWidth, e.Key, mapping, HelpStyle.Render(value))
+	// Only scoped variables are tagged; the rest go to every environment
+	if len(e.Environments) > 0 {
+		row += HelpStyle.Render(" (" + strings.Join(e.Environments, ", ") + ")")
+	}
+	if i == m.cursor {
+		return PromptStyle.Render("▸ ") + row
+	}
+	return "  " + row
+}
+
+// HelpText is the footer text for the env review
+func (m EnvModel) HelpText() string {
+	if m.editing {
+		return " Env Review | enter save key • esc cancel edit "
+	}
+	return " Env Review | ↑↓ move • space include • e edit key • v reveal • enter confirm • esc cancel "
+}
+
+// pageSize is how many rows fit below the header and step list
+func (m EnvModel) pageSize() int {
+	size := m.height - lipgloss.Height(Header()) - len(state.StepOrder) - 14
+	if size < 3 {
+		size = 3
+	}
+	return size
+}
+
+func (m *EnvModel) clampOffset() {
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+m.pageSize() {
+		m.offset = m.cursor - m.pageSize() + 1
+	}
+}
@@ -0,0 +1,159 @@
+package tui
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/johnhorton/deploy-tunnel/internal/state"
+)
+
+// newEnvModel seeds env vars for a migration and opens the review on them, loaded
+func newEnvModel(t *testing.T) (EnvModel, *state.DB) {
+	t.Helper()
+	db := newTestSession(t, nil).StateDB
+	if err := db.CreateMigration("m1", "vercel", "netlify", "example.com"); err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range []struct{ key, value string }{
+		{"API_KEY", "sk_live_1"},
+		{"DEBUG", "1"},
+		{"SECRET", "hunter2"},
+	} {
+		if err := db.SaveEnvVar("m1", e.key, e.value, e.key, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	m := NewEnvModel(db, context.Background(), "m1", 120, 60)
+	m, _ = m.Update(m.Init()())
+	if len(m.vars) != 3 {
+		t.Fatalf("loaded %d env vars, want 3 (err %v)", len(m.vars), m.err)
+	}
+	return m, db
+}
+
+// envKeys sends each key to the env review in turn
+func envKeys(m EnvModel, keys ...string) EnvModel {
+	for _, key := range keys {
+		m, _ = m.Update(keyMsg(key))
+	}
+	return m
+}
+
+// moveTo moves the cursor to the row for key
+func moveTo(t *testing.T, m EnvModel, key string) EnvModel {
+	t.Helper()
+	m = envKeys(m, "up", "up", "up")
+	for i, e := range m.vars {
+		if e.Key == key {
+			for range i {
+				m = envKeys(m, "down")
+			}
+			return m
+		}
+	}
+	t.Fatalf("no row for %s", key)
+	return m
+}
+
+// storedVar reads key back from the database
+func storedVar(t *testing.T, db *state.DB, key string) state.EnvVar {
+	t.Helper()
+	vars, err := db.GetEnvVars("m1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range vars {
+		if e.Key == key {
+			return e
+		}
+	}
+	t.Fatalf("%s is not stored", key)
+	return state.EnvVar{}
+}
+
+func TestEnvReviewEditAndExclude(t *testing.T) {
+	m, db := newEnvModel(t)
+
+	m = envKeys(moveTo(t, m, "SECRET"), " ")
+	if !m.vars[m.cursor].Excluded {
+		t.Error("space didn't exclude the row")
+	}
+	if view := ansi.Strip(m.View()); !strings.Contains(view, "2 of 3 included") {
+		t.Errorf("view doesn't count the exclusion:\n%s", view)
+	}
+
+	// Replace the target key with NEW_API_KEY
+	m = envKeys(moveTo(t, m, "API_KEY"), "e")
+	if !m.editing {
+		t.Fatal("e didn't start editing the target key")
+	}
+	for range len("API_KEY") {
+		m = envKeys(m, "backspace")
+	}
+	m = envKeys(m, "NEW_API_KEY", "enter")
+	if m.editing || m.vars[m.cursor].TargetKey != "NEW_API_KEY" {
+		t.Errorf("after editing: editing = %v, target key = %q", m.editing, m.vars[m.cursor].TargetKey)
+	}
+
+	// esc abandons an edit
+	m = envKeys(moveTo(t, m, "DEBUG"), "e", "X", "esc")
+	if m.editing || m.vars[m.cursor].SyncKey() != "DEBUG" {
+		t.Errorf("after esc: editing = %v, target key = %q, want DEBUG kept", m.editing, m.vars[m.cursor].SyncKey())
+	}
+
+	m, cmd := m.Update(keyMsg("enter"))
+	if !m.saving || cmd == nil {
+		t.Fatal("enter didn't save the review")
+	}
+	done, ok := cmd().(envReviewDoneMsg)
+	if !ok || !done.confirmed || done.err != nil {
+		t.Fatalf("save returned %+v, want a confirmed review", done)
+	}
+
+	if e := storedVar(t, db, "SECRET"); !e.Excluded {
+		t.Error("SECRET wasn't stored as excluded")
+	}
+	if e := storedVar(t, db, "API_KEY"); e.TargetKey != "NEW_API_KEY" || e.Excluded || e.Value != "sk_live_1" {
+		t.Errorf("API_KEY stored as %+v, want target NEW_API_KEY, included, value kept", e)
+	}
+	if e := storedVar(t, db, "DEBUG"); e.SyncKey() != "DEBUG" || e.Excluded {
+		t.Errorf("DEBUG stored as %+v, want it unchanged", e)
+	}
+}
+
+func TestEnvReviewMasksValues(t *testing.T) {
+	m, _ := newEnvModel(t)
+
+	view := ansi.Strip(m.View())
+	if strings.Contains(view, "hunter2") || !strings.Contains(view, maskedValue) {
+		t.Errorf("values aren't masked by default:\n%s", view)
+	}
+
+	m = envKeys(m, "v")
+	if view := ansi.Strip(m.View()); !strings.Contains(view, "hunter2") {
+		t.Errorf("v didn't reveal the values:\n%s", view)
+	}
+	m = envKeys(m, "v")
+	if view := ansi.Strip(m.View()); strings.Contains(view, "hunter2") {
+		t.Errorf("v didn't mask the values again:\n%s", view)
+	}
+}
+
+func TestEnvReviewCancel(t *testing.T) {
+	m, db := newEnvModel(t)
+	m = envKeys(moveTo(t, m, "SECRET"), " ")
+
+	_, cmd := m.Update(keyMsg("esc"))
+	if cmd == nil {
+		t.Fatal("esc returned no command")
+	}
+	if done, ok := cmd().(envReviewDoneMsg); !ok || done.confirmed {
+		t.Errorf("esc returned %+v, want a cancelled review", done)
+	}
+	if e := storedVar(t, db, "SECRET"); e.Excluded {
+		t.Error("cancelling the review saved the exclusion")
+	}
+}
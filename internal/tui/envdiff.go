@@ -0,0 +1,187 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/johnhorton/deploy-tunnel/internal/bridge"
+	"github.com/johnhorton/deploy-tunnel/internal/state"
+	"github.com/johnhorton/deploy-tunnel/ui"
+)
+
+// EnvDiffModel compares the source and target env vars side by side, with
+// values masked
+type EnvDiffModel struct {
+	migration *state.Migration
+	projects  workflowProjects
+	diff      bridge.EnvDiffResult
+	rows      [][]string
+	offset    int
+	loading   bool
+	err       error
+	width     int
+	height    int
+	bridge    workflowBridge
+	ctx       context.Context
+}
+
+// envDiffClosedMsg reports that the diff panel was dismissed
+type envDiffClosedMsg struct{}
+
+type envDiffLoadedMsg struct {
+	diff bridge.EnvDiffResult
+	err  error
+}
+
+func NewEnvDiffModel(br workflowBridge, ctx context.Context, migration *state.Migration, projects workflowProjects, width, height int) EnvDiffModel {
+	return EnvDiffModel{
+		migration: migration,
+		projects:  projects,
+		loading:   true,
+		width:     width,
+		height:    height,
+		bridge:    br,
+		ctx:       ctx,
+	}
+}
+
+func (m EnvDiffModel) Init() tea.Cmd {
+	br, ctx, migration, projects := m.bridge, m.ctx, m.migration, m.projects
+	return func() tea.Msg {
+		source, err := fetchConfigFor(br, ctx, migration.Source, projects.SourceProjectID)
+		if err != nil {
+			return envDiffLoadedMsg{err: err}
+		}
+		target, err := fetchConfigFor(br, ctx, migration.Target, projects.TargetProjectID)
+		if err != nil {
+			return envDiffLoadedMsg{err: err}
+		}
+		return envDiffLoadedMsg{diff: bridge.EnvDiff(source, target)}
+	}
+}
+
+// fetchConfigFor fetches a project's config with the provider's stored token
+func fetchConfigFor(br workflowBridge, ctx context.Context, provider, project string) (*bridge.FetchConfigData, error) {
+	token, err := providerToken(provider)
+	if err != nil {
+		return nil, err
+	}
+	config, err := br.FetchConfig(ctx, bridge.FetchConfigParams{
+		Provider:  bridge.Provider(provider),
+		Token:     token,
+		ProjectID: project,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s config: %w", provider, err)
+	}
+	return config, nil
+}
+
+func (m EnvDiffModel) Update(msg tea.Msg) (EnvDiffModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case envDiffLoadedMsg:
+		m.loading = false
+		m.diff = msg.diff
+		m.err = msg.err
+		m.rows = envDiffRows(msg.diff)
+		return m, nil
+
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.clampOffset()
+		return m, nil
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc", "d":
+			return m, func() tea.Msg { return envDiffClosedMsg{} }
+
+		case "up", "k":
+			m.offset--
+			m.clampOffset()
+
+		case "down", "j":
+			m.offset++
+			m.clampOffset()
+		}
+	}
+	return m, nil
+}
+
+// envDiffRows lays out a diff as table rows, most actionable changes first
+func envDiffRows(diff bridge.EnvDiffResult) [][]string {
+	var rows [][]string
+	add := func(change string, entries []bridge.EnvDiffEntry) {
+		for _, e := range entries {
+			env := e.Environment
+			if env == "" {
+				env = "all"
+			}
+			source, target := e.SourceValue, e.TargetValue
+			if source == "" {
+				source = "-"
+			}
+			if target == "" {
+				target = "-"
+			}
+			rows = append(rows, []string{change, e.Key, env, source, target})
+		}
+	}
+	add("added", diff.Added)
+	add("changed", diff.Changed)
+	add("removed", diff.Removed)
+	return rows
+}
+
+func (m EnvDiffModel) View() string {
+	if m.loading {
+		return HelpStyle.Render("Comparing source and target environment variables...")
+	}
+	if m.err != nil {
+		return ErrorStyle.Render(fmt.Sprintf("✗ Error: %s", m.err))
+	}
+	if m.diff.Empty() {
+		return SuccessStyle.Render("✓ Source and target environment variables match")
+	}
+
+	end := m.offset + m.pageSize()
+	if end > len(m.rows) {
+		end = len(m.rows)
+	}
+	lines := []string{
+		PromptStyle.Render(fmt.Sprintf("Env diff: %d missing on %s, %d changed, %d only on %s",
+			len(m.diff.Added), m.migration.Target, len(m.diff.Changed), len(m.diff.Removed), m.migration.Target)),
+		"",
+		ui.TableWidth([]string{"CHANGE", "KEY", "ENVIRONMENT", "SOURCE", "TARGET"}, m.rows[m.offset:end], m.width-4),
+	}
+	if len(m.rows) > m.pageSize() {
+		lines = append(lines, HelpStyle.Render(fmt.Sprintf("%d–%d of %d", m.offset+1, end, len(m.rows))))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// HelpText is the footer shown while the diff is open
+func (m EnvDiffModel) HelpText() string {
+	return " Env Diff | ↑↓ scroll • esc close "
+}
+
+// pageSize is how many rows fit below the header and step list
+func (m EnvDiffModel) pageSize() int {
+	size := m.height - lipgloss.Height(Header()) - len(state.StepOrder) - 16
+	if size < 3 {
+		size = 3
+	}
+	return size
+}
+
+func (m *EnvDiffModel) clampOffset() {
+	if last := len(m.rows) - m.pageSize(); m.offset > last {
+		m.offset = last
+	}
+	if m.offset < 0 {
+		m.offset = 0
+	}
+}
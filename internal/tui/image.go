@@ -1,97 +1,124 @@
 package tui
 
 import (
+	"bytes"
+	_ "embed"
+	"fmt"
 	"image"
 	_ "image/png"
 	"os"
-	"path/filepath"
 	"strings"
 	"sync"
 
 	"github.com/BourgeoisBear/rasterm"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/johnhorton/deploy-tunnel/ui"
 	"github.com/qeesung/image2ascii/convert"
 	"golang.org/x/term"
 )
 
+// EnvLogo names a PNG to show instead of the built-in logo
+const EnvLogo = "DEPLOY_TUNNEL_LOGO"
+
+//go:embed deploytunnel.png
+var builtinLogo []byte
+
 var (
-	asciiArtCache     string
-	asciiArtCacheLock sync.Mutex
-	imageSupported    *bool
+	logoOnce  sync.Once
+	logoImage image.Image
+	logoNote  string
+
+	asciiArtCache      string
+	asciiArtCacheWidth int
+	asciiArtCacheLock  sync.Mutex
+	imageSupported     *bool
+
+	// The encoded protocol image for one mode and width; "" is cached too,
+	// so a terminal that can't show images doesn't retry on every redraw
+	protocolCache      string
+	protocolCacheMode  string
+	protocolCacheWidth int
+	protocolCacheValid bool
+	protocolCacheLock  sync.Mutex
 )
 
-// DisplayImage tries to display the deploytunnel.png image using terminal protocols
-// Falls back to ASCII art if protocols aren't supported
+// DisplayImage renders the logo the way ui.ImageMode asks. In auto mode it
+// uses a terminal image protocol when one is detected and stdout is a
+// terminal, falling back to ASCII art; a forced protocol that fails also
+// falls back to ASCII art. Quiet mode and the off mode show nothing.
 func DisplayImage() string {
+	if ui.Quiet() {
+		return ""
+	}
+	return renderLogo(ui.ImageMode(), term.IsTerminal(int(os.Stdout.Fd())))
+}
+
+// renderLogo draws the logo in mode; tty says whether stdout is a terminal
+func renderLogo(mode string, tty bool) string {
+	if mode == ui.ImageOff {
+		return ""
+	}
+
+	img, _ := loadLogo()
+	if img == nil {
+		return ""
+	}
+
 	// Get terminal width for scaling
 	termWidth, _, err := term.GetSize(int(os.Stdout.Fd()))
 	if err != nil || termWidth == 0 {
 		termWidth = 80 // Default fallback
 	}
 
-	// Try to load the image
-	imgPath := findImagePath()
-	if imgPath == "" {
-		return "" // No image found, skip
-	}
-
-	// Check if terminal supports image protocols
-	if supportsImageProtocol() {
-		if imgStr := tryTerminalImage(imgPath, termWidth); imgStr != "" {
+	// Escape sequences are garbage in a pipe or log file
+	if mode != ui.ImageASCII && (mode != ui.ImageAuto || tty) {
+		if imgStr := getProtocolImage(img, mode, termWidth); imgStr != "" {
 			return imgStr
 		}
 	}
 
 	// Fall back to ASCII art
-	return getASCIIArt(imgPath, termWidth)
+	return getASCIIArt(img, termWidth)
 }
 
-// findImagePath locates the deploytunnel.png file
-func findImagePath() string {
-	// Try multiple locations
-	var locations []string
-
-	// Try current working directory first
-	if cwd, err := os.Getwd(); err == nil {
-		locations = append(locations,
-			filepath.Join(cwd, "deploytunnel.png"),
-			filepath.Join(cwd, "..", "deploytunnel.png"),
-		)
-	}
-
-	// Try relative paths
-	locations = append(locations,
-		"deploytunnel.png",
-		"./deploytunnel.png",
-		"../deploytunnel.png",
-	)
-
-	// Try relative to executable (works for installed binaries)
-	if execPath, err := os.Executable(); err == nil {
-		// Resolve symlinks (important for go run and installed binaries)
-		if realExec, err := filepath.EvalSymlinks(execPath); err == nil {
-			execPath = realExec
-		}
-		execDir := filepath.Dir(execPath)
-		locations = append(locations,
-			filepath.Join(execDir, "deploytunnel.png"),
-			filepath.Join(execDir, "..", "deploytunnel.png"),
-			filepath.Join(execDir, "..", "..", "deploytunnel.png"),
-		)
-	}
-
-	// Check all locations
-	for _, path := range locations {
-		// Convert to absolute path for better debugging
-		if absPath, err := filepath.Abs(path); err == nil {
-			path = absPath
-		}
+// LogoNote explains why a DEPLOY_TUNNEL_LOGO file wasn't shown, or is empty
+func LogoNote() string {
+	_, note := loadLogo()
+	return note
+}
 
-		if info, err := os.Stat(path); err == nil && !info.IsDir() {
-			return path
+// loadLogo decodes the logo once: the DEPLOY_TUNNEL_LOGO file when it's set
+// and readable, otherwise the built-in one
+func loadLogo() (image.Image, string) {
+	logoOnce.Do(func() {
+		logoImage, logoNote = decodeLogo(os.Getenv(EnvLogo), builtinLogo)
+	})
+	return logoImage, logoNote
+}
+
+// decodeLogo decodes the file at path, falling back to builtin when path is
+// empty, missing, or not an image; note says which went wrong
+func decodeLogo(path string, builtin []byte) (img image.Image, note string) {
+	if path != "" {
+		data, err := os.ReadFile(path)
+		switch {
+		case os.IsNotExist(err):
+			note = fmt.Sprintf("logo %s not found; using the built-in logo", path)
+		case err != nil:
+			note = fmt.Sprintf("can't read logo %s: %s", path, err)
+		default:
+			if img, _, err = image.Decode(bytes.NewReader(data)); err == nil {
+				return img, ""
+			}
+			note = fmt.Sprintf("logo %s is not a valid image (%s); using the built-in logo", path, err)
 		}
 	}
 
-	return ""
+	img, _, err := image.Decode(bytes.NewReader(builtin))
+	if err != nil {
+		return nil, fmt.Sprintf("built-in logo is corrupt: %s", err)
+	}
+	return img, note
 }
 
 // supportsImageProtocol checks if the terminal supports image display
@@ -124,71 +151,118 @@ func supportsImageProtocol() bool {
 	return supported
 }
 
-// tryTerminalImage attempts to display the image using terminal protocols
-func tryTerminalImage(imgPath string, termWidth int) string {
-	file, err := os.Open(imgPath)
-	if err != nil {
-		return ""
+// getProtocolImage encodes img for a terminal image protocol, or retrieves
+// the cached encoding. Header runs on every redraw, so without the cache a
+// large logo would be scaled and re-encoded each frame.
+func getProtocolImage(img image.Image, mode string, termWidth int) string {
+	protocolCacheLock.Lock()
+	defer protocolCacheLock.Unlock()
+
+	if protocolCacheValid && protocolCacheMode == mode && protocolCacheWidth == termWidth {
+		return protocolCache
 	}
-	defer file.Close()
 
-	img, _, err := image.Decode(file)
-	if err != nil {
-		return ""
+	var imgStr string
+	switch mode {
+	case ui.ImageKitty:
+		imgStr = kittyImage(img, termWidth)
+	case ui.ImageIterm:
+		imgStr = itermImage(img, termWidth)
+	case ui.ImageSixel:
+		imgStr = sixelImage(img, termWidth)
+	case ui.ImageAuto:
+		if supportsImageProtocol() {
+			imgStr = tryTerminalImage(img, termWidth)
+		}
 	}
 
-	// Try to encode using rasterm protocols
-	var output strings.Builder
+	protocolCache = imgStr
+	protocolCacheMode = mode
+	protocolCacheWidth = termWidth
+	protocolCacheValid = true
+	return imgStr
+}
+
+// logoCols is how many terminal columns the logo spans
+func logoCols(termWidth int) int {
+	return int(float64(termWidth) * 0.75)
+}
 
+// tryTerminalImage attempts to display the image using terminal protocols
+func tryTerminalImage(img image.Image, termWidth int) string {
 	// Check which protocol to use based on terminal
 	termProgram := os.Getenv("TERM_PROGRAM")
 	kittyWindow := os.Getenv("KITTY_WINDOW_ID")
 
 	// Try Kitty protocol first (most capable)
 	if kittyWindow != "" || rasterm.IsKittyCapable() {
-		// Use DstCols for destination width in terminal columns
-		targetCols := uint32(float64(termWidth) * 0.75)
-		opts := rasterm.KittyImgOpts{
-			DstCols: targetCols,
-			DstRows: 0, // Auto height
-		}
-		if err := rasterm.KittyWriteImage(&output, img, opts); err == nil {
-			return output.String() + "\n"
+		if imgStr := kittyImage(img, termWidth); imgStr != "" {
+			return imgStr
 		}
 	}
 
 	// Try iTerm2 protocol
 	if termProgram == "iTerm.app" || rasterm.IsItermCapable() {
-		if err := rasterm.ItermWriteImage(&output, img); err == nil {
-			return output.String() + "\n"
+		if imgStr := itermImage(img, termWidth); imgStr != "" {
+			return imgStr
 		}
 	}
 
 	// Try Sixel protocol as last resort
 	if capable, err := rasterm.IsSixelCapable(); err == nil && capable {
-		// Convert to paletted image for Sixel
-		bounds := img.Bounds()
-		palettedImg := image.NewPaletted(bounds, nil)
-		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
-			for x := bounds.Min.X; x < bounds.Max.X; x++ {
-				palettedImg.Set(x, y, img.At(x, y))
-			}
-		}
-		if err := rasterm.SixelWriteImage(&output, palettedImg); err == nil {
-			return output.String() + "\n"
-		}
+		return sixelImage(img, termWidth)
 	}
 
 	return ""
 }
 
+// kittyImage encodes img with the Kitty protocol, or returns "" on failure.
+// img is scaled down first so a large logo isn't sent at full resolution.
+func kittyImage(img image.Image, termWidth int) string {
+	var output strings.Builder
+	// Use DstCols for destination width in terminal columns
+	targetCols := logoCols(termWidth)
+	opts := rasterm.KittyImgOpts{
+		DstCols: uint32(targetCols),
+		DstRows: 0, // Auto height
+	}
+	if err := rasterm.KittyWriteImage(&output, scaleToCols(img, targetCols), opts); err != nil {
+		return ""
+	}
+	return output.String() + "\n"
+}
+
+// itermImage encodes img with the iTerm2 protocol, or returns "" on failure.
+// img is scaled down first so a large logo isn't sent at full resolution.
+func itermImage(img image.Image, termWidth int) string {
+	var output strings.Builder
+	if err := rasterm.ItermWriteImage(&output, scaleToCols(img, logoCols(termWidth))); err != nil {
+		return ""
+	}
+	return output.String() + "\n"
+}
+
+// sixelImage encodes img with the Sixel protocol, or returns "" on failure.
+// Sixel needs a paletted image, so img is scaled to the same share of the
+// terminal as the Kitty path and quantized first.
+func sixelImage(img image.Image, termWidth int) string {
+	palettedImg := quantize(scaleToCols(img, logoCols(termWidth)), sixelColors)
+
+	var output strings.Builder
+	if err := rasterm.SixelWriteImage(&output, palettedImg); err != nil || output.Len() == 0 {
+		return ""
+	}
+	return output.String() + "\n"
+}
+
 // getASCIIArt generates or retrieves cached ASCII art
-func getASCIIArt(imgPath string, termWidth int) string {
+func getASCIIArt(img image.Image, termWidth int) string {
 	asciiArtCacheLock.Lock()
 	defer asciiArtCacheLock.Unlock()
 
-	// Return cached version if available
-	if asciiArtCache != "" {
+	// Return cached version if it was rendered for this width;
+	// both the art size and the centering depend on it
+	if asciiArtCache != "" && asciiArtCacheWidth == termWidth {
 		return asciiArtCache
 	}
 
@@ -205,31 +279,41 @@ func getASCIIArt(imgPath string, termWidth int) string {
 	convertOptions.Colored = false  // No color for cleaner output
 
 	converter := convert.NewImageConverter()
-	asciiArt := converter.ImageFile2ASCIIString(imgPath, &convertOptions)
+	asciiArt := converter.Image2ASCIIString(img, &convertOptions)
 
-	// Center the ASCII art
-	lines := strings.Split(asciiArt, "\n")
-	var centered strings.Builder
+	asciiArtCache = centerLines(asciiArt, termWidth)
+	asciiArtCacheWidth = termWidth
+	return asciiArtCache
+}
 
-	for _, line := range lines {
-		if line != "" {
-			padding := (termWidth - len(line)) / 2
-			if padding > 0 {
-				centered.WriteString(strings.Repeat(" ", padding))
-			}
-			centered.WriteString(line)
-			centered.WriteString("\n")
+// centerLines centers each non-empty line by its display width, so colored
+// or wide glyphs don't skew it. Lines as wide as the terminal aren't indented.
+func centerLines(text string, termWidth int) string {
+	var centered strings.Builder
+	for _, line := range strings.Split(text, "\n") {
+		if line == "" {
+			continue
+		}
+		if padding := (termWidth - lipgloss.Width(line)) / 2; padding > 0 {
+			centered.WriteString(strings.Repeat(" ", padding))
 		}
+		centered.WriteString(line)
+		centered.WriteString("\n")
 	}
-
-	asciiArtCache = centered.String()
-	return asciiArtCache
+	return centered.String()
 }
 
-// ClearImageCache clears the ASCII art cache (useful for testing or terminal resize)
+// ClearImageCache clears the ASCII art and protocol image caches (useful for
+// testing or terminal resize)
 func ClearImageCache() {
 	asciiArtCacheLock.Lock()
-	defer asciiArtCacheLock.Unlock()
 	asciiArtCache = ""
+	asciiArtCacheWidth = 0
 	imageSupported = nil
+	asciiArtCacheLock.Unlock()
+
+	protocolCacheLock.Lock()
+	defer protocolCacheLock.Unlock()
+	protocolCache = ""
+	protocolCacheValid = false
 }
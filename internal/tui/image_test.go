@@ -0,0 +1,269 @@
+package tui
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/johnhorton/deploy-tunnel/ui"
+)
+
+// artWidth returns the width of the widest line of art, without its centering
+func artWidth(art string) int {
+	width := 0
+	for _, line := range strings.Split(art, "\n") {
+		width = max(width, lipgloss.Width(strings.TrimLeft(line, " ")))
+	}
+	return width
+}
+
+// gradientImage is a left to right gray ramp, kept light enough that no
+// column renders as blank ASCII art (black renders as spaces)
+func gradientImage(w, h int) image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for x := range w {
+		v := uint8(128 + x*127/max(w-1, 1))
+		for y := range h {
+			img.SetNRGBA(x, y, color.NRGBA{R: v, G: v, B: v, A: 255})
+		}
+	}
+	return img
+}
+
+func TestASCIIArtFollowsWidth(t *testing.T) {
+	ClearImageCache()
+	t.Cleanup(ClearImageCache)
+	img := gradientImage(20, 4)
+
+	narrow := getASCIIArt(img, 80)
+	wide := getASCIIArt(img, 120)
+	if narrow == wide {
+		t.Fatal("art rendered at 120 columns is the art cached at 80")
+	}
+	if got, want := artWidth(narrow), 60; got != want {
+		t.Errorf("art width at 80 columns = %d, want %d", got, want)
+	}
+	if got, want := artWidth(wide), 80; got != want {
+		t.Errorf("art width at 120 columns = %d, want %d", got, want)
+	}
+	if again := getASCIIArt(img, 80); again != narrow {
+		t.Error("rendering at 80 columns again gave different art")
+	}
+}
+
+func TestResizeClearsASCIIArtCache(t *testing.T) {
+	ClearImageCache()
+	t.Cleanup(ClearImageCache)
+	img := gradientImage(20, 4)
+
+	m := sized(t, NewListModel(newTestSession(t, nil)))
+	getASCIIArt(img, 120)
+
+	// The same width keeps the cache
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	if cachedASCIIArt() == "" {
+		t.Error("a resize to the same width cleared the cache")
+	}
+
+	updated.Update(tea.WindowSizeMsg{Width: 90, Height: 40})
+	if cachedASCIIArt() != "" {
+		t.Error("a resize to a new width kept the cached art")
+	}
+}
+
+// cachedASCIIArt reads the ASCII art cache under its lock
+func cachedASCIIArt() string {
+	asciiArtCacheLock.Lock()
+	defer asciiArtCacheLock.Unlock()
+	return asciiArtCache
+}
+
+func TestCenterLines(t *testing.T) {
+	colored := "\x1b[31m####\x1b[0m"
+	tests := []struct {
+		name  string
+		text  string
+		width int
+		want  string
+	}{
+		{name: "ascii", text: "####", width: 10, want: "   ####\n"},
+		{name: "odd padding rounds down", text: "####", width: 9, want: "  ####\n"},
+		// Four double-width glyphs take 8 columns, not 4 runes or 12 bytes
+		{name: "wide glyphs", text: "漢字漢字", width: 10, want: " 漢字漢字\n"},
+		{name: "block glyphs", text: "█▓▒░", width: 10, want: "   █▓▒░\n"},
+		{name: "escapes take no width", text: colored, width: 10, want: "   " + colored + "\n"},
+		{name: "as wide as the terminal", text: "##########", width: 10, want: "##########\n"},
+		{name: "wider than the terminal", text: "漢字漢字漢字", width: 10, want: "漢字漢字漢字\n"},
+		{name: "empty lines dropped", text: "\n##\n\n##\n", width: 4, want: " ##\n ##\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := centerLines(tt.text, tt.width); got != tt.want {
+				t.Errorf("centerLines(%q, %d) = %q, want %q", tt.text, tt.width, got, tt.want)
+			}
+		})
+	}
+}
+
+// writeLogo writes a 2x1 PNG to a temp dir and returns its path
+func writeLogo(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "logo.png")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if err := png.Encode(file, image.NewGray(image.Rect(0, 0, 2, 1))); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestDecodeLogo(t *testing.T) {
+	dir := t.TempDir()
+	corrupt := filepath.Join(dir, "corrupt.png")
+	if err := os.WriteFile(corrupt, []byte("not a png"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		path string
+		// wantNote is a substring of the expected note, "" when there is none
+		wantNote   string
+		wantCustom bool
+	}{
+		{name: "custom logo", path: writeLogo(t), wantCustom: true},
+		{name: "missing", path: filepath.Join(dir, "missing.png"), wantNote: "not found; using the built-in logo"},
+		{name: "unreadable", path: dir, wantNote: "can't read logo " + dir},
+		{name: "corrupt", path: corrupt, wantNote: "is not a valid image"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img, note := decodeLogo(tt.path, builtinLogo)
+			if img == nil {
+				t.Fatalf("decodeLogo(%q) returned no image (note %q)", tt.path, note)
+			}
+			if tt.wantNote == "" && note != "" {
+				t.Errorf("decodeLogo(%q) note = %q, want none", tt.path, note)
+			}
+			if !strings.Contains(note, tt.wantNote) {
+				t.Errorf("decodeLogo(%q) note = %q, want %q", tt.path, note, tt.wantNote)
+			}
+			if custom := img.Bounds().Dx() == 2; custom != tt.wantCustom {
+				t.Errorf("decodeLogo(%q) used the custom logo = %v, want %v", tt.path, custom, tt.wantCustom)
+			}
+		})
+	}
+}
+
+func TestBuiltinLogo(t *testing.T) {
+	img, note := decodeLogo("", builtinLogo)
+	if img == nil || note != "" {
+		t.Fatalf("decodeLogo(\"\") = %v, %q, want the built-in logo and no note", img, note)
+	}
+	if got := img.Bounds().Size(); got != image.Pt(500, 500) {
+		t.Errorf("built-in logo is %v, want 500x500", got)
+	}
+
+	if img, note := decodeLogo("", []byte("not a png")); img != nil || !strings.Contains(note, "built-in logo is corrupt") {
+		t.Errorf("decodeLogo with a corrupt built-in = %v, %q, want no image and a note", img, note)
+	}
+}
+
+func TestRenderLogoModes(t *testing.T) {
+	tests := []struct {
+		name string
+		mode string
+		tty  bool
+		// prefix starts the output; "" expects ASCII art, "-" nothing at all
+		prefix string
+	}{
+		{name: "off", mode: ui.ImageOff, tty: true, prefix: "-"},
+		{name: "ascii", mode: ui.ImageASCII, tty: true},
+		{name: "kitty", mode: ui.ImageKitty, prefix: "\x1b_G"},
+		{name: "iterm", mode: ui.ImageIterm, prefix: "\x1b]1337;"},
+		{name: "sixel", mode: ui.ImageSixel, prefix: "\x1bP"},
+		// Escapes would be garbage in a pipe, so auto draws ASCII there
+		{name: "auto when redirected", mode: ui.ImageAuto},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ClearImageCache()
+			t.Cleanup(ClearImageCache)
+
+			got := renderLogo(tt.mode, tt.tty)
+			switch tt.prefix {
+			case "-":
+				if got != "" {
+					t.Errorf("renderLogo(%s) drew %d bytes, want nothing", tt.mode, len(got))
+				}
+			case "":
+				if got == "" || strings.Contains(got, "\x1b") {
+					t.Errorf("renderLogo(%s) = %.40q, want ASCII art without escapes", tt.mode, got)
+				}
+			default:
+				if !strings.HasPrefix(got, tt.prefix) {
+					t.Errorf("renderLogo(%s) = %.40q, want it to start with %q", tt.mode, got, tt.prefix)
+				}
+			}
+		})
+	}
+}
+
+func TestScaleToCols(t *testing.T) {
+	img := image.NewGray(image.Rect(0, 0, 500, 250))
+
+	tests := []struct {
+		name string
+		cols int
+		want image.Point
+	}{
+		{name: "shrunk keeping aspect", cols: 30, want: image.Pt(300, 150)},
+		{name: "already fits", cols: 60, want: image.Pt(500, 250)},
+		{name: "no columns", cols: 0, want: image.Pt(500, 250)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scaleToCols(img, tt.cols).Bounds().Size(); got != tt.want {
+				t.Errorf("scaleToCols(%d) = %v, want %v", tt.cols, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProtocolImageCache(t *testing.T) {
+	ClearImageCache()
+	t.Cleanup(ClearImageCache)
+	img := image.NewGray(image.Rect(0, 0, 2000, 1000))
+
+	narrow := getProtocolImage(img, ui.ImageIterm, 80)
+	if narrow == "" {
+		t.Fatal("getProtocolImage() drew nothing")
+	}
+	// A full 2000px image would encode to far more than the scaled 600px one
+	if full := itermImage(img, 1000); len(narrow) >= len(full) {
+		t.Errorf("encoded %d bytes at 80 columns, want less than the %d of a barely scaled image", len(narrow), len(full))
+	}
+
+	if again := getProtocolImage(img, ui.ImageIterm, 80); again != narrow {
+		t.Error("the same width and mode re-encoded differently")
+	}
+	if wide := getProtocolImage(img, ui.ImageIterm, 120); wide == narrow {
+		t.Error("a new width returned the image cached at 80 columns")
+	}
+	if kitty := getProtocolImage(img, ui.ImageKitty, 120); strings.HasPrefix(kitty, "\x1b]1337;") {
+		t.Error("a new mode returned the image cached for iterm")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -12,6 +13,7 @@ import (
 	"github.com/johnhorton/deploy-tunnel/internal/bridge"
 	"github.com/johnhorton/deploy-tunnel/internal/keychain"
 	"github.com/johnhorton/deploy-tunnel/internal/state"
+	"github.com/johnhorton/deploy-tunnel/internal/validate"
 )
 
 type initStep int
@@ -25,33 +27,78 @@ const (
 )
 
 type InitModel struct {
-	step           initStep
-	sourceList     list.Model
-	targetList     list.Model
-	domainInput    textinput.Model
+	step        initStep
+	sourceList  list.Model
+	targetList  list.Model
+	domainInput textinput.Model
+	// domainList offers the source's domains; empty when they couldn't be listed
+	domainList list.Model
+	// typingDomain is set when the user chose to type a domain not in domainList
+	typingDomain   bool
 	selectedSource bridge.Provider
 	selectedTarget bridge.Provider
 	domain         string
 	migrationID    string
 	err            error
+	domainErr      error
 	width          int
 	height         int
-	stateDB        *state.DB
-	bridge         *bridge.Bridge
-	ctx            context.Context
+	showHelp       bool
+	// undone is set once the created migration was undone with u
+	undone  bool
+	undoErr error
+	// status runs while the adapters are checked
+	status  statusSpinner
+	session *Session
+	stateDB *state.DB
+	bridge  *bridge.Bridge
+	ctx     context.Context
 }
 
 type item struct {
 	title string
 	desc  string
 	value bridge.Provider
+	// unavailable is why the provider's adapter didn't respond, if it didn't
+	unavailable string
 }
 
-func (i item) Title() string       { return i.title }
-func (i item) Description() string { return i.desc }
+func (i item) Title() string { return i.title }
+func (i item) Description() string {
+	if i.unavailable != "" {
+		return RedStyle.Render("adapter unavailable: " + i.unavailable)
+	}
+	return i.desc
+}
 func (i item) FilterValue() string { return i.title }
 
-func NewInitModel(stateDB *state.DB, br *bridge.Bridge) InitModel {
+// domainItem is a domain on the source, or the entry for typing another one
+type domainItem struct {
+	domain bridge.Domain
+	other  bool
+}
+
+func (i domainItem) Title() string {
+	if i.other {
+		return "Enter another domain..."
+	}
+	return i.domain.Name
+}
+func (i domainItem) Description() string {
+	switch {
+	case i.other:
+		return "Type a domain not listed on the source"
+	case !i.domain.Verified:
+		return YellowStyle.Render("not verified")
+	case i.domain.ProjectID != "":
+		return "project " + i.domain.ProjectID
+	default:
+		return "verified"
+	}
+}
+func (i domainItem) FilterValue() string { return i.Title() }
+
+func NewInitModel(s *Session) InitModel {
 	// Provider items
 	items := []list.Item{
 		item{title: "Vercel", desc: "Deploy in seconds with Vercel", value: bridge.ProviderVercel},
@@ -85,40 +132,117 @@ func NewInitModel(stateDB *state.DB, br *bridge.Bridge) InitModel {
 	domainInput.Prompt = PromptStyle.Render("► ")
 	domainInput.TextStyle = InputStyle
 
+	// Domain list, filled in once the source's domains are fetched
+	domainList := list.New(nil, list.NewDefaultDelegate(), 0, 0)
+	domainList.Title = "Select Domain"
+	domainList.SetShowStatusBar(false)
+	domainList.SetFilteringEnabled(false)
+	domainList.Styles.Title = TitleStyle
+	domainList.Styles.HelpStyle = HelpStyle
+
 	return InitModel{
 		step:        stepSelectSource,
 		sourceList:  sourceList,
 		targetList:  targetList,
 		domainInput: domainInput,
-		stateDB:     stateDB,
-		bridge:      br,
-		ctx:         context.Background(),
+		domainList:  domainList,
+		status:      newStatusSpinner(false),
+		session:     s,
+		stateDB:     s.StateDB,
+		bridge:      s.Bridge,
+		ctx:         s.Ctx,
 	}
 }
 
 func (m InitModel) Init() tea.Cmd {
-	return nil
+	return tea.Batch(m.status.Start("Checking adapters..."), fetchProviderHealthCmd(m.bridge, m.ctx))
 }
 
 func (m InitModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if m.showHelp && msg.String() != "ctrl+c" {
+			if msg.String() == "?" || msg.String() == "esc" {
+				m.showHelp = false
+			}
+			return m, nil
+		}
 		switch msg.String() {
+		case "?":
+			m.showHelp = true
+			return m, nil
+
 		case "ctrl+c":
 			return m, tea.Quit
 
 		case "q":
 			return m, tea.Quit
 
+		case "u":
+			if m.step == stepComplete && m.err == nil && !m.undone {
+				return m.undo(), nil
+			}
+
+		case "esc":
+			// The first step is the top level, so esc leaves the flow
+			if m.step == stepSelectSource {
+				return m, tea.Quit
+			}
+			return m.goBack(), nil
+
+		case "left", "b":
+			// These are ordinary input while typing the domain
+			if !m.typingDomainInput() {
+				return m.goBack(), nil
+			}
+
 		case "enter":
 			return m.handleEnter()
 		}
 
+	case spinner.TickMsg:
+		var cmd tea.Cmd
+		m.status, cmd = m.status.Update(msg)
+		return m, cmd
+
+	case providerHealthMsg:
+		m.status.Stop()
+		unavailable := unavailableProviders(msg.results)
+		markUnavailableItems(&m.sourceList, unavailable)
+		markUnavailableItems(&m.targetList, unavailable)
+		return m, nil
+
+	case sourceDomainsMsg:
+		// Ignore a late result for a source the user has since changed
+		if msg.provider != m.selectedSource {
+			return m, nil
+		}
+		m.status.Stop()
+		// Without domains the step falls back to free-text entry
+		if msg.err == nil && len(msg.domains) > 0 {
+			items := make([]list.Item, 0, len(msg.domains)+1)
+			for _, d := range msg.domains {
+				items = append(items, domainItem{domain: d})
+			}
+			items = append(items, domainItem{other: true})
+			m.domainList.SetItems(items)
+			// Don't swap the input out from under someone already typing
+			if m.step == stepEnterDomain && m.domainInput.Value() != "" {
+				m.typingDomain = true
+			}
+		}
+		return m, nil
+
 	case tea.WindowSizeMsg:
+		if m.width != 0 && msg.Width != m.width {
+			// Re-render the logo for the new width
+			ClearImageCache()
+		}
 		m.width = msg.Width
 		m.height = msg.Height
 		m.sourceList.SetSize(msg.Width-4, msg.Height-10)
 		m.targetList.SetSize(msg.Width-4, msg.Height-10)
+		m.domainList.SetSize(msg.Width-4, msg.Height-14)
 		return m, nil
 	}
 
@@ -130,18 +254,44 @@ func (m InitModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case stepSelectTarget:
 		m.targetList, cmd = m.targetList.Update(msg)
 	case stepEnterDomain:
-		m.domainInput, cmd = m.domainInput.Update(msg)
+		if m.typingDomainInput() {
+			m.domainInput, cmd = m.domainInput.Update(msg)
+		} else {
+			m.domainList, cmd = m.domainList.Update(msg)
+		}
 	}
 
 	return m, cmd
 }
 
+// typingDomainInput reports whether the domain step shows the text input
+// rather than the source's domains
+func (m InitModel) typingDomainInput() bool {
+	return m.step == stepEnterDomain && (m.typingDomain || len(m.domainList.Items()) == 0)
+}
+
+// markUnavailableItems flags the list's providers whose adapter didn't respond
+func markUnavailableItems(l *list.Model, unavailable map[bridge.Provider]string) {
+	items := l.Items()
+	for i, it := range items {
+		if p, ok := it.(item); ok {
+			p.unavailable = unavailable[p.value]
+			items[i] = p
+		}
+	}
+	l.SetItems(items)
+}
+
 func (m InitModel) handleEnter() (tea.Model, tea.Cmd) {
 	switch m.step {
 	case stepSelectSource:
 		if i, ok := m.sourceList.SelectedItem().(item); ok {
+			changed := i.value != m.selectedSource
 			m.selectedSource = i.value
 			m.step = stepSelectTarget
+			if changed {
+				return m.fetchSourceDomains()
+			}
 		}
 
 	case stepSelectTarget:
@@ -151,10 +301,24 @@ func (m InitModel) handleEnter() (tea.Model, tea.Cmd) {
 		}
 
 	case stepEnterDomain:
-		m.domain = m.domainInput.Value()
-		if m.domain != "" {
-			m.step = stepConfirm
+		if !m.typingDomainInput() {
+			i, ok := m.domainList.SelectedItem().(domainItem)
+			if !ok {
+				return m, nil
+			}
+			if i.other {
+				m.typingDomain = true
+				return m, nil
+			}
+			m.domainInput.SetValue(i.domain.Name)
 		}
+		if err := validate.Domain(m.domainInput.Value()); err != nil {
+			m.domainErr = err
+			return m, nil
+		}
+		m.domainErr = nil
+		m.domain = validate.NormalizeDomain(m.domainInput.Value())
+		m.step = stepConfirm
 
 	case stepConfirm:
 		// Create migration
@@ -168,18 +332,66 @@ func (m InitModel) handleEnter() (tea.Model, tea.Cmd) {
 			m.err = err
 			return m, nil
 		}
+		stateDB, id := m.stateDB, m.migrationID
+		m.session.pushUndo("create migration", func() error {
+			return stateDB.DeleteMigration(id)
+		})
+		// Stay on the summary so the creation can still be undone
 		m.step = stepComplete
-		return m, tea.Quit
+		return m, nil
 	}
 
 	return m, nil
 }
 
+// undo reverses the last action in the session, normally creating this
+// migration
+func (m InitModel) undo() InitModel {
+	if _, err := m.session.Undo(); err != nil {
+		m.undoErr = err
+		return m
+	}
+	m.undoErr = nil
+	m.undone = true
+	return m
+}
+
+// fetchSourceDomains starts listing the new source's domains when its
+// credentials are stored, so the domain step can offer them
+func (m InitModel) fetchSourceDomains() (tea.Model, tea.Cmd) {
+	m.domainList.SetItems(nil)
+	m.typingDomain = false
+
+	token, err := keychain.Get(string(m.selectedSource))
+	if err != nil || token == "" {
+		return m, nil
+	}
+	start := m.status.Start(fmt.Sprintf("Loading domains on %s...", m.selectedSource))
+	return m, tea.Batch(start, fetchSourceDomainsCmd(m.bridge, m.ctx, m.selectedSource, token))
+}
+
+// goBack returns to the previous step, keeping earlier selections and input
+func (m InitModel) goBack() InitModel {
+	// Typing a domain backs out to the source's domains first
+	if m.step == stepEnterDomain && m.typingDomain {
+		m.typingDomain = false
+		return m
+	}
+	if m.step > stepSelectSource && m.step < stepComplete {
+		m.step--
+	}
+	return m
+}
+
 func (m InitModel) View() string {
 	if m.width == 0 {
 		return "Loading..."
 	}
 
+	if m.showHelp {
+		return renderHelpOverlay(m.width, m.height, initKeys(m.step, m.step == stepEnterDomain && !m.typingDomainInput()))
+	}
+
 	header := Header()
 
 	var content string
@@ -191,6 +403,7 @@ func (m InitModel) View() string {
 			StepIndicator(1, 4, "Where are you migrating FROM?"),
 			"",
 			m.sourceList.View(),
+			m.status.View(),
 		)
 
 	case stepSelectTarget:
@@ -201,6 +414,7 @@ func (m InitModel) View() string {
 			SuccessStyle.Render(fmt.Sprintf("✓ Source: %s", m.selectedSource)),
 			"",
 			m.targetList.View(),
+			m.status.View(),
 		)
 
 	case stepEnterDomain:
@@ -216,21 +430,30 @@ func (m InitModel) View() string {
 			"",
 			HelpStyle.Render("Press Enter to continue"),
 		)
+		if !m.typingDomainInput() {
+			content = lipgloss.JoinVertical(
+				lipgloss.Left,
+				StepIndicator(3, 4, "What domain are you migrating?"),
+				"",
+				SuccessStyle.Render(fmt.Sprintf("✓ Source: %s", m.selectedSource)),
+				SuccessStyle.Render(fmt.Sprintf("✓ Target: %s", m.selectedTarget)),
+				"",
+				m.domainList.View(),
+			)
+		}
+		if m.domainErr != nil {
+			content = lipgloss.JoinVertical(
+				lipgloss.Left,
+				content,
+				"",
+				ErrorStyle.Render(fmt.Sprintf("✗ %s", m.domainErr)),
+			)
+		}
 
 	case stepConfirm:
 		// Check auth status
-		sourceAuth, _ := keychain.Get(string(m.selectedSource))
-		targetAuth, _ := keychain.Get(string(m.selectedTarget))
-
-		sourceStatus := RedStyle.Render("✗ Not authenticated")
-		if sourceAuth != "" {
-			sourceStatus = GreenStyle.Render("✓ Authenticated")
-		}
-
-		targetStatus := RedStyle.Render("✗ Not authenticated")
-		if targetAuth != "" {
-			targetStatus = GreenStyle.Render("✓ Authenticated")
-		}
+		sourceStatus := authStatus(m.selectedSource)
+		targetStatus := authStatus(m.selectedTarget)
 
 		confirmBox := BoxStyle.Render(lipgloss.JoinVertical(
 			lipgloss.Left,
@@ -255,9 +478,17 @@ func (m InitModel) View() string {
 		)
 
 	case stepComplete:
-		if m.err != nil {
+		switch {
+		case m.err != nil:
 			content = ErrorStyle.Render(fmt.Sprintf("Error: %s", m.err))
-		} else {
+		case m.undone:
+			content = lipgloss.JoinVertical(
+				lipgloss.Left,
+				SuccessStyle.Render("↶ Migration creation undone"),
+				"",
+				HelpStyle.Render(fmt.Sprintf("Migration %s was deleted. Run 'dt init' to start over.", m.migrationID)),
+			)
+		default:
 			content = lipgloss.JoinVertical(
 				lipgloss.Left,
 				SuccessStyle.Render("✓ Migration initialized successfully!"),
@@ -273,11 +504,21 @@ func (m InitModel) View() string {
 					UnselectedItemStyle.Render("3. Start the migration workflow"),
 				)),
 			)
+			if m.undoErr != nil {
+				content = lipgloss.JoinVertical(lipgloss.Left, content, "", ErrorStyle.Render(m.undoErr.Error()))
+			}
 		}
 	}
 
+	help := "esc/← back • q quit"
+	switch {
+	case m.step == stepComplete && m.err == nil && !m.undone:
+		help = m.session.undoHint() + " • q quit"
+	case m.step == stepSelectSource || m.step == stepComplete:
+		help = "Press 'q' to quit"
+	}
 	footer := StatusBarStyle.Render(
-		fmt.Sprintf(" %s | Press 'q' to quit ", "Deploy Tunnel v1.0"),
+		fmt.Sprintf(" %s | %s • ? help ", appTitle(), help),
 	)
 
 	return lipgloss.JoinVertical(
@@ -290,15 +531,27 @@ func (m InitModel) View() string {
 }
 
 // RunInitTUI runs the interactive init TUI
-func RunInitTUI(stateDB *state.DB, br *bridge.Bridge) error {
+func RunInitTUI(s *Session) error {
 	p := tea.NewProgram(
-		NewInitModel(stateDB, br),
+		NewInitModel(s),
 		tea.WithAltScreen(),
+		tea.WithContext(s.Ctx),
 	)
 
-	if _, err := p.Run(); err != nil {
-		return err
-	}
+	_, err := p.Run()
+	return runErr(s, err)
+}
 
-	return nil
+// authStatus renders whether credentials are stored for provider, showing
+// "unknown" when the keychain can't be read rather than claiming they're missing
+func authStatus(provider bridge.Provider) string {
+	exists, err := keychain.Exists(string(provider))
+	switch {
+	case err != nil:
+		return YellowStyle.Render("? Unknown (keychain unavailable)")
+	case exists:
+		return GreenStyle.Render("✓ Authenticated")
+	default:
+		return RedStyle.Render("✗ Not authenticated")
+	}
 }
@@ -0,0 +1,79 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/johnhorton/deploy-tunnel/internal/bridge"
+)
+
+func TestInitBackKeepsInput(t *testing.T) {
+	m := sized(t, NewInitModel(newTestSession(t, nil)))
+
+	// Vercel → Netlify for example.com, up to the confirm step
+	m, _ = press(t, m, "enter", "down", "down", "down", "enter", "example.com", "enter")
+	if m.step != stepConfirm {
+		t.Fatalf("step = %v, want %v (domain error %v)", m.step, stepConfirm, m.domainErr)
+	}
+
+	m, _ = press(t, m, "esc")
+	if m.step != stepEnterDomain {
+		t.Fatalf("esc from confirm: step = %v, want %v", m.step, stepEnterDomain)
+	}
+	if got := m.domainInput.Value(); got != "example.com" {
+		t.Errorf("domain input = %q after going back, want %q", got, "example.com")
+	}
+
+	m, _ = press(t, m, "esc")
+	if m.step != stepSelectTarget {
+		t.Fatalf("esc from domain: step = %v, want %v", m.step, stepSelectTarget)
+	}
+	if m.selectedTarget != bridge.ProviderNetlify || m.targetList.Index() != 3 {
+		t.Errorf("target = %q at index %d, want netlify kept selected", m.selectedTarget, m.targetList.Index())
+	}
+
+	// left and b go back too, but never past the first step
+	m, _ = press(t, m, "left")
+	if m.step != stepSelectSource {
+		t.Fatalf("left from target: step = %v, want %v", m.step, stepSelectSource)
+	}
+	m, _ = press(t, m, "b")
+	if m.step != stepSelectSource {
+		t.Errorf("b on the first step: step = %v, want %v", m.step, stepSelectSource)
+	}
+	if m.selectedSource != bridge.ProviderVercel {
+		t.Errorf("source = %q, want vercel kept", m.selectedSource)
+	}
+
+	// Going forward again keeps what was entered
+	m, _ = press(t, m, "enter", "enter")
+	if m.step != stepEnterDomain {
+		t.Fatalf("step = %v, want %v", m.step, stepEnterDomain)
+	}
+	if got := m.domainInput.Value(); got != "example.com" {
+		t.Errorf("domain input = %q after returning, want %q", got, "example.com")
+	}
+}
+
+func TestInitBackKeysWhileTypingDomain(t *testing.T) {
+	m := sized(t, NewInitModel(newTestSession(t, nil)))
+	m, _ = press(t, m, "enter", "enter")
+	if !m.typingDomainInput() {
+		t.Fatal("domain step isn't showing the text input")
+	}
+
+	// b and left are ordinary input in the domain field
+	m, _ = press(t, m, "b", "left")
+	if m.step != stepEnterDomain {
+		t.Errorf("step = %v, want %v", m.step, stepEnterDomain)
+	}
+	if got := m.domainInput.Value(); got != "b" {
+		t.Errorf("domain input = %q, want %q", got, "b")
+	}
+}
+
+func TestInitEscQuitsFromFirstStep(t *testing.T) {
+	m := sized(t, NewInitModel(newTestSession(t, nil)))
+	if _, cmd := press(t, m, "esc"); !isQuit(cmd) {
+		t.Error("esc on the first step didn't quit")
+	}
+}
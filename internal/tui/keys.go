@@ -0,0 +1,99 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// keyHelp describes a keybinding for the help overlay
+type keyHelp struct {
+	keys string
+	desc string
+}
+
+// Shared bindings, so every screen describes them the same way
+var (
+	keyNavigate = keyHelp{"↑/↓", "move selection"}
+	keySelect   = keyHelp{"enter", "select"}
+	keyContinue = keyHelp{"enter", "continue"}
+	keyBack     = keyHelp{"esc/←/b", "previous step"}
+	keyEscBack  = keyHelp{"esc", "previous step"}
+	keyEscUp    = keyHelp{"esc", "go back"}
+	keyEscQuit  = keyHelp{"esc", "quit"}
+	keyQuit     = keyHelp{"q", "quit"}
+	keyReturn   = keyHelp{"q", "return to dashboard"}
+	keyForceQ   = keyHelp{"ctrl+c", "quit immediately"}
+	keyHelpKey  = keyHelp{"?", "toggle this help"}
+	keyUndo     = keyHelp{"u", "undo the last action (for 2 minutes)"}
+)
+
+// dashboardKeys lists the dashboard bindings
+func dashboardKeys(showCapabilities bool) []keyHelp {
+	if showCapabilities {
+		return []keyHelp{{"enter", "back to menu"}, keyQuit, keyForceQ, keyHelpKey}
+	}
+	return []keyHelp{keyNavigate, keySelect, keyQuit, keyForceQ, keyHelpKey}
+}
+
+// initKeys lists the init bindings for a step; pickingDomain is set when the
+// domain step lists the source's domains instead of taking typed input
+func initKeys(step initStep, pickingDomain bool) []keyHelp {
+	switch step {
+	case stepSelectSource:
+		return []keyHelp{keyNavigate, keySelect, keyQuit, keyEscQuit, keyForceQ, keyHelpKey}
+	case stepSelectTarget:
+		return []keyHelp{keyNavigate, keySelect, keyBack, keyQuit, keyForceQ, keyHelpKey}
+	case stepEnterDomain:
+		if pickingDomain {
+			return []keyHelp{keyNavigate, keySelect, keyBack, keyQuit, keyForceQ, keyHelpKey}
+		}
+		return []keyHelp{{"type", "enter the domain"}, keyContinue, keyEscBack, keyForceQ, keyHelpKey}
+	case stepConfirm:
+		return []keyHelp{{"enter", "create migration"}, keyBack, {"q", "cancel"}, keyForceQ, keyHelpKey}
+	case stepComplete:
+		return []keyHelp{keyUndo, keyQuit, keyForceQ, keyHelpKey}
+	default:
+		return []keyHelp{keyQuit, keyForceQ, keyHelpKey}
+	}
+}
+
+// authKeys lists the auth bindings for a step
+func authKeys(step authStep) []keyHelp {
+	switch step {
+	case authStepMenu:
+		return []keyHelp{keyNavigate, keySelect, keyReturn, {"esc", "return to dashboard"}, keyForceQ, keyHelpKey}
+	case authStepSelectProvider:
+		return []keyHelp{keyNavigate, {"space", "check or uncheck provider"}, {"enter", "authenticate checked (or highlighted) providers"}, keyEscUp, keyForceQ, keyHelpKey}
+	case authStepRevokeSelect:
+		return []keyHelp{keyNavigate, keySelect, keyEscUp, keyForceQ, keyHelpKey}
+	case authStepEnterToken:
+		return []keyHelp{{"paste", "enter your token"}, {"enter", "verify and store"}, keyEscUp, keyForceQ}
+	case authStepRevokeConfirm:
+		return []keyHelp{{"y", "revoke credentials"}, {"n", "cancel"}, keyEscUp, keyForceQ, keyHelpKey}
+	case authStepComplete:
+		return []keyHelp{keyUndo, keyReturn, {"esc", "back to menu"}, keyForceQ, keyHelpKey}
+	default:
+		return []keyHelp{keyReturn, {"esc", "back to menu"}, keyForceQ, keyHelpKey}
+	}
+}
+
+// renderHelpOverlay renders the bindings in a box centered over the screen
+func renderHelpOverlay(width, height int, bindings []keyHelp) string {
+	keyWidth := 0
+	for _, b := range bindings {
+		if w := lipgloss.Width(b.keys); w > keyWidth {
+			keyWidth = w
+		}
+	}
+
+	lines := []string{TitleStyle.Render("Keyboard Shortcuts"), ""}
+	for _, b := range bindings {
+		pad := strings.Repeat(" ", keyWidth-lipgloss.Width(b.keys))
+		lines = append(lines, PromptStyle.Render(b.keys)+pad+"  "+InputStyle.Render(b.desc))
+	}
+	lines = append(lines, "", HelpStyle.Render("Press ? or esc to close"))
+
+	box := BoxStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, box)
+}
@@ -0,0 +1,93 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// helpShown reports whether m has its help overlay open
+func helpShown(m tea.Model) bool {
+	switch m := m.(type) {
+	case DashboardModel:
+		return m.showHelp
+	case InitModel:
+		return m.showHelp
+	case AuthModel:
+		return m.showHelp
+	}
+	return false
+}
+
+func TestHelpOverlayToggle(t *testing.T) {
+	tests := []struct {
+		name  string
+		model func(s *Session) tea.Model
+		// want are binding descriptions the overlay must list
+		want []string
+	}{
+		{
+			name:  "dashboard",
+			model: func(s *Session) tea.Model { return NewDashboardModel(s) },
+			want:  []string{"move selection", "quit immediately", "toggle this help"},
+		},
+		{
+			name:  "init",
+			model: func(s *Session) tea.Model { return NewInitModel(s) },
+			want:  []string{"move selection", "quit", "toggle this help"},
+		},
+		{
+			name:  "auth",
+			model: func(s *Session) tea.Model { return NewAuthModel(s) },
+			want:  []string{"move selection", "return to dashboard", "toggle this help"},
+		},
+	}
+
+	for _, tt := range tests {
+		for _, dismiss := range []string{"?", "esc"} {
+			t.Run(tt.name+" closed with "+dismiss, func(t *testing.T) {
+				m := sized(t, tt.model(newTestSession(t, nil)))
+				if d, ok := m.(DashboardModel); ok {
+					t.Cleanup(d.cancel)
+				}
+
+				m, _ = press(t, m, "?")
+				if !helpShown(m) {
+					t.Fatal("? didn't open the help overlay")
+				}
+				view := ansi.Strip(m.View())
+				for _, want := range tt.want {
+					if !strings.Contains(view, want) {
+						t.Errorf("help overlay is missing %q:\n%s", want, view)
+					}
+				}
+
+				// Keys other than the dismissals are swallowed while it's open
+				m, cmd := press(t, m, "q")
+				if !helpShown(m) || cmd != nil {
+					t.Error("q acted behind the help overlay")
+				}
+
+				m, _ = press(t, m, dismiss)
+				if helpShown(m) {
+					t.Errorf("%s didn't close the help overlay", dismiss)
+				}
+			})
+		}
+	}
+}
+
+func TestHelpKeyIsInputWhileTypingToken(t *testing.T) {
+	m := sized(t, NewAuthModel(newTestSession(t, nil)))
+	m.step = authStepEnterToken
+
+	m, _ = press(t, m, "?")
+	if m.showHelp {
+		t.Error("? opened the help overlay while typing a token")
+	}
+	if got := m.tokenInput.Value(); got != "?" {
+		t.Errorf("token input = %q, want %q", got, "?")
+	}
+}
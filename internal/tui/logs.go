@@ -0,0 +1,286 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/johnhorton/deploy-tunnel/internal/state"
+)
+
+const (
+	// logViewLimit caps how many entries the log viewer keeps in memory
+	logViewLimit = 500
+	// logPollInterval is how often the log viewer checks for new rows
+	logPollInterval = 2 * time.Second
+)
+
+// logLevels is the minimum-level cycle order for the log viewer
+var logLevels = []string{"debug", "info", "warn", "error"}
+
+// LogsModel is a scrollable, tailing view of a migration's logs
+type LogsModel struct {
+	id          int64
+	migrationID string
+	domain      string
+	viewport    viewport.Model
+	filterInput textinput.Model
+	filtering   bool
+	minLevel    int
+	entries     []state.LogEntry
+	lastID      int
+	err         error
+	width       int
+	height      int
+	stateDB     *state.DB
+	ctx         context.Context
+}
+
+// closeLogsMsg asks the parent model to dismiss the log viewer
+type closeLogsMsg struct{}
+
+type logsLoadedMsg struct {
+	id       int64
+	minLevel int
+	entries  []state.LogEntry
+	replace  bool
+	err      error
+}
+
+type logsTickMsg struct {
+	id int64
+}
+
+func NewLogsModel(stateDB *state.DB, ctx context.Context, migration state.Migration, width, height int) LogsModel {
+	fi := textinput.New()
+	fi.Placeholder = "filter messages"
+	fi.Prompt = "/"
+	fi.CharLimit = 100
+
+	m := LogsModel{
+		// Distinguishes this viewer's poll loop from any earlier, closed one
+		id:          time.Now().UnixNano(),
+		migrationID: migration.ID,
+		domain:      migration.Domain,
+		filterInput: fi,
+		stateDB:     stateDB,
+		ctx:         ctx,
+	}
+	m.setSize(width, height)
+	return m
+}
+
+func (m LogsModel) Init() tea.Cmd {
+	return tea.Batch(m.loadCmd(true), m.tickCmd())
+}
+
+func (m LogsModel) Update(msg tea.Msg) (LogsModel, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if m.filtering {
+			switch msg.String() {
+			case "enter":
+				m.filtering = false
+				m.filterInput.Blur()
+				return m, nil
+			case "esc":
+				m.filtering = false
+				m.filterInput.Blur()
+				m.filterInput.SetValue("")
+				m.refresh()
+				return m, nil
+			}
+			var cmd tea.Cmd
+			m.filterInput, cmd = m.filterInput.Update(msg)
+			m.refresh()
+			return m, cmd
+		}
+
+		switch msg.String() {
+		case "q", "esc":
+			return m, func() tea.Msg { return closeLogsMsg{} }
+
+		case "/":
+			m.filtering = true
+			return m, m.filterInput.Focus()
+
+		case "l":
+			m.minLevel = (m.minLevel + 1) % len(logLevels)
+			return m, m.loadCmd(true)
+		}
+
+	case tea.WindowSizeMsg:
+		m.setSize(msg.Width, msg.Height)
+		m.refresh()
+		return m, nil
+
+	case logsTickMsg:
+		if msg.id != m.id {
+			return m, nil
+		}
+		return m, tea.Batch(m.loadCmd(false), m.tickCmd())
+
+	case logsLoadedMsg:
+		// Drop results from a poll that started before the level changed
+		if msg.id != m.id || msg.minLevel != m.minLevel {
+			return m, nil
+		}
+		m.err = msg.err
+		if msg.err != nil {
+			return m, nil
+		}
+		m.appendEntries(msg.entries, msg.replace)
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(msg)
+	return m, cmd
+}
+
+func (m LogsModel) View() string {
+	level := "all levels"
+	if m.minLevel > 0 {
+		level = logLevels[m.minLevel] + "+"
+	}
+	title := lipgloss.JoinHorizontal(
+		lipgloss.Top,
+		PromptStyle.Render(fmt.Sprintf("Logs: %s", m.domain)),
+		HelpStyle.Render(fmt.Sprintf("  %s • %d shown", level, len(m.visible()))),
+	)
+
+	filter := HelpStyle.Render("press / to filter")
+	if m.filtering || m.filterInput.Value() != "" {
+		filter = m.filterInput.View()
+	}
+
+	body := m.viewport.View()
+	if m.err != nil {
+		body = ErrorStyle.Render(fmt.Sprintf("✗ Error: %s", m.err))
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, title, filter, "", body)
+}
+
+// HelpText is the footer text for the log viewer
+func (m LogsModel) HelpText() string {
+	if m.filtering {
+		return " Logs | enter apply • esc clear "
+	}
+	return " Logs | ↑↓ scroll • / filter • l level • q back "
+}
+
+func (m *LogsModel) setSize(width, height int) {
+	m.width = width
+	m.height = height
+
+	// Leave room for the header, title, filter line, and footer
+	vh := height - lipgloss.Height(Header()) - 6
+	if vh < 3 {
+		vh = 3
+	}
+	m.viewport = viewport.New(width-4, vh)
+}
+
+// appendEntries merges freshly loaded rows, keeping the view pinned to the bottom while tailing
+func (m *LogsModel) appendEntries(entries []state.LogEntry, replace bool) {
+	// Queries return newest first
+	chrono := make([]state.LogEntry, 0, len(entries))
+	for i := len(entries) - 1; i >= 0; i-- {
+		chrono = append(chrono, entries[i])
+	}
+
+	follow := replace || m.viewport.AtBottom()
+	if replace {
+		m.entries = chrono
+	} else {
+		for _, e := range chrono {
+			if e.ID > m.lastID {
+				m.entries = append(m.entries, e)
+			}
+		}
+		if len(m.entries) > logViewLimit {
+			m.entries = m.entries[len(m.entries)-logViewLimit:]
+		}
+	}
+	if len(m.entries) > 0 {
+		m.lastID = m.entries[len(m.entries)-1].ID
+	}
+
+	m.refresh()
+	if follow {
+		m.viewport.GotoBottom()
+	}
+}
+
+// visible returns the entries matching the substring filter
+func (m LogsModel) visible() []state.LogEntry {
+	needle := strings.ToLower(strings.TrimSpace(m.filterInput.Value()))
+	if needle == "" {
+		return m.entries
+	}
+
+	var out []state.LogEntry
+	for _, e := range m.entries {
+		if strings.Contains(strings.ToLower(e.Message), needle) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func (m *LogsModel) refresh() {
+	entries := m.visible()
+	if len(entries) == 0 {
+		m.viewport.SetContent(HelpStyle.Render("No matching log entries"))
+		return
+	}
+
+	lines := make([]string, len(entries))
+	for i, e := range entries {
+		lines[i] = fmt.Sprintf("%s %s %s",
+			HelpStyle.Render(e.Timestamp.Local().Format("Jan 2 15:04:05")),
+			logLevelStyle(e.Level).Render(fmt.Sprintf("%-5s", e.Level)),
+			InputStyle.Render(e.Message),
+		)
+	}
+	m.viewport.SetContent(strings.Join(lines, "\n"))
+}
+
+// queryLevels expands the minimum level into the levels to fetch
+func (m LogsModel) queryLevels() []string {
+	if m.minLevel == 0 {
+		// No filter, so entries with nonstandard levels still show
+		return nil
+	}
+
+	var levels []string
+	for _, level := range logLevels[m.minLevel:] {
+		levels = append(levels, level)
+		if level == "warn" {
+			levels = append(levels, "warning")
+		}
+	}
+	return levels
+}
+
+func (m LogsModel) loadCmd(replace bool) tea.Cmd {
+	id, minLevel, stateDB, ctx, migrationID := m.id, m.minLevel, m.stateDB, m.ctx, m.migrationID
+	query := state.LogQuery{Levels: m.queryLevels(), Limit: logViewLimit}
+	return func() tea.Msg {
+		entries, err := stateDB.GetLogsFilteredContext(ctx, migrationID, query)
+		return logsLoadedMsg{id: id, minLevel: minLevel, entries: entries, replace: replace, err: err}
+	}
+}
+
+func (m LogsModel) tickCmd() tea.Cmd {
+	id := m.id
+	return tea.Tick(logPollInterval, func(time.Time) tea.Msg {
+		return logsTickMsg{id: id}
+	})
+}
@@ -0,0 +1,138 @@
+package tui
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/johnhorton/deploy-tunnel/internal/state"
+)
+
+// newLogsModel seeds a migration's logs and opens the viewer on them, loaded
+func newLogsModel(t *testing.T) (LogsModel, *state.DB) {
+	t.Helper()
+	db := newTestSession(t, nil).StateDB
+	if err := db.CreateMigration("m1", "vercel", "netlify", "example.com"); err != nil {
+		t.Fatal(err)
+	}
+	m1 := "m1"
+	for _, l := range []struct{ level, message string }{
+		{"debug", "cache hit"},
+		{"info", "deploy started"},
+		{"warn", "slow build"},
+		{"error", "deploy failed"},
+	} {
+		if err := db.Log(&m1, l.level, l.message, ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mig, err := db.GetMigration("m1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewLogsModel(db, context.Background(), *mig, 120, 40)
+	m, _ = m.Update(m.loadCmd(true)())
+	return m, db
+}
+
+// shown returns the messages the viewer currently lists, oldest first
+func shown(m LogsModel) []string {
+	var messages []string
+	for _, e := range m.visible() {
+		messages = append(messages, e.Message)
+	}
+	return messages
+}
+
+func TestLogsFilter(t *testing.T) {
+	m, _ := newLogsModel(t)
+	if got := shown(m); len(got) != 4 {
+		t.Fatalf("shown = %v, want all 4 entries", got)
+	}
+
+	for _, key := range []string{"/", "D", "e", "p", "l", "o", "y"} {
+		m, _ = m.Update(keyMsg(key))
+	}
+	want := []string{"deploy started", "deploy failed"}
+	if got := shown(m); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("filtered = %v, want %v", got, want)
+	}
+	view := ansi.Strip(m.View())
+	if strings.Contains(view, "slow build") || !strings.Contains(view, "deploy failed") {
+		t.Errorf("view doesn't follow the filter:\n%s", view)
+	}
+
+	// enter keeps the filter; esc while editing clears it
+	m, _ = m.Update(keyMsg("enter"))
+	if m.filtering || len(shown(m)) != 2 {
+		t.Errorf("after enter: filtering = %v, shown = %v", m.filtering, shown(m))
+	}
+	m, _ = m.Update(keyMsg("/"))
+	m, _ = m.Update(keyMsg("esc"))
+	if got := shown(m); len(got) != 4 {
+		t.Errorf("after clearing the filter, shown = %v, want all 4", got)
+	}
+}
+
+func TestLogsLevelCycle(t *testing.T) {
+	m, _ := newLogsModel(t)
+
+	tests := []struct {
+		level string
+		want  []string
+	}{
+		{"info", []string{"deploy started", "slow build", "deploy failed"}},
+		{"warn", []string{"slow build", "deploy failed"}},
+		{"error", []string{"deploy failed"}},
+		{"debug", []string{"cache hit", "deploy started", "slow build", "deploy failed"}},
+	}
+	for _, tt := range tests {
+		var cmd tea.Cmd
+		m, cmd = m.Update(keyMsg("l"))
+		if logLevels[m.minLevel] != tt.level {
+			t.Fatalf("level = %s, want %s", logLevels[m.minLevel], tt.level)
+		}
+		m, _ = m.Update(cmd())
+		if got := shown(m); strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("at %s, shown = %v, want %v", tt.level, got, tt.want)
+		}
+	}
+}
+
+func TestLogsTail(t *testing.T) {
+	m, db := newLogsModel(t)
+	stale := m.loadCmd(false)
+
+	m1 := "m1"
+	if err := db.Log(&m1, "info", "dns updated", ""); err != nil {
+		t.Fatal(err)
+	}
+	m, _ = m.Update(m.loadCmd(false)())
+	want := []string{"cache hit", "deploy started", "slow build", "deploy failed", "dns updated"}
+	if got := shown(m); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("after polling, shown = %v, want %v", got, want)
+	}
+
+	// A poll started before the level changed is dropped
+	m, _ = m.Update(keyMsg("l"))
+	m, _ = m.Update(stale())
+	if got := len(shown(m)); got != 5 {
+		t.Errorf("a stale poll changed the entries: %d shown", got)
+	}
+}
+
+func TestLogsClose(t *testing.T) {
+	m, _ := newLogsModel(t)
+	for _, key := range []string{"q", "esc"} {
+		_, cmd := m.Update(keyMsg(key))
+		if cmd == nil {
+			t.Fatalf("%s returned no command", key)
+		}
+		if _, ok := cmd().(closeLogsMsg); !ok {
+			t.Errorf("%s didn't close the log viewer", key)
+		}
+	}
+}
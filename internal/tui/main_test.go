@@ -0,0 +1,94 @@
+package tui
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/johnhorton/deploy-tunnel/internal/bridge"
+	"github.com/johnhorton/deploy-tunnel/internal/keychain"
+	"github.com/johnhorton/deploy-tunnel/internal/state"
+)
+
+// TestMain keeps credentials in a throwaway encrypted file instead of the
+// system keychain
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "dt-tui-test")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv(keychain.EnvBackend, keychain.BackendFile)
+	os.Setenv(keychain.EnvKey, "test")
+	keychain.SetDir(dir)
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// newTestSession returns a session over a fresh on-disk state database
+func newTestSession(t *testing.T, br *bridge.Bridge) *Session {
+	t.Helper()
+	db, err := state.OpenWithKey(t.TempDir(), make([]byte, 32))
+	if err != nil {
+		t.Fatalf("failed to open state: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	s := NewSession(context.Background(), db, br)
+	t.Cleanup(s.Cancel)
+	return s
+}
+
+// keyMsg builds the key press bubbletea reports for key
+func keyMsg(key string) tea.KeyMsg {
+	switch key {
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "esc":
+		return tea.KeyMsg{Type: tea.KeyEsc}
+	case "up":
+		return tea.KeyMsg{Type: tea.KeyUp}
+	case "down":
+		return tea.KeyMsg{Type: tea.KeyDown}
+	case "tab":
+		return tea.KeyMsg{Type: tea.KeyTab}
+	case "backspace":
+		return tea.KeyMsg{Type: tea.KeyBackspace}
+	case " ":
+		return tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+	case "ctrl+c":
+		return tea.KeyMsg{Type: tea.KeyCtrlC}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+}
+
+// press sends each key to m in turn and returns the updated model and the
+// last command
+func press[M tea.Model](t *testing.T, m M, keys ...string) (M, tea.Cmd) {
+	t.Helper()
+	var cmd tea.Cmd
+	for _, key := range keys {
+		var updated tea.Model
+		updated, cmd = m.Update(keyMsg(key))
+		m = updated.(M)
+	}
+	return m, cmd
+}
+
+// sized delivers a window size to m, as bubbletea does on start
+func sized[M tea.Model](t *testing.T, m M) M {
+	t.Helper()
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	return updated.(M)
+}
+
+// isQuit reports whether cmd quits the program
+func isQuit(cmd tea.Cmd) bool {
+	if cmd == nil {
+		return false
+	}
+	_, ok := cmd().(tea.QuitMsg)
+	return ok
+}
@@ -0,0 +1,336 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/johnhorton/deploy-tunnel/internal/bridge"
+	"github.com/johnhorton/deploy-tunnel/internal/state"
+)
+
+type listStep int
+
+const (
+	listStepBrowse listStep = iota
+	listStepLoading
+	listStepDetail
+	listStepLogs
+)
+
+// detailLogLimit caps how many recent log lines the detail view shows
+const detailLogLimit = 10
+
+type ListModel struct {
+	step       listStep
+	list       list.Model
+	migrations []state.Migration
+	selected   *state.Migration
+	envVars    []state.EnvVar
+	dnsRecords []state.DnsRecord
+	logs       []state.LogEntry
+	logsView   LogsModel
+	err        error
+	width      int
+	height     int
+	stateDB    *state.DB
+	bridge     *bridge.Bridge
+	ctx        context.Context
+}
+
+type migrationItem struct {
+	migration state.Migration
+}
+
+func (i migrationItem) Title() string {
+	return fmt.Sprintf("%s  %s", i.migration.Domain, StatusStyle(i.migration.Status).Render(i.migration.Status))
+}
+func (i migrationItem) Description() string {
+	return fmt.Sprintf("%s → %s • %s", i.migration.Source, i.migration.Target, i.migration.CreatedAt.Local().Format("Jan 2 2006 15:04"))
+}
+func (i migrationItem) FilterValue() string { return i.migration.Domain }
+
+func NewListModel(s *Session) ListModel {
+	ctx, stateDB, br := s.Ctx, s.StateDB, s.Bridge
+
+	migrations, err := stateDB.ListMigrationsContext(ctx, "", false)
+
+	items := make([]list.Item, len(migrations))
+	for i, mig := range migrations {
+		items[i] = migrationItem{migration: mig}
+	}
+
+	l := list.New(items, list.NewDefaultDelegate(), 0, 0)
+	l.Title = "Migrations"
+	l.SetShowStatusBar(false)
+	l.SetFilteringEnabled(false)
+	l.Styles.Title = TitleStyle
+	l.Styles.HelpStyle = HelpStyle
+
+	return ListModel{
+		step:       listStepBrowse,
+		list:       l,
+		migrations: migrations,
+		err:        err,
+		stateDB:    stateDB,
+		bridge:     br,
+		ctx:        ctx,
+	}
+}
+
+func (m ListModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m ListModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if m.step == listStepLogs {
+		return m.updateLogs(msg)
+	}
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c":
+			return m, tea.Quit
+
+		case "q", "esc":
+			if m.step == listStepDetail {
+				m.step = listStepBrowse
+				return m, nil
+			}
+			return m, tea.Quit
+
+		case "l":
+			if m.step == listStepDetail {
+				m.logsView = NewLogsModel(m.stateDB, m.ctx, *m.selected, m.width, m.height)
+				m.step = listStepLogs
+				return m, m.logsView.Init()
+			}
+
+		case "enter":
+			if m.step == listStepBrowse {
+				if i, ok := m.list.SelectedItem().(migrationItem); ok {
+					mig := i.migration
+					m.selected = &mig
+					m.step = listStepLoading
+					return m, loadMigrationDetailCmd(m.stateDB, m.ctx, mig.ID)
+				}
+			}
+			return m, nil
+		}
+
+	case tea.WindowSizeMsg:
+		if m.width != 0 && msg.Width != m.width {
+			// Re-render the logo for the new width
+			ClearImageCache()
+		}
+		m.width = msg.Width
+		m.height = msg.Height
+		m.list.SetSize(msg.Width-4, msg.Height-10)
+		return m, nil
+
+	case migrationDetailMsg:
+		m.envVars = msg.envVars
+		m.dnsRecords = msg.dnsRecords
+		m.logs = msg.logs
+		m.err = msg.err
+		m.step = listStepDetail
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	if m.step == listStepBrowse {
+		m.list, cmd = m.list.Update(msg)
+	}
+	return m, cmd
+}
+
+// updateLogs routes messages to the embedded log viewer
+func (m ListModel) updateLogs(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" {
+			return m, tea.Quit
+		}
+
+	case closeLogsMsg:
+		m.step = listStepDetail
+		return m, nil
+
+	case tea.WindowSizeMsg:
+		if m.width != 0 && msg.Width != m.width {
+			ClearImageCache()
+		}
+		m.width = msg.Width
+		m.height = msg.Height
+		m.list.SetSize(msg.Width-4, msg.Height-10)
+	}
+
+	var cmd tea.Cmd
+	m.logsView, cmd = m.logsView.Update(msg)
+	return m, cmd
+}
+
+func (m ListModel) View() string {
+	if m.width == 0 {
+		return "Loading..."
+	}
+
+	header := Header()
+	var content string
+
+	switch m.step {
+	case listStepBrowse:
+		if len(m.migrations) == 0 {
+			content = BoxStyle.Render(HelpStyle.Render("No migrations yet. Start one from the dashboard!"))
+		} else {
+			content = m.list.View()
+		}
+		if m.err != nil {
+			content = ErrorStyle.Render(fmt.Sprintf("✗ Error: %s", m.err))
+		}
+
+	case listStepLoading:
+		content = HelpStyle.Render("Loading migration details...")
+
+	case listStepDetail:
+		content = m.detailView()
+
+	case listStepLogs:
+		content = m.logsView.View()
+	}
+
+	help := " Migrations | ↑↓ navigate • enter details • q back "
+	switch m.step {
+	case listStepDetail:
+		help = " Migration Details | l logs • q back to list "
+	case listStepLogs:
+		help = m.logsView.HelpText()
+	}
+	footer := StatusBarStyle.Render(help)
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		content,
+		"",
+		footer,
+	)
+}
+
+func (m ListModel) detailView() string {
+	if m.err != nil {
+		return ErrorStyle.Render(fmt.Sprintf("✗ Error: %s", m.err))
+	}
+
+	mig := m.selected
+	summary := BoxStyle.Render(lipgloss.JoinVertical(
+		lipgloss.Left,
+		PromptStyle.Render(mig.Domain),
+		"",
+		fmt.Sprintf("ID:      %s", InputStyle.Render(mig.ID)),
+		fmt.Sprintf("Source:  %s", InputStyle.Render(mig.Source)),
+		fmt.Sprintf("Target:  %s", InputStyle.Render(mig.Target)),
+		fmt.Sprintf("Status:  %s", StatusStyle(mig.Status).Render(mig.Status)),
+		fmt.Sprintf("Created: %s", InputStyle.Render(mig.CreatedAt.Local().Format("Jan 2 2006 15:04"))),
+	))
+
+	envLines := []string{PromptStyle.Render(fmt.Sprintf("Environment Variables (%d)", len(m.envVars)))}
+	if len(m.envVars) == 0 {
+		envLines = append(envLines, HelpStyle.Render("  none"))
+	}
+	for _, e := range m.envVars {
+		line := "  " + e.Key
+		if e.TargetKey != "" && e.TargetKey != e.Key {
+			line += " → " + e.TargetKey
+		}
+		envLines = append(envLines, InputStyle.Render(line))
+	}
+
+	dnsLines := []string{PromptStyle.Render(fmt.Sprintf("DNS Records (%d)", len(m.dnsRecords)))}
+	if len(m.dnsRecords) == 0 {
+		dnsLines = append(dnsLines, HelpStyle.Render("  none"))
+	}
+	for _, r := range m.dnsRecords {
+		dnsLines = append(dnsLines, InputStyle.Render(fmt.Sprintf("  %s %s → %s (ttl %d)", r.RecordType, r.RecordName, r.RecordValue, r.TTL)))
+	}
+
+	logLines := []string{PromptStyle.Render("Recent Logs")}
+	if len(m.logs) == 0 {
+		logLines = append(logLines, HelpStyle.Render("  none"))
+	}
+	for _, l := range m.logs {
+		logLines = append(logLines, fmt.Sprintf("  %s %s %s",
+			HelpStyle.Render(l.Timestamp.Local().Format("15:04:05")),
+			logLevelStyle(l.Level).Render(l.Level),
+			InputStyle.Render(l.Message),
+		))
+	}
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		summary,
+		"",
+		lipgloss.JoinVertical(lipgloss.Left, envLines...),
+		"",
+		lipgloss.JoinVertical(lipgloss.Left, dnsLines...),
+		"",
+		lipgloss.JoinVertical(lipgloss.Left, logLines...),
+	)
+}
+
+func logLevelStyle(level string) lipgloss.Style {
+	switch level {
+	case "error":
+		return RedStyle
+	case "warn", "warning":
+		return YellowStyle
+	case "debug":
+		return HelpStyle
+	default:
+		return GreenStyle
+	}
+}
+
+type migrationDetailMsg struct {
+	envVars    []state.EnvVar
+	dnsRecords []state.DnsRecord
+	logs       []state.LogEntry
+	err        error
+}
+
+func loadMigrationDetailCmd(stateDB *state.DB, ctx context.Context, migrationID string) tea.Cmd {
+	return func() tea.Msg {
+		envVars, err := stateDB.GetEnvVarsContext(ctx, migrationID)
+		if err != nil {
+			return migrationDetailMsg{err: err}
+		}
+		records, err := stateDB.GetDnsRecordsContext(ctx, migrationID)
+		if err != nil {
+			return migrationDetailMsg{err: err}
+		}
+		logs, err := stateDB.GetLogsContext(ctx, migrationID, detailLogLimit)
+		if err != nil {
+			return migrationDetailMsg{err: err}
+		}
+		return migrationDetailMsg{envVars: envVars, dnsRecords: records, logs: logs}
+	}
+}
+
+// RunListTUI runs the migration list TUI, then returns to the dashboard
+func RunListTUI(s *Session) error {
+	p := tea.NewProgram(
+		NewListModel(s),
+		tea.WithAltScreen(),
+		tea.WithContext(s.Ctx),
+	)
+
+	if _, err := p.Run(); err != nil || s.done() {
+		return runErr(s, err)
+	}
+
+	// Return to dashboard
+	return RunDashboardTUI(s)
+}
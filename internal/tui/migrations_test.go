@@ -0,0 +1,94 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDashboardViewMigrations(t *testing.T) {
+	m := sized(t, NewDashboardModel(newTestSession(t, nil)))
+	defer m.cancel()
+
+	m, cmd := press(t, m, "down", "enter")
+	if m.selected != "list" {
+		t.Fatalf("selected = %q, want %q", m.selected, "list")
+	}
+	if cmd == nil {
+		t.Fatal("choosing View Migrations returned no command")
+	}
+	msg := cmd()
+	if _, ok := msg.(switchToListMsg); !ok {
+		t.Fatalf("command returned %T, want switchToListMsg", msg)
+	}
+
+	// The dashboard exits so RunDashboardTUI can start the list
+	_, cmd = m.Update(msg)
+	if !isQuit(cmd) {
+		t.Error("switchToListMsg didn't quit the dashboard")
+	}
+}
+
+func TestListModelDetail(t *testing.T) {
+	s := newTestSession(t, nil)
+	db := s.StateDB
+	if err := db.CreateMigration("m1", "vercel", "netlify", "example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.SaveEnvVar("m1", "API_KEY", "sk_secret", "NEW_API_KEY", nil); err != nil {
+		t.Fatal(err)
+	}
+	m1 := "m1"
+	if err := db.Log(&m1, "info", "synced env", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	m := sized(t, NewListModel(s))
+	if view := m.View(); !strings.Contains(view, "example.com") {
+		t.Errorf("list view is missing the migration:\n%s", view)
+	}
+
+	m, cmd := press(t, m, "enter")
+	if m.step != listStepLoading {
+		t.Fatalf("after enter, step = %v, want %v", m.step, listStepLoading)
+	}
+	if m.selected == nil || m.selected.ID != "m1" {
+		t.Fatalf("selected = %v, want m1", m.selected)
+	}
+
+	updated, _ := m.Update(cmd())
+	m = updated.(ListModel)
+	if m.step != listStepDetail {
+		t.Fatalf("after loading, step = %v, want %v (err %v)", m.step, listStepDetail, m.err)
+	}
+	view := m.View()
+	for _, want := range []string{"API_KEY → NEW_API_KEY", "synced env", "DNS Records (0)"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("detail view is missing %q:\n%s", want, view)
+		}
+	}
+	if strings.Contains(view, "sk_secret") {
+		t.Error("detail view shows an env var value")
+	}
+
+	m, cmd = press(t, m, "q")
+	if m.step != listStepBrowse || cmd != nil {
+		t.Errorf("q from details: step = %v, want %v without quitting", m.step, listStepBrowse)
+	}
+	_, cmd = press(t, m, "q")
+	if !isQuit(cmd) {
+		t.Error("q from the list didn't return to the dashboard")
+	}
+}
+
+func TestListModelEmpty(t *testing.T) {
+	m := sized(t, NewListModel(newTestSession(t, nil)))
+	if view := m.View(); !strings.Contains(view, "No migrations yet") {
+		t.Errorf("empty list view = %q, want the no migrations hint", view)
+	}
+
+	// Nothing to select, so enter stays put
+	m, cmd := press(t, m, "enter")
+	if m.step != listStepBrowse || cmd != nil {
+		t.Errorf("enter on an empty list: step = %v, cmd = %v", m.step, cmd != nil)
+	}
+}
@@ -0,0 +1,43 @@
+package tui
+
+import (
+	"context"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/johnhorton/deploy-tunnel/internal/bridge"
+)
+
+type bridgeProgressMsg struct {
+	// ch identifies the call, so events from an earlier one are ignored
+	ch       chan bridge.Progress
+	progress bridge.Progress
+}
+
+// newBridgeProgressCh makes the channel for one call's events, with room for
+// every wait and retry of a call
+func newBridgeProgressCh() chan bridge.Progress {
+	return make(chan bridge.Progress, 8)
+}
+
+// withBridgeProgress returns ctx reporting the retries and rate limit waits
+// of calls made with it to ch. Events are dropped rather than stalling the
+// call if the model falls behind.
+func withBridgeProgress(ctx context.Context, ch chan bridge.Progress) context.Context {
+	return bridge.WithProgress(ctx, func(p bridge.Progress) {
+		select {
+		case ch <- p:
+		default:
+		}
+	})
+}
+
+// waitForBridgeProgress delivers the next retry event; it yields nothing once the call closes the channel
+func waitForBridgeProgress(ch chan bridge.Progress) tea.Cmd {
+	return func() tea.Msg {
+		progress, ok := <-ch
+		if !ok {
+			return nil
+		}
+		return bridgeProgressMsg{ch: ch, progress: progress}
+	}
+}
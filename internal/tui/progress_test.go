@@ -0,0 +1,62 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/johnhorton/deploy-tunnel/internal/bridge"
+)
+
+func TestWaitForBridgeProgress(t *testing.T) {
+	ch := newBridgeProgressCh()
+	ch <- bridge.Progress{Kind: bridge.ProgressRetrying, Attempt: 1, MaxAttempts: 3}
+	close(ch)
+
+	msg, ok := waitForBridgeProgress(ch)().(bridgeProgressMsg)
+	if !ok || msg.ch != ch || msg.progress.Kind != bridge.ProgressRetrying {
+		t.Fatalf("first message = %+v, want the retry event", msg)
+	}
+	if msg := waitForBridgeProgress(ch)(); msg != nil {
+		t.Errorf("message after close = %+v, want nil", msg)
+	}
+}
+
+func TestAuthShowsBridgeProgress(t *testing.T) {
+	m := sized(t, NewAuthModel(newTestSession(t, nil)))
+	m.step = authStepVerifying
+	m.progressCh = newBridgeProgressCh()
+	m.status.Start("Verifying credentials...")
+
+	update := func(msg bridgeProgressMsg) tea.Cmd {
+		t.Helper()
+		updated, cmd := m.Update(msg)
+		m = updated.(AuthModel)
+		return cmd
+	}
+
+	waiting := bridge.Progress{Kind: bridge.ProgressWaiting, Wait: 2 * time.Second}
+	if cmd := update(bridgeProgressMsg{ch: m.progressCh, progress: waiting}); cmd == nil {
+		t.Error("a progress event didn't wait for the next one")
+	}
+	if view := ansi.Strip(m.View()); !strings.Contains(view, "waiting 2s for rate limit...") {
+		t.Errorf("view doesn't show the wait:\n%s", view)
+	}
+
+	retrying := bridge.Progress{Kind: bridge.ProgressRetrying, Attempt: 1, MaxAttempts: 3}
+	update(bridgeProgressMsg{ch: m.progressCh, progress: retrying})
+	view := ansi.Strip(m.View())
+	if !strings.Contains(view, "retrying (1/3)...") || strings.Contains(view, "waiting") {
+		t.Errorf("view doesn't replace the wait with the retry:\n%s", view)
+	}
+
+	// Events from an earlier call are dropped
+	if cmd := update(bridgeProgressMsg{ch: newBridgeProgressCh(), progress: waiting}); cmd != nil {
+		t.Error("a stale progress event was waited on")
+	}
+	if view := ansi.Strip(m.View()); strings.Contains(view, "waiting") {
+		t.Errorf("view shows a stale event:\n%s", view)
+	}
+}
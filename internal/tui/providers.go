@@ -0,0 +1,171 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/johnhorton/deploy-tunnel/internal/bridge"
+	"github.com/johnhorton/deploy-tunnel/internal/keychain"
+)
+
+// providerHealthMsg carries the per-provider capability results
+type providerHealthMsg struct {
+	results []bridge.ProviderCapabilities
+}
+
+// fetchProviderHealthCmd queries every provider; individual failures are
+// kept in the results so the rest of the view can still render
+func fetchProviderHealthCmd(br *bridge.Bridge, ctx context.Context) tea.Cmd {
+	return func() tea.Msg {
+		return providerHealthMsg{
+			results: bridge.InOrder(bridge.AllProviders, br.CapabilitiesAll(ctx, bridge.AllProviders)),
+		}
+	}
+}
+
+// unavailableProviders maps each provider whose adapter didn't respond to a
+// short reason
+func unavailableProviders(results []bridge.ProviderCapabilities) map[bridge.Provider]string {
+	unavailable := make(map[bridge.Provider]string)
+	for _, r := range results {
+		if !r.Available() {
+			unavailable[r.Provider] = errorSummary(r.Err)
+		}
+	}
+	return unavailable
+}
+
+// renderHealthPanel renders one line per provider, marking failed ones as
+// unavailable and authed ones as signed in
+func renderHealthPanel(results []bridge.ProviderCapabilities, authed map[string]bool) string {
+	lines := []string{PromptStyle.Render("Provider Health"), ""}
+
+	if results == nil {
+		lines = append(lines, HelpStyle.Render("Checking providers..."))
+		return BoxStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+	}
+
+	for _, r := range results {
+		if r.Available() {
+			line := fmt.Sprintf("%s %s",
+				GreenStyle.Render("✓ "+padCell(string(r.Provider), 12)),
+				InputStyle.Render(fmt.Sprintf("%s v%s", r.Capabilities.AdapterName, r.Capabilities.AdapterVersion)),
+			)
+			if authed[string(r.Provider)] {
+				line += " " + GreenStyle.Render("● signed in")
+			} else {
+				line += " " + HelpStyle.Render("○ signed out")
+			}
+			lines = append(lines, line)
+		} else {
+			lines = append(lines, fmt.Sprintf("%s %s",
+				RedStyle.Render("✗ "+padCell(string(r.Provider), 12)),
+				HelpStyle.Render("unavailable: "+errorSummary(r.Err)),
+			))
+		}
+	}
+
+	return BoxStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
+
+// renderCapabilityMatrix renders a provider × feature grid.
+// Providers that failed to respond get an "unavailable" row with the error inline.
+func renderCapabilityMatrix(results []bridge.ProviderCapabilities) string {
+	if results == nil {
+		return HelpStyle.Render("Checking providers...")
+	}
+
+	headers := []string{"Provider", "Auth", "DNS", "Preview", "Env", "Logs"}
+	widths := []int{12, 8, 5, 8, 5, 5}
+
+	var header []string
+	for i, h := range headers {
+		header = append(header, PromptStyle.Render(padCell(h, widths[i])))
+	}
+	lines := []string{strings.Join(header, " ")}
+
+	for _, r := range results {
+		name := padCell(string(r.Provider), widths[0])
+		if !r.Available() {
+			lines = append(lines, fmt.Sprintf("%s %s",
+				RedStyle.Render(name),
+				HelpStyle.Render("unavailable: "+errorSummary(r.Err)),
+			))
+			continue
+		}
+
+		f := r.Capabilities.Features
+		row := []string{
+			InputStyle.Render(name),
+			InputStyle.Render(padCell(r.Capabilities.AuthType, widths[1])),
+			featureCell(f.DNSManagement, widths[2]),
+			featureCell(f.PreviewDeployments, widths[3]),
+			featureCell(f.EnvVariables, widths[4]),
+			featureCell(f.BuildLogs, widths[5]),
+		}
+		lines = append(lines, strings.Join(row, " "))
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+func featureCell(supported bool, width int) string {
+	if supported {
+		return GreenStyle.Render(padCell("✓", width))
+	}
+	return HelpStyle.Render(padCell("–", width))
+}
+
+func padCell(s string, width int) string {
+	if n := lipgloss.Width(s); n < width {
+		return s + strings.Repeat(" ", width-n)
+	}
+	return s
+}
+
+// errorSummary keeps inline errors to a single short line
+func errorSummary(err error) string {
+	if err == nil {
+		return "no response"
+	}
+	if _, ok := bridge.MissingRuntime(err); ok {
+		return "Bun not installed (see https://bun.sh)"
+	}
+	msg := strings.SplitN(err.Error(), "\n", 2)[0]
+	// Cut by runes so a multi-byte character isn't split
+	if runes := []rune(msg); len(runes) > 60 {
+		msg = string(runes[:57]) + "..."
+	}
+	return msg
+}
+
+// withKeychainWarning adds a banner above footer when the system keychain
+// is unavailable, so users know credentials are going to the encrypted file
+func withKeychainWarning(footer string) string {
+	if keychain.BackendName() == keychain.BackendSystem {
+		return footer
+	}
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		YellowStyle.Render("⚠ System keychain unavailable: credentials are stored in an encrypted file in ~/.deploy-tunnel"),
+		footer,
+	)
+}
+
+// sourceDomainsMsg carries the domains listed on the source provider
+type sourceDomainsMsg struct {
+	provider bridge.Provider
+	domains  []bridge.Domain
+	err      error
+}
+
+// fetchSourceDomainsCmd lists the domains on provider with its stored token
+func fetchSourceDomainsCmd(br *bridge.Bridge, ctx context.Context, provider bridge.Provider, token string) tea.Cmd {
+	return func() tea.Msg {
+		domains, err := br.ListDomains(ctx, provider, token)
+		return sourceDomainsMsg{provider: provider, domains: domains, err: err}
+	}
+}
@@ -0,0 +1,98 @@
+package tui
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/johnhorton/deploy-tunnel/internal/bridge"
+)
+
+// partialHealth has netlify failing between two providers that respond
+func partialHealth() []bridge.ProviderCapabilities {
+	caps := func(name string) *bridge.CapabilitiesData {
+		return &bridge.CapabilitiesData{
+			AdapterName:    name,
+			AdapterVersion: "1.2.0",
+			AuthType:       "token",
+			Features:       bridge.Features{DNSManagement: true},
+		}
+	}
+	return []bridge.ProviderCapabilities{
+		{Provider: bridge.ProviderVercel, CapResult: bridge.CapResult{Capabilities: caps("vercel")}},
+		{Provider: bridge.ProviderNetlify, CapResult: bridge.CapResult{Err: errors.New("adapter crashed\nstack trace")}},
+		{Provider: bridge.ProviderCloudflare, CapResult: bridge.CapResult{Capabilities: caps("cloudflare")}},
+	}
+}
+
+// lineWith returns the first line of view that contains s
+func lineWith(view, s string) string {
+	for _, line := range strings.Split(view, "\n") {
+		if strings.Contains(line, s) {
+			return line
+		}
+	}
+	return ""
+}
+
+func TestRenderPartialFailures(t *testing.T) {
+	tests := []struct {
+		name string
+		view string
+	}{
+		{name: "health panel", view: renderHealthPanel(partialHealth(), map[string]bool{"vercel": true})},
+		{name: "capability matrix", view: renderCapabilityMatrix(partialHealth())},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			view := ansi.Strip(tt.view)
+
+			failed := lineWith(view, "netlify")
+			if !strings.Contains(failed, "unavailable: adapter crashed") {
+				t.Errorf("netlify line = %q, want it marked unavailable with the error", failed)
+			}
+			if strings.Contains(view, "stack trace") {
+				t.Error("the error's later lines were rendered inline")
+			}
+			for _, provider := range []string{"vercel", "cloudflare"} {
+				line := lineWith(view, provider)
+				if line == "" || strings.Contains(line, "unavailable") {
+					t.Errorf("%s line = %q, want it rendered as available", provider, line)
+				}
+			}
+		})
+	}
+}
+
+func TestDashboardRendersPartialHealth(t *testing.T) {
+	m := NewDashboardModel(newTestSession(t, nil))
+	defer m.cancel()
+
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 160, Height: 50})
+	updated, _ = updated.Update(providerHealthMsg{results: partialHealth()})
+	view := ansi.Strip(updated.View())
+
+	if !strings.Contains(lineWith(view, "netlify"), "unavailable") {
+		t.Error("dashboard doesn't mark netlify unavailable")
+	}
+	if !strings.Contains(view, "vercel v1.2.0") || !strings.Contains(view, "cloudflare v1.2.0") {
+		t.Errorf("dashboard dropped the providers that responded:\n%s", view)
+	}
+}
+
+func TestKeychainWarning(t *testing.T) {
+	// TestMain forces the encrypted file backend
+	m := sized(t, NewDashboardModel(newTestSession(t, nil)))
+	defer m.cancel()
+
+	if view := ansi.Strip(m.View()); !strings.Contains(view, "System keychain unavailable") {
+		t.Errorf("dashboard doesn't warn about the file backend:\n%s", view)
+	}
+	lines := strings.Split(ansi.Strip(withKeychainWarning("footer")), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], "encrypted file") || strings.TrimSpace(lines[1]) != "footer" {
+		t.Errorf("withKeychainWarning() = %q, want the warning above the footer", lines)
+	}
+}
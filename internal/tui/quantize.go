@@ -0,0 +1,133 @@
+package tui
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"sort"
+
+	"github.com/nfnt/resize"
+)
+
+// sixelColors is the palette size for Sixel output; most terminals accept 256
+const sixelColors = 256
+
+// sixelCellWidth estimates a terminal cell's width in pixels, to turn a
+// column count into an image width
+const sixelCellWidth = 10
+
+// scaleToCols shrinks img to fit cols terminal columns, keeping its aspect
+// ratio. Images that already fit are returned as is.
+func scaleToCols(img image.Image, cols int) image.Image {
+	width := uint(cols * sixelCellWidth)
+	if width == 0 || img.Bounds().Dx() <= int(width) {
+		return img
+	}
+	return resize.Resize(width, 0, img, resize.Lanczos3)
+}
+
+// quantize maps img onto a palette of at most maxColors built by median cut,
+// dithering with Floyd-Steinberg
+func quantize(img image.Image, maxColors int) *image.Paletted {
+	bounds := img.Bounds()
+	pixels := make([]color.RGBA, 0, bounds.Dx()*bounds.Dy())
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			pixels = append(pixels, color.RGBAModel.Convert(img.At(x, y)).(color.RGBA))
+		}
+	}
+
+	paletted := image.NewPaletted(bounds, medianCut(pixels, maxColors))
+	draw.FloydSteinberg.Draw(paletted, bounds, img, bounds.Min)
+	return paletted
+}
+
+// colorBox is a set of pixels that median cut splits along its widest channel
+type colorBox struct {
+	pixels []color.RGBA
+}
+
+// widest returns the channel (0 red, 1 green, 2 blue) with the largest
+// spread in the box, and that spread
+func (b colorBox) widest() (channel int, spread uint8) {
+	lo := [3]uint8{255, 255, 255}
+	var hi [3]uint8
+	for _, p := range b.pixels {
+		for c, v := range [3]uint8{p.R, p.G, p.B} {
+			if v < lo[c] {
+				lo[c] = v
+			}
+			if v > hi[c] {
+				hi[c] = v
+			}
+		}
+	}
+	for c := range lo {
+		if hi[c]-lo[c] > spread {
+			channel, spread = c, hi[c]-lo[c]
+		}
+	}
+	return channel, spread
+}
+
+// average returns the mean color of the box
+func (b colorBox) average() color.RGBA {
+	var r, g, bl, a int
+	for _, p := range b.pixels {
+		r += int(p.R)
+		g += int(p.G)
+		bl += int(p.B)
+		a += int(p.A)
+	}
+	n := len(b.pixels)
+	return color.RGBA{uint8(r / n), uint8(g / n), uint8(bl / n), uint8(a / n)}
+}
+
+// medianCut builds a palette of at most maxColors by repeatedly splitting
+// the box with the widest color spread at its median
+func medianCut(pixels []color.RGBA, maxColors int) color.Palette {
+	if len(pixels) == 0 || maxColors <= 0 {
+		return color.Palette{color.Black}
+	}
+
+	boxes := []colorBox{{pixels: pixels}}
+	for len(boxes) < maxColors {
+		split, channel := -1, 0
+		var widest uint8
+		for i, box := range boxes {
+			if len(box.pixels) < 2 {
+				continue
+			}
+			if c, spread := box.widest(); spread > widest {
+				split, channel, widest = i, c, spread
+			}
+		}
+		if split < 0 {
+			break // every box is a single color
+		}
+
+		box := boxes[split].pixels
+		sort.Slice(box, func(i, j int) bool {
+			return channelOf(box[i], channel) < channelOf(box[j], channel)
+		})
+		mid := len(box) / 2
+		boxes[split] = colorBox{pixels: box[:mid]}
+		boxes = append(boxes, colorBox{pixels: box[mid:]})
+	}
+
+	palette := make(color.Palette, len(boxes))
+	for i, box := range boxes {
+		palette[i] = box.average()
+	}
+	return palette
+}
+
+func channelOf(p color.RGBA, channel int) uint8 {
+	switch channel {
+	case 0:
+		return p.R
+	case 1:
+		return p.G
+	}
+	return p.B
+}
@@ -0,0 +1,83 @@
+package tui
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+// quadrants returns a 4x4 image with a solid color in each quadrant
+func quadrants(colors [4]color.RGBA) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			img.SetRGBA(x, y, colors[y/2*2+x/2])
+		}
+	}
+	return img
+}
+
+func TestMedianCut(t *testing.T) {
+	red, green, blue := color.RGBA{255, 0, 0, 255}, color.RGBA{0, 255, 0, 255}, color.RGBA{0, 0, 255, 255}
+
+	tests := []struct {
+		name      string
+		pixels    []color.RGBA
+		maxColors int
+		wantLen   int
+	}{
+		{name: "no pixels", maxColors: 4, wantLen: 1},
+		{name: "one color", pixels: []color.RGBA{red, red, red}, maxColors: 4, wantLen: 1},
+		{name: "fewer colors than the palette", pixels: []color.RGBA{red, green, blue, red}, maxColors: 256, wantLen: 3},
+		{name: "capped", pixels: gradient(1000), maxColors: 16, wantLen: 16},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := medianCut(tt.pixels, tt.maxColors); len(got) != tt.wantLen {
+				t.Errorf("medianCut() has %d colors, want %d", len(got), tt.wantLen)
+			}
+		})
+	}
+}
+
+// gradient returns n pixels of distinct colors
+func gradient(n int) []color.RGBA {
+	pixels := make([]color.RGBA, n)
+	for i := range pixels {
+		pixels[i] = color.RGBA{uint8(i), uint8(i / 4), uint8(255 - i%256), 255}
+	}
+	return pixels
+}
+
+func TestQuantizeKeepsFewColorsExact(t *testing.T) {
+	colors := [4]color.RGBA{{255, 0, 0, 255}, {0, 255, 0, 255}, {0, 0, 255, 255}, {255, 255, 255, 255}}
+	img := quadrants(colors)
+
+	paletted := quantize(img, sixelColors)
+	if paletted.Bounds() != img.Bounds() {
+		t.Fatalf("quantized bounds = %v, want %v", paletted.Bounds(), img.Bounds())
+	}
+	if len(paletted.Palette) != 4 {
+		t.Errorf("palette has %d colors, want the image's 4", len(paletted.Palette))
+	}
+	// With every color in the palette there's no error to dither
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 4; x++ {
+			if got, want := color.RGBAModel.Convert(paletted.At(x, y)), colors[y/2*2+x/2]; got != want {
+				t.Errorf("pixel (%d,%d) = %v, want %v", x, y, got, want)
+			}
+		}
+	}
+}
+
+func TestQuantizeCapsPalette(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 40, 25))
+	for i, p := range gradient(1000) {
+		img.SetRGBA(i%40, i/40, p)
+	}
+
+	if got := quantize(img, 8); len(got.Palette) != 8 {
+		t.Errorf("palette has %d colors, want 8", len(got.Palette))
+	}
+}
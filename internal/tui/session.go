@@ -0,0 +1,94 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/johnhorton/deploy-tunnel/internal/bridge"
+	"github.com/johnhorton/deploy-tunnel/internal/keychain"
+	"github.com/johnhorton/deploy-tunnel/internal/state"
+)
+
+// Session is shared by every TUI in a chain (dashboard → auth → dashboard
+// ...), so they run under one cancelable context and see each other's
+// changes, such as providers authenticated a screen ago
+type Session struct {
+	// Ctx is canceled when the session ends; each model's adapter calls and
+	// queries run under it
+	Ctx     context.Context
+	Cancel  context.CancelFunc
+	StateDB *state.DB
+	Bridge  *bridge.Bridge
+
+	mu sync.Mutex
+	// authed caches keychain.List; nil until first read
+	authed []string
+	// undo holds the session's reversible actions, newest last
+	undo []undoAction
+	// now is replaceable so the undo window can be tested
+	now func() time.Time
+}
+
+// NewSession starts a session under ctx. Call Cancel when the TUIs are done.
+func NewSession(ctx context.Context, stateDB *state.DB, br *bridge.Bridge) *Session {
+	ctx, cancel := context.WithCancel(ctx)
+	return &Session{
+		Ctx:     ctx,
+		Cancel:  cancel,
+		StateDB: stateDB,
+		Bridge:  br,
+		now:     time.Now,
+	}
+}
+
+// Authed returns the providers with stored credentials, reading the
+// keychain only the first time
+func (s *Session) Authed() []string {
+	s.mu.Lock()
+	cached := s.authed
+	s.mu.Unlock()
+	if cached != nil {
+		return cached
+	}
+	return s.RefreshAuthed()
+}
+
+// RefreshAuthed re-reads the keychain after credentials were added or
+// removed, so later screens show the change
+func (s *Session) RefreshAuthed() []string {
+	authed, _ := keychain.List()
+	if authed == nil {
+		authed = []string{}
+	}
+	s.mu.Lock()
+	s.authed = authed
+	s.mu.Unlock()
+	return authed
+}
+
+// authedSet is Authed as a lookup
+func (s *Session) authedSet() map[string]bool {
+	set := make(map[string]bool)
+	for _, p := range s.Authed() {
+		set[p] = true
+	}
+	return set
+}
+
+// runErr is what a TUI runner returns once its program exits: a program
+// stopped because the session was canceled is a clean exit, not an error
+func runErr(s *Session, err error) error {
+	if err != nil && s.done() && errors.Is(err, tea.ErrProgramKilled) {
+		return nil
+	}
+	return err
+}
+
+// done reports whether the session was canceled, e.g. by ctrl+c from the
+// shell, so the chain should stop instead of opening the next screen
+func (s *Session) done() bool {
+	return s.Ctx.Err() != nil
+}
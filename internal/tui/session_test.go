@@ -0,0 +1,59 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestSessionAuthedIsShared(t *testing.T) {
+	s := newTestSession(t, nil)
+	if got := s.Authed(); got == nil || len(got) != 0 {
+		t.Fatalf("Authed() = %#v with nothing stored, want an empty list", got)
+	}
+
+	storeCredential(t, "vercel", "tok_session")
+	if got := s.Authed(); len(got) != 0 {
+		t.Errorf("Authed() = %v, want the cached empty list until a refresh", got)
+	}
+	s.RefreshAuthed()
+
+	storeCredential(t, "netlify", "tok_session")
+	if got := strings.Join(s.Authed(), ","); got != "vercel" {
+		t.Errorf("Authed() = %s, want the list cached at the last refresh", got)
+	}
+	if got := strings.Join(s.RefreshAuthed(), ","); got != "vercel,netlify" {
+		t.Errorf("RefreshAuthed() = %s, want vercel,netlify", got)
+	}
+	if !s.authedSet()["netlify"] {
+		t.Error("authedSet() is missing netlify after the refresh")
+	}
+}
+
+func TestSessionCancel(t *testing.T) {
+	parent, cancelParent := context.WithCancel(context.Background())
+	defer cancelParent()
+	s := NewSession(parent, nil, nil)
+	defer s.Cancel()
+
+	killed := tea.ErrProgramKilled
+	other := errors.New("terminal gone")
+	if err := runErr(s, killed); !errors.Is(err, killed) {
+		t.Errorf("runErr() before cancel = %v, want the kill reported", err)
+	}
+
+	// ctrl+c from the shell cancels the parent, ending every screen
+	cancelParent()
+	if !s.done() {
+		t.Fatal("done() = false after the parent was canceled")
+	}
+	if err := runErr(s, killed); err != nil {
+		t.Errorf("runErr() after cancel = %v, want a clean exit", err)
+	}
+	if err := runErr(s, other); !errors.Is(err, other) {
+		t.Errorf("runErr() = %v, want other errors kept", err)
+	}
+}
@@ -0,0 +1,93 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// statusSpinner is a spinner with a status message, shared by the TUI
+// models. It only ticks while started, and can show how long it has run.
+type statusSpinner struct {
+	spinner spinner.Model
+	message string
+	// detail is shown under the message, e.g. a retry in progress
+	detail      string
+	active      bool
+	showElapsed bool
+	started     time.Time
+	// now is replaceable so elapsed time can be pinned
+	now func() time.Time
+}
+
+// newStatusSpinner creates a stopped spinner; showElapsed appends the time
+// since Start to the message
+func newStatusSpinner(showElapsed bool) statusSpinner {
+	s := spinner.New()
+	s.Spinner = spinner.Dot
+	s.Style = lipgloss.NewStyle().Foreground(Coral)
+
+	return statusSpinner{spinner: s, showElapsed: showElapsed, now: time.Now}
+}
+
+// Start shows message and starts ticking; the returned command must be run
+func (s *statusSpinner) Start(message string) tea.Cmd {
+	s.message = message
+	s.detail = ""
+	s.started = s.now()
+	if s.active {
+		return nil
+	}
+	s.active = true
+	return s.spinner.Tick
+}
+
+// Stop hides the spinner; its pending tick is dropped
+func (s *statusSpinner) Stop() {
+	s.active = false
+}
+
+// SetMessage changes the message without restarting the timer
+func (s *statusSpinner) SetMessage(message string) {
+	s.message = message
+}
+
+// SetDetail sets the line shown under the message; "" hides it
+func (s *statusSpinner) SetDetail(detail string) {
+	s.detail = detail
+}
+
+// Active reports whether the spinner is running
+func (s statusSpinner) Active() bool {
+	return s.active
+}
+
+// Update advances the frame on the spinner's own ticks
+func (s statusSpinner) Update(msg tea.Msg) (statusSpinner, tea.Cmd) {
+	tick, ok := msg.(spinner.TickMsg)
+	if !ok || !s.active {
+		return s, nil
+	}
+	var cmd tea.Cmd
+	s.spinner, cmd = s.spinner.Update(tick)
+	return s, cmd
+}
+
+// View renders the frame and message, or nothing when stopped
+func (s statusSpinner) View() string {
+	if !s.active {
+		return ""
+	}
+	view := s.spinner.View() + " " + s.message
+	if s.showElapsed {
+		elapsed := s.now().Sub(s.started).Truncate(time.Second)
+		view += HelpStyle.Render(fmt.Sprintf(" (%s)", elapsed))
+	}
+	if s.detail != "" {
+		view += "\n  " + YellowStyle.Render(s.detail)
+	}
+	return view
+}
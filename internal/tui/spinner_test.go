@@ -0,0 +1,73 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestStatusSpinnerStartStop(t *testing.T) {
+	s := newStatusSpinner(false)
+	if s.Active() || s.View() != "" {
+		t.Fatalf("a new spinner is active = %v with view %q, want stopped and empty", s.Active(), s.View())
+	}
+
+	if cmd := s.Start("Fetching config..."); cmd == nil {
+		t.Fatal("Start() returned no tick")
+	}
+	if cmd := s.Start("Syncing env..."); cmd != nil {
+		t.Error("restarting a running spinner returned a second tick")
+	}
+	if view := ansi.Strip(s.View()); !strings.Contains(view, "Syncing env...") {
+		t.Errorf("View() = %q, want the latest message", view)
+	}
+
+	s.Stop()
+	if s.Active() || s.View() != "" {
+		t.Errorf("after Stop, active = %v with view %q, want stopped and empty", s.Active(), s.View())
+	}
+	if _, cmd := s.Update(spinner.TickMsg{}); cmd != nil {
+		t.Error("a stopped spinner kept ticking")
+	}
+}
+
+func TestStatusSpinnerElapsed(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	s := newStatusSpinner(true)
+	s.now = func() time.Time { return now }
+	s.Start("Deploying preview...")
+
+	now = now.Add(42*time.Second + 300*time.Millisecond)
+	if view := ansi.Strip(s.View()); !strings.Contains(view, "Deploying preview... (42s)") {
+		t.Errorf("View() = %q, want the elapsed time truncated to seconds", view)
+	}
+
+	// SetMessage keeps the timer running, Start resets it
+	s.SetMessage("Still deploying...")
+	if view := ansi.Strip(s.View()); !strings.Contains(view, "(42s)") {
+		t.Errorf("View() = %q after SetMessage, want the timer kept", view)
+	}
+	s.Start("Verifying...")
+	if view := ansi.Strip(s.View()); !strings.Contains(view, "(0s)") {
+		t.Errorf("View() = %q after Start, want the timer reset", view)
+	}
+}
+
+func TestStatusSpinnerDetail(t *testing.T) {
+	s := newStatusSpinner(false)
+	s.Start("Syncing env...")
+	s.SetDetail("Rate limited, retrying in 5s")
+
+	view := ansi.Strip(s.View())
+	if !strings.Contains(view, "Syncing env...\n  Rate limited, retrying in 5s") {
+		t.Errorf("View() = %q, want the detail on its own line", view)
+	}
+
+	s.Start("Deploying preview...")
+	if view := ansi.Strip(s.View()); strings.Contains(view, "Rate limited") {
+		t.Errorf("View() = %q, want Start to clear the detail", view)
+	}
+}
@@ -1,87 +1,136 @@
 package tui
 
-import "github.com/charmbracelet/lipgloss"
+import (
+	"strings"
 
+	"github.com/charmbracelet/lipgloss"
+	"github.com/johnhorton/deploy-tunnel/internal/version"
+	"github.com/johnhorton/deploy-tunnel/ui"
+)
+
+// Colors and styles, rebuilt from the active ui theme by applyTheme
 var (
 	// Colors
-	Coral     = lipgloss.Color("#ef9f76")
-	Gray      = lipgloss.Color("#6c6f85")
-	LightGray = lipgloss.Color("#a5adce")
-	Green     = lipgloss.Color("#a6d189")
-	Red       = lipgloss.Color("#e78284")
-	Yellow    = lipgloss.Color("#e5c890")
-	Blue      = lipgloss.Color("#8caaee")
+	Coral     lipgloss.Color
+	Gray      lipgloss.Color
+	LightGray lipgloss.Color
+	Green     lipgloss.Color
+	Red       lipgloss.Color
+	Yellow    lipgloss.Color
+	Blue      lipgloss.Color
 
 	// Color render styles
-	GreenStyle  = lipgloss.NewStyle().Foreground(Green)
-	RedStyle    = lipgloss.NewStyle().Foreground(Red)
-	YellowStyle = lipgloss.NewStyle().Foreground(Yellow)
+	GreenStyle  lipgloss.Style
+	RedStyle    lipgloss.Style
+	YellowStyle lipgloss.Style
 
 	// Base styles
+	BaseStyle           lipgloss.Style
+	TitleStyle          lipgloss.Style
+	SubtitleStyle       lipgloss.Style
+	SelectedItemStyle   lipgloss.Style
+	UnselectedItemStyle lipgloss.Style
+	PromptStyle         lipgloss.Style
+	InputStyle          lipgloss.Style
+	HelpStyle           lipgloss.Style
+	ErrorStyle          lipgloss.Style
+	SuccessStyle        lipgloss.Style
+	StatusBarStyle      lipgloss.Style
+	BoxStyle            lipgloss.Style
+	ProgressBarStyle    lipgloss.Style
+	ProgressEmptyStyle  lipgloss.Style
+)
+
+func init() {
+	ui.OnThemeChange(applyTheme)
+}
+
+// applyTheme builds the colors and styles from t
+func applyTheme(t ui.Theme) {
+	Coral = lipgloss.Color(t.Coral)
+	Gray = lipgloss.Color(t.Gray)
+	LightGray = lipgloss.Color(t.LightGray)
+	Green = lipgloss.Color(t.Green)
+	Red = lipgloss.Color(t.Red)
+	Yellow = lipgloss.Color(t.Yellow)
+	Blue = lipgloss.Color(t.Blue)
+
+	GreenStyle = lipgloss.NewStyle().Foreground(Green)
+	RedStyle = lipgloss.NewStyle().Foreground(Red)
+	YellowStyle = lipgloss.NewStyle().Foreground(Yellow)
+
 	BaseStyle = lipgloss.NewStyle().
-			Padding(1, 2)
+		Padding(1, 2)
 
 	TitleStyle = lipgloss.NewStyle().
-			Foreground(Coral).
-			Bold(true).
-			Padding(0, 1)
+		Foreground(Coral).
+		Bold(true).
+		Padding(0, 1)
 
 	SubtitleStyle = lipgloss.NewStyle().
-			Foreground(LightGray).
-			Italic(true)
+		Foreground(LightGray).
+		Italic(true)
 
 	SelectedItemStyle = lipgloss.NewStyle().
-				Foreground(Coral).
-				Bold(true).
-				PaddingLeft(2)
+		Foreground(Coral).
+		Bold(true).
+		PaddingLeft(2)
 
 	UnselectedItemStyle = lipgloss.NewStyle().
-				Foreground(LightGray).
-				PaddingLeft(2)
+		Foreground(LightGray).
+		PaddingLeft(2)
 
 	PromptStyle = lipgloss.NewStyle().
-			Foreground(Coral).
-			Bold(true)
+		Foreground(Coral).
+		Bold(true)
 
 	InputStyle = lipgloss.NewStyle().
-			Foreground(LightGray)
+		Foreground(LightGray)
 
 	HelpStyle = lipgloss.NewStyle().
-			Foreground(Gray).
-			Italic(true)
+		Foreground(Gray).
+		Italic(true)
 
 	ErrorStyle = lipgloss.NewStyle().
-			Foreground(Red).
-			Bold(true)
+		Foreground(Red).
+		Bold(true)
 
 	SuccessStyle = lipgloss.NewStyle().
-			Foreground(Green).
-			Bold(true)
+		Foreground(Green).
+		Bold(true)
 
 	StatusBarStyle = lipgloss.NewStyle().
-			Foreground(LightGray).
-			Background(Gray).
-			Padding(0, 1)
+		Foreground(LightGray).
+		Background(Gray).
+		Padding(0, 1)
 
 	BoxStyle = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(Coral).
-			Padding(1, 2)
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(Coral).
+		Padding(1, 2)
 
 	ProgressBarStyle = lipgloss.NewStyle().
-				Foreground(Coral)
+		Foreground(Coral)
 
 	ProgressEmptyStyle = lipgloss.NewStyle().
-				Foreground(Gray)
-)
+		Foreground(Gray)
+}
 
-// Renders the Deploy Tunnel header with optional image
+// Renders the Deploy Tunnel header with optional image, or nothing when
+// quiet
 func Header() string {
+	if ui.Quiet() {
+		return ""
+	}
+
 	// Try to display the logo image
 	imageDisplay := DisplayImage()
 
 	title := TitleStyle.Render("DEPLOY ▸ TUNNEL")
 	subtitle := SubtitleStyle.Render("migrate safely between hosts")
+	if note := LogoNote(); note != "" {
+		subtitle = lipgloss.JoinVertical(lipgloss.Left, subtitle, HelpStyle.Render(note))
+	}
 
 	// If we have an image, show it above the text
 	if imageDisplay != "" {
@@ -105,6 +154,18 @@ func Header() string {
 	)
 }
 
+// Returns the color style for a migration status
+func StatusStyle(status string) lipgloss.Style {
+	switch status {
+	case "completed":
+		return GreenStyle
+	case "failed":
+		return RedStyle
+	default:
+		return YellowStyle
+	}
+}
+
 // Renders a step indicator
 func StepIndicator(current, total int, description string) string {
 	steps := ""
@@ -128,3 +189,28 @@ func StepIndicator(current, total int, description string) string {
 		PromptStyle.Render(description),
 	)
 }
+
+// appTitle is the product name and build version shown in status bars
+func appTitle() string {
+	return "Deploy Tunnel " + version.Short()
+}
+
+// Renders a progress bar, or "" when total is zero
+func ProgressBar(current, total, width int) string {
+	if total <= 0 {
+		return ""
+	}
+	if width < 0 {
+		width = 0
+	}
+	if current > total {
+		current = total
+	}
+	if current < 0 {
+		current = 0
+	}
+
+	filled := width * current / total
+	return ProgressBarStyle.Render(strings.Repeat("█", filled)) +
+		ProgressEmptyStyle.Render(strings.Repeat("░", width-filled))
+}
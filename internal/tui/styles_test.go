@@ -0,0 +1,41 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestProgressBar(t *testing.T) {
+	tests := []struct {
+		name                  string
+		current, total, width int
+		filled, empty         int
+	}{
+		{name: "half", current: 5, total: 10, width: 10, filled: 5, empty: 5},
+		{name: "done", current: 10, total: 10, width: 4, filled: 4, empty: 0},
+		{name: "over total", current: 15, total: 10, width: 10, filled: 10, empty: 0},
+		{name: "negative current", current: -3, total: 10, width: 10, filled: 0, empty: 10},
+		{name: "zero width", current: 5, total: 10, width: 0, filled: 0, empty: 0},
+		{name: "negative width", current: 5, total: 10, width: -4, filled: 0, empty: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bar := ansi.Strip(ProgressBar(tt.current, tt.total, tt.width))
+			if got := strings.Count(bar, "█"); got != tt.filled {
+				t.Errorf("filled = %d, want %d", got, tt.filled)
+			}
+			if got := strings.Count(bar, "░"); got != tt.empty {
+				t.Errorf("empty = %d, want %d", got, tt.empty)
+			}
+		})
+	}
+}
+
+func TestProgressBarZeroTotal(t *testing.T) {
+	if bar := ProgressBar(3, 0, 10); bar != "" {
+		t.Errorf("ProgressBar with zero total = %q, want empty", bar)
+	}
+}
@@ -0,0 +1,78 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// undoWindow is how long after an action it can still be undone
+const undoWindow = 2 * time.Minute
+
+var errNothingToUndo = errors.New("nothing to undo")
+
+// undoAction is a reversible action taken in this session
+type undoAction struct {
+	// label names the action, e.g. "create migration"
+	label   string
+	at      time.Time
+	reverse func() error
+}
+
+// pushUndo records an action that reverse can take back. Only actions that
+// can be fully reversed belong here.
+func (s *Session) pushUndo(label string, reverse func() error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.undo = append(s.undo, undoAction{label: label, at: s.now(), reverse: reverse})
+}
+
+// lastUndo returns the label of the action Undo would reverse, and false if
+// there is none or it is older than undoWindow
+func (s *Session) lastUndo() (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.undo) == 0 {
+		return "", false
+	}
+	last := s.undo[len(s.undo)-1]
+	if s.now().Sub(last.at) > undoWindow {
+		return "", false
+	}
+	return last.label, true
+}
+
+// Undo reverses the most recent action and returns its label. Actions older
+// than undoWindow are dropped instead. A failed reversal stays on the stack
+// so it can be retried.
+func (s *Session) Undo() (string, error) {
+	s.mu.Lock()
+	if len(s.undo) == 0 {
+		s.mu.Unlock()
+		return "", errNothingToUndo
+	}
+	last := s.undo[len(s.undo)-1]
+	if s.now().Sub(last.at) > undoWindow {
+		s.undo = nil
+		s.mu.Unlock()
+		return "", fmt.Errorf("can no longer undo %s: undo is only available for %s", last.label, undoWindow)
+	}
+	s.mu.Unlock()
+
+	if err := last.reverse(); err != nil {
+		return "", fmt.Errorf("failed to undo %s: %w", last.label, err)
+	}
+
+	s.mu.Lock()
+	s.undo = s.undo[:len(s.undo)-1]
+	s.mu.Unlock()
+	return last.label, nil
+}
+
+// undoHint describes the u binding for a footer
+func (s *Session) undoHint() string {
+	if label, ok := s.lastUndo(); ok {
+		return "u undo " + label
+	}
+	return "undo unavailable"
+}
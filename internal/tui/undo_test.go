@@ -0,0 +1,88 @@
+package tui
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/johnhorton/deploy-tunnel/internal/bridge"
+	"github.com/johnhorton/deploy-tunnel/internal/state"
+)
+
+// confirmedInit returns an init flow on its confirm step, and its session
+func confirmedInit(t *testing.T) (InitModel, *Session) {
+	t.Helper()
+	db, err := state.OpenWithKey(t.TempDir(), make([]byte, 32))
+	if err != nil {
+		t.Fatalf("failed to open state: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	s := NewSession(context.Background(), db, nil)
+	t.Cleanup(s.Cancel)
+
+	m := NewInitModel(s)
+	m.step = stepConfirm
+	m.selectedSource = bridge.ProviderVercel
+	m.selectedTarget = bridge.ProviderNetlify
+	m.domain = "example.com"
+	return m, s
+}
+
+func pressKey(t *testing.T, m InitModel, key string) InitModel {
+	t.Helper()
+	msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+	if key == "enter" {
+		msg = tea.KeyMsg{Type: tea.KeyEnter}
+	}
+	updated, _ := m.Update(msg)
+	return updated.(InitModel)
+}
+
+func TestUndoCreateMigration(t *testing.T) {
+	m, s := confirmedInit(t)
+
+	m = pressKey(t, m, "enter")
+	if m.err != nil {
+		t.Fatalf("create failed: %v", m.err)
+	}
+	if created, _ := s.StateDB.GetMigration(m.migrationID); created == nil {
+		t.Fatal("migration was not created")
+	}
+
+	m = pressKey(t, m, "u")
+	if m.undoErr != nil {
+		t.Fatalf("undo failed: %v", m.undoErr)
+	}
+	if !m.undone {
+		t.Error("undone = false after u")
+	}
+	migration, err := s.StateDB.GetMigration(m.migrationID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if migration != nil {
+		t.Errorf("migration %s still exists after undo", m.migrationID)
+	}
+
+	if _, err := s.Undo(); err != errNothingToUndo {
+		t.Errorf("second Undo() = %v, want %v", err, errNothingToUndo)
+	}
+}
+
+func TestUndoCreateMigrationExpired(t *testing.T) {
+	m, s := confirmedInit(t)
+	m = pressKey(t, m, "enter")
+
+	created := time.Now()
+	s.now = func() time.Time { return created.Add(undoWindow + time.Second) }
+
+	m = pressKey(t, m, "u")
+	if m.undoErr == nil {
+		t.Fatal("undo succeeded after the undo window")
+	}
+	if migration, _ := s.StateDB.GetMigration(m.migrationID); migration == nil {
+		t.Error("migration was deleted after the undo window")
+	}
+}
@@ -0,0 +1,979 @@
+package tui
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/johnhorton/deploy-tunnel/internal/bridge"
+	"github.com/johnhorton/deploy-tunnel/internal/keychain"
+	"github.com/johnhorton/deploy-tunnel/internal/state"
+)
+
+type workflowPhase int
+
+const (
+	workflowPhaseSourceProject workflowPhase = iota
+	workflowPhaseTargetProject
+	workflowPhaseReady
+	workflowPhaseReviewEnv
+	workflowPhaseRunning
+	workflowPhaseFailed
+	workflowPhaseDone
+	workflowPhaseEnvDiff
+	workflowPhaseDnsRecord
+)
+
+const (
+	verifyTimeout = 15 * time.Second
+	// syncBarWidth is the width of the env sync progress bar
+	syncBarWidth = 40
+)
+
+// workflowBridge is the subset of bridge calls the migration workflow needs
+type workflowBridge interface {
+	Capabilities(ctx context.Context, provider bridge.Provider) (*bridge.CapabilitiesData, error)
+	FetchConfig(ctx context.Context, params bridge.FetchConfigParams) (*bridge.FetchConfigData, error)
+	SyncEnvBatched(ctx context.Context, params bridge.SyncEnvParams, batchSize int, onBatch func(bridge.SyncProgress)) (*bridge.SyncEnvData, error)
+	DeployPreview(ctx context.Context, params bridge.DeployPreviewParams) (*bridge.DeployPreviewData, error)
+	DnsUpdate(ctx context.Context, params bridge.DnsUpdateParams) (*bridge.DnsUpdateData, error)
+}
+
+var stepLabels = map[state.Step]string{
+	state.StepFetchConfig:   "Fetch source config",
+	state.StepSyncEnv:       "Sync environment variables",
+	state.StepDeployPreview: "Deploy preview",
+	state.StepDnsUpdate:     "Update DNS",
+	state.StepVerify:        "Verify deployment",
+	state.StepCutover:       "Cutover",
+}
+
+// stepRequirement is what a provider's adapter must support to run a step
+type stepRequirement struct {
+	// onSource is true when the step runs against the source provider
+	onSource bool
+	verb     string
+	// feature reports the capability flag, nil when the verb is enough
+	feature     func(bridge.Features) bool
+	featureName string
+}
+
+var stepRequirements = map[state.Step]stepRequirement{
+	state.StepFetchConfig: {onSource: true, verb: "fetch:config"},
+	state.StepSyncEnv: {
+		verb:        "sync:env",
+		feature:     func(f bridge.Features) bool { return f.EnvVariables },
+		featureName: "environment variables",
+	},
+	state.StepDeployPreview: {
+		verb:        "deploy:preview",
+		feature:     func(f bridge.Features) bool { return f.PreviewDeployments },
+		featureName: "preview deployments",
+	},
+	state.StepDnsUpdate: {
+		verb:        "dns:update",
+		feature:     func(f bridge.Features) bool { return f.DNSManagement },
+		featureName: "DNS",
+	},
+}
+
+// unsupportedSteps maps each step the adapters can't run to a note like
+// "Render adapter: DNS not supported". Verify needs the preview deployment,
+// so it's skipped with it. A nil caps means unknown, which gates nothing.
+func unsupportedSteps(source, target string, sourceCaps, targetCaps *bridge.CapabilitiesData) map[state.Step]string {
+	skipped := make(map[state.Step]string)
+	for _, step := range state.StepOrder {
+		req, ok := stepRequirements[step]
+		if !ok {
+			continue
+		}
+		provider, caps := target, targetCaps
+		if req.onSource {
+			provider, caps = source, sourceCaps
+		}
+		if caps == nil {
+			continue
+		}
+
+		name := adapterLabel(provider)
+		switch {
+		case len(caps.SupportedVerbs) > 0 && !containsString(caps.SupportedVerbs, req.verb):
+			skipped[step] = fmt.Sprintf("%s adapter: %s not supported", name, req.verb)
+		case req.feature != nil && !req.feature(caps.Features):
+			skipped[step] = fmt.Sprintf("%s adapter: %s not supported", name, req.featureName)
+		}
+	}
+	if _, ok := skipped[state.StepDeployPreview]; ok {
+		skipped[state.StepVerify] = "needs a preview deployment"
+	}
+	return skipped
+}
+
+// adapterLabel capitalizes a provider name for notes
+func adapterLabel(provider string) string {
+	if provider == "" {
+		return provider
+	}
+	return strings.ToUpper(provider[:1]) + provider[1:]
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// workflowProjects identifies the projects on each side; stored in the fetch_config checkpoint
+type workflowProjects struct {
+	SourceProjectID string `json:"source_project_id"`
+	TargetProjectID string `json:"target_project_id"`
+}
+
+type fetchConfigCheckpoint struct {
+	workflowProjects
+	ProjectName string `json:"project_name"`
+	EnvCount    int    `json:"env_count"`
+}
+
+type dnsUpdateCheckpoint struct {
+	RecordID      string  `json:"record_id"`
+	PreviousValue *string `json:"previous_value,omitempty"`
+}
+
+type MigrationModel struct {
+	phase        workflowPhase
+	migration    *state.Migration
+	projects     workflowProjects
+	projectInput textinput.Model
+	// dnsRecord is what the DNS step sets, entered before it runs: the
+	// domain is the zone apex, so there's no safe record to assume
+	dnsRecord   *bridge.DnsRecord
+	recordErr   error
+	checkpoints map[state.Step]json.RawMessage
+	// skipped holds steps the adapters can't run, with a note why
+	skipped    map[state.Step]string
+	capsLoaded bool
+	envReview  EnvModel
+	envDiff    EnvDiffModel
+	// diffReturn is the phase to go back to when the env diff closes
+	diffReturn   workflowPhase
+	syncCh       chan bridge.SyncProgress
+	syncProgress bridge.SyncProgress
+	current      state.Step
+	stepErr      error
+	status       statusSpinner
+	width        int
+	height       int
+	stateDB      *state.DB
+	bridge       workflowBridge
+	ctx          context.Context
+}
+
+func NewMigrationModel(s *Session, migration *state.Migration) MigrationModel {
+	projectInput := textinput.New()
+	projectInput.Placeholder = "project ID or name"
+	projectInput.Focus()
+	projectInput.CharLimit = 255
+	projectInput.Width = 50
+	projectInput.Prompt = PromptStyle.Render("► ")
+	projectInput.TextStyle = InputStyle
+
+	m := MigrationModel{
+		phase:        workflowPhaseSourceProject,
+		migration:    migration,
+		projectInput: projectInput,
+		checkpoints:  make(map[state.Step]json.RawMessage),
+		skipped:      make(map[state.Step]string),
+		status:       newStatusSpinner(true),
+		stateDB:      s.StateDB,
+		bridge:       s.Bridge,
+		ctx:          s.Ctx,
+	}
+
+	// Resume from checkpoints left by an earlier run
+	records, err := m.stateDB.GetStepsContext(m.ctx, migration.ID)
+	if err != nil {
+		m.stepErr = err
+		m.phase = workflowPhaseFailed
+		return m
+	}
+	for _, r := range records {
+		m.checkpoints[r.Step] = r.Data
+	}
+
+	if data, ok := m.checkpoints[state.StepFetchConfig]; ok {
+		var cp fetchConfigCheckpoint
+		if err := json.Unmarshal(data, &cp); err == nil {
+			m.projects = cp.workflowProjects
+			m.phase = workflowPhaseReady
+		}
+	}
+	m.current = m.nextStep()
+	if m.current == "" {
+		m.phase = workflowPhaseDone
+	}
+
+	return m
+}
+
+// nextStep returns the first step without a checkpoint that the adapters
+// support, or "" when all are done
+func (m MigrationModel) nextStep() state.Step {
+	for _, step := range state.StepOrder {
+		if _, done := m.checkpoints[step]; done {
+			continue
+		}
+		if _, skip := m.skipped[step]; skip {
+			continue
+		}
+		return step
+	}
+	return ""
+}
+
+// workflowCapsMsg carries both adapters' capabilities; a nil entry means
+// that adapter didn't answer
+type workflowCapsMsg struct {
+	source, target *bridge.CapabilitiesData
+}
+
+// fetchWorkflowCapsCmd asks the source and target adapters what they support
+func fetchWorkflowCapsCmd(br workflowBridge, ctx context.Context, source, target string) tea.Cmd {
+	return func() tea.Msg {
+		var msg workflowCapsMsg
+		msg.source, _ = br.Capabilities(ctx, bridge.Provider(source))
+		msg.target, _ = br.Capabilities(ctx, bridge.Provider(target))
+		return msg
+	}
+}
+
+func (m MigrationModel) Init() tea.Cmd {
+	return tea.Batch(
+		textinput.Blink,
+		fetchWorkflowCapsCmd(m.bridge, m.ctx, m.migration.Source, m.migration.Target),
+	)
+}
+
+// applyCapabilities skips the steps the adapters can't run
+func (m *MigrationModel) applyCapabilities(msg workflowCapsMsg) {
+	m.capsLoaded = true
+	m.skipped = unsupportedSteps(m.migration.Source, m.migration.Target, msg.source, msg.target)
+	if m.phase == workflowPhaseRunning || m.phase == workflowPhaseReviewEnv {
+		return
+	}
+	m.current = m.nextStep()
+	if m.current == "" {
+		m.phase = workflowPhaseDone
+	}
+}
+
+func (m MigrationModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if m.phase == workflowPhaseReviewEnv {
+		if cmd, handled := m.updateEnvReview(msg); handled {
+			return m, cmd
+		}
+	}
+	if m.phase == workflowPhaseEnvDiff {
+		if cmd, handled := m.updateEnvDiff(msg); handled {
+			return m, cmd
+		}
+	}
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c":
+			return m, tea.Quit
+
+		case "q":
+			if m.phase == workflowPhaseReady || m.phase == workflowPhaseFailed || m.phase == workflowPhaseDone {
+				return m, tea.Quit
+			}
+
+		case "enter":
+			return m.handleEnter()
+
+		case "esc":
+			if m.phase == workflowPhaseDnsRecord {
+				m.phase = workflowPhaseReady
+				return m, nil
+			}
+
+		case "d":
+			if m.canDiffEnv() {
+				return m.startEnvDiff()
+			}
+		}
+
+	case tea.WindowSizeMsg:
+		if m.width != 0 && msg.Width != m.width {
+			// Re-render the logo for the new width
+			ClearImageCache()
+		}
+		m.width = msg.Width
+		m.height = msg.Height
+		var cmd tea.Cmd
+		m.envReview, cmd = m.envReview.Update(msg)
+		m.envDiff, _ = m.envDiff.Update(msg)
+		return m, cmd
+
+	case spinner.TickMsg:
+		var cmd tea.Cmd
+		m.status, cmd = m.status.Update(msg)
+		return m, cmd
+
+	case workflowCapsMsg:
+		m.applyCapabilities(msg)
+		return m, nil
+
+	case stepDoneMsg:
+		m.status.Stop()
+		if msg.err != nil {
+			m.stepErr = msg.err
+			m.phase = workflowPhaseFailed
+			m.migration.Status = "failed"
+			return m, nil
+		}
+
+		m.checkpoints[msg.step] = msg.data
+		m.current = m.nextStep()
+		if m.current == "" {
+			m.phase = workflowPhaseDone
+			m.migration.Status = "completed"
+			return m, nil
+		}
+		if m.current == state.StepSyncEnv {
+			// Pause for the user to review what gets synced
+			return m.startEnvReview()
+		}
+		if m.current == state.StepDnsUpdate && m.dnsRecord == nil {
+			return m.startDnsRecord()
+		}
+		// Keep going until a step fails or the workflow finishes
+		cmd := m.startStep()
+		return m, cmd
+
+	case syncProgressMsg:
+		// Batches report cumulative totals, so never move the bar backwards
+		if msg.progress.Done() >= m.syncProgress.Done() {
+			m.syncProgress = msg.progress
+		}
+		return m, waitForSyncProgress(m.syncCh)
+	}
+
+	var cmd tea.Cmd
+	if m.phase == workflowPhaseSourceProject || m.phase == workflowPhaseTargetProject || m.phase == workflowPhaseDnsRecord {
+		m.projectInput, cmd = m.projectInput.Update(msg)
+	}
+	return m, cmd
+}
+
+func (m MigrationModel) handleEnter() (tea.Model, tea.Cmd) {
+	switch m.phase {
+	case workflowPhaseSourceProject:
+		if value := strings.TrimSpace(m.projectInput.Value()); value != "" {
+			m.projects.SourceProjectID = value
+			m.projectInput.Reset()
+			m.phase = workflowPhaseTargetProject
+		}
+
+	case workflowPhaseTargetProject:
+		if value := strings.TrimSpace(m.projectInput.Value()); value != "" {
+			m.projects.TargetProjectID = value
+			m.phase = workflowPhaseReady
+		}
+
+	case workflowPhaseReady, workflowPhaseFailed:
+		// Wait to learn which steps the adapters support
+		if m.current == "" || !m.capsLoaded {
+			return m, nil
+		}
+		if m.current == state.StepSyncEnv && m.phase == workflowPhaseReady {
+			return m.startEnvReview()
+		}
+		// A failed DNS update is retried with the record open for editing
+		if m.current == state.StepDnsUpdate && (m.dnsRecord == nil || m.phase == workflowPhaseFailed) {
+			return m.startDnsRecord()
+		}
+		cmd := m.startStep()
+		return m, cmd
+
+	case workflowPhaseDnsRecord:
+		record, err := bridge.ParseRecordSpec(strings.TrimSpace(m.projectInput.Value()))
+		if err != nil {
+			m.recordErr = err
+			return m, nil
+		}
+		m.dnsRecord = &record
+		m.recordErr = nil
+		cmd := m.startStep()
+		return m, cmd
+
+	case workflowPhaseDone:
+		return m, tea.Quit
+	}
+
+	return m, nil
+}
+
+// startStep runs the current step, listening for progress when it is the env sync
+func (m *MigrationModel) startStep() tea.Cmd {
+	m.stepErr = nil
+	m.phase = workflowPhaseRunning
+	m.migration.Status = "in_progress"
+	tick := m.status.Start(PromptStyle.Render(stepLabels[m.current]))
+
+	if m.current != state.StepSyncEnv {
+		m.syncCh = nil
+		return tea.Batch(tick, runStepCmd(*m, m.current))
+	}
+
+	m.syncCh = make(chan bridge.SyncProgress)
+	m.syncProgress = bridge.SyncProgress{}
+	return tea.Batch(tick, runStepCmd(*m, m.current), waitForSyncProgress(m.syncCh))
+}
+
+// startDnsRecord asks for the record the DNS step should set, starting from
+// the last one entered
+func (m MigrationModel) startDnsRecord() (tea.Model, tea.Cmd) {
+	m.projectInput.Reset()
+	m.projectInput.Placeholder = "TYPE:NAME:VALUE"
+	if m.dnsRecord != nil {
+		m.projectInput.SetValue(m.dnsRecord.Type + ":" + m.dnsRecord.Name + ":" + m.dnsRecord.Value)
+	}
+	m.recordErr = nil
+	m.phase = workflowPhaseDnsRecord
+	return m, textinput.Blink
+}
+
+// startEnvReview opens the env var review ahead of the sync step
+func (m MigrationModel) startEnvReview() (tea.Model, tea.Cmd) {
+	m.envReview = NewEnvModel(m.stateDB, m.ctx, m.migration.ID, m.width, m.height)
+	m.phase = workflowPhaseReviewEnv
+	return m, m.envReview.Init()
+}
+
+// updateEnvReview routes messages to the env review; handled is false for
+// messages the workflow itself should process
+func (m *MigrationModel) updateEnvReview(msg tea.Msg) (cmd tea.Cmd, handled bool) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" {
+			return tea.Quit, true
+		}
+
+	case tea.WindowSizeMsg, spinner.TickMsg:
+		return nil, false
+
+	case envReviewDoneMsg:
+		switch {
+		case msg.err != nil:
+			m.stepErr = msg.err
+			m.phase = workflowPhaseFailed
+			return nil, true
+		case !msg.confirmed:
+			m.phase = workflowPhaseReady
+			return nil, true
+		}
+		return m.startStep(), true
+	}
+
+	m.envReview, cmd = m.envReview.Update(msg)
+	return cmd, true
+}
+
+// canDiffEnv reports whether the env diff can be opened: both projects are
+// known, the capabilities are in, and no step is running
+func (m MigrationModel) canDiffEnv() bool {
+	if !m.capsLoaded || m.projects.SourceProjectID == "" || m.projects.TargetProjectID == "" {
+		return false
+	}
+	return m.phase == workflowPhaseReady || m.phase == workflowPhaseFailed || m.phase == workflowPhaseDone
+}
+
+// startEnvDiff opens the source/target env comparison
+func (m MigrationModel) startEnvDiff() (tea.Model, tea.Cmd) {
+	m.envDiff = NewEnvDiffModel(m.bridge, m.ctx, m.migration, m.projects, m.width, m.height)
+	m.diffReturn = m.phase
+	m.phase = workflowPhaseEnvDiff
+	return m, m.envDiff.Init()
+}
+
+// updateEnvDiff routes messages to the env diff; handled is false for
+// messages the workflow itself should process
+func (m *MigrationModel) updateEnvDiff(msg tea.Msg) (cmd tea.Cmd, handled bool) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" {
+			return tea.Quit, true
+		}
+
+	case tea.WindowSizeMsg, spinner.TickMsg, workflowCapsMsg:
+		return nil, false
+
+	case envDiffClosedMsg:
+		m.phase = m.diffReturn
+		return nil, true
+	}
+
+	m.envDiff, cmd = m.envDiff.Update(msg)
+	return cmd, true
+}
+
+func (m MigrationModel) View() string {
+	if m.width == 0 {
+		return "Loading..."
+	}
+
+	header := Header()
+
+	summary := fmt.Sprintf("%s  %s → %s  %s",
+		PromptStyle.Render(m.migration.Domain),
+		InputStyle.Render(m.migration.Source),
+		InputStyle.Render(m.migration.Target),
+		StatusStyle(m.migration.Status).Render(m.migration.Status),
+	)
+
+	var steps []string
+	for _, step := range state.StepOrder {
+		label := stepLabels[step]
+		_, done := m.checkpoints[step]
+		note, skipped := m.skipped[step]
+		switch {
+		case done:
+			steps = append(steps, SuccessStyle.Render("✓ "+label))
+		case skipped:
+			steps = append(steps, HelpStyle.Render("– "+label+" ("+note+")"))
+		case step == m.current && m.phase == workflowPhaseRunning:
+			steps = append(steps, m.status.View())
+		case step == m.current && m.phase == workflowPhaseFailed:
+			steps = append(steps, ErrorStyle.Render("✗ "+label))
+		default:
+			steps = append(steps, UnselectedItemStyle.Render("○ "+label))
+		}
+	}
+
+	var content string
+	switch m.phase {
+	case workflowPhaseSourceProject:
+		content = lipgloss.JoinVertical(
+			lipgloss.Left,
+			PromptStyle.Render(fmt.Sprintf("Source project on %s:", m.migration.Source)),
+			m.projectInput.View(),
+			"",
+			HelpStyle.Render("Press Enter to continue"),
+		)
+
+	case workflowPhaseTargetProject:
+		content = lipgloss.JoinVertical(
+			lipgloss.Left,
+			SuccessStyle.Render(fmt.Sprintf("✓ Source project: %s", m.projects.SourceProjectID)),
+			"",
+			PromptStyle.Render(fmt.Sprintf("Target project on %s:", m.migration.Target)),
+			m.projectInput.View(),
+			"",
+			HelpStyle.Render("Press Enter to continue"),
+		)
+
+	case workflowPhaseReady:
+		content = HelpStyle.Render(fmt.Sprintf("Press Enter to run: %s", stepLabels[m.current]))
+		if !m.capsLoaded {
+			content = HelpStyle.Render("Checking adapter capabilities...")
+		}
+
+	case workflowPhaseReviewEnv:
+		content = m.envReview.View()
+
+	case workflowPhaseRunning:
+		content = HelpStyle.Render(fmt.Sprintf("Running: %s...", stepLabels[m.current]))
+		if m.current == state.StepSyncEnv && m.syncProgress.Total > 0 {
+			content = lipgloss.JoinVertical(
+				lipgloss.Left,
+				content,
+				"",
+				ProgressBar(m.syncProgress.Done(), m.syncProgress.Total, syncBarWidth)+
+					InputStyle.Render(fmt.Sprintf(" %d/%d synced", m.syncProgress.Synced, m.syncProgress.Total)),
+			)
+		}
+
+	case workflowPhaseFailed:
+		lines := []string{ErrorStyle.Render(fmt.Sprintf("✗ Error: %s", m.stepErr))}
+		if m.current == state.StepSyncEnv && len(m.syncProgress.Failed) > 0 {
+			lines = append(lines, "", PromptStyle.Render(fmt.Sprintf("Failed keys (%d of %d):", len(m.syncProgress.Failed), m.syncProgress.Total)))
+			for _, key := range m.syncProgress.Failed {
+				lines = append(lines, RedStyle.Render("  "+key))
+			}
+		}
+		lines = append(lines, "", HelpStyle.Render("Press Enter to retry • q to return"))
+		content = lipgloss.JoinVertical(lipgloss.Left, lines...)
+
+	case workflowPhaseDnsRecord:
+		lines := []string{
+			PromptStyle.Render(fmt.Sprintf("DNS record pointing %s at %s (TYPE:NAME:VALUE):", m.migration.Domain, m.migration.Target)),
+			m.projectInput.View(),
+			"",
+			HelpStyle.Render("The apex (@) can't be a CNAME: give it an A or AAAA record, e.g. A:@:203.0.113.10"),
+		}
+		if host := m.previewHost(); host != "" {
+			lines = append(lines, HelpStyle.Render(fmt.Sprintf("A subdomain can be a CNAME to the preview, e.g. CNAME:www:%s", host)))
+		}
+		if m.recordErr != nil {
+			lines = append(lines, "", ErrorStyle.Render(fmt.Sprintf("✗ %s", m.recordErr)))
+		}
+		lines = append(lines, "", HelpStyle.Render("Press Enter to update DNS • esc to go back"))
+		content = lipgloss.JoinVertical(lipgloss.Left, lines...)
+
+	case workflowPhaseDone:
+		content = SuccessStyle.Render("✓ Migration complete!")
+
+	case workflowPhaseEnvDiff:
+		content = m.envDiff.View()
+	}
+
+	help := " Deploy Tunnel Migration | enter run • q back "
+	if m.canDiffEnv() {
+		help = " Deploy Tunnel Migration | enter run • d env diff • q back "
+	}
+	switch m.phase {
+	case workflowPhaseReviewEnv:
+		help = m.envReview.HelpText()
+	case workflowPhaseEnvDiff:
+		help = m.envDiff.HelpText()
+	}
+	footer := StatusBarStyle.Render(help)
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		header,
+		summary,
+		"",
+		BoxStyle.Render(lipgloss.JoinVertical(lipgloss.Left, steps...)),
+		"",
+		content,
+		"",
+		footer,
+	)
+}
+
+// Messages
+type stepDoneMsg struct {
+	step state.Step
+	data json.RawMessage
+	err  error
+}
+
+type syncProgressMsg struct {
+	progress bridge.SyncProgress
+}
+
+// waitForSyncProgress delivers the next batch update; it yields nothing once the sync closes the channel
+func waitForSyncProgress(ch chan bridge.SyncProgress) tea.Cmd {
+	return func() tea.Msg {
+		progress, ok := <-ch
+		if !ok {
+			return nil
+		}
+		return syncProgressMsg{progress: progress}
+	}
+}
+
+// runStepCmd executes one workflow step and checkpoints it on success
+func runStepCmd(m MigrationModel, step state.Step) tea.Cmd {
+	return func() tea.Msg {
+		migrationID := m.migration.ID
+
+		if err := m.stateDB.UpdateMigrationStatus(migrationID, "in_progress"); err != nil {
+			return stepDoneMsg{step: step, err: fmt.Errorf("failed to update migration status: %w", err)}
+		}
+		m.stateDB.LogJSON(&migrationID, "info", fmt.Sprintf("%s started", stepLabels[step]), map[string]interface{}{"step": step})
+
+		data, err := m.execStep(step)
+		if err != nil {
+			if statusErr := m.stateDB.UpdateMigrationStatus(migrationID, "failed"); statusErr != nil {
+				err = fmt.Errorf("%w (and failed to mark the migration failed: %v)", err, statusErr)
+			}
+			m.stateDB.LogJSON(&migrationID, "error", fmt.Sprintf("%s failed: %s", stepLabels[step], err), map[string]interface{}{"step": step})
+			return stepDoneMsg{step: step, err: err}
+		}
+
+		if err := m.stateDB.SetStep(migrationID, step, data); err != nil {
+			return stepDoneMsg{step: step, err: fmt.Errorf("failed to save checkpoint: %w", err)}
+		}
+		m.stateDB.LogJSON(&migrationID, "info", fmt.Sprintf("%s completed", stepLabels[step]), map[string]interface{}{"step": step})
+
+		return stepDoneMsg{step: step, data: data}
+	}
+}
+
+func (m MigrationModel) execStep(step state.Step) (json.RawMessage, error) {
+	mig := m.migration
+
+	switch step {
+	case state.StepFetchConfig:
+		token, err := providerToken(mig.Source)
+		if err != nil {
+			return nil, err
+		}
+		config, err := m.bridge.FetchConfig(m.ctx, bridge.FetchConfigParams{
+			Provider:  bridge.Provider(mig.Source),
+			Token:     token,
+			ProjectID: m.projects.SourceProjectID,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		// Re-running after a partial failure must not duplicate saved vars
+		existing, err := m.stateDB.GetEnvVarsContext(m.ctx, mig.ID)
+		if err != nil {
+			return nil, err
+		}
+		if len(existing) == 0 {
+			for _, e := range config.Env {
+				if err := m.stateDB.SaveEnvVar(mig.ID, e.Key, e.Value, e.Key, e.Target); err != nil {
+					return nil, fmt.Errorf("failed to save %s: %w", e.Key, err)
+				}
+			}
+		}
+
+		return json.Marshal(fetchConfigCheckpoint{
+			workflowProjects: m.projects,
+			ProjectName:      config.Project.Name,
+			EnvCount:         len(config.Env),
+		})
+
+	case state.StepSyncEnv:
+		if m.syncCh != nil {
+			defer close(m.syncCh)
+		}
+		token, err := providerToken(mig.Target)
+		if err != nil {
+			return nil, err
+		}
+		stored, err := m.stateDB.GetEnvVarsContext(m.ctx, mig.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		// A retry after a partial failure only sends what didn't make it
+		synced, err := m.stateDB.SyncedEnvContext(m.ctx, mig.ID, mig.Target)
+		if err != nil {
+			return nil, err
+		}
+
+		var envVars []bridge.EnvVar
+		for _, e := range stored {
+			environments := bridge.SyncEnvironments(e.Environments, "")
+			if e.Excluded || synced[e.SyncKey()] == m.stateDB.EnvFingerprint(e.Value, environments) {
+				continue
+			}
+			envVars = append(envVars, bridge.EnvVar{
+				Key:    e.SyncKey(),
+				Value:  e.Value,
+				Target: environments,
+			})
+		}
+
+		result, err := m.bridge.SyncEnvBatched(m.ctx, bridge.SyncEnvParams{
+			Provider:  bridge.Provider(mig.Target),
+			Token:     token,
+			ProjectID: m.projects.TargetProjectID,
+			EnvVars:   envVars,
+		}, bridge.DefaultEnvBatchSize, func(p bridge.SyncProgress) {
+			if m.syncCh != nil {
+				m.syncCh <- p
+			}
+		})
+		done := make(map[string]string)
+		for _, e := range bridge.SyncedVars(envVars, result) {
+			done[e.Key] = m.stateDB.EnvFingerprint(e.Value, e.Target)
+		}
+		if recordErr := m.stateDB.RecordSyncedEnvContext(m.ctx, mig.ID, mig.Target, done); recordErr != nil && err == nil {
+			err = recordErr
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(result.Failed) > 0 {
+			return nil, fmt.Errorf("failed to sync %d variable(s): %s", len(result.Failed), strings.Join(result.Failed, ", "))
+		}
+		return json.Marshal(result)
+
+	case state.StepDeployPreview:
+		token, err := providerToken(mig.Target)
+		if err != nil {
+			return nil, err
+		}
+		deploy, err := m.bridge.DeployPreview(m.ctx, bridge.DeployPreviewParams{
+			Provider:  bridge.Provider(mig.Target),
+			Token:     token,
+			ProjectID: m.projects.TargetProjectID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		// Recorded so `dt deploy list` can show it after the workflow
+		if err := m.stateDB.SaveDeploymentContext(m.ctx, &state.Deployment{
+			ID:          deploy.DeploymentID,
+			MigrationID: mig.ID,
+			Provider:    mig.Target,
+			URL:         deploy.URL,
+			Status:      deploy.Status,
+			BuildTime:   deploy.BuildTime,
+		}); err != nil {
+			return nil, err
+		}
+		// Best effort: it only feeds `dt deploy preview --wait` estimates
+		if deploy.Status == bridge.DeploymentReady && deploy.BuildTime != nil {
+			m.stateDB.RecordBuildTimeContext(m.ctx, mig.Target, m.projects.TargetProjectID, time.Duration(*deploy.BuildTime)*time.Second)
+		}
+		return json.Marshal(deploy)
+
+	case state.StepDnsUpdate:
+		if m.dnsRecord == nil {
+			return nil, fmt.Errorf("no DNS record entered")
+		}
+		token, err := providerToken(mig.Target)
+		if err != nil {
+			return nil, err
+		}
+
+		params := bridge.DnsUpdateParams{
+			Provider:    bridge.Provider(mig.Target),
+			Token:       token,
+			Domain:      mig.Domain,
+			RecordType:  m.dnsRecord.Type,
+			RecordName:  m.dnsRecord.Name,
+			RecordValue: m.dnsRecord.Value,
+			TTL:         300,
+		}
+		result, err := m.bridge.DnsUpdate(m.ctx, params)
+		if err != nil {
+			return nil, err
+		}
+
+		migrationID := mig.ID
+		if err := m.stateDB.SaveDnsRecord(&state.DnsRecord{
+			ProviderRecordID: result.RecordID,
+			MigrationID:      &migrationID,
+			Domain:           params.Domain,
+			RecordType:       params.RecordType,
+			RecordName:       params.RecordName,
+			RecordValue:      params.RecordValue,
+			TTL:              params.TTL,
+			PreviousValue:    result.PreviousValue,
+		}); err != nil {
+			return nil, fmt.Errorf("failed to save DNS record: %w", err)
+		}
+
+		return json.Marshal(dnsUpdateCheckpoint{
+			RecordID:      result.RecordID,
+			PreviousValue: result.PreviousValue,
+		})
+
+	case state.StepVerify:
+		deploy, err := m.previewDeployment()
+		if err != nil {
+			return nil, err
+		}
+		status, err := checkURL(m.ctx, deploy.URL)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(map[string]interface{}{"url": deploy.URL, "status": status})
+
+	case state.StepCutover:
+		if err := m.stateDB.UpdateMigrationStatus(mig.ID, "completed"); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	return nil, fmt.Errorf("unknown step: %s", step)
+}
+
+// previewHost is the preview deployment's host, or "" if there isn't one
+func (m MigrationModel) previewHost() string {
+	deploy, err := m.previewDeployment()
+	if err != nil {
+		return ""
+	}
+	previewURL, err := url.Parse(deploy.URL)
+	if err != nil {
+		return ""
+	}
+	return previewURL.Host
+}
+
+// previewDeployment reads the deploy_preview checkpoint
+func (m MigrationModel) previewDeployment() (*bridge.DeployPreviewData, error) {
+	data, ok := m.checkpoints[state.StepDeployPreview]
+	if !ok {
+		return nil, fmt.Errorf("no preview deployment recorded")
+	}
+	var deploy bridge.DeployPreviewData
+	if err := json.Unmarshal(data, &deploy); err != nil {
+		return nil, fmt.Errorf("corrupt preview checkpoint: %w", err)
+	}
+	return &deploy, nil
+}
+
+// checkURL requests the deployment and fails on a 4xx/5xx response
+func checkURL(ctx context.Context, rawURL string) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, verifyTimeout)
+	defer cancel()
+
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "https://" + rawURL
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return 0, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("preview is unreachable: %w", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return resp.StatusCode, fmt.Errorf("preview returned HTTP %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+func providerToken(provider string) (string, error) {
+	token, err := keychain.Get(provider)
+	if err != nil || token == "" {
+		return "", fmt.Errorf("no credentials for %s (run: dt auth %s)", provider, provider)
+	}
+	return token, nil
+}
+
+// RunMigrationTUI runs the migration workflow TUI for a migration, then
+// returns to the dashboard
+func RunMigrationTUI(s *Session, migration *state.Migration) error {
+	p := tea.NewProgram(
+		NewMigrationModel(s, migration),
+		tea.WithAltScreen(),
+		tea.WithContext(s.Ctx),
+	)
+
+	if _, err := p.Run(); err != nil || s.done() {
+		return runErr(s, err)
+	}
+
+	// Return to dashboard
+	return RunDashboardTUI(s)
+}
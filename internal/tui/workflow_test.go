@@ -0,0 +1,455 @@
+package tui
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/johnhorton/deploy-tunnel/internal/bridge"
+	"github.com/johnhorton/deploy-tunnel/internal/state"
+)
+
+// fakeWorkflowBridge answers the workflow's bridge calls from canned results
+type fakeWorkflowBridge struct {
+	caps   map[bridge.Provider]*bridge.CapabilitiesData
+	config *bridge.FetchConfigData
+	// syncBatches are reported to onBatch in order before syncResult returns
+	syncBatches []bridge.SyncProgress
+	// syncedVars are the env vars the last sync was asked to send
+	syncedVars []bridge.EnvVar
+	syncResult *bridge.SyncEnvData
+	syncErr    error
+	deploy     *bridge.DeployPreviewData
+	deployErr  error
+	dns        *bridge.DnsUpdateData
+	// dnsParams is the last DNS update asked for
+	dnsParams bridge.DnsUpdateParams
+	// calls records each verb as it is made
+	calls []string
+}
+
+func (f *fakeWorkflowBridge) Capabilities(ctx context.Context, provider bridge.Provider) (*bridge.CapabilitiesData, error) {
+	if caps, ok := f.caps[provider]; ok {
+		return caps, nil
+	}
+	return nil, fmt.Errorf("no adapter for %s", provider)
+}
+
+func (f *fakeWorkflowBridge) FetchConfig(ctx context.Context, params bridge.FetchConfigParams) (*bridge.FetchConfigData, error) {
+	f.calls = append(f.calls, "fetch:config")
+	return f.config, nil
+}
+
+func (f *fakeWorkflowBridge) SyncEnvBatched(ctx context.Context, params bridge.SyncEnvParams, batchSize int, onBatch func(bridge.SyncProgress)) (*bridge.SyncEnvData, error) {
+	f.calls = append(f.calls, "sync:env")
+	f.syncedVars = params.EnvVars
+	for _, p := range f.syncBatches {
+		onBatch(p)
+	}
+	if f.syncResult == nil {
+		return &bridge.SyncEnvData{Synced: len(params.EnvVars)}, f.syncErr
+	}
+	return f.syncResult, f.syncErr
+}
+
+func (f *fakeWorkflowBridge) DeployPreview(ctx context.Context, params bridge.DeployPreviewParams) (*bridge.DeployPreviewData, error) {
+	f.calls = append(f.calls, "deploy:preview")
+	return f.deploy, f.deployErr
+}
+
+func (f *fakeWorkflowBridge) DnsUpdate(ctx context.Context, params bridge.DnsUpdateParams) (*bridge.DnsUpdateData, error) {
+	f.calls = append(f.calls, "dns:update")
+	f.dnsParams = params
+	return f.dns, nil
+}
+
+// newWorkflow opens the workflow for a vercel → netlify migration with
+// credentials for both and the given steps already checkpointed
+func newWorkflow(t *testing.T, fake *fakeWorkflowBridge, done ...state.Step) MigrationModel {
+	t.Helper()
+	storeCredential(t, "vercel", "tok_source")
+	storeCredential(t, "netlify", "tok_target")
+
+	s := newTestSession(t, nil)
+	if err := s.StateDB.CreateMigration("m1", "vercel", "netlify", "example.com"); err != nil {
+		t.Fatal(err)
+	}
+	for _, step := range done {
+		data := json.RawMessage(`{}`)
+		if step == state.StepFetchConfig {
+			data, _ = json.Marshal(fetchConfigCheckpoint{
+				workflowProjects: workflowProjects{SourceProjectID: "src", TargetProjectID: "tgt"},
+			})
+		}
+		if err := s.StateDB.SetStep("m1", step, data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mig, err := s.StateDB.GetMigration("m1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewMigrationModel(s, mig)
+	m.bridge = fake
+	return sized(t, m)
+}
+
+// runCurrentStep runs the current step to completion and delivers its result
+func runCurrentStep(t *testing.T, m MigrationModel) MigrationModel {
+	t.Helper()
+	if m.phase != workflowPhaseRunning {
+		t.Fatalf("phase = %v, want %v before running %s", m.phase, workflowPhaseRunning, m.current)
+	}
+	updated, _ := m.Update(runStepCmd(m, m.current)())
+	return updated.(MigrationModel)
+}
+
+// checkpointed returns the steps stored for m1
+func checkpointed(t *testing.T, m MigrationModel) []state.Step {
+	t.Helper()
+	records, err := m.stateDB.GetSteps("m1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var steps []state.Step
+	for _, r := range records {
+		steps = append(steps, r.Step)
+	}
+	return steps
+}
+
+func TestWorkflowResumesFromCheckpoints(t *testing.T) {
+	m := newWorkflow(t, &fakeWorkflowBridge{}, state.StepFetchConfig)
+
+	if m.phase != workflowPhaseReady {
+		t.Errorf("phase = %v, want %v", m.phase, workflowPhaseReady)
+	}
+	if m.projects.SourceProjectID != "src" || m.projects.TargetProjectID != "tgt" {
+		t.Errorf("projects = %+v, want them restored from the checkpoint", m.projects)
+	}
+	if m.current != state.StepSyncEnv {
+		t.Errorf("current = %q, want %q", m.current, state.StepSyncEnv)
+	}
+}
+
+func TestWorkflowFetchConfig(t *testing.T) {
+	fake := &fakeWorkflowBridge{
+		config: &bridge.FetchConfigData{
+			Project: bridge.Project{Name: "site"},
+			Env:     []bridge.EnvVar{{Key: "API_KEY", Value: "sk_1", Target: []string{"production"}}},
+		},
+	}
+	m := newWorkflow(t, fake)
+	if m.phase != workflowPhaseSourceProject {
+		t.Fatalf("phase = %v, want %v", m.phase, workflowPhaseSourceProject)
+	}
+
+	m, _ = press(t, m, "src", "enter", "tgt", "enter")
+	if m.phase != workflowPhaseReady {
+		t.Fatalf("after entering projects, phase = %v, want %v", m.phase, workflowPhaseReady)
+	}
+
+	// Nothing runs until the capabilities are in
+	m, cmd := press(t, m, "enter")
+	if m.phase != workflowPhaseReady || cmd != nil {
+		t.Fatalf("enter before capabilities: phase = %v, want %v without a command", m.phase, workflowPhaseReady)
+	}
+	updated, _ := m.Update(workflowCapsMsg{})
+	m = updated.(MigrationModel)
+
+	m, _ = press(t, m, "enter")
+	if m.current != state.StepFetchConfig {
+		t.Fatalf("current = %q, want %q", m.current, state.StepFetchConfig)
+	}
+	m = runCurrentStep(t, m)
+
+	if got := checkpointed(t, m); len(got) != 1 || got[0] != state.StepFetchConfig {
+		t.Errorf("checkpoints = %v, want [%s]", got, state.StepFetchConfig)
+	}
+	envVars, err := m.stateDB.GetEnvVars("m1")
+	if err != nil || len(envVars) != 1 || envVars[0].Key != "API_KEY" {
+		t.Errorf("GetEnvVars = %+v, %v, want API_KEY saved", envVars, err)
+	}
+	// The sync waits for the env review
+	if m.current != state.StepSyncEnv || m.phase != workflowPhaseReviewEnv {
+		t.Errorf("after fetch: current = %q, phase = %v, want %q in %v", m.current, m.phase, state.StepSyncEnv, workflowPhaseReviewEnv)
+	}
+}
+
+func TestWorkflowStepFailureAndRetry(t *testing.T) {
+	fake := &fakeWorkflowBridge{deployErr: errors.New("build failed")}
+	m := newWorkflow(t, fake, state.StepFetchConfig, state.StepSyncEnv)
+	updated, _ := m.Update(workflowCapsMsg{})
+	m = updated.(MigrationModel)
+
+	m, _ = press(t, m, "enter")
+	m = runCurrentStep(t, m)
+	if m.phase != workflowPhaseFailed {
+		t.Fatalf("phase = %v, want %v", m.phase, workflowPhaseFailed)
+	}
+	if m.current != state.StepDeployPreview {
+		t.Errorf("current = %q, want the failed step kept", m.current)
+	}
+	if view := m.View(); !strings.Contains(view, "build failed") {
+		t.Errorf("failed view is missing the error:\n%s", view)
+	}
+	mig, err := m.stateDB.GetMigration("m1")
+	if err != nil || mig.Status != "failed" {
+		t.Errorf("stored status = %v, %v, want failed", mig, err)
+	}
+	if got := checkpointed(t, m); len(got) != 2 {
+		t.Errorf("checkpoints = %v, want no checkpoint for the failed step", got)
+	}
+
+	// Enter retries the failed step
+	fake.deployErr = nil
+	fake.deploy = &bridge.DeployPreviewData{DeploymentID: "dpl_1", URL: "https://site.netlify.app", Status: bridge.DeploymentReady}
+	m, _ = press(t, m, "enter")
+	m = runCurrentStep(t, m)
+	if m.stepErr != nil {
+		t.Fatalf("retry error: %v", m.stepErr)
+	}
+	if got := checkpointed(t, m); len(got) != 3 || got[2] != state.StepDeployPreview {
+		t.Errorf("checkpoints = %v, want %s added", got, state.StepDeployPreview)
+	}
+	// Successful steps chain into the next one, which asks for its record
+	if m.current != state.StepDnsUpdate || m.phase != workflowPhaseDnsRecord {
+		t.Errorf("after retry: current = %q, phase = %v, want %q asking for a record", m.current, m.phase, state.StepDnsUpdate)
+	}
+}
+
+func TestWorkflowAsksForDnsRecord(t *testing.T) {
+	fake := &fakeWorkflowBridge{dns: &bridge.DnsUpdateData{RecordID: "rec_1"}}
+	m := newWorkflow(t, fake, state.StepFetchConfig, state.StepSyncEnv, state.StepDeployPreview)
+	updated, _ := m.Update(workflowCapsMsg{})
+	m = updated.(MigrationModel)
+
+	// Nothing is assumed for the apex; the record has to be given
+	m, _ = press(t, m, "enter")
+	if m.phase != workflowPhaseDnsRecord {
+		t.Fatalf("phase = %v, want %v", m.phase, workflowPhaseDnsRecord)
+	}
+	m, _ = press(t, m, "MX:@:mail", "enter")
+	if m.phase != workflowPhaseDnsRecord || m.recordErr == nil {
+		t.Fatalf("an unsupported record type was accepted: phase = %v", m.phase)
+	}
+	if view := m.View(); !strings.Contains(view, "not a supported record type") {
+		t.Errorf("view is missing the record error:\n%s", view)
+	}
+
+	m.projectInput.Reset()
+	m, _ = press(t, m, "a:@:203.0.113.10", "enter")
+	m = runCurrentStep(t, m)
+	if m.stepErr != nil {
+		t.Fatalf("DNS step error: %v", m.stepErr)
+	}
+	want := bridge.DnsUpdateParams{Provider: "netlify", Token: "tok_target", Domain: "example.com", RecordType: "A", RecordName: "@", RecordValue: "203.0.113.10", TTL: 300}
+	if fake.dnsParams != want {
+		t.Errorf("DNS update = %+v, want %+v", fake.dnsParams, want)
+	}
+	records, err := m.stateDB.GetDnsRecords("m1")
+	if err != nil || len(records) != 1 || records[0].ProviderRecordID != "rec_1" || records[0].RecordType != "A" {
+		t.Errorf("GetDnsRecords = %+v, %v, want the A record saved", records, err)
+	}
+}
+
+func TestWorkflowSkipsUnsupportedSteps(t *testing.T) {
+	m := newWorkflow(t, &fakeWorkflowBridge{}, state.StepFetchConfig, state.StepSyncEnv)
+	updated, _ := m.Update(workflowCapsMsg{
+		target: &bridge.CapabilitiesData{
+			Features: bridge.Features{EnvVariables: true},
+		},
+	})
+	m = updated.(MigrationModel)
+
+	// Verify needs the preview deployment, so it goes with it
+	if m.current != state.StepCutover {
+		t.Errorf("current = %q, want %q", m.current, state.StepCutover)
+	}
+	view := m.View()
+	for _, want := range []string{"Netlify adapter: preview deployments not supported", "Netlify adapter: DNS not supported", "needs a preview deployment"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("view is missing %q:\n%s", want, view)
+		}
+	}
+}
+
+// startSync seeds API_KEY and DEBUG to sync, with SECRET excluded, and
+// confirms the env review so the sync step starts
+func startSync(t *testing.T, fake *fakeWorkflowBridge) MigrationModel {
+	t.Helper()
+	m := newWorkflow(t, fake, state.StepFetchConfig)
+	for _, key := range []string{"API_KEY", "DEBUG", "SECRET"} {
+		if err := m.stateDB.SaveEnvVar("m1", key, "value_"+key, key, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	vars, err := m.stateDB.GetEnvVars("m1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, e := range vars {
+		if e.Key == "SECRET" {
+			if err := m.stateDB.SetEnvVarExcluded(e.ID, true); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	updated, _ := m.Update(workflowCapsMsg{})
+	m, _ = press(t, updated.(MigrationModel), "enter")
+	if m.phase != workflowPhaseReviewEnv {
+		t.Fatalf("phase = %v, want %v", m.phase, workflowPhaseReviewEnv)
+	}
+	updated, _ = m.Update(envReviewDoneMsg{confirmed: true})
+	m = updated.(MigrationModel)
+	if m.phase != workflowPhaseRunning || m.syncCh == nil {
+		t.Fatalf("confirming the review didn't start the sync: phase = %v", m.phase)
+	}
+	return m
+}
+
+// runSync runs the sync step, delivering each batch's progress to the model
+// as the program would, and calls check after each one
+func runSync(t *testing.T, m MigrationModel, check func(MigrationModel)) MigrationModel {
+	t.Helper()
+	result := make(chan tea.Msg, 1)
+	go func() { result <- runStepCmd(m, m.current)() }()
+
+	for {
+		msg := waitForSyncProgress(m.syncCh)()
+		if msg == nil {
+			break
+		}
+		updated, _ := m.Update(msg)
+		m = updated.(MigrationModel)
+		check(m)
+	}
+	updated, _ := m.Update(<-result)
+	return updated.(MigrationModel)
+}
+
+func TestWorkflowSyncProgress(t *testing.T) {
+	fake := &fakeWorkflowBridge{
+		syncBatches: []bridge.SyncProgress{
+			{Synced: 1, Total: 2},
+			{Synced: 2, Total: 2},
+		},
+	}
+	m := startSync(t, fake)
+
+	var seen []int
+	m = runSync(t, m, func(m MigrationModel) {
+		seen = append(seen, m.syncProgress.Done())
+		if view := ansi.Strip(m.View()); !strings.Contains(view, fmt.Sprintf("%d/2 synced", m.syncProgress.Synced)) {
+			t.Errorf("view is missing the progress:\n%s", view)
+		}
+	})
+	if fmt.Sprint(seen) != "[1 2]" {
+		t.Errorf("progress went %v, want [1 2]", seen)
+	}
+	if m.stepErr != nil {
+		t.Fatalf("sync failed: %v", m.stepErr)
+	}
+
+	var sent []string
+	for _, e := range fake.syncedVars {
+		sent = append(sent, e.Key)
+	}
+	if strings.Join(sent, ",") != "API_KEY,DEBUG" {
+		t.Errorf("synced %v, want only the included API_KEY and DEBUG", sent)
+	}
+
+	// A late update from an earlier batch never moves the bar backwards
+	updated, _ := m.Update(syncProgressMsg{progress: bridge.SyncProgress{Synced: 1, Total: 2}})
+	if got := updated.(MigrationModel).syncProgress.Done(); got != 2 {
+		t.Errorf("after a stale update, done = %d, want 2", got)
+	}
+}
+
+func TestWorkflowSyncFailedKeys(t *testing.T) {
+	fake := &fakeWorkflowBridge{
+		syncBatches: []bridge.SyncProgress{{Synced: 1, Failed: []string{"DEBUG"}, Total: 2}},
+		syncResult:  &bridge.SyncEnvData{Synced: 1, Failed: []string{"DEBUG"}},
+	}
+	m := runSync(t, startSync(t, fake), func(MigrationModel) {})
+
+	if m.phase != workflowPhaseFailed {
+		t.Fatalf("phase = %v, want %v", m.phase, workflowPhaseFailed)
+	}
+	view := ansi.Strip(m.View())
+	for _, want := range []string{"Failed keys (1 of 2)", "DEBUG"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("failed view is missing %q:\n%s", want, view)
+		}
+	}
+}
+
+func TestWorkflowSyncNothingToSend(t *testing.T) {
+	m := newWorkflow(t, &fakeWorkflowBridge{}, state.StepFetchConfig)
+	m.phase = workflowPhaseRunning
+	m.current = state.StepSyncEnv
+
+	// With a zero total there is no bar to draw
+	if view := ansi.Strip(m.View()); strings.Contains(view, "synced") {
+		t.Errorf("view shows a progress bar with nothing to sync:\n%s", view)
+	}
+}
+
+func TestUnsupportedSteps(t *testing.T) {
+	all := bridge.Features{EnvVariables: true, PreviewDeployments: true, DNSManagement: true}
+
+	tests := []struct {
+		name   string
+		source *bridge.CapabilitiesData
+		target *bridge.CapabilitiesData
+		want   map[state.Step]string
+	}{
+		{name: "everything supported", source: &bridge.CapabilitiesData{Features: all}, target: &bridge.CapabilitiesData{Features: all}, want: map[state.Step]string{}},
+		{name: "unknown capabilities gate nothing", want: map[state.Step]string{}},
+		{
+			name:   "no DNS",
+			target: &bridge.CapabilitiesData{Features: bridge.Features{EnvVariables: true, PreviewDeployments: true}},
+			want:   map[state.Step]string{state.StepDnsUpdate: "Render adapter: DNS not supported"},
+		},
+		{
+			name:   "verb not listed",
+			target: &bridge.CapabilitiesData{Features: all, SupportedVerbs: []string{"sync:env", "deploy:preview"}},
+			want:   map[state.Step]string{state.StepDnsUpdate: "Render adapter: dns:update not supported"},
+		},
+		{
+			name:   "source can't fetch config",
+			source: &bridge.CapabilitiesData{Features: all, SupportedVerbs: []string{"capabilities"}},
+			want:   map[state.Step]string{state.StepFetchConfig: "Vercel adapter: fetch:config not supported"},
+		},
+		{
+			name:   "no previews skips verify",
+			target: &bridge.CapabilitiesData{Features: bridge.Features{EnvVariables: true, DNSManagement: true}},
+			want: map[state.Step]string{
+				state.StepDeployPreview: "Render adapter: preview deployments not supported",
+				state.StepVerify:        "needs a preview deployment",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := unsupportedSteps("vercel", "render", tt.source, tt.target)
+			if len(got) != len(tt.want) {
+				t.Errorf("unsupportedSteps() = %v, want %v", got, tt.want)
+				return
+			}
+			for step, note := range tt.want {
+				if got[step] != note {
+					t.Errorf("note for %s = %q, want %q", step, got[step], note)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package validate
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	maxDomainLength = 253
+	maxLabelLength  = 63
+)
+
+// NormalizeDomain trims whitespace, lowercases, and drops a single trailing dot
+func NormalizeDomain(domain string) string {
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	return strings.TrimSuffix(domain, ".")
+}
+
+// Domain checks that domain is a bare hostname such as "app.example.com".
+// Schemes, paths, ports, and whitespace are rejected with a hint about what to remove.
+func Domain(domain string) error {
+	domain = NormalizeDomain(domain)
+
+	if domain == "" {
+		return fmt.Errorf("domain is required")
+	}
+	if strings.Contains(domain, "://") {
+		return fmt.Errorf("remove the scheme (e.g. https://) from the domain")
+	}
+	if strings.ContainsAny(domain, "/?#") {
+		return fmt.Errorf("remove the path from the domain")
+	}
+	if strings.Contains(domain, ":") {
+		return fmt.Errorf("remove the port from the domain")
+	}
+	if strings.ContainsAny(domain, " \t") {
+		return fmt.Errorf("domain must not contain spaces")
+	}
+	if len(domain) > maxDomainLength {
+		return fmt.Errorf("domain must be at most %d characters", maxDomainLength)
+	}
+
+	labels := strings.Split(domain, ".")
+	if len(labels) < 2 {
+		return fmt.Errorf("domain must include a TLD (e.g. example.com)")
+	}
+
+	for _, label := range labels {
+		if err := validateLabel(label); err != nil {
+			return err
+		}
+	}
+
+	tld := labels[len(labels)-1]
+	if len(tld) < 2 {
+		return fmt.Errorf("TLD %q is too short", tld)
+	}
+	if !strings.HasPrefix(tld, "xn--") {
+		for _, r := range tld {
+			if r < 'a' || r > 'z' {
+				return fmt.Errorf("TLD %q must contain only letters", tld)
+			}
+		}
+	}
+
+	return nil
+}
+
+func validateLabel(label string) error {
+	if label == "" {
+		return fmt.Errorf("domain must not contain empty labels")
+	}
+	if len(label) > maxLabelLength {
+		return fmt.Errorf("label %q must be at most %d characters", label, maxLabelLength)
+	}
+	if strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+		return fmt.Errorf("label %q must not start or end with a hyphen", label)
+	}
+	for _, r := range label {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-') {
+			return fmt.Errorf("label %q contains invalid character %q", label, r)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package validate
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDomain(t *testing.T) {
+	tests := []struct {
+		name   string
+		domain string
+		// wantErr is a substring of the expected error, "" for valid domains
+		wantErr string
+	}{
+		{name: "apex", domain: "example.com"},
+		{name: "subdomain", domain: "app.example.com"},
+		{name: "digits and hyphens", domain: "my-app2.example.co.uk"},
+		{name: "uppercase", domain: "App.Example.COM"},
+		{name: "surrounding space", domain: "  example.com  "},
+		{name: "trailing dot", domain: "example.com."},
+		{name: "punycode tld", domain: "example.xn--p1ai"},
+		{name: "longest label", domain: strings.Repeat("a", 63) + ".com"},
+
+		{name: "empty", domain: "", wantErr: "required"},
+		{name: "only spaces", domain: "   ", wantErr: "required"},
+		{name: "scheme", domain: "https://example.com", wantErr: "scheme"},
+		{name: "scheme and path", domain: "http://example.com/", wantErr: "scheme"},
+		{name: "path", domain: "example.com/app", wantErr: "path"},
+		{name: "query", domain: "example.com?a=1", wantErr: "path"},
+		{name: "port", domain: "example.com:8080", wantErr: "port"},
+		{name: "inner space", domain: "exa mple.com", wantErr: "spaces"},
+		{name: "no tld", domain: "example", wantErr: "TLD"},
+		{name: "short tld", domain: "example.c", wantErr: "too short"},
+		{name: "numeric tld", domain: "example.123", wantErr: "only letters"},
+		{name: "empty label", domain: "app..example.com", wantErr: "empty labels"},
+		{name: "leading dot", domain: ".example.com", wantErr: "empty labels"},
+		{name: "leading hyphen", domain: "-app.example.com", wantErr: "hyphen"},
+		{name: "trailing hyphen", domain: "app-.example.com", wantErr: "hyphen"},
+		{name: "underscore", domain: "my_app.example.com", wantErr: "invalid character"},
+		{name: "long label", domain: strings.Repeat("a", 64) + ".com", wantErr: "at most 63"},
+		{name: "long domain", domain: strings.Repeat(strings.Repeat("a", 60)+".", 5) + "com", wantErr: "at most 253"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Domain(tt.domain)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Domain(%q) = %v, want nil", tt.domain, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Domain(%q) = %v, want error containing %q", tt.domain, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNormalizeDomain(t *testing.T) {
+	tests := []struct {
+		domain, want string
+	}{
+		{"example.com", "example.com"},
+		{" Example.COM. ", "example.com"},
+		{"example.com..", "example.com."},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeDomain(tt.domain); got != tt.want {
+			t.Errorf("NormalizeDomain(%q) = %q, want %q", tt.domain, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package validate
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// tokenShape describes what a provider's API tokens look like
+type tokenShape struct {
+	// name is the provider's display name for warnings
+	name string
+	// patterns match any valid token format, e.g. current and legacy ones
+	patterns []*regexp.Regexp
+	// expected describes the format for the warning
+	expected string
+	// prefix is the literal prefix of the current format, if it has one; a
+	// token with it is that provider's even if a looser shape also matches
+	prefix string
+}
+
+// tokenShapes are per-provider heuristics; tune them here as formats change
+var tokenShapes = map[string]tokenShape{
+	"vercel": {
+		name:     "Vercel",
+		patterns: []*regexp.Regexp{regexp.MustCompile(`^[A-Za-z0-9]{24}$`)},
+		expected: "24 letters and digits",
+	},
+	"cloudflare": {
+		name:     "Cloudflare",
+		patterns: []*regexp.Regexp{regexp.MustCompile(`^[A-Za-z0-9_-]{40}$`)},
+		expected: "40 letters, digits, - or _",
+	},
+	"netlify": {
+		name: "Netlify",
+		patterns: []*regexp.Regexp{
+			regexp.MustCompile(`^nfp_[A-Za-z0-9]{36}$`),
+			regexp.MustCompile(`^[A-Za-z0-9_-]{43}$`),
+		},
+		expected: "nfp_ followed by 36 letters and digits",
+		prefix:   "nfp_",
+	},
+	"render": {
+		name:     "Render",
+		patterns: []*regexp.Regexp{regexp.MustCompile(`^rnd_[A-Za-z0-9]{20,40}$`)},
+		expected: "rnd_ followed by letters and digits",
+		prefix:   "rnd_",
+	},
+}
+
+func (s tokenShape) matches(token string) bool {
+	for _, p := range s.patterns {
+		if p.MatchString(token) {
+			return true
+		}
+	}
+	return false
+}
+
+// TokenFormat returns a warning when token doesn't look like one of
+// provider's API tokens, naming the provider it does look like if any. It is
+// a heuristic, so callers should warn rather than refuse. Providers without a
+// known shape always pass.
+func TokenFormat(provider, token string) string {
+	shape, ok := tokenShapes[provider]
+	if !ok || shape.matches(token) {
+		return ""
+	}
+	if strings.ContainsAny(token, " \t\r\n") {
+		return fmt.Sprintf("this %s token contains whitespace; check it was pasted whole", shape.name)
+	}
+
+	warning := fmt.Sprintf("this doesn't look like a %s token (expected %s)", shape.name, shape.expected)
+
+	if other := lookalike(provider, token); other != "" {
+		return fmt.Sprintf("%s; it looks like a %s token", warning, tokenShapes[other].name)
+	}
+	return warning
+}
+
+// lookalike returns the provider other than provider whose shape token
+// matches, or "". Shapes overlap (a 40-character nfp_ token also fits
+// Cloudflare's), so a matching prefix wins; otherwise the first provider in
+// sorted order does, so the suggestion is stable.
+func lookalike(provider, token string) string {
+	others := make([]string, 0, len(tokenShapes))
+	for other := range tokenShapes {
+		if other != provider && tokenShapes[other].matches(token) {
+			others = append(others, other)
+		}
+	}
+	sort.Strings(others)
+	for _, other := range others {
+		if prefix := tokenShapes[other].prefix; prefix != "" && strings.HasPrefix(token, prefix) {
+			return other
+		}
+	}
+	if len(others) == 0 {
+		return ""
+	}
+	return others[0]
+}
@@ -0,0 +1,44 @@
+package validate
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTokenFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider string
+		token    string
+		// want is a substring of the expected warning, "" when the token passes
+		want string
+	}{
+		{name: "vercel", provider: "vercel", token: strings.Repeat("a1", 12)},
+		{name: "cloudflare", provider: "cloudflare", token: strings.Repeat("a_-1", 10)},
+		{name: "netlify", provider: "netlify", token: "nfp_" + strings.Repeat("a1", 18)},
+		{name: "netlify legacy", provider: "netlify", token: strings.Repeat("a", 43)},
+		{name: "render", provider: "render", token: "rnd_" + strings.Repeat("a", 24)},
+		{name: "unknown provider", provider: "fly", token: "anything"},
+
+		{name: "too short", provider: "vercel", token: "abc", want: "doesn't look like a Vercel token (expected 24 letters and digits)"},
+		{name: "whitespace", provider: "vercel", token: strings.Repeat("a", 12) + " " + strings.Repeat("a", 11), want: "contains whitespace"},
+		{name: "trailing newline", provider: "render", token: "rnd_" + strings.Repeat("a", 24) + "\n", want: "contains whitespace"},
+		{name: "another provider's token", provider: "vercel", token: "nfp_" + strings.Repeat("a1", 18), want: "it looks like a Netlify token"},
+		{name: "render token as netlify", provider: "netlify", token: "rnd_" + strings.Repeat("a", 24), want: "it looks like a Render token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := TokenFormat(tt.provider, tt.token)
+			if tt.want == "" {
+				if got != "" {
+					t.Errorf("TokenFormat(%s, %q) = %q, want no warning", tt.provider, tt.token, got)
+				}
+				return
+			}
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("TokenFormat(%s, %q) = %q, want %q", tt.provider, tt.token, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,21 @@
+// Package version holds build metadata injected at link time, e.g.
+//
+//	go build -ldflags "-X github.com/johnhorton/deploy-tunnel/internal/version.Version=1.2.0"
+package version
+
+import "strings"
+
+// Set via -ldflags -X; the defaults mark a local development build
+var (
+	Version = "dev"
+	Commit  = "none"
+	Date    = "unknown"
+)
+
+// Short returns the version for display, e.g. "v1.2.0" or "dev"
+func Short() string {
+	if Version == "dev" || strings.HasPrefix(Version, "v") {
+		return Version
+	}
+	return "v" + Version
+}
@@ -0,0 +1,30 @@
+package version
+
+import "testing"
+
+func TestShort(t *testing.T) {
+	tests := []struct {
+		version, want string
+	}{
+		{"dev", "dev"},
+		{"1.2.0", "v1.2.0"},
+		{"v1.2.0", "v1.2.0"},
+		{"1.3.0-rc.1", "v1.3.0-rc.1"},
+	}
+
+	previous := Version
+	defer func() { Version = previous }()
+	for _, tt := range tests {
+		Version = tt.version
+		if got := Short(); got != tt.want {
+			t.Errorf("Short() with Version %q = %q, want %q", tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestDefaults(t *testing.T) {
+	// Unlinked builds must read as development builds
+	if Version != "dev" || Commit != "none" || Date != "unknown" {
+		t.Errorf("defaults = %q, %q, %q, want dev, none, unknown", Version, Commit, Date)
+	}
+}
@@ -4,7 +4,7 @@ import { spawn } from "child_process";
 import { platform } from "os";
 import { join, dirname } from "path";
 import { fileURLToPath } from "url";
-import { mkdirSync, existsSync } from "fs";
+import { mkdirSync, existsSync, readFileSync } from "fs";
 
 const __filename = fileURLToPath(import.meta.url);
 const __dirname = dirname(__filename);
@@ -60,8 +60,16 @@ const build = async () => {
   console.log(`Output: ${outputPath}`);
   console.log("");
 
-  // Build the Go binary
-  const buildArgs = ["build", "-o", outputPath, "./cmd/deploy-tunnel"];
+  // Stamp the package version into the binary for `dt version`
+  const { version } = JSON.parse(readFileSync(join(rootDir, "package.json"), "utf8"));
+  const versionPkg = "github.com/johnhorton/deploy-tunnel/internal/version";
+  const ldflags = [
+    `-X ${versionPkg}.Version=${version}`,
+    `-X ${versionPkg}.Date=${new Date().toISOString()}`,
+  ].join(" ");
+
+  // Build the Go binary; sqlite_fts5 adds the full-text log search index
+  const buildArgs = ["build", "-tags", "sqlite_fts5", "-ldflags", ldflags, "-o", outputPath, "./cmd/deploy-tunnel"];
 
   const child = spawn("go", buildArgs, {
     cwd: rootDir,
@@ -0,0 +1,54 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+	"golang.org/x/term"
+)
+
+// Color modes for --color
+const (
+	ColorAuto   = "auto"
+	ColorAlways = "always"
+	ColorNever  = "never"
+)
+
+// ParseColorMode validates a --color value; empty means auto
+func ParseColorMode(mode string) (string, error) {
+	switch mode {
+	case "":
+		return ColorAuto, nil
+	case ColorAuto, ColorAlways, ColorNever:
+		return mode, nil
+	}
+	return "", fmt.Errorf("invalid --color %q: want auto, always, or never", mode)
+}
+
+// SetColorMode sets whether styles emit color for every later render, in ui
+// and the TUI. auto turns color off when NO_COLOR is set or stdout isn't a
+// terminal; always keeps it even when output is redirected.
+func SetColorMode(mode string) {
+	lipgloss.SetColorProfile(colorProfile(mode))
+}
+
+func colorProfile(mode string) termenv.Profile {
+	switch mode {
+	case ColorNever:
+		return termenv.Ascii
+	case ColorAlways:
+		profile := termenv.NewOutput(os.Stdout, termenv.WithTTY(true)).ColorProfile()
+		if profile == termenv.Ascii {
+			// TERM is unset or dumb, as in many CI runners
+			return termenv.ANSI256
+		}
+		return profile
+	}
+
+	if _, set := os.LookupEnv("NO_COLOR"); set || !term.IsTerminal(int(os.Stdout.Fd())) {
+		return termenv.Ascii
+	}
+	return termenv.NewOutput(os.Stdout).ColorProfile()
+}
@@ -0,0 +1,75 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// keepColorProfile restores lipgloss's color profile when the test ends
+func keepColorProfile(t *testing.T) {
+	t.Helper()
+	profile := lipgloss.ColorProfile()
+	t.Cleanup(func() { lipgloss.SetColorProfile(profile) })
+}
+
+func TestParseColorMode(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "", want: ColorAuto},
+		{in: "auto", want: ColorAuto},
+		{in: "always", want: ColorAlways},
+		{in: "never", want: ColorNever},
+		{in: "yes", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseColorMode(tt.in)
+			if got != tt.want || (err != nil) != tt.wantErr {
+				t.Errorf("ParseColorMode(%q) = %q, %v; want %q, error %v", tt.in, got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestColorMode(t *testing.T) {
+	tests := []struct {
+		name      string
+		mode      string
+		noColor   bool
+		wantColor bool
+	}{
+		{name: "never", mode: ColorNever},
+		{name: "always", mode: ColorAlways, wantColor: true},
+		{name: "always beats NO_COLOR", mode: ColorAlways, noColor: true, wantColor: true},
+		{name: "auto with NO_COLOR", mode: ColorAuto, noColor: true},
+		// Test output isn't a terminal
+		{name: "auto when redirected", mode: ColorAuto},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keepColorProfile(t)
+			if tt.noColor {
+				t.Setenv("NO_COLOR", "1")
+			}
+
+			SetColorMode(tt.mode)
+			if got := lipgloss.ColorProfile() != termenv.Ascii; got != tt.wantColor {
+				t.Errorf("color on = %v, want %v", got, tt.wantColor)
+			}
+
+			for _, out := range []string{Success("done"), Error("failed"), Warning("careful"), Info("note"), KeyValue("key", "value")} {
+				if colored := strings.Contains(out, "\x1b["); colored != tt.wantColor {
+					t.Errorf("%q has escapes = %v, want %v", out, colored, tt.wantColor)
+				}
+			}
+		})
+	}
+}
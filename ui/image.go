@@ -0,0 +1,53 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// EnvImage forces how the logo is drawn; --image overrides it
+const EnvImage = "DEPLOY_TUNNEL_IMAGE"
+
+// Image modes for --image and DEPLOY_TUNNEL_IMAGE
+const (
+	ImageAuto  = "auto"
+	ImageASCII = "ascii"
+	ImageKitty = "kitty"
+	ImageIterm = "iterm"
+	ImageSixel = "sixel"
+	ImageOff   = "off"
+)
+
+// imageMode is the mode set by --image; empty defers to DEPLOY_TUNNEL_IMAGE
+var imageMode string
+
+// ParseImageMode validates an --image value; empty means auto
+func ParseImageMode(mode string) (string, error) {
+	switch mode = strings.ToLower(mode); mode {
+	case "":
+		return ImageAuto, nil
+	case ImageAuto, ImageASCII, ImageKitty, ImageIterm, ImageSixel, ImageOff:
+		return mode, nil
+	}
+	return "", fmt.Errorf("invalid --image %q: want auto, ascii, kitty, iterm, sixel, or off", mode)
+}
+
+// SetImageMode forces how the logo is drawn. An empty mode falls back to
+// DEPLOY_TUNNEL_IMAGE, then auto-detection.
+func SetImageMode(mode string) {
+	imageMode = mode
+}
+
+// ImageMode returns the mode set by SetImageMode, else DEPLOY_TUNNEL_IMAGE,
+// else auto. An invalid DEPLOY_TUNNEL_IMAGE is treated as auto.
+func ImageMode() string {
+	if imageMode != "" {
+		return imageMode
+	}
+	mode, err := ParseImageMode(os.Getenv(EnvImage))
+	if err != nil {
+		return ImageAuto
+	}
+	return mode
+}
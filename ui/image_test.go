@@ -0,0 +1,33 @@
+package ui
+
+import "testing"
+
+func TestImageMode(t *testing.T) {
+	tests := []struct {
+		name string
+		flag string
+		env  string
+		want string
+	}{
+		{name: "default", want: ImageAuto},
+		{name: "env", env: "SIXEL", want: ImageSixel},
+		{name: "invalid env", env: "png", want: ImageAuto},
+		{name: "flag beats env", flag: ImageOff, env: ImageKitty, want: ImageOff},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(EnvImage, tt.env)
+			SetImageMode(tt.flag)
+			t.Cleanup(func() { SetImageMode("") })
+
+			if got := ImageMode(); got != tt.want {
+				t.Errorf("ImageMode() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := ParseImageMode("png"); err == nil {
+		t.Error(`ParseImageMode("png") succeeded, want an error`)
+	}
+}
@@ -0,0 +1,24 @@
+package ui
+
+import (
+	"os"
+
+	"golang.org/x/term"
+)
+
+// plain renders borders, bullets, and bars as ASCII; see SetPlain. It
+// starts on when stdout isn't a terminal, e.g. under `dt ... | tee`.
+var plain = !term.IsTerminal(int(os.Stdout.Fd()))
+
+// SetPlain turns plain mode on or off. When plain, Header drops its box,
+// List uses "- " bullets, and tables and progress bars are drawn with ASCII
+// characters, so piped output and logs stay readable. Color is separate; see
+// SetColorMode.
+func SetPlain(p bool) {
+	plain = p
+}
+
+// Plain reports whether plain mode is on
+func Plain() bool {
+	return plain
+}
@@ -0,0 +1,51 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+)
+
+// keepPlain restores plain mode when the test ends
+func keepPlain(t *testing.T) {
+	t.Helper()
+	p := Plain()
+	t.Cleanup(func() { SetPlain(p) })
+}
+
+func TestPlainOutput(t *testing.T) {
+	keepColorProfile(t)
+	keepPlain(t)
+	// As when piped: auto color is off since test output isn't a terminal
+	SetColorMode(ColorAuto)
+	SetPlain(true)
+
+	outputs := map[string]string{
+		"Header":      Header(),
+		"List":        List([]string{"first", "second"}),
+		"Table":       Table([]string{"NAME", "STATUS"}, [][]string{{"vercel", "ok"}, {"netlify", "down"}}),
+		"ProgressBar": ProgressBar(3, 10, 10),
+		"Success":     Success("done"),
+		"Error":       Error("failed"),
+		"Warning":     Warning("careful"),
+		"Info":        Info("note"),
+		"KeyValue":    KeyValue("key", "value"),
+	}
+	for name, out := range outputs {
+		if strings.Contains(out, "\x1b") {
+			t.Errorf("%s() = %q, want no ANSI escapes", name, out)
+		}
+		for _, r := range out {
+			if r > 0x7f && !strings.ContainsRune("✓✗⚠ℹ", r) {
+				t.Errorf("%s() = %q, want ASCII drawing, found %q", name, out, r)
+				break
+			}
+		}
+	}
+
+	if got := outputs["ProgressBar"]; got != "[###-------]  30%" {
+		t.Errorf("ProgressBar() = %q, want [###-------]  30%%", got)
+	}
+	if got := outputs["List"]; got != "- first\n- second" {
+		t.Errorf("List() = %q, want - bullets", got)
+	}
+}
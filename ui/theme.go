@@ -0,0 +1,210 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ThemeFileName is the theme file inside the deploy-tunnel directory
+const ThemeFileName = "theme.json"
+
+// DefaultThemeName is the theme used when none is chosen
+const DefaultThemeName = "default"
+
+// Theme holds the named colors every style is built from. Each is a hex
+// color like "#ef9f76" or an ANSI color number like "208".
+type Theme struct {
+	Name string `json:"name"`
+	// Coral is the accent: headers, keys, the selection, borders
+	Coral string `json:"coral"`
+	// Gray is for help text and status bar backgrounds
+	Gray string `json:"gray"`
+	// LightGray is for ordinary text
+	LightGray string `json:"light_gray"`
+	Green     string `json:"green"`
+	Red       string `json:"red"`
+	Yellow    string `json:"yellow"`
+	Blue      string `json:"blue"`
+}
+
+// builtinThemes are selectable by name in the theme file or config
+var builtinThemes = map[string]Theme{
+	DefaultThemeName: {
+		Name:      DefaultThemeName,
+		Coral:     "#ef9f76",
+		Gray:      "#6c6f85",
+		LightGray: "#a5adce",
+		Green:     "#a6d189",
+		Red:       "#e78284",
+		Yellow:    "#e5c890",
+		Blue:      "#8caaee",
+	},
+	// light is for light terminal backgrounds
+	"light": {
+		Name:      "light",
+		Coral:     "#fe640b",
+		Gray:      "#8c8fa1",
+		LightGray: "#4c4f69",
+		Green:     "#40a02b",
+		Red:       "#d20f39",
+		Yellow:    "#df8e1d",
+		Blue:      "#1e66f5",
+	},
+	// high-contrast sticks to bright, saturated colors
+	"high-contrast": {
+		Name:      "high-contrast",
+		Coral:     "#ff8700",
+		Gray:      "#808080",
+		LightGray: "#ffffff",
+		Green:     "#00ff00",
+		Red:       "#ff0000",
+		Yellow:    "#ffff00",
+		Blue:      "#00afff",
+	},
+}
+
+// ThemeNames lists the built-in themes, sorted
+func ThemeNames() []string {
+	names := make([]string, 0, len(builtinThemes))
+	for name := range builtinThemes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// BuiltinTheme returns the built-in theme called name
+func BuiltinTheme(name string) (Theme, error) {
+	theme, ok := builtinThemes[name]
+	if !ok {
+		return Theme{}, fmt.Errorf("unknown theme %q (available: %s)", name, strings.Join(ThemeNames(), ", "))
+	}
+	return theme, nil
+}
+
+// LoadTheme reads the theme file at path. The file's "name" (or base when
+// the file doesn't set one) picks the built-in theme to start from, and any
+// colors in the file override it. A missing file gives the base theme.
+func LoadTheme(path, base string) (Theme, error) {
+	if base == "" {
+		base = DefaultThemeName
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return BuiltinTheme(base)
+	}
+	if err != nil {
+		return Theme{}, fmt.Errorf("failed to read theme: %w", err)
+	}
+
+	var overrides Theme
+	if err := json.Unmarshal(data, &overrides); err != nil {
+		return Theme{}, fmt.Errorf("invalid theme %s: %w", path, err)
+	}
+	if overrides.Name != "" {
+		base = overrides.Name
+	}
+	theme, err := BuiltinTheme(base)
+	if err != nil {
+		return Theme{}, fmt.Errorf("invalid theme %s: %w", path, err)
+	}
+	theme = theme.merge(overrides)
+	if err := theme.Validate(); err != nil {
+		return Theme{}, fmt.Errorf("invalid theme %s: %w", path, err)
+	}
+	return theme, nil
+}
+
+// merge returns t with every color set in overrides applied on top
+func (t Theme) merge(overrides Theme) Theme {
+	for _, pair := range [][2]*string{
+		{&t.Coral, &overrides.Coral},
+		{&t.Gray, &overrides.Gray},
+		{&t.LightGray, &overrides.LightGray},
+		{&t.Green, &overrides.Green},
+		{&t.Red, &overrides.Red},
+		{&t.Yellow, &overrides.Yellow},
+		{&t.Blue, &overrides.Blue},
+	} {
+		if *pair[1] != "" {
+			*pair[0] = *pair[1]
+		}
+	}
+	return t
+}
+
+var hexColorPattern = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// Validate checks every color is a hex color or an ANSI color number
+func (t Theme) Validate() error {
+	for _, c := range []struct{ key, value string }{
+		{"coral", t.Coral},
+		{"gray", t.Gray},
+		{"light_gray", t.LightGray},
+		{"green", t.Green},
+		{"red", t.Red},
+		{"yellow", t.Yellow},
+		{"blue", t.Blue},
+	} {
+		if hexColorPattern.MatchString(c.value) {
+			continue
+		}
+		if n, err := strconv.Atoi(c.value); err == nil && n >= 0 && n <= 255 {
+			continue
+		}
+		return fmt.Errorf("%s: %q is not a hex color or ANSI color number", c.key, c.value)
+	}
+	return nil
+}
+
+var (
+	themeMu     sync.Mutex
+	activeTheme = builtinThemes[DefaultThemeName]
+	themeHooks  []func(Theme)
+)
+
+// ActiveTheme returns the theme styles are currently built from
+func ActiveTheme() Theme {
+	themeMu.Lock()
+	defer themeMu.Unlock()
+	return activeTheme
+}
+
+// SetTheme makes t the active theme and rebuilds every style from it, in ui
+// and in packages registered with OnThemeChange. Colors t leaves empty come
+// from the default theme. Call it at startup, before rendering anything.
+func SetTheme(t Theme) {
+	name := t.Name
+	if name == "" {
+		name = DefaultThemeName
+	}
+	t = builtinThemes[DefaultThemeName].merge(t)
+	t.Name = name
+
+	themeMu.Lock()
+	activeTheme = t
+	hooks := append([]func(Theme){}, themeHooks...)
+	themeMu.Unlock()
+
+	for _, hook := range hooks {
+		hook(t)
+	}
+}
+
+// OnThemeChange calls apply with the active theme now and again whenever
+// SetTheme changes it, so a package can rebuild its styles
+func OnThemeChange(apply func(Theme)) {
+	themeMu.Lock()
+	themeHooks = append(themeHooks, apply)
+	t := activeTheme
+	themeMu.Unlock()
+
+	apply(t)
+}
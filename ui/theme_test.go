@@ -0,0 +1,109 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeTheme writes content as a theme file and returns its path
+func writeTheme(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), ThemeFileName)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// keepTheme restores the active theme when the test ends
+func keepTheme(t *testing.T) {
+	t.Helper()
+	theme := ActiveTheme()
+	t.Cleanup(func() { SetTheme(theme) })
+}
+
+func TestLoadTheme(t *testing.T) {
+	light := builtinThemes["light"]
+
+	tests := []struct {
+		name string
+		// file is the theme file's content; "" leaves it missing
+		file string
+		base string
+		want Theme
+	}{
+		{name: "missing file", want: builtinThemes[DefaultThemeName]},
+		{name: "missing file keeps the base", base: "light", want: light},
+		{name: "name picks the base", file: `{"name": "high-contrast"}`, base: "light", want: builtinThemes["high-contrast"]},
+		{
+			name: "colors override the base",
+			file: `{"coral": "#123456", "gray": "244"}`,
+			base: "light",
+			want: Theme{Name: "light", Coral: "#123456", Gray: "244", LightGray: light.LightGray, Green: light.Green, Red: light.Red, Yellow: light.Yellow, Blue: light.Blue},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), ThemeFileName)
+			if tt.file != "" {
+				path = writeTheme(t, tt.file)
+			}
+			got, err := LoadTheme(path, tt.base)
+			if err != nil {
+				t.Fatalf("LoadTheme() error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("LoadTheme() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadThemeInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		// wantErr is a substring of the expected error
+		wantErr string
+	}{
+		{name: "malformed", file: `{"coral": "#123456",`, wantErr: "invalid theme"},
+		{name: "wrong type", file: `{"coral": 208}`, wantErr: "invalid theme"},
+		{name: "unknown name", file: `{"name": "neon"}`, wantErr: `unknown theme "neon"`},
+		{name: "bad hex color", file: `{"green": "#12345g"}`, wantErr: `green: "#12345g" is not a hex color`},
+		{name: "named color", file: `{"red": "red"}`, wantErr: "red:"},
+		{name: "ANSI number out of range", file: `{"blue": "256"}`, wantErr: "blue:"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeTheme(t, tt.file)
+			_, err := LoadTheme(path, "")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadTheme(%s) error = %v, want %q", tt.file, err, tt.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), path) {
+				t.Errorf("LoadTheme() error = %v, want it to name the file", err)
+			}
+		})
+	}
+}
+
+func TestSetTheme(t *testing.T) {
+	keepTheme(t)
+
+	var applied []string
+	OnThemeChange(func(theme Theme) { applied = append(applied, theme.Name) })
+
+	// Colors left empty come from the default theme
+	SetTheme(Theme{Name: "custom", Coral: "#000000"})
+	got := ActiveTheme()
+	if got.Coral != "#000000" || got.Green != builtinThemes[DefaultThemeName].Green {
+		t.Errorf("ActiveTheme() = %+v, want coral set and the rest from the default", got)
+	}
+	if len(applied) != 2 || applied[0] != DefaultThemeName || applied[1] != "custom" {
+		t.Errorf("hook saw themes %v, want the active one then custom", applied)
+	}
+}
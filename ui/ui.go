@@ -5,64 +5,98 @@ import (
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 )
 
+// Styles, rebuilt from the active theme by applyTheme
 var (
-	// Colors
-	coral     = lipgloss.Color("#ef9f76")
-	gray      = lipgloss.Color("#6c6f85")
-	lightGray = lipgloss.Color("#a5adce")
-	green     = lipgloss.Color("#a6d189")
-	red       = lipgloss.Color("#e78284")
-	yellow    = lipgloss.Color("#e5c890")
-
-	// Styles
+	HeaderStyle    lipgloss.Style
+	SubheaderStyle lipgloss.Style
+	SuccessStyle   lipgloss.Style
+	ErrorStyle     lipgloss.Style
+	WarningStyle   lipgloss.Style
+	InfoStyle      lipgloss.Style
+	KeyStyle       lipgloss.Style
+	ValueStyle     lipgloss.Style
+	BoxStyle       lipgloss.Style
+	SpinnerStyle   lipgloss.Style
+)
+
+func init() {
+	OnThemeChange(applyTheme)
+}
+
+// applyTheme builds the styles from t's colors
+func applyTheme(t Theme) {
+	coral := lipgloss.Color(t.Coral)
+	lightGray := lipgloss.Color(t.LightGray)
+
 	HeaderStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(coral).
-			MarginTop(1).
-			MarginBottom(1)
+		Bold(true).
+		Foreground(coral).
+		MarginTop(1).
+		MarginBottom(1)
 
 	SubheaderStyle = lipgloss.NewStyle().
-			Foreground(lightGray).
-			Italic(true)
+		Foreground(lightGray).
+		Italic(true)
 
 	SuccessStyle = lipgloss.NewStyle().
-			Foreground(green).
-			Bold(true)
+		Foreground(lipgloss.Color(t.Green)).
+		Bold(true)
 
 	ErrorStyle = lipgloss.NewStyle().
-			Foreground(red).
-			Bold(true)
+		Foreground(lipgloss.Color(t.Red)).
+		Bold(true)
 
 	WarningStyle = lipgloss.NewStyle().
-			Foreground(yellow)
+		Foreground(lipgloss.Color(t.Yellow))
 
 	InfoStyle = lipgloss.NewStyle().
-			Foreground(lightGray)
+		Foreground(lightGray)
 
 	KeyStyle = lipgloss.NewStyle().
-			Foreground(coral).
-			Bold(true)
+		Foreground(coral).
+		Bold(true)
 
 	ValueStyle = lipgloss.NewStyle().
-			Foreground(lightGray)
+		Foreground(lightGray)
 
 	BoxStyle = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(coral).
-			Padding(1, 2).
-			MarginTop(1)
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(coral).
+		Padding(1, 2).
+		MarginTop(1)
 
 	SpinnerStyle = lipgloss.NewStyle().
-			Foreground(coral)
-)
+		Foreground(coral)
+}
+
+// quiet suppresses the header and progress chatter; see SetQuiet
+var quiet bool
+
+// SetQuiet turns quiet mode on or off. When quiet, Header renders nothing
+// and callers should skip decorative output, leaving results and errors.
+func SetQuiet(q bool) {
+	quiet = q
+}
 
-// Header renders the Deploy Tunnel header
+// Quiet reports whether quiet mode is on
+func Quiet() bool {
+	return quiet
+}
+
+// Header renders the Deploy Tunnel header, or nothing when quiet
 func Header() string {
+	if quiet {
+		return ""
+	}
 	title := HeaderStyle.Render("DEPLOY ▸ TUNNEL")
 	subtitle := SubheaderStyle.Render("migrate safely between hosts")
 
+	if plain {
+		return HeaderStyle.UnsetMargins().Render("DEPLOY > TUNNEL") + "\n" + subtitle
+	}
 	return BoxStyle.Render(
 		fmt.Sprintf("%s\n%s", title, subtitle),
 	)
@@ -98,9 +132,13 @@ func KeyValue(key, value string) string {
 
 // List renders a bulleted list
 func List(items []string) string {
+	bullet := "  • "
+	if plain {
+		bullet = "- "
+	}
 	var lines []string
 	for _, item := range items {
-		lines = append(lines, InfoStyle.Render("  • "+item))
+		lines = append(lines, InfoStyle.Render(bullet+item))
 	}
 	return strings.Join(lines, "\n")
 }
@@ -114,23 +152,117 @@ func Step(current, total int, message string) string {
 // Spinner frames for CLI animations
 var SpinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
 
+// Align is a table column's horizontal alignment
+type Align int
+
+const (
+	AlignLeft Align = iota
+	AlignRight
+	AlignCenter
+)
+
 // Table renders a simple table
 func Table(headers []string, rows [][]string) string {
+	return AlignedTable(headers, rows, nil)
+}
+
+// AlignedTable renders a table with per-column alignment. Columns beyond
+// the end of aligns are left-aligned.
+func AlignedTable(headers []string, rows [][]string, aligns []Align) string {
 	if len(rows) == 0 {
 		return InfoStyle.Render("No data")
 	}
 
-	// Calculate column widths
-	widths := make([]int, len(headers))
-	for i, h := range headers {
-		widths[i] = len(h)
+	return renderTable(headers, rows, aligns, columnWidths(headers, rows))
+}
+
+// TableWidth renders a table no wider than maxWidth cells, shrinking the
+// widest columns first and truncating their cells with "…". Trailing
+// columns are dropped when even one cell each won't fit
+func TableWidth(headers []string, rows [][]string, maxWidth int) string {
+	if len(rows) == 0 {
+		return InfoStyle.Render("No data")
 	}
-	for _, row := range rows {
-		for i, cell := range row {
-			if i < len(widths) && len(cell) > widths[i] {
-				widths[i] = len(cell)
+
+	cols := len(headers)
+	for cols > 1 && cols+tableGap*(cols-1) > maxWidth {
+		cols--
+	}
+	headers = headers[:cols]
+
+	kept := make([][]string, len(rows))
+	for i, row := range rows {
+		kept[i] = row[:min(cols, len(row))]
+	}
+
+	widths := fitWidths(columnWidths(headers, kept), maxWidth-tableGap*(cols-1))
+
+	headers = truncateCells(headers, widths)
+	for i, row := range kept {
+		kept[i] = truncateCells(row, widths)
+	}
+	return clipLines(renderTable(headers, kept, nil, widths), maxWidth)
+}
+
+// clipLines cuts every line of s to maxWidth cells, for a single column
+// that still won't fit
+func clipLines(s string, maxWidth int) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if lipgloss.Width(line) > maxWidth {
+			lines[i] = ansi.Truncate(line, max(maxWidth, 0), "")
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// tableGap is the space between table columns
+const tableGap = 2
+
+// fitWidths shrinks column widths until they sum to at most available,
+// always taking a cell from the widest column so narrow ones stay intact.
+// Every column keeps at least one cell.
+func fitWidths(widths []int, available int) []int {
+	fitted := append([]int(nil), widths...)
+	total := 0
+	for _, w := range fitted {
+		total += w
+	}
+
+	for total > available {
+		widest := 0
+		for i, w := range fitted {
+			if w > fitted[widest] {
+				widest = i
 			}
 		}
+		if fitted[widest] <= 1 {
+			break
+		}
+		fitted[widest]--
+		total--
+	}
+	return fitted
+}
+
+// truncateCells cuts each cell to its column width, ending cut cells with "…"
+func truncateCells(cells []string, widths []int) []string {
+	out := make([]string, len(cells))
+	for i, cell := range cells {
+		if i < len(widths) && lipgloss.Width(cell) > widths[i] {
+			cell = ansi.Truncate(cell, widths[i], "…")
+		}
+		out[i] = cell
+	}
+	return out
+}
+
+func renderTable(headers []string, rows [][]string, aligns []Align, widths []int) string {
+	align := func(i int) Align {
+		if i < len(aligns) {
+			return aligns[i]
+		}
+		return AlignLeft
 	}
 
 	// Build table
@@ -138,18 +270,22 @@ func Table(headers []string, rows [][]string) string {
 
 	// Header row
 	for i, h := range headers {
-		sb.WriteString(KeyStyle.Render(padRight(h, widths[i])))
+		sb.WriteString(KeyStyle.Render(pad(h, widths[i], align(i))))
 		if i < len(headers)-1 {
-			sb.WriteString("  ")
+			sb.WriteString(strings.Repeat(" ", tableGap))
 		}
 	}
 	sb.WriteString("\n")
 
 	// Separator
+	rule := "─"
+	if plain {
+		rule = "-"
+	}
 	for i, w := range widths {
-		sb.WriteString(strings.Repeat("─", w))
+		sb.WriteString(strings.Repeat(rule, w))
 		if i < len(widths)-1 {
-			sb.WriteString("  ")
+			sb.WriteString(strings.Repeat(" ", tableGap))
 		}
 	}
 	sb.WriteString("\n")
@@ -158,9 +294,9 @@ func Table(headers []string, rows [][]string) string {
 	for _, row := range rows {
 		for i, cell := range row {
 			if i < len(widths) {
-				sb.WriteString(ValueStyle.Render(padRight(cell, widths[i])))
+				sb.WriteString(ValueStyle.Render(pad(cell, widths[i], align(i))))
 				if i < len(row)-1 {
-					sb.WriteString("  ")
+					sb.WriteString(strings.Repeat(" ", tableGap))
 				}
 			}
 		}
@@ -170,14 +306,44 @@ func Table(headers []string, rows [][]string) string {
 	return sb.String()
 }
 
-func padRight(s string, width int) string {
-	if len(s) >= width {
+// columnWidths returns each column's widest cell in terminal cells, so
+// multi-byte text and styled (ANSI) strings line up
+func columnWidths(headers []string, rows [][]string) []int {
+	widths := make([]int, len(headers))
+	for i, h := range headers {
+		widths[i] = lipgloss.Width(h)
+	}
+	for _, row := range rows {
+		for i, cell := range row {
+			if i < len(widths) {
+				if w := lipgloss.Width(cell); w > widths[i] {
+					widths[i] = w
+				}
+			}
+		}
+	}
+	return widths
+}
+
+// pad pads s with spaces to width terminal cells, placing it per align
+func pad(s string, width int, align Align) string {
+	gap := width - lipgloss.Width(s)
+	if gap <= 0 {
 		return s
 	}
-	return s + strings.Repeat(" ", width-len(s))
+
+	switch align {
+	case AlignRight:
+		return strings.Repeat(" ", gap) + s
+	case AlignCenter:
+		left := gap / 2
+		return strings.Repeat(" ", left) + s + strings.Repeat(" ", gap-left)
+	default:
+		return s + strings.Repeat(" ", gap)
+	}
 }
 
-// ProgressBar renders a simple progress bar
+// ProgressBar renders a simple progress bar, or "" when total is zero
 func ProgressBar(current, total int, width int) string {
 	if width <= 0 {
 		width = 40
@@ -186,11 +352,24 @@ func ProgressBar(current, total int, width int) string {
 		return ""
 	}
 
+	// Clamp so current < 0 or current > total can't produce a negative repeat
 	percent := float64(current) / float64(total)
+	if percent < 0 {
+		percent = 0
+	}
+	if percent > 1 {
+		percent = 1
+	}
 	filled := int(float64(width) * percent)
+	if filled > width {
+		filled = width
+	}
 
-	bar := strings.Repeat("█", filled) + strings.Repeat("░", width-filled)
 	percentText := fmt.Sprintf(" %3.0f%%", percent*100)
+	if plain {
+		return "[" + strings.Repeat("#", filled) + strings.Repeat("-", width-filled) + "]" + InfoStyle.Render(percentText)
+	}
+	bar := strings.Repeat("█", filled) + strings.Repeat("░", width-filled)
 
 	return SpinnerStyle.Render(bar) + InfoStyle.Render(percentText)
 }
@@ -199,7 +378,7 @@ func ProgressBar(current, total int, width int) string {
 func Confirm(message string) string {
 	return fmt.Sprintf("%s %s",
 		KeyStyle.Render("?"),
-		message+" (y/n)",
+		message+" (y/N)",
 	)
 }
 
@@ -0,0 +1,197 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// tableLines renders a table and returns its non-empty lines without ANSI styling
+func tableLines(table string) []string {
+	var lines []string
+	for _, line := range strings.Split(ansi.Strip(table), "\n") {
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func TestTableAlignsByDisplayWidth(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers []string
+		rows    [][]string
+	}{
+		{
+			name:    "ascii",
+			headers: []string{"DOMAIN", "PROVIDER"},
+			rows:    [][]string{{"example.com", "vercel"}, {"a.io", "netlify"}},
+		},
+		{
+			name:    "cjk",
+			headers: []string{"DOMAIN", "PROVIDER"},
+			rows:    [][]string{{"例え.jp", "vercel"}, {"example.com", "ネットリファイ"}},
+		},
+		{
+			name:    "accented",
+			headers: []string{"DOMAIN", "PROVIDER"},
+			rows:    [][]string{{"café.fr", "vercel"}, {"naïve.io", "netlify"}},
+		},
+		{
+			name:    "styled cells",
+			headers: []string{"DOMAIN", "STATUS"},
+			rows:    [][]string{{"example.com", SuccessStyle.Render("ok")}, {"a.io", ErrorStyle.Render("failed")}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := tableLines(Table(tt.headers, tt.rows))
+			if len(lines) != len(tt.rows)+2 {
+				t.Fatalf("got %d lines, want %d", len(lines), len(tt.rows)+2)
+			}
+
+			want := lipgloss.Width(lines[0])
+			for i, line := range lines {
+				if got := lipgloss.Width(line); got != want {
+					t.Errorf("line %d is %d cells wide, want %d: %q", i, got, want, line)
+				}
+			}
+
+			// The second column starts at the same cell offset on every row
+			offset := lipgloss.Width(lines[0][:strings.Index(lines[0], tt.headers[1])])
+			for i, row := range tt.rows {
+				line := lines[i+2]
+				start := strings.Index(line, ansi.Strip(row[1]))
+				if start < 0 {
+					t.Fatalf("row %d missing %q: %q", i, row[1], line)
+				}
+				if got := lipgloss.Width(line[:start]); got != offset {
+					t.Errorf("row %d second column starts at cell %d, want %d", i, got, offset)
+				}
+			}
+		})
+	}
+}
+
+func TestProgressBar(t *testing.T) {
+	tests := []struct {
+		name                  string
+		current, total, width int
+		filled                int
+		percent               string
+	}{
+		{name: "half", current: 5, total: 10, width: 10, filled: 5, percent: " 50%"},
+		{name: "over total", current: 15, total: 10, width: 10, filled: 10, percent: "100%"},
+		{name: "negative current", current: -3, total: 10, width: 10, filled: 0, percent: "  0%"},
+		{name: "default width", current: 1, total: 2, width: 0, filled: 20, percent: " 50%"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bar := ansi.Strip(ProgressBar(tt.current, tt.total, tt.width))
+			// Plain mode draws with "#" instead of "█"
+			if got := strings.Count(bar, "█") + strings.Count(bar, "#"); got != tt.filled {
+				t.Errorf("filled = %d, want %d in %q", got, tt.filled, bar)
+			}
+			if !strings.HasSuffix(bar, tt.percent) {
+				t.Errorf("bar %q does not end with %q", bar, tt.percent)
+			}
+		})
+	}
+
+	if bar := ProgressBar(3, 0, 10); bar != "" {
+		t.Errorf("ProgressBar with zero total = %q, want empty", bar)
+	}
+}
+
+func TestTableWidthFits(t *testing.T) {
+	headers := []string{"DOMAIN", "PROVIDER", "STATUS"}
+	rows := [][]string{
+		{"very-long-subdomain.example.com", "vercel", "ready"},
+		{"例え.jp", "netlify", "building"},
+	}
+
+	tests := []struct {
+		name     string
+		maxWidth int
+		ellipsis bool
+	}{
+		{name: "wide enough", maxWidth: 120, ellipsis: false},
+		{name: "truncates widest", maxWidth: 40, ellipsis: true},
+		{name: "one cell per column", maxWidth: 7, ellipsis: true},
+		{name: "drops columns", maxWidth: 4, ellipsis: true},
+		{name: "single cell", maxWidth: 1, ellipsis: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := TableWidth(headers, rows, tt.maxWidth)
+			for i, line := range tableLines(table) {
+				if got := lipgloss.Width(line); got > tt.maxWidth {
+					t.Errorf("line %d is %d cells wide, max %d: %q", i, got, tt.maxWidth, line)
+				}
+			}
+			if got := strings.Contains(table, "…"); got != tt.ellipsis {
+				t.Errorf("ellipsis present = %v, want %v:\n%s", got, tt.ellipsis, table)
+			}
+		})
+	}
+}
+
+func TestAlignedTable(t *testing.T) {
+	headers := []string{"NAME", "BUILDS", "STATUS"}
+	rows := [][]string{
+		{"shop", "7", "ok"},
+		{"blog", "1234", "failed"},
+		{"ドメイン", "56", "ok"},
+	}
+
+	lines := tableLines(AlignedTable(headers, rows, []Align{AlignLeft, AlignRight, AlignCenter}))
+	want := []string{
+		"NAME      BUILDS  STATUS",
+		"--------  ------  ------",
+		"shop           7    ok  ",
+		"blog        1234  failed",
+		"ドメイン      56    ok  ",
+	}
+	if !plain {
+		want[1] = strings.ReplaceAll(want[1], "-", "─")
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("got %d lines, want %d:\n%s", len(lines), len(want), strings.Join(lines, "\n"))
+	}
+	for i := range want {
+		// The last cell of each line is padded too, so compare it whole
+		if lines[i] != want[i] {
+			t.Errorf("line %d = %q, want %q", i, lines[i], want[i])
+		}
+	}
+}
+
+func TestAlignedTableDefaultsLeft(t *testing.T) {
+	headers := []string{"KEY", "COUNT"}
+	rows := [][]string{{"a", "1"}, {"b", "100"}}
+
+	// Columns past the end of aligns are left-aligned, like Table
+	if got, want := AlignedTable(headers, rows, []Align{AlignLeft}), Table(headers, rows); got != want {
+		t.Errorf("AlignedTable with a short aligns list = %q, want %q", got, want)
+	}
+	if got := tableLines(AlignedTable(headers, rows, []Align{AlignLeft, AlignRight})); got[2] != "a        1" {
+		t.Errorf("right-aligned row = %q, want %q", got[2], "a        1")
+	}
+}
+
+func TestHeaderQuiet(t *testing.T) {
+	if Header() == "" {
+		t.Fatal("Header() is empty outside quiet mode")
+	}
+	SetQuiet(true)
+	defer SetQuiet(false)
+	if got := Header(); got != "" {
+		t.Errorf("Header() = %q in quiet mode, want nothing", got)
+	}
+}